@@ -0,0 +1,74 @@
+// canary runs every scraper against a set of reference subjects and reports the scrapers that fail or whose
+// responses drifted from their schema. The JSON report is written to stdout (or to -output) and the exit
+// status is 1 when the report isn't healthy, so it can run from cron or CI.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"market_data_mcp_server/pkg/canary"
+	"market_data_mcp_server/pkg/config"
+	"market_data_mcp_server/pkg/marketDataScraper"
+	"os"
+	"strings"
+	"time"
+)
+
+func main() {
+	reference := canary.DefaultReferenceSet
+	symbols := flag.String("symbols", strings.Join(reference.Symbols, ","), "Comma separated reference stock symbols")
+	flag.StringVar(&reference.Etf, "etf", reference.Etf, "Reference ETF symbol")
+	flag.StringVar(&reference.Sector, "sector", reference.Sector, "Reference sector (url name)")
+	flag.StringVar(&reference.Industry, "industry", reference.Industry, "Reference industry (url name)")
+	flag.StringVar(&reference.SuperInvestor, "super-investor", reference.SuperInvestor, "Reference super investor")
+	output := flag.String("output", "", "File to write the JSON report to, stdout when empty")
+	timeout := flag.Duration("timeout", 10*time.Minute, "Maximum duration of the run")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "[CANARY] ", log.LstdFlags)
+
+	reference.Symbols = nil
+	for _, symbol := range strings.Split(*symbols, ",") {
+		if symbol = strings.ToLower(strings.TrimSpace(symbol)); symbol != "" {
+			reference.Symbols = append(reference.Symbols, symbol)
+		}
+	}
+
+	// The base URLs are the ones of the server so that the canary can run against a mirror or the fake upstreams
+	conf, _ := config.LoadConfig()
+	scraper := marketDataScraper.NewMarketDataScraper(marketDataScraper.BaseURLs{
+		StockAnalysis:    conf.StockAnalysisBaseURL,
+		StockAnalysisApi: conf.StockAnalysisApiBaseURL,
+		Dataroma:         conf.DataromaBaseURL,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report := canary.Run(ctx, scraper, reference)
+
+	if *output != "" {
+		if err := canary.WriteReport(*output, report); err != nil {
+			logger.Fatalf("Failed to write the report: %v", err)
+		}
+	} else {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			logger.Fatalf("Failed to write the report: %v", err)
+		}
+	}
+
+	for _, result := range report.Results {
+		if result.Status != canary.StatusOk {
+			logger.Printf("%s %s: %s", result.Scraper, result.Subject, result.Status)
+		}
+	}
+	logger.Printf("%d checks, %d failed, %d drifted in %dms", report.Checks, report.Failed, report.Drifted, report.DurationMs)
+
+	if !report.Healthy() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,32 @@
+// fake_upstreams serves canned responses of all the upstreams of the MCP server so that it can be run
+// and demoed without network. Start it and run the MCP server with the environment variables it prints.
+package main
+
+import (
+	"flag"
+	"log"
+	"market_data_mcp_server/pkg/fakeupstreams"
+	"net/http"
+	"os"
+	"strings"
+)
+
+func main() {
+	port := flag.String("port", "8090", "Port to listen on")
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "[FAKE UPSTREAMS] ", log.LstdFlags)
+
+	handler := fakeupstreams.NewHandler()
+	logged := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.Printf("%s %s", r.Method, r.URL.RequestURI())
+		handler.ServeHTTP(w, r)
+	})
+
+	env := fakeupstreams.Env("http://localhost:" + *port)
+	logger.Printf("Serving fake upstreams on port %s, point the server to them with:\n%s", *port, strings.Join(env, "\n"))
+
+	if err := http.ListenAndServe(":"+*port, logged); err != nil {
+		logger.Fatalf("Server error: %v", err)
+	}
+}
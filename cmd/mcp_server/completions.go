@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CompleteHandlerFunc handles a completion/complete request
+type CompleteHandlerFunc func(ctx context.Context, req mcp.CompleteRequest) (*mcp.CompleteResult, error)
+
+// CompletionMiddleware answers the completion/complete requests before they reach the MCP server.
+// mcp-go doesn't route completion/complete to any handler (it responds with "method not found"),
+// so we intercept these requests at the HTTP level and forward everything else untouched.
+type CompletionMiddleware struct {
+	logger  *log.Logger
+	handler CompleteHandlerFunc
+}
+
+func NewCompletionMiddleware(logger *log.Logger, handler CompleteHandlerFunc) *CompletionMiddleware {
+	return &CompletionMiddleware{logger: logger, handler: handler}
+}
+
+func (m *CompletionMiddleware) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var message struct {
+			JSONRPC string             `json:"jsonrpc"`
+			ID      mcp.RequestId      `json:"id"`
+			Method  mcp.MCPMethod      `json:"method"`
+			Params  mcp.CompleteParams `json:"params"`
+		}
+		// Batches and invalid messages are handled by the MCP server
+		if err := json.Unmarshal(body, &message); err != nil || message.Method != "completion/complete" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		req := mcp.CompleteRequest{
+			Request: mcp.Request{Method: string(message.Method)},
+			Params:  message.Params,
+			Header:  r.Header,
+		}
+
+		var response any
+		result, err := m.handler(r.Context(), req)
+		if err != nil {
+			m.logger.Printf("Completion failed: argument=%s error=%v", message.Params.Argument.Name, err)
+			response = mcp.NewJSONRPCError(message.ID, mcp.INVALID_PARAMS, err.Error(), nil)
+		} else {
+			response = mcp.NewJSONRPCResultResponse(message.ID, *result)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if sessionID := r.Header.Get("Mcp-Session-Id"); sessionID != "" {
+			w.Header().Set("Mcp-Session-Id", sessionID)
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			m.logger.Printf("Failed to write completion response: %v", err)
+		}
+	})
+}
@@ -2,19 +2,23 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"log"
-	alphavantage "market_data_mcp_server/pkg/alpha_vantage"
 	"market_data_mcp_server/pkg/api/mcp/tools"
-	coingecko "market_data_mcp_server/pkg/coin_gecko"
+	"market_data_mcp_server/pkg/app"
+	"market_data_mcp_server/pkg/canary"
 	"market_data_mcp_server/pkg/config"
+	"market_data_mcp_server/pkg/httpreplay"
 	"market_data_mcp_server/pkg/marketDataScraper"
+	"market_data_mcp_server/pkg/scheduler"
 	"market_data_mcp_server/pkg/services"
+	"market_data_mcp_server/pkg/warmer"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
@@ -24,173 +28,36 @@ func main() {
 	// Initialize components
 	logger := log.New(os.Stdout, "[MCP] ", log.LstdFlags)
 
+	setupHttpReplay(conf, logger)
+
 	// Create middleware
 	loggingMW := NewLoggingMiddleware(logger)
 
-	mcpServer := server.NewMCPServer(
-		"Market Data MCP Server",
-		"1.0.0",
-		server.WithToolCapabilities(true),
-		server.WithResourceCapabilities(false, true),
-		server.WithPromptCapabilities(true),
-		server.WithRecovery(),
-		server.WithToolHandlerMiddleware(loggingMW.ToolMiddleware),
-	)
+	// Track the requested symbols, the cache warmer prefetches the most requested ones
+	usageTracker := warmer.NewUsageTracker("stock_symbol")
 
-	// Setup cache and data services
+	// Setup cache and the MCP server
 	cache, _ := services.NewBadgerCacheService()
-	dataService := marketDataScraper.NewMarketDataScraperWithCache(cache, conf)
-
-	alphaVantageClient, _ := alphavantage.NewAlphaVantageClientWithCache(conf.AlphaVantageApiKey, cache, conf.AlphaVantageCacheTtl)
-	coinGeckoClient, _ := coingecko.NewCoinGeckoClientWithCache(conf.CoinGeckoApiKey, cache, conf.CoinGeckoCacheTtl)
-
-	// Set up services
-	tickerService, _ := services.NewTickerService(dataService)
-	etfService, _ := services.NewEtfService(dataService)
-	superInvestorService, _ := services.NewSuperInvestorService(dataService)
-	cryptoService, _ := services.NewCryptoService(coinGeckoClient, alphaVantageClient)
-	investingIdeasService, _ := services.NewInvestingIdeasLocalDataService(conf.InvestingIdeasDataPath)
-
-	// Setup tools
-	searchStocksTool, _ := tools.NewStockSearchTool(tickerService)
-	searchEtfsTool, _ := tools.NewSearchEtfTool(etfService)
-	getEtfTool, _ := tools.NewGetEtfTool(etfService)
-	getSuperInvestorsTool, _ := tools.NewGetSuperInvestorsTool(superInvestorService)
-	getSuperInvestorPortfolioTool, _ := tools.NewGetSuperInvestorPortfolioTool(superInvestorService)
-	getMarketNewsTool, _ := tools.NewGetMarketNewsTool(dataService)
-	getSectorsTool, _ := tools.NewGetSectorsTool(dataService)
-	getSectorStocksTool, _ := tools.NewGetSectorStocksTool(dataService)
-	getStockOverviewTool, _ := tools.NewGetStockOverviewTool(dataService)
-	getStockFinancialsTool, _ := tools.NewGetStockFinancialsTool(dataService)
-	getEconomicIndicatorTimeSeriesTool, _ := tools.NewGetEconomicIndicatorTimeSeriesTool(alphaVantageClient)
-	getCommodityTimeSeriesTool, _ := tools.NewGetCommodityTimeSeriesTool(alphaVantageClient)
-	searchCryptocurrenciesTool, _ := tools.NewSearchCryptocurrenciesTool(cryptoService)
-	getCryptocurrencyDataByIdTool, _ := tools.NewGetCryptocurrencyDataByIdTool(cryptoService)
-	getCryptocurrencyNewsTool, _ := tools.NewGetCryptocurrencyNewsTool(cryptoService)
-	calculateInvestmentFutureValueTool, _ := tools.NewCalculateInvestmentFutureValueTool()
-	getEarningsCallTranscriptTool, _ := tools.NewGetEarningsCallTranscriptTool(alphaVantageClient)
-	getInsiderTransactionsTool, _ := tools.NewGetInsiderTransactionsTool(alphaVantageClient)
-	getCompanyKpiMetricsTool, _ := tools.NewGetCompanyKpiMetricsTool(dataService)
-	getInvestingIdeasTool, _ := tools.NewGetInvestingIdeasTool(investingIdeasService)
-	getInvestingIdeaStocksTool, _ := tools.NewGetInvestingIdeaStocksTool(investingIdeasService)
-	getCurrencyExchangeRateTool, _ := tools.NewGetCurrencyExchangeRateTool(alphaVantageClient)
-
-	// Add tools
-	mcpServer.AddTool(
-		searchStocksTool.GetTool(),
-		mcp.NewStructuredToolHandler(searchStocksTool.HandleSearchStocks),
-	)
-
-	mcpServer.AddTool(
-		searchEtfsTool.GetTool(),
-		mcp.NewStructuredToolHandler(searchEtfsTool.HandleSearchEtfs),
-	)
-
-	mcpServer.AddTool(
-		getEtfTool.GetTool(),
-		mcp.NewStructuredToolHandler(getEtfTool.HandleGetEtf),
-	)
-
-	mcpServer.AddTool(
-		getSuperInvestorsTool.GetTool(),
-		mcp.NewStructuredToolHandler(getSuperInvestorsTool.HandleGetSuperInvestors),
-	)
-
-	mcpServer.AddTool(
-		getSuperInvestorPortfolioTool.GetTool(),
-		mcp.NewStructuredToolHandler(getSuperInvestorPortfolioTool.HandleGetSuperInvestorPortfolio),
-	)
-
-	mcpServer.AddTool(
-		getMarketNewsTool.GetTool(),
-		mcp.NewStructuredToolHandler(getMarketNewsTool.HandleGetNews),
-	)
-
-	mcpServer.AddTool(
-		getSectorsTool.GetTool(),
-		mcp.NewStructuredToolHandler(getSectorsTool.HandleGetSectors),
-	)
-
-	mcpServer.AddTool(
-		getSectorStocksTool.GetTool(),
-		mcp.NewStructuredToolHandler(getSectorStocksTool.HandleGetSectorStocks),
-	)
-
-	mcpServer.AddTool(
-		getStockOverviewTool.GetTool(),
-		mcp.NewStructuredToolHandler(getStockOverviewTool.HandleGetStockOverview),
-	)
-
-	mcpServer.AddTool(
-		getStockFinancialsTool.GetTool(),
-		mcp.NewStructuredToolHandler(getStockFinancialsTool.HandleGetStockFinancials),
-	)
-
-	mcpServer.AddTool(
-		getEconomicIndicatorTimeSeriesTool.GetTool(),
-		mcp.NewStructuredToolHandler(getEconomicIndicatorTimeSeriesTool.HandleGetEconomicIndicatorTimeSeries),
-	)
-
-	mcpServer.AddTool(
-		getCommodityTimeSeriesTool.GetTool(),
-		mcp.NewStructuredToolHandler(getCommodityTimeSeriesTool.HandleGetCommodityTimeSeries),
-	)
-
-	mcpServer.AddTool(
-		searchCryptocurrenciesTool.GetTool(),
-		mcp.NewStructuredToolHandler(searchCryptocurrenciesTool.HandleSearchCryptocurrencies),
-	)
-
-	mcpServer.AddTool(
-		getCryptocurrencyDataByIdTool.GetTool(),
-		mcp.NewStructuredToolHandler(getCryptocurrencyDataByIdTool.HandleGetCryptocurrencyDataById),
-	)
-
-	mcpServer.AddTool(
-		getCryptocurrencyNewsTool.GetTool(),
-		mcp.NewStructuredToolHandler(getCryptocurrencyNewsTool.HandleGetCryptocurrencyNews),
-	)
-
-	mcpServer.AddTool(
-		calculateInvestmentFutureValueTool.GetTool(),
-		mcp.NewStructuredToolHandler(calculateInvestmentFutureValueTool.HandleCalculateInvestmentFutureValue),
-	)
-
-	mcpServer.AddTool(
-		getEarningsCallTranscriptTool.GetTool(),
-		mcp.NewStructuredToolHandler(getEarningsCallTranscriptTool.HandleGetEarningsCallTranscript),
+	mcpApp := app.New(conf, cache,
+		server.WithToolHandlerMiddleware(loggingMW.ToolMiddleware),
+		server.WithToolHandlerMiddleware(usageTracker.ToolMiddleware),
 	)
+	completionMW := NewCompletionMiddleware(logger, mcpApp.Completer.HandleComplete)
 
-	mcpServer.AddTool(
-		getInsiderTransactionsTool.GetTool(),
-		mcp.NewStructuredToolHandler(getInsiderTransactionsTool.HandleGetInsiderTransactions),
-	)
+	// Setup the canary checking the scrapers for schema drift
+	canaryJob := setupCanary(conf, logger)
 
-	mcpServer.AddTool(
-		getCompanyKpiMetricsTool.GetTool(),
-		mcp.NewStructuredToolHandler(getCompanyKpiMetricsTool.HandleGetCompanyKpiMetrics),
-	)
+	// Setup the cache warmer
+	warmerEnabled := setupWarmer(conf, cache, usageTracker, logger)
 
-	mcpServer.AddTool(
-		getInvestingIdeasTool.GetTool(),
-		mcp.NewStructuredToolHandler(getInvestingIdeasTool.HandleGetInvestingIdeas),
-	)
-
-	mcpServer.AddTool(
-		getInvestingIdeaStocksTool.GetTool(),
-		mcp.NewStructuredToolHandler(getInvestingIdeaStocksTool.HandleGetInvestingIdeaStocks),
-	)
-
-	mcpServer.AddTool(
-		getCurrencyExchangeRateTool.GetTool(),
-		mcp.NewStructuredToolHandler(getCurrencyExchangeRateTool.HandleGetCurrencyExchangeRate),
-	)
+	// Schedule the evaluation of the alerts
+	setupAlerts(conf, mcpApp.Alerts, logger)
 
 	// Start the server
-	startWithGracefulShutdown(mcpServer, conf.Port)
+	startWithGracefulShutdown(mcpApp.MCPServer, completionMW, canaryJob, canaryJob != nil || warmerEnabled, conf.Port)
 }
 
-func startWithGracefulShutdown(mcpServer *server.MCPServer, port string) {
+func startWithGracefulShutdown(mcpServer *server.MCPServer, completionMW *CompletionMiddleware, canaryJob *canary.Job, serveMetrics bool, port string) {
 	// Setup signal handling
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -199,7 +66,15 @@ func startWithGracefulShutdown(mcpServer *server.MCPServer, port string) {
 
 	// Start server in goroutine
 	go func() {
-		httpServer = server.NewStreamableHTTPServer(mcpServer)
+		mux := http.NewServeMux()
+		httpServer = server.NewStreamableHTTPServer(mcpServer, server.WithStreamableHTTPServer(&http.Server{Handler: mux}))
+		mux.Handle("/mcp", completionMW.HTTPMiddleware(httpServer))
+		if canaryJob != nil {
+			mux.Handle("/canary", canaryJob)
+		}
+		if serveMetrics {
+			mux.Handle("/debug/vars", expvar.Handler())
+		}
 		if err := httpServer.Start(":" + port); err != nil {
 			log.Printf("Server error: %v", err)
 		}
@@ -219,3 +94,111 @@ func startWithGracefulShutdown(mcpServer *server.MCPServer, port string) {
 
 	log.Println("Server stopped")
 }
+
+// setupHttpReplay routes all the upstream requests through a cassette when recording or replaying is configured.
+// The scrapers and the API clients use the default transport, so replacing it covers all of them.
+func setupHttpReplay(conf config.Config, logger *log.Logger) {
+	switch {
+	case conf.HttpReplayPath != "":
+		replayer, err := httpreplay.NewReplayer(conf.HttpReplayPath)
+		if err != nil {
+			logger.Fatalf("Failed to load HTTP replay cassette: %v", err)
+		}
+		http.DefaultTransport = replayer
+		logger.Printf("Replaying upstream responses from %s", conf.HttpReplayPath)
+	case conf.HttpRecordPath != "":
+		recorder, err := httpreplay.NewRecorder(conf.HttpRecordPath, http.DefaultTransport)
+		if err != nil {
+			logger.Fatalf("Failed to create HTTP recorder: %v", err)
+		}
+		http.DefaultTransport = recorder
+		logger.Printf("Recording upstream responses to %s", conf.HttpRecordPath)
+	}
+}
+
+// setupCanary starts the background canary when an interval is configured. The canary runs the scrapers
+// without the cache and serves its last report on /canary and its metrics on /debug/vars.
+func setupCanary(conf config.Config, logger *log.Logger) *canary.Job {
+	if conf.CanaryIntervalMinutes <= 0 {
+		return nil
+	}
+
+	scraper := marketDataScraper.NewMarketDataScraper(marketDataScraper.BaseURLs{
+		StockAnalysis:    conf.StockAnalysisBaseURL,
+		StockAnalysisApi: conf.StockAnalysisApiBaseURL,
+		Dataroma:         conf.DataromaBaseURL,
+	})
+	reference := canary.DefaultReferenceSet
+	if len(conf.CanarySymbols) > 0 {
+		reference.Symbols = conf.CanarySymbols
+	}
+
+	job, err := canary.NewJob(scraper, reference, time.Duration(conf.CanaryIntervalMinutes)*time.Minute, conf.CanaryReportPath, logger)
+	if err != nil {
+		logger.Fatalf("Failed to create the canary: %v", err)
+	}
+	job.Start(context.Background())
+	logger.Printf("Canary running every %d minutes", conf.CanaryIntervalMinutes)
+	return job
+}
+
+// setupWarmer schedules the cache warmer when a schedule is configured and publishes its stats (coverage of
+// the warmed data, upstream calls and failures) on /debug/vars. It returns false when nothing is scheduled.
+func setupWarmer(conf config.Config, cache services.CacheService, usageTracker *warmer.UsageTracker, logger *log.Logger) bool {
+	if conf.WarmerMarketSchedule == "" && conf.WarmerSymbolsSchedule == "" {
+		return false
+	}
+
+	// The warmer scrapes into the cache of the tools, with the same keys and ttl
+	scraper := marketDataScraper.NewMarketDataScraperWithCache(warmer.RefreshCache(cache), conf)
+	w, err := warmer.NewWarmer(scraper, usageTracker, warmer.Config{
+		Watchlist:          conf.WarmerWatchlist,
+		TopSymbols:         conf.WarmerTopSymbols,
+		PerformancePeriods: tools.StockOverviewPerformancePeriods,
+		RatePerMinute:      conf.WarmerRatePerMinute,
+		CacheTtl:           time.Duration(conf.CacheTtl) * time.Second,
+	})
+	if err != nil {
+		logger.Fatalf("Failed to create the cache warmer: %v", err)
+	}
+	warmer.PublishMetrics(w)
+
+	jobs := scheduler.NewScheduler(logger)
+	for _, job := range []struct {
+		name string
+		spec string
+		run  func(ctx context.Context)
+	}{
+		{"warm_market", conf.WarmerMarketSchedule, w.WarmMarket},
+		{"warm_symbols", conf.WarmerSymbolsSchedule, w.WarmSymbols},
+	} {
+		if job.spec == "" {
+			continue
+		}
+		schedule, err := scheduler.Parse(job.spec)
+		if err != nil {
+			logger.Fatalf("Failed to schedule the cache warmer: %v", err)
+		}
+		// The cache is emptied on start, it's warmed right away
+		jobs.Add(scheduler.Job{Name: job.name, Schedule: schedule, Run: job.run, RunAtStart: true})
+		logger.Printf("Cache warmer job %s scheduled on %q", job.name, job.spec)
+	}
+	jobs.Start(context.Background())
+	return true
+}
+
+// setupAlerts schedules the evaluation of the alerts of all the users when a schedule is configured
+func setupAlerts(conf config.Config, alerts *services.AlertEngine, logger *log.Logger) {
+	if conf.AlertsSchedule == "" {
+		return
+	}
+
+	schedule, err := scheduler.Parse(conf.AlertsSchedule)
+	if err != nil {
+		logger.Fatalf("Failed to schedule the alerts: %v", err)
+	}
+	jobs := scheduler.NewScheduler(logger)
+	jobs.Add(scheduler.Job{Name: "evaluate_alerts", Schedule: schedule, Run: alerts.EvaluateAll})
+	jobs.Start(context.Background())
+	logger.Printf("Alerts evaluated on %q", conf.AlertsSchedule)
+}
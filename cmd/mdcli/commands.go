@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"market_data_mcp_server/pkg/api/mcp/tools"
+	"market_data_mcp_server/pkg/app"
+	"market_data_mcp_server/pkg/domain"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// command mirrors one or several tools, it calls their handlers with the arguments of the command line
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, t *app.Tools, flags *flag.FlagSet, args []string) (output, error)
+}
+
+var commands = []command{
+	{
+		name:  "search",
+		usage: "search [-types stock,etf,...] [-limit n] <query>",
+		run:   runSearch,
+	},
+	{
+		name:  "overview",
+		usage: "overview <symbol>",
+		run:   runOverview,
+	},
+	{
+		name:  "financials",
+		usage: "financials [-statement balance|income|cash] [-limit n] <symbol>",
+		run:   runFinancials,
+	},
+	{
+		name:  "etf",
+		usage: "etf <symbol>",
+		run:   runEtf,
+	},
+	{
+		name:  "crypto",
+		usage: "crypto search <query> | crypto get <id> | crypto news <symbol>",
+		run:   runCrypto,
+	},
+	{
+		name:  "macro",
+		usage: "macro [-maturity 10Y] [-limit n] <indicator|commodity> | macro fx <from> <to>",
+		run:   runMacro,
+	},
+	{
+		name:  "investors",
+		usage: "investors [super investor name]",
+		run:   runInvestors,
+	},
+	{
+		name:  "news",
+		usage: "news [symbol]",
+		run:   runNews,
+	},
+	{
+		name:  "ideas",
+		usage: "ideas [idea id]",
+		run:   runIdeas,
+	},
+}
+
+var commodities = []domain.Commodity{
+	domain.CrudeOil, domain.NaturalGas, domain.Copper, domain.Aluminum, domain.Wheat, domain.Corn, domain.Sugar, domain.Coffee,
+}
+
+func runSearch(ctx context.Context, t *app.Tools, flags *flag.FlagSet, args []string) (output, error) {
+	types := flags.String("types", "", "Comma separated result types (stock, etf, crypto, super_investor, sector, industry, investing_idea)")
+	limit := flags.Int("limit", 20, "Maximum results")
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return output{}, err
+	}
+
+	request := tools.SearchRequest{Query: strings.Join(args, " "), Limit: *limit}
+	if *types != "" {
+		request.Types = strings.Split(*types, ",")
+	}
+
+	response, err := t.Search.HandleSearch(ctx, mcp.CallToolRequest{}, request)
+	return output{response: response, records: response.Results}, err
+}
+
+func runOverview(ctx context.Context, t *app.Tools, flags *flag.FlagSet, args []string) (output, error) {
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return output{}, err
+	}
+
+	response, err := t.GetStockOverview.HandleGetStockOverview(ctx, mcp.CallToolRequest{}, tools.GetStockOverviewRequest{StockSymbol: args[0]})
+	return output{response: response}, err
+}
+
+func runFinancials(ctx context.Context, t *app.Tools, flags *flag.FlagSet, args []string) (output, error) {
+	statement := flags.String("statement", "balance", "Financial statement: balance, income or cash")
+	limit := flags.Int("limit", 4, "Number of quarters starting from the most recent one, all when 0")
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return output{}, err
+	}
+
+	request := tools.GetStockFinancialsRequest{StockSymbol: args[0], Limit: *limit}
+	switch *statement {
+	case "balance":
+		request.IncludeBalanceSheets = true
+	case "income":
+		request.IncludeIncomeStatements = true
+	case "cash":
+		request.IncludeCashFlows = true
+	default:
+		return output{}, fmt.Errorf("unknown statement %s, expected balance, income or cash", *statement)
+	}
+
+	response, err := t.GetStockFinancials.HandleGetStockFinancials(ctx, mcp.CallToolRequest{}, request)
+
+	// The line items are the rows and the quarters the columns, as in the published statements
+	out := output{response: response, transpose: true}
+	switch *statement {
+	case "balance":
+		out.records = response.BalanceSheets
+	case "income":
+		out.records = response.IncomeStatements
+	case "cash":
+		out.records = response.CashFlows
+	}
+	return out, err
+}
+
+func runEtf(ctx context.Context, t *app.Tools, flags *flag.FlagSet, args []string) (output, error) {
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return output{}, err
+	}
+
+	response, err := t.GetEtf.HandleGetEtf(ctx, mcp.CallToolRequest{}, tools.GetEtfRequest{EtfSymbol: args[0]})
+	return output{response: response}, err
+}
+
+func runCrypto(ctx context.Context, t *app.Tools, flags *flag.FlagSet, args []string) (output, error) {
+	limit := flags.Int("limit", 20, "Maximum results of the search")
+	args, err := parseArgs(flags, args, 2)
+	if err != nil {
+		return output{}, err
+	}
+
+	switch action, subject := args[0], strings.Join(args[1:], " "); action {
+	case "search":
+		response, err := t.SearchCryptocurrencies.HandleSearchCryptocurrencies(ctx, mcp.CallToolRequest{}, tools.SearchCryptocurrenciesRequest{SearchQuery: subject, Limit: *limit})
+		return output{response: response, records: response.Results}, err
+	case "get":
+		response, err := t.GetCryptocurrencyDataById.HandleGetCryptocurrencyDataById(ctx, mcp.CallToolRequest{}, tools.GetCryptocurrencyDataByIdRequest{Id: subject})
+		return output{response: response}, err
+	case "news":
+		response, err := t.GetCryptocurrencyNews.HandleGetCryptocurrencyNews(ctx, mcp.CallToolRequest{}, tools.GetCryptocurrencyNewsRequest{Symbol: subject})
+		return output{response: response, records: response.News}, err
+	default:
+		return output{}, fmt.Errorf("unknown crypto action %s, expected search, get or news", action)
+	}
+}
+
+func runMacro(ctx context.Context, t *app.Tools, flags *flag.FlagSet, args []string) (output, error) {
+	maturity := flags.String("maturity", "", "Maturity of the treasury yield (3m, 2Y, 5Y, 10Y, 30Y)")
+	limit := flags.Int("limit", 12, "Number of data entries starting from the most recent one")
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return output{}, err
+	}
+
+	if args[0] == "fx" {
+		if len(args) != 3 {
+			return output{}, fmt.Errorf("usage: macro fx <from> <to>")
+		}
+		request := tools.GetCurrencyExchangeRateRequest{FromCurrency: strings.ToUpper(args[1]), ToCurrency: strings.ToUpper(args[2])}
+		response, err := t.GetCurrencyExchangeRate.HandleGetCurrencyExchangeRate(ctx, mcp.CallToolRequest{}, request)
+		return output{response: response}, err
+	}
+
+	if slices.Contains(commodities, domain.Commodity(args[0])) {
+		request := tools.GetCommodityTimeSeriesRequest{CommodityName: args[0], Limit: *limit}
+		response, err := t.GetCommodityTimeSeries.HandleGetCommodityTimeSeries(ctx, mcp.CallToolRequest{}, request)
+		return output{response: response, records: response.Data}, err
+	}
+
+	request := tools.GetEconomicIndicatorTimeSeriesRequest{IndicatorName: args[0], TreasuryYieldMaturity: *maturity, Limit: *limit}
+	response, err := t.GetEconomicIndicatorTimeSeries.HandleGetEconomicIndicatorTimeSeries(ctx, mcp.CallToolRequest{}, request)
+	return output{response: response, records: response.Data}, err
+}
+
+func runInvestors(ctx context.Context, t *app.Tools, flags *flag.FlagSet, args []string) (output, error) {
+	args, err := parseArgs(flags, args, 0)
+	if err != nil {
+		return output{}, err
+	}
+
+	if len(args) == 0 {
+		response, err := t.GetSuperInvestors.HandleGetSuperInvestors(ctx, mcp.CallToolRequest{}, tools.GetSuperInvestorsRequest{})
+		return output{response: response, records: response.SuperInvestors}, err
+	}
+
+	request := tools.GetSuperInvestorPortfolioRequest{SuperInvestorName: strings.Join(args, " ")}
+	response, err := t.GetSuperInvestorPortfolio.HandleGetSuperInvestorPortfolio(ctx, mcp.CallToolRequest{}, request)
+	return output{response: response, records: response.Holdings}, err
+}
+
+func runNews(ctx context.Context, t *app.Tools, flags *flag.FlagSet, args []string) (output, error) {
+	args, err := parseArgs(flags, args, 0)
+	if err != nil {
+		return output{}, err
+	}
+
+	request := tools.GetMarketNewsRequest{}
+	if len(args) > 0 {
+		request.StockSymbol = args[0]
+	}
+
+	response, err := t.GetMarketNews.HandleGetNews(ctx, mcp.CallToolRequest{}, request)
+	return output{response: response, records: response.News}, err
+}
+
+func runIdeas(ctx context.Context, t *app.Tools, flags *flag.FlagSet, args []string) (output, error) {
+	args, err := parseArgs(flags, args, 0)
+	if err != nil {
+		return output{}, err
+	}
+
+	if len(args) == 0 {
+		response, err := t.GetInvestingIdeas.HandleGetInvestingIdeas(ctx, mcp.CallToolRequest{}, tools.GetInvestingIdeasRequest{})
+		return output{response: response, records: response.InvestingIdeas}, err
+	}
+
+	response, err := t.GetInvestingIdeaStocks.HandleGetInvestingIdeaStocks(ctx, mcp.CallToolRequest{}, tools.GetInvestingIdeaStocksRequest{IdeaID: args[0]})
+	return output{response: response, records: response.Stocks}, err
+}
+
+// parseArgs parses the flags wherever they are among the arguments (e.g. "financials aapl -statement cash")
+// and returns the positional arguments, an error when there are less than min of them
+func parseArgs(flags *flag.FlagSet, args []string, min int) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if len(positional) < min {
+		return nil, fmt.Errorf("missing arguments")
+	}
+	return positional, nil
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// output is what a command prints: the tool response in json, the records of the response in table and csv
+type output struct {
+	response any
+	// records is the part of the response printed in table and csv, the whole response when nil
+	records any
+	// transpose prints the fields as rows and the records as columns (e.g. the periods of financial statements)
+	transpose bool
+}
+
+func render(w io.Writer, format string, out output) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out.response)
+	case FormatTable, FormatCSV:
+		value := out.records
+		if value == nil {
+			value = out.response
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		generic, err := decodeOrdered(json.NewDecoder(bytes.NewReader(data)))
+		if err != nil {
+			return err
+		}
+		if format == FormatCSV {
+			return renderCSV(w, generic, out.transpose)
+		}
+		return renderTable(w, generic, out.transpose)
+	default:
+		return fmt.Errorf("unknown format %s, expected table, json or csv", format)
+	}
+}
+
+// renderTable prints a list as a table and an object as a field/value table followed by a table per list of objects
+func renderTable(w io.Writer, value any, transpose bool) error {
+	obj, ok := value.(*object)
+	if !ok {
+		return writeTable(w, tabulate(value, transpose))
+	}
+
+	var lists []string
+	fields := &object{values: make(map[string]any)}
+	for _, name := range obj.names {
+		if isObjectList(obj.values[name]) {
+			lists = append(lists, name)
+		} else {
+			fields.set(name, obj.values[name])
+		}
+	}
+
+	if len(fields.names) > 0 {
+		if err := writeTable(w, tabulate(fields, false)); err != nil {
+			return err
+		}
+	}
+	for _, name := range lists {
+		fmt.Fprintf(w, "\n%s\n", strings.ToUpper(name))
+		if err := writeTable(w, tabulate(obj.values[name], transpose)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderCSV(w io.Writer, value any, transpose bool) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(tabulate(value, transpose)); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func writeTable(w io.Writer, rows [][]string) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		// A line break or a tab in a cell would break the alignment
+		cells := make([]string, len(row))
+		for i, c := range row {
+			cells[i] = strings.Join(strings.Fields(c), " ")
+		}
+		fmt.Fprintln(writer, strings.Join(cells, "\t"))
+	}
+	return writer.Flush()
+}
+
+// tabulate returns the rows of a value, the first one being the header:
+// a list of objects has a column per field, an object a row per field and a list of scalars a single column
+func tabulate(value any, transpose bool) [][]string {
+	switch value := value.(type) {
+	case []any:
+		if !isObjectList(value) {
+			rows := [][]string{{"value"}}
+			for _, item := range value {
+				rows = append(rows, []string{cell(item)})
+			}
+			return rows
+		}
+
+		var columns []string
+		seen := make(map[string]bool)
+		records := make([]map[string]string, 0, len(value))
+		for _, item := range value {
+			record := make(map[string]string)
+			flatten(item, "", record, func(key string) {
+				if !seen[key] {
+					seen[key] = true
+					columns = append(columns, key)
+				}
+			})
+			records = append(records, record)
+		}
+
+		rows := [][]string{columns}
+		for _, record := range records {
+			row := make([]string, len(columns))
+			for i, column := range columns {
+				row[i] = record[column]
+			}
+			rows = append(rows, row)
+		}
+		if transpose {
+			return transposed(rows)
+		}
+		return rows
+	case *object:
+		var keys []string
+		record := make(map[string]string)
+		flatten(value, "", record, func(key string) { keys = append(keys, key) })
+
+		rows := [][]string{{"field", "value"}}
+		for _, key := range keys {
+			rows = append(rows, []string{key, record[key]})
+		}
+		return rows
+	default:
+		return [][]string{{"value"}, {cell(value)}}
+	}
+}
+
+// flatten adds the scalar fields of the value to the record, the keys of nested fields are dotted
+// and the keys of the items of nested lists are indexed (e.g. "holdings[0].symbol")
+func flatten(value any, prefix string, record map[string]string, added func(key string)) {
+	switch value := value.(type) {
+	case *object:
+		for _, name := range value.names {
+			key := name
+			if prefix != "" {
+				key = prefix + "." + name
+			}
+			flatten(value.values[name], key, record, added)
+		}
+	case []any:
+		if !isObjectList(value) {
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				items = append(items, cell(item))
+			}
+			record[prefix] = strings.Join(items, ", ")
+			added(prefix)
+			return
+		}
+		for i, item := range value {
+			flatten(item, fmt.Sprintf("%s[%d]", prefix, i), record, added)
+		}
+	default:
+		record[prefix] = cell(value)
+		added(prefix)
+	}
+}
+
+func transposed(rows [][]string) [][]string {
+	if len(rows) == 0 {
+		return rows
+	}
+	result := make([][]string, len(rows[0]))
+	for i := range rows[0] {
+		result[i] = make([]string, len(rows))
+		for j, row := range rows {
+			result[i][j] = row[i]
+		}
+	}
+	return result
+}
+
+func isObjectList(value any) bool {
+	items, ok := value.([]any)
+	if !ok || len(items) == 0 {
+		return false
+	}
+	_, ok = items[0].(*object)
+	return ok
+}
+
+// object is a JSON object that keeps the order of its fields, so that the columns are in the order of the response
+type object struct {
+	names  []string
+	values map[string]any
+}
+
+func (o *object) set(name string, value any) {
+	if _, ok := o.values[name]; !ok {
+		o.names = append(o.names, name)
+	}
+	o.values[name] = value
+}
+
+// decodeOrdered decodes the next JSON value, the objects into *object and the rest as encoding/json does
+func decodeOrdered(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		obj := &object{values: make(map[string]any)}
+		for decoder.More() {
+			name, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(name.(string), value)
+		}
+		_, err := decoder.Token()
+		return obj, err
+	case json.Delim('['):
+		items := []any{}
+		for decoder.More() {
+			item, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err := decoder.Token()
+		return items, err
+	default:
+		return token, nil
+	}
+}
+
+func cell(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,112 @@
+// mdcli prints market data from a terminal, without an MCP client: each command mirrors one or several tools
+// and calls them on top of the same services and cache as the server, which also makes it handy to debug the
+// output of the scrapers. The output is a table, JSON (the tool response as is) or CSV:
+//
+//	mdcli financials aapl -statement income -format csv
+//	mdcli investors "Warren Buffett - Berkshire Hathaway"
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"market_data_mcp_server/pkg/app"
+	"market_data_mcp_server/pkg/config"
+	"market_data_mcp_server/pkg/services"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run runs the command and returns the exit status, the deferred calls (e.g. closing the cache) are run before
+// the process exits
+func run() int {
+	logger := log.New(os.Stderr, "[MDCLI] ", 0)
+
+	global := flag.NewFlagSet("mdcli", flag.ExitOnError)
+	format := global.String("format", FormatTable, "Output format: table, json or csv")
+	cachePath := global.String("cache", defaultCachePath(), "Directory of the cache kept between runs, no cache when empty")
+	timeout := global.Duration("timeout", 2*time.Minute, "Maximum duration of the command")
+	global.Usage = func() { usage(global) }
+	global.Parse(os.Args[1:])
+
+	if global.NArg() == 0 {
+		usage(global)
+		return 2
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == global.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		logger.Printf("Unknown command %s", global.Arg(0))
+		usage(global)
+		return 2
+	}
+
+	// The format can also be given after the command. The flags are parsed once the cache is open, an invalid
+	// flag is returned as an error rather than exiting.
+	flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	flags.StringVar(format, "format", *format, "Output format: table, json or csv")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: mdcli [flags] %s\n", cmd.usage)
+		flags.PrintDefaults()
+	}
+
+	conf, _ := config.LoadConfig()
+
+	var cache services.CacheService = services.NewMemoryCacheService()
+	if *cachePath != "" {
+		badgerCache, err := services.OpenBadgerCacheService(*cachePath)
+		if err != nil {
+			// e.g. another run holds the cache, the command still works without it
+			logger.Printf("Failed to open the cache, running without it: %v", err)
+		} else {
+			defer badgerCache.Close()
+			cache = badgerCache
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	out, err := cmd.run(ctx, app.NewTools(conf, cache), flags, global.Args()[1:])
+	if err == nil {
+		err = render(os.Stdout, *format, out)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		logger.Printf("%s: %v", cmd.name, err)
+		return 1
+	}
+	return 0
+}
+
+func usage(global *flag.FlagSet) {
+	fmt.Fprintf(global.Output(), "Usage: mdcli [flags] <command> [command flags] [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(global.Output(), "  %s\n", cmd.usage)
+	}
+	fmt.Fprintf(global.Output(), "\nFlags:\n")
+	global.PrintDefaults()
+}
+
+// defaultCachePath is in the user cache directory, apart from the cache of the server which is reset on start
+func defaultCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "market_data_mcp_server", "mdcli")
+}
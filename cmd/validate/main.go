@@ -0,0 +1,91 @@
+// validate calls every tool of the MCP server and checks their structured outputs against the output schemas
+// the tools declare and against semantic rules. The server is started in-process, against the fake upstreams
+// with -fake-upstreams, or a running server is validated with -url. The exit status is 1 when a case fails.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"market_data_mcp_server/pkg/app"
+	"market_data_mcp_server/pkg/config"
+	"market_data_mcp_server/pkg/fakeupstreams"
+	"market_data_mcp_server/pkg/services"
+	"market_data_mcp_server/pkg/validate"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func main() {
+	url := flag.String("url", "", "URL of the MCP endpoint of a running server (e.g. http://localhost:8080/mcp), in-process when empty")
+	useFakeUpstreams := flag.Bool("fake-upstreams", false, "Run the in-process server against the fake upstreams")
+	casesPath := flag.String("cases", "", "JSON file of the cases to run, the default cases when empty")
+	timeout := flag.Duration("timeout", 10*time.Minute, "Maximum duration of the run")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "[VALIDATE] ", log.LstdFlags)
+
+	cases := validate.DefaultCases
+	if *casesPath != "" {
+		var err error
+		if cases, err = validate.LoadCases(*casesPath); err != nil {
+			logger.Fatalf("Failed to load the cases: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var mcpClient *client.Client
+	var err error
+	if *url != "" {
+		mcpClient, err = client.NewStreamableHttpClient(*url)
+	} else {
+		conf, _ := config.LoadConfig()
+		// The user contexts, the transactions, the watchlists and the alerts written by the cases are only kept in memory
+		conf.UserContextDataPath = ""
+		conf.TransactionsDataPath = ""
+		conf.WatchlistsDataPath = ""
+		conf.AlertsDataPath = ""
+		if *useFakeUpstreams {
+			upstreams := httptest.NewServer(fakeupstreams.NewHandler())
+			defer upstreams.Close()
+			conf.StockAnalysisBaseURL = upstreams.URL + fakeupstreams.StockAnalysisPrefix
+			conf.StockAnalysisApiBaseURL = upstreams.URL + fakeupstreams.StockAnalysisApiPrefix
+			conf.DataromaBaseURL = upstreams.URL + fakeupstreams.DataromaPrefix
+			conf.AlphaVantageBaseURL = upstreams.URL + fakeupstreams.AlphaVantagePrefix + "/query"
+			conf.CoinGeckoBaseURL = upstreams.URL + fakeupstreams.CoinGeckoPrefix + "/api/v3"
+		}
+		mcpClient, err = client.NewInProcessClient(app.New(conf, services.NewMemoryCacheService()).MCPServer)
+	}
+	if err != nil {
+		logger.Fatalf("Failed to create the client: %v", err)
+	}
+	defer mcpClient.Close()
+
+	if err := mcpClient.Start(ctx); err != nil {
+		logger.Fatalf("Failed to start the client: %v", err)
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "validate", Version: app.Version}
+	if _, err := mcpClient.Initialize(ctx, initRequest); err != nil {
+		logger.Fatalf("Failed to initialize the session: %v", err)
+	}
+
+	report, err := validate.Run(ctx, mcpClient, cases)
+	if err != nil {
+		logger.Fatalf("Validation failed: %v", err)
+	}
+
+	validate.PrintReport(os.Stdout, report)
+
+	if !report.Passed() {
+		os.Exit(1)
+	}
+}
@@ -1,24 +1,30 @@
 package alphavantage
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"market_data_mcp_server/pkg/domain"
 	"market_data_mcp_server/pkg/errors"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type AlphaVantageClient struct {
-	apiKey string
+	apiKey  string
+	baseURL string
 }
 
-const alphaVantageBaseURL = "https://www.alphavantage.co/query"
+// DefaultBaseURL is the url of the Alpha Vantage query endpoint
+const DefaultBaseURL = "https://www.alphavantage.co/query"
 
-func NewAlphaVantageClient(apiKey string) (*AlphaVantageClient, error) {
-	return &AlphaVantageClient{apiKey: apiKey}, nil
+// NewAlphaVantageClient creates a client that sends its requests to the given baseURL (DefaultBaseURL when empty)
+func NewAlphaVantageClient(apiKey string, baseURL string) (*AlphaVantageClient, error) {
+	return &AlphaVantageClient{apiKey: apiKey, baseURL: cmp.Or(baseURL, DefaultBaseURL)}, nil
 }
 
 func (c *AlphaVantageClient) GetRealGdpTimeSeries(interval domain.EconomicIndicatorInterval) (domain.EconomicIndicatorTimeSeries, error) {
@@ -29,7 +35,7 @@ func (c *AlphaVantageClient) GetRealGdpTimeSeries(interval domain.EconomicIndica
 	}
 
 	// Build URL with query parameters
-	requestUrl, err := url.Parse(alphaVantageBaseURL)
+	requestUrl, err := url.Parse(c.baseURL)
 	if err != nil {
 		return domain.EconomicIndicatorTimeSeries{}, &errors.HTTPError{
 			StatusCode: 0,
@@ -113,7 +119,7 @@ func (c *AlphaVantageClient) GetTreasuryYieldTimeSeries(
 	apiInterval := "monthly"
 
 	// Build URL with query parameters
-	requestUrl, err := url.Parse(alphaVantageBaseURL)
+	requestUrl, err := url.Parse(c.baseURL)
 	if err != nil {
 		return domain.EconomicIndicatorTimeSeries{}, &errors.HTTPError{
 			StatusCode: 0,
@@ -206,7 +212,7 @@ func (c *AlphaVantageClient) GetInterestRatesTimeSeries() (domain.EconomicIndica
 	apiInterval := "monthly"
 
 	// Build URL with query parameters
-	requestUrl, err := url.Parse(alphaVantageBaseURL)
+	requestUrl, err := url.Parse(c.baseURL)
 	if err != nil {
 		return domain.EconomicIndicatorTimeSeries{}, &errors.HTTPError{
 			StatusCode: 0,
@@ -280,7 +286,7 @@ func (c *AlphaVantageClient) GetInterestRatesTimeSeries() (domain.EconomicIndica
 // GetInflationTimeSeries returns the annual inflation time series
 func (c *AlphaVantageClient) GetInflationTimeSeries() (domain.EconomicIndicatorTimeSeries, error) {
 	// Build URL with query parameters
-	requestUrl, err := url.Parse(alphaVantageBaseURL)
+	requestUrl, err := url.Parse(c.baseURL)
 	if err != nil {
 		return domain.EconomicIndicatorTimeSeries{}, &errors.HTTPError{
 			StatusCode: 0,
@@ -353,7 +359,7 @@ func (c *AlphaVantageClient) GetInflationTimeSeries() (domain.EconomicIndicatorT
 // GetUnemploymentRateTimeSeries returns the monthly unemployment rate time series
 func (c *AlphaVantageClient) GetUnemploymentRateTimeSeries() (domain.EconomicIndicatorTimeSeries, error) {
 	// Build URL with query parameters
-	requestUrl, err := url.Parse(alphaVantageBaseURL)
+	requestUrl, err := url.Parse(c.baseURL)
 	if err != nil {
 		return domain.EconomicIndicatorTimeSeries{}, &errors.HTTPError{
 			StatusCode: 0,
@@ -479,7 +485,7 @@ func (c *AlphaVantageClient) GetCommodityTimeSeries(commodity domain.Commodity)
 }
 
 func (c *AlphaVantageClient) fetchCommodityData(function string, interval string) (CommodityTimeSeriesResponse, error) {
-	requestUrl, err := url.Parse(alphaVantageBaseURL)
+	requestUrl, err := url.Parse(c.baseURL)
 	if err != nil {
 		return CommodityTimeSeriesResponse{}, &errors.HTTPError{
 			StatusCode: 0,
@@ -531,7 +537,7 @@ func (c *AlphaVantageClient) fetchCommodityData(function string, interval string
 
 func (c *AlphaVantageClient) GetCryptocurrencyNews(symbol string) ([]domain.NewsArticle, error) {
 	// Build URL with query parameters
-	requestUrl, err := url.Parse(alphaVantageBaseURL)
+	requestUrl, err := url.Parse(c.baseURL)
 	if err != nil {
 		return nil, &errors.HTTPError{
 			StatusCode: 0,
@@ -599,7 +605,7 @@ func (c *AlphaVantageClient) GetCryptocurrencyNews(symbol string) ([]domain.News
 }
 
 func (c *AlphaVantageClient) GetEarningsCallTranscript(symbol string, year int, quarter domain.Quarter) ([]domain.EarningsCallTranscript, error) {
-	requestUrl, err := url.Parse(alphaVantageBaseURL)
+	requestUrl, err := url.Parse(c.baseURL)
 	if err != nil {
 		return nil, &errors.HTTPError{
 			StatusCode: 0,
@@ -661,7 +667,7 @@ func (c *AlphaVantageClient) GetEarningsCallTranscript(symbol string, year int,
 }
 
 func (c *AlphaVantageClient) GetInsiderTransactions(symbol string) ([]domain.InsiderTransaction, error) {
-	requestUrl, err := url.Parse(alphaVantageBaseURL)
+	requestUrl, err := url.Parse(c.baseURL)
 	if err != nil {
 		return nil, &errors.HTTPError{
 			StatusCode: 0,
@@ -786,7 +792,7 @@ func (c *AlphaVantageClient) GetCurrencyExchangeRate(fromCurrency domain.Currenc
 	}
 
 	// Build URL with query parameters
-	requestUrl, err := url.Parse(alphaVantageBaseURL)
+	requestUrl, err := url.Parse(c.baseURL)
 	if err != nil {
 		return domain.CurrencyExchangeRate{}, &errors.HTTPError{
 			StatusCode: 0,
@@ -867,3 +873,89 @@ func (c *AlphaVantageClient) GetCurrencyExchangeRate(fromCurrency domain.Currenc
 	}, nil
 }
 
+// GetStockTimeSeries returns the daily (the last 100 trading days) or the weekly (the whole history) bars of a stock
+// or an ETF, the oldest first
+func (c *AlphaVantageClient) GetStockTimeSeries(symbol string, interval domain.PriceInterval) ([]domain.Price, error) {
+	var function string
+	switch interval {
+	case domain.PriceIntervalDaily:
+		function = "TIME_SERIES_DAILY"
+	case domain.PriceIntervalWeekly:
+		function = "TIME_SERIES_WEEKLY"
+	default:
+		return nil, fmt.Errorf("unsupported interval: %s", interval)
+	}
+
+	requestUrl, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, &errors.HTTPError{
+			StatusCode: 0,
+			Message:    fmt.Sprintf("failed to parse base URL: %v", err),
+		}
+	}
+
+	q := requestUrl.Query()
+	q.Set("function", function)
+	q.Set("symbol", symbol)
+	q.Set("apikey", c.apiKey)
+	requestUrl.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", requestUrl.String(), nil)
+	if err != nil {
+		return nil, &errors.HTTPError{
+			StatusCode: 0,
+			Message:    fmt.Sprintf("failed to create HTTP request: %v", err),
+		}
+	}
+
+	httpClient := &http.Client{}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, &errors.HTTPError{
+			StatusCode: 0,
+			Message:    fmt.Sprintf("failed to send HTTP request: %v", err),
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &errors.HTTPError{
+			StatusCode: resp.StatusCode,
+			Message:    resp.Status,
+		}
+	}
+
+	var apiResponse StockTimeSeriesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
+		return nil, &errors.JSONMarshalError{
+			Message: "failed to decode JSON response",
+			Err:     err,
+		}
+	}
+
+	series := apiResponse.Daily
+	if interval == domain.PriceIntervalWeekly {
+		series = apiResponse.Weekly
+	}
+	// Alpha Vantage answers the errors and the rate limits with a 200 and a message instead of the series
+	if len(series) == 0 {
+		return nil, fmt.Errorf("API response is missing the time series of %s, might be an error from API", symbol)
+	}
+
+	prices := make([]domain.Price, 0, len(series))
+	for date, entry := range series {
+		day, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse date %s: %v", date, err)
+		}
+		open, _ := strconv.ParseFloat(entry.Open, 64)
+		high, _ := strconv.ParseFloat(entry.High, 64)
+		low, _ := strconv.ParseFloat(entry.Low, 64)
+		closePrice, _ := strconv.ParseFloat(entry.Close, 64)
+		volume, _ := strconv.ParseFloat(entry.Volume, 64)
+		prices = append(prices, domain.Price{Date: day, ClosePrice: closePrice, Open: open, High: high, Low: low, Volume: volume})
+	}
+	slices.SortFunc(prices, func(a, b domain.Price) int { return a.Date.Compare(b.Date) })
+
+	return prices, nil
+}
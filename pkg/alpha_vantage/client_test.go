@@ -0,0 +1,65 @@
+package alphavantage
+
+import (
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/httpreplay"
+	"path/filepath"
+	"testing"
+)
+
+func TestAlphaVantageClient(t *testing.T) {
+	client, err := NewAlphaVantageClient("test-key", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() (any, error)
+	}{
+		{"real_gdp", func() (any, error) {
+			return client.GetRealGdpTimeSeries(domain.QuarterlyEconomicIndicatorInterval)
+		}},
+		{"treasury_yield", func() (any, error) {
+			return client.GetTreasuryYieldTimeSeries(domain.TenYearTreasuryYieldMaturity)
+		}},
+		{"interest_rates", func() (any, error) { return client.GetInterestRatesTimeSeries() }},
+		{"inflation", func() (any, error) { return client.GetInflationTimeSeries() }},
+		{"unemployment_rate", func() (any, error) { return client.GetUnemploymentRateTimeSeries() }},
+		{"commodity", func() (any, error) { return client.GetCommodityTimeSeries(domain.CrudeOil) }},
+		{"cryptocurrency_news", func() (any, error) { return client.GetCryptocurrencyNews("BTC") }},
+		{"earnings_call_transcript", func() (any, error) {
+			return client.GetEarningsCallTranscript("IBM", 2024, domain.Q1Quarter)
+		}},
+		{"insider_transactions", func() (any, error) { return client.GetInsiderTransactions("IBM") }},
+		{"currency_exchange_rate", func() (any, error) { return client.GetCurrencyExchangeRate(domain.USD, domain.EUR) }},
+		{"stock_time_series", func() (any, error) { return client.GetStockTimeSeries("IBM", domain.PriceIntervalDaily) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreplay.UseCassette(t, filepath.Join("testdata", "cassettes", tt.name+".json"))
+
+			got, err := tt.call()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			httpreplay.AssertGolden(t, filepath.Join("testdata", "golden", tt.name+".json"), got)
+		})
+	}
+}
+
+// Alpha Vantage answers with a 200 and an "Information" message when the rate limit is hit
+func TestGetCurrencyExchangeRateRateLimited(t *testing.T) {
+	httpreplay.UseCassette(t, filepath.Join("testdata", "cassettes", "currency_exchange_rate_rate_limited.json"))
+
+	client, err := NewAlphaVantageClient("test-key", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.GetCurrencyExchangeRate(domain.USD, domain.JPY); err == nil {
+		t.Fatal("expected an error for a rate limited response")
+	}
+}
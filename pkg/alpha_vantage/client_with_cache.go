@@ -1,6 +1,7 @@
 package alphavantage
 
 import (
+	"cmp"
 	"fmt"
 	"market_data_mcp_server/pkg/domain"
 	"market_data_mcp_server/pkg/services"
@@ -9,12 +10,14 @@ import (
 
 type AlphaVantageClientWithCache struct {
 	apiKey          string
+	baseURL         string
 	cache           services.CacheService
 	cacheTtlSeconds int
 }
 
-func NewAlphaVantageClientWithCache(apiKey string, cache services.CacheService, cacheTtlSeconds int) (*AlphaVantageClientWithCache, error) {
-	return &AlphaVantageClientWithCache{apiKey: apiKey, cache: cache, cacheTtlSeconds: cacheTtlSeconds}, nil
+func NewAlphaVantageClientWithCache(apiKey string, baseURL string, cache services.CacheService, cacheTtlSeconds int) (*AlphaVantageClientWithCache, error) {
+	baseURL = cmp.Or(baseURL, DefaultBaseURL)
+	return &AlphaVantageClientWithCache{apiKey: apiKey, baseURL: baseURL, cache: services.NamespacedCache(cache, baseURL), cacheTtlSeconds: cacheTtlSeconds}, nil
 }
 
 func (c *AlphaVantageClientWithCache) GetRealGdpTimeSeries(interval domain.EconomicIndicatorInterval) (domain.EconomicIndicatorTimeSeries, error) {
@@ -28,7 +31,7 @@ func (c *AlphaVantageClientWithCache) GetRealGdpTimeSeries(interval domain.Econo
 	}
 
 	// If not in cache, get from API
-	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey}
+	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey, baseURL: c.baseURL}
 	economicIndicatorTimeSeries, err = alphaVantageClient.GetRealGdpTimeSeries(interval)
 	if err != nil {
 		return domain.EconomicIndicatorTimeSeries{}, err
@@ -51,7 +54,7 @@ func (c *AlphaVantageClientWithCache) GetTreasuryYieldTimeSeries(maturity domain
 	}
 
 	// If not in cache, get from API
-	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey}
+	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey, baseURL: c.baseURL}
 	economicIndicatorTimeSeries, err = alphaVantageClient.GetTreasuryYieldTimeSeries(maturity)
 	if err != nil {
 		return domain.EconomicIndicatorTimeSeries{}, err
@@ -74,7 +77,7 @@ func (c *AlphaVantageClientWithCache) GetInterestRatesTimeSeries() (domain.Econo
 	}
 
 	// If not in cache, get from API
-	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey}
+	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey, baseURL: c.baseURL}
 	economicIndicatorTimeSeries, err = alphaVantageClient.GetInterestRatesTimeSeries()
 	if err != nil {
 		return domain.EconomicIndicatorTimeSeries{}, err
@@ -97,7 +100,7 @@ func (c *AlphaVantageClientWithCache) GetInflationTimeSeries() (domain.EconomicI
 	}
 
 	// If not in cache, get from API
-	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey}
+	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey, baseURL: c.baseURL}
 	economicIndicatorTimeSeries, err = alphaVantageClient.GetInflationTimeSeries()
 	if err != nil {
 		return domain.EconomicIndicatorTimeSeries{}, err
@@ -120,7 +123,7 @@ func (c *AlphaVantageClientWithCache) GetUnemploymentRateTimeSeries() (domain.Ec
 	}
 
 	// If not in cache, get from API
-	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey}
+	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey, baseURL: c.baseURL}
 	economicIndicatorTimeSeries, err = alphaVantageClient.GetUnemploymentRateTimeSeries()
 	if err != nil {
 		return domain.EconomicIndicatorTimeSeries{}, err
@@ -143,7 +146,7 @@ func (c *AlphaVantageClientWithCache) GetCommodityTimeSeries(commodity domain.Co
 	}
 
 	// If not in cache, get from API
-	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey}
+	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey, baseURL: c.baseURL}
 	commodityTimeSeries, err = alphaVantageClient.GetCommodityTimeSeries(commodity)
 	if err != nil {
 		return domain.CommodityTimeSeries{}, err
@@ -166,7 +169,7 @@ func (c *AlphaVantageClientWithCache) GetCryptocurrencyNews(symbol string) ([]do
 	}
 
 	// If not in cache, get from API
-	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey}
+	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey, baseURL: c.baseURL}
 	newsArticles, err = alphaVantageClient.GetCryptocurrencyNews(symbol)
 	if err != nil {
 		return nil, err
@@ -189,7 +192,7 @@ func (c *AlphaVantageClientWithCache) GetEarningsCallTranscript(symbol string, y
 	}
 
 	// If not in cache, get from API
-	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey}
+	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey, baseURL: c.baseURL}
 
 	earningsCallTranscript, err = alphaVantageClient.GetEarningsCallTranscript(symbol, year, quarter)
 	if err != nil {
@@ -211,7 +214,7 @@ func (c *AlphaVantageClientWithCache) GetInsiderTransactions(symbol string) ([]d
 		return insiderTransactions, nil
 	}
 
-	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey}
+	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey, baseURL: c.baseURL}
 	insiderTransactions, err = alphaVantageClient.GetInsiderTransactions(symbol)
 	if err != nil {
 		return nil, err
@@ -231,7 +234,7 @@ func (c *AlphaVantageClientWithCache) GetCurrencyExchangeRate(fromCurrency domai
 		return currencyExchangeRate, nil
 	}
 
-	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey}
+	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey, baseURL: c.baseURL}
 	currencyExchangeRate, err = alphaVantageClient.GetCurrencyExchangeRate(fromCurrency, toCurrency)
 	if err != nil {
 		return domain.CurrencyExchangeRate{}, err
@@ -241,3 +244,23 @@ func (c *AlphaVantageClientWithCache) GetCurrencyExchangeRate(fromCurrency domai
 
 	return currencyExchangeRate, nil
 }
+
+func (c *AlphaVantageClientWithCache) GetStockTimeSeries(symbol string, interval domain.PriceInterval) ([]domain.Price, error) {
+	var prices []domain.Price
+
+	key := fmt.Sprintf("stock_time_series_%s_%s", symbol, interval)
+	err := c.cache.Get(key, &prices)
+	if err == nil {
+		return prices, nil
+	}
+
+	alphaVantageClient := AlphaVantageClient{apiKey: c.apiKey, baseURL: c.baseURL}
+	prices, err = alphaVantageClient.GetStockTimeSeries(symbol, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.Set(key, prices, time.Duration(c.cacheTtlSeconds)*time.Second)
+
+	return prices, nil
+}
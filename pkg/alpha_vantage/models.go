@@ -133,3 +133,17 @@ type RealtimeCurrencyExchangeRate struct {
 type CurrencyExchangeRateResponse struct {
 	RealtimeCurrencyExchangeRate RealtimeCurrencyExchangeRate `json:"Realtime Currency Exchange Rate"`
 }
+
+type StockTimeSeriesEntry struct {
+	Open   string `json:"1. open"`
+	High   string `json:"2. high"`
+	Low    string `json:"3. low"`
+	Close  string `json:"4. close"`
+	Volume string `json:"5. volume"`
+}
+
+// StockTimeSeriesResponse is the response of TIME_SERIES_DAILY or TIME_SERIES_WEEKLY, only one of the series is set
+type StockTimeSeriesResponse struct {
+	Daily  map[string]StockTimeSeriesEntry `json:"Time Series (Daily)"`
+	Weekly map[string]StockTimeSeriesEntry `json:"Weekly Time Series"`
+}
@@ -0,0 +1,166 @@
+// Package dcf values companies by discounting their projected free cash flows.
+//
+// The rates and the growths are fractions (0.05 is 5%). The cash flows are yearly and discounted at the end of
+// their year.
+package dcf
+
+import (
+	"fmt"
+	"math"
+)
+
+type TerminalMethod string
+
+const (
+	PerpetuityGrowth TerminalMethod = "perpetuity_growth"
+	ExitMultiple     TerminalMethod = "exit_multiple"
+)
+
+// Stage grows the cash flow for a number of years, the growth moving linearly from the start growth to the end
+// growth reached the last year. The start and the end growths are equal for a constant growth.
+type Stage struct {
+	Years       int
+	StartGrowth float64
+	EndGrowth   float64
+}
+
+// Growth returns the growth of the year of the stage, from 1
+func (s Stage) Growth(year int) float64 {
+	if s.StartGrowth == s.EndGrowth {
+		return s.EndGrowth
+	}
+	return s.StartGrowth + (s.EndGrowth-s.StartGrowth)*float64(year)/float64(s.Years)
+}
+
+type Model struct {
+	BaseCashFlow   float64 // Free cash flow of the last twelve months, grown from the first year
+	Stages         []Stage
+	DiscountRate   float64
+	Terminal       TerminalMethod
+	TerminalGrowth float64 // Growth after the last year, for the perpetuity growth
+	ExitMultiple   float64 // Multiple of the cash flow of the last year, for the exit multiple
+	NetCash        float64 // Cash minus debt, from the enterprise value to the equity value
+	Shares         float64
+}
+
+// Year is a projected year, its cash flow discounted to today
+type Year struct {
+	Year           int
+	Growth         float64
+	CashFlow       float64
+	DiscountFactor float64
+	PresentValue   float64
+}
+
+type Valuation struct {
+	Years                   []Year
+	PresentValueOfCashFlows float64
+	TerminalValue           float64 // At the end of the last year
+	PresentValueOfTerminal  float64
+	EnterpriseValue         float64
+	EquityValue             float64
+	PerShare                float64
+}
+
+// Value projects the cash flows of the stages, adds the terminal value at the end of the last year and discounts
+// them to the value per share
+func Value(m Model) (Valuation, error) {
+	if m.BaseCashFlow <= 0 {
+		return Valuation{}, fmt.Errorf("the free cash flow must be positive to be projected")
+	}
+	if m.Shares <= 0 {
+		return Valuation{}, fmt.Errorf("the number of shares must be positive")
+	}
+	if m.DiscountRate <= 0 {
+		return Valuation{}, fmt.Errorf("the discount rate must be positive")
+	}
+	switch m.Terminal {
+	case PerpetuityGrowth:
+		if m.TerminalGrowth >= m.DiscountRate {
+			return Valuation{}, fmt.Errorf("the terminal growth must be lower than the discount rate")
+		}
+	case ExitMultiple:
+		if m.ExitMultiple <= 0 {
+			return Valuation{}, fmt.Errorf("the exit multiple must be positive")
+		}
+	default:
+		return Valuation{}, fmt.Errorf("unknown terminal method %s", m.Terminal)
+	}
+
+	var valuation Valuation
+	cashFlow := m.BaseCashFlow
+	for _, stage := range m.Stages {
+		for year := 1; year <= stage.Years; year++ {
+			growth := stage.Growth(year)
+			if growth <= -1 {
+				return Valuation{}, fmt.Errorf("the growth must be greater than -100%%")
+			}
+			cashFlow *= 1 + growth
+			n := len(valuation.Years) + 1
+			factor := 1 / math.Pow(1+m.DiscountRate, float64(n))
+			valuation.Years = append(valuation.Years, Year{
+				Year:           n,
+				Growth:         growth,
+				CashFlow:       cashFlow,
+				DiscountFactor: factor,
+				PresentValue:   cashFlow * factor,
+			})
+			valuation.PresentValueOfCashFlows += cashFlow * factor
+		}
+	}
+	if len(valuation.Years) == 0 {
+		return Valuation{}, fmt.Errorf("at least a year must be projected")
+	}
+
+	last := valuation.Years[len(valuation.Years)-1]
+	if m.Terminal == PerpetuityGrowth {
+		valuation.TerminalValue = last.CashFlow * (1 + m.TerminalGrowth) / (m.DiscountRate - m.TerminalGrowth)
+	} else {
+		valuation.TerminalValue = last.CashFlow * m.ExitMultiple
+	}
+	valuation.PresentValueOfTerminal = valuation.TerminalValue * last.DiscountFactor
+	valuation.EnterpriseValue = valuation.PresentValueOfCashFlows + valuation.PresentValueOfTerminal
+	valuation.EquityValue = valuation.EnterpriseValue + m.NetCash
+	valuation.PerShare = valuation.EquityValue / m.Shares
+	return valuation, nil
+}
+
+// Sensitivity returns the values per share of the model for each discount rate (the rows) and each terminal growth
+// or exit multiple (the columns), depending on its terminal method. The cells whose model can't be valued, e.g.
+// with a discount rate under the terminal growth, are NaN.
+func Sensitivity(m Model, discountRates []float64, terminals []float64) [][]float64 {
+	grid := make([][]float64, len(discountRates))
+	for i, rate := range discountRates {
+		grid[i] = make([]float64, len(terminals))
+		for j, terminal := range terminals {
+			cell := m
+			cell.DiscountRate = rate
+			if m.Terminal == PerpetuityGrowth {
+				cell.TerminalGrowth = terminal
+			} else {
+				cell.ExitMultiple = terminal
+			}
+			valuation, err := Value(cell)
+			if err != nil {
+				grid[i][j] = math.NaN()
+				continue
+			}
+			grid[i][j] = valuation.PerShare
+		}
+	}
+	return grid
+}
+
+// CostOfEquity is the expected return of the capital asset pricing model
+func CostOfEquity(riskFreeRate float64, beta float64, equityRiskPremium float64) float64 {
+	return riskFreeRate + beta*equityRiskPremium
+}
+
+// Wacc is the weighted average cost of capital, with the market values of the equity and the debt as weights and
+// the cost of the debt after taxes
+func Wacc(equity float64, debt float64, costOfEquity float64, costOfDebt float64, taxRate float64) float64 {
+	if equity+debt <= 0 {
+		return costOfEquity
+	}
+	return (equity*costOfEquity + debt*costOfDebt*(1-taxRate)) / (equity + debt)
+}
@@ -0,0 +1,93 @@
+package dcf
+
+import (
+	"math"
+	"testing"
+)
+
+func almostEqual(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestValue(t *testing.T) {
+	m := Model{
+		BaseCashFlow:   100,
+		Stages:         []Stage{{Years: 2, StartGrowth: 0.1, EndGrowth: 0.1}},
+		DiscountRate:   0.1,
+		Terminal:       PerpetuityGrowth,
+		TerminalGrowth: 0.02,
+		NetCash:        50,
+		Shares:         10,
+	}
+
+	// 110 and 121 discounted at 10% are both worth 100 today
+	valuation, err := Value(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(valuation.Years) != 2 || !almostEqual(valuation.Years[1].CashFlow, 121) || !almostEqual(valuation.PresentValueOfCashFlows, 200) {
+		t.Errorf("unexpected years %+v", valuation.Years)
+	}
+	if !almostEqual(valuation.TerminalValue, 1542.75) || !almostEqual(valuation.PresentValueOfTerminal, 1275) ||
+		!almostEqual(valuation.EnterpriseValue, 1475) || !almostEqual(valuation.PerShare, 152.5) {
+		t.Errorf("unexpected valuation %+v", valuation)
+	}
+
+	m.Terminal, m.ExitMultiple = ExitMultiple, 10
+	if valuation, _ := Value(m); !almostEqual(valuation.PresentValueOfTerminal, 1000) || !almostEqual(valuation.PerShare, 125) {
+		t.Errorf("unexpected exit multiple valuation %+v", valuation)
+	}
+
+	invalid := []Model{
+		{BaseCashFlow: -1, Stages: m.Stages, DiscountRate: 0.1, Terminal: ExitMultiple, ExitMultiple: 10, Shares: 1},
+		{BaseCashFlow: 1, Stages: m.Stages, DiscountRate: 0.1, Terminal: ExitMultiple, ExitMultiple: 10},
+		{BaseCashFlow: 1, Stages: m.Stages, DiscountRate: 0.1, Terminal: ExitMultiple, Shares: 1},
+		{BaseCashFlow: 1, Stages: m.Stages, DiscountRate: 0.03, Terminal: PerpetuityGrowth, TerminalGrowth: 0.03, Shares: 1},
+		{BaseCashFlow: 1, DiscountRate: 0.1, Terminal: ExitMultiple, ExitMultiple: 10, Shares: 1},
+		{BaseCashFlow: 1, Stages: []Stage{{Years: 1, StartGrowth: -1, EndGrowth: -1}}, DiscountRate: 0.1, Terminal: ExitMultiple, ExitMultiple: 10, Shares: 1},
+	}
+	for _, model := range invalid {
+		if _, err := Value(model); err == nil {
+			t.Errorf("expected an error for %+v", model)
+		}
+	}
+}
+
+func TestStageGrowth(t *testing.T) {
+	fade := Stage{Years: 4, StartGrowth: 0.1, EndGrowth: 0.02}
+	if !almostEqual(fade.Growth(1), 0.08) || !almostEqual(fade.Growth(4), 0.02) {
+		t.Errorf("expected the growth to fade from 8%% to 2%%, got %v and %v", fade.Growth(1), fade.Growth(4))
+	}
+}
+
+func TestSensitivity(t *testing.T) {
+	m := Model{
+		BaseCashFlow:   100,
+		Stages:         []Stage{{Years: 2, StartGrowth: 0.1, EndGrowth: 0.1}},
+		DiscountRate:   0.1,
+		Terminal:       PerpetuityGrowth,
+		TerminalGrowth: 0.02,
+		NetCash:        50,
+		Shares:         10,
+	}
+
+	grid := Sensitivity(m, []float64{0.02, 0.1}, []float64{0.01, 0.02})
+	if len(grid) != 2 || len(grid[0]) != 2 || math.IsNaN(grid[0][0]) || !math.IsNaN(grid[0][1]) || !almostEqual(grid[1][1], 152.5) {
+		t.Errorf("unexpected grid %v", grid)
+	}
+	if grid[1][0] >= grid[1][1] {
+		t.Errorf("expected a higher value with a higher terminal growth, got %v", grid[1])
+	}
+}
+
+func TestWacc(t *testing.T) {
+	if costOfEquity := CostOfEquity(0.04, 1.2, 0.05); !almostEqual(costOfEquity, 0.1) {
+		t.Errorf("unexpected cost of equity %v", costOfEquity)
+	}
+	if wacc := Wacc(80, 20, 0.1, 0.05, 0.2); !almostEqual(wacc, 0.088) {
+		t.Errorf("unexpected wacc %v", wacc)
+	}
+	if wacc := Wacc(0, 0, 0.1, 0.05, 0.2); wacc != 0.1 {
+		t.Errorf("expected the cost of equity without weights, got %v", wacc)
+	}
+}
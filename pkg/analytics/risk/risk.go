@@ -0,0 +1,321 @@
+// Package risk computes risk and return statistics over price histories.
+//
+// The returns, the rates and the ratios are fractions (0.05 is 5%). The periods of the returns are the intervals
+// between the prices (e.g. a day for the daily closes), and the annualized statistics are scaled by the number of
+// periods per year observed in the prices.
+package risk
+
+import (
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"slices"
+	"time"
+)
+
+const daysPerYear = 365.25
+
+// DailyCloses keeps the last price of each day, in UTC
+func DailyCloses(prices []domain.Price) []domain.Price {
+	daily := make([]domain.Price, 0, len(prices))
+	for _, p := range prices {
+		if n := len(daily); n > 0 && sameDay(daily[n-1].Date, p.Date) {
+			daily[n-1] = p
+			continue
+		}
+		daily = append(daily, p)
+	}
+	return daily
+}
+
+func sameDay(a time.Time, b time.Time) bool {
+	return a.UTC().Format(time.DateOnly) == b.UTC().Format(time.DateOnly)
+}
+
+// Align keeps the last price of each day found in every series, e.g. the trading days of both a stock and a
+// cryptocurrency
+func Align(series ...[]domain.Price) [][]domain.Price {
+	counts := make(map[string]int)
+	for _, prices := range series {
+		for _, p := range DailyCloses(prices) {
+			counts[p.Date.UTC().Format(time.DateOnly)]++
+		}
+	}
+
+	aligned := make([][]domain.Price, len(series))
+	for i, prices := range series {
+		aligned[i] = slices.DeleteFunc(DailyCloses(prices), func(p domain.Price) bool {
+			return counts[p.Date.UTC().Format(time.DateOnly)] != len(series)
+		})
+	}
+	return aligned
+}
+
+// Returns returns the simple returns between the consecutive prices
+func Returns(prices []domain.Price) []float64 {
+	if len(prices) < 2 {
+		return nil
+	}
+	returns := make([]float64, 0, len(prices)-1)
+	for i := 1; i < len(prices); i++ {
+		previous := prices[i-1].ClosePrice
+		if previous == 0 {
+			returns = append(returns, 0)
+			continue
+		}
+		returns = append(returns, prices[i].ClosePrice/previous-1)
+	}
+	return returns
+}
+
+// years returns the years between the first and the last prices
+func years(prices []domain.Price) float64 {
+	if len(prices) < 2 {
+		return 0
+	}
+	return prices[len(prices)-1].Date.Sub(prices[0].Date).Hours() / 24 / daysPerYear
+}
+
+// PeriodsPerYear returns the number of returns per year of the prices, about 252 for the daily closes of a stock
+// and 365 for the ones of a cryptocurrency
+func PeriodsPerYear(prices []domain.Price) float64 {
+	y := years(prices)
+	if y == 0 {
+		return 0
+	}
+	return float64(len(prices)-1) / y
+}
+
+// AnnualizedReturn returns the compound annual growth rate from the first to the last price
+func AnnualizedReturn(prices []domain.Price) float64 {
+	y := years(prices)
+	if y == 0 || prices[0].ClosePrice <= 0 || prices[len(prices)-1].ClosePrice <= 0 {
+		return 0
+	}
+	return math.Pow(prices[len(prices)-1].ClosePrice/prices[0].ClosePrice, 1/y) - 1
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// covariance returns the sample covariance of the values, they must have the same length
+func covariance(a []float64, b []float64) float64 {
+	if len(a) < 2 {
+		return 0
+	}
+	meanA, meanB := mean(a), mean(b)
+	var sum float64
+	for i := range a {
+		sum += (a[i] - meanA) * (b[i] - meanB)
+	}
+	return sum / float64(len(a)-1)
+}
+
+// AnnualizedVolatility returns the sample standard deviation of the returns scaled to a year
+func AnnualizedVolatility(returns []float64, periodsPerYear float64) float64 {
+	return math.Sqrt(covariance(returns, returns) * periodsPerYear)
+}
+
+// SharpeRatio returns the annualized mean return in excess of the annual risk-free rate over the annualized
+// volatility, 0 when the returns don't vary
+func SharpeRatio(returns []float64, periodsPerYear float64, riskFreeRate float64) float64 {
+	volatility := AnnualizedVolatility(returns, periodsPerYear)
+	if volatility == 0 {
+		return 0
+	}
+	return (mean(returns)*periodsPerYear - riskFreeRate) / volatility
+}
+
+// SortinoRatio returns the annualized mean return in excess of the annual risk-free rate over the annualized
+// downside deviation below the risk-free rate, 0 without returns below it
+func SortinoRatio(returns []float64, periodsPerYear float64, riskFreeRate float64) float64 {
+	if len(returns) == 0 || periodsPerYear == 0 {
+		return 0
+	}
+	target := riskFreeRate / periodsPerYear
+	var sum float64
+	for _, r := range returns {
+		if shortfall := min(r-target, 0); shortfall < 0 {
+			sum += shortfall * shortfall
+		}
+	}
+	downside := math.Sqrt(sum / float64(len(returns)) * periodsPerYear)
+	if downside == 0 {
+		return 0
+	}
+	return (mean(returns)*periodsPerYear - riskFreeRate) / downside
+}
+
+// Beta returns the sensitivity of the returns to the benchmark returns of the same periods
+func Beta(returns []float64, benchmarkReturns []float64) float64 {
+	variance := covariance(benchmarkReturns, benchmarkReturns)
+	if variance == 0 {
+		return 0
+	}
+	return covariance(returns, benchmarkReturns) / variance
+}
+
+// Correlation returns the Pearson correlation of the returns and the benchmark returns of the same periods
+func Correlation(returns []float64, benchmarkReturns []float64) float64 {
+	deviations := math.Sqrt(covariance(returns, returns) * covariance(benchmarkReturns, benchmarkReturns))
+	if deviations == 0 {
+		return 0
+	}
+	return covariance(returns, benchmarkReturns) / deviations
+}
+
+// HistoricalVaR returns the loss of a period not exceeded with the given confidence (e.g. 0.95) in the past
+// returns, and the expected shortfall: the mean loss of the periods beyond it. The losses are positive.
+func HistoricalVaR(returns []float64, confidence float64) (float64, float64) {
+	if len(returns) == 0 {
+		return 0, 0
+	}
+	sorted := slices.Sorted(slices.Values(returns))
+	// Nearest rank of the tail, rounded against the float errors of e.g. (1 - 0.95) * 100
+	tail := max(int(math.Ceil((1-confidence)*float64(len(sorted))-1e-9)), 1)
+	return max(-sorted[tail-1], 0), max(-mean(sorted[:tail]), 0)
+}
+
+type Drawdown struct {
+	Depth    float64 // Loss from the peak to the trough, positive
+	Peak     time.Time
+	Trough   time.Time
+	Recovery time.Time // First price back at the peak, zero when not recovered
+}
+
+// MaxDrawdown returns the largest loss from a peak to a later trough of the prices
+func MaxDrawdown(prices []domain.Price) Drawdown {
+	var worst Drawdown
+	if len(prices) == 0 {
+		return worst
+	}
+
+	peak := prices[0]
+	var worstPeak float64
+	for _, p := range prices {
+		if p.ClosePrice >= peak.ClosePrice {
+			peak = p
+		}
+		if peak.ClosePrice <= 0 {
+			continue
+		}
+		if depth := 1 - p.ClosePrice/peak.ClosePrice; depth > worst.Depth {
+			worst = Drawdown{Depth: depth, Peak: peak.Date, Trough: p.Date}
+			worstPeak = peak.ClosePrice
+		}
+	}
+
+	for _, p := range prices {
+		if worst.Depth > 0 && p.Date.After(worst.Trough) && p.ClosePrice >= worstPeak {
+			worst.Recovery = p.Date
+			break
+		}
+	}
+	return worst
+}
+
+// WeightedPortfolio returns the value of a portfolio, starting at 100, holding the series with constant weights
+// rebalanced at every price. The series are aligned on their common days, the weights missing to 1 earn nothing
+// (e.g. the cash).
+func WeightedPortfolio(series [][]domain.Price, weights []float64) ([]domain.Price, error) {
+	if len(series) == 0 || len(series) != len(weights) {
+		return nil, fmt.Errorf("a weight is needed for each series")
+	}
+
+	aligned := Align(series...)
+	days := len(aligned[0])
+	if days < 2 {
+		return nil, fmt.Errorf("not enough prices: the series have %d days in common", days)
+	}
+
+	returns := make([][]float64, len(aligned))
+	for i, prices := range aligned {
+		returns[i] = Returns(prices)
+	}
+
+	portfolio := make([]domain.Price, days)
+	portfolio[0] = domain.Price{Date: aligned[0][0].Date, ClosePrice: 100}
+	for day := 1; day < days; day++ {
+		var r float64
+		for i := range aligned {
+			r += weights[i] * returns[i][day-1]
+		}
+		portfolio[day] = domain.Price{Date: aligned[0][day].Date, ClosePrice: portfolio[day-1].ClosePrice * (1 + r)}
+	}
+	return portfolio, nil
+}
+
+type Metrics struct {
+	Start                time.Time
+	End                  time.Time
+	Observations         int // Number of returns
+	PeriodsPerYear       float64
+	TotalReturn          float64
+	AnnualizedReturn     float64
+	AnnualizedVolatility float64
+	MaxDrawdown          Drawdown
+	RiskFreeRate         float64
+	SharpeRatio          float64
+	SortinoRatio         float64
+	Confidence           float64
+	ValueAtRisk          float64 // Loss of a period, positive
+	ExpectedShortfall    float64 // Mean loss of the periods beyond the value at risk, positive
+	HasBenchmark         bool
+	Beta                 float64
+	Correlation          float64
+}
+
+// Compute computes the metrics of the prices, and the beta and the correlation against the benchmark prices when
+// given. The prices are reduced to a price per day, and to the days of the benchmark when given.
+func Compute(prices []domain.Price, benchmark []domain.Price, riskFreeRate float64, confidence float64) (Metrics, error) {
+	if confidence <= 0 || confidence >= 1 {
+		return Metrics{}, fmt.Errorf("the confidence must be between 0 and 1")
+	}
+
+	prices = DailyCloses(prices)
+	if benchmark != nil {
+		aligned := Align(prices, benchmark)
+		prices, benchmark = aligned[0], aligned[1]
+	}
+	if len(prices) < 3 {
+		return Metrics{}, fmt.Errorf("not enough prices: 3 days needed, %d available", len(prices))
+	}
+
+	returns := Returns(prices)
+	periodsPerYear := PeriodsPerYear(prices)
+	valueAtRisk, expectedShortfall := HistoricalVaR(returns, confidence)
+	metrics := Metrics{
+		Start:                prices[0].Date,
+		End:                  prices[len(prices)-1].Date,
+		Observations:         len(returns),
+		PeriodsPerYear:       periodsPerYear,
+		AnnualizedReturn:     AnnualizedReturn(prices),
+		AnnualizedVolatility: AnnualizedVolatility(returns, periodsPerYear),
+		MaxDrawdown:          MaxDrawdown(prices),
+		RiskFreeRate:         riskFreeRate,
+		SharpeRatio:          SharpeRatio(returns, periodsPerYear, riskFreeRate),
+		SortinoRatio:         SortinoRatio(returns, periodsPerYear, riskFreeRate),
+		Confidence:           confidence,
+		ValueAtRisk:          valueAtRisk,
+		ExpectedShortfall:    expectedShortfall,
+	}
+	if first := prices[0].ClosePrice; first != 0 {
+		metrics.TotalReturn = prices[len(prices)-1].ClosePrice/first - 1
+	}
+	if benchmark != nil {
+		benchmarkReturns := Returns(benchmark)
+		metrics.HasBenchmark = true
+		metrics.Beta = Beta(returns, benchmarkReturns)
+		metrics.Correlation = Correlation(returns, benchmarkReturns)
+	}
+
+	return metrics, nil
+}
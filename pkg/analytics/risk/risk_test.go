@@ -0,0 +1,152 @@
+package risk
+
+import (
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func dailyPrices(closes ...float64) []domain.Price {
+	prices := make([]domain.Price, len(closes))
+	for i, c := range closes {
+		prices[i] = domain.Price{Date: start.AddDate(0, 0, i), ClosePrice: c}
+	}
+	return prices
+}
+
+func almostEqual(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestAlign(t *testing.T) {
+	hourly := []domain.Price{
+		{Date: start.Add(1 * time.Hour), ClosePrice: 1},
+		{Date: start.Add(23 * time.Hour), ClosePrice: 2},
+		{Date: start.AddDate(0, 0, 1), ClosePrice: 3},
+		{Date: start.AddDate(0, 0, 2), ClosePrice: 4},
+	}
+	weekdays := []domain.Price{{Date: start, ClosePrice: 10}, {Date: start.AddDate(0, 0, 2), ClosePrice: 12}}
+
+	aligned := Align(hourly, weekdays)
+	if len(aligned[0]) != 2 || aligned[0][0].ClosePrice != 2 || aligned[0][1].ClosePrice != 4 || len(aligned[1]) != 2 {
+		t.Errorf("unexpected aligned series %+v", aligned)
+	}
+}
+
+func TestReturnStatistics(t *testing.T) {
+	prices := dailyPrices(100, 110, 99, 108.9)
+	returns := Returns(prices)
+	if !almostEqual(returns[0], 0.1) || !almostEqual(returns[1], -0.1) || !almostEqual(returns[2], 0.1) {
+		t.Fatalf("unexpected returns %v", returns)
+	}
+
+	// A year of 365.25 days has 365.25 daily returns
+	if !almostEqual(PeriodsPerYear(prices), 365.25) {
+		t.Errorf("unexpected periods per year %v", PeriodsPerYear(prices))
+	}
+
+	// Sample standard deviation of 0.1, -0.1, 0.1
+	volatility := AnnualizedVolatility(returns, 252)
+	if !almostEqual(volatility, math.Sqrt(0.04/3*252)) {
+		t.Errorf("unexpected volatility %v", volatility)
+	}
+	if sharpe := SharpeRatio(returns, 252, 0.03); !almostEqual(sharpe, (0.1/3*252-0.03)/volatility) {
+		t.Errorf("unexpected Sharpe ratio %v", sharpe)
+	}
+	// A single return below the target of 0.03/252
+	downside := math.Sqrt(math.Pow(-0.1-0.03/252, 2) / 3 * 252)
+	if sortino := SortinoRatio(returns, 252, 0.03); !almostEqual(sortino, (0.1/3*252-0.03)/downside) {
+		t.Errorf("unexpected Sortino ratio %v", sortino)
+	}
+
+	if annualized := AnnualizedReturn([]domain.Price{{Date: start, ClosePrice: 100}, {Date: start.AddDate(2, 0, 0), ClosePrice: 121}}); math.Abs(annualized-0.1) > 1e-3 {
+		t.Errorf("expected a 10%% annual growth over 2 years, got %v", annualized)
+	}
+}
+
+func TestBetaAndCorrelation(t *testing.T) {
+	benchmark := []float64{0.01, -0.02, 0.03, 0.01}
+	leveraged := []float64{0.02, -0.04, 0.06, 0.02}
+	inverse := []float64{-0.01, 0.02, -0.03, -0.01}
+
+	if beta := Beta(leveraged, benchmark); !almostEqual(beta, 2) {
+		t.Errorf("expected a beta of 2, got %v", beta)
+	}
+	if correlation := Correlation(inverse, benchmark); !almostEqual(correlation, -1) {
+		t.Errorf("expected a correlation of -1, got %v", correlation)
+	}
+	if beta := Beta(leveraged, []float64{0.01, 0.01, 0.01, 0.01}); beta != 0 {
+		t.Errorf("expected a beta of 0 against a constant benchmark, got %v", beta)
+	}
+}
+
+func TestHistoricalVaR(t *testing.T) {
+	returns := make([]float64, 100)
+	for i := range returns {
+		returns[i] = float64(i-10) / 100 // -0.10 to 0.89
+	}
+	valueAtRisk, expectedShortfall := HistoricalVaR(returns, 0.95)
+	// The 5 worst returns are -0.10 to -0.06
+	if !almostEqual(valueAtRisk, 0.06) || !almostEqual(expectedShortfall, 0.08) {
+		t.Errorf("unexpected VaR %v and expected shortfall %v", valueAtRisk, expectedShortfall)
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	prices := dailyPrices(100, 120, 90, 110, 60, 130, 125)
+	drawdown := MaxDrawdown(prices)
+	if !almostEqual(drawdown.Depth, 0.5) || !drawdown.Peak.Equal(prices[1].Date) || !drawdown.Trough.Equal(prices[4].Date) || !drawdown.Recovery.Equal(prices[5].Date) {
+		t.Errorf("unexpected drawdown %+v", drawdown)
+	}
+
+	if drawdown := MaxDrawdown(dailyPrices(100, 80)); !drawdown.Recovery.IsZero() {
+		t.Errorf("expected no recovery, got %+v", drawdown)
+	}
+}
+
+func TestWeightedPortfolio(t *testing.T) {
+	a := dailyPrices(100, 110, 121)
+	b := dailyPrices(50, 45, 45)
+
+	// Half in a, a quarter in b and a quarter in cash
+	portfolio, err := WeightedPortfolio([][]domain.Price{a, b}, []float64{0.5, 0.25})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(portfolio) != 3 || !almostEqual(portfolio[1].ClosePrice, 100*(1+0.05-0.025)) || !almostEqual(portfolio[2].ClosePrice, portfolio[1].ClosePrice*1.05) {
+		t.Errorf("unexpected portfolio %+v", portfolio)
+	}
+
+	if _, err := WeightedPortfolio([][]domain.Price{a, b[2:]}, []float64{0.5, 0.5}); err == nil {
+		t.Error("expected an error without enough days in common")
+	}
+}
+
+func TestCompute(t *testing.T) {
+	benchmark := dailyPrices(100, 101, 99, 102, 100, 103)
+	prices := dailyPrices(100, 102, 98, 104, 100, 106)
+
+	metrics, err := Compute(prices, benchmark, 0.04, 0.9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.Observations != 5 || !metrics.HasBenchmark || metrics.Beta <= 1 || metrics.Correlation <= 0.9 || !almostEqual(metrics.TotalReturn, 0.06) {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+	if metrics.MaxDrawdown.Depth == 0 || metrics.ValueAtRisk == 0 || metrics.RiskFreeRate != 0.04 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+
+	if metrics, _ := Compute(prices, nil, 0, 0.95); metrics.HasBenchmark || metrics.Beta != 0 {
+		t.Errorf("expected no benchmark statistics, got %+v", metrics)
+	}
+	if _, err := Compute(prices[:2], nil, 0, 0.95); err == nil {
+		t.Error("expected an error without enough prices")
+	}
+	if _, err := Compute(prices, nil, 0, 1); err == nil {
+		t.Error("expected an error for a confidence of 1")
+	}
+}
@@ -0,0 +1,201 @@
+package technical
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"slices"
+)
+
+type Indicator string
+
+const (
+	IndicatorSMA                  Indicator = "sma"
+	IndicatorEMA                  Indicator = "ema"
+	IndicatorRSI                  Indicator = "rsi"
+	IndicatorMACD                 Indicator = "macd"
+	IndicatorBollingerBands       Indicator = "bollinger_bands"
+	IndicatorATR                  Indicator = "atr"
+	IndicatorVWAP                 Indicator = "vwap"
+	IndicatorFiftyTwoWeekRange    Indicator = "52_week_range"
+	IndicatorMovingAverageCrosses Indicator = "moving_average_crosses"
+)
+
+// Indicators are all the indicators, in the order of a report
+var Indicators = []Indicator{
+	IndicatorSMA, IndicatorEMA, IndicatorRSI, IndicatorMACD, IndicatorBollingerBands, IndicatorATR, IndicatorVWAP,
+	IndicatorFiftyTwoWeekRange, IndicatorMovingAverageCrosses,
+}
+
+// Parameters are the parameters of the indicators, the zero values are replaced by the usual ones
+type Parameters struct {
+	SMAPeriods                  []int   // 20, 50 and 200 by default
+	EMAPeriods                  []int   // 12 and 26 by default
+	RSIPeriod                   int     // 14 by default
+	MACDFastPeriod              int     // 12 by default
+	MACDSlowPeriod              int     // 26 by default
+	MACDSignalPeriod            int     // 9 by default
+	BollingerPeriod             int     // 20 by default
+	BollingerStandardDeviations float64 // 2 by default
+	ATRPeriod                   int     // 14 by default
+	CrossFastPeriod             int     // 50 by default
+	CrossSlowPeriod             int     // 200 by default
+}
+
+func (p Parameters) withDefaults() Parameters {
+	if len(p.SMAPeriods) == 0 {
+		p.SMAPeriods = []int{20, 50, 200}
+	}
+	if len(p.EMAPeriods) == 0 {
+		p.EMAPeriods = []int{12, 26}
+	}
+	p.RSIPeriod = cmp.Or(p.RSIPeriod, 14)
+	p.MACDFastPeriod = cmp.Or(p.MACDFastPeriod, 12)
+	p.MACDSlowPeriod = cmp.Or(p.MACDSlowPeriod, 26)
+	p.MACDSignalPeriod = cmp.Or(p.MACDSignalPeriod, 9)
+	p.BollingerPeriod = cmp.Or(p.BollingerPeriod, 20)
+	p.BollingerStandardDeviations = cmp.Or(p.BollingerStandardDeviations, 2)
+	p.ATRPeriod = cmp.Or(p.ATRPeriod, 14)
+	p.CrossFastPeriod = cmp.Or(p.CrossFastPeriod, 50)
+	p.CrossSlowPeriod = cmp.Or(p.CrossSlowPeriod, 200)
+	return p
+}
+
+type MovingAverage struct {
+	Period int
+	Values []float64
+}
+
+type Crosses struct {
+	FastPeriod int
+	SlowPeriod int
+	Crosses    []Cross // The oldest first
+	FastAbove  bool    // Whether the fast average is above the slow one at the last price
+}
+
+// Unavailable is an indicator that couldn't be computed, e.g. for lack of prices
+type Unavailable struct {
+	Indicator Indicator
+	Reason    string
+}
+
+// Report holds the indicators computed over a price history, the ones not requested or not available are nil
+type Report struct {
+	Parameters     Parameters
+	SMA            []MovingAverage
+	EMA            []MovingAverage
+	RSI            []float64
+	MACD           *MACDResult
+	BollingerBands *BollingerBandsResult
+	ATR            []float64
+	VWAP           []float64
+	FiftyTwoWeek   *PriceRange
+	Crosses        *Crosses
+	Unavailable    []Unavailable
+}
+
+// Compute computes the indicators over the prices, all of them when none is given. The indicators that can't be
+// computed are reported as unavailable with the reason.
+func Compute(history domain.HistoricalPrices, indicators []Indicator, parameters Parameters) (Report, error) {
+	if len(indicators) == 0 {
+		indicators = Indicators
+	}
+	for _, indicator := range indicators {
+		if !slices.Contains(Indicators, indicator) {
+			return Report{}, fmt.Errorf("unknown indicator: %s", indicator)
+		}
+	}
+
+	parameters = parameters.withDefaults()
+	report := Report{Parameters: parameters}
+	prices := history.Prices
+	closes := Closes(prices)
+	unavailable := func(indicator Indicator, err error) {
+		report.Unavailable = append(report.Unavailable, Unavailable{Indicator: indicator, Reason: err.Error()})
+	}
+
+	for _, indicator := range Indicators {
+		if !slices.Contains(indicators, indicator) {
+			continue
+		}
+
+		switch indicator {
+		case IndicatorSMA, IndicatorEMA:
+			average := SMA
+			periods := parameters.SMAPeriods
+			if indicator == IndicatorEMA {
+				average, periods = EMA, parameters.EMAPeriods
+			}
+			var averages []MovingAverage
+			for _, period := range periods {
+				values, err := average(closes, period)
+				if err != nil {
+					unavailable(indicator, fmt.Errorf("period %d: %w", period, err))
+					continue
+				}
+				averages = append(averages, MovingAverage{Period: period, Values: values})
+			}
+			if indicator == IndicatorSMA {
+				report.SMA = averages
+			} else {
+				report.EMA = averages
+			}
+		case IndicatorRSI:
+			rsi, err := RSI(closes, parameters.RSIPeriod)
+			if err != nil {
+				unavailable(indicator, err)
+				continue
+			}
+			report.RSI = rsi
+		case IndicatorMACD:
+			macd, err := MACD(closes, parameters.MACDFastPeriod, parameters.MACDSlowPeriod, parameters.MACDSignalPeriod)
+			if err != nil {
+				unavailable(indicator, err)
+				continue
+			}
+			report.MACD = &macd
+		case IndicatorBollingerBands:
+			bands, err := BollingerBands(closes, parameters.BollingerPeriod, parameters.BollingerStandardDeviations)
+			if err != nil {
+				unavailable(indicator, err)
+				continue
+			}
+			report.BollingerBands = &bands
+		case IndicatorATR:
+			atr, err := ATR(prices, parameters.ATRPeriod)
+			if err != nil {
+				unavailable(indicator, err)
+				continue
+			}
+			report.ATR = atr
+		case IndicatorVWAP:
+			vwap, err := VWAP(prices)
+			if err != nil {
+				unavailable(indicator, err)
+				continue
+			}
+			report.VWAP = vwap
+		case IndicatorFiftyTwoWeekRange:
+			priceRange, err := FiftyTwoWeekRange(prices)
+			if err != nil {
+				unavailable(indicator, err)
+				continue
+			}
+			report.FiftyTwoWeek = &priceRange
+		case IndicatorMovingAverageCrosses:
+			crosses, fastAbove, err := MovingAverageCrosses(prices, parameters.CrossFastPeriod, parameters.CrossSlowPeriod)
+			if err != nil {
+				unavailable(indicator, err)
+				continue
+			}
+			report.Crosses = &Crosses{
+				FastPeriod: parameters.CrossFastPeriod,
+				SlowPeriod: parameters.CrossSlowPeriod,
+				Crosses:    crosses,
+				FastAbove:  fastAbove,
+			}
+		}
+	}
+
+	return report, nil
+}
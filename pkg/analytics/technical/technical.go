@@ -0,0 +1,345 @@
+// Package technical computes technical indicators over price histories.
+//
+// The series returned are aligned with the prices they are computed from, the values before the window of an
+// indicator is full are NaN.
+package technical
+
+import (
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"time"
+)
+
+// Closes returns the closes of the prices
+func Closes(prices []domain.Price) []float64 {
+	closes := make([]float64, len(prices))
+	for i, p := range prices {
+		closes[i] = p.ClosePrice
+	}
+	return closes
+}
+
+// HasOHLC reports whether every price has a high and a low
+func HasOHLC(prices []domain.Price) bool {
+	for _, p := range prices {
+		if p.High == 0 || p.Low == 0 {
+			return false
+		}
+	}
+	return len(prices) > 0
+}
+
+// HasVolume reports whether every price has a volume
+func HasVolume(prices []domain.Price) bool {
+	for _, p := range prices {
+		if p.Volume == 0 {
+			return false
+		}
+	}
+	return len(prices) > 0
+}
+
+// Last returns the last value of a series, NaN when it's empty
+func Last(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	return values[len(values)-1]
+}
+
+func checkPeriod(count int, period int, needed int) error {
+	if period < 1 {
+		return fmt.Errorf("the period must be positive")
+	}
+	if count < needed {
+		return fmt.Errorf("not enough prices: %d needed, %d available", needed, count)
+	}
+	return nil
+}
+
+func nanSeries(length int) []float64 {
+	series := make([]float64, length)
+	for i := range series {
+		series[i] = math.NaN()
+	}
+	return series
+}
+
+// SMA returns the simple moving average of the values over the period
+func SMA(values []float64, period int) ([]float64, error) {
+	if err := checkPeriod(len(values), period, period); err != nil {
+		return nil, err
+	}
+
+	sma := nanSeries(len(values))
+	var sum float64
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			sma[i] = sum / float64(period)
+		}
+	}
+	return sma, nil
+}
+
+// EMA returns the exponential moving average of the values over the period, seeded with the simple moving
+// average of the first period
+func EMA(values []float64, period int) ([]float64, error) {
+	if err := checkPeriod(len(values), period, period); err != nil {
+		return nil, err
+	}
+	return ema(values, 0, period), nil
+}
+
+// ema computes the exponential moving average of the values from the start index, the values before it are ignored
+func ema(values []float64, start int, period int) []float64 {
+	series := nanSeries(len(values))
+	if len(values)-start < period {
+		return series
+	}
+
+	var seed float64
+	for _, v := range values[start : start+period] {
+		seed += v
+	}
+	series[start+period-1] = seed / float64(period)
+
+	multiplier := 2 / float64(period+1)
+	for i := start + period; i < len(values); i++ {
+		series[i] = (values[i]-series[i-1])*multiplier + series[i-1]
+	}
+	return series
+}
+
+// RSI returns the relative strength index of the values over the period, with the smoothing of Wilder
+func RSI(values []float64, period int) ([]float64, error) {
+	if err := checkPeriod(len(values), period, period+1); err != nil {
+		return nil, err
+	}
+
+	rsi := nanSeries(len(values))
+	var averageGain, averageLoss float64
+	for i := 1; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		gain, loss := max(change, 0), max(-change, 0)
+		if i <= period {
+			averageGain += gain / float64(period)
+			averageLoss += loss / float64(period)
+			if i < period {
+				continue
+			}
+		} else {
+			averageGain = (averageGain*float64(period-1) + gain) / float64(period)
+			averageLoss = (averageLoss*float64(period-1) + loss) / float64(period)
+		}
+
+		switch {
+		case averageLoss == 0 && averageGain == 0:
+			rsi[i] = 50
+		case averageLoss == 0:
+			rsi[i] = 100
+		default:
+			rsi[i] = 100 - 100/(1+averageGain/averageLoss)
+		}
+	}
+	return rsi, nil
+}
+
+type MACDResult struct {
+	MACD      []float64 // Fast EMA minus slow EMA
+	Signal    []float64 // EMA of the MACD
+	Histogram []float64 // MACD minus signal
+}
+
+// MACD returns the moving average convergence divergence of the values
+func MACD(values []float64, fastPeriod int, slowPeriod int, signalPeriod int) (MACDResult, error) {
+	if fastPeriod >= slowPeriod {
+		return MACDResult{}, fmt.Errorf("the fast period must be shorter than the slow period")
+	}
+	if err := checkPeriod(len(values), min(fastPeriod, signalPeriod), slowPeriod+signalPeriod-1); err != nil {
+		return MACDResult{}, err
+	}
+
+	fast := ema(values, 0, fastPeriod)
+	slow := ema(values, 0, slowPeriod)
+	macd := nanSeries(len(values))
+	for i := slowPeriod - 1; i < len(values); i++ {
+		macd[i] = fast[i] - slow[i]
+	}
+	signal := ema(macd, slowPeriod-1, signalPeriod)
+	histogram := nanSeries(len(values))
+	for i := range values {
+		histogram[i] = macd[i] - signal[i]
+	}
+
+	return MACDResult{MACD: macd, Signal: signal, Histogram: histogram}, nil
+}
+
+type BollingerBandsResult struct {
+	Middle []float64 // Simple moving average
+	Upper  []float64
+	Lower  []float64
+}
+
+// BollingerBands returns the simple moving average of the values over the period, and the bands the given number
+// of standard deviations above and below it
+func BollingerBands(values []float64, period int, standardDeviations float64) (BollingerBandsResult, error) {
+	middle, err := SMA(values, period)
+	if err != nil {
+		return BollingerBandsResult{}, err
+	}
+
+	upper, lower := nanSeries(len(values)), nanSeries(len(values))
+	for i := period - 1; i < len(values); i++ {
+		var variance float64
+		for _, v := range values[i-period+1 : i+1] {
+			variance += (v - middle[i]) * (v - middle[i])
+		}
+		deviation := math.Sqrt(variance/float64(period)) * standardDeviations
+		upper[i] = middle[i] + deviation
+		lower[i] = middle[i] - deviation
+	}
+
+	return BollingerBandsResult{Middle: middle, Upper: upper, Lower: lower}, nil
+}
+
+// ATR returns the average true range of the prices over the period, with the smoothing of Wilder. The prices need
+// a high and a low.
+func ATR(prices []domain.Price, period int) ([]float64, error) {
+	if !HasOHLC(prices) {
+		return nil, fmt.Errorf("the average true range needs the high and the low prices")
+	}
+	if err := checkPeriod(len(prices), period, period+1); err != nil {
+		return nil, err
+	}
+
+	atr := nanSeries(len(prices))
+	var average float64
+	for i := 1; i < len(prices); i++ {
+		previousClose := prices[i-1].ClosePrice
+		trueRange := max(prices[i].High-prices[i].Low, math.Abs(prices[i].High-previousClose), math.Abs(prices[i].Low-previousClose))
+		if i <= period {
+			average += trueRange / float64(period)
+			if i < period {
+				continue
+			}
+		} else {
+			average = (average*float64(period-1) + trueRange) / float64(period)
+		}
+		atr[i] = average
+	}
+	return atr, nil
+}
+
+// VWAP returns the volume weighted average price from the first price, of the typical prices (the average of the
+// high, the low and the close) or of the closes when the prices have no high and low. The prices need a volume.
+func VWAP(prices []domain.Price) ([]float64, error) {
+	if !HasVolume(prices) {
+		return nil, fmt.Errorf("the volume weighted average price needs the volumes")
+	}
+
+	ohlc := HasOHLC(prices)
+	vwap := make([]float64, len(prices))
+	var value, volume float64
+	for i, p := range prices {
+		price := p.ClosePrice
+		if ohlc {
+			price = (p.High + p.Low + p.ClosePrice) / 3
+		}
+		value += price * p.Volume
+		volume += p.Volume
+		vwap[i] = value / volume
+	}
+	return vwap, nil
+}
+
+type PriceRange struct {
+	High     float64
+	HighDate time.Time
+	Low      float64
+	LowDate  time.Time
+}
+
+// FiftyTwoWeekRange returns the highest and the lowest prices of the 52 weeks ending at the last price, the highs
+// and the lows when the prices have them or else the closes. The prices must start 52 weeks before the last one, a
+// week of tolerance is given for the weekly prices.
+func FiftyTwoWeekRange(prices []domain.Price) (PriceRange, error) {
+	if len(prices) == 0 {
+		return PriceRange{}, fmt.Errorf("not enough prices: no prices available")
+	}
+	start := prices[len(prices)-1].Date.AddDate(0, 0, -52*7)
+	if prices[0].Date.After(start.AddDate(0, 0, 7)) {
+		return PriceRange{}, fmt.Errorf("not enough prices: 52 weeks needed, the prices start on %s", prices[0].Date.Format(time.DateOnly))
+	}
+
+	ohlc := HasOHLC(prices)
+	r := PriceRange{Low: math.Inf(1)}
+	for _, p := range prices {
+		if p.Date.Before(start) {
+			continue
+		}
+		high, low := p.ClosePrice, p.ClosePrice
+		if ohlc {
+			high, low = p.High, p.Low
+		}
+		if high > r.High {
+			r.High, r.HighDate = high, p.Date
+		}
+		if low < r.Low {
+			r.Low, r.LowDate = low, p.Date
+		}
+	}
+	return r, nil
+}
+
+type CrossType string
+
+const (
+	// CrossGolden is the fast moving average crossing above the slow one
+	CrossGolden CrossType = "golden"
+	// CrossDeath is the fast moving average crossing below the slow one
+	CrossDeath CrossType = "death"
+)
+
+type Cross struct {
+	Date time.Time
+	Type CrossType
+}
+
+// MovingAverageCrosses returns the crosses of the fast and the slow simple moving averages of the closes, the oldest
+// first, and whether the fast one is above the slow one at the last price
+func MovingAverageCrosses(prices []domain.Price, fastPeriod int, slowPeriod int) ([]Cross, bool, error) {
+	if fastPeriod >= slowPeriod {
+		return nil, false, fmt.Errorf("the fast period must be shorter than the slow period")
+	}
+	if err := checkPeriod(len(prices), fastPeriod, slowPeriod+1); err != nil {
+		return nil, false, err
+	}
+
+	closes := Closes(prices)
+	fast, _ := SMA(closes, fastPeriod)
+	slow, _ := SMA(closes, slowPeriod)
+
+	var crosses []Cross
+	// The sign of the difference of the averages, the equalities keep the previous one
+	sign := 0
+	for i := slowPeriod - 1; i < len(prices); i++ {
+		difference := fast[i] - slow[i]
+		switch {
+		case difference > 0 && sign < 0:
+			crosses = append(crosses, Cross{Date: prices[i].Date, Type: CrossGolden})
+		case difference < 0 && sign > 0:
+			crosses = append(crosses, Cross{Date: prices[i].Date, Type: CrossDeath})
+		}
+		if difference > 0 {
+			sign = 1
+		} else if difference < 0 {
+			sign = -1
+		}
+	}
+	return crosses, sign > 0, nil
+}
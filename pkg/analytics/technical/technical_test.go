@@ -0,0 +1,216 @@
+package technical
+
+import (
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+func closesPrices(closes ...float64) []domain.Price {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	prices := make([]domain.Price, len(closes))
+	for i, c := range closes {
+		prices[i] = domain.Price{Date: start.AddDate(0, 0, i), ClosePrice: c}
+	}
+	return prices
+}
+
+func almostEqual(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestMovingAverages(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6}
+
+	sma, err := SMA(values, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(sma[1]) || sma[2] != 2 || sma[5] != 5 {
+		t.Errorf("unexpected SMA %v", sma)
+	}
+
+	// Seeded with the SMA of 1, 2, 3 then a multiplier of 0.5
+	ema, err := EMA(values, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ema[2] != 2 || ema[3] != 3 || ema[5] != 5 {
+		t.Errorf("unexpected EMA %v", ema)
+	}
+
+	if _, err := SMA(values, 7); err == nil {
+		t.Error("expected an error for a period longer than the values")
+	}
+	if _, err := EMA(values, 0); err == nil {
+		t.Error("expected an error for a zero period")
+	}
+}
+
+func TestRSI(t *testing.T) {
+	rsi, err := RSI([]float64{1, 2, 3, 4, 5}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(rsi[2]) || rsi[3] != 100 || rsi[4] != 100 {
+		t.Errorf("expected an RSI of 100 without losses, got %v", rsi)
+	}
+
+	// Average gain of 2/3 and average loss of 1/3, then a loss of 1
+	rsi, _ = RSI([]float64{10, 11, 10, 11, 10}, 3)
+	if !almostEqual(rsi[3], 100-100/(1+2.0)) || !almostEqual(rsi[4], 100-100/(1+(4.0/9)/(5.0/9))) {
+		t.Errorf("unexpected RSI %v", rsi)
+	}
+
+	if rsi, _ := RSI([]float64{5, 5, 5}, 2); rsi[2] != 50 {
+		t.Errorf("expected an RSI of 50 for flat prices, got %v", rsi)
+	}
+}
+
+func TestMACD(t *testing.T) {
+	values := make([]float64, 40)
+	for i := range values {
+		values[i] = 100
+	}
+	macd, err := MACD(values, 12, 26, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(macd.MACD[24]) || macd.MACD[25] != 0 || !math.IsNaN(macd.Signal[32]) || macd.Signal[33] != 0 || macd.Histogram[39] != 0 {
+		t.Errorf("unexpected MACD of flat prices %+v", macd)
+	}
+
+	// A rising trend has the fast average above the slow one
+	for i := range values {
+		values[i] = float64(i)
+	}
+	macd, _ = MACD(values, 12, 26, 9)
+	if !almostEqual(macd.MACD[39], 7) || !almostEqual(macd.Signal[39], 7) {
+		t.Errorf("expected the lag difference of a linear trend, got %v and %v", macd.MACD[39], macd.Signal[39])
+	}
+
+	if _, err := MACD(values[:33], 12, 26, 9); err == nil {
+		t.Error("expected an error without enough prices for the signal")
+	}
+	if _, err := MACD(values, 26, 12, 9); err == nil {
+		t.Error("expected an error for a fast period longer than the slow one")
+	}
+}
+
+func TestBollingerBands(t *testing.T) {
+	bands, err := BollingerBands([]float64{2, 4, 4, 4, 5, 5, 7, 9}, 8, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Mean of 5 and population standard deviation of 2
+	if bands.Middle[7] != 5 || bands.Upper[7] != 9 || bands.Lower[7] != 1 {
+		t.Errorf("unexpected bands %v %v %v", bands.Middle[7], bands.Upper[7], bands.Lower[7])
+	}
+}
+
+func TestATRAndVWAP(t *testing.T) {
+	prices := closesPrices(10, 11, 12, 13)
+	if _, err := ATR(prices, 2); err == nil {
+		t.Error("expected an error without the highs and the lows")
+	}
+	if _, err := VWAP(prices); err == nil {
+		t.Error("expected an error without the volumes")
+	}
+
+	for i := range prices {
+		prices[i].High = prices[i].ClosePrice + 1
+		prices[i].Low = prices[i].ClosePrice - 1
+		prices[i].Volume = float64(i + 1)
+	}
+	atr, err := ATR(prices, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// True ranges of 2, from the lows to the highs
+	if !math.IsNaN(atr[1]) || atr[2] != 2 || atr[3] != 2 {
+		t.Errorf("unexpected ATR %v", atr)
+	}
+
+	vwap, err := VWAP(prices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// (10*1 + 11*2 + 12*3 + 13*4) / 10
+	if vwap[0] != 10 || !almostEqual(vwap[3], 12) {
+		t.Errorf("unexpected VWAP %v", vwap)
+	}
+}
+
+func TestFiftyTwoWeekRange(t *testing.T) {
+	closes := make([]float64, 400)
+	for i := range closes {
+		closes[i] = 100 + float64(i%50)
+	}
+	closes[10] = 500 // More than 52 weeks before the last price
+	closes[300] = 20
+	prices := closesPrices(closes...)
+
+	r, err := FiftyTwoWeekRange(prices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.High != 149 || r.Low != 20 || !r.LowDate.Equal(prices[300].Date) {
+		t.Errorf("unexpected range %+v", r)
+	}
+
+	if _, err := FiftyTwoWeekRange(prices[300:]); err == nil {
+		t.Error("expected an error for less than 52 weeks of prices")
+	}
+}
+
+func TestMovingAverageCrosses(t *testing.T) {
+	// Down then up then down
+	closes := []float64{10, 9, 8, 7, 6, 5, 6, 7, 8, 9, 10, 11, 10, 9, 8, 7}
+	prices := closesPrices(closes...)
+
+	crosses, fastAbove, err := MovingAverageCrosses(prices, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(crosses) != 2 || crosses[0].Type != CrossGolden || !crosses[0].Date.Equal(prices[7].Date) || crosses[1].Type != CrossDeath || fastAbove {
+		t.Errorf("unexpected crosses %+v, fast above %v", crosses, fastAbove)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	closes := make([]float64, 60)
+	for i := range closes {
+		closes[i] = float64(100 + i)
+	}
+	history := domain.HistoricalPrices{Prices: closesPrices(closes...)}
+
+	report, err := Compute(history, nil, Parameters{SMAPeriods: []int{10, 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.SMA) != 1 || report.SMA[0].Period != 10 || Last(report.SMA[0].Values) != 154.5 {
+		t.Errorf("unexpected SMA %+v", report.SMA)
+	}
+	if len(report.EMA) != 2 || report.RSI == nil || report.MACD == nil || report.BollingerBands == nil {
+		t.Errorf("expected the indicators of the closes, got %+v", report)
+	}
+	// The SMA 100, the ATR, the VWAP, the 52 week range and the crosses of the 50 and 200 days SMA are unavailable
+	unavailable := map[Indicator]bool{}
+	for _, u := range report.Unavailable {
+		unavailable[u.Indicator] = true
+	}
+	if len(report.Unavailable) != 5 || !unavailable[IndicatorSMA] || !unavailable[IndicatorATR] || !unavailable[IndicatorVWAP] ||
+		!unavailable[IndicatorFiftyTwoWeekRange] || !unavailable[IndicatorMovingAverageCrosses] {
+		t.Errorf("unexpected unavailable indicators %+v", report.Unavailable)
+	}
+
+	report, _ = Compute(history, []Indicator{IndicatorRSI}, Parameters{})
+	if report.RSI == nil || report.SMA != nil || report.MACD != nil {
+		t.Errorf("expected only the RSI, got %+v", report)
+	}
+
+	if _, err := Compute(history, []Indicator{"stochastic"}, Parameters{}); err == nil {
+		t.Error("expected an error for an unknown indicator")
+	}
+}
@@ -0,0 +1,333 @@
+package completions
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxCompletionValues is the maximum number of values a completion result can contain (MCP spec)
+const maxCompletionValues = 100
+
+type TickersSource interface {
+	GetTickers() ([]domain.Ticker, error)
+}
+
+type EtfsSource interface {
+	GetEtfs() ([]domain.Etf, error)
+}
+
+type CryptocurrenciesSource interface {
+	GetCryptocurrenciesList() ([]domain.Cryptocurrency, error)
+}
+
+type SuperInvestorsSource interface {
+	GetSuperInvestors() ([]domain.SuperInvestor, error)
+}
+
+type InvestingIdeasSource interface {
+	GetInvestingIdeas() ([]domain.InvestingIdea, error)
+}
+
+// Candidate is a possible completion value together with the text that is matched against the user input
+type Candidate struct {
+	Value    string
+	Keywords []string
+}
+
+// CandidatesProvider returns all the possible completion values of an argument
+type CandidatesProvider func() ([]Candidate, error)
+
+type Completer struct {
+	providers map[string]CandidatesProvider
+}
+
+func NewCompleter(
+	tickersSource TickersSource,
+	etfsSource EtfsSource,
+	cryptocurrenciesSource CryptocurrenciesSource,
+	superInvestorsSource SuperInvestorsSource,
+	investingIdeasSource InvestingIdeasSource,
+) (*Completer, error) {
+	c := &Completer{providers: make(map[string]CandidatesProvider)}
+
+	if tickersSource != nil {
+		c.RegisterArgument("stock_symbol", tickerCandidates(tickersSource))
+	}
+	if etfsSource != nil {
+		c.RegisterArgument("etf_symbol", etfCandidates(etfsSource))
+	}
+	if cryptocurrenciesSource != nil {
+		c.RegisterArgument("cryptocurrency_id", cryptocurrencyCandidates(cryptocurrenciesSource))
+	}
+	if superInvestorsSource != nil {
+		c.RegisterArgument("super_investor_name", superInvestorCandidates(superInvestorsSource))
+	}
+	if investingIdeasSource != nil {
+		c.RegisterArgument("idea_id", investingIdeaCandidates(investingIdeasSource))
+	}
+
+	return c, nil
+}
+
+// RegisterArgument registers (or replaces) the provider of the completion values for the argument with the given name
+func (c *Completer) RegisterArgument(argumentName string, provider CandidatesProvider) {
+	c.providers[argumentName] = provider
+}
+
+// RegisterEnumArguments registers the enum values declared in the jsonschema tags
+// of the given request struct (e.g. `jsonschema:"enum=CrudeOil,enum=NaturalGas"`)
+// as completion values of the corresponding json argument
+func (c *Completer) RegisterEnumArguments(request any) {
+	for argumentName, values := range enumValuesFromJsonschemaTags(request) {
+		candidates := make([]Candidate, 0, len(values))
+		for _, v := range values {
+			candidates = append(candidates, Candidate{Value: v})
+		}
+		c.RegisterArgument(argumentName, func() ([]Candidate, error) {
+			return candidates, nil
+		})
+	}
+}
+
+// HandleComplete handles a completion/complete request for prompt and resource template arguments.
+// The completion values are resolved using the argument name, so the same argument name gets the same
+// completions no matter which prompt or resource template it belongs to.
+func (c *Completer) HandleComplete(ctx context.Context, req mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	refType, err := referenceType(req.Params.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if refType != "ref/prompt" && refType != "ref/resource" {
+		return nil, fmt.Errorf("unsupported completion reference type: %s", refType)
+	}
+
+	result := &mcp.CompleteResult{}
+	result.Completion.Values = make([]string, 0)
+
+	provider, ok := c.providers[req.Params.Argument.Name]
+	if !ok {
+		return result, nil
+	}
+
+	candidates, err := provider()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := matchCandidates(candidates, req.Params.Argument.Value)
+	result.Completion.Total = len(matches)
+	if len(matches) > maxCompletionValues {
+		matches = matches[:maxCompletionValues]
+		result.Completion.HasMore = true
+	}
+	result.Completion.Values = matches
+
+	return result, nil
+}
+
+func referenceType(ref any) (string, error) {
+	switch r := ref.(type) {
+	case map[string]any:
+		refType, _ := r["type"].(string)
+		return refType, nil
+	case mcp.PromptReference:
+		return r.Type, nil
+	case mcp.ResourceReference:
+		return r.Type, nil
+	default:
+		return "", fmt.Errorf("invalid completion reference")
+	}
+}
+
+// matchCandidates returns the values of the candidates matching the given input.
+// Values starting with the input come first, then values with a keyword starting with the input
+// and last the values with a keyword that contains the input.
+func matchCandidates(candidates []Candidate, input string) []string {
+	input = strings.ToLower(strings.TrimSpace(input))
+
+	const (
+		valuePrefixMatch = iota
+		keywordPrefixMatch
+		containsMatch
+		noMatch
+	)
+
+	type match struct {
+		value string
+		rank  int
+	}
+
+	matches := make([]match, 0)
+	seen := make(map[string]bool)
+	for _, cand := range candidates {
+		if cand.Value == "" || seen[cand.Value] {
+			continue
+		}
+
+		rank := noMatch
+		value := strings.ToLower(cand.Value)
+		switch {
+		case strings.HasPrefix(value, input):
+			rank = valuePrefixMatch
+		case strings.Contains(value, input):
+			rank = containsMatch
+		}
+
+		for _, keyword := range cand.Keywords {
+			keyword = strings.ToLower(keyword)
+			if rank > keywordPrefixMatch && hasWordPrefix(keyword, input) {
+				rank = keywordPrefixMatch
+			} else if rank > containsMatch && strings.Contains(keyword, input) {
+				rank = containsMatch
+			}
+		}
+
+		if rank == noMatch {
+			continue
+		}
+		seen[cand.Value] = true
+		matches = append(matches, match{value: cand.Value, rank: rank})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		// Shorter values first so that "AAPL" comes before "AAPLX"
+		return len(matches[i].value) < len(matches[j].value)
+	})
+
+	values := make([]string, 0, len(matches))
+	for _, m := range matches {
+		values = append(values, m.value)
+	}
+	return values
+}
+
+// hasWordPrefix returns true if any word of the text starts with the given prefix
+func hasWordPrefix(text, prefix string) bool {
+	if strings.HasPrefix(text, prefix) {
+		return true
+	}
+	for _, word := range strings.Fields(text) {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func tickerCandidates(source TickersSource) CandidatesProvider {
+	return func() ([]Candidate, error) {
+		tickers, err := source.GetTickers()
+		if err != nil {
+			return nil, err
+		}
+		candidates := make([]Candidate, 0, len(tickers))
+		for _, t := range tickers {
+			candidates = append(candidates, Candidate{Value: t.Symbol, Keywords: []string{t.CompanyName}})
+		}
+		return candidates, nil
+	}
+}
+
+func etfCandidates(source EtfsSource) CandidatesProvider {
+	return func() ([]Candidate, error) {
+		etfs, err := source.GetEtfs()
+		if err != nil {
+			return nil, err
+		}
+		candidates := make([]Candidate, 0, len(etfs))
+		for _, e := range etfs {
+			candidates = append(candidates, Candidate{Value: e.Symbol, Keywords: []string{e.Name}})
+		}
+		return candidates, nil
+	}
+}
+
+func cryptocurrencyCandidates(source CryptocurrenciesSource) CandidatesProvider {
+	return func() ([]Candidate, error) {
+		cryptocurrencies, err := source.GetCryptocurrenciesList()
+		if err != nil {
+			return nil, err
+		}
+		candidates := make([]Candidate, 0, len(cryptocurrencies))
+		for _, c := range cryptocurrencies {
+			// The CoinGecko ids are rarely known, users type the name or the symbol
+			candidates = append(candidates, Candidate{Value: c.Id, Keywords: []string{c.Name, c.Symbol}})
+		}
+		return candidates, nil
+	}
+}
+
+func superInvestorCandidates(source SuperInvestorsSource) CandidatesProvider {
+	return func() ([]Candidate, error) {
+		superInvestors, err := source.GetSuperInvestors()
+		if err != nil {
+			return nil, err
+		}
+		candidates := make([]Candidate, 0, len(superInvestors))
+		for _, si := range superInvestors {
+			// The names have the format "Manager - Firm" so users can start typing either of them
+			candidates = append(candidates, Candidate{Value: si.Name, Keywords: strings.Split(si.Name, " - ")})
+		}
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].Value < candidates[j].Value })
+		return candidates, nil
+	}
+}
+
+func investingIdeaCandidates(source InvestingIdeasSource) CandidatesProvider {
+	return func() ([]Candidate, error) {
+		ideas, err := source.GetInvestingIdeas()
+		if err != nil {
+			return nil, err
+		}
+		candidates := make([]Candidate, 0, len(ideas))
+		for _, idea := range ideas {
+			// Users know the title of the idea, not the id
+			candidates = append(candidates, Candidate{Value: idea.ID, Keywords: []string{idea.Title}})
+		}
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].Keywords[0] < candidates[j].Keywords[0] })
+		return candidates, nil
+	}
+}
+
+// enumValuesFromJsonschemaTags returns a map with key the json name of each field
+// of the given struct and value the enum values declared in its jsonschema tag
+func enumValuesFromJsonschemaTags(request any) map[string][]string {
+	enums := make(map[string][]string)
+
+	t := reflect.TypeOf(request)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return enums
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName == "" || jsonName == "-" {
+			continue
+		}
+
+		var values []string
+		for _, option := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+			if value, ok := strings.CutPrefix(option, "enum="); ok {
+				values = append(values, value)
+			}
+		}
+		if len(values) > 0 {
+			enums[jsonName] = values
+		}
+	}
+
+	return enums
+}
@@ -0,0 +1,156 @@
+package completions
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/api/mcp/tools"
+	"market_data_mcp_server/pkg/domain"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type stubTickers []domain.Ticker
+
+func (s stubTickers) GetTickers() ([]domain.Ticker, error) {
+	return s, nil
+}
+
+type stubCryptocurrencies []domain.Cryptocurrency
+
+func (s stubCryptocurrencies) GetCryptocurrenciesList() ([]domain.Cryptocurrency, error) {
+	return s, nil
+}
+
+type stubSuperInvestors []domain.SuperInvestor
+
+func (s stubSuperInvestors) GetSuperInvestors() ([]domain.SuperInvestor, error) {
+	return s, nil
+}
+
+type commodityRequest struct {
+	CommodityName string `json:"commodity_name" jsonschema:"enum=CrudeOil,enum=NaturalGas,enum=Copper"`
+}
+
+func completeRequest(ref any, argument string, value string) mcp.CompleteRequest {
+	req := mcp.CompleteRequest{}
+	req.Params.Ref = ref
+	req.Params.Argument.Name = argument
+	req.Params.Argument.Value = value
+	return req
+}
+
+func TestHandleComplete(t *testing.T) {
+	tickers := stubTickers{
+		{Symbol: "AAPLX", CompanyName: "Apple Leveraged"},
+		{Symbol: "AAPL", CompanyName: "Apple Inc."},
+		{Symbol: "MSFT", CompanyName: "Microsoft Corporation"},
+		{Symbol: "PAA", CompanyName: "Plains All American"},
+	}
+	cryptocurrencies := stubCryptocurrencies{
+		{Id: "bitcoin", Symbol: "btc", Name: "Bitcoin"},
+		{Id: "wrapped-bitcoin", Symbol: "wbtc", Name: "Wrapped Bitcoin"},
+		{Id: "ethereum", Symbol: "eth", Name: "Ethereum"},
+	}
+	superInvestors := stubSuperInvestors{{Name: "Warren Buffett - Berkshire Hathaway"}, {Name: "Bill Ackman - Pershing Square"}}
+	c, _ := NewCompleter(tickers, nil, cryptocurrencies, superInvestors, nil)
+	c.RegisterEnumArguments(commodityRequest{})
+	c.RegisterEnumArguments(tools.GetCurrencyExchangeRateRequest{})
+
+	tests := []struct {
+		name     string
+		req      mcp.CompleteRequest
+		expected []string
+	}{
+		{
+			name:     "prompt argument",
+			req:      completeRequest(mcp.PromptReference{Type: "ref/prompt", Name: "stockAnalysis"}, "stock_symbol", "aap"),
+			expected: []string{"AAPL", "AAPLX"},
+		},
+		{
+			name:     "prompt argument matched on the company name",
+			req:      completeRequest(map[string]any{"type": "ref/prompt", "name": "stockAnalysis"}, "stock_symbol", "micro"),
+			expected: []string{"MSFT"},
+		},
+		{
+			name:     "symbol prefix, then name prefix, then contained",
+			req:      completeRequest(mcp.PromptReference{Type: "ref/prompt", Name: "stockAnalysis"}, "stock_symbol", "a"),
+			expected: []string{"AAPL", "AAPLX", "PAA", "MSFT"},
+		},
+		{
+			name:     "resource template variable",
+			req:      completeRequest(mcp.ResourceReference{Type: "ref/resource", URI: "superinvestor://{super_investor_name}/portfolio"}, "super_investor_name", "pershing"),
+			expected: []string{"Bill Ackman - Pershing Square"},
+		},
+		{
+			name:     "enum argument",
+			req:      completeRequest(mcp.PromptReference{Type: "ref/prompt", Name: "commodityOutlook"}, "commodity_name", "c"),
+			expected: []string{"Copper", "CrudeOil"},
+		},
+		{
+			name:     "cryptocurrency id matched on the name",
+			req:      completeRequest(mcp.PromptReference{Type: "ref/prompt", Name: "analyzeCryptocurrency"}, "cryptocurrency_id", "bitc"),
+			expected: []string{"bitcoin", "wrapped-bitcoin"},
+		},
+		{
+			name:     "cryptocurrency id matched on the symbol",
+			req:      completeRequest(mcp.PromptReference{Type: "ref/prompt", Name: "analyzeCryptocurrency"}, "cryptocurrency_id", "eth"),
+			expected: []string{"ethereum"},
+		},
+		{
+			name:     "currency enum",
+			req:      completeRequest(mcp.PromptReference{Type: "ref/prompt", Name: "currencyOutlook"}, "from_currency", "c"),
+			expected: []string{"CHF", "CAD"},
+		},
+		{
+			name:     "other currency enum",
+			req:      completeRequest(mcp.PromptReference{Type: "ref/prompt", Name: "currencyOutlook"}, "to_currency", "eu"),
+			expected: []string{"EUR"},
+		},
+		{
+			name:     "unknown argument",
+			req:      completeRequest(mcp.PromptReference{Type: "ref/prompt", Name: "stockAnalysis"}, "symbol", "aap"),
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.HandleComplete(context.Background(), tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.Completion.Values, tt.expected) || result.Completion.Total != len(tt.expected) || result.Completion.HasMore {
+				t.Errorf("expected %v, got %+v", tt.expected, result.Completion)
+			}
+		})
+	}
+}
+
+func TestHandleCompleteUnknownReference(t *testing.T) {
+	c, _ := NewCompleter(stubTickers{{Symbol: "AAPL"}}, nil, nil, nil, nil)
+
+	for _, ref := range []any{map[string]any{"type": "ref/tool"}, "ref/prompt", nil} {
+		if _, err := c.HandleComplete(context.Background(), completeRequest(ref, "stock_symbol", "a")); err == nil {
+			t.Errorf("expected an error for the reference %v", ref)
+		}
+	}
+}
+
+func TestHandleCompleteCapsValues(t *testing.T) {
+	tickers := make(stubTickers, 150)
+	for i := range tickers {
+		tickers[i] = domain.Ticker{Symbol: fmt.Sprintf("A%03d", i)}
+	}
+	c, _ := NewCompleter(tickers, nil, nil, nil, nil)
+
+	result, err := c.HandleComplete(context.Background(), completeRequest(mcp.PromptReference{Type: "ref/prompt"}, "stock_symbol", "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Completion.Values) != maxCompletionValues || result.Completion.Total != 150 || !result.Completion.HasMore {
+		t.Errorf("expected %d of 150 values, got %d of %d (has more: %v)",
+			maxCompletionValues, len(result.Completion.Values), result.Completion.Total, result.Completion.HasMore)
+	}
+}
@@ -0,0 +1,40 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type CommodityOutlookPrompt struct{}
+
+func NewCommodityOutlookPrompt() (*CommodityOutlookPrompt, error) {
+	return &CommodityOutlookPrompt{}, nil
+}
+
+func (p *CommodityOutlookPrompt) HandleCommodityOutlook(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	commodityName := req.Params.Arguments["commodity_name"]
+	if commodityName == "" {
+		return nil, fmt.Errorf("commodity_name is required")
+	}
+
+	text := fmt.Sprintf(
+		"Give an outlook for %s. Use the getCommodityTimeSeries tool with commodity_name %s to get its recent prices "+
+			"and the getEconomicIndicatorTimeSeries tool for the Inflation and InterestRate indicators. "+
+			"Describe the recent trend and how the macro environment could affect it.",
+		commodityName, commodityName,
+	)
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Outlook for %s", commodityName),
+		[]mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text))},
+	), nil
+}
+
+func (p *CommodityOutlookPrompt) GetPrompt() mcp.Prompt {
+	return mcp.NewPrompt("commodityOutlook",
+		mcp.WithPromptDescription("Give an outlook for a commodity based on its prices and the macro environment"),
+		mcp.WithArgument("commodity_name", mcp.ArgumentDescription("Name of the commodity"), mcp.RequiredArgument()),
+	)
+}
@@ -0,0 +1,41 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type AnalyzeCryptocurrencyPrompt struct{}
+
+func NewAnalyzeCryptocurrencyPrompt() (*AnalyzeCryptocurrencyPrompt, error) {
+	return &AnalyzeCryptocurrencyPrompt{}, nil
+}
+
+func (p *AnalyzeCryptocurrencyPrompt) HandleAnalyzeCryptocurrency(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	id := strings.ToLower(strings.TrimSpace(req.Params.Arguments["cryptocurrency_id"]))
+	if id == "" {
+		return nil, fmt.Errorf("cryptocurrency_id is required")
+	}
+
+	text := fmt.Sprintf(
+		"Analyze the cryptocurrency %s. Use the getCryptocurrencyDataById tool with id %s to get its price, market cap, "+
+			"supply and performance, and the getCryptocurrencyNews tool with its symbol to get its recent news. "+
+			"Summarize its momentum, its supply and the main risks.",
+		id, id,
+	)
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Analysis of the cryptocurrency %s", id),
+		[]mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text))},
+	), nil
+}
+
+func (p *AnalyzeCryptocurrencyPrompt) GetPrompt() mcp.Prompt {
+	return mcp.NewPrompt("analyzeCryptocurrency",
+		mcp.WithPromptDescription("Analyze a cryptocurrency using its market data and news"),
+		mcp.WithArgument("cryptocurrency_id", mcp.ArgumentDescription("CoinGecko id of the cryptocurrency (e.g. bitcoin)"), mcp.RequiredArgument()),
+	)
+}
@@ -0,0 +1,46 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type CurrencyOutlookPrompt struct{}
+
+func NewCurrencyOutlookPrompt() (*CurrencyOutlookPrompt, error) {
+	return &CurrencyOutlookPrompt{}, nil
+}
+
+func (p *CurrencyOutlookPrompt) HandleCurrencyOutlook(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	fromCurrency := strings.ToUpper(req.Params.Arguments["from_currency"])
+	if fromCurrency == "" {
+		return nil, fmt.Errorf("from_currency is required")
+	}
+	toCurrency := strings.ToUpper(req.Params.Arguments["to_currency"])
+	if toCurrency == "" {
+		return nil, fmt.Errorf("to_currency is required")
+	}
+
+	text := fmt.Sprintf(
+		"Give an outlook for the exchange rate of %s to %s. Use the getCurrencyExchangeRate tool with from_currency %s "+
+			"and to_currency %s to get the current rate, and the getEconomicIndicatorTimeSeries tool for the Inflation and "+
+			"InterestRate indicators. Describe how the macro environment could move the rate.",
+		fromCurrency, toCurrency, fromCurrency, toCurrency,
+	)
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Outlook for %s/%s", fromCurrency, toCurrency),
+		[]mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text))},
+	), nil
+}
+
+func (p *CurrencyOutlookPrompt) GetPrompt() mcp.Prompt {
+	return mcp.NewPrompt("currencyOutlook",
+		mcp.WithPromptDescription("Give an outlook for an exchange rate based on the macro environment"),
+		mcp.WithArgument("from_currency", mcp.ArgumentDescription("Currency code of the currency to convert from"), mcp.RequiredArgument()),
+		mcp.WithArgument("to_currency", mcp.ArgumentDescription("Currency code of the currency to convert to"), mcp.RequiredArgument()),
+	)
+}
@@ -0,0 +1,40 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type ExploreInvestingIdeaPrompt struct{}
+
+func NewExploreInvestingIdeaPrompt() (*ExploreInvestingIdeaPrompt, error) {
+	return &ExploreInvestingIdeaPrompt{}, nil
+}
+
+func (p *ExploreInvestingIdeaPrompt) HandleExploreInvestingIdea(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	ideaID := req.Params.Arguments["idea_id"]
+	if ideaID == "" {
+		return nil, fmt.Errorf("idea_id is required")
+	}
+
+	text := fmt.Sprintf(
+		"Explore the investing idea with id %s. Use the getInvestingIdeaStocks tool to get the companies of the idea, "+
+			"then use the stockSearch and getStockOverview tools on the most relevant ones. "+
+			"Explain the theme and compare the companies that are best positioned to benefit from it.",
+		ideaID,
+	)
+
+	return mcp.NewGetPromptResult(
+		"Exploration of an investing idea",
+		[]mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text))},
+	), nil
+}
+
+func (p *ExploreInvestingIdeaPrompt) GetPrompt() mcp.Prompt {
+	return mcp.NewPrompt("exploreInvestingIdea",
+		mcp.WithPromptDescription("Explore an investing idea/theme and the companies behind it"),
+		mcp.WithArgument("idea_id", mcp.ArgumentDescription("The id of the investing idea"), mcp.RequiredArgument()),
+	)
+}
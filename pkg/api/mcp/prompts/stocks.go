@@ -0,0 +1,72 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type AnalyzeStockPrompt struct{}
+
+func NewAnalyzeStockPrompt() (*AnalyzeStockPrompt, error) {
+	return &AnalyzeStockPrompt{}, nil
+}
+
+func (p *AnalyzeStockPrompt) HandleAnalyzeStock(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	symbol := strings.ToUpper(req.Params.Arguments["stock_symbol"])
+	if symbol == "" {
+		return nil, fmt.Errorf("stock_symbol is required")
+	}
+
+	text := fmt.Sprintf(
+		"Analyze the stock %s. Use the getStockOverview tool to get its profile, financial ratios, forecasts and performance, "+
+			"the getStockFinancials tool to get its latest financial statements and the getMarketNews tool to get its recent news. "+
+			"Summarize the business, its valuation, its financial health and the main risks.",
+		symbol,
+	)
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Analysis of the stock %s", symbol),
+		[]mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text))},
+	), nil
+}
+
+func (p *AnalyzeStockPrompt) GetPrompt() mcp.Prompt {
+	return mcp.NewPrompt("analyzeStock",
+		mcp.WithPromptDescription("Analyze a stock using its overview, financials and news"),
+		mcp.WithArgument("stock_symbol", mcp.ArgumentDescription("Symbol of the stock to analyze"), mcp.RequiredArgument()),
+	)
+}
+
+type AnalyzeEtfPrompt struct{}
+
+func NewAnalyzeEtfPrompt() (*AnalyzeEtfPrompt, error) {
+	return &AnalyzeEtfPrompt{}, nil
+}
+
+func (p *AnalyzeEtfPrompt) HandleAnalyzeEtf(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	symbol := strings.ToUpper(req.Params.Arguments["etf_symbol"])
+	if symbol == "" {
+		return nil, fmt.Errorf("etf_symbol is required")
+	}
+
+	text := fmt.Sprintf(
+		"Analyze the ETF %s. Use the getETF tool to get its holdings, costs and performance. "+
+			"Summarize what it invests in, how concentrated it is, what it costs and how it performed.",
+		symbol,
+	)
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Analysis of the ETF %s", symbol),
+		[]mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text))},
+	), nil
+}
+
+func (p *AnalyzeEtfPrompt) GetPrompt() mcp.Prompt {
+	return mcp.NewPrompt("analyzeEtf",
+		mcp.WithPromptDescription("Analyze an ETF using its holdings, costs and performance"),
+		mcp.WithArgument("etf_symbol", mcp.ArgumentDescription("Symbol of the ETF to analyze"), mcp.RequiredArgument()),
+	)
+}
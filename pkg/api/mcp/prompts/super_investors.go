@@ -0,0 +1,39 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type ReviewSuperInvestorPortfolioPrompt struct{}
+
+func NewReviewSuperInvestorPortfolioPrompt() (*ReviewSuperInvestorPortfolioPrompt, error) {
+	return &ReviewSuperInvestorPortfolioPrompt{}, nil
+}
+
+func (p *ReviewSuperInvestorPortfolioPrompt) HandleReviewSuperInvestorPortfolio(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	superInvestorName := req.Params.Arguments["super_investor_name"]
+	if superInvestorName == "" {
+		return nil, fmt.Errorf("super_investor_name is required")
+	}
+
+	text := fmt.Sprintf(
+		"Review the portfolio of the super investor \"%s\". Use the getSuperInvestorPortfolio tool with this exact name. "+
+			"Describe the largest positions, the sector allocation and the most recent buys and sells.",
+		superInvestorName,
+	)
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Portfolio review of %s", superInvestorName),
+		[]mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text))},
+	), nil
+}
+
+func (p *ReviewSuperInvestorPortfolioPrompt) GetPrompt() mcp.Prompt {
+	return mcp.NewPrompt("reviewSuperInvestorPortfolio",
+		mcp.WithPromptDescription("Review the portfolio of a super investor (Portfolio Manager - Firm)"),
+		mcp.WithArgument("super_investor_name", mcp.ArgumentDescription("The name of the super investor (Portfolio Manager - Firm)"), mcp.RequiredArgument()),
+	)
+}
@@ -0,0 +1,45 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type EtfService interface {
+	GetEtf(etfSymbol string) (domain.EtfOverview, error)
+}
+
+type EtfResource struct {
+	etfService EtfService
+}
+
+func NewEtfResource(etfService EtfService) (*EtfResource, error) {
+	return &EtfResource{etfService: etfService}, nil
+}
+
+func (r *EtfResource) HandleReadEtf(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	etfSymbol := templateArgument(req, "etf_symbol")
+	if etfSymbol == "" {
+		return nil, fmt.Errorf("etf_symbol is required")
+	}
+
+	etf, err := r.etfService.GetEtf(strings.ToLower(etfSymbol))
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonResourceContents(req.Params.URI, etf)
+}
+
+func (r *EtfResource) GetResourceTemplate() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		"etf://{etf_symbol}",
+		"ETF overview",
+		mcp.WithTemplateDescription("The overview (description, costs, performance and top holdings) of an ETF"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
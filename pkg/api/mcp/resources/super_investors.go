@@ -0,0 +1,44 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type SuperInvestorsService interface {
+	GetSuperInvestorPortfolio(superInvestorName string) (domain.SuperInvestorPortfolio, error)
+}
+
+type SuperInvestorPortfolioResource struct {
+	superInvestorsService SuperInvestorsService
+}
+
+func NewSuperInvestorPortfolioResource(superInvestorsService SuperInvestorsService) (*SuperInvestorPortfolioResource, error) {
+	return &SuperInvestorPortfolioResource{superInvestorsService: superInvestorsService}, nil
+}
+
+func (r *SuperInvestorPortfolioResource) HandleReadSuperInvestorPortfolio(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	superInvestorName := templateArgument(req, "super_investor_name")
+	if superInvestorName == "" {
+		return nil, fmt.Errorf("super_investor_name is required")
+	}
+
+	portfolio, err := r.superInvestorsService.GetSuperInvestorPortfolio(superInvestorName)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonResourceContents(req.Params.URI, portfolio)
+}
+
+func (r *SuperInvestorPortfolioResource) GetResourceTemplate() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		"superinvestor://{super_investor_name}/portfolio",
+		"Super investor portfolio",
+		mcp.WithTemplateDescription("The holdings and sector analysis of a super investor (Portfolio Manager - Firm)"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
@@ -0,0 +1,328 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type AlertService interface {
+	GetAlerts(userID string) ([]domain.Alert, error)
+	CreateAlert(alert domain.Alert) (domain.Alert, error)
+	DeleteAlert(userID string, alertID int) (domain.Alert, error)
+	GetAlertEvents(userID string, limit int) ([]domain.AlertEvent, error)
+}
+
+type AlertEvaluationService interface {
+	EvaluateUserAlerts(ctx context.Context, userID string) ([]domain.AlertEvent, error)
+}
+
+type AlertSchema struct {
+	ID              int     `json:"id"`
+	Type            string  `json:"type" jsonschema:"enum=price_above,enum=price_below,enum=percent_move,enum=ratio_above,enum=ratio_below,enum=insider_buy,enum=news_mention"`
+	Symbol          string  `json:"symbol"`
+	AssetClass      string  `json:"asset_class" jsonschema:"enum=stock,enum=etf,enum=crypto"`
+	Threshold       float64 `json:"threshold,omitempty"`
+	Period          string  `json:"period,omitempty"`
+	Ratio           string  `json:"ratio,omitempty"`
+	Keyword         string  `json:"keyword,omitempty"`
+	CooldownMinutes int     `json:"cooldown_minutes"`
+	WebhookURL      string  `json:"webhook_url,omitempty"`
+	Note            string  `json:"note,omitempty"`
+	CreatedAt       string  `json:"created_at" jsonschema_description:"ISO 8601 format"`
+	// State
+	Active          bool    `json:"active" jsonschema_description:"Whether the condition of a threshold alert held on the last evaluation, it triggers again only once it stopped holding"`
+	LastValue       float64 `json:"last_value" jsonschema_description:"Price, percentage or ratio of the last evaluation, number of new items for the insider buys and the news mentions"`
+	LastEvaluatedAt string  `json:"last_evaluated_at,omitempty" jsonschema_description:"ISO 8601 format"`
+	LastTriggeredAt string  `json:"last_triggered_at,omitempty" jsonschema_description:"ISO 8601 format"`
+	LastError       string  `json:"last_error,omitempty" jsonschema_description:"Why the last evaluation failed"`
+}
+
+func newAlertSchema(a domain.Alert) AlertSchema {
+	return AlertSchema{
+		ID:              a.ID,
+		Type:            string(a.Type),
+		Symbol:          a.Symbol,
+		AssetClass:      string(a.AssetClass),
+		Threshold:       a.Threshold,
+		Period:          string(a.Period),
+		Ratio:           a.Ratio,
+		Keyword:         a.Keyword,
+		CooldownMinutes: a.CooldownMinutes,
+		WebhookURL:      a.WebhookURL,
+		Note:            a.Note,
+		CreatedAt:       a.CreatedAt,
+		Active:          a.State.Active,
+		LastValue:       a.State.LastValue,
+		LastEvaluatedAt: a.State.LastEvaluatedAt,
+		LastTriggeredAt: a.State.LastTriggeredAt,
+		LastError:       a.State.LastError,
+	}
+}
+
+type AlertEventSchema struct {
+	ID                 int      `json:"id"`
+	AlertID            int      `json:"alert_id"`
+	Type               string   `json:"type"`
+	Symbol             string   `json:"symbol"`
+	Message            string   `json:"message"`
+	Value              float64  `json:"value" jsonschema_description:"Price, percentage or ratio that triggered the alert, number of new items for the insider buys and the news mentions"`
+	TriggeredAt        string   `json:"triggered_at" jsonschema_description:"ISO 8601 format"`
+	NotificationErrors []string `json:"notification_errors,omitempty" jsonschema_description:"Notifications that couldn't be delivered"`
+}
+
+func newAlertEventSchemas(events []domain.AlertEvent) []AlertEventSchema {
+	schemas := make([]AlertEventSchema, 0, len(events))
+	for _, e := range events {
+		schemas = append(schemas, AlertEventSchema{
+			ID:                 e.ID,
+			AlertID:            e.AlertID,
+			Type:               string(e.Type),
+			Symbol:             e.Symbol,
+			Message:            e.Message,
+			Value:              e.Value,
+			TriggeredAt:        e.TriggeredAt,
+			NotificationErrors: e.NotificationErrors,
+		})
+	}
+	return schemas
+}
+
+type CreateAlertRequest struct {
+	UserID          string  `json:"user_id" jsonschema_description:"The id of the user"`
+	Type            string  `json:"type" jsonschema:"enum=price_above,enum=price_below,enum=percent_move,enum=ratio_above,enum=ratio_below,enum=insider_buy,enum=news_mention"`
+	Symbol          string  `json:"symbol" jsonschema_description:"Symbol of the stock, the ETF or the cryptocurrency (e.g. AAPL, SPY, BTC)"`
+	AssetClass      string  `json:"asset_class,omitempty" jsonschema:"enum=stock,enum=etf,enum=crypto,default=stock" jsonschema_description:"The ratio, insider buy and news mention alerts are only available for stocks"`
+	Threshold       float64 `json:"threshold,omitempty" jsonschema_description:"Price in USD of the price alerts, percentage of the percent moves (5 for a rise of 5% or more, -5 for a drop of 5% or more), value of the ratio alerts in the unit of the ratio (20 for a ROE of 20%)"`
+	Period          string  `json:"period,omitempty" jsonschema:"enum=1d,enum=5d,enum=1m,enum=6m,enum=1y,default=1d" jsonschema_description:"Period of the percent moves, 5d and 6m aren't available for the cryptocurrencies"`
+	Ratio           string  `json:"ratio,omitempty" jsonschema:"enum=market_cap,enum=pe,enum=ps,enum=pb,enum=pfcf,enum=ev_ebitda,enum=ev_revenue,enum=debt_equity,enum=current_ratio,enum=roe,enum=roic,enum=dividend_yield,enum=fcf_yield" jsonschema_description:"Trailing twelve months ratio of the ratio alerts, the returns and yields in percent"`
+	Keyword         string  `json:"keyword,omitempty" jsonschema_description:"Keyword the new articles of a news mention alert must contain, every new article when empty"`
+	CooldownMinutes int     `json:"cooldown_minutes,omitempty" jsonschema:"default=60" jsonschema_description:"Minimum time between two triggers, the triggers within it are delayed to its end"`
+	WebhookURL      string  `json:"webhook_url,omitempty" jsonschema_description:"URL also notified by a POST of the events in JSON"`
+	Note            string  `json:"note,omitempty"`
+}
+
+type CreateAlertTool struct {
+	alertService AlertService
+}
+
+func NewCreateAlertTool(alertService AlertService) (*CreateAlertTool, error) {
+	return &CreateAlertTool{
+		alertService: alertService,
+	}, nil
+}
+
+func (t *CreateAlertTool) HandleCreateAlert(ctx context.Context, req mcp.CallToolRequest, args CreateAlertRequest) (AlertSchema, error) {
+	if args.UserID == "" {
+		return AlertSchema{}, fmt.Errorf("user_id is required")
+	}
+	if args.Type == "" {
+		return AlertSchema{}, fmt.Errorf("type is required")
+	}
+
+	alert, err := t.alertService.CreateAlert(domain.Alert{
+		UserID:          args.UserID,
+		Type:            domain.AlertType(args.Type),
+		Symbol:          args.Symbol,
+		AssetClass:      domain.AssetClass(args.AssetClass),
+		Threshold:       args.Threshold,
+		Period:          domain.Period(args.Period),
+		Ratio:           args.Ratio,
+		Keyword:         args.Keyword,
+		CooldownMinutes: args.CooldownMinutes,
+		WebhookURL:      args.WebhookURL,
+		Note:            args.Note,
+	})
+	if err != nil {
+		return AlertSchema{}, err
+	}
+	return newAlertSchema(alert), nil
+}
+
+func (t *CreateAlertTool) GetTool() mcp.Tool {
+	return mcp.NewTool("createAlert",
+		mcp.WithDescription("Create an alert for the user on a price threshold, a percent move over a period, a financial ratio threshold, the new insider buys or the new news articles of a symbol. "+
+			"The alerts are evaluated on a schedule, their events are sent to the MCP sessions of the user as logging notifications (logger alerts, level notice) and to the optional webhook. "+
+			"A threshold alert triggers when its condition becomes true, the insider buy and news mention alerts when items appear after their creation."),
+		mcp.WithInputSchema[CreateAlertRequest](),
+		mcp.WithOutputSchema[AlertSchema](),
+	)
+}
+
+type ListAlertsRequest struct {
+	UserID string `json:"user_id" jsonschema_description:"The id of the user"`
+}
+
+type ListAlertsResponse struct {
+	UserID string        `json:"user_id"`
+	Alerts []AlertSchema `json:"alerts" jsonschema_description:"Alerts of the user in the order they were created"`
+}
+
+type ListAlertsTool struct {
+	alertService AlertService
+}
+
+func NewListAlertsTool(alertService AlertService) (*ListAlertsTool, error) {
+	return &ListAlertsTool{
+		alertService: alertService,
+	}, nil
+}
+
+func (t *ListAlertsTool) HandleListAlerts(ctx context.Context, req mcp.CallToolRequest, args ListAlertsRequest) (ListAlertsResponse, error) {
+	if args.UserID == "" {
+		return ListAlertsResponse{}, fmt.Errorf("user_id is required")
+	}
+
+	alerts, err := t.alertService.GetAlerts(args.UserID)
+	if err != nil {
+		return ListAlertsResponse{}, err
+	}
+
+	schemas := make([]AlertSchema, 0, len(alerts))
+	for _, alert := range alerts {
+		schemas = append(schemas, newAlertSchema(alert))
+	}
+	return ListAlertsResponse{UserID: args.UserID, Alerts: schemas}, nil
+}
+
+func (t *ListAlertsTool) GetTool() mcp.Tool {
+	return mcp.NewTool("listAlerts",
+		mcp.WithDescription("List the alerts of the user with the state of their last evaluation"),
+		mcp.WithInputSchema[ListAlertsRequest](),
+		mcp.WithOutputSchema[ListAlertsResponse](),
+	)
+}
+
+type DeleteAlertRequest struct {
+	UserID  string `json:"user_id" jsonschema_description:"The id of the user"`
+	AlertID int    `json:"alert_id" jsonschema_description:"Id of the alert, as returned by createAlert or listAlerts"`
+}
+
+type DeleteAlertTool struct {
+	alertService AlertService
+}
+
+func NewDeleteAlertTool(alertService AlertService) (*DeleteAlertTool, error) {
+	return &DeleteAlertTool{
+		alertService: alertService,
+	}, nil
+}
+
+func (t *DeleteAlertTool) HandleDeleteAlert(ctx context.Context, req mcp.CallToolRequest, args DeleteAlertRequest) (AlertSchema, error) {
+	if args.UserID == "" {
+		return AlertSchema{}, fmt.Errorf("user_id is required")
+	}
+	if args.AlertID <= 0 {
+		return AlertSchema{}, fmt.Errorf("alert_id is required")
+	}
+
+	alert, err := t.alertService.DeleteAlert(args.UserID, args.AlertID)
+	if err != nil {
+		return AlertSchema{}, err
+	}
+	return newAlertSchema(alert), nil
+}
+
+func (t *DeleteAlertTool) GetTool() mcp.Tool {
+	return mcp.NewTool("deleteAlert",
+		mcp.WithDescription("Delete an alert of the user, its past events are kept. Returns the deleted alert."),
+		mcp.WithInputSchema[DeleteAlertRequest](),
+		mcp.WithOutputSchema[AlertSchema](),
+	)
+}
+
+type GetAlertEventsRequest struct {
+	UserID string `json:"user_id" jsonschema_description:"The id of the user"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"default=20" jsonschema_description:"Maximum number of events, the last 100 events are kept"`
+}
+
+type GetAlertEventsResponse struct {
+	UserID string             `json:"user_id"`
+	Events []AlertEventSchema `json:"events" jsonschema_description:"The most recent first"`
+}
+
+type GetAlertEventsTool struct {
+	alertService AlertService
+}
+
+func NewGetAlertEventsTool(alertService AlertService) (*GetAlertEventsTool, error) {
+	return &GetAlertEventsTool{
+		alertService: alertService,
+	}, nil
+}
+
+func (t *GetAlertEventsTool) HandleGetAlertEvents(ctx context.Context, req mcp.CallToolRequest, args GetAlertEventsRequest) (GetAlertEventsResponse, error) {
+	if args.UserID == "" {
+		return GetAlertEventsResponse{}, fmt.Errorf("user_id is required")
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	}
+
+	events, err := t.alertService.GetAlertEvents(args.UserID, args.Limit)
+	if err != nil {
+		return GetAlertEventsResponse{}, err
+	}
+	return GetAlertEventsResponse{UserID: args.UserID, Events: newAlertEventSchemas(events)}, nil
+}
+
+func (t *GetAlertEventsTool) GetTool() mcp.Tool {
+	return mcp.NewTool("getAlertEvents",
+		mcp.WithDescription("Get the last events triggered by the alerts of the user, including the ones whose notifications were missed"),
+		mcp.WithInputSchema[GetAlertEventsRequest](),
+		mcp.WithOutputSchema[GetAlertEventsResponse](),
+	)
+}
+
+type CheckAlertsRequest struct {
+	UserID string `json:"user_id" jsonschema_description:"The id of the user"`
+}
+
+type CheckAlertsResponse struct {
+	UserID string             `json:"user_id"`
+	Events []AlertEventSchema `json:"events" jsonschema_description:"Events triggered by this evaluation"`
+	Alerts []AlertSchema      `json:"alerts" jsonschema_description:"Alerts of the user after this evaluation"`
+}
+
+type CheckAlertsTool struct {
+	alertService           AlertService
+	alertEvaluationService AlertEvaluationService
+}
+
+func NewCheckAlertsTool(alertService AlertService, alertEvaluationService AlertEvaluationService) (*CheckAlertsTool, error) {
+	return &CheckAlertsTool{
+		alertService:           alertService,
+		alertEvaluationService: alertEvaluationService,
+	}, nil
+}
+
+func (t *CheckAlertsTool) HandleCheckAlerts(ctx context.Context, req mcp.CallToolRequest, args CheckAlertsRequest) (CheckAlertsResponse, error) {
+	if args.UserID == "" {
+		return CheckAlertsResponse{}, fmt.Errorf("user_id is required")
+	}
+
+	events, err := t.alertEvaluationService.EvaluateUserAlerts(ctx, args.UserID)
+	if err != nil {
+		return CheckAlertsResponse{}, err
+	}
+	alerts, err := t.alertService.GetAlerts(args.UserID)
+	if err != nil {
+		return CheckAlertsResponse{}, err
+	}
+
+	schemas := make([]AlertSchema, 0, len(alerts))
+	for _, alert := range alerts {
+		schemas = append(schemas, newAlertSchema(alert))
+	}
+	return CheckAlertsResponse{UserID: args.UserID, Events: newAlertEventSchemas(events), Alerts: schemas}, nil
+}
+
+func (t *CheckAlertsTool) GetTool() mcp.Tool {
+	return mcp.NewTool("checkAlerts",
+		mcp.WithDescription("Evaluate the alerts of the user now instead of waiting for the schedule. The events triggered are also notified and recorded."),
+		mcp.WithInputSchema[CheckAlertsRequest](),
+		mcp.WithOutputSchema[CheckAlertsResponse](),
+	)
+}
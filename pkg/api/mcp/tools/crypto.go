@@ -4,12 +4,13 @@ import (
 	"context"
 	"fmt"
 	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/search"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 type CryptoDataService interface {
-	SearchCryptocurrencies(query string) ([]domain.Cryptocurrency, error)
+	SearchCryptocurrencies(query string) ([]search.Result[domain.Cryptocurrency], error)
 	GetCryptocurrencyDataById(id string) (domain.CryptocurrencyData, error)
 	GetCryptocurrencyNews(symbol string) ([]domain.NewsArticle, error)
 }
@@ -20,9 +21,12 @@ type SearchCryptocurrenciesRequest struct {
 }
 
 type SearchCryptocurrencyResultSchema struct {
-	Id     string `json:"id" jsonschema_description:"ID of the cryptocurrency"`
-	Name   string `json:"name" jsonschema_description:"Name of the cryptocurrency"`
-	Symbol string `json:"symbol" jsonschema_description:"Symbol of the cryptocurrency"`
+	Id          string  `json:"id" jsonschema_description:"ID of the cryptocurrency"`
+	Name        string  `json:"name" jsonschema_description:"Name of the cryptocurrency"`
+	Symbol      string  `json:"symbol" jsonschema_description:"Symbol of the cryptocurrency"`
+	MarketCap   float64 `json:"market_cap" jsonschema_description:"Market cap in USD (0 when the cryptocurrency isn't one of the top 250)"`
+	Score       float64 `json:"score" jsonschema_description:"Relevance score of the result (higher is better)"`
+	MatchReason string  `json:"match_reason,omitempty" jsonschema_description:"Why the result matched the search query" jsonschema:"enum=exact_symbol,enum=symbol_prefix,enum=name_token_prefix,enum=fuzzy,enum=contains"`
 }
 
 type SearchCryptocurrenciesResponse struct {
@@ -62,9 +66,12 @@ func (t *SearchCryptocurrenciesTool) HandleSearchCryptocurrencies(ctx context.Co
 		}
 
 		response.Results = append(response.Results, SearchCryptocurrencyResultSchema{
-			Id:     cryptocurrency.Id,
-			Name:   cryptocurrency.Name,
-			Symbol: cryptocurrency.Symbol,
+			Id:          cryptocurrency.Item.Id,
+			Name:        cryptocurrency.Item.Name,
+			Symbol:      cryptocurrency.Item.Symbol,
+			MarketCap:   cryptocurrency.Item.MarketCap,
+			Score:       cryptocurrency.Score,
+			MatchReason: string(cryptocurrency.Reason),
 		})
 	}
 
@@ -73,7 +80,7 @@ func (t *SearchCryptocurrenciesTool) HandleSearchCryptocurrencies(ctx context.Co
 
 func (t *SearchCryptocurrenciesTool) GetTool() mcp.Tool {
 	return mcp.NewTool("searchCryptocurrencies",
-		mcp.WithDescription("Search for cryptocurrencies by name or symbol."),
+		mcp.WithDescription("Search for cryptocurrencies by name or symbol. Results are ranked by relevance (exact symbol, symbol prefix, name words, typos) and market cap."),
 		mcp.WithInputSchema[SearchCryptocurrenciesRequest](),
 		mcp.WithOutputSchema[SearchCryptocurrenciesResponse](),
 	)
@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/analytics/dcf"
+	"market_data_mcp_server/pkg/services"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type DcfService interface {
+	CalculateDcf(query services.DcfQuery) (services.DcfResult, error)
+}
+
+type CalculateDcfRequest struct {
+	Symbol               string   `json:"symbol" jsonschema_description:"Symbol of the stock to value (e.g. AAPL)"`
+	GrowthPct            *float64 `json:"growth_pct,omitempty" jsonschema_description:"Yearly growth of the free cash flow in the high growth years in percent. By default the revenue growth of the next four quarters estimated by the analysts, or of the last four quarters without estimates."`
+	HighGrowthYears      int      `json:"high_growth_years,omitempty" jsonschema:"minimum=1,maximum=10,default=5"`
+	FadeYears            *int     `json:"fade_years,omitempty" jsonschema_description:"Years the growth fades linearly to the terminal growth after the high growth years" jsonschema:"minimum=0,maximum=10,default=5"`
+	BaseFcf              float64  `json:"base_fcf,omitempty" jsonschema_description:"Free cash flow the projection starts from, the one of the trailing twelve months by default"`
+	DiscountRatePct      float64  `json:"discount_rate_pct,omitempty" jsonschema_description:"Discount rate in percent, the WACC derived from the treasury yield and the beta by default"`
+	RiskFreeRatePct      *float64 `json:"risk_free_rate_pct,omitempty" jsonschema_description:"Risk-free rate of the WACC in percent, the last 10 year treasury yield by default"`
+	Beta                 *float64 `json:"beta,omitempty" jsonschema_description:"Beta of the WACC, the 5 year beta against SPY by default"`
+	EquityRiskPremiumPct float64  `json:"equity_risk_premium_pct,omitempty" jsonschema:"default=5"`
+	TerminalMethod       string   `json:"terminal_method,omitempty" jsonschema:"enum=perpetuity_growth,enum=exit_multiple,default=perpetuity_growth"`
+	TerminalGrowthPct    *float64 `json:"terminal_growth_pct,omitempty" jsonschema_description:"Growth after the projected years in percent, of the perpetuity growth and the end of the fade" jsonschema:"default=2.5"`
+	ExitMultiple         float64  `json:"exit_multiple,omitempty" jsonschema_description:"Multiple of the free cash flow of the last year of the exit multiple, the current EV/FCF by default"`
+}
+
+type DcfWaccSchema struct {
+	Rate              float64 `json:"rate" jsonschema_description:"The discount rate in percent"`
+	Source            string  `json:"source" jsonschema:"enum=derived,enum=override"`
+	RiskFreeRate      float64 `json:"risk_free_rate,omitempty" jsonschema_description:"In percent"`
+	Beta              float64 `json:"beta,omitempty"`
+	EquityRiskPremium float64 `json:"equity_risk_premium,omitempty" jsonschema_description:"In percent"`
+	CostOfEquity      float64 `json:"cost_of_equity,omitempty" jsonschema_description:"Risk-free rate plus beta times the equity risk premium, in percent"`
+	CostOfDebt        float64 `json:"cost_of_debt,omitempty" jsonschema_description:"Interest of the last four quarters over the debt before taxes, in percent"`
+	TaxRate           float64 `json:"tax_rate,omitempty" jsonschema_description:"In percent"`
+	EquityWeight      float64 `json:"equity_weight,omitempty" jsonschema_description:"Market cap over the market cap plus the debt, in percent"`
+	DebtWeight        float64 `json:"debt_weight,omitempty" jsonschema_description:"In percent"`
+}
+
+type DcfYearSchema struct {
+	Year           int     `json:"year"`
+	Growth         float64 `json:"growth" jsonschema_description:"In percent"`
+	Fcf            float64 `json:"fcf"`
+	DiscountFactor float64 `json:"discount_factor"`
+	PresentValue   float64 `json:"present_value"`
+}
+
+type DcfSensitivityCellSchema struct {
+	Terminal      float64  `json:"terminal" jsonschema_description:"The terminal growth in percent or the exit multiple"`
+	ValuePerShare *float64 `json:"value_per_share,omitempty" jsonschema_description:"Absent when the discount rate isn't above the terminal growth"`
+}
+
+type DcfSensitivityRowSchema struct {
+	DiscountRate float64                    `json:"discount_rate" jsonschema_description:"In percent"`
+	Values       []DcfSensitivityCellSchema `json:"values"`
+}
+
+type CalculateDcfResponse struct {
+	Symbol                  string                    `json:"symbol"`
+	Price                   float64                   `json:"price" jsonschema_description:"Last close"`
+	IntrinsicValuePerShare  float64                   `json:"intrinsic_value_per_share"`
+	Upside                  float64                   `json:"upside" jsonschema_description:"Of the intrinsic value over the price in percent, negative when the stock is above its intrinsic value"`
+	BaseFcf                 float64                   `json:"base_fcf"`
+	Growth                  float64                   `json:"growth" jsonschema_description:"Growth of the high growth years in percent"`
+	GrowthSource            string                    `json:"growth_source" jsonschema:"enum=analyst_estimates,enum=historical,enum=override"`
+	HighGrowthYears         int                       `json:"high_growth_years"`
+	FadeYears               int                       `json:"fade_years"`
+	Wacc                    DcfWaccSchema             `json:"wacc"`
+	TerminalMethod          string                    `json:"terminal_method"`
+	TerminalGrowth          float64                   `json:"terminal_growth" jsonschema_description:"In percent"`
+	ExitMultiple            float64                   `json:"exit_multiple,omitempty"`
+	Projections             []DcfYearSchema           `json:"projections"`
+	PresentValueOfFcf       float64                   `json:"present_value_of_fcf" jsonschema_description:"Of the projected years"`
+	TerminalValue           float64                   `json:"terminal_value" jsonschema_description:"At the end of the last projected year"`
+	PresentValueOfTerminal  float64                   `json:"present_value_of_terminal"`
+	TerminalShareOfValuePct float64                   `json:"terminal_share_of_value_pct" jsonschema_description:"Share of the enterprise value coming from the terminal value"`
+	EnterpriseValue         float64                   `json:"enterprise_value"`
+	NetCash                 float64                   `json:"net_cash" jsonschema_description:"Cash minus debt, negative for a net debt"`
+	EquityValue             float64                   `json:"equity_value"`
+	Shares                  float64                   `json:"shares"`
+	Sensitivity             []DcfSensitivityRowSchema `json:"sensitivity" jsonschema_description:"The intrinsic value per share for discount rates and terminals around the ones of the valuation"`
+	Warnings                []string                  `json:"warnings,omitempty"`
+}
+
+type CalculateDcfTool struct {
+	dcfService DcfService
+}
+
+func NewCalculateDcfTool(dcfService DcfService) (*CalculateDcfTool, error) {
+	return &CalculateDcfTool{
+		dcfService: dcfService,
+	}, nil
+}
+
+// fraction returns the percentage as a fraction, nil when not given
+func fraction(pct *float64) *float64 {
+	if pct == nil {
+		return nil
+	}
+	value := *pct / 100
+	return &value
+}
+
+func (t *CalculateDcfTool) HandleCalculateDcf(ctx context.Context, req mcp.CallToolRequest, args CalculateDcfRequest) (CalculateDcfResponse, error) {
+	if args.Symbol == "" {
+		return CalculateDcfResponse{}, fmt.Errorf("symbol is required")
+	}
+
+	result, err := t.dcfService.CalculateDcf(services.DcfQuery{
+		Symbol:            args.Symbol,
+		Growth:            fraction(args.GrowthPct),
+		HighGrowthYears:   args.HighGrowthYears,
+		FadeYears:         args.FadeYears,
+		BaseCashFlow:      args.BaseFcf,
+		DiscountRate:      args.DiscountRatePct / 100,
+		RiskFreeRate:      fraction(args.RiskFreeRatePct),
+		Beta:              args.Beta,
+		EquityRiskPremium: args.EquityRiskPremiumPct / 100,
+		TerminalMethod:    dcf.TerminalMethod(args.TerminalMethod),
+		TerminalGrowth:    fraction(args.TerminalGrowthPct),
+		ExitMultiple:      args.ExitMultiple,
+	})
+	if err != nil {
+		return CalculateDcfResponse{}, err
+	}
+
+	v := result.Valuation
+	response := CalculateDcfResponse{
+		Symbol:                 result.Symbol,
+		Price:                  result.Price,
+		IntrinsicValuePerShare: v.PerShare,
+		Upside:                 result.Upside * 100,
+		BaseFcf:                result.BaseCashFlow,
+		Growth:                 result.Growth * 100,
+		GrowthSource:           result.GrowthSource,
+		HighGrowthYears:        result.HighGrowthYears,
+		FadeYears:              result.FadeYears,
+		Wacc: DcfWaccSchema{
+			Rate:              result.Wacc.Rate * 100,
+			Source:            result.Wacc.Source,
+			RiskFreeRate:      result.Wacc.RiskFreeRate * 100,
+			Beta:              result.Wacc.Beta,
+			EquityRiskPremium: result.Wacc.EquityRiskPremium * 100,
+			CostOfEquity:      result.Wacc.CostOfEquity * 100,
+			CostOfDebt:        result.Wacc.CostOfDebt * 100,
+			TaxRate:           result.Wacc.TaxRate * 100,
+			EquityWeight:      result.Wacc.EquityWeight * 100,
+			DebtWeight:        result.Wacc.DebtWeight * 100,
+		},
+		TerminalMethod:         string(result.TerminalMethod),
+		TerminalGrowth:         result.TerminalGrowth * 100,
+		Projections:            make([]DcfYearSchema, len(v.Years)),
+		PresentValueOfFcf:      v.PresentValueOfCashFlows,
+		TerminalValue:          v.TerminalValue,
+		PresentValueOfTerminal: v.PresentValueOfTerminal,
+		EnterpriseValue:        v.EnterpriseValue,
+		NetCash:                result.NetCash,
+		EquityValue:            v.EquityValue,
+		Shares:                 result.Shares,
+		Sensitivity:            make([]DcfSensitivityRowSchema, len(result.Sensitivity.DiscountRates)),
+		Warnings:               result.Warnings,
+	}
+	if result.TerminalMethod == dcf.ExitMultiple {
+		response.ExitMultiple = result.ExitMultiple
+	}
+	if v.EnterpriseValue > 0 {
+		response.TerminalShareOfValuePct = v.PresentValueOfTerminal / v.EnterpriseValue * 100
+	}
+	for i, year := range v.Years {
+		response.Projections[i] = DcfYearSchema{
+			Year:           year.Year,
+			Growth:         year.Growth * 100,
+			Fcf:            year.CashFlow,
+			DiscountFactor: year.DiscountFactor,
+			PresentValue:   year.PresentValue,
+		}
+	}
+	for i, rate := range result.Sensitivity.DiscountRates {
+		row := DcfSensitivityRowSchema{DiscountRate: rate * 100, Values: make([]DcfSensitivityCellSchema, len(result.Sensitivity.Terminals))}
+		for j, terminal := range result.Sensitivity.Terminals {
+			// The terminal growths are in percent, the exit multiples as they are
+			if result.TerminalMethod == dcf.PerpetuityGrowth {
+				terminal *= 100
+			}
+			row.Values[j] = DcfSensitivityCellSchema{Terminal: terminal, ValuePerShare: result.Sensitivity.Values[i][j]}
+		}
+		response.Sensitivity[i] = row
+	}
+
+	return response, nil
+}
+
+func (t *CalculateDcfTool) GetTool() mcp.Tool {
+	return mcp.NewTool("calculateDcf",
+		mcp.WithDescription("Calculate the intrinsic value per share of a stock with a discounted cash flow model and compare it with its price. "+
+			"The trailing twelve months free cash flow grows at the analyst revenue estimates for the high growth years, then fades "+
+			"to the terminal growth, and is discounted at the WACC (10 year treasury yield, 5 year beta, cost and weight of the debt). "+
+			"The terminal value is a perpetuity growth or an exit multiple of the free cash flow. Every assumption can be overridden. "+
+			"Returns the projections, the valuation bridge and a sensitivity grid of the value per share to the discount rate and the terminal."),
+		mcp.WithInputSchema[CalculateDcfRequest](),
+		mcp.WithOutputSchema[CalculateDcfResponse](),
+	)
+}
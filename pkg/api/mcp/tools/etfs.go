@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/search"
 	"market_data_mcp_server/pkg/services"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -11,22 +12,27 @@ import (
 
 type SearchEtfRequest struct {
 	SearchString string `json:"search_string,omitempty" jsonschema_description:"Search string query"`
-	Limit        int    `json:"limit,omitempty" jsonschema_description:"Maximum results" jsonschema:"minimum=1,default=100"`
+	Limit        int    `json:"limit,omitempty" jsonschema_description:"Maximum results per page" jsonschema:"minimum=1,maximum=100,default=50"`
+	Cursor       string `json:"cursor,omitempty" jsonschema_description:"Cursor of the page to return (next_cursor of the previous response)"`
 }
 
 type EtfSearchResultSchema struct {
-	Symbol     string  `json:"symbol" jsonschema_description:"ETF symbol"`
-	EtfName    string  `json:"etf_name" jsonschema_description:"ETF name"`
-	AssetClass string  `json:"asset_class" jsonschema_description:"ETF asset class"`
-	Aum        float32 `json:"aum" jsonschema_description:"ETF assets under management"`
+	Symbol      string  `json:"symbol" jsonschema_description:"ETF symbol"`
+	EtfName     string  `json:"etf_name" jsonschema_description:"ETF name"`
+	AssetClass  string  `json:"asset_class" jsonschema_description:"ETF asset class"`
+	Aum         float32 `json:"aum" jsonschema_description:"ETF assets under management"`
+	Score       float64 `json:"score" jsonschema_description:"Relevance score of the result (higher is better, 0 when there is no search string)"`
+	MatchReason string  `json:"match_reason,omitempty" jsonschema_description:"Why the result matched the search string" jsonschema:"enum=exact_symbol,enum=symbol_prefix,enum=name_token_prefix,enum=fuzzy,enum=contains"`
 }
 
 type EtfSearchResultsResponse struct {
 	SearchResults []EtfSearchResultSchema `json:"search_results" jsonschema_description:"Search results"`
+	TotalCount    int                     `json:"total_count" jsonschema_description:"Total number of results matching the search"`
+	NextCursor    string                  `json:"next_cursor,omitempty" jsonschema_description:"Cursor of the next page, empty when there are no more results"`
 }
 
 type EtfService interface {
-	GetEtfs(filters services.EtfFilterOptions) ([]domain.Etf, error)
+	GetEtfs(filters services.EtfFilterOptions) (services.Page[search.Result[domain.Etf]], error)
 	GetEtf(etfSymbol string) (domain.EtfOverview, error)
 }
 
@@ -41,29 +47,34 @@ func NewSearchEtfTool(etfService EtfService) (*SearchEtfTool, error) {
 }
 
 func (t *SearchEtfTool) HandleSearchEtfs(ctx context.Context, req mcp.CallToolRequest, args SearchEtfRequest) (EtfSearchResultsResponse, error) {
-	if args.Limit == 0 {
-		args.Limit = 100
-	}
-	tickerFilters := services.EtfFilterOptions{
+	etfFilters := services.EtfFilterOptions{
+		Limit:        args.Limit,
+		Cursor:       args.Cursor,
 		SearchString: args.SearchString,
 	}
 
-	etfs, err := t.etfService.GetEtfs(tickerFilters)
+	etfs, err := t.etfService.GetEtfs(etfFilters)
 	if err != nil {
 		return EtfSearchResultsResponse{}, err
 	}
 
 	response := EtfSearchResultsResponse{
-		SearchResults: make([]EtfSearchResultSchema, 0, len(etfs)),
+		SearchResults: make([]EtfSearchResultSchema, 0, len(etfs.Items)),
+		TotalCount:    etfs.TotalCount,
+		NextCursor:    etfs.NextCursor,
 	}
 
-	for i, e := range etfs {
-		if i > args.Limit {
-			break
-		}
+	for _, e := range etfs.Items {
 		response.SearchResults = append(
 			response.SearchResults,
-			EtfSearchResultSchema{Symbol: e.Symbol, EtfName: e.Name, AssetClass: e.AssetClass, Aum: e.Aum},
+			EtfSearchResultSchema{
+				Symbol:      e.Item.Symbol,
+				EtfName:     e.Item.Name,
+				AssetClass:  e.Item.AssetClass,
+				Aum:         e.Item.Aum,
+				Score:       e.Score,
+				MatchReason: string(e.Reason),
+			},
 		)
 	}
 
@@ -72,7 +83,7 @@ func (t *SearchEtfTool) HandleSearchEtfs(ctx context.Context, req mcp.CallToolRe
 
 func (t *SearchEtfTool) GetTool() mcp.Tool {
 	return mcp.NewTool("etfSearch",
-		mcp.WithDescription("Search for an ETF using the symbol or the ETF name"),
+		mcp.WithDescription("Search for an ETF using the symbol or the ETF name. Results are ranked by relevance (exact symbol, symbol prefix, ETF name words, typos) and AUM and are paginated, use the next_cursor of the response to get the next page"),
 		mcp.WithInputSchema[SearchEtfRequest](),
 		mcp.WithOutputSchema[EtfSearchResultsResponse](),
 	)
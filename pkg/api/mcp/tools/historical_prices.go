@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type PriceHistoryService interface {
+	GetPriceHistory(query domain.PriceHistoryQuery) (domain.PriceHistory, error)
+}
+
+type GetHistoricalPricesRequest struct {
+	Symbol     string `json:"symbol" jsonschema_description:"Symbol of the stock, the ETF or the cryptocurrency (e.g. AAPL, SPY, BTC)"`
+	AssetClass string `json:"asset_class,omitempty" jsonschema:"enum=stock,enum=etf,enum=crypto,default=stock"`
+	Period     string `json:"period,omitempty" jsonschema_description:"Period ending now, ignored when start_date is set" jsonschema:"enum=1d,enum=5d,enum=1m,enum=ytd,enum=6m,enum=1y,enum=5y,enum=max,default=1y"`
+	StartDate  string `json:"start_date,omitempty" jsonschema_description:"First day of a custom range in YYYY-MM-DD format"`
+	EndDate    string `json:"end_date,omitempty" jsonschema_description:"Last day of the custom range in YYYY-MM-DD format, today by default"`
+	Ohlcv      bool   `json:"ohlcv,omitempty" jsonschema_description:"Daily bars (weekly for the ranges starting more than 140 days ago) with the open, high, low and volume for the stocks and the ETFs, from a rate limited source. The cryptocurrencies always have the closes and the 24h volumes."`
+	MaxPoints  int    `json:"max_points,omitempty" jsonschema_description:"Maximum number of points, the consecutive points are merged beyond" jsonschema:"minimum=2,maximum=5000,default=500"`
+}
+
+type PricePointSchema struct {
+	Date   string  `json:"date" jsonschema_description:"ISO 8601 format"`
+	Open   float64 `json:"open,omitempty"`
+	High   float64 `json:"high,omitempty"`
+	Low    float64 `json:"low,omitempty"`
+	Close  float64 `json:"close"`
+	Volume float64 `json:"volume,omitempty" jsonschema_description:"Volume of the bar, or the 24h volume in USD of a cryptocurrency"`
+}
+
+type GetHistoricalPricesResponse struct {
+	Symbol           string             `json:"symbol"`
+	AssetClass       string             `json:"asset_class"`
+	StartDate        string             `json:"start_date" jsonschema_description:"Date of the first point, ISO 8601 format"`
+	EndDate          string             `json:"end_date" jsonschema_description:"Date of the last point, ISO 8601 format"`
+	Source           string             `json:"source"`
+	PercentageChange float64            `json:"percentage_change" jsonschema_description:"Change from the first to the last close in percent"`
+	SourcePoints     int                `json:"source_points" jsonschema_description:"Number of points before they were merged down to max_points"`
+	Prices           []PricePointSchema `json:"prices" jsonschema_description:"The oldest first, a merged point has the date and the close of its last point"`
+}
+
+type GetHistoricalPricesTool struct {
+	priceHistoryService PriceHistoryService
+}
+
+func NewGetHistoricalPricesTool(priceHistoryService PriceHistoryService) (*GetHistoricalPricesTool, error) {
+	return &GetHistoricalPricesTool{
+		priceHistoryService: priceHistoryService,
+	}, nil
+}
+
+func (t *GetHistoricalPricesTool) HandleGetHistoricalPrices(ctx context.Context, req mcp.CallToolRequest, args GetHistoricalPricesRequest) (GetHistoricalPricesResponse, error) {
+	if args.Symbol == "" {
+		return GetHistoricalPricesResponse{}, fmt.Errorf("symbol is required")
+	}
+
+	query := domain.PriceHistoryQuery{
+		Symbol:     args.Symbol,
+		AssetClass: domain.AssetClass(args.AssetClass),
+		Period:     domain.Period(args.Period),
+		Ohlcv:      args.Ohlcv,
+		MaxPoints:  args.MaxPoints,
+	}
+	if args.StartDate != "" {
+		start, err := time.Parse(time.DateOnly, args.StartDate)
+		if err != nil {
+			return GetHistoricalPricesResponse{}, fmt.Errorf("start_date must be in YYYY-MM-DD format")
+		}
+		query.Start = start
+	}
+	if args.EndDate != "" {
+		if args.StartDate == "" {
+			return GetHistoricalPricesResponse{}, fmt.Errorf("end_date requires a start_date")
+		}
+		end, err := time.Parse(time.DateOnly, args.EndDate)
+		if err != nil {
+			return GetHistoricalPricesResponse{}, fmt.Errorf("end_date must be in YYYY-MM-DD format")
+		}
+		query.End = end
+	}
+
+	history, err := t.priceHistoryService.GetPriceHistory(query)
+	if err != nil {
+		return GetHistoricalPricesResponse{}, err
+	}
+
+	prices := make([]PricePointSchema, 0, len(history.Prices))
+	for _, p := range history.Prices {
+		prices = append(prices, PricePointSchema{
+			Date:   p.Date.Format(time.RFC3339),
+			Open:   p.Open,
+			High:   p.High,
+			Low:    p.Low,
+			Close:  p.ClosePrice,
+			Volume: p.Volume,
+		})
+	}
+
+	return GetHistoricalPricesResponse{
+		Symbol:           history.Symbol,
+		AssetClass:       string(history.AssetClass),
+		StartDate:        history.Start.Format(time.RFC3339),
+		EndDate:          history.End.Format(time.RFC3339),
+		Source:           history.Source,
+		PercentageChange: history.PercentageChange,
+		SourcePoints:     history.SourcePoints,
+		Prices:           prices,
+	}, nil
+}
+
+func (t *GetHistoricalPricesTool) GetTool() mcp.Tool {
+	return mcp.NewTool("getHistoricalPrices",
+		mcp.WithDescription("Get the historical prices of a stock, an ETF or a cryptocurrency over a period ending now (1d to max) or a custom date range, "+
+			"the closes by default or the OHLCV bars, downsampled to a maximum number of points"),
+		mcp.WithInputSchema[GetHistoricalPricesRequest](),
+		mcp.WithOutputSchema[GetHistoricalPricesResponse](),
+	)
+}
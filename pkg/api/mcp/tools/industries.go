@@ -0,0 +1,202 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/services"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type IndustrySchema struct {
+	Name             string  `json:"name" jsonschema_description:"Industry name"`
+	UrlName          string  `json:"url_name" jsonschema_description:"The url name of the industry, to get its stocks with getIndustryStocks"`
+	Sector           string  `json:"sector,omitempty" jsonschema_description:"Name of the sector of the industry, absent when unknown"`
+	SectorUrlName    string  `json:"sector_url_name,omitempty" jsonschema_description:"The url name of the sector of the industry"`
+	NumberOfStocks   int     `json:"number_of_stocks" jsonschema_description:"Number of stocks in the industry"`
+	MarketCap        float32 `json:"market_cap" jsonschema_description:"Market cap of the industry"`
+	DividendYieldPct float32 `json:"dividend_yield_pct" jsonschema_description:"Dividend yield percentage of the industry"`
+	PeRatio          float32 `json:"pe_ratio,omitempty" jsonschema_description:"PE ratio of the industry, absent when it has none"`
+	ProfitMarginPct  float32 `json:"profit_margin_pct" jsonschema_description:"Profit margin percentage of the industry"`
+	OneYearChangePct float32 `json:"one_year_change_pct" jsonschema_description:"One year price change percentage of the industry"`
+}
+
+type IndustryStockSchema struct {
+	Symbol      string  `json:"symbol" jsonschema_description:"Stock symbol"`
+	CompanyName string  `json:"company_name" jsonschema_description:"Company name of the stock"`
+	MarketCap   float32 `json:"market_cap" jsonschema_description:"Market cap of the stock"`
+}
+
+type IndustriesService interface {
+	GetIndustries(query services.IndustriesQuery) (services.IndustriesResult, error)
+	GetIndustryStocks(query services.IndustryStocksQuery) (services.IndustryStocksResult, error)
+}
+
+func newIndustrySchema(industry services.SectorIndustry) IndustrySchema {
+	return IndustrySchema{
+		Name:             industry.Name,
+		UrlName:          industry.UrlName,
+		Sector:           industry.Sector,
+		SectorUrlName:    industry.SectorUrlName,
+		NumberOfStocks:   industry.NumberOfStocks,
+		MarketCap:        industry.MarketCap,
+		DividendYieldPct: industry.DividendYieldPct,
+		PeRatio:          industry.PeRatio,
+		ProfitMarginPct:  industry.ProfitMarginPct,
+		OneYearChangePct: industry.OneYearChangePct,
+	}
+}
+
+// descending returns whether the order is descending, the default one
+func descending(order string) (bool, error) {
+	switch order {
+	case "", "desc":
+		return true, nil
+	case "asc":
+		return false, nil
+	default:
+		return false, fmt.Errorf("order valid values are: asc, desc")
+	}
+}
+
+type GetIndustriesRequest struct {
+	Sector           string  `json:"sector,omitempty" jsonschema_description:"Name or url name of the sector whose industries are returned (e.g. Technology), all the industries when empty"`
+	MinMarketCap     float64 `json:"min_market_cap,omitempty"`
+	MaxMarketCap     float64 `json:"max_market_cap,omitempty"`
+	MinPeRatio       float64 `json:"min_pe_ratio,omitempty" jsonschema_description:"The industries without a PE ratio are left out when a PE bound is set"`
+	MaxPeRatio       float64 `json:"max_pe_ratio,omitempty"`
+	MinOneYearChange float64 `json:"min_one_year_change_pct,omitempty"`
+	MaxOneYearChange float64 `json:"max_one_year_change_pct,omitempty"`
+	Sort             string  `json:"sort,omitempty" jsonschema:"enum=name,enum=market_cap,enum=pe_ratio,enum=one_year_change,enum=number_of_stocks,enum=dividend_yield,enum=profit_margin,default=market_cap"`
+	Order            string  `json:"order,omitempty" jsonschema:"enum=asc,enum=desc,default=desc"`
+	Limit            int     `json:"limit,omitempty" jsonschema:"minimum=1,default=200"`
+}
+
+type GetIndustriesResponse struct {
+	Industries []IndustrySchema `json:"industries" jsonschema_description:"A list with the industries"`
+	Total      int              `json:"total" jsonschema_description:"Number of the industries matching the filters, before the limit"`
+	Warnings   []string         `json:"warnings,omitempty"`
+}
+
+type GetIndustriesTool struct {
+	industriesService IndustriesService
+}
+
+func NewGetIndustriesTool(industriesService IndustriesService) (*GetIndustriesTool, error) {
+	return &GetIndustriesTool{
+		industriesService: industriesService,
+	}, nil
+}
+
+func (t *GetIndustriesTool) HandleGetIndustries(ctx context.Context, req mcp.CallToolRequest, args GetIndustriesRequest) (GetIndustriesResponse, error) {
+	desc, err := descending(args.Order)
+	if err != nil {
+		return GetIndustriesResponse{}, err
+	}
+
+	result, err := t.industriesService.GetIndustries(services.IndustriesQuery{
+		Sector:           args.Sector,
+		MinMarketCap:     args.MinMarketCap,
+		MaxMarketCap:     args.MaxMarketCap,
+		MinPeRatio:       args.MinPeRatio,
+		MaxPeRatio:       args.MaxPeRatio,
+		MinOneYearChange: args.MinOneYearChange,
+		MaxOneYearChange: args.MaxOneYearChange,
+		Sort:             args.Sort,
+		Descending:       desc,
+		Limit:            args.Limit,
+	})
+	if err != nil {
+		return GetIndustriesResponse{}, err
+	}
+
+	response := GetIndustriesResponse{
+		Industries: make([]IndustrySchema, 0, len(result.Industries)),
+		Total:      result.Total,
+		Warnings:   result.Warnings,
+	}
+	for _, industry := range result.Industries {
+		response.Industries = append(response.Industries, newIndustrySchema(industry))
+	}
+
+	return response, nil
+}
+
+func (t *GetIndustriesTool) GetTool() mcp.Tool {
+	return mcp.NewTool("getIndustries",
+		mcp.WithDescription("Get the stock industries with their sector, market cap, PE ratio, margin and one year change, "+
+			"filtered by sector (from getSectors), market cap, PE ratio and one year change. Use getIndustryStocks with the url name of an industry to get its stocks."),
+		mcp.WithInputSchema[GetIndustriesRequest](),
+		mcp.WithOutputSchema[GetIndustriesResponse](),
+	)
+}
+
+type GetIndustryStocksRequest struct {
+	IndustryUrlName string  `json:"url_name" jsonschema_description:"The url name of the industry to get the stocks for (e.g. semiconductors)"`
+	MinMarketCap    float64 `json:"min_market_cap,omitempty"`
+	MaxMarketCap    float64 `json:"max_market_cap,omitempty"`
+	Sort            string  `json:"sort,omitempty" jsonschema:"enum=market_cap,enum=symbol,enum=name,default=market_cap"`
+	Order           string  `json:"order,omitempty" jsonschema:"enum=asc,enum=desc,default=desc"`
+	Limit           int     `json:"limit,omitempty" jsonschema_description:"Maximum results" jsonschema:"minimum=1,default=100"`
+}
+
+type GetIndustryStocksResponse struct {
+	Industry       IndustrySchema        `json:"industry" jsonschema_description:"The industry, with only its url name when it couldn't be fetched"`
+	IndustryStocks []IndustryStockSchema `json:"industry_stocks" jsonschema_description:"The stocks of the industry"`
+	Total          int                   `json:"total" jsonschema_description:"Number of the stocks matching the filters, before the limit"`
+	Warnings       []string              `json:"warnings,omitempty"`
+}
+
+type GetIndustryStocksTool struct {
+	industriesService IndustriesService
+}
+
+func NewGetIndustryStocksTool(industriesService IndustriesService) (*GetIndustryStocksTool, error) {
+	return &GetIndustryStocksTool{industriesService: industriesService}, nil
+}
+
+func (t *GetIndustryStocksTool) HandleGetIndustryStocks(ctx context.Context, req mcp.CallToolRequest, args GetIndustryStocksRequest) (GetIndustryStocksResponse, error) {
+	if args.IndustryUrlName == "" {
+		return GetIndustryStocksResponse{}, fmt.Errorf("url_name is required")
+	}
+	desc, err := descending(args.Order)
+	if err != nil {
+		return GetIndustryStocksResponse{}, err
+	}
+
+	result, err := t.industriesService.GetIndustryStocks(services.IndustryStocksQuery{
+		Industry:     args.IndustryUrlName,
+		MinMarketCap: args.MinMarketCap,
+		MaxMarketCap: args.MaxMarketCap,
+		Sort:         args.Sort,
+		Descending:   desc,
+		Limit:        args.Limit,
+	})
+	if err != nil {
+		return GetIndustryStocksResponse{}, err
+	}
+
+	response := GetIndustryStocksResponse{
+		Industry:       newIndustrySchema(result.Industry),
+		IndustryStocks: make([]IndustryStockSchema, 0, len(result.Stocks)),
+		Total:          result.Total,
+		Warnings:       result.Warnings,
+	}
+	for _, stock := range result.Stocks {
+		response.IndustryStocks = append(response.IndustryStocks, IndustryStockSchema{
+			Symbol:      stock.Symbol,
+			CompanyName: stock.CompanyName,
+			MarketCap:   stock.MarketCap,
+		})
+	}
+
+	return response, nil
+}
+
+func (t *GetIndustryStocksTool) GetTool() mcp.Tool {
+	return mcp.NewTool("getIndustryStocks",
+		mcp.WithDescription("Get the stocks of an industry, filtered by market cap and sorted, with the industry and its sector"),
+		mcp.WithInputSchema[GetIndustryStocksRequest](),
+		mcp.WithOutputSchema[GetIndustryStocksResponse](),
+	)
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type PeerComparisonService interface {
+	ComparePeers(symbol string, count int) (domain.PeerComparison, error)
+}
+
+type GetPeerComparisonRequest struct {
+	Symbol string `json:"symbol" jsonschema_description:"Symbol of the stock compared to its peers (e.g. NVDA)"`
+	Peers  int    `json:"peers,omitempty" jsonschema_description:"Number of the peers, the stocks of the industry nearest by market cap" jsonschema:"minimum=1,maximum=25,default=10"`
+}
+
+type PeerSchema struct {
+	Symbol      string  `json:"symbol"`
+	CompanyName string  `json:"company_name"`
+	MarketCap   float64 `json:"market_cap"`
+}
+
+type PeerMetricSchema struct {
+	Name        string   `json:"name"`
+	Set         string   `json:"set" jsonschema:"enum=valuation,enum=profitability"`
+	Unit        string   `json:"unit" jsonschema:"enum=ratio,enum=percent"`
+	Better      string   `json:"better" jsonschema_description:"Whether the higher or the lower values are better" jsonschema:"enum=higher,enum=lower"`
+	Value       *float64 `json:"value,omitempty" jsonschema_description:"Value of the stock, absent when missing"`
+	PeerMedian  *float64 `json:"peer_median,omitempty" jsonschema_description:"Median of the meaningful values of the peers (e.g. not a negative P/E)"`
+	PeerCount   int      `json:"peer_count" jsonschema_description:"Number of the peers with a meaningful value"`
+	Percentile  *float64 `json:"percentile,omitempty" jsonschema_description:"Share of the peers with a worse value in percent, the equal ones counting for half. 100 is the best, so a high valuation percentile means cheaper than the peers."`
+	VsMedianPct *float64 `json:"vs_median_pct,omitempty" jsonschema_description:"Difference of the value with the peer median in percent"`
+}
+
+type SetPercentileSchema struct {
+	Set        string  `json:"set" jsonschema:"enum=valuation,enum=profitability"`
+	Percentile float64 `json:"percentile" jsonschema_description:"Average of the percentiles of the metrics of the set"`
+	Metrics    int     `json:"metrics" jsonschema_description:"Number of the metrics with a percentile"`
+}
+
+type GetPeerComparisonResponse struct {
+	Symbol          string                  `json:"symbol"`
+	CompanyName     string                  `json:"company_name"`
+	Industry        string                  `json:"industry"`
+	IndustryUrlName string                  `json:"industry_url_name" jsonschema_description:"The url name of the industry, to get all its stocks with getIndustryStocks"`
+	Sector          string                  `json:"sector,omitempty"`
+	MarketCap       float64                 `json:"market_cap"`
+	Peers           []PeerSchema            `json:"peers" jsonschema_description:"The peers, nearest by market cap first"`
+	Metrics         []PeerMetricSchema      `json:"metrics" jsonschema_description:"The trailing twelve months ratios, the yields and the returns in percent"`
+	Sets            []SetPercentileSchema   `json:"sets,omitempty"`
+	Errors          []ComparisonErrorSchema `json:"errors,omitempty" jsonschema_description:"The peers whose ratios couldn't be fetched, left out of the medians and the percentiles"`
+	Warnings        []string                `json:"warnings,omitempty"`
+}
+
+type GetPeerComparisonTool struct {
+	peerComparisonService PeerComparisonService
+}
+
+func NewGetPeerComparisonTool(peerComparisonService PeerComparisonService) (*GetPeerComparisonTool, error) {
+	return &GetPeerComparisonTool{
+		peerComparisonService: peerComparisonService,
+	}, nil
+}
+
+func (t *GetPeerComparisonTool) HandleGetPeerComparison(ctx context.Context, req mcp.CallToolRequest, args GetPeerComparisonRequest) (GetPeerComparisonResponse, error) {
+	if args.Symbol == "" {
+		return GetPeerComparisonResponse{}, fmt.Errorf("symbol is required")
+	}
+
+	comparison, err := t.peerComparisonService.ComparePeers(args.Symbol, args.Peers)
+	if err != nil {
+		return GetPeerComparisonResponse{}, err
+	}
+
+	response := GetPeerComparisonResponse{
+		Symbol:          comparison.Symbol,
+		CompanyName:     comparison.CompanyName,
+		Industry:        comparison.Industry,
+		IndustryUrlName: comparison.IndustryUrlName,
+		Sector:          comparison.Sector,
+		MarketCap:       comparison.MarketCap,
+		Peers:           make([]PeerSchema, len(comparison.Peers)),
+		Metrics:         make([]PeerMetricSchema, len(comparison.Metrics)),
+		Warnings:        comparison.Warnings,
+	}
+	for i, peer := range comparison.Peers {
+		response.Peers[i] = PeerSchema{
+			Symbol:      peer.Symbol,
+			CompanyName: peer.CompanyName,
+			MarketCap:   peer.MarketCap,
+		}
+	}
+	for i, metric := range comparison.Metrics {
+		better := "lower"
+		if metric.HigherIsBetter {
+			better = "higher"
+		}
+		response.Metrics[i] = PeerMetricSchema{
+			Name:        metric.Name,
+			Set:         string(metric.Set),
+			Unit:        string(metric.Unit),
+			Better:      better,
+			Value:       metric.Value,
+			PeerMedian:  metric.PeerMedian,
+			PeerCount:   metric.PeerCount,
+			Percentile:  metric.Percentile,
+			VsMedianPct: metric.VsMedianPct,
+		}
+	}
+	for _, set := range comparison.Sets {
+		response.Sets = append(response.Sets, SetPercentileSchema{
+			Set:        string(set.Set),
+			Percentile: set.Percentile,
+			Metrics:    set.Metrics,
+		})
+	}
+	for _, e := range comparison.Errors {
+		response.Errors = append(response.Errors, ComparisonErrorSchema{Symbol: e.Symbol, Error: e.Error})
+	}
+
+	return response, nil
+}
+
+func (t *GetPeerComparisonTool) GetTool() mcp.Tool {
+	return mcp.NewTool("getPeerComparison",
+		mcp.WithDescription("Compare a stock with its peers, the stocks of its industry nearest by market cap, to tell whether it is "+
+			"expensive or more profitable than them. For the valuation (P/E, P/S, P/B, P/FCF, EV multiples, yields) and profitability "+
+			"(ROE, ROA, ROIC, asset turnover) ratios, returns the value of the stock, the peer median and the percentile of the stock "+
+			"among its peers, 100 being the best (e.g. the lowest P/E)."),
+		mcp.WithInputSchema[GetPeerComparisonRequest](),
+		mcp.WithOutputSchema[GetPeerComparisonResponse](),
+	)
+}
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type PortfolioAnalyticsService interface {
+	GetPortfolioExposure(userID string, weighting domain.ExposureWeighting) (domain.PortfolioExposure, error)
+}
+
+type GetPortfolioExposureRequest struct {
+	UserID     string `json:"user_id" jsonschema_description:"The id of the user whose portfolio is analyzed"`
+	Weighting  string `json:"weighting,omitempty" jsonschema_description:"How the holdings are weighted: by their live market value, or by the portfolio percentages stated in the user context (when the quantities aren't known)" jsonschema:"enum=market_value,enum=stated,default=market_value"`
+	NamesLimit int    `json:"names_limit,omitempty" jsonschema_description:"Maximum number of single names to return, the largest first" jsonschema:"minimum=1,maximum=100,default=25"`
+}
+
+type ExposureBucketSchema struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight" jsonschema_description:"Percentage of the portfolio, 20 means 20%"`
+}
+
+type NameExposureSchema struct {
+	Symbol         string   `json:"symbol"`
+	Name           string   `json:"name"`
+	Weight         float64  `json:"weight" jsonschema_description:"Percentage of the portfolio, held directly and through funds"`
+	DirectWeight   float64  `json:"direct_weight" jsonschema_description:"Percentage of the portfolio held directly"`
+	IndirectWeight float64  `json:"indirect_weight" jsonschema_description:"Percentage of the portfolio held through funds"`
+	Funds          []string `json:"funds" jsonschema_description:"Symbols of the funds holding the name"`
+}
+
+type ConcentrationSchema struct {
+	Top10Weight     float64 `json:"top10_weight" jsonschema_description:"Percentage of the portfolio in the 10 largest single names"`
+	HerfindahlIndex float64 `json:"herfindahl_index" jsonschema_description:"Sum of the squared weights of the single names as fractions, from 1/names (equal weights) to 1 (a single name)"`
+	EffectiveNames  float64 `json:"effective_names" jsonschema_description:"Number of equally weighted names with the same concentration, 1 / herfindahl_index"`
+}
+
+type GetPortfolioExposureResponse struct {
+	UserID              string                  `json:"user_id"`
+	Weighting           string                  `json:"weighting"`
+	AssetClasses        []ExposureBucketSchema  `json:"asset_classes" jsonschema_description:"Exposure by asset class of the holdings"`
+	Sectors             []ExposureBucketSchema  `json:"sectors" jsonschema_description:"Exposure by sector with the funds looked through. Non-equity holdings are grouped as Non-equity, and the unexpanded remainder of the funds as Unknown."`
+	Industries          []ExposureBucketSchema  `json:"industries" jsonschema_description:"Exposure by industry with the funds looked through"`
+	Countries           []ExposureBucketSchema  `json:"countries" jsonschema_description:"Exposure by country with the funds looked through"`
+	Names               []NameExposureSchema    `json:"names" jsonschema_description:"Exposure by single name with the funds looked through, the largest first"`
+	OverlappingNames    []NameExposureSchema    `json:"overlapping_names" jsonschema_description:"Names held both directly and through funds"`
+	Concentration       ConcentrationSchema     `json:"concentration"`
+	LookThroughCoverage float64                 `json:"look_through_coverage" jsonschema_description:"Percentage of the funds weight expanded into their top holdings, the rest of the funds is reported under the fund symbol. 0 when there is no fund."`
+	ExcludedHoldings    []UnpricedHoldingSchema `json:"excluded_holdings" jsonschema_description:"Holdings without weight (not priced, or no stated percentage), they aren't part of the exposure"`
+}
+
+type GetPortfolioExposureTool struct {
+	portfolioAnalyticsService PortfolioAnalyticsService
+}
+
+func NewGetPortfolioExposureTool(portfolioAnalyticsService PortfolioAnalyticsService) (*GetPortfolioExposureTool, error) {
+	return &GetPortfolioExposureTool{
+		portfolioAnalyticsService: portfolioAnalyticsService,
+	}, nil
+}
+
+func (t *GetPortfolioExposureTool) HandleGetPortfolioExposure(ctx context.Context, req mcp.CallToolRequest, args GetPortfolioExposureRequest) (GetPortfolioExposureResponse, error) {
+	if args.UserID == "" {
+		return GetPortfolioExposureResponse{}, fmt.Errorf("user_id is required")
+	}
+	weighting := domain.WeightingMarketValue
+	if args.Weighting != "" {
+		weighting = domain.ExposureWeighting(args.Weighting)
+	}
+	if weighting != domain.WeightingMarketValue && weighting != domain.WeightingStated {
+		return GetPortfolioExposureResponse{}, fmt.Errorf("weighting valid values are: market_value, stated")
+	}
+	if args.NamesLimit == 0 {
+		args.NamesLimit = 25
+	}
+	if args.NamesLimit < 1 || args.NamesLimit > 100 {
+		return GetPortfolioExposureResponse{}, fmt.Errorf("names_limit must be between 1 and 100")
+	}
+
+	exposure, err := t.portfolioAnalyticsService.GetPortfolioExposure(args.UserID, weighting)
+	if err != nil {
+		return GetPortfolioExposureResponse{}, err
+	}
+
+	names := make([]NameExposureSchema, 0, min(len(exposure.Names), args.NamesLimit))
+	overlapping := make([]NameExposureSchema, 0)
+	for i, name := range exposure.Names {
+		schema := newNameExposureSchema(name)
+		if i < args.NamesLimit {
+			names = append(names, schema)
+		}
+		if name.DirectWeight > 0 && name.IndirectWeight > 0 {
+			overlapping = append(overlapping, schema)
+		}
+	}
+
+	excluded := make([]UnpricedHoldingSchema, 0, len(exposure.Excluded))
+	for _, h := range exposure.Excluded {
+		excluded = append(excluded, UnpricedHoldingSchema{
+			AssetClass:   string(h.Holding.AssetClass),
+			Symbol:       h.Holding.Symbol,
+			Name:         h.Holding.Name,
+			Quantity:     h.Holding.Quantity,
+			StatedWeight: h.Holding.PortfolioPercentage,
+			Reason:       h.Reason,
+		})
+	}
+
+	return GetPortfolioExposureResponse{
+		UserID:           exposure.UserID,
+		Weighting:        string(exposure.Weighting),
+		AssetClasses:     newExposureBucketSchemas(exposure.AssetClasses),
+		Sectors:          newExposureBucketSchemas(exposure.Sectors),
+		Industries:       newExposureBucketSchemas(exposure.Industries),
+		Countries:        newExposureBucketSchemas(exposure.Countries),
+		Names:            names,
+		OverlappingNames: overlapping,
+		Concentration: ConcentrationSchema{
+			Top10Weight:     exposure.Top10Weight,
+			HerfindahlIndex: exposure.Herfindahl,
+			EffectiveNames:  exposure.EffectiveNames,
+		},
+		LookThroughCoverage: exposure.LookThroughCoverage,
+		ExcludedHoldings:    excluded,
+	}, nil
+}
+
+func newExposureBucketSchemas(buckets []domain.ExposureBucket) []ExposureBucketSchema {
+	schemas := make([]ExposureBucketSchema, 0, len(buckets))
+	for _, bucket := range buckets {
+		schemas = append(schemas, ExposureBucketSchema{Name: bucket.Name, Weight: bucket.Weight})
+	}
+	return schemas
+}
+
+func newNameExposureSchema(name domain.NameExposure) NameExposureSchema {
+	funds := name.Funds
+	if funds == nil {
+		funds = []string{}
+	}
+	return NameExposureSchema{
+		Symbol:         name.Symbol,
+		Name:           name.Name,
+		Weight:         name.Weight,
+		DirectWeight:   name.DirectWeight,
+		IndirectWeight: name.IndirectWeight,
+		Funds:          funds,
+	}
+}
+
+func (t *GetPortfolioExposureTool) GetTool() mcp.Tool {
+	return mcp.NewTool("getPortfolioExposure",
+		mcp.WithDescription("Analyze the real exposure of the user portfolio, looking through the ETFs into their top holdings: exposure by asset class, sector, industry, country and single name, concentration (top 10 weight, Herfindahl index) and the names held both directly and through funds. Answers questions like \"how much tech am I really exposed to?\"."),
+		mcp.WithInputSchema[GetPortfolioExposureRequest](),
+		mcp.WithOutputSchema[GetPortfolioExposureResponse](),
+	)
+}
@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type PortfolioValuationService interface {
+	GetPortfolioValuation(userID string, baseCurrency domain.Currency) (domain.PortfolioValuation, error)
+}
+
+type GetPortfolioValuationRequest struct {
+	UserID       string `json:"user_id" jsonschema_description:"The id of the user whose portfolio is valued"`
+	BaseCurrency string `json:"base_currency,omitempty" jsonschema_description:"Currency code of the currency the portfolio is valued in" jsonschema:"enum=AED,enum=USD,enum=EUR,enum=GBP,enum=JPY,enum=CHF,enum=CAD,enum=AUD,default=USD"`
+}
+
+type HoldingValuationSchema struct {
+	AssetClass    string  `json:"asset_class"`
+	Symbol        string  `json:"symbol"`
+	Name          string  `json:"name"`
+	Quantity      float64 `json:"quantity"`
+	Price         float64 `json:"price" jsonschema_description:"Price of one unit in price_currency"`
+	PriceCurrency string  `json:"price_currency"`
+	PriceDate     string  `json:"price_date" jsonschema_description:"Date of the price, YYYY-MM-DD format"`
+	PriceSource   string  `json:"price_source" jsonschema:"enum=stockanalysis,enum=coingecko,enum=face_value"`
+	MarketValue   float64 `json:"market_value" jsonschema_description:"Market value of the holding in the base currency"`
+	Weight        float64 `json:"weight" jsonschema_description:"Actual weight of the holding in the priced portfolio, 20 means 20%"`
+	StatedWeight  float64 `json:"stated_weight" jsonschema_description:"Portfolio percentage stated in the user context, 0 when not given"`
+	WeightDrift   float64 `json:"weight_drift" jsonschema_description:"Actual weight minus the stated weight in percentage points, 0 when the stated weight isn't given"`
+}
+
+type UnpricedHoldingSchema struct {
+	AssetClass   string  `json:"asset_class"`
+	Symbol       string  `json:"symbol"`
+	Name         string  `json:"name"`
+	Quantity     float64 `json:"quantity"`
+	StatedWeight float64 `json:"stated_weight" jsonschema_description:"Portfolio percentage stated in the user context, 0 when not given"`
+	Reason       string  `json:"reason" jsonschema_description:"Why the holding couldn't be priced"`
+}
+
+type GetPortfolioValuationResponse struct {
+	UserID           string                   `json:"user_id"`
+	BaseCurrency     string                   `json:"base_currency"`
+	TotalValue       float64                  `json:"total_value" jsonschema_description:"Market value of the priced holdings in the base currency"`
+	Holdings         []HoldingValuationSchema `json:"holdings" jsonschema_description:"Priced holdings, the largest first"`
+	UnpricedHoldings []UnpricedHoldingSchema  `json:"unpriced_holdings" jsonschema_description:"Holdings that couldn't be priced, they aren't part of the total value"`
+	ValuedAt         string                   `json:"valued_at" jsonschema_description:"When the portfolio was valued, ISO 8601 format"`
+}
+
+type GetPortfolioValuationTool struct {
+	portfolioValuationService PortfolioValuationService
+}
+
+func NewGetPortfolioValuationTool(portfolioValuationService PortfolioValuationService) (*GetPortfolioValuationTool, error) {
+	return &GetPortfolioValuationTool{
+		portfolioValuationService: portfolioValuationService,
+	}, nil
+}
+
+func (t *GetPortfolioValuationTool) HandleGetPortfolioValuation(ctx context.Context, req mcp.CallToolRequest, args GetPortfolioValuationRequest) (GetPortfolioValuationResponse, error) {
+	if args.UserID == "" {
+		return GetPortfolioValuationResponse{}, fmt.Errorf("user_id is required")
+	}
+	baseCurrency := domain.USD
+	if args.BaseCurrency != "" {
+		baseCurrency = domain.Currency(strings.ToUpper(args.BaseCurrency))
+	}
+	if _, ok := domain.CurrencyCodeToNameMap[baseCurrency]; !ok {
+		return GetPortfolioValuationResponse{}, fmt.Errorf("invalid base_currency: %s", args.BaseCurrency)
+	}
+
+	valuation, err := t.portfolioValuationService.GetPortfolioValuation(args.UserID, baseCurrency)
+	if err != nil {
+		return GetPortfolioValuationResponse{}, err
+	}
+
+	holdings := make([]HoldingValuationSchema, 0, len(valuation.Holdings))
+	for _, h := range valuation.Holdings {
+		var drift float64
+		if h.Holding.PortfolioPercentage > 0 {
+			drift = h.Weight - h.Holding.PortfolioPercentage
+		}
+		holdings = append(holdings, HoldingValuationSchema{
+			AssetClass:    string(h.Holding.AssetClass),
+			Symbol:        h.Holding.Symbol,
+			Name:          h.Holding.Name,
+			Quantity:      h.Holding.Quantity,
+			Price:         h.Price,
+			PriceCurrency: string(h.PriceCurrency),
+			PriceDate:     h.PriceDate.Format(time.DateOnly),
+			PriceSource:   h.PriceSource,
+			MarketValue:   h.MarketValue,
+			Weight:        h.Weight,
+			StatedWeight:  h.Holding.PortfolioPercentage,
+			WeightDrift:   drift,
+		})
+	}
+
+	unpriced := make([]UnpricedHoldingSchema, 0, len(valuation.Unpriced))
+	for _, h := range valuation.Unpriced {
+		unpriced = append(unpriced, UnpricedHoldingSchema{
+			AssetClass:   string(h.Holding.AssetClass),
+			Symbol:       h.Holding.Symbol,
+			Name:         h.Holding.Name,
+			Quantity:     h.Holding.Quantity,
+			StatedWeight: h.Holding.PortfolioPercentage,
+			Reason:       h.Reason,
+		})
+	}
+
+	return GetPortfolioValuationResponse{
+		UserID:           valuation.UserID,
+		BaseCurrency:     string(valuation.BaseCurrency),
+		TotalValue:       valuation.TotalValue,
+		Holdings:         holdings,
+		UnpricedHoldings: unpriced,
+		ValuedAt:         valuation.ValuedAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (t *GetPortfolioValuationTool) GetTool() mcp.Tool {
+	return mcp.NewTool("getPortfolioValuation",
+		mcp.WithDescription("Price the portfolio holdings of the user live and return the market value of each holding in the base currency, its actual weight versus the stated portfolio percentage, the total value and the holdings that couldn't be priced. Stocks and ETFs are priced at their last close, cryptocurrencies at their current price and cash (symbol = currency code) at face value."),
+		mcp.WithInputSchema[GetPortfolioValuationRequest](),
+		mcp.WithOutputSchema[GetPortfolioValuationResponse](),
+	)
+}
@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/services"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type RiskMetricsService interface {
+	GetRiskMetrics(query services.RiskMetricsQuery) (services.RiskMetricsResult, error)
+}
+
+type GetRiskMetricsRequest struct {
+	Symbol     string  `json:"symbol,omitempty" jsonschema_description:"Symbol of the stock, the ETF or the cryptocurrency, required without user_id"`
+	AssetClass string  `json:"asset_class,omitempty" jsonschema:"enum=stock,enum=etf,enum=crypto,default=stock"`
+	UserID     string  `json:"user_id,omitempty" jsonschema_description:"The id of the user whose portfolio is measured instead of a symbol"`
+	Benchmark  string  `json:"benchmark,omitempty" jsonschema_description:"Symbol of the benchmark ETF of the beta and the correlation" jsonschema:"default=SPY"`
+	Period     string  `json:"period,omitempty" jsonschema_description:"Period of the prices, ending now" jsonschema:"enum=1m,enum=ytd,enum=6m,enum=1y,enum=5y,enum=max,default=1y"`
+	Confidence float64 `json:"confidence,omitempty" jsonschema_description:"Confidence of the value at risk" jsonschema:"minimum=0.5,maximum=0.999,default=0.95"`
+}
+
+type MaxDrawdownSchema struct {
+	Percentage   float64 `json:"percentage" jsonschema_description:"Loss from the peak to the trough in percent, positive"`
+	PeakDate     string  `json:"peak_date" jsonschema_description:"ISO 8601 format"`
+	TroughDate   string  `json:"trough_date" jsonschema_description:"ISO 8601 format"`
+	RecoveryDate string  `json:"recovery_date,omitempty" jsonschema_description:"First date back at the peak, ISO 8601 format, absent when not recovered"`
+}
+
+type RiskHoldingSchema struct {
+	Symbol     string  `json:"symbol"`
+	AssetClass string  `json:"asset_class"`
+	Weight     float64 `json:"weight" jsonschema_description:"Weight in the measured portfolio in percent"`
+}
+
+type GetRiskMetricsResponse struct {
+	Symbol               string                  `json:"symbol,omitempty"`
+	AssetClass           string                  `json:"asset_class,omitempty"`
+	UserID               string                  `json:"user_id,omitempty"`
+	Benchmark            string                  `json:"benchmark"`
+	StartDate            string                  `json:"start_date" jsonschema_description:"ISO 8601 format"`
+	EndDate              string                  `json:"end_date" jsonschema_description:"ISO 8601 format"`
+	Observations         int                     `json:"observations" jsonschema_description:"Number of returns, one per day with a price (for both the subject and the benchmark)"`
+	PeriodsPerYear       float64                 `json:"periods_per_year" jsonschema_description:"Returns per year the statistics are annualized with"`
+	TotalReturn          float64                 `json:"total_return" jsonschema_description:"In percent"`
+	AnnualizedReturn     float64                 `json:"annualized_return" jsonschema_description:"Compound annual growth rate in percent"`
+	AnnualizedVolatility float64                 `json:"annualized_volatility" jsonschema_description:"In percent"`
+	MaxDrawdown          MaxDrawdownSchema       `json:"max_drawdown"`
+	RiskFreeRate         float64                 `json:"risk_free_rate" jsonschema_description:"Mean 3 month treasury yield over the period in percent"`
+	SharpeRatio          float64                 `json:"sharpe_ratio"`
+	SortinoRatio         float64                 `json:"sortino_ratio"`
+	Beta                 float64                 `json:"beta" jsonschema_description:"Against the benchmark"`
+	Correlation          float64                 `json:"correlation" jsonschema_description:"Correlation of the returns with the ones of the benchmark, from -1 to 1"`
+	Confidence           float64                 `json:"confidence"`
+	ValueAtRisk          float64                 `json:"value_at_risk" jsonschema_description:"Historical loss of a period (a day for the daily prices) not exceeded with the confidence, in percent"`
+	ExpectedShortfall    float64                 `json:"expected_shortfall" jsonschema_description:"Mean loss of the periods beyond the value at risk in percent"`
+	Holdings             []RiskHoldingSchema     `json:"holdings,omitempty" jsonschema_description:"The holdings of the portfolio measured, with their current weights held over the period"`
+	Excluded             []UnpricedHoldingSchema `json:"excluded,omitempty" jsonschema_description:"The holdings of the portfolio left out, e.g. without a price history"`
+	Warnings             []string                `json:"warnings,omitempty"`
+}
+
+type GetRiskMetricsTool struct {
+	riskMetricsService RiskMetricsService
+}
+
+func NewGetRiskMetricsTool(riskMetricsService RiskMetricsService) (*GetRiskMetricsTool, error) {
+	return &GetRiskMetricsTool{
+		riskMetricsService: riskMetricsService,
+	}, nil
+}
+
+func (t *GetRiskMetricsTool) HandleGetRiskMetrics(ctx context.Context, req mcp.CallToolRequest, args GetRiskMetricsRequest) (GetRiskMetricsResponse, error) {
+	if args.Symbol == "" && args.UserID == "" {
+		return GetRiskMetricsResponse{}, fmt.Errorf("symbol or user_id is required")
+	}
+
+	result, err := t.riskMetricsService.GetRiskMetrics(services.RiskMetricsQuery{
+		Symbol:     args.Symbol,
+		AssetClass: domain.AssetClass(args.AssetClass),
+		UserID:     args.UserID,
+		Benchmark:  args.Benchmark,
+		Period:     domain.Period(args.Period),
+		Confidence: args.Confidence,
+	})
+	if err != nil {
+		return GetRiskMetricsResponse{}, err
+	}
+
+	m := result.Metrics
+	response := GetRiskMetricsResponse{
+		Symbol:               result.Symbol,
+		AssetClass:           string(result.AssetClass),
+		UserID:               result.UserID,
+		Benchmark:            result.Benchmark,
+		StartDate:            m.Start.Format(time.RFC3339),
+		EndDate:              m.End.Format(time.RFC3339),
+		Observations:         m.Observations,
+		PeriodsPerYear:       m.PeriodsPerYear,
+		TotalReturn:          m.TotalReturn * 100,
+		AnnualizedReturn:     m.AnnualizedReturn * 100,
+		AnnualizedVolatility: m.AnnualizedVolatility * 100,
+		MaxDrawdown: MaxDrawdownSchema{
+			Percentage: m.MaxDrawdown.Depth * 100,
+			PeakDate:   m.MaxDrawdown.Peak.Format(time.RFC3339),
+			TroughDate: m.MaxDrawdown.Trough.Format(time.RFC3339),
+		},
+		RiskFreeRate:      m.RiskFreeRate * 100,
+		SharpeRatio:       m.SharpeRatio,
+		SortinoRatio:      m.SortinoRatio,
+		Beta:              m.Beta,
+		Correlation:       m.Correlation,
+		Confidence:        m.Confidence,
+		ValueAtRisk:       m.ValueAtRisk * 100,
+		ExpectedShortfall: m.ExpectedShortfall * 100,
+		Warnings:          result.Warnings,
+	}
+	if !m.MaxDrawdown.Recovery.IsZero() {
+		response.MaxDrawdown.RecoveryDate = m.MaxDrawdown.Recovery.Format(time.RFC3339)
+	}
+	for _, holding := range result.Holdings {
+		response.Holdings = append(response.Holdings, RiskHoldingSchema{
+			Symbol:     holding.Symbol,
+			AssetClass: string(holding.AssetClass),
+			Weight:     holding.Weight,
+		})
+	}
+	for _, excluded := range result.Excluded {
+		response.Excluded = append(response.Excluded, UnpricedHoldingSchema{
+			AssetClass:   string(excluded.Holding.AssetClass),
+			Symbol:       excluded.Holding.Symbol,
+			Name:         excluded.Holding.Name,
+			Quantity:     excluded.Holding.Quantity,
+			StatedWeight: excluded.Holding.PortfolioPercentage,
+			Reason:       excluded.Reason,
+		})
+	}
+
+	return response, nil
+}
+
+func (t *GetRiskMetricsTool) GetTool() mcp.Tool {
+	return mcp.NewTool("getRiskMetrics",
+		mcp.WithDescription("Get the risk and return statistics of a stock, an ETF, a cryptocurrency or a user portfolio over a period: "+
+			"annualized return and volatility, maximum drawdown with its dates, Sharpe and Sortino ratios over the 3 month treasury yield, "+
+			"beta and correlation against a benchmark ETF (SPY by default), and historical value at risk"),
+		mcp.WithInputSchema[GetRiskMetricsRequest](),
+		mcp.WithOutputSchema[GetRiskMetricsResponse](),
+	)
+}
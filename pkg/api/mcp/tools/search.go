@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/services"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type UniversalSearchService interface {
+	Search(filters services.UniversalSearchFilterOptions) (services.UniversalSearchResults, error)
+}
+
+type SearchRequest struct {
+	Query string   `json:"query" jsonschema_description:"Search query (symbol, company/ETF/cryptocurrency name, super investor, sector, industry or investing idea)"`
+	Types []string `json:"types,omitempty" jsonschema_description:"Limit the search to these result types (all types when empty)" jsonschema:"enum=stock,enum=etf,enum=crypto,enum=super_investor,enum=sector,enum=industry,enum=investing_idea"`
+	Limit int      `json:"limit,omitempty" jsonschema_description:"Maximum results" jsonschema:"minimum=1,maximum=100,default=50"`
+}
+
+type SearchResultSchema struct {
+	Type              string            `json:"type" jsonschema_description:"Type of the result" jsonschema:"enum=stock,enum=etf,enum=crypto,enum=super_investor,enum=sector,enum=industry,enum=investing_idea"`
+	ID                string            `json:"id" jsonschema_description:"ID of the result in its source (symbol, CoinGecko id, super investor name, sector/industry url name, investing idea id)"`
+	Symbol            string            `json:"symbol,omitempty" jsonschema_description:"Symbol of the stock, ETF or cryptocurrency"`
+	Name              string            `json:"name" jsonschema_description:"Name of the result"`
+	Score             float64           `json:"score" jsonschema_description:"Relevance score of the result (higher is better, comparable across types)"`
+	MatchReason       string            `json:"match_reason" jsonschema_description:"Why the result matched the query" jsonschema:"enum=exact_symbol,enum=symbol_prefix,enum=name_token_prefix,enum=fuzzy,enum=contains"`
+	NextTool          string            `json:"next_tool,omitempty" jsonschema_description:"The tool to call to get more data about the result"`
+	NextToolArguments map[string]string `json:"next_tool_arguments,omitempty" jsonschema_description:"The arguments to call the next_tool with"`
+}
+
+type SearchResponse struct {
+	Results       []SearchResultSchema `json:"results" jsonschema_description:"Search results ranked across all types"`
+	FailedSources []string             `json:"failed_sources,omitempty" jsonschema_description:"Result types that couldn't be searched, the results of the rest are still returned"`
+}
+
+type SearchTool struct {
+	searchService UniversalSearchService
+}
+
+func NewSearchTool(searchService UniversalSearchService) (*SearchTool, error) {
+	return &SearchTool{
+		searchService: searchService,
+	}, nil
+}
+
+func (t *SearchTool) HandleSearch(ctx context.Context, req mcp.CallToolRequest, args SearchRequest) (SearchResponse, error) {
+	if strings.TrimSpace(args.Query) == "" {
+		return SearchResponse{}, fmt.Errorf("query is required")
+	}
+
+	types := make([]domain.SearchResultType, 0, len(args.Types))
+	for _, resultType := range args.Types {
+		types = append(types, domain.SearchResultType(resultType))
+	}
+
+	results, err := t.searchService.Search(services.UniversalSearchFilterOptions{
+		Query: args.Query,
+		Types: types,
+		Limit: args.Limit,
+	})
+	if err != nil {
+		return SearchResponse{}, err
+	}
+
+	response := SearchResponse{
+		Results:       make([]SearchResultSchema, 0, len(results.Results)),
+		FailedSources: make([]string, 0, len(results.FailedSources)),
+	}
+
+	for _, result := range results.Results {
+		nextTool, nextToolArguments := nextToolForSearchResult(result)
+		response.Results = append(response.Results, SearchResultSchema{
+			Type:              string(result.Type),
+			ID:                result.ID,
+			Symbol:            result.Symbol,
+			Name:              result.Name,
+			Score:             result.Score,
+			MatchReason:       result.MatchReason,
+			NextTool:          nextTool,
+			NextToolArguments: nextToolArguments,
+		})
+	}
+
+	for resultType := range results.FailedSources {
+		response.FailedSources = append(response.FailedSources, string(resultType))
+	}
+	slices.Sort(response.FailedSources)
+
+	return response, nil
+}
+
+// nextToolForSearchResult returns the tool (and its arguments) that gives more data about a search result
+func nextToolForSearchResult(result domain.SearchResult) (string, map[string]string) {
+	switch result.Type {
+	case domain.SearchResultStock:
+		return "getStockOverview", map[string]string{"stock_symbol": result.ID}
+	case domain.SearchResultEtf:
+		return "getETF", map[string]string{"etf_symbol": result.ID}
+	case domain.SearchResultCrypto:
+		return "getCryptocurrencyDataById", map[string]string{"id": result.ID}
+	case domain.SearchResultSuperInvestor:
+		return "getSuperInvestorPortfolio", map[string]string{"super_investor_name": result.ID}
+	case domain.SearchResultSector:
+		return "getSectorStocks", map[string]string{"url_name": result.ID}
+	case domain.SearchResultIndustry:
+		return "getIndustryStocks", map[string]string{"url_name": result.ID}
+	case domain.SearchResultInvestingIdea:
+		return "getInvestingIdeaStocks", map[string]string{"idea_id": result.ID}
+	default:
+		return "", nil
+	}
+}
+
+func (t *SearchTool) GetTool() mcp.Tool {
+	return mcp.NewTool("search",
+		mcp.WithDescription("Search stocks, ETFs, cryptocurrencies, super investors, sectors, industries and investing ideas at once. "+
+			"Use it when you don't know what kind of asset the query refers to. "+
+			"Each result is tagged with its type and the tool (with its arguments) to call next to get more data."),
+		mcp.WithInputSchema[SearchRequest](),
+		mcp.WithOutputSchema[SearchResponse](),
+	)
+}
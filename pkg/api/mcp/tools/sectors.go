@@ -73,7 +73,7 @@ func (t *GetSectorsTool) HandleGetSectors(ctx context.Context, req mcp.CallToolR
 
 func (t *GetSectorsTool) GetTool() mcp.Tool {
 	return mcp.NewTool("getSectors",
-		mcp.WithDescription("Get all stock sectors"),
+		mcp.WithDescription("Get all stock sectors. Use getIndustries with the url name of a sector to get its industries."),
 		mcp.WithInputSchema[GetSectorsRequest](),
 		mcp.WithOutputSchema[GetSectorsResponse](),
 	)
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type StockComparisonService interface {
+	CompareStocks(symbols []string, sets []domain.MetricSet) (domain.StockComparison, error)
+}
+
+type CompareStocksRequest struct {
+	Symbols    []string `json:"symbols" jsonschema_description:"Symbols of the stocks to compare, from 2 to 10 (e.g. AAPL, MSFT)" jsonschema:"minItems=2,maxItems=10"`
+	MetricSets []string `json:"metric_sets,omitempty" jsonschema_description:"Sets of the metrics to compare (all of them when empty)" jsonschema:"enum=valuation,enum=profitability,enum=growth,enum=leverage,enum=performance"`
+}
+
+type ComparedValueSchema struct {
+	Symbol string   `json:"symbol"`
+	Value  *float64 `json:"value,omitempty" jsonschema_description:"Absent when missing"`
+	Rank   int      `json:"rank" jsonschema_description:"Rank among the symbols, 1 is the best and the equal values share their rank. 0 when missing or not meaningful, e.g. a negative P/E."`
+}
+
+type ComparedMetricSchema struct {
+	Name   string                `json:"name"`
+	Set    string                `json:"set"`
+	Unit   string                `json:"unit" jsonschema:"enum=ratio,enum=percent,enum=usd"`
+	Better string                `json:"better" jsonschema_description:"Whether the higher or the lower values rank first" jsonschema:"enum=higher,enum=lower"`
+	Values []ComparedValueSchema `json:"values" jsonschema_description:"The values in the order of the symbols"`
+	Min    *float64              `json:"min,omitempty" jsonschema_description:"Of the ranked values"`
+	Max    *float64              `json:"max,omitempty" jsonschema_description:"Of the ranked values"`
+	Median *float64              `json:"median,omitempty" jsonschema_description:"Of the ranked values"`
+}
+
+type ComparisonErrorSchema struct {
+	Symbol string `json:"symbol"`
+	Error  string `json:"error"`
+}
+
+type CompareStocksResponse struct {
+	Symbols []string                `json:"symbols"`
+	Metrics []ComparedMetricSchema  `json:"metrics"`
+	Errors  []ComparisonErrorSchema `json:"errors,omitempty" jsonschema_description:"The data that couldn't be fetched, the metrics depending on it are missing"`
+}
+
+type CompareStocksTool struct {
+	stockComparisonService StockComparisonService
+}
+
+func NewCompareStocksTool(stockComparisonService StockComparisonService) (*CompareStocksTool, error) {
+	return &CompareStocksTool{
+		stockComparisonService: stockComparisonService,
+	}, nil
+}
+
+func (t *CompareStocksTool) HandleCompareStocks(ctx context.Context, req mcp.CallToolRequest, args CompareStocksRequest) (CompareStocksResponse, error) {
+	if len(args.Symbols) == 0 {
+		return CompareStocksResponse{}, fmt.Errorf("symbols is required")
+	}
+
+	var sets []domain.MetricSet
+	for _, set := range args.MetricSets {
+		sets = append(sets, domain.MetricSet(set))
+	}
+	comparison, err := t.stockComparisonService.CompareStocks(args.Symbols, sets)
+	if err != nil {
+		return CompareStocksResponse{}, err
+	}
+
+	response := CompareStocksResponse{
+		Symbols: comparison.Symbols,
+		Metrics: make([]ComparedMetricSchema, len(comparison.Metrics)),
+	}
+	for i, metric := range comparison.Metrics {
+		better := "lower"
+		if metric.HigherIsBetter {
+			better = "higher"
+		}
+		response.Metrics[i] = ComparedMetricSchema{
+			Name:   metric.Name,
+			Set:    string(metric.Set),
+			Unit:   string(metric.Unit),
+			Better: better,
+			Values: make([]ComparedValueSchema, len(metric.Values)),
+			Min:    metric.Min,
+			Max:    metric.Max,
+			Median: metric.Median,
+		}
+		for j, value := range metric.Values {
+			response.Metrics[i].Values[j] = ComparedValueSchema{
+				Symbol: value.Symbol,
+				Value:  value.Value,
+				Rank:   value.Rank,
+			}
+		}
+	}
+	for _, e := range comparison.Errors {
+		response.Errors = append(response.Errors, ComparisonErrorSchema{Symbol: e.Symbol, Error: e.Error})
+	}
+
+	return response, nil
+}
+
+func (t *CompareStocksTool) GetTool() mcp.Tool {
+	return mcp.NewTool("compareStocks",
+		mcp.WithDescription("Compare 2 to 10 stocks side by side on the metrics of the sets: valuation (market cap, P/E, P/S, P/B, P/FCF, "+
+			"EV multiples, yields, analyst target upside), profitability (ROE, ROA, ROIC, asset turnover), growth (market cap growth, "+
+			"next quarter revenue and EPS growth estimates), leverage (debt ratios, current and quick ratios) and performance "+
+			"(1 month, YTD and 1 year changes). Each metric has the value and the rank of every stock, and the min, max and median."),
+		mcp.WithInputSchema[CompareStocksRequest](),
+		mcp.WithOutputSchema[CompareStocksResponse](),
+	)
+}
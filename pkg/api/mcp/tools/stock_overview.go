@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"market_data_mcp_server/pkg/domain"
+	"strings"
 	"sync"
 	"time"
 
@@ -108,6 +109,9 @@ type GetStockOverviewResponse struct {
 	HistoricalPerformance []HistoricalPerformanceSchema `json:"stock_historical_performance" jsonschema_description:"Historical price performance of the stock"`
 }
 
+// StockOverviewPerformancePeriods are the periods of the historical performance of the overview
+var StockOverviewPerformancePeriods = []domain.Period{domain.Period5D, domain.Period1M, domain.Period6M, domain.Period1Y, domain.Period5Y}
+
 type GetStockOverviewTool struct {
 	stockOverviewService StockOverviewService
 }
@@ -123,19 +127,20 @@ func (t *GetStockOverviewTool) HandleGetStockOverview(ctx context.Context, req m
 		return GetStockOverviewResponse{}, fmt.Errorf("stock_symbol is required")
 	}
 
-	stockSymbol := args.StockSymbol
+	// Lowercase as expected by the scrapers, so that the cache entries are shared whatever the case of the request
+	stockSymbol := strings.ToLower(args.StockSymbol)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var fetchErr error
 
 	response := GetStockOverviewResponse{
-		Symbol:                stockSymbol,
+		Symbol:                args.StockSymbol,
 		CurrentDate:           time.Now().Format("2006-01-02"),
 		StockFinancialRatios:  make([]FinancialRatiosSchema, 0),
 		HistoricalPerformance: make([]HistoricalPerformanceSchema, 0),
 	}
 
-	wg.Add(8) // 3 main + 5 historical
+	wg.Add(3 + len(StockOverviewPerformancePeriods)) // 3 main + historical
 
 	// Fetch stock profile
 	go func() {
@@ -284,10 +289,9 @@ func (t *GetStockOverviewTool) HandleGetStockOverview(ctx context.Context, req m
 	}()
 
 	// Fetch historical performance for multiple periods
-	periods := []domain.Period{domain.Period5D, domain.Period1M, domain.Period6M, domain.Period1Y, domain.Period5Y}
-	performanceList := make([]HistoricalPerformanceSchema, 5)
+	performanceList := make([]HistoricalPerformanceSchema, len(StockOverviewPerformancePeriods))
 
-	for i, period := range periods {
+	for i, period := range StockOverviewPerformancePeriods {
 		index, performancePeriod := i, period // capture loop variables for goroutines
 		go func() {
 			defer wg.Done()
@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type StockScreenerService interface {
+	ScreenStocks(query domain.ScreenerQuery) (domain.ScreenerResult, error)
+}
+
+type ScreenerFilterSchema struct {
+	Field    string   `json:"field" jsonschema:"enum=symbol,enum=name,enum=market_cap,enum=price,enum=change_1d,enum=revenue,enum=pe,enum=ps,enum=pb,enum=dividend_yield,enum=revenue_growth,enum=gross_margin,enum=operating_margin,enum=profit_margin,enum=sector,enum=industry,enum=exchange,enum=country"`
+	Operator string   `json:"operator" jsonschema_description:"gt, gte, lt, lte and between (inclusive) compare the number fields with value, eq, neq and contains the text fields with text, in with texts. The text comparisons are case insensitive." jsonschema:"enum=gt,enum=gte,enum=lt,enum=lte,enum=between,enum=eq,enum=neq,enum=in,enum=contains"`
+	Value    float64  `json:"value,omitempty" jsonschema_description:"The number of the comparison, the lower bound of between. The percents are in percent (e.g. 2.5 for 2.5%)."`
+	Upper    float64  `json:"upper,omitempty" jsonschema_description:"The upper bound of between"`
+	Text     string   `json:"text,omitempty" jsonschema_description:"The text of eq, neq and contains"`
+	Texts    []string `json:"texts,omitempty" jsonschema_description:"The texts of in"`
+}
+
+type ScreenStocksRequest struct {
+	Filters []ScreenerFilterSchema `json:"filters,omitempty" jsonschema_description:"The filters the stocks must all match, the stocks without a value for a filtered field don't"`
+	Sort    string                 `json:"sort,omitempty" jsonschema_description:"Field the stocks are sorted by, the stocks without a value last" jsonschema:"default=market_cap"`
+	Order   string                 `json:"order,omitempty" jsonschema:"enum=asc,enum=desc,default=desc"`
+	Limit   int                    `json:"limit,omitempty" jsonschema:"minimum=1,maximum=500,default=25"`
+	Columns []string               `json:"columns,omitempty" jsonschema_description:"Fields returned for each stock besides the symbol and the name, market_cap, price, pe, dividend_yield, sector and industry by default"`
+}
+
+type ScreenedStockSchema struct {
+	Symbol string         `json:"symbol"`
+	Name   string         `json:"name"`
+	Values map[string]any `json:"values" jsonschema_description:"The values of the columns by field, absent when unknown"`
+}
+
+type ScreenStocksResponse struct {
+	Stocks        []ScreenedStockSchema `json:"stocks"`
+	Matches       int                   `json:"matches" jsonschema_description:"Number of the stocks matching the filters, before the limit"`
+	PushedFilters []string              `json:"pushed_filters,omitempty" jsonschema_description:"The filters applied by the screener"`
+	LocalFilters  []string              `json:"local_filters,omitempty" jsonschema_description:"The filters the screener doesn't support, applied to the stocks it returned"`
+}
+
+type ScreenStocksTool struct {
+	stockScreenerService StockScreenerService
+}
+
+func NewScreenStocksTool(stockScreenerService StockScreenerService) (*ScreenStocksTool, error) {
+	return &ScreenStocksTool{
+		stockScreenerService: stockScreenerService,
+	}, nil
+}
+
+func (t *ScreenStocksTool) HandleScreenStocks(ctx context.Context, req mcp.CallToolRequest, args ScreenStocksRequest) (ScreenStocksResponse, error) {
+	desc, err := descending(args.Order)
+	if err != nil {
+		return ScreenStocksResponse{}, err
+	}
+
+	query := domain.ScreenerQuery{
+		Sort:       args.Sort,
+		Descending: desc,
+		Limit:      args.Limit,
+		Columns:    args.Columns,
+	}
+	for _, filter := range args.Filters {
+		texts := filter.Texts
+		if filter.Text != "" {
+			texts = append([]string{filter.Text}, texts...)
+		}
+		query.Filters = append(query.Filters, domain.ScreenerFilter{
+			Field:    filter.Field,
+			Operator: domain.ScreenerOperator(filter.Operator),
+			Value:    filter.Value,
+			Upper:    filter.Upper,
+			Texts:    texts,
+		})
+	}
+
+	result, err := t.stockScreenerService.ScreenStocks(query)
+	if err != nil {
+		return ScreenStocksResponse{}, err
+	}
+
+	response := ScreenStocksResponse{
+		Stocks:  make([]ScreenedStockSchema, len(result.Stocks)),
+		Matches: result.Matches,
+	}
+	for i, stock := range result.Stocks {
+		response.Stocks[i] = ScreenedStockSchema{
+			Symbol: stock.Symbol,
+			Name:   stock.Name,
+			Values: stock.Values,
+		}
+	}
+	for _, filter := range result.Pushed {
+		response.PushedFilters = append(response.PushedFilters, formatScreenerFilter(filter))
+	}
+	for _, filter := range result.Local {
+		response.LocalFilters = append(response.LocalFilters, formatScreenerFilter(filter))
+	}
+
+	return response, nil
+}
+
+// formatScreenerFilter returns the filter as field operator value, e.g. market_cap gt 10000000000
+func formatScreenerFilter(filter domain.ScreenerFilter) string {
+	value := strconv.FormatFloat(filter.Value, 'f', -1, 64)
+	switch filter.Operator {
+	case domain.BetweenScreenerOperator:
+		value += " and " + strconv.FormatFloat(filter.Upper, 'f', -1, 64)
+	case domain.EqualScreenerOperator, domain.NotEqualScreenerOperator, domain.InScreenerOperator, domain.ContainsScreenerOperator:
+		value = strings.Join(filter.Texts, ", ")
+	}
+	return fmt.Sprintf("%s %s %s", filter.Field, filter.Operator, value)
+}
+
+func (t *ScreenStocksTool) GetTool() mcp.Tool {
+	var fields []string
+	for _, field := range domain.ScreenerFields {
+		description := field.Name + " (" + field.Description
+		if field.Unit != "" {
+			description += ", " + string(field.Unit)
+		}
+		fields = append(fields, description+")")
+	}
+
+	return mcp.NewTool("screenStocks",
+		mcp.WithDescription("Screen the US stocks with filters on their fields, sorted by a field and with the selected fields as columns. "+
+			"The fields are: "+strings.Join(fields, ", ")+"."),
+		mcp.WithInputSchema[ScreenStocksRequest](),
+		mcp.WithOutputSchema[ScreenStocksResponse](),
+	)
+}
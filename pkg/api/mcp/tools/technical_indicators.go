@@ -0,0 +1,390 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/analytics/technical"
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// technicalIndicatorsMaxPoints keeps the prices of the history whole, the indicators are computed over every bar
+	technicalIndicatorsMaxPoints = 5000
+	maxIndicatorPeriod           = 1000
+	maxIndicatorSeriesPoints     = 250
+	rsiOverbought                = 70
+	rsiOversold                  = 30
+)
+
+type TechnicalIndicatorsParametersSchema struct {
+	SmaPeriods                  []int   `json:"sma_periods,omitempty" jsonschema_description:"Periods of the simple moving averages, 20, 50 and 200 by default"`
+	EmaPeriods                  []int   `json:"ema_periods,omitempty" jsonschema_description:"Periods of the exponential moving averages, 12 and 26 by default"`
+	RsiPeriod                   int     `json:"rsi_period,omitempty" jsonschema:"minimum=1,maximum=1000,default=14"`
+	MacdFastPeriod              int     `json:"macd_fast_period,omitempty" jsonschema:"minimum=1,maximum=1000,default=12"`
+	MacdSlowPeriod              int     `json:"macd_slow_period,omitempty" jsonschema:"minimum=1,maximum=1000,default=26"`
+	MacdSignalPeriod            int     `json:"macd_signal_period,omitempty" jsonschema:"minimum=1,maximum=1000,default=9"`
+	BollingerPeriod             int     `json:"bollinger_period,omitempty" jsonschema:"minimum=1,maximum=1000,default=20"`
+	BollingerStandardDeviations float64 `json:"bollinger_standard_deviations,omitempty" jsonschema:"minimum=0.1,maximum=10,default=2"`
+	AtrPeriod                   int     `json:"atr_period,omitempty" jsonschema:"minimum=1,maximum=1000,default=14"`
+	CrossFastPeriod             int     `json:"cross_fast_period,omitempty" jsonschema_description:"Period of the fast simple moving average of the golden and death crosses" jsonschema:"minimum=1,maximum=1000,default=50"`
+	CrossSlowPeriod             int     `json:"cross_slow_period,omitempty" jsonschema_description:"Period of the slow simple moving average of the golden and death crosses" jsonschema:"minimum=1,maximum=1000,default=200"`
+}
+
+type GetTechnicalIndicatorsRequest struct {
+	Symbol       string                              `json:"symbol" jsonschema_description:"Symbol of the stock, the ETF or the cryptocurrency (e.g. AAPL, SPY, BTC)"`
+	AssetClass   string                              `json:"asset_class,omitempty" jsonschema:"enum=stock,enum=etf,enum=crypto,default=stock"`
+	Indicators   []string                            `json:"indicators,omitempty" jsonschema_description:"Indicators to compute (all of them when empty)" jsonschema:"enum=sma,enum=ema,enum=rsi,enum=macd,enum=bollinger_bands,enum=atr,enum=vwap,enum=52_week_range,enum=moving_average_crosses"`
+	Period       string                              `json:"period,omitempty" jsonschema_description:"Period of the prices the indicators are computed over, ending now" jsonschema:"enum=1m,enum=ytd,enum=6m,enum=1y,enum=5y,enum=max,default=1y"`
+	Ohlcv        bool                                `json:"ohlcv,omitempty" jsonschema_description:"Compute over the OHLCV bars of the stocks and the ETFs, needed by the ATR and the VWAP: daily bars for the periods starting within 140 days, weekly bars before. The cryptocurrencies have the closes and the 24h volumes, enough for the VWAP."`
+	Parameters   TechnicalIndicatorsParametersSchema `json:"parameters,omitempty" jsonschema_description:"Parameters of the indicators, the usual ones by default"`
+	SeriesPoints int                                 `json:"series_points,omitempty" jsonschema_description:"Number of the most recent values of each indicator to return besides the last one" jsonschema:"minimum=0,maximum=250,default=0"`
+}
+
+type IndicatorPointSchema struct {
+	Date  string  `json:"date" jsonschema_description:"ISO 8601 format"`
+	Value float64 `json:"value"`
+}
+
+type MovingAverageSchema struct {
+	Period                int                    `json:"period"`
+	Value                 float64                `json:"value"`
+	CloseVsAveragePercent float64                `json:"close_vs_average_percent" jsonschema_description:"Distance of the last close above (positive) or below the average in percent"`
+	Series                []IndicatorPointSchema `json:"series,omitempty"`
+}
+
+type RsiSchema struct {
+	Period int                    `json:"period"`
+	Value  float64                `json:"value"`
+	Zone   string                 `json:"zone" jsonschema:"enum=overbought,enum=oversold,enum=neutral" jsonschema_description:"Overbought from 70, oversold up to 30"`
+	Series []IndicatorPointSchema `json:"series,omitempty"`
+}
+
+type MacdPointSchema struct {
+	Date      string  `json:"date" jsonschema_description:"ISO 8601 format"`
+	Macd      float64 `json:"macd"`
+	Signal    float64 `json:"signal"`
+	Histogram float64 `json:"histogram"`
+}
+
+type MacdSchema struct {
+	FastPeriod   int               `json:"fast_period"`
+	SlowPeriod   int               `json:"slow_period"`
+	SignalPeriod int               `json:"signal_period"`
+	Macd         float64           `json:"macd" jsonschema_description:"Fast EMA minus slow EMA"`
+	Signal       float64           `json:"signal" jsonschema_description:"EMA of the MACD"`
+	Histogram    float64           `json:"histogram" jsonschema_description:"MACD minus signal"`
+	Series       []MacdPointSchema `json:"series,omitempty"`
+}
+
+type BollingerPointSchema struct {
+	Date   string  `json:"date" jsonschema_description:"ISO 8601 format"`
+	Middle float64 `json:"middle"`
+	Upper  float64 `json:"upper"`
+	Lower  float64 `json:"lower"`
+}
+
+type BollingerBandsSchema struct {
+	Period             int                    `json:"period"`
+	StandardDeviations float64                `json:"standard_deviations"`
+	Middle             float64                `json:"middle" jsonschema_description:"Simple moving average"`
+	Upper              float64                `json:"upper"`
+	Lower              float64                `json:"lower"`
+	PercentB           float64                `json:"percent_b" jsonschema_description:"Position of the last close in the bands, 0 at the lower band and 1 at the upper band"`
+	Series             []BollingerPointSchema `json:"series,omitempty"`
+}
+
+type AtrSchema struct {
+	Period            int                    `json:"period"`
+	Value             float64                `json:"value"`
+	PercentageOfClose float64                `json:"percentage_of_close"`
+	Series            []IndicatorPointSchema `json:"series,omitempty"`
+}
+
+type VwapSchema struct {
+	Value     float64                `json:"value"`
+	StartDate string                 `json:"start_date" jsonschema_description:"Date the volumes are accumulated from, ISO 8601 format"`
+	Series    []IndicatorPointSchema `json:"series,omitempty"`
+}
+
+type FiftyTwoWeekRangeSchema struct {
+	High               float64 `json:"high"`
+	HighDate           string  `json:"high_date" jsonschema_description:"ISO 8601 format"`
+	Low                float64 `json:"low"`
+	LowDate            string  `json:"low_date" jsonschema_description:"ISO 8601 format"`
+	PercentageFromHigh float64 `json:"percentage_from_high" jsonschema_description:"Distance of the last close from the high in percent, 0 or negative"`
+	PercentageAboveLow float64 `json:"percentage_above_low" jsonschema_description:"Distance of the last close above the low in percent"`
+}
+
+type MovingAverageCrossSchema struct {
+	Date string `json:"date" jsonschema_description:"ISO 8601 format"`
+	Type string `json:"type" jsonschema:"enum=golden,enum=death" jsonschema_description:"Golden when the fast average crosses above the slow one, death when it crosses below"`
+}
+
+type MovingAverageCrossesSchema struct {
+	FastPeriod    int                        `json:"fast_period"`
+	SlowPeriod    int                        `json:"slow_period"`
+	FastAboveSlow bool                       `json:"fast_above_slow" jsonschema_description:"Whether the fast average is above the slow one at the last close"`
+	Crosses       []MovingAverageCrossSchema `json:"crosses" jsonschema_description:"Crosses over the period, the most recent first"`
+}
+
+type UnavailableIndicatorSchema struct {
+	Indicator string `json:"indicator"`
+	Reason    string `json:"reason"`
+}
+
+type GetTechnicalIndicatorsResponse struct {
+	Symbol               string                       `json:"symbol"`
+	AssetClass           string                       `json:"asset_class"`
+	Source               string                       `json:"source"`
+	StartDate            string                       `json:"start_date" jsonschema_description:"Date of the first price, ISO 8601 format"`
+	EndDate              string                       `json:"end_date" jsonschema_description:"Date of the last price, ISO 8601 format"`
+	Prices               int                          `json:"prices" jsonschema_description:"Number of prices the indicators are computed over"`
+	LastClose            float64                      `json:"last_close"`
+	Sma                  []MovingAverageSchema        `json:"sma,omitempty"`
+	Ema                  []MovingAverageSchema        `json:"ema,omitempty"`
+	Rsi                  *RsiSchema                   `json:"rsi,omitempty"`
+	Macd                 *MacdSchema                  `json:"macd,omitempty"`
+	BollingerBands       *BollingerBandsSchema        `json:"bollinger_bands,omitempty"`
+	Atr                  *AtrSchema                   `json:"atr,omitempty"`
+	Vwap                 *VwapSchema                  `json:"vwap,omitempty"`
+	FiftyTwoWeekRange    *FiftyTwoWeekRangeSchema     `json:"52_week_range,omitempty"`
+	MovingAverageCrosses *MovingAverageCrossesSchema  `json:"moving_average_crosses,omitempty"`
+	Unavailable          []UnavailableIndicatorSchema `json:"unavailable,omitempty" jsonschema_description:"Indicators that couldn't be computed, e.g. for lack of prices, with the reason"`
+}
+
+type GetTechnicalIndicatorsTool struct {
+	priceHistoryService PriceHistoryService
+}
+
+func NewGetTechnicalIndicatorsTool(priceHistoryService PriceHistoryService) (*GetTechnicalIndicatorsTool, error) {
+	return &GetTechnicalIndicatorsTool{
+		priceHistoryService: priceHistoryService,
+	}, nil
+}
+
+func (t *GetTechnicalIndicatorsTool) HandleGetTechnicalIndicators(ctx context.Context, req mcp.CallToolRequest, args GetTechnicalIndicatorsRequest) (GetTechnicalIndicatorsResponse, error) {
+	if args.Symbol == "" {
+		return GetTechnicalIndicatorsResponse{}, fmt.Errorf("symbol is required")
+	}
+	if args.SeriesPoints < 0 || args.SeriesPoints > maxIndicatorSeriesPoints {
+		return GetTechnicalIndicatorsResponse{}, fmt.Errorf("series_points must be between 0 and %d", maxIndicatorSeriesPoints)
+	}
+	p := args.Parameters
+	periods := append(slices.Clone(p.SmaPeriods), p.EmaPeriods...)
+	periods = append(periods, p.RsiPeriod, p.MacdFastPeriod, p.MacdSlowPeriod, p.MacdSignalPeriod, p.BollingerPeriod, p.AtrPeriod, p.CrossFastPeriod, p.CrossSlowPeriod)
+	for _, period := range periods {
+		if period < 0 || period > maxIndicatorPeriod {
+			return GetTechnicalIndicatorsResponse{}, fmt.Errorf("the periods must be between 1 and %d", maxIndicatorPeriod)
+		}
+	}
+	if p.BollingerStandardDeviations < 0 {
+		return GetTechnicalIndicatorsResponse{}, fmt.Errorf("bollinger_standard_deviations must be positive")
+	}
+
+	indicators := make([]technical.Indicator, 0, len(args.Indicators))
+	for _, indicator := range args.Indicators {
+		indicators = append(indicators, technical.Indicator(indicator))
+	}
+
+	history, err := t.priceHistoryService.GetPriceHistory(domain.PriceHistoryQuery{
+		Symbol:     args.Symbol,
+		AssetClass: domain.AssetClass(args.AssetClass),
+		Period:     domain.Period(args.Period),
+		Ohlcv:      args.Ohlcv,
+		MaxPoints:  technicalIndicatorsMaxPoints,
+	})
+	if err != nil {
+		return GetTechnicalIndicatorsResponse{}, err
+	}
+
+	report, err := technical.Compute(domain.HistoricalPrices{
+		Period:           domain.Period(args.Period),
+		Prices:           history.Prices,
+		PercentageChange: history.PercentageChange,
+	}, indicators, technical.Parameters{
+		SMAPeriods:                  p.SmaPeriods,
+		EMAPeriods:                  p.EmaPeriods,
+		RSIPeriod:                   p.RsiPeriod,
+		MACDFastPeriod:              p.MacdFastPeriod,
+		MACDSlowPeriod:              p.MacdSlowPeriod,
+		MACDSignalPeriod:            p.MacdSignalPeriod,
+		BollingerPeriod:             p.BollingerPeriod,
+		BollingerStandardDeviations: p.BollingerStandardDeviations,
+		ATRPeriod:                   p.AtrPeriod,
+		CrossFastPeriod:             p.CrossFastPeriod,
+		CrossSlowPeriod:             p.CrossSlowPeriod,
+	})
+	if err != nil {
+		return GetTechnicalIndicatorsResponse{}, err
+	}
+
+	prices := history.Prices
+	lastClose := prices[len(prices)-1].ClosePrice
+	series := func(values []float64) []IndicatorPointSchema {
+		var points []IndicatorPointSchema
+		for i := max(len(values)-args.SeriesPoints, 0); i < len(values); i++ {
+			if !math.IsNaN(values[i]) {
+				points = append(points, IndicatorPointSchema{Date: prices[i].Date.Format(time.RFC3339), Value: values[i]})
+			}
+		}
+		return points
+	}
+	movingAverages := func(averages []technical.MovingAverage) []MovingAverageSchema {
+		var schemas []MovingAverageSchema
+		for _, average := range averages {
+			value := technical.Last(average.Values)
+			schemas = append(schemas, MovingAverageSchema{
+				Period:                average.Period,
+				Value:                 value,
+				CloseVsAveragePercent: percentageChange(value, lastClose),
+				Series:                series(average.Values),
+			})
+		}
+		return schemas
+	}
+
+	response := GetTechnicalIndicatorsResponse{
+		Symbol:     history.Symbol,
+		AssetClass: string(history.AssetClass),
+		Source:     history.Source,
+		StartDate:  history.Start.Format(time.RFC3339),
+		EndDate:    history.End.Format(time.RFC3339),
+		Prices:     len(prices),
+		LastClose:  lastClose,
+		Sma:        movingAverages(report.SMA),
+		Ema:        movingAverages(report.EMA),
+	}
+
+	if report.RSI != nil {
+		value := technical.Last(report.RSI)
+		zone := "neutral"
+		if value >= rsiOverbought {
+			zone = "overbought"
+		} else if value <= rsiOversold {
+			zone = "oversold"
+		}
+		response.Rsi = &RsiSchema{Period: report.Parameters.RSIPeriod, Value: value, Zone: zone, Series: series(report.RSI)}
+	}
+
+	if macd := report.MACD; macd != nil {
+		response.Macd = &MacdSchema{
+			FastPeriod:   report.Parameters.MACDFastPeriod,
+			SlowPeriod:   report.Parameters.MACDSlowPeriod,
+			SignalPeriod: report.Parameters.MACDSignalPeriod,
+			Macd:         technical.Last(macd.MACD),
+			Signal:       technical.Last(macd.Signal),
+			Histogram:    technical.Last(macd.Histogram),
+		}
+		for i := max(len(prices)-args.SeriesPoints, 0); i < len(prices); i++ {
+			if !math.IsNaN(macd.Histogram[i]) {
+				response.Macd.Series = append(response.Macd.Series, MacdPointSchema{
+					Date:      prices[i].Date.Format(time.RFC3339),
+					Macd:      macd.MACD[i],
+					Signal:    macd.Signal[i],
+					Histogram: macd.Histogram[i],
+				})
+			}
+		}
+	}
+
+	if bands := report.BollingerBands; bands != nil {
+		upper, lower := technical.Last(bands.Upper), technical.Last(bands.Lower)
+		var percentB float64
+		if upper != lower {
+			percentB = (lastClose - lower) / (upper - lower)
+		}
+		response.BollingerBands = &BollingerBandsSchema{
+			Period:             report.Parameters.BollingerPeriod,
+			StandardDeviations: report.Parameters.BollingerStandardDeviations,
+			Middle:             technical.Last(bands.Middle),
+			Upper:              upper,
+			Lower:              lower,
+			PercentB:           percentB,
+		}
+		for i := max(len(prices)-args.SeriesPoints, 0); i < len(prices); i++ {
+			if !math.IsNaN(bands.Middle[i]) {
+				response.BollingerBands.Series = append(response.BollingerBands.Series, BollingerPointSchema{
+					Date:   prices[i].Date.Format(time.RFC3339),
+					Middle: bands.Middle[i],
+					Upper:  bands.Upper[i],
+					Lower:  bands.Lower[i],
+				})
+			}
+		}
+	}
+
+	if report.ATR != nil {
+		value := technical.Last(report.ATR)
+		var percentage float64
+		if lastClose != 0 {
+			percentage = value / lastClose * 100
+		}
+		response.Atr = &AtrSchema{Period: report.Parameters.ATRPeriod, Value: value, PercentageOfClose: percentage, Series: series(report.ATR)}
+	}
+
+	if report.VWAP != nil {
+		response.Vwap = &VwapSchema{
+			Value:     technical.Last(report.VWAP),
+			StartDate: history.Start.Format(time.RFC3339),
+			Series:    series(report.VWAP),
+		}
+	}
+
+	if r := report.FiftyTwoWeek; r != nil {
+		response.FiftyTwoWeekRange = &FiftyTwoWeekRangeSchema{
+			High:               r.High,
+			HighDate:           r.HighDate.Format(time.RFC3339),
+			Low:                r.Low,
+			LowDate:            r.LowDate.Format(time.RFC3339),
+			PercentageFromHigh: percentageChange(r.High, lastClose),
+			PercentageAboveLow: percentageChange(r.Low, lastClose),
+		}
+	}
+
+	if crosses := report.Crosses; crosses != nil {
+		response.MovingAverageCrosses = &MovingAverageCrossesSchema{
+			FastPeriod:    crosses.FastPeriod,
+			SlowPeriod:    crosses.SlowPeriod,
+			FastAboveSlow: crosses.FastAbove,
+			Crosses:       make([]MovingAverageCrossSchema, 0, len(crosses.Crosses)),
+		}
+		for _, cross := range slices.Backward(crosses.Crosses) {
+			response.MovingAverageCrosses.Crosses = append(response.MovingAverageCrosses.Crosses, MovingAverageCrossSchema{
+				Date: cross.Date.Format(time.RFC3339),
+				Type: string(cross.Type),
+			})
+		}
+	}
+
+	for _, unavailable := range report.Unavailable {
+		response.Unavailable = append(response.Unavailable, UnavailableIndicatorSchema{
+			Indicator: string(unavailable.Indicator),
+			Reason:    unavailable.Reason,
+		})
+	}
+
+	return response, nil
+}
+
+// percentageChange returns the change from the reference to the value in percent, 0 for a zero reference
+func percentageChange(reference float64, value float64) float64 {
+	if reference == 0 {
+		return 0
+	}
+	return (value - reference) / reference * 100
+}
+
+func (t *GetTechnicalIndicatorsTool) GetTool() mcp.Tool {
+	return mcp.NewTool("getTechnicalIndicators",
+		mcp.WithDescription("Compute technical indicators over the historical prices of a stock, an ETF or a cryptocurrency: "+
+			"simple and exponential moving averages, RSI, MACD, Bollinger Bands, ATR, VWAP, 52-week high/low and golden/death crosses. "+
+			"The ATR needs the OHLCV bars (ohlcv) and the VWAP the volumes."),
+		mcp.WithInputSchema[GetTechnicalIndicatorsRequest](),
+		mcp.WithOutputSchema[GetTechnicalIndicatorsResponse](),
+	)
+}
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/search"
 	"market_data_mcp_server/pkg/services"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -10,20 +11,26 @@ import (
 
 type SearchStocksRequest struct {
 	SearchString string `json:"search_string,omitempty" jsonschema_description:"Search string query"`
-	Limit        int    `json:"limit,omitempty" jsonschema_description:"Maximum results" jsonschema:"minimum=1,default=100"`
+	Limit        int    `json:"limit,omitempty" jsonschema_description:"Maximum results per page" jsonschema:"minimum=1,maximum=100,default=50"`
+	Cursor       string `json:"cursor,omitempty" jsonschema_description:"Cursor of the page to return (next_cursor of the previous response)"`
 }
 
 type StockSearchResultSchema struct {
-	Symbol      string `json:"symbol" jsonschema_description:"Stock symbol"`
-	CompanyName string `json:"company_name" jsonschema_description:"Company name"`
+	Symbol      string  `json:"symbol" jsonschema_description:"Stock symbol"`
+	CompanyName string  `json:"company_name" jsonschema_description:"Company name"`
+	MarketCap   float64 `json:"market_cap" jsonschema_description:"Market capitalization"`
+	Score       float64 `json:"score" jsonschema_description:"Relevance score of the result (higher is better, 0 when there is no search string)"`
+	MatchReason string  `json:"match_reason,omitempty" jsonschema_description:"Why the result matched the search string" jsonschema:"enum=exact_symbol,enum=symbol_prefix,enum=name_token_prefix,enum=fuzzy,enum=contains"`
 }
 
 type StockSearchResultsResponse struct {
 	SearchResults []StockSearchResultSchema `json:"search_results" jsonschema_description:"Search results"`
+	TotalCount    int                       `json:"total_count" jsonschema_description:"Total number of results matching the search"`
+	NextCursor    string                    `json:"next_cursor,omitempty" jsonschema_description:"Cursor of the next page, empty when there are no more results"`
 }
 
 type TickerService interface {
-	GetTickers(filters services.TickerFilterOptions) ([]domain.Ticker, error)
+	GetTickers(filters services.TickerFilterOptions) (services.Page[search.Result[domain.Ticker]], error)
 }
 
 type StockSearchTool struct {
@@ -37,11 +44,9 @@ func NewStockSearchTool(tickerService TickerService) (*StockSearchTool, error) {
 }
 
 func (h *StockSearchTool) HandleSearchStocks(ctx context.Context, req mcp.CallToolRequest, args SearchStocksRequest) (StockSearchResultsResponse, error) {
-	if args.Limit == 0 {
-		args.Limit = 100
-	}
 	tickerFilters := services.TickerFilterOptions{
 		Limit:        args.Limit,
+		Cursor:       args.Cursor,
 		SearchString: args.SearchString,
 	}
 
@@ -51,15 +56,20 @@ func (h *StockSearchTool) HandleSearchStocks(ctx context.Context, req mcp.CallTo
 	}
 
 	response := StockSearchResultsResponse{
-		SearchResults: make([]StockSearchResultSchema, 0, len(tickers)),
+		SearchResults: make([]StockSearchResultSchema, 0, len(tickers.Items)),
+		TotalCount:    tickers.TotalCount,
+		NextCursor:    tickers.NextCursor,
 	}
 
-	for _, t := range tickers {
+	for _, t := range tickers.Items {
 		response.SearchResults = append(
 			response.SearchResults,
 			StockSearchResultSchema{
-				Symbol:      t.Symbol,
-				CompanyName: t.CompanyName,
+				Symbol:      t.Item.Symbol,
+				CompanyName: t.Item.CompanyName,
+				MarketCap:   t.Item.MarketCap,
+				Score:       t.Score,
+				MatchReason: string(t.Reason),
 			},
 		)
 	}
@@ -69,7 +79,7 @@ func (h *StockSearchTool) HandleSearchStocks(ctx context.Context, req mcp.CallTo
 
 func (h *StockSearchTool) GetTool() mcp.Tool {
 	return mcp.NewTool("stockSearch",
-		mcp.WithDescription("Search for a stock using the symbol or the company name"),
+		mcp.WithDescription("Search for a stock using the symbol or the company name. Results are ranked by relevance (exact symbol, symbol prefix, company name words, typos) and market cap and are paginated, use the next_cursor of the response to get the next page"),
 		mcp.WithInputSchema[SearchStocksRequest](),
 		mcp.WithOutputSchema[StockSearchResultsResponse](),
 	)
@@ -0,0 +1,344 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type TransactionLedgerService interface {
+	GetTransactions(userID string) ([]domain.Transaction, error)
+	AddTransaction(transaction domain.Transaction) (domain.Transaction, error)
+	DeleteTransaction(userID string, transactionID int) (domain.Transaction, error)
+}
+
+type PortfolioPerformanceService interface {
+	GetPortfolioPerformance(userID string, baseCurrency domain.Currency, method domain.CostBasisMethod) (domain.PortfolioPerformance, error)
+}
+
+type TransactionSchema struct {
+	TransactionID int     `json:"transaction_id"`
+	Type          string  `json:"type" jsonschema:"enum=buy,enum=sell,enum=dividend,enum=split,enum=deposit,enum=withdrawal,enum=fee"`
+	Date          string  `json:"date" jsonschema_description:"Date of the transaction, YYYY-MM-DD format"`
+	AssetClass    string  `json:"asset_class,omitempty"`
+	Symbol        string  `json:"symbol,omitempty"`
+	Quantity      float64 `json:"quantity,omitempty"`
+	Price         float64 `json:"price,omitempty"`
+	Amount        float64 `json:"amount,omitempty"`
+	Fees          float64 `json:"fees,omitempty"`
+	SplitRatio    float64 `json:"split_ratio,omitempty"`
+	Currency      string  `json:"currency"`
+	Notes         string  `json:"notes,omitempty"`
+}
+
+func newTransactionSchema(t domain.Transaction) TransactionSchema {
+	return TransactionSchema{
+		TransactionID: t.ID,
+		Type:          string(t.Type),
+		Date:          t.Date.Format(time.DateOnly),
+		AssetClass:    string(t.AssetClass),
+		Symbol:        t.Symbol,
+		Quantity:      t.Quantity,
+		Price:         t.Price,
+		Amount:        t.Amount,
+		Fees:          t.Fees,
+		SplitRatio:    t.SplitRatio,
+		Currency:      string(t.Currency),
+		Notes:         t.Notes,
+	}
+}
+
+type RecordTransactionRequest struct {
+	UserID     string  `json:"user_id" jsonschema_description:"The id of the user"`
+	Type       string  `json:"type" jsonschema_description:"Type of the transaction" jsonschema:"enum=buy,enum=sell,enum=dividend,enum=split,enum=deposit,enum=withdrawal,enum=fee"`
+	Date       string  `json:"date" jsonschema_description:"Date of the transaction, YYYY-MM-DD format"`
+	AssetClass string  `json:"asset_class,omitempty" jsonschema_description:"Asset class of a buy or a sell" jsonschema:"enum=stock,enum=etf,enum=crypto,default=stock"`
+	Symbol     string  `json:"symbol,omitempty" jsonschema_description:"Symbol of the buy, sell, dividend or split (optional for a fee)"`
+	Quantity   float64 `json:"quantity,omitempty" jsonschema_description:"Quantity bought or sold"`
+	Price      float64 `json:"price,omitempty" jsonschema_description:"Price per unit of a buy or a sell"`
+	Amount     float64 `json:"amount,omitempty" jsonschema_description:"Amount of a dividend, deposit, withdrawal or fee"`
+	Fees       float64 `json:"fees,omitempty" jsonschema_description:"Fees of a buy or a sell"`
+	SplitRatio float64 `json:"split_ratio,omitempty" jsonschema_description:"New shares per old share of a split, e.g. 4 for a 4-for-1 split, 0.1 for a 1-for-10 reverse split"`
+	Currency   string  `json:"currency,omitempty" jsonschema_description:"Currency of the price, the amount and the fees" jsonschema:"enum=AED,enum=USD,enum=EUR,enum=GBP,enum=JPY,enum=CHF,enum=CAD,enum=AUD,default=USD"`
+	Notes      string  `json:"notes,omitempty" jsonschema_description:"Free-form notes"`
+}
+
+type RecordTransactionTool struct {
+	transactionLedgerService TransactionLedgerService
+}
+
+func NewRecordTransactionTool(transactionLedgerService TransactionLedgerService) (*RecordTransactionTool, error) {
+	return &RecordTransactionTool{
+		transactionLedgerService: transactionLedgerService,
+	}, nil
+}
+
+func (t *RecordTransactionTool) HandleRecordTransaction(ctx context.Context, req mcp.CallToolRequest, args RecordTransactionRequest) (TransactionSchema, error) {
+	if args.UserID == "" {
+		return TransactionSchema{}, fmt.Errorf("user_id is required")
+	}
+	date, err := time.Parse(time.DateOnly, args.Date)
+	if err != nil {
+		return TransactionSchema{}, fmt.Errorf("date must be in YYYY-MM-DD format")
+	}
+
+	transaction, err := t.transactionLedgerService.AddTransaction(domain.Transaction{
+		UserID:     args.UserID,
+		Type:       domain.TransactionType(args.Type),
+		Date:       date,
+		AssetClass: domain.AssetClass(args.AssetClass),
+		Symbol:     args.Symbol,
+		Quantity:   args.Quantity,
+		Price:      args.Price,
+		Amount:     args.Amount,
+		Fees:       args.Fees,
+		SplitRatio: args.SplitRatio,
+		Currency:   domain.Currency(strings.ToUpper(args.Currency)),
+		Notes:      args.Notes,
+	})
+	if err != nil {
+		return TransactionSchema{}, err
+	}
+
+	return newTransactionSchema(transaction), nil
+}
+
+func (t *RecordTransactionTool) GetTool() mcp.Tool {
+	return mcp.NewTool("recordTransaction",
+		mcp.WithDescription("Record a transaction in the ledger of the user: a buy, a sell, a dividend, a split, a deposit, a withdrawal or a fee. The ledger is the source of the cost basis and the profit and loss reported by getPortfolioPerformance. A transaction that makes the ledger inconsistent (e.g. selling more than held) is rejected."),
+		mcp.WithInputSchema[RecordTransactionRequest](),
+		mcp.WithOutputSchema[TransactionSchema](),
+	)
+}
+
+type DeleteTransactionRequest struct {
+	UserID        string `json:"user_id" jsonschema_description:"The id of the user"`
+	TransactionID int    `json:"transaction_id" jsonschema_description:"Id of the transaction to delete, from listTransactions"`
+}
+
+type DeleteTransactionTool struct {
+	transactionLedgerService TransactionLedgerService
+}
+
+func NewDeleteTransactionTool(transactionLedgerService TransactionLedgerService) (*DeleteTransactionTool, error) {
+	return &DeleteTransactionTool{
+		transactionLedgerService: transactionLedgerService,
+	}, nil
+}
+
+func (t *DeleteTransactionTool) HandleDeleteTransaction(ctx context.Context, req mcp.CallToolRequest, args DeleteTransactionRequest) (TransactionSchema, error) {
+	if args.UserID == "" {
+		return TransactionSchema{}, fmt.Errorf("user_id is required")
+	}
+	if args.TransactionID <= 0 {
+		return TransactionSchema{}, fmt.Errorf("transaction_id is required")
+	}
+
+	transaction, err := t.transactionLedgerService.DeleteTransaction(args.UserID, args.TransactionID)
+	if err != nil {
+		return TransactionSchema{}, err
+	}
+
+	return newTransactionSchema(transaction), nil
+}
+
+func (t *DeleteTransactionTool) GetTool() mcp.Tool {
+	return mcp.NewTool("deleteTransaction",
+		mcp.WithDescription("Delete a transaction from the ledger of the user, e.g. one recorded by mistake. Returns the deleted transaction."),
+		mcp.WithInputSchema[DeleteTransactionRequest](),
+		mcp.WithOutputSchema[TransactionSchema](),
+	)
+}
+
+type ListTransactionsRequest struct {
+	UserID string `json:"user_id" jsonschema_description:"The id of the user"`
+	Symbol string `json:"symbol,omitempty" jsonschema_description:"Only the transactions of the symbol"`
+}
+
+type ListTransactionsResponse struct {
+	UserID       string              `json:"user_id"`
+	Transactions []TransactionSchema `json:"transactions" jsonschema_description:"Transactions in the order they apply, the oldest first"`
+}
+
+type ListTransactionsTool struct {
+	transactionLedgerService TransactionLedgerService
+}
+
+func NewListTransactionsTool(transactionLedgerService TransactionLedgerService) (*ListTransactionsTool, error) {
+	return &ListTransactionsTool{
+		transactionLedgerService: transactionLedgerService,
+	}, nil
+}
+
+func (t *ListTransactionsTool) HandleListTransactions(ctx context.Context, req mcp.CallToolRequest, args ListTransactionsRequest) (ListTransactionsResponse, error) {
+	if args.UserID == "" {
+		return ListTransactionsResponse{}, fmt.Errorf("user_id is required")
+	}
+
+	transactions, err := t.transactionLedgerService.GetTransactions(args.UserID)
+	if err != nil {
+		return ListTransactionsResponse{}, err
+	}
+
+	schemas := make([]TransactionSchema, 0, len(transactions))
+	for _, transaction := range transactions {
+		if args.Symbol != "" && !strings.EqualFold(transaction.Symbol, args.Symbol) {
+			continue
+		}
+		schemas = append(schemas, newTransactionSchema(transaction))
+	}
+
+	return ListTransactionsResponse{UserID: args.UserID, Transactions: schemas}, nil
+}
+
+func (t *ListTransactionsTool) GetTool() mcp.Tool {
+	return mcp.NewTool("listTransactions",
+		mcp.WithDescription("List the transactions of the ledger of the user"),
+		mcp.WithInputSchema[ListTransactionsRequest](),
+		mcp.WithOutputSchema[ListTransactionsResponse](),
+	)
+}
+
+type GetPortfolioPerformanceRequest struct {
+	UserID          string `json:"user_id" jsonschema_description:"The id of the user"`
+	BaseCurrency    string `json:"base_currency,omitempty" jsonschema_description:"Currency code of the currency the amounts are reported in" jsonschema:"enum=AED,enum=USD,enum=EUR,enum=GBP,enum=JPY,enum=CHF,enum=CAD,enum=AUD,default=USD"`
+	CostBasisMethod string `json:"cost_basis_method,omitempty" jsonschema_description:"How the sold quantities are matched with the bought lots: first in first out, or at the average cost" jsonschema:"enum=fifo,enum=average,default=fifo"`
+	IncludeLots     bool   `json:"include_lots,omitempty" jsonschema_description:"Whether to return the lots of the open positions"`
+}
+
+type LotSchema struct {
+	Date     string  `json:"date" jsonschema_description:"Date of the buy, YYYY-MM-DD format (the first buy with the average cost method)"`
+	Quantity float64 `json:"quantity"`
+	UnitCost float64 `json:"unit_cost" jsonschema_description:"Cost per unit including the fees, in the base currency"`
+}
+
+type PositionSchema struct {
+	AssetClass    string      `json:"asset_class"`
+	Symbol        string      `json:"symbol"`
+	Quantity      float64     `json:"quantity" jsonschema_description:"Quantity held, 0 for a closed position"`
+	CostBasis     float64     `json:"cost_basis" jsonschema_description:"Cost of the quantity held including the fees"`
+	AverageCost   float64     `json:"average_cost" jsonschema_description:"Cost basis per unit, 0 for a closed position"`
+	Price         float64     `json:"price" jsonschema_description:"Current price in the base currency, 0 when it couldn't be priced"`
+	MarketValue   float64     `json:"market_value"`
+	UnrealizedPnL float64     `json:"unrealized_pnl" jsonschema_description:"Market value minus cost basis"`
+	RealizedPnL   float64     `json:"realized_pnl" jsonschema_description:"Proceeds of the sales minus the cost of the sold lots"`
+	Dividends     float64     `json:"dividends"`
+	Fees          float64     `json:"fees" jsonschema_description:"Fees attributed to the position besides the ones of the buys and sells"`
+	PriceError    string      `json:"price_error,omitempty" jsonschema_description:"Why the position couldn't be priced"`
+	Lots          []LotSchema `json:"lots,omitempty"`
+}
+
+type GetPortfolioPerformanceResponse struct {
+	UserID                       string           `json:"user_id"`
+	BaseCurrency                 string           `json:"base_currency"`
+	CostBasisMethod              string           `json:"cost_basis_method"`
+	Positions                    []PositionSchema `json:"positions" jsonschema_description:"Positions derived from the ledger, the largest first"`
+	Cash                         float64          `json:"cash" jsonschema_description:"Cash of the ledger: deposits, sale proceeds and dividends not reinvested"`
+	MarketValue                  float64          `json:"market_value" jsonschema_description:"Market value of the positions and the cash"`
+	NetDeposits                  float64          `json:"net_deposits" jsonschema_description:"Deposits minus withdrawals. The buys that the cash doesn't cover count as deposits."`
+	RealizedPnL                  float64          `json:"realized_pnl"`
+	UnrealizedPnL                float64          `json:"unrealized_pnl"`
+	Dividends                    float64          `json:"dividends"`
+	Fees                         float64          `json:"fees"`
+	TimeWeightedReturn           *float64         `json:"time_weighted_return,omitempty" jsonschema_description:"Return of the portfolio independent of the timing of the cash flows, 20 means 20%"`
+	AnnualizedTimeWeightedReturn *float64         `json:"annualized_time_weighted_return,omitempty" jsonschema_description:"Annualized time-weighted return, only when the ledger covers a year or more"`
+	MoneyWeightedReturn          *float64         `json:"money_weighted_return,omitempty" jsonschema_description:"Annualized internal rate of return of the cash flows of the investor, 20 means 20%"`
+	ReturnsError                 string           `json:"returns_error,omitempty" jsonschema_description:"Why the returns couldn't be computed"`
+	FirstTransactionDate         string           `json:"first_transaction_date" jsonschema_description:"YYYY-MM-DD format"`
+	ValuedAt                     string           `json:"valued_at" jsonschema_description:"ISO 8601 format"`
+	Note                         string           `json:"note"`
+}
+
+type GetPortfolioPerformanceTool struct {
+	portfolioPerformanceService PortfolioPerformanceService
+}
+
+func NewGetPortfolioPerformanceTool(portfolioPerformanceService PortfolioPerformanceService) (*GetPortfolioPerformanceTool, error) {
+	return &GetPortfolioPerformanceTool{
+		portfolioPerformanceService: portfolioPerformanceService,
+	}, nil
+}
+
+func (t *GetPortfolioPerformanceTool) HandleGetPortfolioPerformance(ctx context.Context, req mcp.CallToolRequest, args GetPortfolioPerformanceRequest) (GetPortfolioPerformanceResponse, error) {
+	if args.UserID == "" {
+		return GetPortfolioPerformanceResponse{}, fmt.Errorf("user_id is required")
+	}
+	baseCurrency := domain.USD
+	if args.BaseCurrency != "" {
+		baseCurrency = domain.Currency(strings.ToUpper(args.BaseCurrency))
+	}
+	if _, ok := domain.CurrencyCodeToNameMap[baseCurrency]; !ok {
+		return GetPortfolioPerformanceResponse{}, fmt.Errorf("invalid base_currency: %s", args.BaseCurrency)
+	}
+	method := domain.CostBasisFIFO
+	if args.CostBasisMethod != "" {
+		method = domain.CostBasisMethod(args.CostBasisMethod)
+	}
+	if method != domain.CostBasisFIFO && method != domain.CostBasisAverage {
+		return GetPortfolioPerformanceResponse{}, fmt.Errorf("cost_basis_method valid values are: fifo, average")
+	}
+
+	performance, err := t.portfolioPerformanceService.GetPortfolioPerformance(args.UserID, baseCurrency, method)
+	if err != nil {
+		return GetPortfolioPerformanceResponse{}, err
+	}
+
+	positions := make([]PositionSchema, 0, len(performance.Positions))
+	for _, p := range performance.Positions {
+		position := PositionSchema{
+			AssetClass:    string(p.AssetClass),
+			Symbol:        p.Symbol,
+			Quantity:      p.Quantity,
+			CostBasis:     p.CostBasis,
+			Price:         p.Price,
+			MarketValue:   p.MarketValue,
+			UnrealizedPnL: p.UnrealizedPnL,
+			RealizedPnL:   p.RealizedPnL,
+			Dividends:     p.Dividends,
+			Fees:          p.Fees,
+			PriceError:    p.PriceError,
+		}
+		if p.Quantity > 0 {
+			position.AverageCost = p.CostBasis / p.Quantity
+		}
+		if args.IncludeLots {
+			for _, lot := range p.Lots {
+				position.Lots = append(position.Lots, LotSchema{Date: lot.Date.Format(time.DateOnly), Quantity: lot.Quantity, UnitCost: lot.UnitCost})
+			}
+		}
+		positions = append(positions, position)
+	}
+
+	return GetPortfolioPerformanceResponse{
+		UserID:                       performance.UserID,
+		BaseCurrency:                 string(performance.BaseCurrency),
+		CostBasisMethod:              string(performance.CostBasisMethod),
+		Positions:                    positions,
+		Cash:                         performance.Cash,
+		MarketValue:                  performance.MarketValue,
+		NetDeposits:                  performance.NetDeposits,
+		RealizedPnL:                  performance.RealizedPnL,
+		UnrealizedPnL:                performance.UnrealizedPnL,
+		Dividends:                    performance.Dividends,
+		Fees:                         performance.Fees,
+		TimeWeightedReturn:           performance.TimeWeightedReturn,
+		AnnualizedTimeWeightedReturn: performance.AnnualizedTimeWeightedReturn,
+		MoneyWeightedReturn:          performance.MoneyWeightedReturn,
+		ReturnsError:                 performance.ReturnsError,
+		FirstTransactionDate:         performance.FirstTransactionDate.Format(time.DateOnly),
+		ValuedAt:                     performance.ValuedAt.Format(time.RFC3339),
+		Note:                         "The amounts in other currencies are converted at the current exchange rates",
+	}, nil
+}
+
+func (t *GetPortfolioPerformanceTool) GetTool() mcp.Tool {
+	return mcp.NewTool("getPortfolioPerformance",
+		mcp.WithDescription("Get the positions derived from the transaction ledger of the user with their cost basis (FIFO or average cost), the realized and unrealized profit and loss, the dividends and fees, and the time-weighted and money-weighted returns of the portfolio"),
+		mcp.WithInputSchema[GetPortfolioPerformanceRequest](),
+		mcp.WithOutputSchema[GetPortfolioPerformanceResponse](),
+	)
+}
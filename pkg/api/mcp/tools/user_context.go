@@ -12,6 +12,14 @@ type UserContextService interface {
 	GetUserContext(userID string) (domain.UserContext, error)
 	CreateUserContext(domain.UserContext) error
 	UpdateUserContext(domain.UserContext) error
+	ReplaceUserContext(userContext domain.UserContext, expectedUpdatedAt string) (domain.UserContext, error)
+	UpsertPortfolioHolding(userID string, expectedUpdatedAt string, holding domain.UserPortfolioHolding) (domain.UserContext, error)
+	RemovePortfolioHolding(userID string, expectedUpdatedAt string, symbol string) (domain.UserContext, error)
+	AdjustPortfolioHoldingQuantity(userID string, expectedUpdatedAt string, symbol string, delta float64) (domain.UserContext, error)
+	PatchUserProfile(userID string, expectedUpdatedAt string, patch map[string]any) (domain.UserContext, error)
+	ClearUserPortfolio(userID string, expectedUpdatedAt string) (domain.UserContext, error)
+	GetUserContextHistory(userID string, limit int) ([]domain.UserContextChange, error)
+	RevertUserContextChange(userID string, expectedUpdatedAt string, changeID int) (domain.UserContext, error)
 }
 
 type GetUserContextRequest struct {
@@ -49,12 +57,43 @@ func (u UserPortfolioHoldingSchema) Validate() error {
 	return nil
 }
 
+func (u UserPortfolioHoldingSchema) toDomain() domain.UserPortfolioHolding {
+	return domain.UserPortfolioHolding{
+		AssetClass:          domain.AssetClass(u.AssetClass),
+		Symbol:              u.Symbol,
+		Name:                u.Name,
+		Quantity:            u.Quantity,
+		PortfolioPercentage: u.PortfolioPercentage,
+	}
+}
+
 type UserContextResponse struct {
 	UserID        string                       `json:"user_id"`
 	UserProfile   map[string]any               `json:"user_profile" jsonschema_description:"General information about the user"`
 	UserPortfolio []UserPortfolioHoldingSchema `json:"user_portfolio"`
 	CreatedAt     string                       `json:"created_at" jsonschema_description:"When the user context was created, ISO 8601 format"`
-	UpdatedAt     string                       `json:"updated_at" jsonschema_description:"When the user context was last updated, ISO 8601 format"`
+	UpdatedAt     string                       `json:"updated_at" jsonschema_description:"When the user context was last updated, ISO 8601 format. Pass it as expected_updated_at to change the context."`
+}
+
+func newUserContextResponse(userContext domain.UserContext) UserContextResponse {
+	portfolio := make([]UserPortfolioHoldingSchema, 0, len(userContext.UserPortfolio))
+	for _, holding := range userContext.UserPortfolio {
+		portfolio = append(portfolio, UserPortfolioHoldingSchema{
+			AssetClass:          string(holding.AssetClass),
+			Symbol:              holding.Symbol,
+			Name:                holding.Name,
+			Quantity:            holding.Quantity,
+			PortfolioPercentage: holding.PortfolioPercentage,
+		})
+	}
+
+	return UserContextResponse{
+		UserID:        userContext.UserID,
+		UserProfile:   userContext.UserProfile,
+		UserPortfolio: portfolio,
+		CreatedAt:     userContext.CreatedAt,
+		UpdatedAt:     userContext.UpdatedAt,
+	}
 }
 
 type GetUserContextTool struct {
@@ -77,26 +116,7 @@ func (t *GetUserContextTool) HandleGetUserContext(ctx context.Context, req mcp.C
 		return UserContextResponse{}, err
 	}
 
-	portfolio := make([]UserPortfolioHoldingSchema, 0, len(userContext.UserPortfolio))
-	for _, holding := range userContext.UserPortfolio {
-		portfolio = append(portfolio, UserPortfolioHoldingSchema{
-			AssetClass:          string(holding.AssetClass),
-			Symbol:              holding.Symbol,
-			Name:                holding.Name,
-			Quantity:            holding.Quantity,
-			PortfolioPercentage: holding.PortfolioPercentage,
-		})
-	}
-
-	response := UserContextResponse{
-		UserID:        userContext.UserID,
-		UserProfile:   userContext.UserProfile,
-		UserPortfolio: portfolio,
-		CreatedAt:     userContext.CreatedAt,
-		UpdatedAt:     userContext.UpdatedAt,
-	}
-
-	return response, nil
+	return newUserContextResponse(userContext), nil
 }
 
 func (t *GetUserContextTool) GetTool() mcp.Tool {
@@ -108,9 +128,10 @@ func (t *GetUserContextTool) GetTool() mcp.Tool {
 }
 
 type UpdateUserContextRequest struct {
-	UserID        string                       `json:"user_id" jsonschema_description:"The id of the user to update the context for"`
-	UserProfile   map[string]any               `json:"user_profile" jsonschema_description:"General information about the user. Must provide the complete user profile as it will replace the existing one."`
-	UserPortfolio []UserPortfolioHoldingSchema `json:"user_portfolio" jsonschema_description:"List of portfolio holdings. Must provide the complete portfolio as it will replace the existing one."`
+	UserID            string                       `json:"user_id" jsonschema_description:"The id of the user to update the context for"`
+	UserProfile       map[string]any               `json:"user_profile" jsonschema_description:"General information about the user. Must provide the complete user profile as it will replace the existing one."`
+	UserPortfolio     []UserPortfolioHoldingSchema `json:"user_portfolio" jsonschema_description:"List of portfolio holdings. Must provide the complete portfolio as it will replace the existing one."`
+	ExpectedUpdatedAt string                       `json:"expected_updated_at,omitempty" jsonschema_description:"The updated_at of the user context the update is based on, the update fails if the context was changed since. Not checked when omitted."`
 }
 
 type UpdateUserContextTool struct {
@@ -138,16 +159,7 @@ func (t *UpdateUserContextTool) HandleUpdateUserContext(ctx context.Context, req
 	// Convert request to domain.UserContext
 	portfolioHoldings := make([]domain.UserPortfolioHolding, 0, len(args.UserPortfolio))
 	for _, h := range args.UserPortfolio {
-		portfolioHoldings = append(
-			portfolioHoldings,
-			domain.UserPortfolioHolding{
-				AssetClass:          domain.AssetClass(h.AssetClass),
-				Symbol:              h.Symbol,
-				Name:                h.Name,
-				Quantity:            h.Quantity,
-				PortfolioPercentage: h.PortfolioPercentage,
-			},
-		)
+		portfolioHoldings = append(portfolioHoldings, h.toDomain())
 	}
 
 	userContext := domain.UserContext{
@@ -156,44 +168,322 @@ func (t *UpdateUserContextTool) HandleUpdateUserContext(ctx context.Context, req
 		UserPortfolio: portfolioHoldings,
 	}
 
-	err := t.userContextService.UpdateUserContext(userContext)
+	updatedUserContext, err := t.userContextService.ReplaceUserContext(userContext, args.ExpectedUpdatedAt)
 	if err != nil {
 		return UserContextResponse{}, err
 	}
 
-	// Fetch the updated user context to return what's actually stored
-	updatedUserContext, err := t.userContextService.GetUserContext(args.UserID)
+	return newUserContextResponse(updatedUserContext), nil
+}
+
+func (t *UpdateUserContextTool) GetTool() mcp.Tool {
+	return mcp.NewTool("updateUserContext",
+		mcp.WithDescription("Update the user context including user profile and portfolio holdings. Note: The provided context will completely replace the existing one, so the entire updated object must be provided. Prefer the incremental tools (upsertPortfolioHolding, removePortfolioHolding, adjustHoldingQuantity, patchUserProfile, clearUserPortfolio) to change a part of the context."),
+		mcp.WithInputSchema[UpdateUserContextRequest](),
+		mcp.WithOutputSchema[UserContextResponse](),
+	)
+}
+
+type UpsertPortfolioHoldingRequest struct {
+	UserID            string                     `json:"user_id" jsonschema_description:"The id of the user"`
+	ExpectedUpdatedAt string                     `json:"expected_updated_at" jsonschema_description:"The updated_at of the user context the change is based on (from getUserContext or the previous change), the change fails if the context was changed since. Empty string if the user has no context yet."`
+	Holding           UserPortfolioHoldingSchema `json:"holding" jsonschema_description:"The holding to add, it replaces the holding with the same symbol (the same name for the holdings without symbol)"`
+}
+
+type UpsertPortfolioHoldingTool struct {
+	userContextService UserContextService
+}
+
+func NewUpsertPortfolioHoldingTool(userContextService UserContextService) (*UpsertPortfolioHoldingTool, error) {
+	return &UpsertPortfolioHoldingTool{
+		userContextService: userContextService,
+	}, nil
+}
+
+func (t *UpsertPortfolioHoldingTool) HandleUpsertPortfolioHolding(ctx context.Context, req mcp.CallToolRequest, args UpsertPortfolioHoldingRequest) (UserContextResponse, error) {
+	if args.UserID == "" {
+		return UserContextResponse{}, fmt.Errorf("user_id is required")
+	}
+	if err := args.Holding.Validate(); err != nil {
+		return UserContextResponse{}, err
+	}
+
+	userContext, err := t.userContextService.UpsertPortfolioHolding(args.UserID, args.ExpectedUpdatedAt, args.Holding.toDomain())
 	if err != nil {
 		return UserContextResponse{}, err
 	}
 
-	// Return the updated user context
-	portfolio := make([]UserPortfolioHoldingSchema, 0, len(updatedUserContext.UserPortfolio))
-	for _, holding := range updatedUserContext.UserPortfolio {
-		portfolio = append(portfolio, UserPortfolioHoldingSchema{
-			AssetClass:          string(holding.AssetClass),
-			Symbol:              holding.Symbol,
-			Name:                holding.Name,
-			Quantity:            holding.Quantity,
-			PortfolioPercentage: holding.PortfolioPercentage,
+	return newUserContextResponse(userContext), nil
+}
+
+func (t *UpsertPortfolioHoldingTool) GetTool() mcp.Tool {
+	return mcp.NewTool("upsertPortfolioHolding",
+		mcp.WithDescription("Add a holding to the user portfolio, or replace the holding with the same symbol. The other holdings and the profile are kept. Creates the user context if it doesn't exist."),
+		mcp.WithInputSchema[UpsertPortfolioHoldingRequest](),
+		mcp.WithOutputSchema[UserContextResponse](),
+	)
+}
+
+type RemovePortfolioHoldingRequest struct {
+	UserID            string `json:"user_id" jsonschema_description:"The id of the user"`
+	ExpectedUpdatedAt string `json:"expected_updated_at" jsonschema_description:"The updated_at of the user context the change is based on (from getUserContext or the previous change), the change fails if the context was changed since"`
+	Symbol            string `json:"symbol" jsonschema_description:"Symbol of the holding to remove (its name for the holdings without symbol)"`
+}
+
+type RemovePortfolioHoldingTool struct {
+	userContextService UserContextService
+}
+
+func NewRemovePortfolioHoldingTool(userContextService UserContextService) (*RemovePortfolioHoldingTool, error) {
+	return &RemovePortfolioHoldingTool{
+		userContextService: userContextService,
+	}, nil
+}
+
+func (t *RemovePortfolioHoldingTool) HandleRemovePortfolioHolding(ctx context.Context, req mcp.CallToolRequest, args RemovePortfolioHoldingRequest) (UserContextResponse, error) {
+	if args.UserID == "" {
+		return UserContextResponse{}, fmt.Errorf("user_id is required")
+	}
+	if args.Symbol == "" {
+		return UserContextResponse{}, fmt.Errorf("symbol is required")
+	}
+
+	userContext, err := t.userContextService.RemovePortfolioHolding(args.UserID, args.ExpectedUpdatedAt, args.Symbol)
+	if err != nil {
+		return UserContextResponse{}, err
+	}
+
+	return newUserContextResponse(userContext), nil
+}
+
+func (t *RemovePortfolioHoldingTool) GetTool() mcp.Tool {
+	return mcp.NewTool("removePortfolioHolding",
+		mcp.WithDescription("Remove a holding from the user portfolio, the other holdings and the profile are kept"),
+		mcp.WithInputSchema[RemovePortfolioHoldingRequest](),
+		mcp.WithOutputSchema[UserContextResponse](),
+	)
+}
+
+type AdjustHoldingQuantityRequest struct {
+	UserID            string  `json:"user_id" jsonschema_description:"The id of the user"`
+	ExpectedUpdatedAt string  `json:"expected_updated_at" jsonschema_description:"The updated_at of the user context the change is based on (from getUserContext or the previous change), the change fails if the context was changed since"`
+	Symbol            string  `json:"symbol" jsonschema_description:"Symbol of the holding (its name for the holdings without symbol)"`
+	QuantityDelta     float64 `json:"quantity_delta" jsonschema_description:"Quantity to add to the holding, negative to remove (e.g. 10 after a buy of 10 shares, -5 after a sale of 5 shares). The holding is removed when its quantity drops to zero."`
+}
+
+type AdjustHoldingQuantityTool struct {
+	userContextService UserContextService
+}
+
+func NewAdjustHoldingQuantityTool(userContextService UserContextService) (*AdjustHoldingQuantityTool, error) {
+	return &AdjustHoldingQuantityTool{
+		userContextService: userContextService,
+	}, nil
+}
+
+func (t *AdjustHoldingQuantityTool) HandleAdjustHoldingQuantity(ctx context.Context, req mcp.CallToolRequest, args AdjustHoldingQuantityRequest) (UserContextResponse, error) {
+	if args.UserID == "" {
+		return UserContextResponse{}, fmt.Errorf("user_id is required")
+	}
+	if args.Symbol == "" {
+		return UserContextResponse{}, fmt.Errorf("symbol is required")
+	}
+	if args.QuantityDelta == 0 {
+		return UserContextResponse{}, fmt.Errorf("quantity_delta must not be zero")
+	}
+
+	userContext, err := t.userContextService.AdjustPortfolioHoldingQuantity(args.UserID, args.ExpectedUpdatedAt, args.Symbol, args.QuantityDelta)
+	if err != nil {
+		return UserContextResponse{}, err
+	}
+
+	return newUserContextResponse(userContext), nil
+}
+
+func (t *AdjustHoldingQuantityTool) GetTool() mcp.Tool {
+	return mcp.NewTool("adjustHoldingQuantity",
+		mcp.WithDescription("Add to or remove from the quantity of a holding of the user portfolio, e.g. after a buy or a sale"),
+		mcp.WithInputSchema[AdjustHoldingQuantityRequest](),
+		mcp.WithOutputSchema[UserContextResponse](),
+	)
+}
+
+type PatchUserProfileRequest struct {
+	UserID            string         `json:"user_id" jsonschema_description:"The id of the user"`
+	ExpectedUpdatedAt string         `json:"expected_updated_at" jsonschema_description:"The updated_at of the user context the change is based on (from getUserContext or the previous change), the change fails if the context was changed since. Empty string if the user has no context yet."`
+	Patch             map[string]any `json:"patch" jsonschema_description:"JSON merge patch of the user profile: the given keys are set, the keys set to null are removed, nested objects are merged and the keys not given are kept"`
+}
+
+type PatchUserProfileTool struct {
+	userContextService UserContextService
+}
+
+func NewPatchUserProfileTool(userContextService UserContextService) (*PatchUserProfileTool, error) {
+	return &PatchUserProfileTool{
+		userContextService: userContextService,
+	}, nil
+}
+
+func (t *PatchUserProfileTool) HandlePatchUserProfile(ctx context.Context, req mcp.CallToolRequest, args PatchUserProfileRequest) (UserContextResponse, error) {
+	if args.UserID == "" {
+		return UserContextResponse{}, fmt.Errorf("user_id is required")
+	}
+	if len(args.Patch) == 0 {
+		return UserContextResponse{}, fmt.Errorf("patch is required")
+	}
+
+	userContext, err := t.userContextService.PatchUserProfile(args.UserID, args.ExpectedUpdatedAt, args.Patch)
+	if err != nil {
+		return UserContextResponse{}, err
+	}
+
+	return newUserContextResponse(userContext), nil
+}
+
+func (t *PatchUserProfileTool) GetTool() mcp.Tool {
+	return mcp.NewTool("patchUserProfile",
+		mcp.WithDescription("Set or unset keys of the user profile without resending the whole profile, e.g. {\"risk_tolerance\": \"high\", \"age\": null}. Creates the user context if it doesn't exist."),
+		mcp.WithInputSchema[PatchUserProfileRequest](),
+		mcp.WithOutputSchema[UserContextResponse](),
+	)
+}
+
+type ClearUserPortfolioRequest struct {
+	UserID            string `json:"user_id" jsonschema_description:"The id of the user"`
+	ExpectedUpdatedAt string `json:"expected_updated_at" jsonschema_description:"The updated_at of the user context the change is based on (from getUserContext or the previous change), the change fails if the context was changed since"`
+}
+
+type ClearUserPortfolioTool struct {
+	userContextService UserContextService
+}
+
+func NewClearUserPortfolioTool(userContextService UserContextService) (*ClearUserPortfolioTool, error) {
+	return &ClearUserPortfolioTool{
+		userContextService: userContextService,
+	}, nil
+}
+
+func (t *ClearUserPortfolioTool) HandleClearUserPortfolio(ctx context.Context, req mcp.CallToolRequest, args ClearUserPortfolioRequest) (UserContextResponse, error) {
+	if args.UserID == "" {
+		return UserContextResponse{}, fmt.Errorf("user_id is required")
+	}
+
+	userContext, err := t.userContextService.ClearUserPortfolio(args.UserID, args.ExpectedUpdatedAt)
+	if err != nil {
+		return UserContextResponse{}, err
+	}
+
+	return newUserContextResponse(userContext), nil
+}
+
+func (t *ClearUserPortfolioTool) GetTool() mcp.Tool {
+	return mcp.NewTool("clearUserPortfolio",
+		mcp.WithDescription("Remove all the holdings of the user portfolio, the profile is kept"),
+		mcp.WithInputSchema[ClearUserPortfolioRequest](),
+		mcp.WithOutputSchema[UserContextResponse](),
+	)
+}
+
+type GetUserContextHistoryRequest struct {
+	UserID string `json:"user_id" jsonschema_description:"The id of the user"`
+	Limit  int    `json:"limit,omitempty" jsonschema_description:"Maximum number of changes to return, the most recent first" jsonschema:"minimum=1,maximum=100,default=20"`
+}
+
+type UserContextChangeSchema struct {
+	ID        int    `json:"id" jsonschema_description:"Id of the change, to revert it with revertUserContextChange"`
+	Operation string `json:"operation" jsonschema:"enum=create,enum=replace,enum=upsert_holding,enum=remove_holding,enum=adjust_quantity,enum=patch_profile,enum=clear_portfolio,enum=revert"`
+	Summary   string `json:"summary" jsonschema_description:"Description of the change"`
+	CreatedAt string `json:"created_at" jsonschema_description:"When the change was made, ISO 8601 format. It's the updated_at of the user context after the change."`
+}
+
+type GetUserContextHistoryResponse struct {
+	UserID  string                    `json:"user_id"`
+	Changes []UserContextChangeSchema `json:"changes" jsonschema_description:"Changes of the user context, the most recent first"`
+}
+
+type GetUserContextHistoryTool struct {
+	userContextService UserContextService
+}
+
+func NewGetUserContextHistoryTool(userContextService UserContextService) (*GetUserContextHistoryTool, error) {
+	return &GetUserContextHistoryTool{
+		userContextService: userContextService,
+	}, nil
+}
+
+func (t *GetUserContextHistoryTool) HandleGetUserContextHistory(ctx context.Context, req mcp.CallToolRequest, args GetUserContextHistoryRequest) (GetUserContextHistoryResponse, error) {
+	if args.UserID == "" {
+		return GetUserContextHistoryResponse{}, fmt.Errorf("user_id is required")
+	}
+	if args.Limit == 0 {
+		args.Limit = 20
+	}
+	if args.Limit < 1 || args.Limit > 100 {
+		return GetUserContextHistoryResponse{}, fmt.Errorf("limit must be between 1 and 100")
+	}
+
+	history, err := t.userContextService.GetUserContextHistory(args.UserID, args.Limit)
+	if err != nil {
+		return GetUserContextHistoryResponse{}, err
+	}
+
+	changes := make([]UserContextChangeSchema, 0, len(history))
+	for _, change := range history {
+		changes = append(changes, UserContextChangeSchema{
+			ID:        change.ID,
+			Operation: change.Operation,
+			Summary:   change.Summary,
+			CreatedAt: change.CreatedAt,
 		})
 	}
 
-	response := UserContextResponse{
-		UserID:        updatedUserContext.UserID,
-		UserProfile:   updatedUserContext.UserProfile,
-		UserPortfolio: portfolio,
-		CreatedAt:     updatedUserContext.CreatedAt,
-		UpdatedAt:     updatedUserContext.UpdatedAt,
+	return GetUserContextHistoryResponse{UserID: args.UserID, Changes: changes}, nil
+}
+
+func (t *GetUserContextHistoryTool) GetTool() mcp.Tool {
+	return mcp.NewTool("getUserContextHistory",
+		mcp.WithDescription("Get the history of the changes of the user context, to find a change to revert"),
+		mcp.WithInputSchema[GetUserContextHistoryRequest](),
+		mcp.WithOutputSchema[GetUserContextHistoryResponse](),
+	)
+}
+
+type RevertUserContextChangeRequest struct {
+	UserID            string `json:"user_id" jsonschema_description:"The id of the user"`
+	ExpectedUpdatedAt string `json:"expected_updated_at" jsonschema_description:"The updated_at of the user context the change is based on (from getUserContext or the previous change), the change fails if the context was changed since"`
+	ChangeID          int    `json:"change_id" jsonschema_description:"Id of the change to revert, from getUserContextHistory"`
+}
+
+type RevertUserContextChangeTool struct {
+	userContextService UserContextService
+}
+
+func NewRevertUserContextChangeTool(userContextService UserContextService) (*RevertUserContextChangeTool, error) {
+	return &RevertUserContextChangeTool{
+		userContextService: userContextService,
+	}, nil
+}
+
+func (t *RevertUserContextChangeTool) HandleRevertUserContextChange(ctx context.Context, req mcp.CallToolRequest, args RevertUserContextChangeRequest) (UserContextResponse, error) {
+	if args.UserID == "" {
+		return UserContextResponse{}, fmt.Errorf("user_id is required")
+	}
+	if args.ChangeID <= 0 {
+		return UserContextResponse{}, fmt.Errorf("change_id is required")
 	}
 
-	return response, nil
+	userContext, err := t.userContextService.RevertUserContextChange(args.UserID, args.ExpectedUpdatedAt, args.ChangeID)
+	if err != nil {
+		return UserContextResponse{}, err
+	}
+
+	return newUserContextResponse(userContext), nil
 }
 
-func (t *UpdateUserContextTool) GetTool() mcp.Tool {
-	return mcp.NewTool("updateUserContext",
-		mcp.WithDescription("Update the user context including user profile and portfolio holdings. Note: The provided context will completely replace the existing one, so the entire updated object must be provided."),
-		mcp.WithInputSchema[UpdateUserContextRequest](),
+func (t *RevertUserContextChangeTool) GetTool() mcp.Tool {
+	return mcp.NewTool("revertUserContextChange",
+		mcp.WithDescription("Restore the user context as it was before a change of its history, which also reverts the changes made after it. The revert is recorded in the history and can itself be reverted."),
+		mcp.WithInputSchema[RevertUserContextChangeRequest](),
 		mcp.WithOutputSchema[UserContextResponse](),
 	)
 }
@@ -0,0 +1,399 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type WatchlistService interface {
+	GetWatchlists(userID string) ([]domain.Watchlist, error)
+	CreateWatchlist(userID string, name string) (domain.Watchlist, error)
+	RenameWatchlist(userID string, name string, newName string) (domain.Watchlist, error)
+	DeleteWatchlist(userID string, name string) (domain.Watchlist, error)
+	AddWatchlistEntries(userID string, name string, entries []domain.WatchlistEntry) (domain.Watchlist, error)
+	RemoveWatchlistEntries(userID string, name string, symbols []string) (domain.Watchlist, error)
+}
+
+type WatchlistSnapshotService interface {
+	GetWatchlistSnapshot(userID string, name string) (domain.WatchlistSnapshot, error)
+}
+
+type WatchlistEntrySchema struct {
+	Symbol     string `json:"symbol"`
+	AssetClass string `json:"asset_class" jsonschema:"enum=stock,enum=etf,enum=crypto"`
+	AddedAt    string `json:"added_at" jsonschema_description:"ISO 8601 format"`
+	Notes      string `json:"notes,omitempty"`
+}
+
+type WatchlistSchema struct {
+	UserID    string                 `json:"user_id"`
+	Name      string                 `json:"name"`
+	Entries   []WatchlistEntrySchema `json:"entries" jsonschema_description:"In the order they were added"`
+	CreatedAt string                 `json:"created_at" jsonschema_description:"ISO 8601 format"`
+	UpdatedAt string                 `json:"updated_at" jsonschema_description:"ISO 8601 format"`
+}
+
+func newWatchlistSchema(w domain.Watchlist) WatchlistSchema {
+	entries := make([]WatchlistEntrySchema, 0, len(w.Entries))
+	for _, e := range w.Entries {
+		entries = append(entries, newWatchlistEntrySchema(e))
+	}
+	return WatchlistSchema{UserID: w.UserID, Name: w.Name, Entries: entries, CreatedAt: w.CreatedAt, UpdatedAt: w.UpdatedAt}
+}
+
+func newWatchlistEntrySchema(e domain.WatchlistEntry) WatchlistEntrySchema {
+	return WatchlistEntrySchema{Symbol: e.Symbol, AssetClass: string(e.AssetClass), AddedAt: e.AddedAt, Notes: e.Notes}
+}
+
+type CreateWatchlistRequest struct {
+	UserID string `json:"user_id" jsonschema_description:"The id of the user"`
+	Name   string `json:"name" jsonschema_description:"Name of the watchlist, unique per user regardless of the case"`
+}
+
+type CreateWatchlistTool struct {
+	watchlistService WatchlistService
+}
+
+func NewCreateWatchlistTool(watchlistService WatchlistService) (*CreateWatchlistTool, error) {
+	return &CreateWatchlistTool{
+		watchlistService: watchlistService,
+	}, nil
+}
+
+func (t *CreateWatchlistTool) HandleCreateWatchlist(ctx context.Context, req mcp.CallToolRequest, args CreateWatchlistRequest) (WatchlistSchema, error) {
+	if args.UserID == "" {
+		return WatchlistSchema{}, fmt.Errorf("user_id is required")
+	}
+
+	watchlist, err := t.watchlistService.CreateWatchlist(args.UserID, args.Name)
+	if err != nil {
+		return WatchlistSchema{}, err
+	}
+	return newWatchlistSchema(watchlist), nil
+}
+
+func (t *CreateWatchlistTool) GetTool() mcp.Tool {
+	return mcp.NewTool("createWatchlist",
+		mcp.WithDescription("Create an empty named watchlist for the user, to follow securities the user doesn't necessarily own. Add entries with addWatchlistEntries."),
+		mcp.WithInputSchema[CreateWatchlistRequest](),
+		mcp.WithOutputSchema[WatchlistSchema](),
+	)
+}
+
+type ListWatchlistsRequest struct {
+	UserID string `json:"user_id" jsonschema_description:"The id of the user"`
+}
+
+type ListWatchlistsResponse struct {
+	UserID     string            `json:"user_id"`
+	Watchlists []WatchlistSchema `json:"watchlists" jsonschema_description:"Watchlists of the user sorted by name"`
+}
+
+type ListWatchlistsTool struct {
+	watchlistService WatchlistService
+}
+
+func NewListWatchlistsTool(watchlistService WatchlistService) (*ListWatchlistsTool, error) {
+	return &ListWatchlistsTool{
+		watchlistService: watchlistService,
+	}, nil
+}
+
+func (t *ListWatchlistsTool) HandleListWatchlists(ctx context.Context, req mcp.CallToolRequest, args ListWatchlistsRequest) (ListWatchlistsResponse, error) {
+	if args.UserID == "" {
+		return ListWatchlistsResponse{}, fmt.Errorf("user_id is required")
+	}
+
+	watchlists, err := t.watchlistService.GetWatchlists(args.UserID)
+	if err != nil {
+		return ListWatchlistsResponse{}, err
+	}
+
+	schemas := make([]WatchlistSchema, 0, len(watchlists))
+	for _, watchlist := range watchlists {
+		schemas = append(schemas, newWatchlistSchema(watchlist))
+	}
+	return ListWatchlistsResponse{UserID: args.UserID, Watchlists: schemas}, nil
+}
+
+func (t *ListWatchlistsTool) GetTool() mcp.Tool {
+	return mcp.NewTool("listWatchlists",
+		mcp.WithDescription("List the watchlists of the user with their entries"),
+		mcp.WithInputSchema[ListWatchlistsRequest](),
+		mcp.WithOutputSchema[ListWatchlistsResponse](),
+	)
+}
+
+type RenameWatchlistRequest struct {
+	UserID  string `json:"user_id" jsonschema_description:"The id of the user"`
+	Name    string `json:"name" jsonschema_description:"Current name of the watchlist"`
+	NewName string `json:"new_name" jsonschema_description:"New name of the watchlist"`
+}
+
+type RenameWatchlistTool struct {
+	watchlistService WatchlistService
+}
+
+func NewRenameWatchlistTool(watchlistService WatchlistService) (*RenameWatchlistTool, error) {
+	return &RenameWatchlistTool{
+		watchlistService: watchlistService,
+	}, nil
+}
+
+func (t *RenameWatchlistTool) HandleRenameWatchlist(ctx context.Context, req mcp.CallToolRequest, args RenameWatchlistRequest) (WatchlistSchema, error) {
+	if args.UserID == "" {
+		return WatchlistSchema{}, fmt.Errorf("user_id is required")
+	}
+	if args.Name == "" {
+		return WatchlistSchema{}, fmt.Errorf("name is required")
+	}
+
+	watchlist, err := t.watchlistService.RenameWatchlist(args.UserID, args.Name, args.NewName)
+	if err != nil {
+		return WatchlistSchema{}, err
+	}
+	return newWatchlistSchema(watchlist), nil
+}
+
+func (t *RenameWatchlistTool) GetTool() mcp.Tool {
+	return mcp.NewTool("renameWatchlist",
+		mcp.WithDescription("Rename a watchlist of the user"),
+		mcp.WithInputSchema[RenameWatchlistRequest](),
+		mcp.WithOutputSchema[WatchlistSchema](),
+	)
+}
+
+type DeleteWatchlistRequest struct {
+	UserID string `json:"user_id" jsonschema_description:"The id of the user"`
+	Name   string `json:"name" jsonschema_description:"Name of the watchlist"`
+}
+
+type DeleteWatchlistTool struct {
+	watchlistService WatchlistService
+}
+
+func NewDeleteWatchlistTool(watchlistService WatchlistService) (*DeleteWatchlistTool, error) {
+	return &DeleteWatchlistTool{
+		watchlistService: watchlistService,
+	}, nil
+}
+
+func (t *DeleteWatchlistTool) HandleDeleteWatchlist(ctx context.Context, req mcp.CallToolRequest, args DeleteWatchlistRequest) (WatchlistSchema, error) {
+	if args.UserID == "" {
+		return WatchlistSchema{}, fmt.Errorf("user_id is required")
+	}
+	if args.Name == "" {
+		return WatchlistSchema{}, fmt.Errorf("name is required")
+	}
+
+	watchlist, err := t.watchlistService.DeleteWatchlist(args.UserID, args.Name)
+	if err != nil {
+		return WatchlistSchema{}, err
+	}
+	return newWatchlistSchema(watchlist), nil
+}
+
+func (t *DeleteWatchlistTool) GetTool() mcp.Tool {
+	return mcp.NewTool("deleteWatchlist",
+		mcp.WithDescription("Delete a watchlist of the user with its entries. Returns the deleted watchlist."),
+		mcp.WithInputSchema[DeleteWatchlistRequest](),
+		mcp.WithOutputSchema[WatchlistSchema](),
+	)
+}
+
+type WatchlistEntryInputSchema struct {
+	Symbol     string `json:"symbol" jsonschema_description:"Symbol of the stock, the ETF or the cryptocurrency (e.g. AAPL, SPY, BTC)"`
+	AssetClass string `json:"asset_class,omitempty" jsonschema:"enum=stock,enum=etf,enum=crypto,default=stock"`
+	Notes      string `json:"notes,omitempty" jsonschema_description:"Why the security is watched, e.g. a target entry price"`
+}
+
+type AddWatchlistEntriesRequest struct {
+	UserID  string                      `json:"user_id" jsonschema_description:"The id of the user"`
+	Name    string                      `json:"name" jsonschema_description:"Name of the watchlist"`
+	Entries []WatchlistEntryInputSchema `json:"entries" jsonschema_description:"Entries to add, an entry already in the watchlist gets the new notes"`
+}
+
+type AddWatchlistEntriesTool struct {
+	watchlistService WatchlistService
+}
+
+func NewAddWatchlistEntriesTool(watchlistService WatchlistService) (*AddWatchlistEntriesTool, error) {
+	return &AddWatchlistEntriesTool{
+		watchlistService: watchlistService,
+	}, nil
+}
+
+func (t *AddWatchlistEntriesTool) HandleAddWatchlistEntries(ctx context.Context, req mcp.CallToolRequest, args AddWatchlistEntriesRequest) (WatchlistSchema, error) {
+	if args.UserID == "" {
+		return WatchlistSchema{}, fmt.Errorf("user_id is required")
+	}
+	if args.Name == "" {
+		return WatchlistSchema{}, fmt.Errorf("name is required")
+	}
+
+	entries := make([]domain.WatchlistEntry, 0, len(args.Entries))
+	for _, e := range args.Entries {
+		entries = append(entries, domain.WatchlistEntry{Symbol: e.Symbol, AssetClass: domain.AssetClass(e.AssetClass), Notes: e.Notes})
+	}
+	watchlist, err := t.watchlistService.AddWatchlistEntries(args.UserID, args.Name, entries)
+	if err != nil {
+		return WatchlistSchema{}, err
+	}
+	return newWatchlistSchema(watchlist), nil
+}
+
+func (t *AddWatchlistEntriesTool) GetTool() mcp.Tool {
+	return mcp.NewTool("addWatchlistEntries",
+		mcp.WithDescription("Add stocks, ETFs or cryptocurrencies to a watchlist of the user"),
+		mcp.WithInputSchema[AddWatchlistEntriesRequest](),
+		mcp.WithOutputSchema[WatchlistSchema](),
+	)
+}
+
+type RemoveWatchlistEntriesRequest struct {
+	UserID  string   `json:"user_id" jsonschema_description:"The id of the user"`
+	Name    string   `json:"name" jsonschema_description:"Name of the watchlist"`
+	Symbols []string `json:"symbols" jsonschema_description:"Symbols of the entries to remove"`
+}
+
+type RemoveWatchlistEntriesTool struct {
+	watchlistService WatchlistService
+}
+
+func NewRemoveWatchlistEntriesTool(watchlistService WatchlistService) (*RemoveWatchlistEntriesTool, error) {
+	return &RemoveWatchlistEntriesTool{
+		watchlistService: watchlistService,
+	}, nil
+}
+
+func (t *RemoveWatchlistEntriesTool) HandleRemoveWatchlistEntries(ctx context.Context, req mcp.CallToolRequest, args RemoveWatchlistEntriesRequest) (WatchlistSchema, error) {
+	if args.UserID == "" {
+		return WatchlistSchema{}, fmt.Errorf("user_id is required")
+	}
+	if args.Name == "" {
+		return WatchlistSchema{}, fmt.Errorf("name is required")
+	}
+
+	watchlist, err := t.watchlistService.RemoveWatchlistEntries(args.UserID, args.Name, args.Symbols)
+	if err != nil {
+		return WatchlistSchema{}, err
+	}
+	return newWatchlistSchema(watchlist), nil
+}
+
+func (t *RemoveWatchlistEntriesTool) GetTool() mcp.Tool {
+	return mcp.NewTool("removeWatchlistEntries",
+		mcp.WithDescription("Remove entries from a watchlist of the user. Fails without removing anything if a symbol isn't in the watchlist."),
+		mcp.WithInputSchema[RemoveWatchlistEntriesRequest](),
+		mcp.WithOutputSchema[WatchlistSchema](),
+	)
+}
+
+type GetWatchlistSnapshotRequest struct {
+	UserID string `json:"user_id" jsonschema_description:"The id of the user"`
+	Name   string `json:"name" jsonschema_description:"Name of the watchlist"`
+}
+
+type KeyRatiosSchema struct {
+	MarketCap     float64 `json:"market_cap" jsonschema_description:"Market capitalization in USD"`
+	Pe            float64 `json:"pe" jsonschema_description:"Price to earnings ratio"`
+	Ps            float64 `json:"ps" jsonschema_description:"Price to sales ratio"`
+	Pb            float64 `json:"pb" jsonschema_description:"Price to book ratio"`
+	EvEbitda      float64 `json:"ev_ebitda" jsonschema_description:"Enterprise value to EBITDA ratio"`
+	DebtEquity    float64 `json:"debt_equity" jsonschema_description:"Debt to equity ratio"`
+	Roe           float64 `json:"roe" jsonschema_description:"Return on equity, 20 means 20%"`
+	DividendYield float64 `json:"dividend_yield" jsonschema_description:"Dividend yield, 2 means 2%"`
+	ExpenseRatio  float64 `json:"expense_ratio" jsonschema_description:"Expense ratio of an ETF, 0.2 means 0.2%"`
+}
+
+type WatchlistEntrySnapshotSchema struct {
+	Symbol     string          `json:"symbol"`
+	AssetClass string          `json:"asset_class"`
+	Notes      string          `json:"notes,omitempty"`
+	Price      float64         `json:"price" jsonschema_description:"Last price in USD, 0 when unknown"`
+	PriceDate  string          `json:"price_date,omitempty" jsonschema_description:"ISO 8601 format"`
+	Change1D   *float64        `json:"change_1d,omitempty" jsonschema_description:"Performance over a day in percent"`
+	Change1M   *float64        `json:"change_1m,omitempty" jsonschema_description:"Performance over a month in percent"`
+	Change1Y   *float64        `json:"change_1y,omitempty" jsonschema_description:"Performance over a year in percent"`
+	Ratios     KeyRatiosSchema `json:"ratios" jsonschema_description:"Key ratios, zero when unknown or not applicable to the asset class"`
+	Errors     []string        `json:"errors,omitempty" jsonschema_description:"Data that couldn't be fetched"`
+}
+
+type GetWatchlistSnapshotResponse struct {
+	UserID    string                         `json:"user_id"`
+	Name      string                         `json:"name"`
+	Entries   []WatchlistEntrySnapshotSchema `json:"entries" jsonschema_description:"In the order of the watchlist"`
+	FetchedAt string                         `json:"fetched_at" jsonschema_description:"ISO 8601 format"`
+}
+
+type GetWatchlistSnapshotTool struct {
+	watchlistSnapshotService WatchlistSnapshotService
+}
+
+func NewGetWatchlistSnapshotTool(watchlistSnapshotService WatchlistSnapshotService) (*GetWatchlistSnapshotTool, error) {
+	return &GetWatchlistSnapshotTool{
+		watchlistSnapshotService: watchlistSnapshotService,
+	}, nil
+}
+
+func (t *GetWatchlistSnapshotTool) HandleGetWatchlistSnapshot(ctx context.Context, req mcp.CallToolRequest, args GetWatchlistSnapshotRequest) (GetWatchlistSnapshotResponse, error) {
+	if args.UserID == "" {
+		return GetWatchlistSnapshotResponse{}, fmt.Errorf("user_id is required")
+	}
+	if args.Name == "" {
+		return GetWatchlistSnapshotResponse{}, fmt.Errorf("name is required")
+	}
+
+	snapshot, err := t.watchlistSnapshotService.GetWatchlistSnapshot(args.UserID, args.Name)
+	if err != nil {
+		return GetWatchlistSnapshotResponse{}, err
+	}
+
+	entries := make([]WatchlistEntrySnapshotSchema, 0, len(snapshot.Entries))
+	for _, e := range snapshot.Entries {
+		entry := WatchlistEntrySnapshotSchema{
+			Symbol:     e.Entry.Symbol,
+			AssetClass: string(e.Entry.AssetClass),
+			Notes:      e.Entry.Notes,
+			Price:      e.Price,
+			Change1D:   e.Change1D,
+			Change1M:   e.Change1M,
+			Change1Y:   e.Change1Y,
+			Ratios: KeyRatiosSchema{
+				MarketCap:     e.Ratios.MarketCap,
+				Pe:            e.Ratios.Pe,
+				Ps:            e.Ratios.Ps,
+				Pb:            e.Ratios.Pb,
+				EvEbitda:      e.Ratios.EvEbitda,
+				DebtEquity:    e.Ratios.DebtEquity,
+				Roe:           e.Ratios.Roe,
+				DividendYield: e.Ratios.DividendYield,
+				ExpenseRatio:  e.Ratios.ExpenseRatio,
+			},
+			Errors: e.Errors,
+		}
+		if !e.PriceDate.IsZero() {
+			entry.PriceDate = e.PriceDate.Format(time.RFC3339)
+		}
+		entries = append(entries, entry)
+	}
+
+	return GetWatchlistSnapshotResponse{
+		UserID:    snapshot.Watchlist.UserID,
+		Name:      snapshot.Watchlist.Name,
+		Entries:   entries,
+		FetchedAt: snapshot.FetchedAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (t *GetWatchlistSnapshotTool) GetTool() mcp.Tool {
+	return mcp.NewTool("getWatchlistSnapshot",
+		mcp.WithDescription("Get the current price, the 1 day, 1 month and 1 year performance and the key ratios of every entry of a watchlist of the user"),
+		mcp.WithInputSchema[GetWatchlistSnapshotRequest](),
+		mcp.WithOutputSchema[GetWatchlistSnapshotResponse](),
+	)
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sessionAlertNotifier sends the alert events as logging notifications (level notice, logger alerts) to the
+// sessions of their user. A session belongs to the users whose user_id it passed to a tool, the clients receive
+// the notifications once they set their logging level to notice or lower.
+type sessionAlertNotifier struct {
+	mcpServer *server.MCPServer
+
+	mu       sync.Mutex
+	sessions map[string]map[string]bool // Session ids by user id
+}
+
+func newSessionAlertNotifier() *sessionAlertNotifier {
+	return &sessionAlertNotifier{sessions: make(map[string]map[string]bool)}
+}
+
+// ToolMiddleware links the session to the user_id argument of the tool calls
+func (n *sessionAlertNotifier) ToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		session := server.ClientSessionFromContext(ctx)
+		if userID, ok := request.GetArguments()["user_id"].(string); ok && userID != "" && session != nil {
+			n.mu.Lock()
+			if n.sessions[userID] == nil {
+				n.sessions[userID] = make(map[string]bool)
+			}
+			n.sessions[userID][session.SessionID()] = true
+			n.mu.Unlock()
+		}
+		return next(ctx, request)
+	}
+}
+
+// unregisterSession forgets the session once closed
+func (n *sessionAlertNotifier) unregisterSession(_ context.Context, session server.ClientSession) {
+	n.removeSession(session.SessionID())
+}
+
+func (n *sessionAlertNotifier) removeSession(sessionID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for userID, sessions := range n.sessions {
+		delete(sessions, sessionID)
+		if len(sessions) == 0 {
+			delete(n.sessions, userID)
+		}
+	}
+}
+
+// NotifyAlert sends the event to the sessions of its user. The sessions closed meanwhile are forgotten, a user
+// without any session isn't an error: the events are also kept for getAlertEvents.
+func (n *sessionAlertNotifier) NotifyAlert(_ context.Context, alert domain.Alert, event domain.AlertEvent) error {
+	n.mu.Lock()
+	var sessionIDs []string
+	for sessionID := range n.sessions[alert.UserID] {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	n.mu.Unlock()
+
+	notification := mcp.NewLoggingMessageNotification(mcp.LoggingLevelNotice, "alerts", event)
+	var errs []error
+	for _, sessionID := range sessionIDs {
+		err := n.mcpServer.SendLogMessageToSpecificClient(sessionID, notification)
+		if errors.Is(err, server.ErrSessionNotFound) {
+			n.removeSession(sessionID)
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("session %s: %w", sessionID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
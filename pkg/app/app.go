@@ -0,0 +1,581 @@
+// Package app assembles the MCP server: the upstream clients, the services and the tools, prompts,
+// resources and argument completions on top of them. It is shared by the server command and the
+// commands that run the server in-process (e.g. cmd/validate).
+package app
+
+import (
+	"context"
+	"log"
+	alphavantage "market_data_mcp_server/pkg/alpha_vantage"
+	"market_data_mcp_server/pkg/api/mcp/completions"
+	"market_data_mcp_server/pkg/api/mcp/prompts"
+	"market_data_mcp_server/pkg/api/mcp/resources"
+	"market_data_mcp_server/pkg/api/mcp/tools"
+	coingecko "market_data_mcp_server/pkg/coin_gecko"
+	"market_data_mcp_server/pkg/config"
+	"market_data_mcp_server/pkg/marketDataScraper"
+	"market_data_mcp_server/pkg/services"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	Name    = "Market Data MCP Server"
+	Version = "1.0.0"
+)
+
+type App struct {
+	MCPServer *server.MCPServer
+	// Completer answers the completion/complete requests, which mcp-go doesn't route to any handler
+	Completer *completions.Completer
+	// Alerts evaluates the alerts of the users, the server command schedules it
+	Alerts *services.AlertEngine
+}
+
+// Tools are the tools of the server, built on top of the upstream clients and the services.
+// They are also called directly by the commands that don't go through MCP (e.g. cmd/mdcli).
+type Tools struct {
+	SearchStocks                   *tools.StockSearchTool
+	SearchEtfs                     *tools.SearchEtfTool
+	GetEtf                         *tools.GetEtfTool
+	GetSuperInvestors              *tools.GetSuperInvestorsTool
+	GetSuperInvestorPortfolio      *tools.GetSuperInvestorPortfolioTool
+	GetMarketNews                  *tools.GetMarketNewsTool
+	GetSectors                     *tools.GetSectorsTool
+	GetSectorStocks                *tools.GetSectorStocksTool
+	GetIndustries                  *tools.GetIndustriesTool
+	GetIndustryStocks              *tools.GetIndustryStocksTool
+	GetStockOverview               *tools.GetStockOverviewTool
+	GetStockFinancials             *tools.GetStockFinancialsTool
+	GetEconomicIndicatorTimeSeries *tools.GetEconomicIndicatorTimeSeriesTool
+	GetCommodityTimeSeries         *tools.GetCommodityTimeSeriesTool
+	SearchCryptocurrencies         *tools.SearchCryptocurrenciesTool
+	GetCryptocurrencyDataById      *tools.GetCryptocurrencyDataByIdTool
+	GetCryptocurrencyNews          *tools.GetCryptocurrencyNewsTool
+	CalculateInvestmentFutureValue *tools.CalculateInvestmentFutureValueTool
+	GetEarningsCallTranscript      *tools.GetEarningsCallTranscriptTool
+	GetInsiderTransactions         *tools.GetInsiderTransactionsTool
+	GetCompanyKpiMetrics           *tools.GetCompanyKpiMetricsTool
+	GetInvestingIdeas              *tools.GetInvestingIdeasTool
+	GetInvestingIdeaStocks         *tools.GetInvestingIdeaStocksTool
+	GetCurrencyExchangeRate        *tools.GetCurrencyExchangeRateTool
+	Search                         *tools.SearchTool
+	GetUserContext                 *tools.GetUserContextTool
+	UpdateUserContext              *tools.UpdateUserContextTool
+	UpsertPortfolioHolding         *tools.UpsertPortfolioHoldingTool
+	RemovePortfolioHolding         *tools.RemovePortfolioHoldingTool
+	AdjustHoldingQuantity          *tools.AdjustHoldingQuantityTool
+	PatchUserProfile               *tools.PatchUserProfileTool
+	ClearUserPortfolio             *tools.ClearUserPortfolioTool
+	GetUserContextHistory          *tools.GetUserContextHistoryTool
+	RevertUserContextChange        *tools.RevertUserContextChangeTool
+	GetPortfolioValuation          *tools.GetPortfolioValuationTool
+	GetPortfolioExposure           *tools.GetPortfolioExposureTool
+	RecordTransaction              *tools.RecordTransactionTool
+	DeleteTransaction              *tools.DeleteTransactionTool
+	ListTransactions               *tools.ListTransactionsTool
+	GetPortfolioPerformance        *tools.GetPortfolioPerformanceTool
+	CreateWatchlist                *tools.CreateWatchlistTool
+	ListWatchlists                 *tools.ListWatchlistsTool
+	RenameWatchlist                *tools.RenameWatchlistTool
+	DeleteWatchlist                *tools.DeleteWatchlistTool
+	AddWatchlistEntries            *tools.AddWatchlistEntriesTool
+	RemoveWatchlistEntries         *tools.RemoveWatchlistEntriesTool
+	GetWatchlistSnapshot           *tools.GetWatchlistSnapshotTool
+	CreateAlert                    *tools.CreateAlertTool
+	ListAlerts                     *tools.ListAlertsTool
+	DeleteAlert                    *tools.DeleteAlertTool
+	GetAlertEvents                 *tools.GetAlertEventsTool
+	CheckAlerts                    *tools.CheckAlertsTool
+	GetHistoricalPrices            *tools.GetHistoricalPricesTool
+	GetTechnicalIndicators         *tools.GetTechnicalIndicatorsTool
+	GetRiskMetrics                 *tools.GetRiskMetricsTool
+	CompareStocks                  *tools.CompareStocksTool
+	ScreenStocks                   *tools.ScreenStocksTool
+	GetPeerComparison              *tools.GetPeerComparisonTool
+	CalculateDcf                   *tools.CalculateDcfTool
+
+	// Services needed by the prompts, resources and completions
+	dataService           *marketDataScraper.MarketDataScraperWithCache
+	etfService            *services.EtfService
+	cryptoService         *services.CryptoService
+	superInvestorService  *services.SuperInvestorService
+	investingIdeasService *services.InvestingIdeasLocalDataService
+	alertEngine           *services.AlertEngine
+}
+
+// NewTools builds the tools on top of the given cache
+func NewTools(conf config.Config, cache services.CacheService) *Tools {
+	// Setup data services
+	dataService := marketDataScraper.NewMarketDataScraperWithCache(cache, conf)
+
+	alphaVantageClient, _ := alphavantage.NewAlphaVantageClientWithCache(conf.AlphaVantageApiKey, conf.AlphaVantageBaseURL, cache, conf.AlphaVantageCacheTtl)
+	coinGeckoClient, _ := coingecko.NewCoinGeckoClientWithCache(conf.CoinGeckoApiKey, conf.CoinGeckoBaseURL, cache, conf.CoinGeckoCacheTtl)
+
+	// Set up services
+	tickerService, _ := services.NewTickerService(dataService)
+	etfService, _ := services.NewEtfService(dataService)
+	superInvestorService, _ := services.NewSuperInvestorService(dataService)
+	cryptoService, _ := services.NewCryptoService(coinGeckoClient, alphaVantageClient)
+	investingIdeasService, _ := services.NewInvestingIdeasLocalDataService(conf.InvestingIdeasDataPath)
+	userContextService, _ := services.NewUserContextLocalDataService(conf.UserContextDataPath)
+	portfolioValuationService, _ := services.NewPortfolioValuationService(userContextService, dataService, cryptoService, alphaVantageClient)
+	portfolioAnalyticsService, _ := services.NewPortfolioAnalyticsService(userContextService, portfolioValuationService, dataService, dataService)
+	transactionLedgerService, _ := services.NewTransactionLedgerLocalDataService(conf.TransactionsDataPath)
+	watchlistService, _ := services.NewWatchlistLocalDataService(conf.WatchlistsDataPath)
+	watchlistSnapshotService, _ := services.NewWatchlistSnapshotService(watchlistService, dataService, cryptoService)
+	portfolioPerformanceService, _ := services.NewPortfolioPerformanceService(transactionLedgerService, portfolioValuationService, dataService, alphaVantageClient)
+	alertService, _ := services.NewAlertLocalDataService(conf.AlertsDataPath)
+	alertEngine, _ := services.NewAlertEngine(alertService, dataService, alphaVantageClient, cryptoService, log.New(os.Stderr, "[Alerts] ", log.LstdFlags))
+	if webhookNotifier, err := services.NewWebhookNotifier(10*time.Second, conf.AlertsWebhookNetworks); err != nil {
+		log.Printf("Webhooks of the alerts disabled: %v", err)
+	} else {
+		alertEngine.AddNotifier(webhookNotifier)
+	}
+	priceHistoryService, _ := services.NewPriceHistoryService(dataService, alphaVantageClient, cryptoService, coinGeckoClient)
+	riskMetricsService, _ := services.NewRiskMetricsService(priceHistoryService, alphaVantageClient, portfolioValuationService)
+	stockComparisonService, _ := services.NewStockComparisonService(dataService)
+	stockScreenerService, _ := services.NewStockScreenerService(dataService)
+	industryService, _ := services.NewIndustryService(dataService)
+	peerComparisonService, _ := services.NewPeerComparisonService(dataService)
+	dcfService, _ := services.NewDcfService(dataService, alphaVantageClient, riskMetricsService)
+	universalSearchService, _ := services.NewUniversalSearchService(
+		tickerService, etfService, cryptoService, dataService, dataService, dataService, investingIdeasService,
+	)
+
+	t := &Tools{
+		dataService:           dataService,
+		etfService:            etfService,
+		cryptoService:         cryptoService,
+		superInvestorService:  superInvestorService,
+		investingIdeasService: investingIdeasService,
+		alertEngine:           alertEngine,
+	}
+
+	// Setup tools
+	t.SearchStocks, _ = tools.NewStockSearchTool(tickerService)
+	t.SearchEtfs, _ = tools.NewSearchEtfTool(etfService)
+	t.GetEtf, _ = tools.NewGetEtfTool(etfService)
+	t.GetSuperInvestors, _ = tools.NewGetSuperInvestorsTool(superInvestorService)
+	t.GetSuperInvestorPortfolio, _ = tools.NewGetSuperInvestorPortfolioTool(superInvestorService)
+	t.GetMarketNews, _ = tools.NewGetMarketNewsTool(dataService)
+	t.GetSectors, _ = tools.NewGetSectorsTool(dataService)
+	t.GetSectorStocks, _ = tools.NewGetSectorStocksTool(dataService)
+	t.GetIndustries, _ = tools.NewGetIndustriesTool(industryService)
+	t.GetIndustryStocks, _ = tools.NewGetIndustryStocksTool(industryService)
+	t.GetStockOverview, _ = tools.NewGetStockOverviewTool(dataService)
+	t.GetStockFinancials, _ = tools.NewGetStockFinancialsTool(dataService)
+	t.GetEconomicIndicatorTimeSeries, _ = tools.NewGetEconomicIndicatorTimeSeriesTool(alphaVantageClient)
+	t.GetCommodityTimeSeries, _ = tools.NewGetCommodityTimeSeriesTool(alphaVantageClient)
+	t.SearchCryptocurrencies, _ = tools.NewSearchCryptocurrenciesTool(cryptoService)
+	t.GetCryptocurrencyDataById, _ = tools.NewGetCryptocurrencyDataByIdTool(cryptoService)
+	t.GetCryptocurrencyNews, _ = tools.NewGetCryptocurrencyNewsTool(cryptoService)
+	t.CalculateInvestmentFutureValue, _ = tools.NewCalculateInvestmentFutureValueTool()
+	t.GetEarningsCallTranscript, _ = tools.NewGetEarningsCallTranscriptTool(alphaVantageClient)
+	t.GetInsiderTransactions, _ = tools.NewGetInsiderTransactionsTool(alphaVantageClient)
+	t.GetCompanyKpiMetrics, _ = tools.NewGetCompanyKpiMetricsTool(dataService)
+	t.GetInvestingIdeas, _ = tools.NewGetInvestingIdeasTool(investingIdeasService)
+	t.GetInvestingIdeaStocks, _ = tools.NewGetInvestingIdeaStocksTool(investingIdeasService)
+	t.GetCurrencyExchangeRate, _ = tools.NewGetCurrencyExchangeRateTool(alphaVantageClient)
+	t.Search, _ = tools.NewSearchTool(universalSearchService)
+	t.GetUserContext, _ = tools.NewGetUserContextTool(userContextService)
+	t.UpdateUserContext, _ = tools.NewUpdateUserContextTool(userContextService)
+	t.UpsertPortfolioHolding, _ = tools.NewUpsertPortfolioHoldingTool(userContextService)
+	t.RemovePortfolioHolding, _ = tools.NewRemovePortfolioHoldingTool(userContextService)
+	t.AdjustHoldingQuantity, _ = tools.NewAdjustHoldingQuantityTool(userContextService)
+	t.PatchUserProfile, _ = tools.NewPatchUserProfileTool(userContextService)
+	t.ClearUserPortfolio, _ = tools.NewClearUserPortfolioTool(userContextService)
+	t.GetUserContextHistory, _ = tools.NewGetUserContextHistoryTool(userContextService)
+	t.RevertUserContextChange, _ = tools.NewRevertUserContextChangeTool(userContextService)
+	t.GetPortfolioValuation, _ = tools.NewGetPortfolioValuationTool(portfolioValuationService)
+	t.GetPortfolioExposure, _ = tools.NewGetPortfolioExposureTool(portfolioAnalyticsService)
+	t.RecordTransaction, _ = tools.NewRecordTransactionTool(transactionLedgerService)
+	t.DeleteTransaction, _ = tools.NewDeleteTransactionTool(transactionLedgerService)
+	t.ListTransactions, _ = tools.NewListTransactionsTool(transactionLedgerService)
+	t.GetPortfolioPerformance, _ = tools.NewGetPortfolioPerformanceTool(portfolioPerformanceService)
+	t.CreateWatchlist, _ = tools.NewCreateWatchlistTool(watchlistService)
+	t.ListWatchlists, _ = tools.NewListWatchlistsTool(watchlistService)
+	t.RenameWatchlist, _ = tools.NewRenameWatchlistTool(watchlistService)
+	t.DeleteWatchlist, _ = tools.NewDeleteWatchlistTool(watchlistService)
+	t.AddWatchlistEntries, _ = tools.NewAddWatchlistEntriesTool(watchlistService)
+	t.RemoveWatchlistEntries, _ = tools.NewRemoveWatchlistEntriesTool(watchlistService)
+	t.GetWatchlistSnapshot, _ = tools.NewGetWatchlistSnapshotTool(watchlistSnapshotService)
+	t.CreateAlert, _ = tools.NewCreateAlertTool(alertService)
+	t.ListAlerts, _ = tools.NewListAlertsTool(alertService)
+	t.DeleteAlert, _ = tools.NewDeleteAlertTool(alertService)
+	t.GetAlertEvents, _ = tools.NewGetAlertEventsTool(alertService)
+	t.CheckAlerts, _ = tools.NewCheckAlertsTool(alertService, alertEngine)
+	t.GetHistoricalPrices, _ = tools.NewGetHistoricalPricesTool(priceHistoryService)
+	t.GetTechnicalIndicators, _ = tools.NewGetTechnicalIndicatorsTool(priceHistoryService)
+	t.GetRiskMetrics, _ = tools.NewGetRiskMetricsTool(riskMetricsService)
+	t.CompareStocks, _ = tools.NewCompareStocksTool(stockComparisonService)
+	t.ScreenStocks, _ = tools.NewScreenStocksTool(stockScreenerService)
+	t.GetPeerComparison, _ = tools.NewGetPeerComparisonTool(peerComparisonService)
+	t.CalculateDcf, _ = tools.NewCalculateDcfTool(dcfService)
+
+	return t
+}
+
+// New builds the MCP server on top of the given cache, the server options (e.g. middlewares) are added to the default ones
+func New(conf config.Config, cache services.CacheService, serverOptions ...server.ServerOption) *App {
+	// The alert events are sent to the sessions of their user as logging notifications
+	alertNotifier := newSessionAlertNotifier()
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(alertNotifier.unregisterSession)
+	// mcp-go has no completions capability, it is advertised as an experimental one for the clients to know they
+	// can send completion/complete requests
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, message *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		if result.Capabilities.Experimental == nil {
+			result.Capabilities.Experimental = make(map[string]any)
+		}
+		result.Capabilities.Experimental["completions"] = map[string]any{}
+	})
+
+	options := []server.ServerOption{
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, true),
+		server.WithPromptCapabilities(true),
+		server.WithLogging(),
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(alertNotifier.ToolMiddleware),
+		server.WithRecovery(),
+	}
+	mcpServer := server.NewMCPServer(Name, Version, append(options, serverOptions...)...)
+	alertNotifier.mcpServer = mcpServer
+
+	t := NewTools(conf, cache)
+	t.alertEngine.AddNotifier(alertNotifier)
+
+	// Add tools
+	mcpServer.AddTool(
+		t.SearchStocks.GetTool(),
+		mcp.NewStructuredToolHandler(t.SearchStocks.HandleSearchStocks),
+	)
+
+	mcpServer.AddTool(
+		t.SearchEtfs.GetTool(),
+		mcp.NewStructuredToolHandler(t.SearchEtfs.HandleSearchEtfs),
+	)
+
+	mcpServer.AddTool(
+		t.GetEtf.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetEtf.HandleGetEtf),
+	)
+
+	mcpServer.AddTool(
+		t.GetSuperInvestors.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetSuperInvestors.HandleGetSuperInvestors),
+	)
+
+	mcpServer.AddTool(
+		t.GetSuperInvestorPortfolio.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetSuperInvestorPortfolio.HandleGetSuperInvestorPortfolio),
+	)
+
+	mcpServer.AddTool(
+		t.GetMarketNews.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetMarketNews.HandleGetNews),
+	)
+
+	mcpServer.AddTool(
+		t.GetSectors.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetSectors.HandleGetSectors),
+	)
+
+	mcpServer.AddTool(
+		t.GetSectorStocks.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetSectorStocks.HandleGetSectorStocks),
+	)
+
+	mcpServer.AddTool(
+		t.GetIndustries.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetIndustries.HandleGetIndustries),
+	)
+
+	mcpServer.AddTool(
+		t.GetIndustryStocks.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetIndustryStocks.HandleGetIndustryStocks),
+	)
+
+	mcpServer.AddTool(
+		t.GetStockOverview.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetStockOverview.HandleGetStockOverview),
+	)
+
+	mcpServer.AddTool(
+		t.GetStockFinancials.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetStockFinancials.HandleGetStockFinancials),
+	)
+
+	mcpServer.AddTool(
+		t.GetEconomicIndicatorTimeSeries.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetEconomicIndicatorTimeSeries.HandleGetEconomicIndicatorTimeSeries),
+	)
+
+	mcpServer.AddTool(
+		t.GetCommodityTimeSeries.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetCommodityTimeSeries.HandleGetCommodityTimeSeries),
+	)
+
+	mcpServer.AddTool(
+		t.SearchCryptocurrencies.GetTool(),
+		mcp.NewStructuredToolHandler(t.SearchCryptocurrencies.HandleSearchCryptocurrencies),
+	)
+
+	mcpServer.AddTool(
+		t.GetCryptocurrencyDataById.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetCryptocurrencyDataById.HandleGetCryptocurrencyDataById),
+	)
+
+	mcpServer.AddTool(
+		t.GetCryptocurrencyNews.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetCryptocurrencyNews.HandleGetCryptocurrencyNews),
+	)
+
+	mcpServer.AddTool(
+		t.CalculateInvestmentFutureValue.GetTool(),
+		mcp.NewStructuredToolHandler(t.CalculateInvestmentFutureValue.HandleCalculateInvestmentFutureValue),
+	)
+
+	mcpServer.AddTool(
+		t.GetEarningsCallTranscript.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetEarningsCallTranscript.HandleGetEarningsCallTranscript),
+	)
+
+	mcpServer.AddTool(
+		t.GetInsiderTransactions.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetInsiderTransactions.HandleGetInsiderTransactions),
+	)
+
+	mcpServer.AddTool(
+		t.GetCompanyKpiMetrics.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetCompanyKpiMetrics.HandleGetCompanyKpiMetrics),
+	)
+
+	mcpServer.AddTool(
+		t.GetInvestingIdeas.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetInvestingIdeas.HandleGetInvestingIdeas),
+	)
+
+	mcpServer.AddTool(
+		t.GetInvestingIdeaStocks.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetInvestingIdeaStocks.HandleGetInvestingIdeaStocks),
+	)
+
+	mcpServer.AddTool(
+		t.GetCurrencyExchangeRate.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetCurrencyExchangeRate.HandleGetCurrencyExchangeRate),
+	)
+
+	mcpServer.AddTool(
+		t.Search.GetTool(),
+		mcp.NewStructuredToolHandler(t.Search.HandleSearch),
+	)
+
+	mcpServer.AddTool(
+		t.GetUserContext.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetUserContext.HandleGetUserContext),
+	)
+
+	mcpServer.AddTool(
+		t.UpdateUserContext.GetTool(),
+		mcp.NewStructuredToolHandler(t.UpdateUserContext.HandleUpdateUserContext),
+	)
+
+	mcpServer.AddTool(
+		t.UpsertPortfolioHolding.GetTool(),
+		mcp.NewStructuredToolHandler(t.UpsertPortfolioHolding.HandleUpsertPortfolioHolding),
+	)
+
+	mcpServer.AddTool(
+		t.RemovePortfolioHolding.GetTool(),
+		mcp.NewStructuredToolHandler(t.RemovePortfolioHolding.HandleRemovePortfolioHolding),
+	)
+
+	mcpServer.AddTool(
+		t.AdjustHoldingQuantity.GetTool(),
+		mcp.NewStructuredToolHandler(t.AdjustHoldingQuantity.HandleAdjustHoldingQuantity),
+	)
+
+	mcpServer.AddTool(
+		t.PatchUserProfile.GetTool(),
+		mcp.NewStructuredToolHandler(t.PatchUserProfile.HandlePatchUserProfile),
+	)
+
+	mcpServer.AddTool(
+		t.ClearUserPortfolio.GetTool(),
+		mcp.NewStructuredToolHandler(t.ClearUserPortfolio.HandleClearUserPortfolio),
+	)
+
+	mcpServer.AddTool(
+		t.GetUserContextHistory.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetUserContextHistory.HandleGetUserContextHistory),
+	)
+
+	mcpServer.AddTool(
+		t.RevertUserContextChange.GetTool(),
+		mcp.NewStructuredToolHandler(t.RevertUserContextChange.HandleRevertUserContextChange),
+	)
+
+	mcpServer.AddTool(
+		t.GetPortfolioValuation.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetPortfolioValuation.HandleGetPortfolioValuation),
+	)
+
+	mcpServer.AddTool(
+		t.GetPortfolioExposure.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetPortfolioExposure.HandleGetPortfolioExposure),
+	)
+
+	mcpServer.AddTool(
+		t.RecordTransaction.GetTool(),
+		mcp.NewStructuredToolHandler(t.RecordTransaction.HandleRecordTransaction),
+	)
+
+	mcpServer.AddTool(
+		t.DeleteTransaction.GetTool(),
+		mcp.NewStructuredToolHandler(t.DeleteTransaction.HandleDeleteTransaction),
+	)
+
+	mcpServer.AddTool(
+		t.ListTransactions.GetTool(),
+		mcp.NewStructuredToolHandler(t.ListTransactions.HandleListTransactions),
+	)
+
+	mcpServer.AddTool(
+		t.GetPortfolioPerformance.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetPortfolioPerformance.HandleGetPortfolioPerformance),
+	)
+
+	mcpServer.AddTool(
+		t.CreateWatchlist.GetTool(),
+		mcp.NewStructuredToolHandler(t.CreateWatchlist.HandleCreateWatchlist),
+	)
+
+	mcpServer.AddTool(
+		t.ListWatchlists.GetTool(),
+		mcp.NewStructuredToolHandler(t.ListWatchlists.HandleListWatchlists),
+	)
+
+	mcpServer.AddTool(
+		t.RenameWatchlist.GetTool(),
+		mcp.NewStructuredToolHandler(t.RenameWatchlist.HandleRenameWatchlist),
+	)
+
+	mcpServer.AddTool(
+		t.DeleteWatchlist.GetTool(),
+		mcp.NewStructuredToolHandler(t.DeleteWatchlist.HandleDeleteWatchlist),
+	)
+
+	mcpServer.AddTool(
+		t.AddWatchlistEntries.GetTool(),
+		mcp.NewStructuredToolHandler(t.AddWatchlistEntries.HandleAddWatchlistEntries),
+	)
+
+	mcpServer.AddTool(
+		t.RemoveWatchlistEntries.GetTool(),
+		mcp.NewStructuredToolHandler(t.RemoveWatchlistEntries.HandleRemoveWatchlistEntries),
+	)
+
+	mcpServer.AddTool(
+		t.GetWatchlistSnapshot.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetWatchlistSnapshot.HandleGetWatchlistSnapshot),
+	)
+
+	mcpServer.AddTool(
+		t.CreateAlert.GetTool(),
+		mcp.NewStructuredToolHandler(t.CreateAlert.HandleCreateAlert),
+	)
+
+	mcpServer.AddTool(
+		t.ListAlerts.GetTool(),
+		mcp.NewStructuredToolHandler(t.ListAlerts.HandleListAlerts),
+	)
+
+	mcpServer.AddTool(
+		t.DeleteAlert.GetTool(),
+		mcp.NewStructuredToolHandler(t.DeleteAlert.HandleDeleteAlert),
+	)
+
+	mcpServer.AddTool(
+		t.GetAlertEvents.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetAlertEvents.HandleGetAlertEvents),
+	)
+
+	mcpServer.AddTool(
+		t.CheckAlerts.GetTool(),
+		mcp.NewStructuredToolHandler(t.CheckAlerts.HandleCheckAlerts),
+	)
+
+	mcpServer.AddTool(
+		t.GetHistoricalPrices.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetHistoricalPrices.HandleGetHistoricalPrices),
+	)
+
+	mcpServer.AddTool(
+		t.GetTechnicalIndicators.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetTechnicalIndicators.HandleGetTechnicalIndicators),
+	)
+
+	mcpServer.AddTool(
+		t.GetRiskMetrics.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetRiskMetrics.HandleGetRiskMetrics),
+	)
+
+	mcpServer.AddTool(
+		t.CompareStocks.GetTool(),
+		mcp.NewStructuredToolHandler(t.CompareStocks.HandleCompareStocks),
+	)
+
+	mcpServer.AddTool(
+		t.ScreenStocks.GetTool(),
+		mcp.NewStructuredToolHandler(t.ScreenStocks.HandleScreenStocks),
+	)
+
+	mcpServer.AddTool(
+		t.GetPeerComparison.GetTool(),
+		mcp.NewStructuredToolHandler(t.GetPeerComparison.HandleGetPeerComparison),
+	)
+
+	mcpServer.AddTool(
+		t.CalculateDcf.GetTool(),
+		mcp.NewStructuredToolHandler(t.CalculateDcf.HandleCalculateDcf),
+	)
+
+	// Setup prompts
+	analyzeStockPrompt, _ := prompts.NewAnalyzeStockPrompt()
+	analyzeEtfPrompt, _ := prompts.NewAnalyzeEtfPrompt()
+	reviewSuperInvestorPortfolioPrompt, _ := prompts.NewReviewSuperInvestorPortfolioPrompt()
+	exploreInvestingIdeaPrompt, _ := prompts.NewExploreInvestingIdeaPrompt()
+	commodityOutlookPrompt, _ := prompts.NewCommodityOutlookPrompt()
+	analyzeCryptocurrencyPrompt, _ := prompts.NewAnalyzeCryptocurrencyPrompt()
+	currencyOutlookPrompt, _ := prompts.NewCurrencyOutlookPrompt()
+
+	// Add prompts
+	mcpServer.AddPrompt(analyzeStockPrompt.GetPrompt(), analyzeStockPrompt.HandleAnalyzeStock)
+	mcpServer.AddPrompt(analyzeEtfPrompt.GetPrompt(), analyzeEtfPrompt.HandleAnalyzeEtf)
+	mcpServer.AddPrompt(reviewSuperInvestorPortfolioPrompt.GetPrompt(), reviewSuperInvestorPortfolioPrompt.HandleReviewSuperInvestorPortfolio)
+	mcpServer.AddPrompt(exploreInvestingIdeaPrompt.GetPrompt(), exploreInvestingIdeaPrompt.HandleExploreInvestingIdea)
+	mcpServer.AddPrompt(commodityOutlookPrompt.GetPrompt(), commodityOutlookPrompt.HandleCommodityOutlook)
+	mcpServer.AddPrompt(analyzeCryptocurrencyPrompt.GetPrompt(), analyzeCryptocurrencyPrompt.HandleAnalyzeCryptocurrency)
+	mcpServer.AddPrompt(currencyOutlookPrompt.GetPrompt(), currencyOutlookPrompt.HandleCurrencyOutlook)
+
+	// Setup resources
+	etfResource, _ := resources.NewEtfResource(t.etfService)
+	superInvestorPortfolioResource, _ := resources.NewSuperInvestorPortfolioResource(t.superInvestorService)
+
+	// Add resources
+	mcpServer.AddResourceTemplate(etfResource.GetResourceTemplate(), etfResource.HandleReadEtf)
+	mcpServer.AddResourceTemplate(superInvestorPortfolioResource.GetResourceTemplate(), superInvestorPortfolioResource.HandleReadSuperInvestorPortfolio)
+
+	// Setup argument completions
+	completer, _ := completions.NewCompleter(t.dataService, t.dataService, t.cryptoService, t.dataService, t.investingIdeasService)
+	// The commodity and currency prompts share their arguments with the tools
+	completer.RegisterEnumArguments(tools.GetCommodityTimeSeriesRequest{})
+	completer.RegisterEnumArguments(tools.GetCurrencyExchangeRateRequest{})
+
+	return &App{MCPServer: mcpServer, Completer: completer, Alerts: t.alertEngine}
+}
@@ -0,0 +1,192 @@
+// Package canary runs every scraper against a set of reference subjects and reports the scrapers that fail
+// or whose responses drifted from the schema they declare, so that a layout change of a scraped website
+// is noticed before the tools start answering with zero values.
+package canary
+
+import (
+	"context"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/drift"
+	"market_data_mcp_server/pkg/marketDataScraper"
+	"time"
+)
+
+// Scraper is the scraper the canary checks, it must not be cached otherwise the canary checks the cache
+type Scraper interface {
+	GetTickers() ([]domain.Ticker, error)
+	GetEtfs() ([]domain.Etf, error)
+	GetEtfOverview(symbol string) (domain.EtfOverview, error)
+	GetBalanceSheets(symbol string) ([]domain.BalanceSheet, error)
+	GetCashFlows(symbol string) ([]domain.CashFlow, error)
+	GetIncomeStatements(symbol string) ([]domain.IncomeStatement, error)
+	GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error)
+	GetStockForecast(symbol string) (domain.StockForecast, error)
+	GetStockProfile(symbol string) (domain.StockProfile, error)
+	GetCompanyKpiMetrics(symbol string) (domain.CompanyKpiMetrics, error)
+	GetStockNews(symbol string) ([]domain.NewsArticle, error)
+	GetMarketNews() ([]domain.NewsArticle, error)
+	GetSectors() ([]domain.Sector, error)
+	GetSectorStocks(sector string) ([]domain.SectorStock, error)
+	GetIndustries() ([]domain.Industry, error)
+	GetIndustryStocks(industry string) ([]domain.IndustryStock, error)
+	GetScreenerStocks(request domain.ScreenerRequest) ([]domain.ScreenedStock, error)
+	GetSuperInvestors() ([]domain.SuperInvestor, error)
+	GetSuperInvestorPortfolio(superInvestorName string) (domain.SuperInvestorPortfolio, error)
+	GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error)
+}
+
+// ReferenceSet is the set of subjects the scrapers are run against, they are expected to always have complete data
+type ReferenceSet struct {
+	Symbols       []string // Stock symbols in lowercase
+	Etf           string
+	Sector        string
+	Industry      string
+	SuperInvestor string
+}
+
+var DefaultReferenceSet = ReferenceSet{
+	Symbols:       []string{"aapl", "msft", "jpm"},
+	Etf:           "spy",
+	Sector:        "technology",
+	Industry:      "semiconductors",
+	SuperInvestor: "Warren Buffett - Berkshire Hathaway",
+}
+
+// Status of a check
+const (
+	StatusOk      = "ok"
+	StatusFailed  = "failed"
+	StatusDrifted = "drifted"
+)
+
+// Result is the result of running a scraper against a subject
+type Result struct {
+	Scraper    string        `json:"scraper"`
+	Subject    string        `json:"subject,omitempty"`
+	Status     string        `json:"status"`
+	DurationMs int64         `json:"durationMs"`
+	Error      string        `json:"error,omitempty"`
+	Drift      *drift.Report `json:"drift,omitempty"`
+}
+
+// Report is the result of a canary run
+type Report struct {
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	Checks     int       `json:"checks"`
+	Failed     int       `json:"failed"`
+	Drifted    int       `json:"drifted"`
+	Results    []Result  `json:"results"`
+}
+
+// Healthy returns true if all the scrapers succeeded without drift
+func (r Report) Healthy() bool {
+	return r.Failed == 0 && r.Drifted == 0
+}
+
+type check struct {
+	scraper string
+	subject string
+	schema  drift.Schema
+	run     func() (any, error)
+}
+
+// Run runs every scraper against the reference set, one after the other to not hammer the websites.
+// The checks left when the context is done are not run.
+func Run(ctx context.Context, scraper Scraper, reference ReferenceSet) Report {
+	report := Report{StartedAt: time.Now().UTC()}
+
+	for _, c := range checks(scraper, reference) {
+		if ctx.Err() != nil {
+			break
+		}
+
+		start := time.Now()
+		response, err := c.run()
+		result := Result{
+			Scraper:    c.scraper,
+			Subject:    c.subject,
+			Status:     StatusOk,
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+
+		if err != nil {
+			result.Status = StatusFailed
+			result.Error = err.Error()
+			report.Failed++
+		} else if driftReport := drift.Validate(c.schema, response); driftReport.HasDrift() {
+			result.Status = StatusDrifted
+			result.Drift = &driftReport
+			report.Drifted++
+		}
+
+		report.Results = append(report.Results, result)
+	}
+
+	report.Checks = len(report.Results)
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	return report
+}
+
+func checks(scraper Scraper, reference ReferenceSet) []check {
+	checks := []check{
+		{"tickers", "", marketDataScraper.TickersSchema, func() (any, error) { return scraper.GetTickers() }},
+		{"etfs", "", marketDataScraper.EtfsSchema, func() (any, error) { return scraper.GetEtfs() }},
+		{"market_news", "", marketDataScraper.NewsSchema, func() (any, error) { return scraper.GetMarketNews() }},
+		{"sectors", "", marketDataScraper.SectorsSchema, func() (any, error) { return scraper.GetSectors() }},
+		{"industries", "", marketDataScraper.IndustriesSchema, func() (any, error) { return scraper.GetIndustries() }},
+		{"super_investors", "", marketDataScraper.SuperInvestorsSchema, func() (any, error) { return scraper.GetSuperInvestors() }},
+		{"screener", "", marketDataScraper.ScreenerSchema, func() (any, error) {
+			return scraper.GetScreenerStocks(domain.ScreenerRequest{
+				Columns:    []string{"market_cap", "sector"},
+				Filters:    []domain.ScreenerFilter{{Field: "market_cap", Operator: domain.GreaterThanScreenerOperator, Value: 10e9}},
+				Sort:       "market_cap",
+				Descending: true,
+			})
+		}},
+	}
+
+	if reference.Etf != "" {
+		checks = append(checks,
+			check{"etf_overview", reference.Etf, marketDataScraper.EtfOverviewSchema, func() (any, error) {
+				return scraper.GetEtfOverview(reference.Etf)
+			}},
+			check{"historical_prices", reference.Etf, marketDataScraper.HistoricalPricesSchema, func() (any, error) {
+				return scraper.GetHistoricalPrices(reference.Etf, domain.ETF, domain.Period1M)
+			}},
+		)
+	}
+	if reference.Sector != "" {
+		checks = append(checks, check{"sector_stocks", reference.Sector, marketDataScraper.SectorStocksSchema, func() (any, error) {
+			return scraper.GetSectorStocks(reference.Sector)
+		}})
+	}
+	if reference.Industry != "" {
+		checks = append(checks, check{"industry_stocks", reference.Industry, marketDataScraper.IndustryStocksSchema, func() (any, error) {
+			return scraper.GetIndustryStocks(reference.Industry)
+		}})
+	}
+	if reference.SuperInvestor != "" {
+		checks = append(checks, check{"super_investor_portfolio", reference.SuperInvestor, marketDataScraper.SuperInvestorPortfolioSchema, func() (any, error) {
+			return scraper.GetSuperInvestorPortfolio(reference.SuperInvestor)
+		}})
+	}
+
+	for _, symbol := range reference.Symbols {
+		checks = append(checks,
+			check{"balance_sheets", symbol, marketDataScraper.BalanceSheetsSchema, func() (any, error) { return scraper.GetBalanceSheets(symbol) }},
+			check{"cash_flows", symbol, marketDataScraper.CashFlowsSchema, func() (any, error) { return scraper.GetCashFlows(symbol) }},
+			check{"income_statements", symbol, marketDataScraper.IncomeStatementsSchema, func() (any, error) { return scraper.GetIncomeStatements(symbol) }},
+			check{"financial_ratios", symbol, marketDataScraper.FinancialRatiosSchema, func() (any, error) { return scraper.GetFinancialRatios(symbol) }},
+			check{"stock_forecast", symbol, marketDataScraper.StockForecastSchema, func() (any, error) { return scraper.GetStockForecast(symbol) }},
+			check{"stock_profile", symbol, marketDataScraper.StockProfileSchema, func() (any, error) { return scraper.GetStockProfile(symbol) }},
+			check{"company_kpi_metrics", symbol, marketDataScraper.CompanyKpiMetricsSchema, func() (any, error) { return scraper.GetCompanyKpiMetrics(symbol) }},
+			check{"stock_news", symbol, marketDataScraper.NewsSchema, func() (any, error) { return scraper.GetStockNews(symbol) }},
+			check{"historical_prices", symbol, marketDataScraper.HistoricalPricesSchema, func() (any, error) {
+				return scraper.GetHistoricalPrices(symbol, domain.Stock, domain.Period1M)
+			}},
+		)
+	}
+
+	return checks
+}
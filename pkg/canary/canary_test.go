@@ -0,0 +1,60 @@
+package canary_test
+
+import (
+	"context"
+	"market_data_mcp_server/pkg/canary"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/fakeupstreams"
+	"market_data_mcp_server/pkg/marketDataScraper"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRunAgainstFakeUpstreams(t *testing.T) {
+	server := httptest.NewServer(fakeupstreams.NewHandler())
+	defer server.Close()
+
+	scraper := marketDataScraper.NewMarketDataScraper(marketDataScraper.BaseURLs{
+		StockAnalysis:    server.URL + fakeupstreams.StockAnalysisPrefix,
+		StockAnalysisApi: server.URL + fakeupstreams.StockAnalysisApiPrefix,
+		Dataroma:         server.URL + fakeupstreams.DataromaPrefix,
+	})
+
+	report := canary.Run(context.Background(), scraper, canary.DefaultReferenceSet)
+	if !report.Healthy() {
+		t.Fatalf("expected a healthy report, got %+v", report.Results)
+	}
+	if report.Checks == 0 {
+		t.Fatal("expected some checks")
+	}
+}
+
+// driftedScraper returns the responses of a website that renamed its fields: no error but zero values
+type driftedScraper struct {
+	*marketDataScraper.MarketDataScraper
+}
+
+func (driftedScraper) GetBalanceSheets(symbol string) ([]domain.BalanceSheet, error) {
+	return []domain.BalanceSheet{{Datekey: "2025-06-30", FiscalYear: "2025", FiscalQuarter: "Q3"}}, nil
+}
+
+func TestRunReportsDrift(t *testing.T) {
+	server := httptest.NewServer(fakeupstreams.NewHandler())
+	defer server.Close()
+
+	scraper := driftedScraper{marketDataScraper.NewMarketDataScraper(marketDataScraper.BaseURLs{
+		StockAnalysis:    server.URL + fakeupstreams.StockAnalysisPrefix,
+		StockAnalysisApi: server.URL + fakeupstreams.StockAnalysisApiPrefix,
+		Dataroma:         server.URL + fakeupstreams.DataromaPrefix,
+	})}
+
+	report := canary.Run(context.Background(), scraper, canary.ReferenceSet{Symbols: []string{"msft"}})
+	if report.Drifted != 1 || report.Failed != 0 {
+		t.Fatalf("expected only the balance sheets to drift, got %+v", report.Results)
+	}
+	for _, result := range report.Results {
+		if result.Scraper == "balance_sheets" && (result.Status != canary.StatusDrifted || result.Drift == nil) {
+			t.Errorf("expected the balance sheets to drift, got %+v", result)
+		}
+	}
+}
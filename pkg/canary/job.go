@@ -0,0 +1,131 @@
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// metrics are published with expvar (/debug/vars) under "canary" and describe the last run
+var metrics = expvar.NewMap("canary")
+
+// PublishMetrics publishes the results of a canary run: the number of runs, the time and the counts of the
+// last run and, per scraper, the number of its checks that failed or drifted in the last run
+func PublishMetrics(report Report) {
+	metrics.Add("runs", 1)
+	setInt(metrics, "lastRunTimestamp", report.StartedAt.Unix())
+	setInt(metrics, "lastRunChecks", int64(report.Checks))
+	setInt(metrics, "lastRunFailed", int64(report.Failed))
+	setInt(metrics, "lastRunDrifted", int64(report.Drifted))
+
+	scrapers := new(expvar.Map)
+	for _, result := range report.Results {
+		if result.Status != StatusOk {
+			scrapers.Add(result.Scraper+"."+result.Status, 1)
+		}
+	}
+	metrics.Set("lastRunUnhealthyScrapers", scrapers)
+}
+
+func setInt(m *expvar.Map, key string, value int64) {
+	v := new(expvar.Int)
+	v.Set(value)
+	m.Set(key, v)
+}
+
+// WriteReport writes the report as indented JSON to the file at path
+func WriteReport(path string, report Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Job runs the canary in the background at a fixed interval, publishes the metrics of each run
+// and writes its report to a file when a report path is set
+type Job struct {
+	scraper    Scraper
+	reference  ReferenceSet
+	interval   time.Duration
+	reportPath string
+	logger     *log.Logger
+
+	mu         sync.RWMutex
+	lastReport *Report
+}
+
+func NewJob(scraper Scraper, reference ReferenceSet, interval time.Duration, reportPath string, logger *log.Logger) (*Job, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive")
+	}
+	return &Job{scraper: scraper, reference: reference, interval: interval, reportPath: reportPath, logger: logger}, nil
+}
+
+// Start runs the canary right away and then at every interval until the context is done
+func (j *Job) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			j.run(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (j *Job) run(ctx context.Context) {
+	report := Run(ctx, j.scraper, j.reference)
+	PublishMetrics(report)
+
+	j.mu.Lock()
+	j.lastReport = &report
+	j.mu.Unlock()
+
+	if report.Healthy() {
+		j.logger.Printf("Canary run completed: %d checks, no drift", report.Checks)
+	} else {
+		j.logger.Printf("Canary run completed: %d checks, %d failed, %d drifted", report.Checks, report.Failed, report.Drifted)
+	}
+
+	if j.reportPath != "" {
+		if err := WriteReport(j.reportPath, report); err != nil {
+			j.logger.Printf("Failed to write the canary report to %s: %v", j.reportPath, err)
+		}
+	}
+}
+
+// LastReport returns the report of the last run, false if the canary didn't run yet
+func (j *Job) LastReport() (Report, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if j.lastReport == nil {
+		return Report{}, false
+	}
+	return *j.lastReport, true
+}
+
+// ServeHTTP serves the report of the last run as JSON
+func (j *Job) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report, ok := j.LastReport()
+	if !ok {
+		http.Error(w, "the canary didn't run yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
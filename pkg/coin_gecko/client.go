@@ -1,24 +1,31 @@
 package coingecko
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"market_data_mcp_server/pkg/domain"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
-const coinGeckoBaseURL = "https://api.coingecko.com/api/v3"
+// DefaultBaseURL is the base url of the CoinGecko api
+const DefaultBaseURL = "https://api.coingecko.com/api/v3"
 
 type CoinGeckoClient struct {
-	apiKey string
+	apiKey  string
+	baseURL string
 }
 
-func NewCoinGeckoClient(apiKey string) (*CoinGeckoClient, error) {
-	return &CoinGeckoClient{apiKey: apiKey}, nil
+// NewCoinGeckoClient creates a client that sends its requests to the given baseURL (DefaultBaseURL when empty)
+func NewCoinGeckoClient(apiKey string, baseURL string) (*CoinGeckoClient, error) {
+	return &CoinGeckoClient{apiKey: apiKey, baseURL: strings.TrimSuffix(cmp.Or(baseURL, DefaultBaseURL), "/")}, nil
 }
 
 func (c *CoinGeckoClient) GetCryptocurrenciesList() ([]domain.Cryptocurrency, error) {
-	requestUrl := fmt.Sprintf("%s/coins/list", coinGeckoBaseURL)
+	requestUrl := fmt.Sprintf("%s/coins/list", c.baseURL)
 
 	// Add the api key in the header
 	req, err := http.NewRequest("GET", requestUrl, nil)
@@ -58,8 +65,46 @@ func (c *CoinGeckoClient) GetCryptocurrenciesList() ([]domain.Cryptocurrency, er
 	return cryptocurrenciesList, nil
 }
 
+// GetCryptocurrenciesMarketCaps returns the USD market cap of the top cryptocurrencies by market cap, keyed by id
+func (c *CoinGeckoClient) GetCryptocurrenciesMarketCaps() (map[string]float64, error) {
+	requestUrl := fmt.Sprintf("%s/coins/markets?vs_currency=usd&order=market_cap_desc&per_page=250&page=1", c.baseURL)
+
+	// Add the api key in the header
+	req, err := http.NewRequest("GET", requestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-cg-demo-api-key", c.apiKey)
+
+	// Send the request
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Check if the request was successful
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get coins markets: %s", resp.Status)
+	}
+
+	// Parse the response
+	var coinsMarkets []CoinGeckoCoinMarket
+	if err := json.NewDecoder(resp.Body).Decode(&coinsMarkets); err != nil {
+		return nil, err
+	}
+
+	marketCaps := make(map[string]float64, len(coinsMarkets))
+	for _, coin := range coinsMarkets {
+		marketCaps[coin.Id] = coin.MarketCap
+	}
+
+	return marketCaps, nil
+}
+
 func (c *CoinGeckoClient) GetCryptocurrencyDataById(id string) (domain.CryptocurrencyData, error) {
-	requestUrl := fmt.Sprintf("%s/coins/%s", coinGeckoBaseURL, id)
+	requestUrl := fmt.Sprintf("%s/coins/%s", c.baseURL, id)
 
 	// Add the api key in the header
 	req, err := http.NewRequest("GET", requestUrl, nil)
@@ -110,3 +155,56 @@ func (c *CoinGeckoClient) GetCryptocurrencyDataById(id string) (domain.Cryptocur
 
 	return cryptocurrencyData, nil
 }
+
+// GetMarketChart returns the USD prices and the 24h volumes of the last days of a cryptocurrency, of its whole
+// history when days is 0. CoinGecko picks the granularity: 5 minutes for a day, hourly up to 90 days, daily beyond.
+func (c *CoinGeckoClient) GetMarketChart(id string, days int) ([]domain.Price, error) {
+	daysParam := "max"
+	if days > 0 {
+		daysParam = strconv.Itoa(days)
+	}
+	requestUrl := fmt.Sprintf("%s/coins/%s/market_chart?vs_currency=usd&days=%s", c.baseURL, id, daysParam)
+
+	// Add the api key in the header
+	req, err := http.NewRequest("GET", requestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-cg-demo-api-key", c.apiKey)
+
+	// Send the request
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Check if the request was successful
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get market chart: %s", resp.Status)
+	}
+
+	// Parse the response
+	var marketChart CoinGeckoMarketChart
+	if err := json.NewDecoder(resp.Body).Decode(&marketChart); err != nil {
+		return nil, err
+	}
+
+	// The volumes have the timestamps of the prices
+	volumes := make(map[float64]float64, len(marketChart.TotalVolumes))
+	for _, volume := range marketChart.TotalVolumes {
+		volumes[volume[0]] = volume[1]
+	}
+
+	prices := make([]domain.Price, 0, len(marketChart.Prices))
+	for _, price := range marketChart.Prices {
+		prices = append(prices, domain.Price{
+			Date:       time.UnixMilli(int64(price[0])).UTC(),
+			ClosePrice: price[1],
+			Volume:     volumes[price[0]],
+		})
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,50 @@
+package coingecko
+
+import (
+	"market_data_mcp_server/pkg/httpreplay"
+	"path/filepath"
+	"testing"
+)
+
+func TestCoinGeckoClient(t *testing.T) {
+	client, err := NewCoinGeckoClient("test-key", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() (any, error)
+	}{
+		{"cryptocurrencies_list", func() (any, error) { return client.GetCryptocurrenciesList() }},
+		{"cryptocurrencies_market_caps", func() (any, error) { return client.GetCryptocurrenciesMarketCaps() }},
+		{"cryptocurrency_data", func() (any, error) { return client.GetCryptocurrencyDataById("bitcoin") }},
+		{"market_chart", func() (any, error) { return client.GetMarketChart("bitcoin", 3) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreplay.UseCassette(t, filepath.Join("testdata", "cassettes", tt.name+".json"))
+
+			got, err := tt.call()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			httpreplay.AssertGolden(t, filepath.Join("testdata", "golden", tt.name+".json"), got)
+		})
+	}
+}
+
+func TestGetCryptocurrencyDataByIdNotFound(t *testing.T) {
+	httpreplay.UseCassette(t, filepath.Join("testdata", "cassettes", "cryptocurrency_data_not_found.json"))
+
+	client, err := NewCoinGeckoClient("test-key", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.GetCryptocurrencyDataById("not-a-coin"); err == nil {
+		t.Fatal("expected an error for an unknown cryptocurrency")
+	}
+}
@@ -1,20 +1,24 @@
 package coingecko
 
 import (
+	"cmp"
 	"fmt"
 	"market_data_mcp_server/pkg/domain"
 	"market_data_mcp_server/pkg/services"
+	"strings"
 	"time"
 )
 
 type CoinGeckoClientWithCache struct {
 	apiKey          string
+	baseURL         string
 	cache           services.CacheService
 	cacheTtlSeconds int
 }
 
-func NewCoinGeckoClientWithCache(apiKey string, cache services.CacheService, cacheTtlSeconds int) (*CoinGeckoClientWithCache, error) {
-	return &CoinGeckoClientWithCache{apiKey: apiKey, cache: cache, cacheTtlSeconds: cacheTtlSeconds}, nil
+func NewCoinGeckoClientWithCache(apiKey string, baseURL string, cache services.CacheService, cacheTtlSeconds int) (*CoinGeckoClientWithCache, error) {
+	baseURL = strings.TrimSuffix(cmp.Or(baseURL, DefaultBaseURL), "/")
+	return &CoinGeckoClientWithCache{apiKey: apiKey, baseURL: baseURL, cache: services.NamespacedCache(cache, baseURL), cacheTtlSeconds: cacheTtlSeconds}, nil
 }
 
 func (c *CoinGeckoClientWithCache) GetCryptocurrenciesList() ([]domain.Cryptocurrency, error) {
@@ -28,7 +32,7 @@ func (c *CoinGeckoClientWithCache) GetCryptocurrenciesList() ([]domain.Cryptocur
 	}
 
 	// If not in cache, get from API
-	coinGeckoClient := CoinGeckoClient{apiKey: c.apiKey}
+	coinGeckoClient := CoinGeckoClient{apiKey: c.apiKey, baseURL: c.baseURL}
 	cryptocurrenciesList, err = coinGeckoClient.GetCryptocurrenciesList()
 	if err != nil {
 		return nil, err
@@ -43,6 +47,32 @@ func (c *CoinGeckoClientWithCache) GetCryptocurrenciesList() ([]domain.Cryptocur
 	return cryptocurrenciesList, nil
 }
 
+func (c *CoinGeckoClientWithCache) GetCryptocurrenciesMarketCaps() (map[string]float64, error) {
+	// Check if the data is in the cache
+	var marketCaps map[string]float64
+
+	key := "cryptocurrencies_market_caps"
+	err := c.cache.Get(key, &marketCaps)
+	if err == nil {
+		return marketCaps, nil
+	}
+
+	// If not in cache, get from API
+	coinGeckoClient := CoinGeckoClient{apiKey: c.apiKey, baseURL: c.baseURL}
+	marketCaps, err = coinGeckoClient.GetCryptocurrenciesMarketCaps()
+	if err != nil {
+		return nil, err
+	}
+
+	// Set in cache
+	err = c.cache.Set(key, marketCaps, time.Duration(c.cacheTtlSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return marketCaps, nil
+}
+
 func (c *CoinGeckoClientWithCache) GetCryptocurrencyDataById(id string) (domain.CryptocurrencyData, error) {
 	// Check if the data is in the cache
 	var cryptocurrencyData domain.CryptocurrencyData
@@ -54,7 +84,7 @@ func (c *CoinGeckoClientWithCache) GetCryptocurrencyDataById(id string) (domain.
 	}
 
 	// If not in cache, get from API
-	coinGeckoClient := CoinGeckoClient{apiKey: c.apiKey}
+	coinGeckoClient := CoinGeckoClient{apiKey: c.apiKey, baseURL: c.baseURL}
 	cryptocurrencyData, err = coinGeckoClient.GetCryptocurrencyDataById(id)
 	if err != nil {
 		return domain.CryptocurrencyData{}, err
@@ -68,3 +98,29 @@ func (c *CoinGeckoClientWithCache) GetCryptocurrencyDataById(id string) (domain.
 
 	return cryptocurrencyData, nil
 }
+
+func (c *CoinGeckoClientWithCache) GetMarketChart(id string, days int) ([]domain.Price, error) {
+	// Check if the data is in the cache
+	var prices []domain.Price
+
+	key := fmt.Sprintf("market_chart_%s_%d", id, days)
+	err := c.cache.Get(key, &prices)
+	if err == nil {
+		return prices, nil
+	}
+
+	// If not in cache, get from API
+	coinGeckoClient := CoinGeckoClient{apiKey: c.apiKey, baseURL: c.baseURL}
+	prices, err = coinGeckoClient.GetMarketChart(id, days)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set in cache
+	err = c.cache.Set(key, prices, time.Duration(c.cacheTtlSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
@@ -40,3 +40,14 @@ type CoinGeckoCoinData struct {
 		MaxSupply   *float64 `json:"max_supply"`
 	} `json:"market_data"`
 }
+
+type CoinGeckoCoinMarket struct {
+	Id        string  `json:"id"`
+	MarketCap float64 `json:"market_cap"`
+}
+
+// CoinGeckoMarketChart has the points of a market chart as [timestamp in milliseconds, value]
+type CoinGeckoMarketChart struct {
+	Prices       [][2]float64 `json:"prices"`
+	TotalVolumes [][2]float64 `json:"total_volumes"`
+}
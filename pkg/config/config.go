@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -13,14 +14,47 @@ type Config struct {
 
 	// Alpha Vantage configs
 	AlphaVantageApiKey   string
-	AlphaVantageCacheTtl int // The ttl for the alpha vantage cache in seconds
+	AlphaVantageCacheTtl int    // The ttl for the alpha vantage cache in seconds
+	AlphaVantageBaseURL  string // The url of the alpha vantage query endpoint, the default one when empty
 
 	// CoinGecko configs
 	CoinGeckoApiKey   string
-	CoinGeckoCacheTtl int // The ttl for the coin gecko cache in seconds
+	CoinGeckoCacheTtl int    // The ttl for the coin gecko cache in seconds
+	CoinGeckoBaseURL  string // The base url of the coin gecko api, the default one when empty
+
+	// Scraped websites configs, the default base urls are used when empty
+	StockAnalysisBaseURL    string
+	StockAnalysisApiBaseURL string
+	DataromaBaseURL         string
 
 	// Investing ideas configs
 	InvestingIdeasDataPath string
+
+	// User contexts configs
+	UserContextDataPath  string // File the user contexts and their history are stored in, only kept in memory when empty
+	TransactionsDataPath string // File the transaction ledgers are stored in, only kept in memory when empty
+	WatchlistsDataPath   string // File the watchlists are stored in, only kept in memory when empty
+	AlertsDataPath       string // File the alerts, their state and their events are stored in, only kept in memory when empty
+
+	// HTTP record/replay configs, used to capture the upstream responses of an incident and replay them offline
+	HttpRecordPath string // Cassette file to record all the upstream responses to
+	HttpReplayPath string // Cassette file to serve all the upstream responses from (no request reaches the network)
+
+	// Canary configs, the background canary checking the scrapers for schema drift
+	CanaryIntervalMinutes int      // The interval between two canary runs, the canary doesn't run when 0
+	CanaryReportPath      string   // File the JSON report of the last canary run is written to, not written when empty
+	CanarySymbols         []string // Reference stock symbols of the canary, the default ones when empty
+
+	// Cache warmer configs, the schedules are cron expressions (e.g. "*/30 * * * *") or "@every <duration>"
+	WarmerMarketSchedule  string   // Schedule of the sectors, industries, tickers and market news prefetch, disabled when empty
+	WarmerSymbolsSchedule string   // Schedule of the overview and financials prefetch of the symbols, disabled when empty
+	WarmerWatchlist       []string // Stock symbols always prefetched
+	WarmerTopSymbols      int      // Number of most requested stock symbols prefetched on top of the watchlist
+	WarmerRatePerMinute   int      // Maximum upstream calls per minute of the warmer, unlimited when 0
+
+	// Alerts configs, the schedule is a cron expression or "@every <duration>" like the warmer ones
+	AlertsSchedule        string   // Schedule of the evaluation of the alerts of all the users, only evaluated by checkAlerts when empty
+	AlertsWebhookNetworks []string // CIDRs of the private networks the webhooks may reach, only the public addresses when empty
 }
 
 func LoadConfig() (Config, error) {
@@ -42,14 +76,50 @@ func LoadConfig() (Config, error) {
 		coinGeckoCacheTtl = 3600
 	}
 
+	canaryIntervalMinutes, err := strconv.Atoi(getEnv("CANARY_INTERVAL_MINUTES", "0"))
+	if err != nil {
+		canaryIntervalMinutes = 0
+	}
+
+	warmerTopSymbols, err := strconv.Atoi(getEnv("WARMER_TOP_SYMBOLS", "20"))
+	if err != nil {
+		warmerTopSymbols = 20
+	}
+
+	warmerRatePerMinute, err := strconv.Atoi(getEnv("WARMER_RATE_PER_MINUTE", "30"))
+	if err != nil {
+		warmerRatePerMinute = 30
+	}
+
 	return Config{
-		Port:                   getEnv("PORT", "8080"),
-		CacheTtl:               cacheTtl,
-		AlphaVantageApiKey:     getEnv("ALPHA_VANTAGE_API_KEY", ""),
-		AlphaVantageCacheTtl:   alphaVantageCacheTtl,
-		CoinGeckoApiKey:        getEnv("COIN_GECKO_API_KEY", ""),
-		CoinGeckoCacheTtl:      coinGeckoCacheTtl,
-		InvestingIdeasDataPath: getEnv("INVESTING_IDEAS_DATA_PATH", "static_data/investing_ideas.json"),
+		Port:                    getEnv("PORT", "8080"),
+		CacheTtl:                cacheTtl,
+		AlphaVantageApiKey:      getEnv("ALPHA_VANTAGE_API_KEY", ""),
+		AlphaVantageCacheTtl:    alphaVantageCacheTtl,
+		AlphaVantageBaseURL:     getEnv("ALPHA_VANTAGE_BASE_URL", ""),
+		CoinGeckoApiKey:         getEnv("COIN_GECKO_API_KEY", ""),
+		CoinGeckoCacheTtl:       coinGeckoCacheTtl,
+		CoinGeckoBaseURL:        getEnv("COIN_GECKO_BASE_URL", ""),
+		StockAnalysisBaseURL:    getEnv("STOCK_ANALYSIS_BASE_URL", ""),
+		StockAnalysisApiBaseURL: getEnv("STOCK_ANALYSIS_API_BASE_URL", ""),
+		DataromaBaseURL:         getEnv("DATAROMA_BASE_URL", ""),
+		InvestingIdeasDataPath:  getEnv("INVESTING_IDEAS_DATA_PATH", "static_data/investing_ideas.json"),
+		UserContextDataPath:     getEnv("USER_CONTEXT_DATA_PATH", "data/user_contexts.json"),
+		TransactionsDataPath:    getEnv("TRANSACTIONS_DATA_PATH", "data/transactions.json"),
+		WatchlistsDataPath:      getEnv("WATCHLISTS_DATA_PATH", "data/watchlists.json"),
+		AlertsDataPath:          getEnv("ALERTS_DATA_PATH", "data/alerts.json"),
+		HttpRecordPath:          getEnv("HTTP_RECORD_PATH", ""),
+		HttpReplayPath:          getEnv("HTTP_REPLAY_PATH", ""),
+		CanaryIntervalMinutes:   canaryIntervalMinutes,
+		CanaryReportPath:        getEnv("CANARY_REPORT_PATH", ""),
+		CanarySymbols:           getEnvSymbols("CANARY_SYMBOLS"),
+		WarmerMarketSchedule:    getEnv("WARMER_MARKET_SCHEDULE", ""),
+		WarmerSymbolsSchedule:   getEnv("WARMER_SYMBOLS_SCHEDULE", ""),
+		WarmerWatchlist:         getEnvSymbols("WARMER_WATCHLIST"),
+		WarmerTopSymbols:        warmerTopSymbols,
+		WarmerRatePerMinute:     warmerRatePerMinute,
+		AlertsSchedule:          getEnv("ALERTS_SCHEDULE", ""),
+		AlertsWebhookNetworks:   getEnvList("ALERTS_WEBHOOK_ALLOWED_NETWORKS"),
 	}, nil
 }
 
@@ -60,6 +130,28 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvSymbols returns the comma separated stock symbols of the variable in lowercase
+func getEnvSymbols(key string) []string {
+	var symbols []string
+	for _, symbol := range strings.Split(getEnv(key, ""), ",") {
+		if symbol = strings.ToLower(strings.TrimSpace(symbol)); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// getEnvList returns the comma separated values of the variable
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvFloat32(key string, fallback float32) float32 {
 	if value, exists := os.LookupEnv(key); exists {
 		if floatValue, err := strconv.ParseFloat(value, 32); err == nil {
@@ -0,0 +1,428 @@
+// Package devalue decodes the devalue format that SvelteKit uses to serialize the data of its pages
+// (the nodes of the __data.json responses) into Go values.
+//
+// The data of a node is a flat array of values: the first one is the root and every object property and
+// array item is the index of its value in the array. The decoder resolves these references into structs
+// whose fields are mapped with `devalue` struct tags:
+//
+//	type sector struct {
+//		Name      string  `devalue:"sector_name,required"`
+//		MarketCap float64 `devalue:"marketCap"`
+//	}
+//
+// The fields without an exact match of their name fall back to a case-insensitive match, like encoding/json.
+// Missing, null and undefined values leave the field to its zero value unless the field is required.
+// Values that don't have the expected type fail the decoding with an errors.DevalueDecodeError
+// naming the path of the value instead of panicking. Fields of type any get the fully resolved value and
+// the types implementing Unmarshaler decode it themselves.
+package devalue
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"market_data_mcp_server/pkg/errors"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Special references of devalue
+const (
+	undefined        = -1
+	hole             = -2
+	notANumber       = -3
+	positiveInfinity = -4
+	negativeInfinity = -5
+	negativeZero     = -6
+)
+
+// maxDepth guards against cyclic references, which devalue supports but the decoded Go values can't represent
+const maxDepth = 1000
+
+// Unmarshaler is implemented by the types that decode their fully resolved value (maps, slices and primitives)
+// themselves, e.g. the numbers that the website can replace with a placeholder string. The error is returned as
+// an errors.DevalueDecodeError with the path of the value.
+type Unmarshaler interface {
+	UnmarshalDevalue(value any) error
+}
+
+var unmarshalerType = reflect.TypeFor[Unmarshaler]()
+
+// UnmarshalNode decodes the data of the node with the given index of a SvelteKit __data.json response into v
+func UnmarshalNode(body []byte, node int, v any) error {
+	var response struct {
+		Nodes []*struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return errors.JSONMarshalError{Message: "failed to decode __data.json response", Err: err}
+	}
+
+	path := fmt.Sprintf("nodes[%d]", node)
+	if node < 0 || node >= len(response.Nodes) || response.Nodes[node] == nil {
+		return errors.DevalueDecodeError{Path: path, Message: fmt.Sprintf("node not found in a response with %d nodes", len(response.Nodes))}
+	}
+	if response.Nodes[node].Type != "data" {
+		return errors.DevalueDecodeError{Path: path, Message: fmt.Sprintf("expected a data node, got %q", response.Nodes[node].Type)}
+	}
+
+	return unmarshal(response.Nodes[node].Data, path, v)
+}
+
+// Unmarshal decodes the devalue encoded data (the data array of a node) into v, which must be a non nil pointer
+func Unmarshal(data []byte, v any) error {
+	return unmarshal(data, "$", v)
+}
+
+func unmarshal(data []byte, rootPath string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("devalue: Unmarshal requires a non nil pointer, got %T", v)
+	}
+
+	var values []any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return errors.JSONMarshalError{Message: "failed to decode devalue data", Err: err}
+	}
+	if len(values) == 0 {
+		return errors.DevalueDecodeError{Path: rootPath, Message: "the data is empty"}
+	}
+
+	d := decoder{values: values}
+	return d.decodeValue(values[0], rv.Elem(), rootPath, 0)
+}
+
+type decoder struct {
+	values []any
+}
+
+// decodeReference decodes the value the given reference (index in the values) points to
+func (d *decoder) decodeReference(ref any, v reflect.Value, path string, depth int) error {
+	index, err := d.index(ref, path)
+	if err != nil {
+		return err
+	}
+
+	switch index {
+	case undefined, hole, notANumber, positiveInfinity, negativeInfinity:
+		// NaN and infinities can't be represented in JSON, which is where all the decoded values end up
+		v.SetZero()
+		return nil
+	case negativeZero:
+		return d.decodeValue(0.0, v, path, depth+1)
+	}
+
+	return d.decodeValue(d.values[index], v, path, depth+1)
+}
+
+func (d *decoder) decodeValue(raw any, v reflect.Value, path string, depth int) error {
+	if depth > maxDepth {
+		return errors.DevalueDecodeError{Path: path, Message: "the value is nested too deeply, the references might be cyclic"}
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if raw == nil {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decodeValue(raw, v.Elem(), path, depth)
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		resolved, err := d.resolve(raw, path, depth)
+		if err != nil {
+			return err
+		}
+		if err := v.Addr().Interface().(Unmarshaler).UnmarshalDevalue(resolved); err != nil {
+			return errors.DevalueDecodeError{Path: path, Message: err.Error()}
+		}
+		return nil
+	}
+
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		resolved, err := d.resolve(raw, path, depth)
+		if err != nil {
+			return err
+		}
+		if resolved == nil {
+			v.SetZero()
+		} else {
+			v.Set(reflect.ValueOf(resolved))
+		}
+		return nil
+	}
+
+	switch raw := raw.(type) {
+	case nil:
+		v.SetZero()
+		return nil
+	case string:
+		if v.Kind() == reflect.String {
+			v.SetString(raw)
+			return nil
+		}
+	case bool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(raw)
+			return nil
+		}
+	case float64:
+		return setNumber(v, raw, path)
+	case map[string]any:
+		return d.decodeObject(raw, v, path, depth)
+	case []any:
+		if typeName, ok := specialType(raw); ok {
+			return d.decodeSpecial(typeName, raw, v, path, depth)
+		}
+		if v.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(v.Type(), len(raw), len(raw))
+			for i, ref := range raw {
+				if err := d.decodeReference(ref, slice.Index(i), fmt.Sprintf("%s[%d]", path, i), depth); err != nil {
+					return err
+				}
+			}
+			v.Set(slice)
+			return nil
+		}
+	}
+
+	return typeError(raw, v.Type(), path)
+}
+
+func (d *decoder) decodeObject(object map[string]any, v reflect.Value, path string, depth int) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, field := range structFields(v.Type()) {
+			fieldPath := path + "." + field.name
+			ref, ok := lookupField(object, field.name)
+			if !ok {
+				if field.required {
+					return errors.DevalueDecodeError{Path: fieldPath, Message: "required field is missing"}
+				}
+				continue
+			}
+			if field.required && d.isNull(ref) {
+				return errors.DevalueDecodeError{Path: fieldPath, Message: "required field is null"}
+			}
+			if err := d.decodeReference(ref, v.Field(field.index), fieldPath, depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return typeError(object, v.Type(), path)
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(object))
+		// Sorted so that the first failing key is always the same one
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decodeReference(object[key], elem, path+"."+key, depth); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		v.Set(m)
+		return nil
+	default:
+		return typeError(object, v.Type(), path)
+	}
+}
+
+// decodeSpecial decodes the values of the types that devalue encodes as an array starting with the type name
+func (d *decoder) decodeSpecial(typeName string, raw []any, v reflect.Value, path string, depth int) error {
+	switch typeName {
+	case "Date":
+		// ["Date", "2024-10-09T00:00:00.000Z"], the ISO string is kept as is
+		if len(raw) == 2 {
+			return d.decodeValue(raw[1], v, path, depth)
+		}
+	case "null":
+		// ["null", key1, ref1, key2, ref2, ...] is an object without prototype
+		if len(raw)%2 == 1 {
+			object := make(map[string]any, len(raw)/2)
+			for i := 1; i < len(raw); i += 2 {
+				key, ok := raw[i].(string)
+				if !ok {
+					return errors.DevalueDecodeError{Path: path, Message: fmt.Sprintf("invalid key %v of an object without prototype", raw[i])}
+				}
+				object[key] = raw[i+1]
+			}
+			return d.decodeObject(object, v, path, depth)
+		}
+	default:
+		return errors.DevalueDecodeError{Path: path, Message: fmt.Sprintf("unsupported devalue type %q", typeName)}
+	}
+
+	return errors.DevalueDecodeError{Path: path, Message: fmt.Sprintf("invalid devalue %s", typeName)}
+}
+
+// resolve resolves all the references of the raw value into plain maps, slices and primitives
+func (d *decoder) resolve(raw any, path string, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.DevalueDecodeError{Path: path, Message: "the value is nested too deeply, the references might be cyclic"}
+	}
+
+	switch raw := raw.(type) {
+	case map[string]any:
+		var resolved map[string]any
+		v := reflect.ValueOf(&resolved).Elem()
+		if err := d.decodeObject(raw, v, path, depth); err != nil {
+			return nil, err
+		}
+		return resolved, nil
+	case []any:
+		if typeName, ok := specialType(raw); ok {
+			if typeName == "Date" && len(raw) == 2 {
+				return raw[1], nil
+			}
+			var object map[string]any
+			if err := d.decodeSpecial(typeName, raw, reflect.ValueOf(&object).Elem(), path, depth); err != nil {
+				return nil, err
+			}
+			return object, nil
+		}
+		items := make([]any, len(raw))
+		for i, ref := range raw {
+			if err := d.decodeReference(ref, reflect.ValueOf(&items[i]).Elem(), fmt.Sprintf("%s[%d]", path, i), depth); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return raw, nil
+	}
+}
+
+// index returns the index in the values of a reference, or the special reference it is
+func (d *decoder) index(ref any, path string) (int, error) {
+	f, ok := ref.(float64)
+	if !ok || f != math.Trunc(f) {
+		return 0, errors.DevalueDecodeError{Path: path, Message: fmt.Sprintf("expected a reference, got %s", describe(ref))}
+	}
+
+	index := int(f)
+	if index >= len(d.values) || index < negativeZero {
+		return 0, errors.DevalueDecodeError{Path: path, Message: fmt.Sprintf("reference %d is out of range of %d values", index, len(d.values))}
+	}
+	return index, nil
+}
+
+// isNull returns true if the reference is undefined or points to a null value
+func (d *decoder) isNull(ref any) bool {
+	index, err := d.index(ref, "")
+	if err != nil {
+		return false
+	}
+	return index == undefined || index == hole || (index >= 0 && d.values[index] == nil)
+}
+
+func setNumber(v reflect.Value, number float64, path string) error {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(number)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if number != math.Trunc(number) || v.OverflowInt(int64(number)) {
+			return errors.DevalueDecodeError{Path: path, Message: fmt.Sprintf("number %v doesn't fit in %s", number, v.Type())}
+		}
+		v.SetInt(int64(number))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if number < 0 || number != math.Trunc(number) || v.OverflowUint(uint64(number)) {
+			return errors.DevalueDecodeError{Path: path, Message: fmt.Sprintf("number %v doesn't fit in %s", number, v.Type())}
+		}
+		v.SetUint(uint64(number))
+		return nil
+	default:
+		return typeError(number, v.Type(), path)
+	}
+}
+
+// lookupField returns the reference of the property with the given name, or of the first one (in sorted order)
+// with the same name ignoring the case when there is no exact match
+func lookupField(object map[string]any, name string) (any, bool) {
+	if ref, ok := object[name]; ok {
+		return ref, true
+	}
+	match, found := "", false
+	for key := range object {
+		if strings.EqualFold(key, name) && (!found || key < match) {
+			match, found = key, true
+		}
+	}
+	return object[match], found
+}
+
+// specialType returns the type name of the values that devalue encodes as an array starting with
+// the type name (e.g. ["Date", "..."]), the items of plain arrays are always references (numbers)
+func specialType(raw []any) (string, bool) {
+	if len(raw) == 0 {
+		return "", false
+	}
+	typeName, ok := raw[0].(string)
+	return typeName, ok
+}
+
+func typeError(raw any, t reflect.Type, path string) error {
+	return errors.DevalueDecodeError{Path: path, Message: fmt.Sprintf("cannot decode %s into %s", describe(raw), t)}
+}
+
+func describe(raw any) string {
+	switch raw := raw.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("string %q", raw)
+	case float64:
+		return fmt.Sprintf("number %v", raw)
+	case bool:
+		return fmt.Sprintf("bool %v", raw)
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", raw)
+	}
+}
+
+type field struct {
+	index    int
+	name     string
+	required bool
+}
+
+// structFields returns the decodable fields of a struct type with the name (and options) of their devalue tag
+func structFields(t reflect.Type) []field {
+	fields := make([]field, 0, t.NumField())
+	for i := range t.NumField() {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		tag := structField.Tag.Get("devalue")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = structField.Name
+		}
+
+		fields = append(fields, field{
+			index:    i,
+			name:     name,
+			required: slices.Contains(strings.Split(options, ","), "required"),
+		})
+	}
+	return fields
+}
@@ -0,0 +1,201 @@
+package devalue
+
+import (
+	stderrors "errors"
+	"fmt"
+	"market_data_mcp_server/pkg/errors"
+	"reflect"
+	"testing"
+)
+
+type sector struct {
+	Name      string   `devalue:"sector_name,required"`
+	Stocks    int      `devalue:"stocks"`
+	MarketCap float32  `devalue:"marketCap"`
+	PeRatio   *float64 `devalue:"peRatio"`
+	Ignored   string   `devalue:"-"`
+}
+
+type sectorsPage struct {
+	Sectors []sector `devalue:"sectors,required"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	data := `[{"sectors":1},[2,6],{"sector_name":3,"stocks":4,"marketCap":5},"Technology",815,1.5e13,{"sector_name":7,"stocks":-6,"peRatio":8,"marketCap":-1},"Energy",21.5]`
+
+	var got sectorsPage
+	if err := Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	peRatio := 21.5
+	want := sectorsPage{Sectors: []sector{
+		{Name: "Technology", Stocks: 815, MarketCap: 1.5e13},
+		{Name: "Energy", PeRatio: &peRatio},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestUnmarshalAny(t *testing.T) {
+	data := `[{"columns":1,"date":5},{"eps":2},[3,-1,4],1.25,"[PRO]",["Date","2025-06-30T00:00:00.000Z"]]`
+
+	var got struct {
+		Columns map[string]any `devalue:"columns"`
+		Date    any            `devalue:"date"`
+	}
+	if err := Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantColumns := map[string]any{"eps": []any{1.25, nil, "[PRO]"}}
+	if !reflect.DeepEqual(got.Columns, wantColumns) {
+		t.Errorf("got columns %v, want %v", got.Columns, wantColumns)
+	}
+	if got.Date != "2025-06-30T00:00:00.000Z" {
+		t.Errorf("got date %v, want the ISO string", got.Date)
+	}
+}
+
+// lockedNumber is a number or the "[PRO]" placeholder of the values reserved to subscribers
+type lockedNumber struct {
+	Value  float64
+	Locked bool
+}
+
+func (n *lockedNumber) UnmarshalDevalue(value any) error {
+	switch value := value.(type) {
+	case float64:
+		n.Value = value
+		return nil
+	case string:
+		if value == "[PRO]" {
+			n.Locked = true
+			return nil
+		}
+	}
+	return fmt.Errorf("expected a number or [PRO], got %v", value)
+}
+
+func TestUnmarshalUnmarshaler(t *testing.T) {
+	var got struct {
+		Eps []lockedNumber `devalue:"eps"`
+	}
+	if err := Unmarshal([]byte(`[{"eps":1},[2,3],1.25,"[PRO]"]`), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []lockedNumber{{Value: 1.25}, {Locked: true}}; !reflect.DeepEqual(got.Eps, want) {
+		t.Errorf("got %+v, want %+v", got.Eps, want)
+	}
+
+	err := Unmarshal([]byte(`[{"eps":1},[2,3],1.25,"n/a"]`), &got)
+	var decodeErr errors.DevalueDecodeError
+	if !stderrors.As(err, &decodeErr) || decodeErr.Path != "$.eps[1]" {
+		t.Errorf("expected a DevalueDecodeError at $.eps[1], got %v", err)
+	}
+}
+
+func TestUnmarshalCaseInsensitiveFields(t *testing.T) {
+	var got struct {
+		EpsDil   float64
+		Datekey  string `devalue:"dateKey"`
+		Revenue  float64
+		Currency string
+	}
+	if err := Unmarshal([]byte(`[{"epsdil":1,"datekey":2,"revenue":3},1.5,"2025-06-30",10]`), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.EpsDil != 1.5 || got.Datekey != "2025-06-30" || got.Revenue != 10 || got.Currency != "" {
+		t.Errorf("unexpected %+v", got)
+	}
+}
+
+func TestUnmarshalNode(t *testing.T) {
+	body := `{"type":"data","nodes":[{"type":"data","data":[{}]},null,{"type":"data","data":[{"sectors":1},[2],{"sector_name":3},"Utilities"]}]}`
+
+	var got sectorsPage
+	if err := UnmarshalNode([]byte(body), 2, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Sectors) != 1 || got.Sectors[0].Name != "Utilities" {
+		t.Errorf("unexpected sectors %+v", got.Sectors)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		node     int
+		wantPath string
+	}{
+		{
+			name:     "missing node",
+			body:     `{"type":"data","nodes":[{"type":"data","data":[{}]}]}`,
+			node:     2,
+			wantPath: "nodes[2]",
+		},
+		{
+			name:     "type mismatch",
+			body:     `{"nodes":[null,null,{"type":"data","data":[{"sectors":1},[2,4],{"sector_name":3},"Utilities",{"sector_name":5},12]}]}`,
+			node:     2,
+			wantPath: "nodes[2].sectors[1].sector_name",
+		},
+		{
+			name:     "missing required field",
+			body:     `{"nodes":[null,null,{"type":"data","data":[{"industries":1},[]]}]}`,
+			node:     2,
+			wantPath: "nodes[2].sectors",
+		},
+		{
+			name:     "null required field",
+			body:     `{"nodes":[null,null,{"type":"data","data":[{"sectors":1},[2],{"sector_name":-1}]}]}`,
+			node:     2,
+			wantPath: "nodes[2].sectors[0].sector_name",
+		},
+		{
+			name:     "reference out of range",
+			body:     `{"nodes":[null,null,{"type":"data","data":[{"sectors":1},[2],{"sector_name":42}]}]}`,
+			node:     2,
+			wantPath: "nodes[2].sectors[0].sector_name",
+		},
+		{
+			name:     "fractional integer",
+			body:     `{"nodes":[null,null,{"type":"data","data":[{"sectors":1},[2],{"sector_name":3,"stocks":4},"Energy",1.5]}]}`,
+			node:     2,
+			wantPath: "nodes[2].sectors[0].stocks",
+		},
+		{
+			name:     "array instead of object",
+			body:     `{"nodes":[null,null,{"type":"data","data":[{"sectors":1},[1]]}]}`,
+			node:     2,
+			wantPath: "nodes[2].sectors[0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got sectorsPage
+			err := UnmarshalNode([]byte(tt.body), tt.node, &got)
+
+			var decodeErr errors.DevalueDecodeError
+			if !stderrors.As(err, &decodeErr) {
+				t.Fatalf("expected a DevalueDecodeError, got %v", err)
+			}
+			if decodeErr.Path != tt.wantPath {
+				t.Errorf("got path %q, want %q (%v)", decodeErr.Path, tt.wantPath, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalCyclicReferences(t *testing.T) {
+	var got any
+	err := Unmarshal([]byte(`[{"self":0}]`), &got)
+
+	var decodeErr errors.DevalueDecodeError
+	if !stderrors.As(err, &decodeErr) {
+		t.Fatalf("expected a DevalueDecodeError, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package domain
+
+type AlertType string
+
+const (
+	AlertPriceAbove  AlertType = "price_above"
+	AlertPriceBelow  AlertType = "price_below"
+	AlertPercentMove AlertType = "percent_move"
+	AlertRatioAbove  AlertType = "ratio_above"
+	AlertRatioBelow  AlertType = "ratio_below"
+	AlertInsiderBuy  AlertType = "insider_buy"
+	AlertNewsMention AlertType = "news_mention"
+)
+
+// Alert is a rule of a user evaluated on a schedule. The fields used depend on the type: the price alerts have a
+// threshold price, the percent moves a threshold percentage over a period (negative for a drop), the ratio alerts
+// a ratio of the financial ratios and a threshold, the news mentions an optional keyword.
+type Alert struct {
+	ID              int // Sequential per user
+	UserID          string
+	Type            AlertType
+	Symbol          string
+	AssetClass      AssetClass
+	Threshold       float64
+	Period          Period // Of the percent moves
+	Ratio           string // Of the ratio alerts, e.g. pe
+	Keyword         string // Of the news mentions, every new article when empty
+	CooldownMinutes int    // Minimum time between two triggers
+	WebhookURL      string // Also notified by a POST of the events when set
+	Note            string
+	CreatedAt       string // ISO 8601 format
+	State           AlertState
+}
+
+// AlertState is what an alert remembers between its evaluations to trigger once per event: the threshold alerts
+// trigger when their condition becomes true, the insider buys and the news mentions when new items appear.
+type AlertState struct {
+	Active          bool     // The condition held on the last evaluation
+	Primed          bool     // The items seen at the first evaluation are recorded, they don't trigger
+	Seen            []string // Keys of the items already seen, the most recent last
+	LastValue       float64
+	LastEvaluatedAt string // ISO 8601 format
+	LastTriggeredAt string // ISO 8601 format
+	LastError       string
+}
+
+// AlertEvent is a trigger of an alert, it's also the payload of the notifications
+type AlertEvent struct {
+	ID          int       `json:"id"` // Sequential per user
+	AlertID     int       `json:"alert_id"`
+	UserID      string    `json:"user_id"`
+	Type        AlertType `json:"type"`
+	Symbol      string    `json:"symbol"`
+	Message     string    `json:"message"`
+	Value       float64   `json:"value"`
+	TriggeredAt string    `json:"triggered_at"` // ISO 8601 format
+	// Delivery of the notifications, the error of each failed notifier
+	NotificationErrors []string `json:"notification_errors,omitempty"`
+}
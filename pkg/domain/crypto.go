@@ -1,9 +1,10 @@
 package domain
 
 type Cryptocurrency struct {
-	Id     string
-	Name   string
-	Symbol string
+	Id        string
+	Name      string
+	Symbol    string
+	MarketCap float64
 }
 
 type CryptocurrencyData struct {
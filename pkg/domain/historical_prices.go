@@ -5,17 +5,24 @@ import "time"
 type Period string
 
 const (
-	Period1D Period = "1d"
-	Period5D Period = "5d"
-	Period1M Period = "1m"
-	Period6M Period = "6m"
-	Period1Y Period = "1y"
-	Period5Y Period = "5y"
+	Period1D  Period = "1d"
+	Period5D  Period = "5d"
+	Period1M  Period = "1m"
+	PeriodYTD Period = "ytd"
+	Period6M  Period = "6m"
+	Period1Y  Period = "1y"
+	Period5Y  Period = "5y"
+	PeriodMax Period = "max"
 )
 
+// Price is a point of a price history. The open, high, low and volume are 0 when the source only has the closes.
 type Price struct {
 	Date       time.Time
 	ClosePrice float64
+	Open       float64
+	High       float64
+	Low        float64
+	Volume     float64
 }
 
 type HistoricalPrices struct {
@@ -23,3 +30,33 @@ type HistoricalPrices struct {
 	Prices           []Price
 	PercentageChange float64
 }
+
+type PriceInterval string
+
+const (
+	PriceIntervalDaily  PriceInterval = "daily"
+	PriceIntervalWeekly PriceInterval = "weekly"
+)
+
+// PriceHistoryQuery selects the prices of a symbol over a period, or over a date range when Start is set
+type PriceHistoryQuery struct {
+	Symbol     string
+	AssetClass AssetClass
+	Period     Period
+	Start      time.Time
+	End        time.Time // Today when zero
+	Ohlcv      bool      // Daily or weekly bars with the open, high, low and volume instead of the closes
+	MaxPoints  int       // The points are merged down to this number
+}
+
+// PriceHistory is the price history of a symbol over a date range
+type PriceHistory struct {
+	Symbol           string
+	AssetClass       AssetClass
+	Start            time.Time
+	End              time.Time
+	Source           string // Where the prices come from, e.g. stockanalysis
+	Prices           []Price
+	SourcePoints     int // Number of points in the range before the downsampling
+	PercentageChange float64
+}
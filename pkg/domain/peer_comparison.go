@@ -0,0 +1,43 @@
+package domain
+
+type Peer struct {
+	Symbol      string
+	CompanyName string
+	MarketCap   float64
+}
+
+// PeerMetric is a metric of a company against its peers. The percentile is the share of the peers with a worse
+// value, the equal ones counting for half, so 100 is the best and a high valuation percentile means cheaper than the
+// peers. The median and the percentile are the ones of the meaningful values (e.g. not a negative P/E).
+type PeerMetric struct {
+	Name           string
+	Set            MetricSet
+	Unit           MetricUnit
+	HigherIsBetter bool
+	Value          *float64 // Nil when missing
+	PeerMedian     *float64
+	PeerCount      int      // Number of the peers with a meaningful value
+	Percentile     *float64 // Nil when the value or the peer values are missing or not meaningful
+	VsMedianPct    *float64 // Difference of the value with the peer median in percent, nil without a positive median
+}
+
+// SetPercentile is the average of the percentiles of the metrics of a set
+type SetPercentile struct {
+	Set        MetricSet
+	Percentile float64
+	Metrics    int // Number of the metrics with a percentile
+}
+
+type PeerComparison struct {
+	Symbol          string
+	CompanyName     string
+	Industry        string
+	IndustryUrlName string
+	Sector          string
+	MarketCap       float64
+	Peers           []Peer // Nearest first by market cap
+	Metrics         []PeerMetric
+	Sets            []SetPercentile
+	Errors          []ComparisonError
+	Warnings        []string
+}
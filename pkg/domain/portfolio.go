@@ -0,0 +1,70 @@
+package domain
+
+import "time"
+
+// HoldingValuation is a holding of a user portfolio priced in the base currency of the valuation
+type HoldingValuation struct {
+	Holding       UserPortfolioHolding
+	Price         float64 // In PriceCurrency
+	PriceCurrency Currency
+	PriceDate     time.Time
+	PriceSource   string
+	MarketValue   float64 // In the base currency
+	Weight        float64 // Percentage of the total market value
+}
+
+// UnpricedHolding is a holding of a user portfolio that couldn't be priced, and the reason why
+type UnpricedHolding struct {
+	Holding UserPortfolioHolding
+	Reason  string
+}
+
+type PortfolioValuation struct {
+	UserID       string
+	BaseCurrency Currency
+	TotalValue   float64
+	Holdings     []HoldingValuation
+	Unpriced     []UnpricedHolding
+	ValuedAt     time.Time
+}
+
+// ExposureWeighting is how the holdings of a portfolio are weighted in its exposure
+type ExposureWeighting string
+
+const (
+	WeightingMarketValue ExposureWeighting = "market_value" // Live market values, see PortfolioValuation
+	WeightingStated      ExposureWeighting = "stated"       // Portfolio percentages stated in the user context
+)
+
+// ExposureBucket is the share of a portfolio in a group of holdings (an asset class, a sector, ...)
+type ExposureBucket struct {
+	Name   string
+	Weight float64 // Percentage of the portfolio
+}
+
+// NameExposure is the exposure of a portfolio to a single name, held directly or through funds
+type NameExposure struct {
+	Symbol         string
+	Name           string
+	Weight         float64  // Percentage of the portfolio
+	DirectWeight   float64  // Held directly
+	IndirectWeight float64  // Held through funds
+	Funds          []string // Funds holding the name
+}
+
+type PortfolioExposure struct {
+	UserID       string
+	Weighting    ExposureWeighting
+	AssetClasses []ExposureBucket
+	Sectors      []ExposureBucket
+	Industries   []ExposureBucket
+	Countries    []ExposureBucket
+	Names        []NameExposure // Sorted by weight, the largest first
+	// Concentration of the single names, the unexpanded remainder of the funds excluded
+	Top10Weight    float64
+	Herfindahl     float64 // Sum of the squared weights (as fractions), from 1/names to 1
+	EffectiveNames float64 // 1 / Herfindahl
+	// Share of the funds weight expanded into their top holdings, the rest is reported as the fund itself
+	LookThroughCoverage float64
+	Excluded            []UnpricedHolding // Holdings without weight
+}
@@ -0,0 +1,109 @@
+package domain
+
+type ScreenerFieldType string
+
+const (
+	NumberScreenerField ScreenerFieldType = "number"
+	TextScreenerField   ScreenerFieldType = "text"
+)
+
+// ScreenerField is a field of the stock screener, a column of the stockanalysis screener
+type ScreenerField struct {
+	Name        string // Name of the field in the tools, e.g. market_cap
+	Column      string // Id of the column in the screener, e.g. marketCap
+	Type        ScreenerFieldType
+	Unit        MetricUnit // Of the number fields, the percents are in percent (e.g. 2.5 for 2.5%)
+	Filterable  bool       // Whether the screener filters on the column, otherwise the filters are applied locally
+	Description string
+}
+
+// ScreenerFields is the catalogue of the fields the screener filters, sorts and returns
+var ScreenerFields = []ScreenerField{
+	{Name: "symbol", Column: "s", Type: TextScreenerField, Description: "Symbol of the stock"},
+	{Name: "name", Column: "n", Type: TextScreenerField, Description: "Name of the company"},
+	{Name: "market_cap", Column: "marketCap", Type: NumberScreenerField, Unit: UsdMetricUnit, Filterable: true, Description: "Market capitalization"},
+	{Name: "price", Column: "price", Type: NumberScreenerField, Unit: UsdMetricUnit, Filterable: true, Description: "Last price"},
+	{Name: "change_1d", Column: "change", Type: NumberScreenerField, Unit: PercentMetricUnit, Filterable: true, Description: "Price change of the day"},
+	{Name: "revenue", Column: "revenue", Type: NumberScreenerField, Unit: UsdMetricUnit, Filterable: true, Description: "Revenue of the trailing twelve months"},
+	{Name: "pe", Column: "peRatio", Type: NumberScreenerField, Unit: RatioMetricUnit, Filterable: true, Description: "Price to earnings ratio"},
+	{Name: "ps", Column: "psRatio", Type: NumberScreenerField, Unit: RatioMetricUnit, Filterable: true, Description: "Price to sales ratio"},
+	{Name: "pb", Column: "pbRatio", Type: NumberScreenerField, Unit: RatioMetricUnit, Filterable: true, Description: "Price to book ratio"},
+	{Name: "dividend_yield", Column: "dividendYield", Type: NumberScreenerField, Unit: PercentMetricUnit, Filterable: true, Description: "Dividend yield"},
+	{Name: "revenue_growth", Column: "revenueGrowth", Type: NumberScreenerField, Unit: PercentMetricUnit, Filterable: true, Description: "Revenue growth of the trailing twelve months, year over year"},
+	{Name: "gross_margin", Column: "grossMargin", Type: NumberScreenerField, Unit: PercentMetricUnit, Description: "Gross margin"},
+	{Name: "operating_margin", Column: "operatingMargin", Type: NumberScreenerField, Unit: PercentMetricUnit, Description: "Operating margin"},
+	{Name: "profit_margin", Column: "profitMargin", Type: NumberScreenerField, Unit: PercentMetricUnit, Description: "Net profit margin"},
+	{Name: "sector", Column: "sector", Type: TextScreenerField, Filterable: true, Description: "Sector, e.g. Technology"},
+	{Name: "industry", Column: "industry", Type: TextScreenerField, Filterable: true, Description: "Industry, e.g. Semiconductors"},
+	{Name: "exchange", Column: "exchange", Type: TextScreenerField, Filterable: true, Description: "Exchange, e.g. NASDAQ"},
+	{Name: "country", Column: "country", Type: TextScreenerField, Filterable: true, Description: "Country of the headquarters, e.g. United States"},
+}
+
+// LookupScreenerField returns the field of the catalogue with the name
+func LookupScreenerField(name string) (ScreenerField, bool) {
+	for _, field := range ScreenerFields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return ScreenerField{}, false
+}
+
+type ScreenerOperator string
+
+const (
+	// The number operators
+	GreaterThanScreenerOperator        ScreenerOperator = "gt"
+	GreaterThanOrEqualScreenerOperator ScreenerOperator = "gte"
+	LessThanScreenerOperator           ScreenerOperator = "lt"
+	LessThanOrEqualScreenerOperator    ScreenerOperator = "lte"
+	BetweenScreenerOperator            ScreenerOperator = "between" // Inclusive
+
+	// The text operators, case insensitive
+	EqualScreenerOperator    ScreenerOperator = "eq"
+	NotEqualScreenerOperator ScreenerOperator = "neq"
+	InScreenerOperator       ScreenerOperator = "in"
+	ContainsScreenerOperator ScreenerOperator = "contains"
+)
+
+// ScreenerFilter keeps the stocks whose field matches. The number operators compare with Value, and between with
+// Value and Upper. The text operators compare with Texts, a single one except for in.
+type ScreenerFilter struct {
+	Field    string
+	Operator ScreenerOperator
+	Value    float64
+	Upper    float64
+	Texts    []string
+}
+
+// ScreenerQuery is a stock screen: the filters, the order of the stocks and the fields returned
+type ScreenerQuery struct {
+	Filters    []ScreenerFilter
+	Sort       string // Field of the order, market_cap by default
+	Descending bool
+	Limit      int
+	Columns    []string // The fields returned besides the symbol and the name
+}
+
+// ScreenerRequest is a request to the screener, its filters are the ones the screener supports
+type ScreenerRequest struct {
+	Columns    []string // Fields
+	Filters    []ScreenerFilter
+	Sort       string
+	Descending bool
+}
+
+// ScreenedStock is a stock of a screen, with the values of the fields by name. The values are float64 for the
+// number fields and string for the text fields, and missing when unknown.
+type ScreenedStock struct {
+	Symbol string
+	Name   string
+	Values map[string]any
+}
+
+type ScreenerResult struct {
+	Stocks  []ScreenedStock
+	Matches int              // Number of the stocks matching the filters, before the limit
+	Pushed  []ScreenerFilter // The filters applied by the screener
+	Local   []ScreenerFilter // The filters applied to the stocks returned by the screener
+}
@@ -0,0 +1,26 @@
+package domain
+
+type SearchResultType string
+
+const (
+	SearchResultStock         SearchResultType = "stock"
+	SearchResultEtf           SearchResultType = "etf"
+	SearchResultCrypto        SearchResultType = "crypto"
+	SearchResultSuperInvestor SearchResultType = "super_investor"
+	SearchResultSector        SearchResultType = "sector"
+	SearchResultIndustry      SearchResultType = "industry"
+	SearchResultInvestingIdea SearchResultType = "investing_idea"
+)
+
+// SearchResult is a result of the search across all the data sources
+type SearchResult struct {
+	Type SearchResultType
+	// ID identifies the result in its source (symbol, CoinGecko id, super investor name, sector/industry url name, idea id)
+	ID          string
+	Symbol      string
+	Name        string
+	Score       float64
+	MatchReason string
+	// Weight is the market cap or the AUM of the result (0 when not applicable)
+	Weight float64
+}
@@ -36,6 +36,7 @@ type StockForecast struct {
 type Ticker struct {
 	Symbol      string
 	CompanyName string
+	MarketCap   float64
 }
 
 type EarningsCallTranscript struct {
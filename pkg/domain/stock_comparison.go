@@ -0,0 +1,51 @@
+package domain
+
+type MetricSet string
+
+const (
+	ValuationMetrics     MetricSet = "valuation"
+	ProfitabilityMetrics MetricSet = "profitability"
+	GrowthMetrics        MetricSet = "growth"
+	LeverageMetrics      MetricSet = "leverage"
+	PerformanceMetrics   MetricSet = "performance"
+)
+
+type MetricUnit string
+
+const (
+	RatioMetricUnit   MetricUnit = "ratio"
+	PercentMetricUnit MetricUnit = "percent"
+	UsdMetricUnit     MetricUnit = "usd"
+)
+
+// ComparedValue is the value of a metric for a symbol, and its rank among the symbols (1 is the best)
+type ComparedValue struct {
+	Symbol string
+	Value  *float64 // Nil when missing
+	Rank   int      // 0 when missing or not meaningful, e.g. a negative P/E
+}
+
+// ComparedMetric is a row of a comparison, the values in the order of the symbols. The min, the max and the
+// median are the ones of the ranked values.
+type ComparedMetric struct {
+	Name           string
+	Set            MetricSet
+	Unit           MetricUnit
+	HigherIsBetter bool
+	Values         []ComparedValue
+	Min            *float64
+	Max            *float64
+	Median         *float64
+}
+
+// ComparisonError is data of a symbol that couldn't be fetched, its metrics are missing
+type ComparisonError struct {
+	Symbol string
+	Error  string
+}
+
+type StockComparison struct {
+	Symbols []string
+	Metrics []ComparedMetric
+	Errors  []ComparisonError
+}
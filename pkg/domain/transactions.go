@@ -0,0 +1,89 @@
+package domain
+
+import "time"
+
+type TransactionType string
+
+const (
+	TransactionBuy        TransactionType = "buy"
+	TransactionSell       TransactionType = "sell"
+	TransactionDividend   TransactionType = "dividend"
+	TransactionSplit      TransactionType = "split"
+	TransactionDeposit    TransactionType = "deposit"
+	TransactionWithdrawal TransactionType = "withdrawal"
+	TransactionFee        TransactionType = "fee"
+)
+
+// Transaction is an entry of the transaction ledger of a user. The fields used depend on the type:
+// buys and sells have a symbol, a quantity, a price and fees, dividends a symbol and an amount, splits a symbol
+// and a ratio, deposits, withdrawals and fees an amount (and optionally a symbol for the fees).
+type Transaction struct {
+	ID         int // Sequential per user
+	UserID     string
+	Type       TransactionType
+	Date       time.Time
+	AssetClass AssetClass
+	Symbol     string
+	Quantity   float64
+	Price      float64 // Per unit, in Currency
+	Amount     float64 // Of the dividends, deposits, withdrawals and fees, in Currency
+	Fees       float64 // Of the buys and sells, in Currency
+	SplitRatio float64 // New shares per old share, e.g. 4 for a 4-for-1 split
+	Currency   Currency
+	Notes      string
+	CreatedAt  string // ISO 8601 format
+}
+
+type CostBasisMethod string
+
+const (
+	CostBasisFIFO    CostBasisMethod = "fifo"
+	CostBasisAverage CostBasisMethod = "average"
+)
+
+// Lot is a quantity of a position bought at the same time, with the cost of the buy including its fees
+type Lot struct {
+	Date     time.Time
+	Quantity float64
+	UnitCost float64
+}
+
+// Position is a holding derived from the ledger
+type Position struct {
+	AssetClass    AssetClass
+	Symbol        string
+	Quantity      float64
+	CostBasis     float64 // Cost of the remaining lots
+	Lots          []Lot
+	RealizedPnL   float64 // Proceeds of the sales minus the cost of the sold lots
+	Dividends     float64
+	Fees          float64 // Fees attributed to the position besides the ones of the buys and sells
+	Price         float64 // Current price, 0 when it couldn't be priced
+	MarketValue   float64
+	UnrealizedPnL float64
+	Priced        bool
+	PriceError    string
+}
+
+// PortfolioPerformance is the performance of a portfolio derived from its ledger, all the amounts are in the base
+// currency, converted at the current exchange rates
+type PortfolioPerformance struct {
+	UserID          string
+	BaseCurrency    Currency
+	CostBasisMethod CostBasisMethod
+	Positions       []Position // Open positions first, by market value
+	Cash            float64
+	MarketValue     float64 // Positions and cash
+	NetDeposits     float64 // Deposits (including the implicit ones) minus withdrawals
+	RealizedPnL     float64
+	UnrealizedPnL   float64
+	Dividends       float64
+	Fees            float64
+	// Time-weighted and money-weighted returns in percent, nil when they can't be computed
+	TimeWeightedReturn           *float64
+	AnnualizedTimeWeightedReturn *float64
+	MoneyWeightedReturn          *float64 // Annualized, the internal rate of return of the external cash flows
+	ReturnsError                 string   // Why the returns couldn't be computed
+	FirstTransactionDate         time.Time
+	ValuedAt                     time.Time
+}
@@ -29,3 +29,14 @@ type UserContext struct {
 	CreatedAt     string // ISO 8601 format
 	UpdatedAt     string // ISO 8601 format
 }
+
+// UserContextChange is a change of a user context in its history, with the context before the change to revert it
+type UserContextChange struct {
+	ID        int // Sequential per user
+	UserID    string
+	Operation string
+	Summary   string
+	CreatedAt string       // ISO 8601 format
+	Before    *UserContext // nil when the change created the context
+	After     UserContext
+}
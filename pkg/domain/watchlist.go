@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// WatchlistEntry is a security a user follows without necessarily owning it
+type WatchlistEntry struct {
+	Symbol     string
+	AssetClass AssetClass
+	AddedAt    string // ISO 8601 format
+	Notes      string
+}
+
+type Watchlist struct {
+	UserID    string
+	Name      string
+	Entries   []WatchlistEntry // In the order they were added
+	CreatedAt string           // ISO 8601 format
+	UpdatedAt string           // ISO 8601 format
+}
+
+// KeyRatios are the main valuation ratios of a security, zero when unknown or not applicable to its asset class
+type KeyRatios struct {
+	MarketCap     float64 // In USD
+	Pe            float64
+	Ps            float64
+	Pb            float64
+	EvEbitda      float64
+	DebtEquity    float64
+	Roe           float64 // In percent
+	DividendYield float64 // In percent
+	ExpenseRatio  float64 // In percent, of the ETFs
+}
+
+type WatchlistEntrySnapshot struct {
+	Entry     WatchlistEntry
+	Price     float64 // In USD
+	PriceDate time.Time
+	// Performance in percent, nil when unknown
+	Change1D *float64
+	Change1M *float64
+	Change1Y *float64
+	Ratios   KeyRatios
+	Errors   []string // Data that couldn't be fetched
+}
+
+type WatchlistSnapshot struct {
+	Watchlist Watchlist
+	Entries   []WatchlistEntrySnapshot // In the order of the watchlist
+	FetchedAt time.Time
+}
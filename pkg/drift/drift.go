@@ -0,0 +1,214 @@
+// Package drift detects schema drift of the scraped websites: when a website renames or moves a field,
+// the scrapers don't fail but silently return zero values, which the tools describe as "not known".
+//
+// Each scraper declares the fields it expects in a Schema and Validate flags the responses where
+// required fields are missing (zero) or where expected fields are zero in most of the records.
+package drift
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DefaultMaxZeroRatio is the ratio of zero values above which an expected field is flagged
+const DefaultMaxZeroRatio = 0.8
+
+// IssueKind is the kind of drift found in a response
+type IssueKind string
+
+const (
+	// TooFewRecords is reported when the response has less records than the schema expects
+	TooFewRecords IssueKind = "too_few_records"
+	// MissingField is reported when a required field is zero in some of the records
+	MissingField IssueKind = "missing_field"
+	// MostlyZeroField is reported when an expected field is zero in more records than allowed
+	MostlyZeroField IssueKind = "mostly_zero_field"
+	// UnknownField is reported when a field of the schema doesn't exist in the response type
+	UnknownField IssueKind = "unknown_field"
+)
+
+// Field is a field a scraper expects in its response
+type Field struct {
+	// Path is the dotted path of the field from the root of the response, slices are traversed
+	// (e.g. "Estimations.Eps" checks the Eps field of every estimation)
+	Path string
+	// Required fields must be set in every record
+	Required bool
+	// MaxZeroRatio is the ratio of zero values above which the field is flagged, DefaultMaxZeroRatio when 0
+	MaxZeroRatio float64
+}
+
+// Schema declares what a scraper expects in its response
+type Schema struct {
+	Name string
+	// MinRecords is the number of records a slice response (or the slice the fields go through) must have
+	MinRecords int
+	Fields     []Field
+}
+
+// Issue is a drift found in a response
+type Issue struct {
+	Kind      IssueKind `json:"kind"`
+	Field     string    `json:"field,omitempty"`
+	ZeroRatio float64   `json:"zeroRatio,omitempty"`
+	Message   string    `json:"message"`
+}
+
+// Report is the result of the validation of a response against its schema
+type Report struct {
+	Schema  string  `json:"schema"`
+	Records int     `json:"records"`
+	Issues  []Issue `json:"issues,omitempty"`
+}
+
+// HasDrift returns true if the response doesn't match its schema
+func (r Report) HasDrift() bool {
+	return len(r.Issues) > 0
+}
+
+// Validate checks a scraper response against the schema of the scraper
+func Validate(schema Schema, response any) Report {
+	report := Report{Schema: schema.Name, Records: countRecords(reflect.ValueOf(response))}
+
+	if report.Records < schema.MinRecords {
+		report.Issues = append(report.Issues, Issue{
+			Kind:    TooFewRecords,
+			Message: fmt.Sprintf("expected at least %d records, got %d", schema.MinRecords, report.Records),
+		})
+	}
+
+	for _, field := range schema.Fields {
+		values, err := collect(reflect.ValueOf(response), strings.Split(field.Path, "."))
+		if err != nil {
+			report.Issues = append(report.Issues, Issue{Kind: UnknownField, Field: field.Path, Message: err.Error()})
+			continue
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		zeros := 0
+		for _, value := range values {
+			if isZero(value) {
+				zeros++
+			}
+		}
+		zeroRatio := float64(zeros) / float64(len(values))
+
+		maxZeroRatio := field.MaxZeroRatio
+		if maxZeroRatio == 0 {
+			maxZeroRatio = DefaultMaxZeroRatio
+		}
+
+		switch {
+		case field.Required && zeros > 0:
+			report.Issues = append(report.Issues, Issue{
+				Kind:      MissingField,
+				Field:     field.Path,
+				ZeroRatio: zeroRatio,
+				Message:   fmt.Sprintf("required field is missing in %d of %d records", zeros, len(values)),
+			})
+		case zeroRatio > maxZeroRatio:
+			report.Issues = append(report.Issues, Issue{
+				Kind:      MostlyZeroField,
+				Field:     field.Path,
+				ZeroRatio: zeroRatio,
+				Message:   fmt.Sprintf("field is zero in %d of %d records", zeros, len(values)),
+			})
+		}
+	}
+
+	return report
+}
+
+// countRecords returns the length of a slice response, 1 for any other response
+func countRecords(v reflect.Value) int {
+	v = indirect(v)
+	if !v.IsValid() {
+		return 0
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		return v.Len()
+	}
+	return 1
+}
+
+// collect returns the values at the path, traversing the slices on the way
+func collect(v reflect.Value, path []string) ([]reflect.Value, error) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		var values []reflect.Value
+		for i := range v.Len() {
+			itemValues, err := collect(v.Index(i), path)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, itemValues...)
+		}
+		// The path is checked against the item type even when the slice is empty
+		if v.Len() == 0 && len(path) > 0 {
+			if err := checkPath(v.Type().Elem(), path); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+
+	if len(path) == 0 {
+		return []reflect.Value{v}, nil
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct field of %s", path[0], v.Type())
+	}
+	field := v.FieldByName(path[0])
+	if !field.IsValid() {
+		return nil, fmt.Errorf("%s has no field %s", v.Type(), path[0])
+	}
+	return collect(field, path[1:])
+}
+
+// checkPath checks that the path exists in the type
+func checkPath(t reflect.Type, path []string) error {
+	for _, name := range path {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return fmt.Errorf("%s is not a struct field of %s", name, t)
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			return fmt.Errorf("%s has no field %s", t, name)
+		}
+		t = field.Type
+	}
+	return nil
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isZero returns true for the zero values, including the empty slices and maps
+func isZero(v reflect.Value) bool {
+	v = indirect(v)
+	if !v.IsValid() {
+		return true
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Map {
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
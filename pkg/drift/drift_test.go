@@ -0,0 +1,82 @@
+package drift
+
+import (
+	"testing"
+)
+
+type price struct {
+	Date  string
+	Close float64
+	Open  float64
+}
+
+type prices struct {
+	Symbol string
+	Prices []price
+}
+
+func TestValidate(t *testing.T) {
+	schema := Schema{
+		Name: "prices",
+		Fields: []Field{
+			{Path: "Symbol", Required: true},
+			{Path: "Prices.Date", Required: true},
+			{Path: "Prices.Close"},
+			{Path: "Prices.Open", MaxZeroRatio: 0.5},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		response any
+		want     []IssueKind
+	}{
+		{
+			name:     "complete",
+			response: prices{Symbol: "AAPL", Prices: []price{{"2025-01-02", 10, 9}, {"2025-01-03", 11, 0}}},
+		},
+		{
+			name:     "required field missing in a record",
+			response: prices{Symbol: "AAPL", Prices: []price{{"2025-01-02", 10, 9}, {"", 11, 10}}},
+			want:     []IssueKind{MissingField},
+		},
+		{
+			name:     "renamed fields",
+			response: &prices{Symbol: "AAPL", Prices: []price{{"2025-01-02", 0, 0}, {"2025-01-03", 0, 0}}},
+			want:     []IssueKind{MostlyZeroField, MostlyZeroField},
+		},
+		{
+			name:     "missing root field",
+			response: prices{Prices: []price{{"2025-01-02", 10, 9}}},
+			want:     []IssueKind{MissingField},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Validate(schema, tt.response)
+
+			var got []IssueKind
+			for _, issue := range report.Issues {
+				got = append(got, issue.Kind)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got issues %+v, want kinds %v", report.Issues, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got issues %+v, want kinds %v", report.Issues, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRecords(t *testing.T) {
+	schema := Schema{Name: "prices", MinRecords: 1, Fields: []Field{{Path: "Dat"}}}
+
+	report := Validate(schema, []price{})
+	if len(report.Issues) != 2 || report.Issues[0].Kind != TooFewRecords || report.Issues[1].Kind != UnknownField {
+		t.Fatalf("expected too few records and an unknown field, got %+v", report.Issues)
+	}
+}
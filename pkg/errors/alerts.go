@@ -0,0 +1,12 @@
+package errors
+
+import "fmt"
+
+type AlertNotFoundError struct {
+	UserID  string
+	AlertID int
+}
+
+func (e AlertNotFoundError) Error() string {
+	return fmt.Sprintf("alert %d not found for user_id: %s", e.AlertID, e.UserID)
+}
@@ -0,0 +1,14 @@
+package errors
+
+import "fmt"
+
+// DevalueDecodeError is returned when a devalue encoded response (SvelteKit __data.json) doesn't have the expected layout.
+// Path is the path of the failing value from the root of the decoded node (e.g. nodes[2].sectors[4].sector_name).
+type DevalueDecodeError struct {
+	Path    string
+	Message string
+}
+
+func (e DevalueDecodeError) Error() string {
+	return fmt.Sprintf("devalue decode error at %s: %s", e.Path, e.Message)
+}
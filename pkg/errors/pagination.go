@@ -0,0 +1,11 @@
+package errors
+
+import "fmt"
+
+type InvalidCursorError struct {
+	Cursor string
+}
+
+func (e InvalidCursorError) Error() string {
+	return fmt.Sprintf("invalid cursor: %s", e.Cursor)
+}
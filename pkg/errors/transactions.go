@@ -0,0 +1,20 @@
+package errors
+
+import "fmt"
+
+type TransactionNotFoundError struct {
+	UserID        string
+	TransactionID int
+}
+
+func (e TransactionNotFoundError) Error() string {
+	return fmt.Sprintf("transaction %d not found in the ledger of user_id: %s", e.TransactionID, e.UserID)
+}
+
+type NoTransactionsError struct {
+	UserID string
+}
+
+func (e NoTransactionsError) Error() string {
+	return fmt.Sprintf("no transactions in the ledger of user_id: %s", e.UserID)
+}
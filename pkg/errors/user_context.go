@@ -17,3 +17,35 @@ type UserContextAlreadyExistsError struct {
 func (e UserContextAlreadyExistsError) Error() string {
 	return fmt.Sprintf("user context for user_id: %s already exists", e.UserID)
 }
+
+// UserContextConflictError is returned when a change is based on a version of the user context that isn't the current one
+type UserContextConflictError struct {
+	UserID            string
+	ExpectedUpdatedAt string
+	UpdatedAt         string
+}
+
+func (e UserContextConflictError) Error() string {
+	if e.ExpectedUpdatedAt == "" {
+		return fmt.Sprintf("user context for user_id: %s already exists, expected_updated_at must be its updated_at: %s", e.UserID, e.UpdatedAt)
+	}
+	return fmt.Sprintf("user context for user_id: %s was updated at %s, not at the expected %s: get the user context again and reapply the change", e.UserID, e.UpdatedAt, e.ExpectedUpdatedAt)
+}
+
+type PortfolioHoldingNotFoundError struct {
+	UserID string
+	Symbol string
+}
+
+func (e PortfolioHoldingNotFoundError) Error() string {
+	return fmt.Sprintf("portfolio holding %s not found for user_id: %s", e.Symbol, e.UserID)
+}
+
+type UserContextChangeNotFoundError struct {
+	UserID   string
+	ChangeID int
+}
+
+func (e UserContextChangeNotFoundError) Error() string {
+	return fmt.Sprintf("change %d not found in the history of user_id: %s", e.ChangeID, e.UserID)
+}
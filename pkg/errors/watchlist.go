@@ -0,0 +1,31 @@
+package errors
+
+import "fmt"
+
+type WatchlistNotFoundError struct {
+	UserID string
+	Name   string
+}
+
+func (e WatchlistNotFoundError) Error() string {
+	return fmt.Sprintf("watchlist %q not found for user_id: %s", e.Name, e.UserID)
+}
+
+type WatchlistAlreadyExistsError struct {
+	UserID string
+	Name   string
+}
+
+func (e WatchlistAlreadyExistsError) Error() string {
+	return fmt.Sprintf("watchlist %q already exists for user_id: %s", e.Name, e.UserID)
+}
+
+type WatchlistEntryNotFoundError struct {
+	UserID string
+	Name   string
+	Symbol string
+}
+
+func (e WatchlistEntryNotFoundError) Error() string {
+	return fmt.Sprintf("%s not found in the watchlist %q of user_id: %s", e.Symbol, e.Name, e.UserID)
+}
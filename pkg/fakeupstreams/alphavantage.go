@@ -0,0 +1,245 @@
+package fakeupstreams
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func registerAlphaVantageRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+AlphaVantagePrefix+"/query", handleAlphaVantageQuery)
+}
+
+// alphaVantageSeries describes the generated time series of an economic indicator or commodity function
+type alphaVantageSeries struct {
+	name       string
+	unit       string
+	interval   string
+	base       float64
+	volatility float64
+}
+
+var alphaVantageSeriesFunctions = map[string]alphaVantageSeries{
+	"REAL_GDP":           {"Real Gross Domestic Product", "billions of dollars", "quarterly", 5800, 0.006},
+	"FEDERAL_FUNDS_RATE": {"Effective Federal Funds Rate", "percent", "monthly", 4.3, 0.02},
+	"INFLATION":          {"Inflation - US Consumer Prices", "percent", "annual", 2.9, 0.25},
+	"UNEMPLOYMENT":       {"Unemployment Rate", "percent", "monthly", 4.2, 0.02},
+	"WTI":                {"Crude Oil Prices WTI", "dollars per barrel", "monthly", 65, 0.06},
+	"NATURAL_GAS":        {"Henry Hub Natural Gas Spot Price", "dollars per million BTU", "monthly", 3.1, 0.1},
+	"COPPER":             {"Global Price of Copper", "dollars per metric ton", "monthly", 9800, 0.04},
+	"ALUMINUM":           {"Global Price of Aluminum", "dollars per metric ton", "monthly", 2600, 0.04},
+	"WHEAT":              {"Global Price of Wheat", "dollars per metric ton", "monthly", 200, 0.05},
+	"CORN":               {"Global Price of Corn", "dollars per metric ton", "monthly", 190, 0.05},
+	"SUGAR":              {"Global Price of Sugar, No. 11, World", "cents per pound", "monthly", 17, 0.06},
+	"COFFEE":             {"Global Price of Coffee, Other Mild Arabica", "cents per pound", "monthly", 380, 0.06},
+}
+
+// treasuryYieldBases are the yields the generated treasury yield series of each maturity start from
+var treasuryYieldBases = map[string]float64{"3month": 4.0, "2year": 3.6, "5year": 3.7, "7year": 3.9, "10year": 4.1, "30year": 4.7}
+
+// usdExchangeRates are the units of each currency per US dollar
+var usdExchangeRates = map[string]struct {
+	name string
+	rate float64
+}{
+	"USD": {"United States Dollar", 1},
+	"EUR": {"Euro", 0.8576},
+	"GBP": {"British Pound Sterling", 0.7451},
+	"JPY": {"Japanese Yen", 150.61},
+	"CHF": {"Swiss Franc", 0.7953},
+	"CAD": {"Canadian Dollar", 1.4043},
+	"AUD": {"Australian Dollar", 1.5412},
+	"AED": {"United Arab Emirates Dirham", 3.6725},
+}
+
+func handleAlphaVantageQuery(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	function := q.Get("function")
+
+	if series, ok := alphaVantageSeriesFunctions[function]; ok {
+		if function == "REAL_GDP" && q.Get("interval") != "quarterly" {
+			series.interval = "annual"
+		}
+		writeJSON(w, http.StatusOK, generateAlphaVantageSeries(function, series, time.Now().UTC()))
+		return
+	}
+
+	switch function {
+	case "TREASURY_YIELD":
+		maturity := q.Get("maturity")
+		base, ok := treasuryYieldBases[maturity]
+		if !ok {
+			writeAlphaVantageError(w, function)
+			return
+		}
+		series := alphaVantageSeries{maturity + " Treasury Constant Maturity Rate", "percent", "monthly", base, 0.03}
+		writeJSON(w, http.StatusOK, generateAlphaVantageSeries(function+maturity, series, time.Now().UTC()))
+	case "CURRENCY_EXCHANGE_RATE":
+		handleAlphaVantageExchangeRate(w, q.Get("from_currency"), q.Get("to_currency"))
+	case "NEWS_SENTIMENT":
+		serveFixture(w, "alphavantage/cryptocurrency_news.json")
+	case "EARNINGS_CALL_TRANSCRIPT":
+		var transcript map[string]any
+		if err := readFixture("alphavantage/earnings_call_transcript.json", &transcript); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		transcript["symbol"] = strings.ToUpper(q.Get("symbol"))
+		transcript["quarter"] = q.Get("quarter")
+		writeJSON(w, http.StatusOK, transcript)
+	case "TIME_SERIES_DAILY", "TIME_SERIES_WEEKLY":
+		handleAlphaVantageTimeSeries(w, function, q.Get("symbol"))
+	case "INSIDER_TRANSACTIONS":
+		var transactions struct {
+			Data []map[string]any `json:"data"`
+		}
+		if err := readFixture("alphavantage/insider_transactions.json", &transactions); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, transaction := range transactions.Data {
+			transaction["ticker"] = strings.ToUpper(q.Get("symbol"))
+		}
+		writeJSON(w, http.StatusOK, transactions)
+	default:
+		writeAlphaVantageError(w, function)
+	}
+}
+
+type alphaVantageDataPoint struct {
+	Date  string `json:"date"`
+	Value string `json:"value"`
+}
+
+// generateAlphaVantageSeries generates the last 10 years of the series (newest first, like Alpha Vantage)
+func generateAlphaVantageSeries(key string, series alphaVantageSeries, now time.Time) map[string]any {
+	months, points := 1, 120
+	switch series.interval {
+	case "quarterly":
+		months, points = 3, 40
+	case "annual":
+		months, points = 12, 10
+	}
+
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	if months == 3 {
+		start = start.AddDate(0, -int(start.Month()-1)%3, 0)
+	} else if months == 12 {
+		start = time.Date(now.Year()-1, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	s := seed(key)
+	rng := rand.New(rand.NewPCG(s, s>>1))
+	value := series.base
+	data := make([]alphaVantageDataPoint, 0, points)
+	for i := range points {
+		data = append(data, alphaVantageDataPoint{
+			Date:  start.AddDate(0, -i*months, 0).Format(time.DateOnly),
+			Value: fmt.Sprintf("%.2f", value),
+		})
+		value = math.Max(0, value*math.Exp(series.volatility*rng.NormFloat64()))
+	}
+
+	return map[string]any{"name": series.name, "interval": series.interval, "unit": series.unit, "data": data}
+}
+
+func handleAlphaVantageExchangeRate(w http.ResponseWriter, fromCurrency string, toCurrency string) {
+	from, fromOk := usdExchangeRates[fromCurrency]
+	to, toOk := usdExchangeRates[toCurrency]
+	if !fromOk || !toOk {
+		writeAlphaVantageError(w, "CURRENCY_EXCHANGE_RATE")
+		return
+	}
+
+	rate := to.rate / from.rate
+	writeJSON(w, http.StatusOK, map[string]any{
+		"Realtime Currency Exchange Rate": map[string]string{
+			"1. From_Currency Code": fromCurrency,
+			"2. From_Currency Name": from.name,
+			"3. To_Currency Code":   toCurrency,
+			"4. To_Currency Name":   to.name,
+			"5. Exchange Rate":      fmt.Sprintf("%.8f", rate),
+			"6. Last Refreshed":     time.Now().UTC().Format(time.DateTime),
+			"7. Time Zone":          "UTC",
+			"8. Bid Price":          fmt.Sprintf("%.8f", rate*0.99997),
+			"9. Ask Price":          fmt.Sprintf("%.8f", rate*1.00003),
+		},
+	})
+}
+
+// handleAlphaVantageTimeSeries generates the daily bars of the last 100 trading days, or the weekly bars of the five
+// years, around the closes of the stockanalysis charts of the symbol
+func handleAlphaVantageTimeSeries(w http.ResponseWriter, function string, symbol string) {
+	closes := dailyCloses(symbol, time.Now().UTC())
+	s := seed(symbol + function)
+	rng := rand.New(rand.NewPCG(s, s>>1))
+
+	type bar struct {
+		date                           time.Time
+		open, high, low, close, volume float64
+	}
+	bars := make([]bar, 0, len(closes))
+	for i, point := range closes {
+		open := point.C
+		if i > 0 {
+			open = closes[i-1].C
+		}
+		bars = append(bars, bar{
+			date:   time.Unix(point.T, 0).UTC(),
+			open:   open,
+			high:   math.Max(open, point.C) * (1 + 0.005*math.Abs(rng.NormFloat64())),
+			low:    math.Min(open, point.C) * (1 - 0.005*math.Abs(rng.NormFloat64())),
+			close:  point.C,
+			volume: math.Round(5e6 * math.Exp(0.3*rng.NormFloat64())),
+		})
+	}
+
+	key := "Time Series (Daily)"
+	if function == "TIME_SERIES_DAILY" {
+		bars = bars[len(bars)-100:]
+	} else {
+		// A weekly bar is dated by the last trading day of its week
+		key = "Weekly Time Series"
+		var weeks []bar
+		for _, b := range bars {
+			year, week := b.date.ISOWeek()
+			if n := len(weeks); n > 0 {
+				if lastYear, lastWeek := weeks[n-1].date.ISOWeek(); lastYear == year && lastWeek == week {
+					weeks[n-1].date = b.date
+					weeks[n-1].high = math.Max(weeks[n-1].high, b.high)
+					weeks[n-1].low = math.Min(weeks[n-1].low, b.low)
+					weeks[n-1].close = b.close
+					weeks[n-1].volume += b.volume
+					continue
+				}
+			}
+			weeks = append(weeks, b)
+		}
+		bars = weeks
+	}
+
+	series := make(map[string]map[string]string, len(bars))
+	for _, b := range bars {
+		series[b.date.Format(time.DateOnly)] = map[string]string{
+			"1. open":   fmt.Sprintf("%.4f", b.open),
+			"2. high":   fmt.Sprintf("%.4f", b.high),
+			"3. low":    fmt.Sprintf("%.4f", b.low),
+			"4. close":  fmt.Sprintf("%.4f", b.close),
+			"5. volume": fmt.Sprintf("%.0f", b.volume),
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"Meta Data": map[string]string{"2. Symbol": strings.ToUpper(symbol)},
+		key:         series,
+	})
+}
+
+// writeAlphaVantageError writes an error like Alpha Vantage does, with a 200 status code
+func writeAlphaVantageError(w http.ResponseWriter, function string) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"Error Message": fmt.Sprintf("Invalid API call. Please retry or visit the documentation for %s.", function),
+	})
+}
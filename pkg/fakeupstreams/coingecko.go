@@ -0,0 +1,152 @@
+package fakeupstreams
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type coinGeckoCoin struct {
+	Id     string `json:"id"`
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+}
+
+type coinGeckoMarket struct {
+	Id           string  `json:"id"`
+	CurrentPrice float64 `json:"current_price"`
+	MarketCap    float64 `json:"market_cap"`
+}
+
+// coinGeckoMaxSupplies are the max supplies of the coins that have one
+var coinGeckoMaxSupplies = map[string]float64{"bitcoin": 21_000_000, "wrapped-bitcoin": 21_000_000, "litecoin": 84_000_000, "cardano": 45_000_000_000, "ripple": 100_000_000_000}
+
+func registerCoinGeckoRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+CoinGeckoPrefix+"/api/v3/coins/list", func(w http.ResponseWriter, r *http.Request) {
+		serveFixture(w, "coingecko/coins_list.json")
+	})
+	mux.HandleFunc("GET "+CoinGeckoPrefix+"/api/v3/coins/markets", func(w http.ResponseWriter, r *http.Request) {
+		serveFixture(w, "coingecko/coins_markets.json")
+	})
+	mux.HandleFunc("GET "+CoinGeckoPrefix+"/api/v3/coins/{id}", handleCoinGeckoCoin)
+	mux.HandleFunc("GET "+CoinGeckoPrefix+"/api/v3/coins/{id}/market_chart", handleCoinGeckoMarketChart)
+}
+
+// handleCoinGeckoCoin generates the data of a coin of the list from its market data
+func handleCoinGeckoCoin(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var coins []coinGeckoCoin
+	var markets []coinGeckoMarket
+	if err := readFixture("coingecko/coins_list.json", &coins); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := readFixture("coingecko/coins_markets.json", &markets); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var coin *coinGeckoCoin
+	for i := range coins {
+		if coins[i].Id == id {
+			coin = &coins[i]
+		}
+	}
+	if coin == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "coin not found"})
+		return
+	}
+
+	market := coinGeckoMarket{Id: id, CurrentPrice: 1, MarketCap: 1_000_000}
+	for _, m := range markets {
+		if m.Id == id {
+			market = m
+		}
+	}
+
+	s := seed(id)
+	rng := rand.New(rand.NewPCG(s, s>>1))
+	priceChange := func(scale float64) float64 {
+		return float64(int(rng.NormFloat64()*scale*100)) / 100
+	}
+
+	marketData := map[string]any{
+		"current_price":                map[string]float64{"usd": market.CurrentPrice},
+		"market_cap":                   map[string]float64{"usd": market.MarketCap},
+		"price_change_percentage_24h":  priceChange(2),
+		"price_change_percentage_7d":   priceChange(5),
+		"price_change_percentage_14d":  priceChange(7),
+		"price_change_percentage_30d":  priceChange(10),
+		"price_change_percentage_60d":  priceChange(14),
+		"price_change_percentage_200d": priceChange(25),
+		"price_change_percentage_1y":   priceChange(40),
+		"total_supply":                 market.MarketCap / market.CurrentPrice,
+		"max_supply":                   nil,
+	}
+	if maxSupply, ok := coinGeckoMaxSupplies[id]; ok {
+		marketData["max_supply"] = maxSupply
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":          coin.Id,
+		"symbol":      coin.Symbol,
+		"name":        coin.Name,
+		"description": map[string]string{"en": fmt.Sprintf("%s (%s) is a cryptocurrency.", coin.Name, strings.ToUpper(coin.Symbol))},
+		"links":       map[string]string{"whitepaper": ""},
+		"market_data": marketData,
+	})
+}
+
+// handleCoinGeckoMarketChart generates a random walk ending at the current price of the coin, with the granularity
+// of CoinGecko: 5 minutes for a day, hourly up to 90 days, daily beyond (5 years for max)
+func handleCoinGeckoMarketChart(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var markets []coinGeckoMarket
+	if err := readFixture("coingecko/coins_markets.json", &markets); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	market := coinGeckoMarket{Id: id, CurrentPrice: 1, MarketCap: 1_000_000}
+	for _, m := range markets {
+		if m.Id == id {
+			market = m
+		}
+	}
+
+	days := 5 * 365
+	if daysParam := r.URL.Query().Get("days"); daysParam != "max" {
+		var err error
+		if days, err = strconv.Atoi(daysParam); err != nil || days <= 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid days"})
+			return
+		}
+	}
+	step := 24 * time.Hour
+	if days == 1 {
+		step = 5 * time.Minute
+	} else if days <= 90 {
+		step = time.Hour
+	}
+
+	s := seed(id + "market_chart")
+	rng := rand.New(rand.NewPCG(s, s>>1))
+	now := time.Now().UTC().Truncate(step)
+	points := int(time.Duration(days) * 24 * time.Hour / step)
+	prices := make([][2]float64, points+1)
+	volumes := make([][2]float64, points+1)
+	price := market.CurrentPrice
+	for i := points; i >= 0; i-- {
+		t := float64(now.Add(-time.Duration(points-i) * step).UnixMilli())
+		prices[i] = [2]float64{t, price}
+		volumes[i] = [2]float64{t, market.MarketCap * 0.03 * math.Exp(0.2*rng.NormFloat64())}
+		price /= math.Exp(0.03 * math.Sqrt(step.Hours()/24) * rng.NormFloat64())
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"prices": prices, "total_volumes": volumes})
+}
@@ -0,0 +1,17 @@
+package fakeupstreams
+
+import "net/http"
+
+// The same canned portfolio is served for every super investor
+func registerDataromaRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+DataromaPrefix+"/m/managers.php", func(w http.ResponseWriter, r *http.Request) {
+		serveFixture(w, "dataroma/managers.html")
+	})
+	mux.HandleFunc("GET "+DataromaPrefix+"/m/holdings.php", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("m") == "" {
+			http.NotFound(w, r)
+			return
+		}
+		serveFixture(w, "dataroma/holdings.html")
+	})
+}
@@ -0,0 +1,87 @@
+// Package fakeupstreams serves realistic canned responses of all the upstreams of the server
+// (stockanalysis, dataroma, Alpha Vantage and CoinGecko) so that it can be run and demoed without network.
+// Each upstream is served under its own path prefix, the base url of an upstream is the url
+// of the fake server followed by the prefix (e.g. http://localhost:8090/stockanalysis).
+package fakeupstreams
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"path"
+	"strings"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Path prefixes of the upstreams
+const (
+	StockAnalysisPrefix    = "/stockanalysis"
+	StockAnalysisApiPrefix = "/stockanalysis-api"
+	DataromaPrefix         = "/dataroma"
+	AlphaVantagePrefix     = "/alphavantage"
+	CoinGeckoPrefix        = "/coingecko"
+)
+
+// NewHandler returns the handler that serves all the fake upstreams
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	registerStockAnalysisRoutes(mux)
+	registerDataromaRoutes(mux)
+	registerAlphaVantageRoutes(mux)
+	registerCoinGeckoRoutes(mux)
+	return mux
+}
+
+// Env returns the environment variables (as KEY=value) that point the server to the fake upstreams served at serverURL
+func Env(serverURL string) []string {
+	serverURL = strings.TrimSuffix(serverURL, "/")
+	return []string{
+		"STOCK_ANALYSIS_BASE_URL=" + serverURL + StockAnalysisPrefix,
+		"STOCK_ANALYSIS_API_BASE_URL=" + serverURL + StockAnalysisApiPrefix,
+		"DATAROMA_BASE_URL=" + serverURL + DataromaPrefix,
+		"ALPHA_VANTAGE_BASE_URL=" + serverURL + AlphaVantagePrefix + "/query",
+		"COIN_GECKO_BASE_URL=" + serverURL + CoinGeckoPrefix + "/api/v3",
+	}
+}
+
+// serveFixture writes the embedded fixture with the given path (relative to the fixtures directory)
+func serveFixture(w http.ResponseWriter, name string) {
+	data, err := fixtures.ReadFile(path.Join("fixtures", name))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("fixture %s not found", name), http.StatusInternalServerError)
+		return
+	}
+
+	contentType := "application/json"
+	if strings.HasSuffix(name, ".html") {
+		contentType = "text/html; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(data)
+}
+
+// readFixture decodes the embedded JSON fixture with the given path into v
+func readFixture(name string, v any) error {
+	data, err := fixtures.ReadFile(path.Join("fixtures", name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// seed returns a stable seed for the generated data of the given key, so that the same request always gets the same response
+func seed(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(key)))
+	return h.Sum64()
+}
@@ -0,0 +1,107 @@
+package fakeupstreams_test
+
+import (
+	alphavantage "market_data_mcp_server/pkg/alpha_vantage"
+	coingecko "market_data_mcp_server/pkg/coin_gecko"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/fakeupstreams"
+	"market_data_mcp_server/pkg/marketDataScraper"
+	"net/http/httptest"
+	"testing"
+)
+
+// The fake upstreams are only useful as long as the real scrapers and clients can parse them
+func TestScrapersAndClientsAgainstFakeUpstreams(t *testing.T) {
+	server := httptest.NewServer(fakeupstreams.NewHandler())
+	defer server.Close()
+
+	scraper := marketDataScraper.NewMarketDataScraper(marketDataScraper.BaseURLs{
+		StockAnalysis:    server.URL + fakeupstreams.StockAnalysisPrefix,
+		StockAnalysisApi: server.URL + fakeupstreams.StockAnalysisApiPrefix,
+		Dataroma:         server.URL + fakeupstreams.DataromaPrefix,
+	})
+	alphaVantageClient, _ := alphavantage.NewAlphaVantageClient("demo", server.URL+fakeupstreams.AlphaVantagePrefix+"/query")
+	coinGeckoClient, _ := coingecko.NewCoinGeckoClient("demo", server.URL+fakeupstreams.CoinGeckoPrefix+"/api/v3")
+
+	tests := []struct {
+		name string
+		call func() (any, error)
+	}{
+		{"GetTickers", func() (any, error) { return scraper.GetTickers() }},
+		{"GetEtfs", func() (any, error) { return scraper.GetEtfs() }},
+		{"GetEtfOverview", func() (any, error) { return scraper.GetEtfOverview("spy") }},
+		{"GetBalanceSheets", func() (any, error) { return scraper.GetBalanceSheets("msft") }},
+		{"GetCashFlows", func() (any, error) { return scraper.GetCashFlows("msft") }},
+		{"GetIncomeStatements", func() (any, error) { return scraper.GetIncomeStatements("msft") }},
+		{"GetFinancialRatios", func() (any, error) { return scraper.GetFinancialRatios("msft") }},
+		{"GetStockForecast", func() (any, error) { return scraper.GetStockForecast("msft") }},
+		{"GetStockProfile", func() (any, error) { return scraper.GetStockProfile("msft") }},
+		{"GetCompanyKpiMetrics", func() (any, error) { return scraper.GetCompanyKpiMetrics("msft") }},
+		{"GetStockNews", func() (any, error) { return scraper.GetStockNews("msft") }},
+		{"GetMarketNews", func() (any, error) { return scraper.GetMarketNews() }},
+		{"GetSectors", func() (any, error) { return scraper.GetSectors() }},
+		{"GetSectorStocks", func() (any, error) { return scraper.GetSectorStocks("technology") }},
+		{"GetIndustries", func() (any, error) { return scraper.GetIndustries() }},
+		{"GetIndustryStocks", func() (any, error) { return scraper.GetIndustryStocks("semiconductors") }},
+		{"GetScreenerStocks", func() (any, error) {
+			return scraper.GetScreenerStocks(domain.ScreenerRequest{
+				Columns: []string{"market_cap", "pe", "sector"},
+				Filters: []domain.ScreenerFilter{{Field: "sector", Operator: domain.EqualScreenerOperator, Texts: []string{"Technology"}}},
+				Sort:    "market_cap",
+			})
+		}},
+		{"GetSuperInvestors", func() (any, error) { return scraper.GetSuperInvestors() }},
+		{"GetSuperInvestorPortfolio", func() (any, error) {
+			return scraper.GetSuperInvestorPortfolio("Warren Buffett - Berkshire Hathaway")
+		}},
+		{"GetHistoricalPrices1D", func() (any, error) { return scraper.GetHistoricalPrices("msft", domain.Stock, domain.Period1D) }},
+		{"GetHistoricalPrices5Y", func() (any, error) { return scraper.GetHistoricalPrices("spy", domain.ETF, domain.Period5Y) }},
+		{"GetHistoricalPricesYTD", func() (any, error) { return scraper.GetHistoricalPrices("msft", domain.Stock, domain.PeriodYTD) }},
+		{"GetHistoricalPricesMax", func() (any, error) { return scraper.GetHistoricalPrices("msft", domain.Stock, domain.PeriodMax) }},
+		{"GetRealGdpTimeSeries", func() (any, error) {
+			return alphaVantageClient.GetRealGdpTimeSeries(domain.QuarterlyEconomicIndicatorInterval)
+		}},
+		{"GetTreasuryYieldTimeSeries", func() (any, error) {
+			return alphaVantageClient.GetTreasuryYieldTimeSeries(domain.ThirtyYearTreasuryYieldMaturity)
+		}},
+		{"GetStockTimeSeriesDaily", func() (any, error) {
+			return alphaVantageClient.GetStockTimeSeries("MSFT", domain.PriceIntervalDaily)
+		}},
+		{"GetStockTimeSeriesWeekly", func() (any, error) {
+			return alphaVantageClient.GetStockTimeSeries("SPY", domain.PriceIntervalWeekly)
+		}},
+		{"GetInterestRatesTimeSeries", func() (any, error) { return alphaVantageClient.GetInterestRatesTimeSeries() }},
+		{"GetInflationTimeSeries", func() (any, error) { return alphaVantageClient.GetInflationTimeSeries() }},
+		{"GetUnemploymentRateTimeSeries", func() (any, error) { return alphaVantageClient.GetUnemploymentRateTimeSeries() }},
+		{"GetCommodityTimeSeries", func() (any, error) { return alphaVantageClient.GetCommodityTimeSeries(domain.Coffee) }},
+		{"GetCryptocurrencyNews", func() (any, error) { return alphaVantageClient.GetCryptocurrencyNews("ETH") }},
+		{"GetEarningsCallTranscript", func() (any, error) {
+			return alphaVantageClient.GetEarningsCallTranscript("MSFT", 2025, domain.Q1Quarter)
+		}},
+		{"GetInsiderTransactions", func() (any, error) { return alphaVantageClient.GetInsiderTransactions("MSFT") }},
+		{"GetCurrencyExchangeRate", func() (any, error) { return alphaVantageClient.GetCurrencyExchangeRate(domain.GBP, domain.JPY) }},
+		{"GetCryptocurrenciesList", func() (any, error) { return coinGeckoClient.GetCryptocurrenciesList() }},
+		{"GetCryptocurrenciesMarketCaps", func() (any, error) { return coinGeckoClient.GetCryptocurrenciesMarketCaps() }},
+		{"GetCryptocurrencyDataById", func() (any, error) { return coinGeckoClient.GetCryptocurrencyDataById("solana") }},
+		{"GetMarketChart", func() (any, error) { return coinGeckoClient.GetMarketChart("bitcoin", 30) }},
+		{"GetMarketChartMax", func() (any, error) { return coinGeckoClient.GetMarketChart("bitcoin", 0) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.call(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestUnknownCryptocurrencyIsNotFound(t *testing.T) {
+	server := httptest.NewServer(fakeupstreams.NewHandler())
+	defer server.Close()
+
+	coinGeckoClient, _ := coingecko.NewCoinGeckoClient("demo", server.URL+fakeupstreams.CoinGeckoPrefix+"/api/v3")
+	if _, err := coinGeckoClient.GetCryptocurrencyDataById("not-a-coin"); err == nil {
+		t.Fatal("expected an error for an unknown cryptocurrency")
+	}
+}
@@ -0,0 +1,217 @@
+package fakeupstreams
+
+import (
+	"cmp"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The same canned company data is served for every stock symbol, ETF symbol, sector and industry
+func registerStockAnalysisRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+StockAnalysisPrefix+"/api/screener/s/f", handleStockAnalysisScreener)
+	mux.HandleFunc("GET "+StockAnalysisPrefix+"/news/__data.json", func(w http.ResponseWriter, r *http.Request) {
+		serveFixture(w, "stockanalysis/market_news.json")
+	})
+	mux.HandleFunc("GET "+StockAnalysisPrefix+"/stocks/{path...}", handleStockAnalysisStocks)
+	mux.HandleFunc("GET "+StockAnalysisPrefix+"/api/charts/{assetClass}/{symbol}/{period}/l", handleStockAnalysisCharts)
+
+	mux.HandleFunc("GET "+StockAnalysisApiPrefix+"/api/screener/e/f", func(w http.ResponseWriter, r *http.Request) {
+		serveFixture(w, "stockanalysis_api/etfs.json")
+	})
+	mux.HandleFunc("GET "+StockAnalysisApiPrefix+"/api/symbol/e/{symbol}/overview", func(w http.ResponseWriter, r *http.Request) {
+		serveFixture(w, "stockanalysis_api/etf_overview.json")
+	})
+}
+
+// stockAnalysisPages maps the paths of the __data.json pages under /stocks ("*" matches any segment) to their fixture.
+// The paths overlap (e.g. /stocks/industry/x and /stocks/x/forecast) so they can't be registered in the mux one by one.
+var stockAnalysisPages = []struct {
+	path    []string
+	fixture string
+}{
+	{[]string{"industry", "all", "__data.json"}, "industries.json"},
+	{[]string{"industry", "sectors", "__data.json"}, "sectors.json"},
+	{[]string{"industry", "*", "__data.json"}, "industry.json"},
+	{[]string{"sector", "*", "__data.json"}, "sector.json"},
+	{[]string{"*", "__data.json"}, "stock_news.json"},
+	{[]string{"*", "company", "__data.json"}, "stock_profile.json"},
+	{[]string{"*", "forecast", "__data.json"}, "forecast.json"},
+	{[]string{"*", "financials", "__data.json"}, "income_statement.json"},
+	{[]string{"*", "financials", "balance-sheet", "__data.json"}, "balance_sheet.json"},
+	{[]string{"*", "financials", "cash-flow-statement", "__data.json"}, "cash_flow.json"},
+	{[]string{"*", "financials", "ratios", "__data.json"}, "financial_ratios.json"},
+	{[]string{"*", "financials", "metrics", "__data.json"}, "kpis.json"},
+}
+
+func handleStockAnalysisStocks(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(r.PathValue("path"), "/")
+	for _, page := range stockAnalysisPages {
+		if matchSegments(page.path, segments) {
+			serveFixture(w, "stockanalysis/"+page.fixture)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// handleStockAnalysisScreener serves the stocks of the stock list matching the filters (column-over|under|is-value),
+// sorted by the m column and with the c columns only
+func handleStockAnalysisScreener(w http.ResponseWriter, r *http.Request) {
+	var list struct {
+		Data struct {
+			Data []map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := readFixture("stockanalysis/stock_list.json", &list); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	stocks := list.Data.Data
+	if f := r.URL.Query().Get("f"); f != "" {
+		for _, filter := range strings.Split(f, ",") {
+			parts := strings.SplitN(filter, "-", 3)
+			if len(parts) != 3 {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"status": 400, "error": "Invalid filter"})
+				return
+			}
+			column, operator, value := parts[0], parts[1], parts[2]
+			threshold, _ := strconv.ParseFloat(value, 64)
+			stocks = slices.DeleteFunc(stocks, func(stock map[string]any) bool {
+				switch v := stock[column].(type) {
+				case float64:
+					return !(operator == "over" && v > threshold || operator == "under" && v < threshold)
+				case string:
+					return !(operator == "is" && strings.EqualFold(v, value))
+				default:
+					return true
+				}
+			})
+		}
+	}
+
+	sort := r.URL.Query().Get("m")
+	slices.SortStableFunc(stocks, func(a, b map[string]any) int {
+		if x, ok := a[sort].(float64); ok {
+			y, _ := b[sort].(float64)
+			return cmp.Compare(x, y)
+		}
+		x, _ := a[sort].(string)
+		y, _ := b[sort].(string)
+		return strings.Compare(x, y)
+	})
+	if r.URL.Query().Get("s") == "desc" {
+		slices.Reverse(stocks)
+	}
+
+	columns := strings.Split(cmp.Or(r.URL.Query().Get("c"), "s,n"), ",")
+	data := make([]map[string]any, len(stocks))
+	for i, stock := range stocks {
+		data[i] = make(map[string]any, len(columns))
+		for _, column := range columns {
+			data[i][column] = stock[column]
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"status": 200, "data": map[string]any{"data": data, "resultsCount": len(data)}})
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+	return slices.EqualFunc(pattern, segments, func(p, s string) bool {
+		return p == "*" || p == s
+	})
+}
+
+type chartPoint struct {
+	T int64   `json:"t"`
+	C float64 `json:"c"`
+}
+
+// chartPeriods are the number of points and the trading days between two points of each chart period, the
+// generated history is five years long so MAX is 5Y. Like the real charts, 1Y spans a calendar year of weekdays.
+var chartPeriods = map[string]struct {
+	points int
+	step   int
+}{
+	"5D":  {5, 1},
+	"1M":  {21, 1},
+	"6M":  {126, 1},
+	"1Y":  {261, 1},
+	"5Y":  {260, 5},
+	"MAX": {260, 5},
+}
+
+func handleStockAnalysisCharts(w http.ResponseWriter, r *http.Request) {
+	symbol, period := r.PathValue("symbol"), r.PathValue("period")
+	closes := dailyCloses(symbol, time.Now().UTC())
+
+	var points []chartPoint
+	if period == "1D" {
+		points = intradayPrices(symbol, closes[len(closes)-1])
+	} else if period == "YTD" {
+		yearStart := time.Date(time.Now().UTC().Year(), 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+		for _, point := range closes {
+			if point.T >= yearStart {
+				points = append(points, point)
+			}
+		}
+	} else {
+		chartPeriod, ok := chartPeriods[period]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]any{"status": 404, "error": "Invalid period"})
+			return
+		}
+		for i := len(closes) - 1; i >= 0 && len(points) < chartPeriod.points; i -= chartPeriod.step {
+			points = append(points, closes[i])
+		}
+		slices.Reverse(points)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"status": 200, "data": points})
+}
+
+// dailyCloses generates five years of daily closes (trading days only) up to the last trading day before now.
+// The prices are a random walk seeded by the symbol, so a symbol always gets the same prices on the same day.
+func dailyCloses(symbol string, now time.Time) []chartPoint {
+	const tradingDays = 5 * 252
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := make([]time.Time, 0, tradingDays)
+	for len(days) < tradingDays {
+		day = day.AddDate(0, 0, -1)
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			days = append(days, day)
+		}
+	}
+	slices.Reverse(days)
+
+	s := seed(symbol)
+	rng := rand.New(rand.NewPCG(s, s>>1))
+	price := 20 + float64(s%480)
+	closes := make([]chartPoint, 0, len(days))
+	for _, d := range days {
+		price *= math.Exp(0.0003 + 0.015*rng.NormFloat64())
+		closes = append(closes, chartPoint{T: d.Unix(), C: math.Round(price*100) / 100})
+	}
+	return closes
+}
+
+// intradayPrices generates the 5 minute prices of the regular session (13:30-20:00 UTC) of the day after the given close
+func intradayPrices(symbol string, previousClose chartPoint) []chartPoint {
+	s := seed(symbol + "1D")
+	rng := rand.New(rand.NewPCG(s, s>>1))
+
+	open := time.Unix(previousClose.T, 0).UTC().Add(24*time.Hour + 13*time.Hour + 30*time.Minute)
+	price := previousClose.C
+	points := make([]chartPoint, 0, 78)
+	for i := range 78 {
+		price *= math.Exp(0.002 * rng.NormFloat64())
+		points = append(points, chartPoint{T: open.Add(time.Duration(i*5) * time.Minute).Unix(), C: math.Round(price*100) / 100})
+	}
+	return points
+}
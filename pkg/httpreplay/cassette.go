@@ -0,0 +1,112 @@
+package httpreplay
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// redactedQueryParams are never written to a cassette (API keys)
+var redactedQueryParams = []string{"apikey", "api_key", "x_cg_demo_api_key"}
+
+const redactedValue = "REDACTED"
+
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+type RecordedResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	// BodyFile is a file (relative to the cassette) with the body, it is used instead of Body when set
+	BodyFile string `json:"body_file,omitempty"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// Cassette is a file with recorded HTTP interactions
+type Cassette struct {
+	path         string
+	Interactions []Interaction `json:"interactions"`
+}
+
+func NewCassette(path string) *Cassette {
+	return &Cassette{path: path, Interactions: make([]Interaction, 0)}
+}
+
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	cassette := NewCassette(path)
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cassette %s: %w", path, err)
+	}
+
+	return cassette, nil
+}
+
+func (c *Cassette) Path() string {
+	return c.path
+}
+
+func (c *Cassette) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
+
+// ResponseBody returns the body of a recorded response, reading it from its BodyFile if needed
+func (c *Cassette) ResponseBody(response RecordedResponse) ([]byte, error) {
+	if response.BodyFile == "" {
+		return []byte(response.Body), nil
+	}
+
+	bodyPath := response.BodyFile
+	if !filepath.IsAbs(bodyPath) {
+		bodyPath = filepath.Join(filepath.Dir(c.path), bodyPath)
+	}
+
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body file: %w", err)
+	}
+	return body, nil
+}
+
+// normalizeURL redacts the secrets and sorts the query parameters so that the recorded
+// and the replayed requests can be compared
+func normalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	q := u.Query()
+	for param := range q {
+		if slices.Contains(redactedQueryParams, param) {
+			q.Set(param, redactedValue)
+		}
+	}
+	// Encode sorts by key
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
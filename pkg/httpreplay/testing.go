@@ -0,0 +1,82 @@
+package httpreplay
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	// ModeEnv selects whether the tests replay their cassettes (default) or record them again
+	ModeEnv = "HTTP_REPLAY_MODE"
+	// UpdateGoldenEnv rewrites the golden files with the current output when set to 1
+	UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+	ModeReplay = "replay"
+	ModeRecord = "record"
+)
+
+// UseCassette routes all the requests of the default HTTP transport (which all the scrapers and clients use)
+// through the given cassette for the duration of the test. In replay mode (the default) no request reaches
+// the network, with HTTP_REPLAY_MODE=record the real upstreams are called and the cassette is rewritten.
+func UseCassette(t testing.TB, cassettePath string) {
+	t.Helper()
+
+	var transport http.RoundTripper
+	var err error
+	if os.Getenv(ModeEnv) == ModeRecord {
+		transport, err = NewRecorder(cassettePath, http.DefaultTransport)
+	} else {
+		transport, err = NewReplayer(cassettePath)
+	}
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+
+	original := http.DefaultTransport
+	http.DefaultTransport = transport
+	t.Cleanup(func() { http.DefaultTransport = original })
+}
+
+// AssertGolden compares the JSON representation of got with the golden file.
+// With UPDATE_GOLDEN=1 the golden file is rewritten instead.
+func AssertGolden(t testing.TB, goldenPath string, got any) {
+	t.Helper()
+
+	actual, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal output: %v", err)
+	}
+	actual = append(actual, '\n')
+
+	if os.Getenv(UpdateGoldenEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(goldenPath, actual, 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with %s=1 to create it): %v", UpdateGoldenEnv, err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("output doesn't match %s (run with %s=1 to update it)\n--- expected\n%s\n--- actual\n%s",
+			goldenPath, UpdateGoldenEnv, truncate(expected), truncate(actual))
+	}
+}
+
+func truncate(data []byte) []byte {
+	const maxLength = 4000
+	if len(data) <= maxLength {
+		return data
+	}
+	return append(data[:maxLength:maxLength], []byte("\n...")...)
+}
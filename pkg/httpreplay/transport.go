@@ -0,0 +1,133 @@
+package httpreplay
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// recordedResponseHeaders are the response headers kept in a cassette, the parsers don't need the rest
+var recordedResponseHeaders = []string{"Content-Type"}
+
+// Recorder is an http.RoundTripper that sends the requests through the wrapped transport
+// and records every interaction to its cassette. The cassette is saved after each interaction
+// so that nothing is lost when the process crashes (which is usually when we want the recording).
+type Recorder struct {
+	mu        sync.Mutex
+	transport http.RoundTripper
+	cassette  *Cassette
+}
+
+func NewRecorder(cassettePath string, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{transport: transport, cassette: NewCassette(cassettePath)}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	headers := make(map[string]string)
+	for _, header := range recordedResponseHeaders {
+		if value := resp.Header.Get(header); value != "" {
+			headers[header] = value
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: RecordedRequest{Method: req.Method, URL: normalizeURL(req.URL.String())},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    headers,
+			Body:       string(body),
+		},
+	})
+	if err := r.cassette.Save(); err != nil {
+		return nil, fmt.Errorf("httpreplay: failed to save cassette: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Replayer is an http.RoundTripper that serves the responses of a cassette without touching the network.
+// Requests are matched by method and URL (with the secrets redacted), the interactions are served in the
+// recorded order and the last matching interaction is served again once all of them have been used.
+type Replayer struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+func NewReplayer(cassettePath string) (*Replayer, error) {
+	cassette, err := LoadCassette(cassettePath)
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{cassette: cassette, used: make([]bool, len(cassette.Interactions))}, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	requestURL := normalizeURL(req.URL.String())
+
+	r.mu.Lock()
+	match := -1
+	for i, interaction := range r.cassette.Interactions {
+		if interaction.Request.Method != req.Method || normalizeURL(interaction.Request.URL) != requestURL {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match != -1 {
+		r.used[match] = true
+	}
+	r.mu.Unlock()
+
+	if match == -1 {
+		return nil, fmt.Errorf("httpreplay: no recorded interaction for %s %s in %s", req.Method, requestURL, r.cassette.Path())
+	}
+
+	recorded := r.cassette.Interactions[match].Response
+	body, err := r.cassette.ResponseBody(recorded)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(http.Header)
+	for key, value := range recorded.Headers {
+		header.Set(key, value)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
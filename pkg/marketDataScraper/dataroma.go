@@ -3,17 +3,40 @@ package marketDataScraper
 import (
 	"fmt"
 	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/drift"
 	"market_data_mcp_server/pkg/errors"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
+// SuperInvestorsSchema declares the fields expected in the response of scrapeSuperInvestors
+var SuperInvestorsSchema = drift.Schema{
+	Name:       "super_investors",
+	MinRecords: 1,
+	Fields: []drift.Field{
+		{Path: "Name", Required: true},
+	},
+}
+
+// SuperInvestorPortfolioSchema declares the fields expected in the response of scrapeSuperInvestorPortfolio
+var SuperInvestorPortfolioSchema = drift.Schema{
+	Name: "super_investor_portfolio",
+	Fields: []drift.Field{
+		{Path: "Holdings", Required: true},
+		{Path: "Holdings.Stock", Required: true},
+		{Path: "Holdings.PortfolioPct", Required: true},
+		{Path: "Holdings.Value"},
+		{Path: "SectorAnalysis.Sector"},
+	},
+}
+
 // scrapeSuperInvestorsAndPortfolioLinks returns a map with key the super investor name
 // and value the link for the super investor portfolio
-func scrapeSuperInvestorsAndPortfolioLinks() (map[string]string, error) {
-	url := "https://www.dataroma.com/m/managers.php"
+func scrapeSuperInvestorsAndPortfolioLinks(dataromaBaseURL string) (map[string]string, error) {
+	url := dataromaBaseURL + "/m/managers.php"
 	client := &http.Client{}
 
 	req, err := http.NewRequest("GET", url, nil)
@@ -29,7 +52,7 @@ func scrapeSuperInvestorsAndPortfolioLinks() (map[string]string, error) {
 	defer rsp.Body.Close()
 
 	if rsp.StatusCode != 200 {
-		return nil, fmt.Errorf("Call to https://www.dataroma.com/m/managers.php failed with status code: %d", rsp.StatusCode)
+		return nil, fmt.Errorf("Call to %s failed with status code: %d", url, rsp.StatusCode)
 	}
 
 	doc, err := goquery.NewDocumentFromReader(rsp.Body)
@@ -43,16 +66,15 @@ func scrapeSuperInvestorsAndPortfolioLinks() (map[string]string, error) {
 		name := item.Text()
 		link, exists := item.Attr("href")
 		if exists && len(name) > 0 {
-			// fmt.Printf("Investor: %s, Link: %s\n", name, "https://www.dataroma.com"+link)
-			investorToPortfolioLinkMap[name] = "https://www.dataroma.com" + link
+			investorToPortfolioLinkMap[name] = dataromaBaseURL + link
 		}
 	})
 
 	return investorToPortfolioLinkMap, nil
 }
 
-func scrapeSuperInvestors() ([]domain.SuperInvestor, error) {
-	investorToPortfolioLinkMap, err := scrapeSuperInvestorsAndPortfolioLinks()
+func scrapeSuperInvestors(dataromaBaseURL string) ([]domain.SuperInvestor, error) {
+	investorToPortfolioLinkMap, err := scrapeSuperInvestorsAndPortfolioLinks(dataromaBaseURL)
 	if err != nil {
 		return nil, err
 	}
@@ -61,12 +83,16 @@ func scrapeSuperInvestors() ([]domain.SuperInvestor, error) {
 	for superInvestorName, _ := range investorToPortfolioLinkMap {
 		superInvestors = append(superInvestors, domain.SuperInvestor{Name: superInvestorName})
 	}
+	// Map iteration order is random, keep the results stable
+	slices.SortFunc(superInvestors, func(a, b domain.SuperInvestor) int {
+		return strings.Compare(a.Name, b.Name)
+	})
 
 	return superInvestors, nil
 }
 
-func scrapeSuperInvestorPortfolio(superInvestorName string) (domain.SuperInvestorPortfolio, error) {
-	investorToPortfolioLinkMap, err := scrapeSuperInvestorsAndPortfolioLinks()
+func scrapeSuperInvestorPortfolio(dataromaBaseURL string, superInvestorName string) (domain.SuperInvestorPortfolio, error) {
+	investorToPortfolioLinkMap, err := scrapeSuperInvestorsAndPortfolioLinks(dataromaBaseURL)
 	if err != nil {
 		return domain.SuperInvestorPortfolio{}, err
 	}
@@ -4,11 +4,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/drift"
 	"net/http"
 )
 
-func scrapeEtfOverview(symbol string) (domain.EtfOverview, error) {
-	url := fmt.Sprintf("https://api.stockanalysis.com/api/symbol/e/%s/overview", symbol)
+// EtfOverviewSchema declares the fields expected in the response of scrapeEtfOverview
+var EtfOverviewSchema = drift.Schema{
+	Name: "etf_overview",
+	Fields: []drift.Field{
+		{Path: "Description", Required: true},
+		{Path: "Aum", Required: true},
+		{Path: "AssetClass"},
+		{Path: "ExpenseRatio"},
+		{Path: "TopHoldings.Symbol"},
+	},
+}
+
+func scrapeEtfOverview(stockAnalysisApiBaseURL string, symbol string) (domain.EtfOverview, error) {
+	url := fmt.Sprintf("%s/api/symbol/e/%s/overview", stockAnalysisApiBaseURL, symbol)
 	resp, err := http.Get(url)
 	if err != nil {
 		return domain.EtfOverview{}, err
@@ -4,11 +4,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/drift"
 	"net/http"
 )
 
-func scrapeEtfs() ([]domain.Etf, error) {
-	url := "https://api.stockanalysis.com/api/screener/e/f?m=s&s=asc&c=s,n,assetClass,aum&i=etf"
+// EtfsSchema declares the fields expected in the response of scrapeEtfs
+var EtfsSchema = drift.Schema{
+	Name:       "etfs",
+	MinRecords: 1,
+	Fields: []drift.Field{
+		{Path: "Symbol", Required: true},
+		{Path: "Name", Required: true},
+		{Path: "AssetClass"},
+		{Path: "Aum"},
+	},
+}
+
+func scrapeEtfs(stockAnalysisApiBaseURL string) ([]domain.Etf, error) {
+	url := stockAnalysisApiBaseURL + "/api/screener/e/f?m=s&s=asc&c=s,n,assetClass,aum&i=etf"
 
 	resp, err := http.Get(url)
 	if err != nil {
@@ -1,209 +1,116 @@
 package marketDataScraper
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
 	"market_data_mcp_server/pkg/domain"
-	"net/http"
+	"market_data_mcp_server/pkg/drift"
+	"reflect"
 )
 
-func scrapeFinancialStatementData(url string) ([]map[string]interface{}, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return []map[string]interface{}{}, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return []map[string]interface{}{}, err
-	}
-
-	var rawData map[string]interface{}
-	if err := json.Unmarshal(body, &rawData); err != nil {
-		return []map[string]interface{}{}, err
-	}
-
-	// Extract "nodes" from rawData
-	nodes, ok := rawData["nodes"].([]interface{})
-	if !ok || len(nodes) < 3 {
-		return []map[string]interface{}{}, errors.New("unexpected structure in 'nodes'")
-	}
-
-	// Access the second element in "nodes" which contains the data we are interested in
-	nodeData, ok := nodes[2].(map[string]interface{})
-	if !ok {
-		return []map[string]interface{}{}, errors.New("unexpected structure in 'nodes[2]'")
-	}
-
-	data, ok := nodeData["data"].([]interface{})
-	if !ok {
-		return []map[string]interface{}{}, errors.New("unexpected structure in 'data'")
-	}
-
-	dataMap, ok := data[0].(map[string]interface{})
-	if !ok {
-		return []map[string]interface{}{}, errors.New("unexpected structure in 'data[0]'")
-	}
-
-	financialDataIndex, ok := dataMap["financialData"].(float64)
-	if !ok {
-		return []map[string]interface{}{}, errors.New("unexpected structure for 'financialData'")
-	}
-
-	// Retrieve data map at financial data index
-	statementDataMap, ok := data[int(financialDataIndex)].(map[string]interface{})
-	if !ok {
-		return []map[string]interface{}{}, errors.New("unexpected structure in balance sheet data map")
+// The fields expected in the responses of the financial statement scrapers, the datekey and the fiscal period
+// identify a statement and the other fields are the ones that are set for most companies
+var (
+	BalanceSheetsSchema = drift.Schema{
+		Name:       "balance_sheets",
+		MinRecords: 1,
+		Fields: append(statementPeriodFields(),
+			drift.Field{Path: "Cashneq"},
+			drift.Field{Path: "Assets"},
+			drift.Field{Path: "Liabilities"},
+			drift.Field{Path: "Equity"},
+			drift.Field{Path: "Liabilitiesequity"},
+		),
+	}
+	CashFlowsSchema = drift.Schema{
+		Name:       "cash_flows",
+		MinRecords: 1,
+		Fields: append(statementPeriodFields(),
+			drift.Field{Path: "NetIncomeCF"},
+			drift.Field{Path: "Ncfo"},
+			drift.Field{Path: "Ncf"},
+			drift.Field{Path: "Fcf"},
+		),
+	}
+	IncomeStatementsSchema = drift.Schema{
+		Name:       "income_statements",
+		MinRecords: 1,
+		Fields: append(statementPeriodFields(),
+			drift.Field{Path: "Revenue"},
+			drift.Field{Path: "Netinc"},
+			drift.Field{Path: "SharesDiluted"},
+			drift.Field{Path: "EpsDil"},
+		),
+	}
+	FinancialRatiosSchema = drift.Schema{
+		Name:       "financial_ratios",
+		MinRecords: 1,
+		Fields: append(statementPeriodFields(),
+			drift.Field{Path: "Marketcap"},
+			drift.Field{Path: "Ev"},
+			drift.Field{Path: "Ps"},
+			drift.Field{Path: "Pb"},
+		),
 	}
+)
 
-	statement_data := make(map[string][]interface{})
-	for field, fieldIndex := range statementDataMap {
-		fieldIndexFloat, ok := fieldIndex.(float64)
-		if !ok {
-			return []map[string]interface{}{}, errors.New("unexpected index type in fieldIndex")
-		}
-		// If index is out of bounds, we just skip it or keep it as is?
-		// Looking at kpis.go, they check if idx > 0 && idx < len(dataArray)
-		if fieldIndexFloat < 0 || int(fieldIndexFloat) >= len(data) {
-			continue
-		}
-		fieldValues := []interface{}{}
-		for _, index := range data[int(fieldIndexFloat)].([]interface{}) {
-			indexFloat, ok := index.(float64)
-			if !ok {
-				return []map[string]interface{}{}, errors.New("unexpected type in field values index")
-			}
-			idx := int(indexFloat)
-			if idx >= 0 && idx < len(data) {
-				fieldValues = append(fieldValues, data[idx])
-			} else {
-				fieldValues = append(fieldValues, indexFloat)
-			}
-		}
-		statement_data[field] = fieldValues
+func statementPeriodFields() []drift.Field {
+	return []drift.Field{
+		{Path: "Datekey", Required: true},
+		{Path: "FiscalYear", Required: true},
+		{Path: "FiscalQuarter", Required: true},
 	}
+}
 
-	// Converting the map of slices into a slice of maps to resemble final structure
-	statement_data_slice := make([]map[string]interface{}, 0, len(statement_data["datekey"]))
-	for i := 0; i < len(statement_data["datekey"]); i++ {
-		record := make(map[string]interface{})
-		for key, values := range statement_data {
-			// There are some cases where the data is missing for a particular key
-			if i >= len(values) {
-				continue
+// scrapeFinancialStatements decodes the statements of every period. financialData maps each field of the
+// statement to its values, one per period: it is decoded into a struct with a column (a slice) per field of T,
+// matched by name ignoring the case, so that a value of an unexpected type fails with a DevalueDecodeError.
+func scrapeFinancialStatements[T any](url string) ([]T, error) {
+	statementType := reflect.TypeFor[T]()
+	columnFields := make([]reflect.StructField, statementType.NumField())
+	for i := range columnFields {
+		field := statementType.Field(i)
+		columnFields[i] = reflect.StructField{Name: field.Name, Type: reflect.SliceOf(field.Type)}
+	}
+	pageType := reflect.StructOf([]reflect.StructField{
+		{Name: "FinancialData", Type: reflect.StructOf(columnFields), Tag: `devalue:"financialData,required"`},
+	})
+
+	page := reflect.New(pageType)
+	if err := fetchSvelteKitNode(url, 2, page.Interface()); err != nil {
+		return []T{}, err
+	}
+
+	// The datekey identifies the periods, the other columns can be shorter when the data is missing
+	columns := page.Elem().Field(0)
+	statements := make([]T, columns.FieldByName("Datekey").Len())
+	for i := range statements {
+		statement := reflect.ValueOf(&statements[i]).Elem()
+		for j := range columns.NumField() {
+			if column := columns.Field(j); i < column.Len() {
+				statement.Field(j).Set(column.Index(i))
 			}
-			record[key] = values[i]
 		}
-		statement_data_slice = append(statement_data_slice, record)
 	}
 
-	return statement_data_slice, nil
+	return statements, nil
 }
 
-func scrapeBalanceSheets(symbol string) ([]domain.BalanceSheet, error) {
-	url := fmt.Sprintf("https://stockanalysis.com/stocks/%s/financials/balance-sheet/__data.json?p=quarterly", symbol)
-	balanceSheetData, err := scrapeFinancialStatementData(url)
-	if err != nil {
-		return []domain.BalanceSheet{}, err
-	}
-
-	balanceSheets := make([]domain.BalanceSheet, 0, len(balanceSheetData))
-	for i := 0; i < len(balanceSheetData); i++ {
-		record := balanceSheetData[i]
-		// Marshal the map to JSON
-		jsonData, err := json.Marshal(record)
-		if err != nil {
-			return []domain.BalanceSheet{}, err
-		}
-		// Unmarshal the JSON data into an instance of balanceSheet
-		var balanceSheetRecord domain.BalanceSheet
-		err = json.Unmarshal(jsonData, &balanceSheetRecord)
-		if err != nil {
-			return []domain.BalanceSheet{}, err
-		}
-		balanceSheets = append(balanceSheets, balanceSheetRecord)
-	}
-	return balanceSheets, nil
+func scrapeBalanceSheets(stockAnalysisBaseURL string, symbol string) ([]domain.BalanceSheet, error) {
+	url := fmt.Sprintf("%s/stocks/%s/financials/balance-sheet/__data.json?p=quarterly", stockAnalysisBaseURL, symbol)
+	return scrapeFinancialStatements[domain.BalanceSheet](url)
 }
 
-func scrapeCashFlows(symbol string) ([]domain.CashFlow, error) {
-	url := fmt.Sprintf("https://stockanalysis.com/stocks/%s/financials/cash-flow-statement/__data.json?p=quarterly", symbol)
-	cashFlowData, err := scrapeFinancialStatementData(url)
-	if err != nil {
-		return []domain.CashFlow{}, err
-	}
-
-	cashFlows := make([]domain.CashFlow, 0, len(cashFlowData))
-	for i := 0; i < len(cashFlowData); i++ {
-		record := cashFlowData[i]
-		// Marshal the map to JSON
-		jsonData, err := json.Marshal(record)
-		if err != nil {
-			return []domain.CashFlow{}, err
-		}
-		// Unmarshal the JSON data into an instance of balanceSheet
-		var cashFlowRecord domain.CashFlow
-		err = json.Unmarshal(jsonData, &cashFlowRecord)
-		if err != nil {
-			return []domain.CashFlow{}, err
-		}
-		cashFlows = append(cashFlows, cashFlowRecord)
-	}
-	return cashFlows, nil
+func scrapeCashFlows(stockAnalysisBaseURL string, symbol string) ([]domain.CashFlow, error) {
+	url := fmt.Sprintf("%s/stocks/%s/financials/cash-flow-statement/__data.json?p=quarterly", stockAnalysisBaseURL, symbol)
+	return scrapeFinancialStatements[domain.CashFlow](url)
 }
 
-func scrapeIncomeStatements(symbol string) ([]domain.IncomeStatement, error) {
-	url := fmt.Sprintf("https://stockanalysis.com/stocks/%s/financials/__data.json?p=quarterly", symbol)
-	incomeStatementData, err := scrapeFinancialStatementData(url)
-	if err != nil {
-		return []domain.IncomeStatement{}, err
-	}
-
-	incomeStatements := make([]domain.IncomeStatement, 0, len(incomeStatementData))
-	for i := 0; i < len(incomeStatementData); i++ {
-		record := incomeStatementData[i]
-		// Marshal the map to JSON
-		jsonData, err := json.Marshal(record)
-		if err != nil {
-			return []domain.IncomeStatement{}, err
-		}
-		// Unmarshal the JSON data into an instance of balanceSheet
-		var incomeStatementRecord domain.IncomeStatement
-		err = json.Unmarshal(jsonData, &incomeStatementRecord)
-		if err != nil {
-			return []domain.IncomeStatement{}, err
-		}
-		incomeStatements = append(incomeStatements, incomeStatementRecord)
-	}
-	return incomeStatements, nil
+func scrapeIncomeStatements(stockAnalysisBaseURL string, symbol string) ([]domain.IncomeStatement, error) {
+	url := fmt.Sprintf("%s/stocks/%s/financials/__data.json?p=quarterly", stockAnalysisBaseURL, symbol)
+	return scrapeFinancialStatements[domain.IncomeStatement](url)
 }
 
-func scrapeFinancialRatios(symbol string) ([]domain.FinancialRatios, error) {
-	url := fmt.Sprintf("https://stockanalysis.com/stocks/%s/financials/ratios/__data.json?p=quarterly", symbol)
-	financialRatiosData, err := scrapeFinancialStatementData(url)
-	if err != nil {
-		return []domain.FinancialRatios{}, err
-	}
-
-	financialRatios := make([]domain.FinancialRatios, 0, len(financialRatiosData))
-	for i := 0; i < len(financialRatiosData); i++ {
-		record := financialRatiosData[i]
-		// Marshal the map to JSON
-		jsonData, err := json.Marshal(record)
-		if err != nil {
-			return []domain.FinancialRatios{}, err
-		}
-		// Unmarshal the JSON data into an instance of balanceSheet
-		var financialRatiosRecord domain.FinancialRatios
-		err = json.Unmarshal(jsonData, &financialRatiosRecord)
-		if err != nil {
-			return []domain.FinancialRatios{}, err
-		}
-		financialRatios = append(financialRatios, financialRatiosRecord)
-	}
-
-	return financialRatios, nil
+func scrapeFinancialRatios(stockAnalysisBaseURL string, symbol string) ([]domain.FinancialRatios, error) {
+	url := fmt.Sprintf("%s/stocks/%s/financials/ratios/__data.json?p=quarterly", stockAnalysisBaseURL, symbol)
+	return scrapeFinancialStatements[domain.FinancialRatios](url)
 }
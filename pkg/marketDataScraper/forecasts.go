@@ -1,238 +1,107 @@
 package marketDataScraper
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"market_data_mcp_server/pkg/domain"
-	"net/http"
+	"market_data_mcp_server/pkg/drift"
 )
 
-func scrapeStockForecast(symbol string) (domain.StockForecast, error) {
-	url := fmt.Sprintf("https://stockanalysis.com/stocks/%s/forecast/__data.json", symbol)
-	resp, err := http.Get(url)
-	if err != nil {
-		return domain.StockForecast{}, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return domain.StockForecast{}, err
-	}
-
-	var rawData map[string]interface{}
-	if err := json.Unmarshal(body, &rawData); err != nil {
-		return domain.StockForecast{}, err
-	}
-
-	// Accessing nodes data
-	nodes, ok := rawData["nodes"].([]interface{})
-	if !ok || len(nodes) < 3 {
-		return domain.StockForecast{}, fmt.Errorf("invalid response structure: nodes missing or too short")
-	}
-
-	nodeData, ok := nodes[2].(map[string]interface{})
-	if !ok {
-		return domain.StockForecast{}, fmt.Errorf("invalid response structure: node[2] is not a map")
-	}
-
-	data, ok := nodeData["data"].([]interface{})
-	if !ok || len(data) == 0 {
-		return domain.StockForecast{}, fmt.Errorf("invalid response structure: missing or empty data")
-	}
-
-	dataMap, ok := data[0].(map[string]interface{})
-	if !ok {
-		return domain.StockForecast{}, fmt.Errorf("invalid response structure: data[0] is not a map")
-	}
-
-	// Estimates Scraping
-	quarterlyEstimatesData := make(map[string][]interface{})
-
-	estimatesIdxVal, ok := dataMap["estimates"]
-	if !ok {
-		return domain.StockForecast{}, fmt.Errorf("missing estimates index")
-	}
-	estimatesDataIndex := int(estimatesIdxVal.(float64))
-
-	estimatesMap, ok := data[estimatesDataIndex].(map[string]interface{})
-	if !ok {
-		return domain.StockForecast{}, fmt.Errorf("invalid estimates data")
-	}
+// StockForecastSchema declares the fields expected in the response of scrapeStockForecast
+var StockForecastSchema = drift.Schema{
+	Name: "stock_forecast",
+	Fields: []drift.Field{
+		{Path: "Estimations", Required: true},
+		{Path: "Estimations.Date", Required: true},
+		{Path: "Estimations.FiscalYear", Required: true},
+		{Path: "Estimations.Eps"},
+		{Path: "Estimations.Revenue"},
+		{Path: "TargetPrice.Average"},
+	},
+}
 
-	tableIdxVal, ok := estimatesMap["table"]
-	if !ok {
-		return domain.StockForecast{}, fmt.Errorf("missing table index")
-	}
-	estimatesTableDataIndex := int(tableIdxVal.(float64))
+// estimate is a value of the quarterly estimates, stockanalysis replaces the ones reserved to its subscribers
+// with "[PRO]"
+type estimate struct {
+	value  float64
+	locked bool
+}
 
-	tableMap, ok := data[estimatesTableDataIndex].(map[string]interface{})
-	if !ok {
-		return domain.StockForecast{}, fmt.Errorf("invalid table data")
+func (e *estimate) UnmarshalDevalue(value any) error {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case float64:
+		e.value = value
+		return nil
+	case string:
+		if value == "[PRO]" {
+			e.locked = true
+			return nil
+		}
 	}
+	return fmt.Errorf("expected a number or \"[PRO]\", got %v", value)
+}
 
-	quarterlyIdxVal, ok := tableMap["quarterly"]
-	if !ok {
-		return domain.StockForecast{}, fmt.Errorf("missing quarterly index")
+// columnValue returns the value of the column for the given period, the zero value when the column is shorter
+func columnValue[T any](column []T, i int) T {
+	var zero T
+	if i >= len(column) {
+		return zero
 	}
-	quarterlyEstimatesDataIndex := int(quarterlyIdxVal.(float64))
+	return column[i]
+}
 
-	quarterlyEstimatesDataMap, ok := data[quarterlyEstimatesDataIndex].(map[string]interface{})
-	if !ok {
-		return domain.StockForecast{}, fmt.Errorf("invalid quarterly estimates data")
+func scrapeStockForecast(stockAnalysisBaseURL string, symbol string) (domain.StockForecast, error) {
+	url := fmt.Sprintf("%s/stocks/%s/forecast/__data.json", stockAnalysisBaseURL, symbol)
+	// The quarterly estimates are columns, one per field, with a value per quarter
+	var page struct {
+		Estimates struct {
+			Table struct {
+				Quarterly struct {
+					Dates         []string   `devalue:"dates"`
+					FiscalQuarter []string   `devalue:"fiscalQuarter"`
+					FiscalYear    []string   `devalue:"fiscalYear"`
+					Eps           []estimate `devalue:"eps"`
+					EpsGrowth     []estimate `devalue:"epsGrowth"`
+					Revenue       []estimate `devalue:"revenue"`
+					RevenueGrowth []estimate `devalue:"revenueGrowth"`
+				} `devalue:"quarterly,required"`
+			} `devalue:"table,required"`
+		} `devalue:"estimates,required"`
+		Targets *struct {
+			Average float32 `devalue:"average"`
+			High    float32 `devalue:"high"`
+			Low     float32 `devalue:"low"`
+			Median  float32 `devalue:"median"`
+		} `devalue:"targets"`
+	}
+	if err := fetchSvelteKitNode(url, 2, &page); err != nil {
+		return domain.StockForecast{}, err
 	}
 
-	for estimationField, estimationFieldIdx := range quarterlyEstimatesDataMap {
-		if estimationField == "lastDate" {
-			continue
-		}
-		idx, ok := estimationFieldIdx.(float64)
-		if !ok {
+	// Estimates Scraping, the quarters whose EPS is reserved to subscribers are skipped
+	quarterly := page.Estimates.Table.Quarterly
+	estimations := make([]domain.StockEstimation, 0, len(quarterly.Eps))
+	for i, eps := range quarterly.Eps {
+		if eps.locked {
 			continue
 		}
-		fieldData, ok := data[int(idx)].([]interface{})
-		if !ok {
-			continue
-		}
-
-		var estimationFieldValues []interface{}
-		for _, fieldValueIndex := range fieldData {
-			fvIdx, ok := fieldValueIndex.(float64)
-			if !ok {
-				continue
-			}
-			if int(fvIdx) >= len(data) {
-				continue
-			}
-			fieldValue := data[int(fvIdx)]
-			if fieldValue == "[PRO]" {
-				continue
-			}
-			estimationFieldValues = append(estimationFieldValues, fieldValue)
-		}
-		quarterlyEstimatesData[estimationField] = estimationFieldValues
-	}
-
-	// Prepare estimations_doc in the same format as in Python
-	var estimationsDoc []map[string]interface{}
-	epsData, ok := quarterlyEstimatesData["eps"]
-	if ok {
-		for i := 0; i < len(epsData); i++ {
-			record := make(map[string]interface{})
-			for key, values := range quarterlyEstimatesData {
-				if i < len(values) {
-					record[key] = values[i]
-				}
-			}
-			estimationsDoc = append(estimationsDoc, record)
-		}
-	}
-
-	// Create a slice of StockEstimation structs from estimationsDoc
-	estimations := make([]domain.StockEstimation, 0, len(estimationsDoc))
-	for _, record := range estimationsDoc {
-		var date string
-		if record["dates"] != nil {
-			if d, ok := record["dates"].(string); ok {
-				date = d
-			}
-		}
-
-		var eps float64
-		if record["eps"] != nil {
-			if e, ok := record["eps"].(float64); ok {
-				eps = e
-			}
-		}
-
-		var epsGrowth float64
-		if record["epsGrowth"] != nil {
-			if eg, ok := record["epsGrowth"].(float64); ok {
-				epsGrowth = eg
-			}
-		}
-
-		var revenue float64
-		if record["revenue"] != nil {
-			if r, ok := record["revenue"].(float64); ok {
-				revenue = r
-			}
-		}
-
-		var revenueGrowth float64
-		if record["revenueGrowth"] != nil {
-			if rg, ok := record["revenueGrowth"].(float64); ok {
-				revenueGrowth = rg
-			}
-		}
-
-		var fiscalQuarter string
-		if fq, ok := record["fiscalQuarter"].(string); ok {
-			fiscalQuarter = fq
-		}
-
-		var fiscalYear string
-		if fy, ok := record["fiscalYear"].(string); ok {
-			fiscalYear = fy
-		}
-
-		estimation := domain.StockEstimation{
-			Date:          date,
-			Eps:           eps,
-			EpsGrowth:     epsGrowth,
-			FiscalQuarter: fiscalQuarter,
-			FiscalYear:    fiscalYear,
-			Revenue:       revenue,
-			RevenueGrowth: revenueGrowth,
-		}
-		estimations = append(estimations, estimation)
+		estimations = append(estimations, domain.StockEstimation{
+			Date:          columnValue(quarterly.Dates, i),
+			Eps:           eps.value,
+			EpsGrowth:     columnValue(quarterly.EpsGrowth, i).value,
+			FiscalQuarter: columnValue(quarterly.FiscalQuarter, i),
+			FiscalYear:    columnValue(quarterly.FiscalYear, i),
+			Revenue:       columnValue(quarterly.Revenue, i).value,
+			RevenueGrowth: columnValue(quarterly.RevenueGrowth, i).value,
+		})
 	}
 
 	// Target Price Scraping
-	targetsIdxVal, ok := dataMap["targets"]
-	if !ok {
-		return domain.StockForecast{Estimations: estimations}, nil
-	}
-	targetDataIndex := int(targetsIdxVal.(float64))
-
-	targetDataMap, ok := data[targetDataIndex].(map[string]interface{})
-	if !ok {
+	if page.Targets == nil {
 		return domain.StockForecast{Estimations: estimations}, nil
 	}
-
-	targetPriceKeys := []string{"average", "high", "low", "median"}
-	targetPriceDoc := make(map[string]interface{})
-	for _, targetKey := range targetPriceKeys {
-		if val, ok := targetDataMap[targetKey]; ok {
-			targetValueIndex := int(val.(float64))
-			if targetValueIndex < len(data) {
-				targetPriceDoc[targetKey] = data[targetValueIndex]
-			}
-		}
-	}
-
-	// Create a StockTargetPrc struct from targetPriceDoc
-	var avg, high, low, median float32
-	if v, ok := targetPriceDoc["average"].(float64); ok {
-		avg = float32(v)
-	}
-	if v, ok := targetPriceDoc["high"].(float64); ok {
-		high = float32(v)
-	}
-	if v, ok := targetPriceDoc["low"].(float64); ok {
-		low = float32(v)
-	}
-	if v, ok := targetPriceDoc["median"].(float64); ok {
-		median = float32(v)
-	}
-
-	targetPrice := domain.StockTargetPrc{
-		Average: avg,
-		High:    high,
-		Low:     low,
-		Median:  median,
-	}
+	targetPrice := domain.StockTargetPrc(*page.Targets)
 
 	return domain.StockForecast{
 		Estimations: estimations,
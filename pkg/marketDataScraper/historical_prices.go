@@ -4,11 +4,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/drift"
 	"net/http"
 	"time"
 )
 
-func scrapeHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error) {
+// HistoricalPricesSchema declares the fields expected in the response of scrapeHistoricalPrices
+var HistoricalPricesSchema = drift.Schema{
+	Name: "historical_prices",
+	Fields: []drift.Field{
+		{Path: "Prices", Required: true},
+		{Path: "Prices.Date", Required: true},
+		{Path: "Prices.ClosePrice", Required: true},
+	},
+}
+
+func scrapeHistoricalPrices(stockAnalysisBaseURL string, ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error) {
 	var assetClassPrefix string
 	var periodPrefix string
 
@@ -17,6 +28,8 @@ func scrapeHistoricalPrices(ticker string, assetClass domain.AssetClass, period
 		assetClassPrefix = "s"
 	case domain.ETF:
 		assetClassPrefix = "e"
+	default:
+		return domain.HistoricalPrices{}, fmt.Errorf("historical prices are not available for the asset class %s", assetClass)
 	}
 
 	switch period {
@@ -26,15 +39,21 @@ func scrapeHistoricalPrices(ticker string, assetClass domain.AssetClass, period
 		periodPrefix = "5D"
 	case domain.Period1M:
 		periodPrefix = "1M"
+	case domain.PeriodYTD:
+		periodPrefix = "YTD"
 	case domain.Period6M:
 		periodPrefix = "6M"
 	case domain.Period1Y:
 		periodPrefix = "1Y"
 	case domain.Period5Y:
 		periodPrefix = "5Y"
+	case domain.PeriodMax:
+		periodPrefix = "MAX"
+	default:
+		return domain.HistoricalPrices{}, fmt.Errorf("invalid period: %s", period)
 	}
 
-	url := fmt.Sprintf("https://stockanalysis.com/api/charts/%s/%s/%s/l", assetClassPrefix, ticker, periodPrefix)
+	url := fmt.Sprintf("%s/api/charts/%s/%s/%s/l", stockAnalysisBaseURL, assetClassPrefix, ticker, periodPrefix)
 	resp, err := http.Get(url)
 	if err != nil {
 		return domain.HistoricalPrices{}, err
@@ -59,6 +78,10 @@ func scrapeHistoricalPrices(ticker string, assetClass domain.AssetClass, period
 		return domain.HistoricalPrices{}, err
 	}
 
+	if len(apiResponse.Data) == 0 {
+		return domain.HistoricalPrices{}, fmt.Errorf("no historical prices found for %s", ticker)
+	}
+
 	prices := make([]domain.Price, 0, len(apiResponse.Data))
 	for _, price := range apiResponse.Data {
 		price := domain.Price{
@@ -68,9 +91,11 @@ func scrapeHistoricalPrices(ticker string, assetClass domain.AssetClass, period
 		prices = append(prices, price)
 	}
 
-	firstPrice := prices[0].ClosePrice
-	lastPrice := prices[len(prices)-1].ClosePrice
-	percentChange := ((lastPrice - firstPrice) / firstPrice) * 100
+	var percentChange float64
+	if firstPrice := prices[0].ClosePrice; firstPrice != 0 {
+		lastPrice := prices[len(prices)-1].ClosePrice
+		percentChange = ((lastPrice - firstPrice) / firstPrice) * 100
+	}
 
 	return domain.HistoricalPrices{
 		Period:           period,
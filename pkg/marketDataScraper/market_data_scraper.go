@@ -1,126 +1,171 @@
 package marketDataScraper
 
 import (
+	"cmp"
 	"fmt"
 	"market_data_mcp_server/pkg/config"
 	"market_data_mcp_server/pkg/domain"
 	"market_data_mcp_server/pkg/services"
+	"strings"
 	"time"
 )
 
+// Default base URLs of the scraped websites
+const (
+	DefaultStockAnalysisBaseURL    = "https://stockanalysis.com"
+	DefaultStockAnalysisApiBaseURL = "https://api.stockanalysis.com"
+	DefaultDataromaBaseURL         = "https://www.dataroma.com"
+)
+
+// BaseURLs are the base URLs (scheme and host, optionally a path prefix) of the scraped websites,
+// they can point to a mirror, a proxy or a local stand-in like cmd/fake_upstreams
+type BaseURLs struct {
+	StockAnalysis    string
+	StockAnalysisApi string
+	Dataroma         string
+}
+
+// withDefaults returns the base URLs with the empty ones set to their default and without trailing slashes
+func (b BaseURLs) withDefaults() BaseURLs {
+	return BaseURLs{
+		StockAnalysis:    strings.TrimSuffix(cmp.Or(b.StockAnalysis, DefaultStockAnalysisBaseURL), "/"),
+		StockAnalysisApi: strings.TrimSuffix(cmp.Or(b.StockAnalysisApi, DefaultStockAnalysisApiBaseURL), "/"),
+		Dataroma:         strings.TrimSuffix(cmp.Or(b.Dataroma, DefaultDataromaBaseURL), "/"),
+	}
+}
+
 type MarketDataScraper struct {
+	baseURLs BaseURLs
+}
+
+func NewMarketDataScraper(baseURLs BaseURLs) *MarketDataScraper {
+	return &MarketDataScraper{baseURLs: baseURLs.withDefaults()}
 }
 
 // GetSectorStocks returns a list of stocks in a sector
 // sector parameter should be the domain.Sector.UrlName value
 func (mds MarketDataScraper) GetSectorStocks(sector string) ([]domain.SectorStock, error) {
-	return scrapeSectorStocks(sector)
+	return scrapeSectorStocks(mds.baseURLs.StockAnalysis, sector)
 }
 
 // GetSectors returns a list of sectors
 func (mds MarketDataScraper) GetSectors() ([]domain.Sector, error) {
-	return scrapeSectors()
+	return scrapeSectors(mds.baseURLs.StockAnalysis)
 }
 
 // GetIndustryStocks returns a list of stocks in an industry
 // industry parameter should be the domain.Industry.UrlName value
 func (mds MarketDataScraper) GetIndustryStocks(industry string) ([]domain.IndustryStock, error) {
-	return scrapeIndustryStocks(industry)
+	return scrapeIndustryStocks(mds.baseURLs.StockAnalysis, industry)
 }
 
 // GetIndustries returns a list of industries
 func (mds MarketDataScraper) GetIndustries() ([]domain.Industry, error) {
-	return scrapeIndustries()
+	return scrapeIndustries(mds.baseURLs.StockAnalysis)
 }
 
 // GetStockForecsat returns the forecast for a stock
 // symbol parameter should be in lowercase
 func (mds MarketDataScraper) GetStockForecast(symbol string) (domain.StockForecast, error) {
-	return scrapeStockForecast(symbol)
+	return scrapeStockForecast(mds.baseURLs.StockAnalysis, symbol)
 }
 
 // GetBalanceSheets returns a list of balance sheets for a stock
 // symbol parameter should be in lowercase
 func (mds MarketDataScraper) GetBalanceSheets(symbol string) ([]domain.BalanceSheet, error) {
-	return scrapeBalanceSheets(symbol)
+	return scrapeBalanceSheets(mds.baseURLs.StockAnalysis, symbol)
 }
 
 // GetIncomeStatements returns a list of income statements for a stock
 // symbol parameter should be in lowercase
 func (mds MarketDataScraper) GetIncomeStatements(symbol string) ([]domain.IncomeStatement, error) {
-	return scrapeIncomeStatements(symbol)
+	return scrapeIncomeStatements(mds.baseURLs.StockAnalysis, symbol)
 }
 
 // GetCashFlows returns a list of cash flows for a stock
 // symbol parameter should be in lowercase
 func (mds MarketDataScraper) GetCashFlows(symbol string) ([]domain.CashFlow, error) {
-	return scrapeCashFlows(symbol)
+	return scrapeCashFlows(mds.baseURLs.StockAnalysis, symbol)
 }
 
 // GetFinancialRatios returns a list of financial ratios for a stock
 // symbol parameter should be in lowercase
 func (mds MarketDataScraper) GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error) {
-	return scrapeFinancialRatios(symbol)
+	return scrapeFinancialRatios(mds.baseURLs.StockAnalysis, symbol)
 }
 
 // GetEtfs returns a list of ETFs
 func (mds MarketDataScraper) GetEtfs() ([]domain.Etf, error) {
-	return scrapeEtfs()
+	return scrapeEtfs(mds.baseURLs.StockAnalysisApi)
 }
 
 // GetEtfOverview returns an overview of an ETF
 // symbol parameter should be in lowercase
 func (mds MarketDataScraper) GetEtfOverview(symbol string) (domain.EtfOverview, error) {
-	return scrapeEtfOverview(symbol)
+	return scrapeEtfOverview(mds.baseURLs.StockAnalysisApi, symbol)
 }
 
 // GetStockProfile returns the profile of a stock
 // symbol parameter should be in lowercase
 func (mds MarketDataScraper) GetStockProfile(symbol string) (domain.StockProfile, error) {
-	return scrapeStockProfile(symbol)
+	return scrapeStockProfile(mds.baseURLs.StockAnalysis, symbol)
 }
 
 // GetMarketNews returns the most recent news of the stock markets
 func (mds MarketDataScraper) GetMarketNews() ([]domain.NewsArticle, error) {
-	return scrapeMarketNews()
+	return scrapeMarketNews(mds.baseURLs.StockAnalysis)
 }
 
 // GetStockNews returns the most recent news of the given stock symbol
 // symbol parameter should be in lowercase
 func (mds MarketDataScraper) GetStockNews(symbol string) ([]domain.NewsArticle, error) {
-	return scrapeStockNews(symbol)
+	return scrapeStockNews(mds.baseURLs.StockAnalysis, symbol)
 }
 
 // GetTickers returns a list of Tickers(stock symbol and company name)
 func (mds MarketDataScraper) GetTickers() ([]domain.Ticker, error) {
-	return scrapeStockList()
+	return scrapeStockList(mds.baseURLs.StockAnalysis)
+}
+
+// GetScreenerStocks returns the stocks of the screener matching the filters of the request
+func (mds MarketDataScraper) GetScreenerStocks(request domain.ScreenerRequest) ([]domain.ScreenedStock, error) {
+	return scrapeScreener(mds.baseURLs.StockAnalysis, request)
 }
 
 // GetSuperInvestors returns a list of SuperInvestors (Name)
 func (mds MarketDataScraper) GetSuperInvestors() ([]domain.SuperInvestor, error) {
-	return scrapeSuperInvestors()
+	return scrapeSuperInvestors(mds.baseURLs.Dataroma)
 }
 
 // GetSuperInvestorPortfolio returns the portfolio of the given super investor
 func (mds MarketDataScraper) GetSuperInvestorPortfolio(superInvestorName string) (domain.SuperInvestorPortfolio, error) {
-	return scrapeSuperInvestorPortfolio(superInvestorName)
+	return scrapeSuperInvestorPortfolio(mds.baseURLs.Dataroma, superInvestorName)
 }
 
 func (mds MarketDataScraper) GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error) {
-	return scrapeHistoricalPrices(ticker, assetClass, period)
+	return scrapeHistoricalPrices(mds.baseURLs.StockAnalysis, ticker, assetClass, period)
 }
 
 func (mds MarketDataScraper) GetCompanyKpiMetrics(symbol string) (domain.CompanyKpiMetrics, error) {
-	return scrapeCompanyKpiMetrics(symbol)
+	return scrapeCompanyKpiMetrics(mds.baseURLs.StockAnalysis, symbol)
 }
 
 type MarketDataScraperWithCache struct {
-	cache services.CacheService
-	conf  config.Config
+	cache    services.CacheService
+	conf     config.Config
+	baseURLs BaseURLs
 }
 
 func NewMarketDataScraperWithCache(cache services.CacheService, conf config.Config) *MarketDataScraperWithCache {
-	return &MarketDataScraperWithCache{cache: cache, conf: conf}
+	baseURLs := BaseURLs{
+		StockAnalysis:    conf.StockAnalysisBaseURL,
+		StockAnalysisApi: conf.StockAnalysisApiBaseURL,
+		Dataroma:         conf.DataromaBaseURL,
+	}
+	baseURLs = baseURLs.withDefaults()
+	// The keys don't name the website they were scraped from, the entries of the other base URLs are kept apart
+	namespace := strings.Join([]string{baseURLs.StockAnalysis, baseURLs.StockAnalysisApi, baseURLs.Dataroma}, " ")
+	return &MarketDataScraperWithCache{cache: services.NamespacedCache(cache, namespace), conf: conf, baseURLs: baseURLs}
 }
 
 // GetSectorStocks returns a list of stocks in a sector
@@ -135,7 +180,7 @@ func (mds MarketDataScraperWithCache) GetSectorStocks(sector string) ([]domain.S
 		return sectorStocks, nil
 	}
 
-	sectorStocks, err = scrapeSectorStocks(sector)
+	sectorStocks, err = scrapeSectorStocks(mds.baseURLs.StockAnalysis, sector)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +200,7 @@ func (mds MarketDataScraperWithCache) GetSectors() ([]domain.Sector, error) {
 		return sectors, nil
 	}
 
-	sectors, err = scrapeSectors()
+	sectors, err = scrapeSectors(mds.baseURLs.StockAnalysis)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +221,7 @@ func (mds MarketDataScraperWithCache) GetIndustryStocks(industry string) ([]doma
 		return industryStocks, nil
 	}
 
-	industryStocks, err = scrapeIndustryStocks(industry)
+	industryStocks, err = scrapeIndustryStocks(mds.baseURLs.StockAnalysis, industry)
 	if err != nil {
 		return nil, err
 	}
@@ -196,7 +241,7 @@ func (mds MarketDataScraperWithCache) GetIndustries() ([]domain.Industry, error)
 		return industries, nil
 	}
 
-	industries, err = scrapeIndustries()
+	industries, err = scrapeIndustries(mds.baseURLs.StockAnalysis)
 	if err != nil {
 		return nil, err
 	}
@@ -217,7 +262,7 @@ func (mds MarketDataScraperWithCache) GetStockForecast(symbol string) (domain.St
 		return stockForecast, nil
 	}
 
-	stockForecast, err = scrapeStockForecast(symbol)
+	stockForecast, err = scrapeStockForecast(mds.baseURLs.StockAnalysis, symbol)
 	if err != nil {
 		return domain.StockForecast{}, err
 	}
@@ -238,7 +283,7 @@ func (mds MarketDataScraperWithCache) GetBalanceSheets(symbol string) ([]domain.
 		return balanceSheets, nil
 	}
 
-	balanceSheets, err = scrapeBalanceSheets(symbol)
+	balanceSheets, err = scrapeBalanceSheets(mds.baseURLs.StockAnalysis, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -259,7 +304,7 @@ func (mds MarketDataScraperWithCache) GetIncomeStatements(symbol string) ([]doma
 		return incomeStatements, nil
 	}
 
-	incomeStatements, err = scrapeIncomeStatements(symbol)
+	incomeStatements, err = scrapeIncomeStatements(mds.baseURLs.StockAnalysis, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -280,7 +325,7 @@ func (mds MarketDataScraperWithCache) GetCashFlows(symbol string) ([]domain.Cash
 		return cashFlows, nil
 	}
 
-	cashFlows, err = scrapeCashFlows(symbol)
+	cashFlows, err = scrapeCashFlows(mds.baseURLs.StockAnalysis, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -301,7 +346,7 @@ func (mds MarketDataScraperWithCache) GetFinancialRatios(symbol string) ([]domai
 		return financialRatios, nil
 	}
 
-	financialRatios, err = scrapeFinancialRatios(symbol)
+	financialRatios, err = scrapeFinancialRatios(mds.baseURLs.StockAnalysis, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -321,7 +366,7 @@ func (mds MarketDataScraperWithCache) GetEtfs() ([]domain.Etf, error) {
 		return etfs, nil
 	}
 
-	etfs, err = scrapeEtfs()
+	etfs, err = scrapeEtfs(mds.baseURLs.StockAnalysisApi)
 	if err != nil {
 		return nil, err
 	}
@@ -342,7 +387,7 @@ func (mds MarketDataScraperWithCache) GetEtfOverview(symbol string) (domain.EtfO
 		return etfOverview, nil
 	}
 
-	etfOverview, err = scrapeEtfOverview(symbol)
+	etfOverview, err = scrapeEtfOverview(mds.baseURLs.StockAnalysisApi, symbol)
 	if err != nil {
 		return domain.EtfOverview{}, err
 	}
@@ -363,7 +408,7 @@ func (mds MarketDataScraperWithCache) GetStockProfile(symbol string) (domain.Sto
 		return stockProfile, nil
 	}
 
-	stockProfile, err = scrapeStockProfile(symbol)
+	stockProfile, err = scrapeStockProfile(mds.baseURLs.StockAnalysis, symbol)
 	if err != nil {
 		return domain.StockProfile{}, err
 	}
@@ -383,7 +428,7 @@ func (mds MarketDataScraperWithCache) GetMarketNews() ([]domain.NewsArticle, err
 		return marketNews, nil
 	}
 
-	marketNews, err = scrapeMarketNews()
+	marketNews, err = scrapeMarketNews(mds.baseURLs.StockAnalysis)
 	if err != nil {
 		return nil, err
 	}
@@ -404,7 +449,7 @@ func (mds MarketDataScraperWithCache) GetStockNews(symbol string) ([]domain.News
 		return stockNews, nil
 	}
 
-	stockNews, err = scrapeStockNews(symbol)
+	stockNews, err = scrapeStockNews(mds.baseURLs.StockAnalysis, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -424,7 +469,7 @@ func (mds MarketDataScraperWithCache) GetTickers() ([]domain.Ticker, error) {
 		return tickers, nil
 	}
 
-	tickers, err = scrapeStockList()
+	tickers, err = scrapeStockList(mds.baseURLs.StockAnalysis)
 	if err != nil {
 		return nil, err
 	}
@@ -433,6 +478,32 @@ func (mds MarketDataScraperWithCache) GetTickers() ([]domain.Ticker, error) {
 	return tickers, nil
 }
 
+// GetScreenerStocks returns the stocks of the screener matching the filters of the request
+func (mds MarketDataScraperWithCache) GetScreenerStocks(request domain.ScreenerRequest) ([]domain.ScreenedStock, error) {
+	// The URL of the request identifies the screen
+	url, err := screenerURL(mds.baseURLs.StockAnalysis, request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if the data is in the cache
+	var stocks []domain.ScreenedStock
+
+	key := fmt.Sprintf("screener_%s", url)
+	err = mds.cache.Get(key, &stocks)
+	if err == nil {
+		return stocks, nil
+	}
+
+	stocks, err = scrapeScreener(mds.baseURLs.StockAnalysis, request)
+	if err != nil {
+		return nil, err
+	}
+
+	mds.cache.Set(key, stocks, time.Duration(mds.conf.CacheTtl)*time.Second)
+	return stocks, nil
+}
+
 // GetSuperInvestors returns a list of SuperInvestors (Name)
 func (mds MarketDataScraperWithCache) GetSuperInvestors() ([]domain.SuperInvestor, error) {
 	// Check if the data is in the cache
@@ -444,7 +515,7 @@ func (mds MarketDataScraperWithCache) GetSuperInvestors() ([]domain.SuperInvesto
 		return superInvestors, nil
 	}
 
-	superInvestors, err = scrapeSuperInvestors()
+	superInvestors, err = scrapeSuperInvestors(mds.baseURLs.Dataroma)
 	if err != nil {
 		return nil, err
 	}
@@ -464,7 +535,7 @@ func (mds MarketDataScraperWithCache) GetSuperInvestorPortfolio(superInvestorNam
 		return superInvestorPortfolio, nil
 	}
 
-	superInvestorPortfolio, err = scrapeSuperInvestorPortfolio(superInvestorName)
+	superInvestorPortfolio, err = scrapeSuperInvestorPortfolio(mds.baseURLs.Dataroma, superInvestorName)
 	if err != nil {
 		return domain.SuperInvestorPortfolio{}, err
 	}
@@ -483,7 +554,7 @@ func (mds MarketDataScraperWithCache) GetHistoricalPrices(ticker string, assetCl
 		return historicalPrices, nil
 	}
 
-	historicalPrices, err = scrapeHistoricalPrices(ticker, assetClass, period)
+	historicalPrices, err = scrapeHistoricalPrices(mds.baseURLs.StockAnalysis, ticker, assetClass, period)
 	if err != nil {
 		return domain.HistoricalPrices{}, err
 	}
@@ -502,7 +573,7 @@ func (mds MarketDataScraperWithCache) GetCompanyKpiMetrics(symbol string) (domai
 		return companyKpiMetrics, nil
 	}
 
-	companyKpiMetrics, err = scrapeCompanyKpiMetrics(symbol)
+	companyKpiMetrics, err = scrapeCompanyKpiMetrics(mds.baseURLs.StockAnalysis, symbol)
 	if err != nil {
 		return domain.CompanyKpiMetrics{}, err
 	}
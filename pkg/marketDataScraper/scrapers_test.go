@@ -0,0 +1,129 @@
+package marketDataScraper
+
+import (
+	stderrors "errors"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/drift"
+	"market_data_mcp_server/pkg/errors"
+	"market_data_mcp_server/pkg/httpreplay"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// The scrapers build time.Time values in the local timezone, keep the golden files independent of it
+	time.Local = time.UTC
+	os.Exit(m.Run())
+}
+
+func TestScrapers(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema drift.Schema
+		scrape func() (any, error)
+	}{
+		{"stock_list", TickersSchema, func() (any, error) { return scrapeStockList(DefaultStockAnalysisBaseURL) }},
+		{"etfs", EtfsSchema, func() (any, error) { return scrapeEtfs(DefaultStockAnalysisApiBaseURL) }},
+		{"etf_overview", EtfOverviewSchema, func() (any, error) { return scrapeEtfOverview(DefaultStockAnalysisApiBaseURL, "eyld") }},
+		{"balance_sheets", BalanceSheetsSchema, func() (any, error) { return scrapeBalanceSheets(DefaultStockAnalysisBaseURL, "aapl") }},
+		{"cash_flows", CashFlowsSchema, func() (any, error) { return scrapeCashFlows(DefaultStockAnalysisBaseURL, "aapl") }},
+		{"income_statements", IncomeStatementsSchema, func() (any, error) { return scrapeIncomeStatements(DefaultStockAnalysisBaseURL, "aapl") }},
+		{"financial_ratios", FinancialRatiosSchema, func() (any, error) { return scrapeFinancialRatios(DefaultStockAnalysisBaseURL, "aapl") }},
+		{"stock_forecast", StockForecastSchema, func() (any, error) { return scrapeStockForecast(DefaultStockAnalysisBaseURL, "aapl") }},
+		{"stock_profile", StockProfileSchema, func() (any, error) { return scrapeStockProfile(DefaultStockAnalysisBaseURL, "aapl") }},
+		{"industries", IndustriesSchema, func() (any, error) { return scrapeIndustries(DefaultStockAnalysisBaseURL) }},
+		{"industry_stocks", IndustryStocksSchema, func() (any, error) { return scrapeIndustryStocks(DefaultStockAnalysisBaseURL, "biotechnology") }},
+		{"sectors", SectorsSchema, func() (any, error) { return scrapeSectors(DefaultStockAnalysisBaseURL) }},
+		{"sector_stocks", SectorStocksSchema, func() (any, error) { return scrapeSectorStocks(DefaultStockAnalysisBaseURL, "financials") }},
+		{"market_news", NewsSchema, func() (any, error) { return scrapeMarketNews(DefaultStockAnalysisBaseURL) }},
+		{"stock_news", NewsSchema, func() (any, error) { return scrapeStockNews(DefaultStockAnalysisBaseURL, "abnb") }},
+		{"super_investors", SuperInvestorsSchema, func() (any, error) { return scrapeSuperInvestors(DefaultDataromaBaseURL) }},
+		{"super_investor_portfolio", SuperInvestorPortfolioSchema, func() (any, error) {
+			return scrapeSuperInvestorPortfolio(DefaultDataromaBaseURL, "Bill & Melinda Gates Foundation Trust")
+		}},
+		{"historical_prices", HistoricalPricesSchema, func() (any, error) {
+			return scrapeHistoricalPrices(DefaultStockAnalysisBaseURL, "aapl", domain.Stock, domain.Period5D)
+		}},
+		{"company_kpi_metrics", CompanyKpiMetricsSchema, func() (any, error) { return scrapeCompanyKpiMetrics(DefaultStockAnalysisBaseURL, "AAPL") }},
+		{"screener", ScreenerSchema, func() (any, error) {
+			return scrapeScreener(DefaultStockAnalysisBaseURL, domain.ScreenerRequest{
+				Columns: []string{"market_cap", "pe", "sector", "gross_margin"},
+				Filters: []domain.ScreenerFilter{
+					{Field: "market_cap", Operator: domain.GreaterThanScreenerOperator, Value: 100e9},
+					{Field: "sector", Operator: domain.EqualScreenerOperator, Texts: []string{"Technology"}},
+				},
+				Sort:       "market_cap",
+				Descending: true,
+			})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreplay.UseCassette(t, filepath.Join("testdata", "cassettes", tt.name+".json"))
+
+			got, err := tt.scrape()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			httpreplay.AssertGolden(t, filepath.Join("testdata", "golden", tt.name+".json"), got)
+
+			// The recorded responses are the reference layout of the websites, they must not drift from the schemas
+			if report := drift.Validate(tt.schema, got); report.HasDrift() {
+				t.Errorf("unexpected drift: %+v", report.Issues)
+			}
+		})
+	}
+}
+
+func TestScrapeHistoricalPricesWithoutData(t *testing.T) {
+	httpreplay.UseCassette(t, filepath.Join("testdata", "cassettes", "historical_prices_empty.json"))
+
+	if _, err := scrapeHistoricalPrices(DefaultStockAnalysisBaseURL, "voo", domain.ETF, domain.Period1D); err == nil {
+		t.Fatal("expected an error for a response without prices")
+	}
+}
+
+// The sector pages used to have the data in the second node, make sure such a response fails instead of panicking
+func TestScrapeSectorStocksLegacyFormat(t *testing.T) {
+	httpreplay.UseCassette(t, filepath.Join("testdata", "cassettes", "sector_stocks_legacy_format.json"))
+
+	if _, err := scrapeSectorStocks(DefaultStockAnalysisBaseURL, "financials"); err == nil {
+		t.Fatal("expected an error for a response in the legacy format")
+	}
+}
+
+func TestScrapeSuperInvestorPortfolioNotFound(t *testing.T) {
+	httpreplay.UseCassette(t, filepath.Join("testdata", "cassettes", "super_investors.json"))
+
+	if _, err := scrapeSuperInvestorPortfolio(DefaultDataromaBaseURL, "Not A Super Investor"); err == nil {
+		t.Fatal("expected an error for an unknown super investor")
+	}
+}
+
+// A field whose type changes upstream fails the scraper with the path of the value instead of a zero value
+func TestScrapersTypeChanges(t *testing.T) {
+	httpreplay.UseCassette(t, filepath.Join("testdata", "cassettes", "type_changes.json"))
+
+	tests := []struct {
+		name     string
+		scrape   func() (any, error)
+		wantPath string
+	}{
+		{"stock_forecast", func() (any, error) { return scrapeStockForecast(DefaultStockAnalysisBaseURL, "aapl") }, "nodes[2].estimates.table.quarterly.eps[0]"},
+		{"balance_sheets", func() (any, error) { return scrapeBalanceSheets(DefaultStockAnalysisBaseURL, "aapl") }, "nodes[2].financialData.Cashneq[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.scrape()
+			var decodeErr errors.DevalueDecodeError
+			if !stderrors.As(err, &decodeErr) || decodeErr.Path != tt.wantPath {
+				t.Errorf("expected a DevalueDecodeError at %s, got %v", tt.wantPath, err)
+			}
+		})
+	}
+}
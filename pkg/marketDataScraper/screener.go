@@ -0,0 +1,148 @@
+package marketDataScraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/drift"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ScreenerSchema declares the fields expected in the response of scrapeScreener
+var ScreenerSchema = drift.Schema{
+	Name:       "screener",
+	MinRecords: 1,
+	Fields: []drift.Field{
+		{Path: "Symbol", Required: true},
+		{Path: "Name", Required: true},
+	},
+}
+
+// screenerOperators are the operators of the screener filters, written column-operator-value
+var screenerOperators = map[domain.ScreenerOperator]string{
+	domain.GreaterThanScreenerOperator: "over",
+	domain.LessThanScreenerOperator:    "under",
+	domain.EqualScreenerOperator:       "is",
+}
+
+// screenerURL returns the URL of the screener request, the fields of the request must be in the catalogue
+func screenerURL(stockAnalysisBaseURL string, request domain.ScreenerRequest) (string, error) {
+	column := func(name string) (string, error) {
+		field, ok := domain.LookupScreenerField(name)
+		if !ok {
+			return "", fmt.Errorf("unknown screener field %s", name)
+		}
+		return field.Column, nil
+	}
+
+	columns := []string{"s", "n"}
+	for _, name := range request.Columns {
+		c, err := column(name)
+		if err != nil {
+			return "", err
+		}
+		if !slices.Contains(columns, c) {
+			columns = append(columns, c)
+		}
+	}
+
+	var filters []string
+	for _, filter := range request.Filters {
+		c, err := column(filter.Field)
+		if err != nil {
+			return "", err
+		}
+		operator, ok := screenerOperators[filter.Operator]
+		if !ok {
+			return "", fmt.Errorf("the screener doesn't support the operator %s", filter.Operator)
+		}
+		value := strconv.FormatFloat(filter.Value, 'f', -1, 64)
+		if filter.Operator == domain.EqualScreenerOperator {
+			if len(filter.Texts) != 1 {
+				return "", fmt.Errorf("the screener %s filter needs one value", filter.Operator)
+			}
+			value = filter.Texts[0]
+		}
+		filters = append(filters, c+"-"+operator+"-"+value)
+	}
+
+	sort, order := "s", "asc"
+	if request.Sort != "" {
+		c, err := column(request.Sort)
+		if err != nil {
+			return "", err
+		}
+		sort = c
+	}
+	if request.Descending {
+		order = "desc"
+	}
+
+	query := url.Values{}
+	query.Set("m", sort)
+	query.Set("s", order)
+	query.Set("c", strings.Join(columns, ","))
+	if len(filters) > 0 {
+		query.Set("f", strings.Join(filters, ","))
+	}
+	query.Set("i", "stocks")
+	return stockAnalysisBaseURL + "/api/screener/s/f?" + query.Encode(), nil
+}
+
+func scrapeScreener(stockAnalysisBaseURL string, request domain.ScreenerRequest) ([]domain.ScreenedStock, error) {
+	url, err := screenerURL(stockAnalysisBaseURL, request)
+	if err != nil {
+		return []domain.ScreenedStock{}, err
+	}
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return []domain.ScreenedStock{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return []domain.ScreenedStock{}, fmt.Errorf("the screener responded with the status %d", resp.StatusCode)
+	}
+
+	var apiResponse struct {
+		Status int `json:"status"`
+		Data   struct {
+			Data []map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&apiResponse)
+	if err != nil {
+		return []domain.ScreenedStock{}, err
+	}
+
+	stocks := make([]domain.ScreenedStock, 0, len(apiResponse.Data.Data))
+	for _, row := range apiResponse.Data.Data {
+		stock := domain.ScreenedStock{Values: make(map[string]any)}
+		stock.Symbol, _ = row["s"].(string)
+		stock.Name, _ = row["n"].(string)
+		for _, name := range request.Columns {
+			field, _ := domain.LookupScreenerField(name)
+			// The values are null when unknown, and the numbers sometimes strings
+			switch value := row[field.Column].(type) {
+			case float64:
+				if field.Type == domain.NumberScreenerField {
+					stock.Values[name] = value
+				}
+			case string:
+				if field.Type == domain.TextScreenerField {
+					stock.Values[name] = value
+				} else if number, err := strconv.ParseFloat(value, 64); err == nil {
+					stock.Values[name] = number
+				}
+			}
+		}
+		stocks = append(stocks, stock)
+	}
+
+	return stocks, nil
+}
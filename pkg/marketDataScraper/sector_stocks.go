@@ -1,83 +1,39 @@
 package marketDataScraper
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"market_data_mcp_server/pkg/domain"
-	"net/http"
+	"market_data_mcp_server/pkg/drift"
 )
 
-func scrapeSectorStocks(sector string) ([]domain.SectorStock, error) {
-	url := fmt.Sprintf("https://stockanalysis.com/stocks/sector/%s/__data.json", sector)
-	resp, err := http.Get(url)
-	if err != nil {
-		return []domain.SectorStock{}, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return []domain.SectorStock{}, err
-	}
+// SectorStocksSchema declares the fields expected in the response of scrapeSectorStocks
+var SectorStocksSchema = drift.Schema{
+	Name:       "sector_stocks",
+	MinRecords: 1,
+	Fields: []drift.Field{
+		{Path: "Symbol", Required: true},
+		{Path: "CompanyName", Required: true},
+		{Path: "MarketCap"},
+	},
+}
 
-	var rawData map[string]interface{}
-	if err := json.Unmarshal(body, &rawData); err != nil {
+func scrapeSectorStocks(stockAnalysisBaseURL string, sector string) ([]domain.SectorStock, error) {
+	// marketCap is missing for some of the stocks
+	var page struct {
+		Data []struct {
+			Symbol      string  `devalue:"s,required"`
+			CompanyName string  `devalue:"n"`
+			MarketCap   float32 `devalue:"marketCap"`
+		} `devalue:"data,required"`
+	}
+	url := fmt.Sprintf("%s/stocks/sector/%s/__data.json", stockAnalysisBaseURL, sector)
+	if err := fetchSvelteKitNode(url, 2, &page); err != nil {
 		return []domain.SectorStock{}, err
 	}
 
-	// Extract "nodes" from rawData
-	nodes, ok := rawData["nodes"].([]interface{})
-	if !ok || len(nodes) < 2 {
-		return []domain.SectorStock{}, fmt.Errorf("unexpected structure in 'nodes'")
-	}
-
-	// Access the second element in "nodes" which contains the data we are interested in
-	nodeData, ok := nodes[2].(map[string]interface{})
-	if !ok {
-		return []domain.SectorStock{}, fmt.Errorf("unexpected structure in 'nodes[1]'")
-	}
-
-	data, ok := nodeData["data"].([]interface{})
-	if !ok {
-		return []domain.SectorStock{}, fmt.Errorf("unexpected structure in 'data'")
-	}
-
-	dataMap, ok := data[0].(map[string]interface{})
-	if !ok {
-		return []domain.SectorStock{}, fmt.Errorf("unexpected structure in 'data[0]'")
-	}
-
-	stocksArrayDataIndex, ok := dataMap["data"].(float64)
-	if !ok {
-		return []domain.SectorStock{}, fmt.Errorf("unexpected structure for 'data'")
-	}
-
-	stocksDataIndicesArray := data[int(stocksArrayDataIndex)].([]interface{})
-	if !ok {
-		return []domain.SectorStock{}, fmt.Errorf("unexpected structure for 'data[stocksArrayDataIndex]'")
-	}
-
-	stocks := make([]domain.SectorStock, 0, len(stocksDataIndicesArray))
-	for i := 0; i < len(stocksDataIndicesArray); i++ {
-		stockDataIndex := int(stocksDataIndicesArray[i].(float64))
-		stockData := data[stockDataIndex].(map[string]interface{})
-		stockSymbolIndex := int(stockData["s"].(float64))
-		stockCompanyNameIndex := int(stockData["n"].(float64))
-
-		var marketCap float32
-		stockMarketCapIndex, ok := stockData["marketCap"].(float64)
-		if !ok {
-			// Do nothing in this case since there are times that this fied doesn't exist
-		} else {
-			marketCap = float32(data[int(stockMarketCapIndex)].(float64))
-		}
-		stock := domain.SectorStock{
-			Symbol:      data[stockSymbolIndex].(string),
-			CompanyName: data[stockCompanyNameIndex].(string),
-			MarketCap:   marketCap,
-		}
-		stocks = append(stocks, stock)
+	stocks := make([]domain.SectorStock, 0, len(page.Data))
+	for _, stock := range page.Data {
+		stocks = append(stocks, domain.SectorStock(stock))
 	}
 
 	return stocks, nil
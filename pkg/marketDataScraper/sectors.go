@@ -1,83 +1,46 @@
 package marketDataScraper
 
 import (
-	"encoding/json"
-	"io"
 	"market_data_mcp_server/pkg/domain"
-	"net/http"
+	"market_data_mcp_server/pkg/drift"
 )
 
-func scrapeSectors() ([]domain.Sector, error) {
-	url := "https://stockanalysis.com/stocks/industry/sectors/__data.json"
-	resp, err := http.Get(url)
-	if err != nil {
-		return []domain.Sector{}, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return []domain.Sector{}, err
-	}
-
-	var rawData map[string]interface{}
-	if err := json.Unmarshal(body, &rawData); err != nil {
-		return []domain.Sector{}, err
-	}
-
-	// Extract "nodes" from rawData
-	nodes, ok := rawData["nodes"].([]interface{})
-	if !ok || len(nodes) < 3 {
-		return []domain.Sector{}, err
-	}
-
-	// Access the second element in "nodes" which contains the data we are interested in
-	nodeData, ok := nodes[2].(map[string]interface{})
-	if !ok {
-		return []domain.Sector{}, err
-	}
-
-	data, ok := nodeData["data"].([]interface{})
-	if !ok {
-		return []domain.Sector{}, err
-	}
+// SectorsSchema declares the fields expected in the response of scrapeSectors
+var SectorsSchema = drift.Schema{
+	Name:       "sectors",
+	MinRecords: 5,
+	Fields: []drift.Field{
+		{Path: "Name", Required: true},
+		{Path: "UrlName", Required: true},
+		{Path: "NumberOfStocks", Required: true},
+		{Path: "MarketCap", Required: true},
+		{Path: "PeRatio"},
+		{Path: "DividendYieldPct"},
+		{Path: "ProfitMarginPct"},
+		{Path: "OneYearChangePct"},
+	},
+}
 
-	dataMap, ok := data[0].(map[string]interface{})
-	if !ok {
-		return []domain.Sector{}, err
+func scrapeSectors(stockAnalysisBaseURL string) ([]domain.Sector, error) {
+	var page struct {
+		Sectors []struct {
+			Name             string  `devalue:"sector_name,required"`
+			UrlName          string  `devalue:"url,required"`
+			NumberOfStocks   int     `devalue:"stocks"`
+			MarketCap        float32 `devalue:"marketCap"`
+			DividendYieldPct float32 `devalue:"dividendYield"`
+			PeRatio          float32 `devalue:"peRatio"`
+			ProfitMarginPct  float32 `devalue:"profitMargin"`
+			OneYearChangePct float32 `devalue:"ch1y"`
+		} `devalue:"sectors,required"`
 	}
-
-	sectorsDataIndex, ok := dataMap["sectors"].(float64)
-	if !ok {
+	if err := fetchSvelteKitNode(stockAnalysisBaseURL+"/stocks/industry/sectors/__data.json", 2, &page); err != nil {
 		return []domain.Sector{}, err
 	}
 
-	sectorDataIndicesArray := data[int(sectorsDataIndex)].([]interface{})
-
-	sectors := make([]domain.Sector, 0, len(sectorDataIndicesArray))
-	for i := 0; i < len(sectorDataIndicesArray); i++ {
-		sectorDataIndex := int(sectorDataIndicesArray[i].(float64))
-		sectorData := data[sectorDataIndex].(map[string]interface{})
-		sectorNameIndex := int(sectorData["sector_name"].(float64))
-		sectorUrlNameIndex := int(sectorData["url"].(float64))
-		numberOfStocksIndex := int(sectorData["stocks"].(float64))
-		marketCapIndex := int(sectorData["marketCap"].(float64))
-		dividendYieldIndex := int(sectorData["dividendYield"].(float64))
-		peRatioIndex := int(sectorData["peRatio"].(float64))
-		profitMarginIndex := int(sectorData["profitMargin"].(float64))
-		oneYearChangeIndex := int(sectorData["ch1y"].(float64))
-
-		sector := domain.Sector{
-			Name:             data[sectorNameIndex].(string),
-			UrlName:          data[sectorUrlNameIndex].(string),
-			NumberOfStocks:   int(data[numberOfStocksIndex].(float64)),
-			MarketCap:        float32(data[marketCapIndex].(float64)),
-			DividendYieldPct: float32(data[dividendYieldIndex].(float64)),
-			PeRatio:          float32(data[peRatioIndex].(float64)),
-			ProfitMarginPct:  float32(data[profitMarginIndex].(float64)),
-			OneYearChangePct: float32(data[oneYearChangeIndex].(float64)),
-		}
-		sectors = append(sectors, sector)
+	sectors := make([]domain.Sector, 0, len(page.Sectors))
+	for _, sector := range page.Sectors {
+		sectors = append(sectors, domain.Sector(sector))
 	}
 	return sectors, nil
 }
@@ -3,11 +3,23 @@ package marketDataScraper
 import (
 	"encoding/json"
 	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/drift"
 	"net/http"
 )
 
-func scrapeStockList() ([]domain.Ticker, error) {
-	url := "https://stockanalysis.com/api/screener/s/f?m=s&s=asc&c=s,n&i=stocks"
+// TickersSchema declares the fields expected in the response of scrapeStockList
+var TickersSchema = drift.Schema{
+	Name:       "tickers",
+	MinRecords: 1,
+	Fields: []drift.Field{
+		{Path: "Symbol", Required: true},
+		{Path: "CompanyName", Required: true},
+		{Path: "MarketCap"},
+	},
+}
+
+func scrapeStockList(stockAnalysisBaseURL string) ([]domain.Ticker, error) {
+	url := stockAnalysisBaseURL + "/api/screener/s/f?m=s&s=asc&c=s,n,marketCap&i=stocks"
 
 	resp, err := http.Get(url)
 	if err != nil {
@@ -20,8 +32,9 @@ func scrapeStockList() ([]domain.Ticker, error) {
 		Status int `json:"status"`
 		Data   struct {
 			Data []struct {
-				S string `json:"s"`
-				N string `json:"n"`
+				S         string  `json:"s"`
+				N         string  `json:"n"`
+				MarketCap float64 `json:"marketCap"`
 			} `json:"data"`
 			ResultsCount int `json:"resultsCount"`
 		} `json:"data"`
@@ -37,6 +50,7 @@ func scrapeStockList() ([]domain.Ticker, error) {
 		ticker := domain.Ticker{
 			Symbol:      tickerData.S,
 			CompanyName: tickerData.N,
+			MarketCap:   tickerData.MarketCap,
 		}
 		tickers = append(tickers, ticker)
 	}
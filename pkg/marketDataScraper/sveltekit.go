@@ -0,0 +1,33 @@
+package marketDataScraper
+
+import (
+	"fmt"
+	"io"
+	"market_data_mcp_server/pkg/devalue"
+	"net/http"
+)
+
+// fetchSvelteKitData fetches the body of a SvelteKit __data.json page
+func fetchSvelteKitData(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Call to %s failed with status code: %d", url, resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// fetchSvelteKitNode fetches a SvelteKit __data.json page and decodes the data of the given node into v
+func fetchSvelteKitNode(url string, node int, v any) error {
+	body, err := fetchSvelteKitData(url)
+	if err != nil {
+		return err
+	}
+
+	return devalue.UnmarshalNode(body, node, v)
+}
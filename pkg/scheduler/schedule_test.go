@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// A thursday
+	now := time.Date(2025, 6, 12, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "* * * * *", want: time.Date(2025, 6, 12, 10, 8, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", want: time.Date(2025, 6, 12, 10, 15, 0, 0, time.UTC)},
+		{spec: "0 6 * * *", want: time.Date(2025, 6, 13, 6, 0, 0, 0, time.UTC)},
+		{spec: "30 9 * * 1-5", want: time.Date(2025, 6, 13, 9, 30, 0, 0, time.UTC)},
+		{spec: "0 8 * * 6,7", want: time.Date(2025, 6, 14, 8, 0, 0, 0, time.UTC)},
+		{spec: "0 8 * * 0", want: time.Date(2025, 6, 15, 8, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 */3 *", want: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "5,10 12-14/2 * * *", want: time.Date(2025, 6, 12, 12, 5, 0, 0, time.UTC)},
+		{spec: "@daily", want: time.Date(2025, 6, 13, 0, 0, 0, 0, time.UTC)},
+		{spec: "@hourly", want: time.Date(2025, 6, 12, 11, 0, 0, 0, time.UTC)},
+		{spec: "@every 90m", want: time.Date(2025, 6, 12, 11, 37, 30, 0, time.UTC)},
+		// Both days restricted: the 1st of the month or a monday
+		{spec: "0 0 1 * 1", want: time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := schedule.Next(now); !got.Equal(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@every 10ms", "@every soon"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a named function run on a schedule
+type Job struct {
+	Name       string
+	Schedule   Schedule
+	Run        func(ctx context.Context)
+	RunAtStart bool // Run the job when the scheduler starts, before its first scheduled run
+}
+
+// Scheduler runs jobs on their schedules, a job never runs twice at the same time: a run that is due
+// while the previous one is still running is skipped
+type Scheduler struct {
+	logger *log.Logger
+
+	mu   sync.Mutex
+	jobs []Job
+}
+
+func NewScheduler(logger *log.Logger) *Scheduler {
+	return &Scheduler{logger: logger}
+}
+
+// Add adds a job, it runs once the scheduler is started
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" {
+		return fmt.Errorf("job name is required")
+	}
+	if job.Schedule == nil || job.Run == nil {
+		return fmt.Errorf("job %s must have a schedule and a function", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+// Start runs every job on its schedule until the context is done
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	if job.RunAtStart {
+		s.run(ctx, job)
+	}
+
+	for {
+		next := job.Schedule.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Printf("Job %s has no next run, it's stopped", job.Name)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// The next run is computed after this one, the runs that were due meanwhile are skipped
+		s.run(ctx, job)
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	start := time.Now()
+	job.Run(ctx)
+	s.logger.Printf("Job %s completed in %v", job.Name, time.Since(start).Round(time.Millisecond))
+}
@@ -0,0 +1,321 @@
+package search
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MatchReason describes why a document matched a query
+type MatchReason string
+
+const (
+	MatchReasonExactSymbol     MatchReason = "exact_symbol"
+	MatchReasonSymbolPrefix    MatchReason = "symbol_prefix"
+	MatchReasonNameTokenPrefix MatchReason = "name_token_prefix"
+	MatchReasonFuzzy           MatchReason = "fuzzy"
+	MatchReasonContains        MatchReason = "contains"
+)
+
+// Base score of each match reason, a document always scores inside the band of its reason
+// so that e.g. a symbol prefix match is always ranked above a name token prefix match.
+const (
+	exactSymbolScore     = 1000
+	symbolPrefixScore    = 800
+	nameTokenPrefixScore = 600
+	fuzzyScore           = 400
+	containsScore        = 200
+)
+
+// Document is the searchable representation of an item of a universe (a stock, an ETF, a cryptocurrency)
+type Document struct {
+	Symbol string
+	Name   string
+	// Aliases are matched like the symbol (e.g. the CoinGecko id of a cryptocurrency)
+	Aliases []string
+	// Weight breaks the ties between documents with the same score (market cap, AUM)
+	Weight float64
+}
+
+type Result[T any] struct {
+	Item   T
+	Score  float64
+	Reason MatchReason
+}
+
+type indexedDocument[T any] struct {
+	item       T
+	symbols    []string
+	name       string
+	nameTokens []string
+	weight     float64
+	sortKey    string
+}
+
+// Index is an in-memory index of a universe of items
+type Index[T any] struct {
+	documents []indexedDocument[T]
+}
+
+func NewIndex[T any](items []T, toDocument func(T) Document) *Index[T] {
+	documents := make([]indexedDocument[T], 0, len(items))
+	for _, item := range items {
+		doc := toDocument(item)
+
+		symbols := make([]string, 0, 1+len(doc.Aliases))
+		for _, symbol := range append([]string{doc.Symbol}, doc.Aliases...) {
+			if normalized := strings.ToLower(strings.TrimSpace(symbol)); normalized != "" {
+				symbols = append(symbols, normalized)
+			}
+		}
+
+		name := strings.ToLower(doc.Name)
+		documents = append(documents, indexedDocument[T]{
+			item:       item,
+			symbols:    symbols,
+			name:       name,
+			nameTokens: tokenize(name),
+			weight:     doc.Weight,
+			sortKey:    strings.ToLower(doc.Symbol) + "\x00" + name,
+		})
+	}
+
+	// Keep the documents sorted so that the results of an empty query and the ties are deterministic
+	slices.SortStableFunc(documents, func(a, b indexedDocument[T]) int {
+		return cmp.Compare(a.sortKey, b.sortKey)
+	})
+
+	return &Index[T]{documents: documents}
+}
+
+// Len returns the number of documents in the index
+func (idx *Index[T]) Len() int {
+	return len(idx.documents)
+}
+
+// Search returns the documents that match the query ranked by score and weight.
+// An empty query returns all the documents ordered by symbol with a zero score.
+func (idx *Index[T]) Search(query string) []Result[T] {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		results := make([]Result[T], 0, len(idx.documents))
+		for _, doc := range idx.documents {
+			results = append(results, Result[T]{Item: doc.item})
+		}
+		return results
+	}
+
+	queryTokens := tokenize(query)
+
+	type scoredDocument struct {
+		doc    *indexedDocument[T]
+		score  float64
+		reason MatchReason
+	}
+	matches := make([]scoredDocument, 0)
+	for i := range idx.documents {
+		doc := &idx.documents[i]
+		if score, reason, ok := scoreDocument(doc.symbols, doc.name, doc.nameTokens, query, queryTokens); ok {
+			matches = append(matches, scoredDocument{doc: doc, score: score, reason: reason})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b scoredDocument) int {
+		return cmp.Or(
+			cmp.Compare(b.score, a.score),
+			cmp.Compare(b.doc.weight, a.doc.weight),
+			cmp.Compare(a.doc.sortKey, b.doc.sortKey),
+		)
+	})
+
+	results := make([]Result[T], 0, len(matches))
+	for _, match := range matches {
+		results = append(results, Result[T]{Item: match.doc.item, Score: match.score, Reason: match.reason})
+	}
+	return results
+}
+
+func scoreDocument(symbols []string, name string, nameTokens []string, query string, queryTokens []string) (float64, MatchReason, bool) {
+	for _, symbol := range symbols {
+		if symbol == query {
+			return exactSymbolScore, MatchReasonExactSymbol, true
+		}
+	}
+
+	bestSymbolPrefix := -1
+	for _, symbol := range symbols {
+		if strings.HasPrefix(symbol, query) && (bestSymbolPrefix == -1 || len(symbol) < bestSymbolPrefix) {
+			bestSymbolPrefix = len(symbol)
+		}
+	}
+	if bestSymbolPrefix != -1 {
+		// Shorter symbols are closer to the query
+		return symbolPrefixScore + 100/float64(1+bestSymbolPrefix-len(query)), MatchReasonSymbolPrefix, true
+	}
+
+	if score, ok := tokenPrefixScore(nameTokens, queryTokens); ok {
+		return nameTokenPrefixScore + score, MatchReasonNameTokenPrefix, true
+	}
+
+	if distance, ok := fuzzyDistance(symbols, nameTokens, queryTokens); ok {
+		return fuzzyScore + 100/float64(1+distance), MatchReasonFuzzy, true
+	}
+
+	if strings.Contains(name, query) {
+		return containsScore, MatchReasonContains, true
+	}
+	for _, symbol := range symbols {
+		if strings.Contains(symbol, query) {
+			return containsScore, MatchReasonContains, true
+		}
+	}
+
+	return 0, "", false
+}
+
+// tokenPrefixScore checks that every query token is a prefix of a different name token.
+// Matches that start at the first name token, full token matches and names that are fully
+// covered by the query score higher.
+func tokenPrefixScore(nameTokens []string, queryTokens []string) (float64, bool) {
+	if len(queryTokens) == 0 {
+		return 0, false
+	}
+
+	used := make([]bool, len(nameTokens))
+	score := 0.0
+	for i, queryToken := range queryTokens {
+		matched := false
+		for j, nameToken := range nameTokens {
+			if used[j] || !strings.HasPrefix(nameToken, queryToken) {
+				continue
+			}
+			used[j] = true
+			matched = true
+			if i == 0 && j == 0 {
+				score += 50
+			}
+			if nameToken == queryToken {
+				score += 20
+			}
+			break
+		}
+		if !matched {
+			return 0, false
+		}
+	}
+
+	// Prefer the names that are mostly covered by the query ("meta" -> "Meta Platforms" over "Meta Materials Holdings Corp")
+	score += 30 * float64(len(queryTokens)) / float64(len(nameTokens))
+	return min(score, 199), true
+}
+
+// fuzzyDistance returns the total edit distance of the query tokens from the closest symbol or name token.
+// Short tokens aren't matched fuzzily since almost everything is one edit away from them.
+func fuzzyDistance(symbols []string, nameTokens []string, queryTokens []string) (int, bool) {
+	if len(queryTokens) == 0 {
+		return 0, false
+	}
+
+	total := 0
+	for _, queryToken := range queryTokens {
+		maxDistance := maxEditDistance(queryToken)
+		best := maxDistance + 1
+		queryLength := utf8.RuneCountInString(queryToken)
+		for _, candidate := range slices.Concat(symbols, nameTokens) {
+			best = min(best, editDistance(queryToken, candidate))
+			// Allow typos in a prefix of a longer token ("amazn" -> "amazon"), cut on runes so that
+			// "munchen" is compared with "münchen" and not with the bytes of "münche"
+			if runes := []rune(candidate); len(runes) > queryLength {
+				best = min(best, editDistance(queryToken, string(runes[:queryLength])))
+			}
+		}
+		if best > maxDistance {
+			return 0, false
+		}
+		total += best
+	}
+
+	return total, true
+}
+
+func maxEditDistance(token string) int {
+	switch n := len([]rune(token)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is the optimal string alignment distance (Levenshtein distance with transpositions)
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prevPrev := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prevPrev[j-2]+1)
+			}
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+
+	return prev[len(rb)]
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// CachedIndex builds the index of a universe on demand and rebuilds it at most once per ttl
+type CachedIndex[T any] struct {
+	mu         sync.Mutex
+	source     func() ([]T, error)
+	toDocument func(T) Document
+	ttl        time.Duration
+	index      *Index[T]
+	builtAt    time.Time
+	now        func() time.Time
+}
+
+func NewCachedIndex[T any](source func() ([]T, error), toDocument func(T) Document, ttl time.Duration) *CachedIndex[T] {
+	return &CachedIndex[T]{source: source, toDocument: toDocument, ttl: ttl, now: time.Now}
+}
+
+func (c *CachedIndex[T]) Get() (*Index[T], error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.index != nil && c.now().Sub(c.builtAt) < c.ttl {
+		return c.index, nil
+	}
+
+	items, err := c.source()
+	if err != nil {
+		return nil, err
+	}
+
+	c.index = NewIndex(items, c.toDocument)
+	c.builtAt = c.now()
+	return c.index, nil
+}
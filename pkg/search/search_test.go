@@ -0,0 +1,239 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	symbol string
+	name   string
+	weight float64
+}
+
+func testDocument(item testItem) Document {
+	return Document{Symbol: item.symbol, Name: item.name, Weight: item.weight}
+}
+
+func TestSearchRanking(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []testItem
+		query    string
+		expected []string
+		reasons  []MatchReason
+	}{
+		{
+			name: "tiers",
+			items: []testItem{
+				{symbol: "CASA", name: "Casanova Holdings", weight: 500},
+				{symbol: "XYZ", name: "Nuva Ring", weight: 400},
+				{symbol: "NVS", name: "Novartis AG", weight: 300},
+				{symbol: "NOVAB", name: "Something Else", weight: 200},
+				{symbol: "NOVA", name: "Sunnova Energy", weight: 100},
+				{symbol: "AAPL", name: "Apple Inc.", weight: 1000},
+			},
+			query:    "nova",
+			expected: []string{"NOVA", "NOVAB", "NVS", "XYZ", "CASA"},
+			reasons:  []MatchReason{MatchReasonExactSymbol, MatchReasonSymbolPrefix, MatchReasonNameTokenPrefix, MatchReasonFuzzy, MatchReasonContains},
+		},
+		{
+			name: "weight breaks the ties",
+			items: []testItem{
+				{symbol: "ACM1", name: "Acme Corp", weight: 1},
+				{symbol: "ACM2", name: "Acme Corp", weight: 100},
+			},
+			query:    "acme",
+			expected: []string{"ACM2", "ACM1"},
+			reasons:  []MatchReason{MatchReasonNameTokenPrefix, MatchReasonNameTokenPrefix},
+		},
+		{
+			name: "apple",
+			items: []testItem{
+				{symbol: "MLP", name: "Maui Land & Pineapple Company", weight: 4e8},
+				{symbol: "PEGY", name: "Pineapple Energy", weight: 1e7},
+				{symbol: "APLE", name: "Apple Hospitality REIT", weight: 3e9},
+				{symbol: "AAPL", name: "Apple Inc.", weight: 3e12},
+			},
+			query:    "Apple",
+			expected: []string{"AAPL", "APLE", "MLP", "PEGY"},
+			reasons:  []MatchReason{MatchReasonNameTokenPrefix, MatchReasonNameTokenPrefix, MatchReasonContains, MatchReasonContains},
+		},
+		{
+			name: "meta",
+			items: []testItem{
+				{symbol: "MTUS", name: "Metallus Inc.", weight: 1e9},
+				{symbol: "MMAT", name: "Meta Materials Holdings Corp", weight: 1e8},
+				{symbol: "META", name: "Meta Platforms, Inc.", weight: 1.5e12},
+				{symbol: "MT", name: "ArcelorMittal", weight: 2e10},
+			},
+			query:    "meta",
+			expected: []string{"META", "MMAT", "MTUS"},
+			reasons:  []MatchReason{MatchReasonExactSymbol, MatchReasonNameTokenPrefix, MatchReasonNameTokenPrefix},
+		},
+		{
+			name: "shorter symbol prefix first",
+			items: []testItem{
+				{symbol: "AAPLX", name: "Leveraged Fund", weight: 100},
+				{symbol: "AAPL", name: "Apple Inc.", weight: 1},
+			},
+			query:    "aap",
+			expected: []string{"AAPL", "AAPLX"},
+			reasons:  []MatchReason{MatchReasonSymbolPrefix, MatchReasonSymbolPrefix},
+		},
+		{
+			name: "multibyte",
+			items: []testItem{
+				{symbol: "MUV2", name: "Münchener Rück"},
+				{symbol: "BMW", name: "Bayerische Motoren Werke"},
+			},
+			query:    "munchen",
+			expected: []string{"MUV2"},
+			reasons:  []MatchReason{MatchReasonFuzzy},
+		},
+		{
+			name: "multibyte token prefix",
+			items: []testItem{
+				{symbol: "MUV2", name: "Münchener Rück"},
+				{symbol: "RUK", name: "Ruko Ltd"},
+			},
+			query:    "RÜCK",
+			expected: []string{"MUV2"},
+			reasons:  []MatchReason{MatchReasonNameTokenPrefix},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := NewIndex(tt.items, testDocument).Search(tt.query)
+			symbols := make([]string, 0, len(results))
+			reasons := make([]MatchReason, 0, len(results))
+			for _, result := range results {
+				symbols = append(symbols, result.Item.symbol)
+				reasons = append(reasons, result.Reason)
+			}
+			if !reflect.DeepEqual(symbols, tt.expected) || !reflect.DeepEqual(reasons, tt.reasons) {
+				t.Errorf("expected %v %v, got %v %v", tt.expected, tt.reasons, symbols, reasons)
+			}
+			for i := 1; i < len(results); i++ {
+				if results[i].Score > results[i-1].Score {
+					t.Errorf("expected decreasing scores, got %v after %v", results[i].Score, results[i-1].Score)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchScoreBands(t *testing.T) {
+	index := NewIndex([]testItem{
+		{symbol: "NOVA", name: "Sunnova Energy"},
+		{symbol: "NOVABC", name: "Something Else"},
+		{symbol: "NVS", name: "Novartis AG"},
+		{symbol: "XYZ", name: "Nuva Ring"},
+		{symbol: "CASA", name: "Casanova Holdings"},
+	}, testDocument)
+
+	bands := map[MatchReason][2]float64{
+		MatchReasonExactSymbol:     {1000, 1000},
+		MatchReasonSymbolPrefix:    {800, 900},
+		MatchReasonNameTokenPrefix: {600, 800},
+		MatchReasonFuzzy:           {400, 600},
+		MatchReasonContains:        {200, 200},
+	}
+	for _, result := range index.Search("nova") {
+		band := bands[result.Reason]
+		if result.Score < band[0] || result.Score > band[1] {
+			t.Errorf("expected the score of %s (%s) in %v, got %v", result.Item.symbol, result.Reason, band, result.Score)
+		}
+	}
+}
+
+func TestSearchEmptyQuery(t *testing.T) {
+	index := NewIndex([]testItem{{symbol: "MSFT"}, {symbol: "AAPL"}, {symbol: "GOOG"}}, testDocument)
+
+	results := index.Search("  ")
+	if len(results) != 3 || results[0].Item.symbol != "AAPL" || results[2].Item.symbol != "MSFT" || results[0].Score != 0 {
+		t.Errorf("expected all the documents ordered by symbol, got %+v", results)
+	}
+}
+
+func TestMaxEditDistance(t *testing.T) {
+	tests := []struct {
+		token    string
+		expected int
+	}{
+		{token: "ibm", expected: 0},
+		{token: "tsla", expected: 1},
+		{token: "amazon", expected: 1},
+		{token: "münchen", expected: 1},
+		{token: "nintendo", expected: 2},
+		{token: "microsoft", expected: 2},
+	}
+
+	for _, tt := range tests {
+		if distance := maxEditDistance(tt.token); distance != tt.expected {
+			t.Errorf("expected a max edit distance of %d for %q, got %d", tt.expected, tt.token, distance)
+		}
+	}
+}
+
+func TestSearchFuzzy(t *testing.T) {
+	index := NewIndex([]testItem{
+		{symbol: "IBM", name: "International Business Machines"},
+		{symbol: "AMZN", name: "Amazon.com, Inc."},
+		{symbol: "MSFT", name: "Microsoft Corporation"},
+	}, testDocument)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		// Tokens under 4 letters must match exactly
+		{query: "ibx", expected: []string{}},
+		// One typo from 4 letters
+		{query: "amazn", expected: []string{"AMZN"}},
+		{query: "amaozn", expected: []string{"AMZN"}},
+		{query: "amzaonn", expected: []string{}},
+		// Two typos from 8 letters
+		{query: "micorsfot", expected: []string{"MSFT"}},
+		{query: "mcirsfto", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		results := index.Search(tt.query)
+		symbols := make([]string, 0, len(results))
+		for _, result := range results {
+			symbols = append(symbols, result.Item.symbol)
+			if result.Reason != MatchReasonFuzzy {
+				t.Errorf("expected a fuzzy match for %q, got %s", tt.query, result.Reason)
+			}
+		}
+		if !reflect.DeepEqual(symbols, tt.expected) {
+			t.Errorf("expected %v for %q, got %v", tt.expected, tt.query, symbols)
+		}
+	}
+}
+
+func TestCachedIndex(t *testing.T) {
+	builds := 0
+	items := []testItem{{symbol: "AAPL"}}
+	cached := NewCachedIndex(func() ([]testItem, error) {
+		builds++
+		return items, nil
+	}, testDocument, 10*time.Minute)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cached.now = func() time.Time { return now }
+
+	index, _ := cached.Get()
+	items = append(items, testItem{symbol: "MSFT"})
+	now = now.Add(9 * time.Minute)
+	if index, _ = cached.Get(); builds != 1 || index.Len() != 1 {
+		t.Errorf("expected the index to be built once before the ttl, got %d builds and %d documents", builds, index.Len())
+	}
+
+	now = now.Add(time.Minute)
+	if index, _ = cached.Get(); builds != 2 || index.Len() != 2 {
+		t.Errorf("expected the index to be rebuilt after the ttl, got %d builds and %d documents", builds, index.Len())
+	}
+}
@@ -0,0 +1,495 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"market_data_mcp_server/pkg/domain"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// maxSeenAlertItems bounds the keys of the insider transactions and the news articles remembered per alert
+const maxSeenAlertItems = 500
+
+type AlertStore interface {
+	GetAlerts(userID string) ([]domain.Alert, error)
+	GetAllAlerts() ([]domain.Alert, error)
+	UpdateAlertState(userID string, alertID int, state domain.AlertState) error
+	AddAlertEvent(event domain.AlertEvent) (domain.AlertEvent, error)
+	UpdateAlertEvent(event domain.AlertEvent) error
+}
+
+type AlertDataService interface {
+	GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error)
+	GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error)
+	GetStockNews(symbol string) ([]domain.NewsArticle, error)
+}
+
+type InsiderTransactionsSource interface {
+	GetInsiderTransactions(symbol string) ([]domain.InsiderTransaction, error)
+}
+
+// AlertNotifier delivers the events of the triggered alerts
+type AlertNotifier interface {
+	NotifyAlert(ctx context.Context, alert domain.Alert, event domain.AlertEvent) error
+}
+
+// alertRatios are the ratios of the ratio alerts, the yields and returns in percent like in the watchlists
+var alertRatios = map[string]func(r domain.FinancialRatios) float64{
+	"market_cap":     func(r domain.FinancialRatios) float64 { return r.Marketcap },
+	"pe":             func(r domain.FinancialRatios) float64 { return r.Pe },
+	"ps":             func(r domain.FinancialRatios) float64 { return r.Ps },
+	"pb":             func(r domain.FinancialRatios) float64 { return r.Pb },
+	"pfcf":           func(r domain.FinancialRatios) float64 { return r.Pfcf },
+	"ev_ebitda":      func(r domain.FinancialRatios) float64 { return r.EvEbitda },
+	"ev_revenue":     func(r domain.FinancialRatios) float64 { return r.EvRevenue },
+	"debt_equity":    func(r domain.FinancialRatios) float64 { return r.DebtEquity },
+	"current_ratio":  func(r domain.FinancialRatios) float64 { return r.CurrentRatio },
+	"roe":            func(r domain.FinancialRatios) float64 { return r.Roe * 100 },
+	"roic":           func(r domain.FinancialRatios) float64 { return r.Roic * 100 },
+	"dividend_yield": func(r domain.FinancialRatios) float64 { return r.DividendYield * 100 },
+	"fcf_yield":      func(r domain.FinancialRatios) float64 { return r.FcfYield * 100 },
+}
+
+func alertRatioNames() []string {
+	return slices.Sorted(maps.Keys(alertRatios))
+}
+
+// alertItem is an insider transaction or a news article of an alert
+type alertItem struct {
+	key         string
+	description string
+}
+
+// AlertEngine evaluates the alerts with the cached clients and notifies their events. A threshold alert triggers
+// when its condition becomes true and again only once it was false in between, an insider buy or a news mention
+// alert when items it hasn't seen appear. No alert triggers twice within its cooldown: the events are delayed to
+// the first evaluation after it.
+type AlertEngine struct {
+	store    AlertStore
+	data     AlertDataService
+	insiders InsiderTransactionsSource
+	crypto   CryptoPricesService
+	logger   *log.Logger
+
+	mu        sync.Mutex
+	notifiers []AlertNotifier
+
+	// The evaluations don't overlap, so that a scheduled one and a checkAlerts call don't trigger the same event
+	evaluating sync.Mutex
+}
+
+func NewAlertEngine(store AlertStore, data AlertDataService, insiders InsiderTransactionsSource, crypto CryptoPricesService, logger *log.Logger) (*AlertEngine, error) {
+	return &AlertEngine{
+		store:    store,
+		data:     data,
+		insiders: insiders,
+		crypto:   crypto,
+		logger:   logger,
+	}, nil
+}
+
+// AddNotifier adds a notifier of the events
+func (e *AlertEngine) AddNotifier(notifier AlertNotifier) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.notifiers = append(e.notifiers, notifier)
+}
+
+// EvaluateAll evaluates the alerts of all the users, it's run by the scheduler
+func (e *AlertEngine) EvaluateAll(ctx context.Context) {
+	alerts, err := e.store.GetAllAlerts()
+	if err != nil {
+		e.logger.Printf("Failed to get the alerts: %v", err)
+		return
+	}
+	events := e.evaluate(ctx, alerts)
+	e.logger.Printf("Evaluated %d alerts, %d triggered", len(alerts), len(events))
+}
+
+// EvaluateUserAlerts evaluates the alerts of the user right away and returns the events triggered
+func (e *AlertEngine) EvaluateUserAlerts(ctx context.Context, userID string) ([]domain.AlertEvent, error) {
+	alerts, err := e.store.GetAlerts(userID)
+	if err != nil {
+		return nil, err
+	}
+	return e.evaluate(ctx, alerts), nil
+}
+
+func (e *AlertEngine) evaluate(ctx context.Context, alerts []domain.Alert) []domain.AlertEvent {
+	e.evaluating.Lock()
+	defer e.evaluating.Unlock()
+
+	results := make([]*domain.AlertEvent, len(alerts))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrentPriceRequests)
+	for i, alert := range alerts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if ctx.Err() != nil {
+				return
+			}
+			results[i] = e.evaluateAlert(ctx, alert, time.Now().UTC())
+		}()
+	}
+	wg.Wait()
+
+	var events []domain.AlertEvent
+	for _, event := range results {
+		if event != nil {
+			events = append(events, *event)
+		}
+	}
+	return events
+}
+
+// evaluateAlert evaluates the alert, records its new state and returns its event when it triggers
+func (e *AlertEngine) evaluateAlert(ctx context.Context, alert domain.Alert, now time.Time) *domain.AlertEvent {
+	state := alert.State
+	state.Seen = slices.Clone(state.Seen)
+	state.LastEvaluatedAt = now.Format(time.RFC3339)
+
+	coolingDown := false
+	if last, err := time.Parse(time.RFC3339, state.LastTriggeredAt); err == nil {
+		coolingDown = now.Before(last.Add(time.Duration(alert.CooldownMinutes) * time.Minute))
+	}
+
+	var message string
+	var value float64
+	var err error
+	switch alert.Type {
+	case domain.AlertInsiderBuy, domain.AlertNewsMention:
+		message, value, err = e.evaluateItems(alert, &state, coolingDown)
+	default:
+		message, value, err = e.evaluateThreshold(alert, &state, coolingDown)
+	}
+
+	var event *domain.AlertEvent
+	if err != nil {
+		state.LastError = err.Error()
+	} else {
+		state.LastError = ""
+		if message != "" {
+			state.LastTriggeredAt = now.Format(time.RFC3339)
+			event = e.trigger(ctx, alert, message, value, now)
+		}
+	}
+
+	if err := e.store.UpdateAlertState(alert.UserID, alert.ID, state); err != nil {
+		e.logger.Printf("Failed to update the state of the alert %d of %s: %v", alert.ID, alert.UserID, err)
+	}
+	return event
+}
+
+// evaluateThreshold returns the message of the event when the condition of the alert becomes true
+func (e *AlertEngine) evaluateThreshold(alert domain.Alert, state *domain.AlertState, coolingDown bool) (string, float64, error) {
+	value, description, err := e.observe(alert)
+	if err != nil {
+		return "", 0, err
+	}
+	state.LastValue = value
+
+	var holds bool
+	var condition string
+	switch alert.Type {
+	case domain.AlertPriceAbove, domain.AlertRatioAbove:
+		holds, condition = value >= alert.Threshold, fmt.Sprintf("at or above %v", alert.Threshold)
+	case domain.AlertPriceBelow, domain.AlertRatioBelow:
+		holds, condition = value <= alert.Threshold, fmt.Sprintf("at or below %v", alert.Threshold)
+	case domain.AlertPercentMove:
+		if alert.Threshold > 0 {
+			holds, condition = value >= alert.Threshold, fmt.Sprintf("a rise of %v%% or more", alert.Threshold)
+		} else {
+			holds, condition = value <= alert.Threshold, fmt.Sprintf("a drop of %v%% or more", -alert.Threshold)
+		}
+	}
+
+	if !holds {
+		state.Active = false
+		return "", value, nil
+	}
+	// Triggered already, or delayed until the end of the cooldown
+	if state.Active || coolingDown {
+		return "", value, nil
+	}
+	state.Active = true
+	return fmt.Sprintf("%s: %s, %s", alert.Symbol, description, condition), value, nil
+}
+
+// observe returns the current value watched by a threshold alert and its description
+func (e *AlertEngine) observe(alert domain.Alert) (float64, string, error) {
+	switch alert.Type {
+	case domain.AlertRatioAbove, domain.AlertRatioBelow:
+		ratios, err := e.data.GetFinancialRatios(strings.ToLower(alert.Symbol))
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to get the financial ratios: %w", err)
+		}
+		if len(ratios) == 0 {
+			return 0, "", fmt.Errorf("no financial ratios found for %s", alert.Symbol)
+		}
+		// The first ratios are the trailing twelve months ones
+		value := alertRatios[alert.Ratio](ratios[0])
+		return value, fmt.Sprintf("%s is %.2f", alert.Ratio, value), nil
+
+	case domain.AlertPercentMove:
+		if alert.AssetClass == domain.Crypto {
+			data, err := cryptocurrencyData(e.crypto, alert.Symbol)
+			if err != nil {
+				return 0, "", err
+			}
+			changes := map[domain.Period]float64{
+				domain.Period1D: data.PriceChangePercentage24h,
+				domain.Period1M: data.PriceChangePercentage30d,
+				domain.Period1Y: data.PriceChangePercentage1y,
+			}
+			return changes[alert.Period], fmt.Sprintf("moved %.2f%% over %s", changes[alert.Period], alert.Period), nil
+		}
+		prices, err := e.data.GetHistoricalPrices(strings.ToLower(alert.Symbol), alert.AssetClass, alert.Period)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to get the %s prices: %w", alert.Period, err)
+		}
+		return prices.PercentageChange, fmt.Sprintf("moved %.2f%% over %s", prices.PercentageChange, alert.Period), nil
+
+	default:
+		if alert.AssetClass == domain.Crypto {
+			data, err := cryptocurrencyData(e.crypto, alert.Symbol)
+			if err != nil {
+				return 0, "", err
+			}
+			return data.CurrentUsdPrice, fmt.Sprintf("price is %v USD", data.CurrentUsdPrice), nil
+		}
+		prices, err := e.data.GetHistoricalPrices(strings.ToLower(alert.Symbol), alert.AssetClass, domain.Period1D)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to get the price: %w", err)
+		}
+		if len(prices.Prices) == 0 {
+			return 0, "", fmt.Errorf("no price found for %s", alert.Symbol)
+		}
+		price := prices.Prices[len(prices.Prices)-1].ClosePrice
+		return price, fmt.Sprintf("price is %v USD", price), nil
+	}
+}
+
+// evaluateItems returns the message of the event when new items appear. The items found by the first evaluation
+// are only recorded, the alert is about the ones that come after its creation.
+func (e *AlertEngine) evaluateItems(alert domain.Alert, state *domain.AlertState, coolingDown bool) (string, float64, error) {
+	items, err := e.items(alert)
+	if err != nil {
+		return "", 0, err
+	}
+
+	seen := make(map[string]bool, len(state.Seen))
+	for _, key := range state.Seen {
+		seen[key] = true
+	}
+	var fresh []alertItem
+	for _, item := range items {
+		if !seen[item.key] {
+			seen[item.key] = true
+			fresh = append(fresh, item)
+		}
+	}
+	state.LastValue = float64(len(fresh))
+
+	if !state.Primed {
+		state.Primed = true
+		state.Seen = lastSeen(state.Seen, fresh)
+		return "", 0, nil
+	}
+	// The new items are kept unseen until the end of the cooldown
+	if len(fresh) == 0 || coolingDown {
+		return "", float64(len(fresh)), nil
+	}
+	state.Seen = lastSeen(state.Seen, fresh)
+
+	descriptions := make([]string, 0, len(fresh))
+	for _, item := range fresh {
+		descriptions = append(descriptions, item.description)
+	}
+	what := "insider buys"
+	if alert.Type == domain.AlertNewsMention {
+		what = "news articles"
+		if alert.Keyword != "" {
+			what = fmt.Sprintf("news articles mentioning %q", alert.Keyword)
+		}
+	}
+	return fmt.Sprintf("%s: %d new %s: %s", alert.Symbol, len(fresh), what, strings.Join(descriptions, "; ")), float64(len(fresh)), nil
+}
+
+// items returns the insider buys or the news articles of the symbol of the alert
+func (e *AlertEngine) items(alert domain.Alert) ([]alertItem, error) {
+	var items []alertItem
+	if alert.Type == domain.AlertInsiderBuy {
+		transactions, err := e.insiders.GetInsiderTransactions(alert.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the insider transactions: %w", err)
+		}
+		for _, t := range transactions {
+			// The acquisitions without a price are grants and option awards, not buys
+			if t.AcquisitionOrDisposal != "A" || t.SharePrice <= 0 {
+				continue
+			}
+			items = append(items, alertItem{
+				key:         fmt.Sprintf("%s|%s|%v|%v", t.TransactionDate, t.Executive, t.Shares, t.SharePrice),
+				description: fmt.Sprintf("%s (%s) bought %v shares at %v on %s", t.Executive, t.ExecutiveTitle, t.Shares, t.SharePrice, t.TransactionDate),
+			})
+		}
+		return items, nil
+	}
+
+	articles, err := e.data.GetStockNews(strings.ToLower(alert.Symbol))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the news: %w", err)
+	}
+	keyword := strings.ToLower(alert.Keyword)
+	for _, article := range articles {
+		if keyword != "" && !strings.Contains(strings.ToLower(article.Title+" "+article.Text), keyword) {
+			continue
+		}
+		items = append(items, alertItem{key: article.Url, description: article.Title})
+	}
+	return items, nil
+}
+
+// trigger notifies the event of the alert and stores it
+func (e *AlertEngine) trigger(ctx context.Context, alert domain.Alert, message string, value float64, now time.Time) *domain.AlertEvent {
+	event := domain.AlertEvent{
+		AlertID:     alert.ID,
+		UserID:      alert.UserID,
+		Type:        alert.Type,
+		Symbol:      alert.Symbol,
+		Message:     message,
+		Value:       value,
+		TriggeredAt: now.Format(time.RFC3339),
+	}
+
+	// The id is assigned first, so that the notifications carry it for the receivers to drop the duplicates
+	event, err := e.store.AddAlertEvent(event)
+	if err != nil {
+		e.logger.Printf("Failed to store the event of the alert %d of %s: %v", alert.ID, alert.UserID, err)
+	}
+
+	e.mu.Lock()
+	notifiers := slices.Clone(e.notifiers)
+	e.mu.Unlock()
+	for _, notifier := range notifiers {
+		if err := notifier.NotifyAlert(ctx, alert, event); err != nil {
+			event.NotificationErrors = append(event.NotificationErrors, err.Error())
+			e.logger.Printf("Failed to notify the event %d of %s: %v", event.ID, alert.UserID, err)
+		}
+	}
+	// The failed notifications are only known now, the stored event is updated for getAlertEvents
+	if len(event.NotificationErrors) > 0 && event.ID != 0 {
+		if err := e.store.UpdateAlertEvent(event); err != nil {
+			e.logger.Printf("Failed to store the notification errors of the event %d of %s: %v", event.ID, alert.UserID, err)
+		}
+	}
+	return &event
+}
+
+// lastSeen appends the keys of the items, keeping the last maxSeenAlertItems
+func lastSeen(seen []string, items []alertItem) []string {
+	for _, item := range items {
+		seen = append(seen, item.key)
+	}
+	if len(seen) > maxSeenAlertItems {
+		seen = seen[len(seen)-maxSeenAlertItems:]
+	}
+	return seen
+}
+
+// WebhookNotifier POSTs the events in JSON to the webhook of their alert, the Alert-Event-Id header lets the
+// receivers drop the duplicates. The webhooks come from the users, so only the public addresses are dialed,
+// and the addresses of the allowed networks of the operator.
+type WebhookNotifier struct {
+	client          *http.Client
+	allowedNetworks []netip.Prefix
+}
+
+func NewWebhookNotifier(timeout time.Duration, allowedNetworks []string) (*WebhookNotifier, error) {
+	n := &WebhookNotifier{}
+	for _, network := range allowedNetworks {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed network %q: %w", network, err)
+		}
+		n.allowedNetworks = append(n.allowedNetworks, prefix.Masked())
+	}
+
+	// The check is on the dialed address, after the resolution and the redirects, and without the proxies
+	// of the environment that would dial in our place
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: n.checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	n.client = &http.Client{Timeout: timeout, Transport: transport}
+	return n, nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, not public either
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// checkAddress rejects the loopback, private, link-local and other non public addresses outside the allowed networks
+func (n *WebhookNotifier) checkAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+
+	for _, allowed := range n.allowedNetworks {
+		if allowed.Contains(ip) {
+			return nil
+		}
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("webhook address %s is not public", ip)
+	}
+	return nil
+}
+
+func (n *WebhookNotifier) NotifyAlert(ctx context.Context, alert domain.Alert, event domain.AlertEvent) error {
+	if alert.WebhookURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, alert.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create the webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Alert-Event-Id", fmt.Sprintf("%s/%d", event.UserID, event.ID))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/errors"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultAlertCooldownMinutes is the cooldown of the alerts created without one
+	defaultAlertCooldownMinutes = 60
+	// maxAlertEvents is the number of events kept per user, the oldest are dropped
+	maxAlertEvents = 100
+)
+
+// AlertLocalDataService stores the alerts of the users, their state and their events in a JSON file, or only in
+// memory when no file is given
+type AlertLocalDataService struct {
+	dataPath string
+
+	mu     sync.Mutex
+	alerts map[string][]domain.Alert      // By user id
+	events map[string][]domain.AlertEvent // By user id, the oldest first
+}
+
+type alertsFile struct {
+	Alerts map[string][]domain.Alert      `json:"alerts"`
+	Events map[string][]domain.AlertEvent `json:"events"`
+}
+
+func NewAlertLocalDataService(dataPath string) (*AlertLocalDataService, error) {
+	s := &AlertLocalDataService{
+		dataPath: dataPath,
+		alerts:   make(map[string][]domain.Alert),
+		events:   make(map[string][]domain.AlertEvent),
+	}
+	if dataPath == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(dataPath)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alerts file: %w", err)
+	}
+	var file alertsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal alerts: %w", err)
+	}
+	if file.Alerts != nil {
+		s.alerts = file.Alerts
+	}
+	if file.Events != nil {
+		s.events = file.Events
+	}
+
+	return s, nil
+}
+
+// GetAlerts returns the alerts of the user in the order they were created
+func (s *AlertLocalDataService) GetAlerts(userID string) ([]domain.Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return cloneAlerts(s.alerts[userID]), nil
+}
+
+// GetAllAlerts returns the alerts of all the users
+func (s *AlertLocalDataService) GetAllAlerts() ([]domain.Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var alerts []domain.Alert
+	for _, userAlerts := range s.alerts {
+		alerts = append(alerts, cloneAlerts(userAlerts)...)
+	}
+	slices.SortFunc(alerts, func(a, b domain.Alert) int {
+		return cmp.Or(strings.Compare(a.UserID, b.UserID), cmp.Compare(a.ID, b.ID))
+	})
+	return alerts, nil
+}
+
+// CreateAlert validates the alert and stores it with its id
+func (s *AlertLocalDataService) CreateAlert(alert domain.Alert) (domain.Alert, error) {
+	if err := validateAlert(&alert); err != nil {
+		return domain.Alert{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.alerts[alert.UserID]
+	alert.ID = 1
+	for _, a := range current {
+		alert.ID = max(alert.ID, a.ID+1)
+	}
+	alert.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	alert.State = domain.AlertState{}
+
+	s.alerts[alert.UserID] = append(slices.Clone(current), alert)
+	if err := s.save(); err != nil {
+		s.alerts[alert.UserID] = current
+		return domain.Alert{}, err
+	}
+	return alert, nil
+}
+
+// DeleteAlert deletes the alert and returns it, its events are kept
+func (s *AlertLocalDataService) DeleteAlert(userID string, alertID int) (domain.Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.alerts[userID]
+	i := slices.IndexFunc(current, func(a domain.Alert) bool { return a.ID == alertID })
+	if i < 0 {
+		return domain.Alert{}, errors.AlertNotFoundError{UserID: userID, AlertID: alertID}
+	}
+
+	s.alerts[userID] = slices.Delete(slices.Clone(current), i, i+1)
+	if err := s.save(); err != nil {
+		s.alerts[userID] = current
+		return domain.Alert{}, err
+	}
+	return current[i], nil
+}
+
+// UpdateAlertState records the state of the alert after an evaluation. An alert deleted meanwhile is ignored.
+func (s *AlertLocalDataService) UpdateAlertState(userID string, alertID int, state domain.AlertState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.alerts[userID], func(a domain.Alert) bool { return a.ID == alertID })
+	if i < 0 {
+		return nil
+	}
+
+	previous := s.alerts[userID][i].State
+	s.alerts[userID][i].State = state
+	if err := s.save(); err != nil {
+		s.alerts[userID][i].State = previous
+		return err
+	}
+	return nil
+}
+
+// AddAlertEvent stores the event with its id, only the last maxAlertEvents events of a user are kept
+func (s *AlertLocalDataService) AddAlertEvent(event domain.AlertEvent) (domain.AlertEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.events[event.UserID]
+	event.ID = 1
+	if len(current) > 0 {
+		event.ID = current[len(current)-1].ID + 1
+	}
+
+	updated := append(slices.Clone(current), event)
+	if len(updated) > maxAlertEvents {
+		updated = updated[len(updated)-maxAlertEvents:]
+	}
+	s.events[event.UserID] = updated
+	if err := s.save(); err != nil {
+		s.events[event.UserID] = current
+		return domain.AlertEvent{}, err
+	}
+	return event, nil
+}
+
+// UpdateAlertEvent replaces the stored event of the same id, e.g. with its notification errors. An event dropped
+// meanwhile is ignored.
+func (s *AlertLocalDataService) UpdateAlertEvent(event domain.AlertEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.events[event.UserID]
+	i := slices.IndexFunc(current, func(e domain.AlertEvent) bool { return e.ID == event.ID })
+	if i < 0 {
+		return nil
+	}
+
+	updated := slices.Clone(current)
+	updated[i] = event
+	s.events[event.UserID] = updated
+	if err := s.save(); err != nil {
+		s.events[event.UserID] = current
+		return err
+	}
+	return nil
+}
+
+// GetAlertEvents returns the last events of the user, the most recent first
+func (s *AlertLocalDataService) GetAlertEvents(userID string, limit int) ([]domain.AlertEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := slices.Clone(s.events[userID])
+	slices.Reverse(events)
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (s *AlertLocalDataService) save() error {
+	if s.dataPath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(alertsFile{Alerts: s.alerts, Events: s.events})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alerts: %w", err)
+	}
+	if err := writeFileAtomically(s.dataPath, data); err != nil {
+		return fmt.Errorf("failed to write alerts file: %w", err)
+	}
+	return nil
+}
+
+func cloneAlerts(alerts []domain.Alert) []domain.Alert {
+	cloned := slices.Clone(alerts)
+	for i := range cloned {
+		cloned[i].State.Seen = slices.Clone(cloned[i].State.Seen)
+	}
+	return cloned
+}
+
+// validateAlert checks the fields required by the type of the alert and normalizes the symbol, the asset class
+// (stock by default), the period of the percent moves (1d by default) and the cooldown
+func validateAlert(a *domain.Alert) error {
+	if a.UserID == "" {
+		return fmt.Errorf("user_id is required")
+	}
+	a.Symbol = strings.ToUpper(strings.TrimSpace(a.Symbol))
+	if a.Symbol == "" {
+		return fmt.Errorf("symbol is required")
+	}
+	a.AssetClass = cmp.Or(a.AssetClass, domain.Stock)
+	if a.CooldownMinutes < 0 {
+		return fmt.Errorf("cooldown_minutes must be a non-negative number")
+	}
+	a.CooldownMinutes = cmp.Or(a.CooldownMinutes, defaultAlertCooldownMinutes)
+	if a.WebhookURL != "" {
+		u, err := url.Parse(a.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook_url must be an http or https URL")
+		}
+	}
+
+	switch a.Type {
+	case domain.AlertPriceAbove, domain.AlertPriceBelow, domain.AlertPercentMove:
+		if a.AssetClass != domain.Stock && a.AssetClass != domain.ETF && a.AssetClass != domain.Crypto {
+			return fmt.Errorf("asset_class valid values for a %s alert are: stock, etf, crypto", a.Type)
+		}
+		if a.Type != domain.AlertPercentMove {
+			if a.Threshold <= 0 {
+				return fmt.Errorf("threshold must be a positive price for a %s alert", a.Type)
+			}
+			break
+		}
+		if a.Threshold == 0 {
+			return fmt.Errorf("threshold is required for a percent_move alert, e.g. 5 for a rise of 5%% or more, -5 for a drop")
+		}
+		a.Period = cmp.Or(a.Period, domain.Period1D)
+		periods := []domain.Period{domain.Period1D, domain.Period5D, domain.Period1M, domain.Period6M, domain.Period1Y}
+		if a.AssetClass == domain.Crypto {
+			periods = []domain.Period{domain.Period1D, domain.Period1M, domain.Period1Y}
+		}
+		if !slices.Contains(periods, a.Period) {
+			return fmt.Errorf("period valid values for a %s percent_move alert are: %v", a.AssetClass, periods)
+		}
+	case domain.AlertRatioAbove, domain.AlertRatioBelow:
+		if a.AssetClass != domain.Stock {
+			return fmt.Errorf("the %s alerts are only available for stocks", a.Type)
+		}
+		a.Ratio = strings.ToLower(strings.TrimSpace(a.Ratio))
+		if _, ok := alertRatios[a.Ratio]; !ok {
+			return fmt.Errorf("ratio valid values are: %s", strings.Join(alertRatioNames(), ", "))
+		}
+	case domain.AlertInsiderBuy, domain.AlertNewsMention:
+		if a.AssetClass != domain.Stock {
+			return fmt.Errorf("the %s alerts are only available for stocks", a.Type)
+		}
+	default:
+		return fmt.Errorf("type valid values are: price_above, price_below, percent_move, ratio_above, ratio_below, insider_buy, news_mention")
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"log"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreateAlert(t *testing.T) {
+	s, _ := NewAlertLocalDataService("")
+
+	alert, err := s.CreateAlert(domain.Alert{UserID: "u", Type: domain.AlertPercentMove, Symbol: " aapl ", Threshold: -5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alert.ID != 1 || alert.Symbol != "AAPL" || alert.AssetClass != domain.Stock || alert.Period != domain.Period1D || alert.CooldownMinutes != defaultAlertCooldownMinutes {
+		t.Errorf("unexpected alert %+v", alert)
+	}
+
+	for _, invalid := range []domain.Alert{
+		{UserID: "u", Type: domain.AlertPriceAbove, Symbol: "AAPL"},
+		{UserID: "u", Type: domain.AlertPercentMove, Symbol: "BTC", AssetClass: domain.Crypto, Threshold: 5, Period: domain.Period5D},
+		{UserID: "u", Type: domain.AlertRatioBelow, Symbol: "AAPL", Ratio: "unknown", Threshold: 20},
+		{UserID: "u", Type: domain.AlertNewsMention, Symbol: "SPY", AssetClass: domain.ETF},
+		{UserID: "u", Type: domain.AlertPriceBelow, Symbol: "AAPL", Threshold: 150, WebhookURL: "ftp://example.com"},
+		{UserID: "u", Type: "unknown", Symbol: "AAPL"},
+	} {
+		if _, err := s.CreateAlert(invalid); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+
+	if _, err := s.DeleteAlert("u", 2); !goerrors.As(err, &errors.AlertNotFoundError{}) {
+		t.Errorf("expected an alert not found error, got %v", err)
+	}
+	if _, err := s.DeleteAlert("u", 1); err != nil {
+		t.Fatal(err)
+	}
+	if alerts, _ := s.GetAlerts("u"); len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %+v", alerts)
+	}
+}
+
+type stubAlertData struct {
+	stubPrices
+	news []domain.NewsArticle
+}
+
+func (s stubAlertData) GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error) {
+	return []domain.FinancialRatios{{Datekey: "TTM", Pe: 30, Roe: 0.25}}, nil
+}
+
+func (s stubAlertData) GetStockNews(symbol string) ([]domain.NewsArticle, error) {
+	return s.news, nil
+}
+
+type stubInsiders []domain.InsiderTransaction
+
+func (s stubInsiders) GetInsiderTransactions(symbol string) ([]domain.InsiderTransaction, error) {
+	return s, nil
+}
+
+type recordingNotifier struct {
+	events []domain.AlertEvent
+	err    error
+}
+
+func (n *recordingNotifier) NotifyAlert(ctx context.Context, alert domain.Alert, event domain.AlertEvent) error {
+	n.events = append(n.events, event)
+	return n.err
+}
+
+func TestAlertEngineThresholds(t *testing.T) {
+	store, _ := NewAlertLocalDataService("")
+	data := stubAlertData{stubPrices: stubPrices{"aapl": 200}}
+	engine, _ := NewAlertEngine(store, data, stubInsiders{}, stubCrypto{}, log.New(io.Discard, "", 0))
+	notifier := &recordingNotifier{}
+	engine.AddNotifier(notifier)
+
+	store.CreateAlert(domain.Alert{UserID: "u", Type: domain.AlertPriceAbove, Symbol: "AAPL", Threshold: 190, CooldownMinutes: 30})
+	store.CreateAlert(domain.Alert{UserID: "u", Type: domain.AlertRatioAbove, Symbol: "AAPL", Ratio: "roe", Threshold: 20})
+	store.CreateAlert(domain.Alert{UserID: "u", Type: domain.AlertPriceBelow, Symbol: "BTC", AssetClass: domain.Crypto, Threshold: 50000})
+
+	events, err := engine.EvaluateUserAlerts(context.Background(), "u")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].AlertID != 1 || events[0].Value != 200 || events[1].AlertID != 2 || events[1].Value != 25 {
+		t.Fatalf("expected the price and the ratio alerts to trigger, got %+v", events)
+	}
+	if len(notifier.events) != 2 || notifier.events[0].ID == 0 {
+		t.Errorf("expected the stored events to be notified, got %+v", notifier.events)
+	}
+
+	// The conditions still hold, they don't trigger again
+	if events, _ := engine.EvaluateUserAlerts(context.Background(), "u"); len(events) != 0 {
+		t.Errorf("expected no events, got %+v", events)
+	}
+
+	// Back below and above again within the cooldown, the trigger is delayed to the end of the cooldown
+	data.stubPrices["aapl"] = 180
+	engine.EvaluateUserAlerts(context.Background(), "u")
+	data.stubPrices["aapl"] = 195
+	if events, _ := engine.EvaluateUserAlerts(context.Background(), "u"); len(events) != 0 {
+		t.Errorf("expected no events within the cooldown, got %+v", events)
+	}
+	alerts, _ := store.GetAlerts("u")
+	state := alerts[0].State
+	state.LastTriggeredAt = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	store.UpdateAlertState("u", 1, state)
+	if events, _ := engine.EvaluateUserAlerts(context.Background(), "u"); len(events) != 1 || events[0].Value != 195 {
+		t.Errorf("expected the price alert to trigger after the cooldown, got %+v", events)
+	}
+
+	if events, _ := store.GetAlertEvents("u", 0); len(events) != 3 || events[0].ID != 3 {
+		t.Errorf("expected 3 events, the most recent first, got %+v", events)
+	}
+}
+
+func TestAlertEngineNewItems(t *testing.T) {
+	store, _ := NewAlertLocalDataService("")
+	data := &stubAlertData{news: []domain.NewsArticle{{Url: "a", Title: "Apple ships the iPhone"}}}
+	insiders := stubInsiders{
+		{TransactionDate: "2025-06-01", Executive: "Cook", AcquisitionOrDisposal: "A", Shares: 100, SharePrice: 200},
+	}
+	engine, _ := NewAlertEngine(store, data, insiders, stubCrypto{}, log.New(io.Discard, "", 0))
+	notifier := &recordingNotifier{err: fmt.Errorf("unreachable")}
+	engine.AddNotifier(notifier)
+
+	store.CreateAlert(domain.Alert{UserID: "u", Type: domain.AlertNewsMention, Symbol: "AAPL", Keyword: "iphone"})
+	store.CreateAlert(domain.Alert{UserID: "u", Type: domain.AlertInsiderBuy, Symbol: "AAPL"})
+
+	// The items found by the first evaluation don't trigger
+	if events, _ := engine.EvaluateUserAlerts(context.Background(), "u"); len(events) != 0 {
+		t.Fatalf("expected no events on the first evaluation, got %+v", events)
+	}
+
+	data.news = append(data.news,
+		domain.NewsArticle{Url: "b", Title: "Apple results", Text: "The iPhone sales grew"},
+		domain.NewsArticle{Url: "c", Title: "Apple results"},
+	)
+	engine.insiders = append(insiders,
+		domain.InsiderTransaction{TransactionDate: "2025-06-02", Executive: "Cook", AcquisitionOrDisposal: "A", Shares: 1000},
+		domain.InsiderTransaction{TransactionDate: "2025-06-02", Executive: "Williams", AcquisitionOrDisposal: "D", Shares: 50, SharePrice: 210},
+	)
+	events, _ := engine.EvaluateUserAlerts(context.Background(), "u")
+	if len(events) != 1 || events[0].AlertID != 1 || events[0].Value != 1 {
+		t.Fatalf("expected the keyword article to trigger, not the grant nor the sale, got %+v", events)
+	}
+	if len(events[0].NotificationErrors) != 1 {
+		t.Errorf("expected the notification error to be recorded, got %+v", events[0])
+	}
+	if stored, _ := store.GetAlertEvents("u", 1); len(stored) != 1 || len(stored[0].NotificationErrors) != 1 {
+		t.Errorf("expected the notification error to be stored, got %+v", stored)
+	}
+
+	if events, _ := engine.EvaluateUserAlerts(context.Background(), "u"); len(events) != 0 {
+		t.Errorf("expected the article to trigger once, got %+v", events)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	received := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Alert-Event-Id") == "u/7" {
+			received++
+		}
+	}))
+	defer server.Close()
+	alert := domain.Alert{UserID: "u", ID: 1, WebhookURL: server.URL}
+	event := domain.AlertEvent{UserID: "u", ID: 7, AlertID: 1}
+
+	// The test server listens on the loopback, rejected unless allowed
+	notifier, _ := NewWebhookNotifier(time.Second, nil)
+	if err := notifier.NotifyAlert(context.Background(), alert, event); err == nil || received != 0 {
+		t.Errorf("expected the loopback webhook to be rejected, got %v", err)
+	}
+
+	notifier, _ = NewWebhookNotifier(time.Second, []string{"127.0.0.0/8", "::1/128"})
+	if err := notifier.NotifyAlert(context.Background(), alert, event); err != nil || received != 1 {
+		t.Errorf("expected the allowed webhook to be notified, got %v", err)
+	}
+
+	if _, err := NewWebhookNotifier(time.Second, []string{"10.0.0.0"}); err == nil {
+		t.Error("expected an error for an invalid allowed network")
+	}
+}
+
+func TestWebhookNotifierCheckAddress(t *testing.T) {
+	notifier, _ := NewWebhookNotifier(time.Second, []string{"10.1.0.0/16"})
+
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{address: "93.184.215.14:443", allowed: true},
+		{address: "[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443", allowed: true},
+		{address: "10.1.2.3:80", allowed: true},
+		{address: "10.2.0.1:80", allowed: false},
+		{address: "127.0.0.1:80", allowed: false},
+		{address: "192.168.1.1:80", allowed: false},
+		{address: "169.254.169.254:80", allowed: false},
+		{address: "100.64.0.1:80", allowed: false},
+		{address: "0.0.0.0:80", allowed: false},
+		{address: "[::1]:80", allowed: false},
+		{address: "[fe80::1]:80", allowed: false},
+		{address: "[fd00::1]:80", allowed: false},
+		{address: "[::ffff:127.0.0.1]:80", allowed: false},
+	}
+
+	for _, tt := range tests {
+		if err := notifier.checkAddress("tcp", tt.address, nil); (err == nil) != tt.allowed {
+			t.Errorf("expected %s to be allowed: %v, got %v", tt.address, tt.allowed, err)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	badger "github.com/dgraph-io/badger/v4"
@@ -24,13 +28,27 @@ func NewBadgerCacheService() (*BadgerCacheService, error) {
 		return nil, err
 	}
 
-	db, err := badger.Open(badger.DefaultOptions("cache.db"))
+	return openBadgerCacheService(badger.DefaultOptions("cache.db"))
+}
+
+// OpenBadgerCacheService opens the cache stored in the directory at path and keeps its entries, so that they
+// outlive the process (e.g. between runs of a command line tool). Badger doesn't log, the output stays clean.
+func OpenBadgerCacheService(path string) (*BadgerCacheService, error) {
+	return openBadgerCacheService(badger.DefaultOptions(path).WithLogger(nil))
+}
+
+func openBadgerCacheService(options badger.Options) (*BadgerCacheService, error) {
+	db, err := badger.Open(options)
 	if err != nil {
 		return nil, err
 	}
 	return &BadgerCacheService{db: db}, nil
 }
 
+func (c *BadgerCacheService) Close() error {
+	return c.db.Close()
+}
+
 func (c *BadgerCacheService) Get(key string, target interface{}) error {
 	var data []byte
 	err := c.db.View(func(txn *badger.Txn) error {
@@ -67,3 +85,75 @@ func (c *BadgerCacheService) Delete(key string) error {
 		return txn.Delete([]byte(key))
 	})
 }
+
+// MemoryCacheService keeps the entries in memory, it is meant for the short lived processes
+// (e.g. cmd/validate) that must not reset the cache.db directory of a running server
+type MemoryCacheService struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+func NewMemoryCacheService() *MemoryCacheService {
+	return &MemoryCacheService{entries: make(map[string]memoryCacheEntry)}
+}
+
+func (c *MemoryCacheService) Get(key string, target interface{}) error {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return fmt.Errorf("key %s not found in cache", key)
+	}
+
+	return json.Unmarshal(entry.data, target)
+}
+
+func (c *MemoryCacheService) Set(key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = memoryCacheEntry{data: data, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (c *MemoryCacheService) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+// namespacedCacheService prefixes the keys of another cache
+type namespacedCacheService struct {
+	cache  CacheService
+	prefix string
+}
+
+// NamespacedCache returns a cache whose keys are prefixed with a hash of the namespace, e.g. the base URL of an
+// upstream, so that the entries cached from another upstream (a fake or a recorded one) are never served
+func NamespacedCache(cache CacheService, namespace string) CacheService {
+	sum := sha256.Sum256([]byte(namespace))
+	return namespacedCacheService{cache: cache, prefix: hex.EncodeToString(sum[:6]) + "_"}
+}
+
+func (c namespacedCacheService) Get(key string, target interface{}) error {
+	return c.cache.Get(c.prefix+key, target)
+}
+
+func (c namespacedCacheService) Set(key string, value interface{}, ttl time.Duration) error {
+	return c.cache.Set(c.prefix+key, value, ttl)
+}
+
+func (c namespacedCacheService) Delete(key string) error {
+	return c.cache.Delete(c.prefix + key)
+}
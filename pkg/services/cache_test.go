@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNamespacedCache(t *testing.T) {
+	cache := NewMemoryCacheService()
+	upstream := NamespacedCache(cache, "https://stockanalysis.com")
+	fake := NamespacedCache(cache, "http://localhost:8090/stockanalysis")
+
+	upstream.Set("sectors", "real", time.Minute)
+	var value string
+	if err := fake.Get("sectors", &value); err == nil {
+		t.Errorf("expected a miss in another namespace, got %q", value)
+	}
+	if err := cache.Get("sectors", &value); err == nil {
+		t.Errorf("expected the key to be prefixed, got %q", value)
+	}
+
+	fake.Set("sectors", "fake", time.Minute)
+	if err := NamespacedCache(cache, "https://stockanalysis.com").Get("sectors", &value); err != nil || value != "real" {
+		t.Errorf("expected the entry of the namespace, got %q, %v", value, err)
+	}
+
+	upstream.Delete("sectors")
+	if err := upstream.Get("sectors", &value); err == nil {
+		t.Error("expected the entry to be deleted")
+	}
+	if err := fake.Get("sectors", &value); err != nil || value != "fake" {
+		t.Errorf("expected the entry of the other namespace to be kept, got %q, %v", value, err)
+	}
+}
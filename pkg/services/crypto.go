@@ -2,12 +2,13 @@ package services
 
 import (
 	"market_data_mcp_server/pkg/domain"
-	"strings"
+	"market_data_mcp_server/pkg/search"
 )
 
 type ICryptoDataService interface {
 	GetCryptocurrenciesList() ([]domain.Cryptocurrency, error)
 	GetCryptocurrencyDataById(id string) (domain.CryptocurrencyData, error)
+	GetCryptocurrenciesMarketCaps() (map[string]float64, error)
 }
 
 type CryptoNewsSource interface {
@@ -17,10 +18,13 @@ type CryptoNewsSource interface {
 type CryptoService struct {
 	cryptoDataService ICryptoDataService
 	cryptoNewsSource  CryptoNewsSource
+	index             *search.CachedIndex[domain.Cryptocurrency]
 }
 
 func NewCryptoService(cryptoDataService ICryptoDataService, cryptoNewsSource CryptoNewsSource) (*CryptoService, error) {
-	return &CryptoService{cryptoDataService: cryptoDataService, cryptoNewsSource: cryptoNewsSource}, nil
+	s := &CryptoService{cryptoDataService: cryptoDataService, cryptoNewsSource: cryptoNewsSource}
+	s.index = search.NewCachedIndex(s.getCryptocurrenciesWithMarketCaps, cryptocurrencyDocument, searchIndexTtl)
+	return s, nil
 }
 
 func (s *CryptoService) GetCryptocurrenciesList() ([]domain.Cryptocurrency, error) {
@@ -31,44 +35,39 @@ func (s *CryptoService) GetCryptocurrencyDataById(id string) (domain.Cryptocurre
 	return s.cryptoDataService.GetCryptocurrencyDataById(id)
 }
 
-func (s *CryptoService) SearchCryptocurrencies(query string) ([]domain.Cryptocurrency, error) {
+// SearchCryptocurrencies returns the cryptocurrencies that match the query (name, symbol or id) ranked by relevance
+func (s *CryptoService) SearchCryptocurrencies(query string) ([]search.Result[domain.Cryptocurrency], error) {
+	index, err := s.index.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return index.Search(query), nil
+}
+
+func (s *CryptoService) getCryptocurrenciesWithMarketCaps() ([]domain.Cryptocurrency, error) {
 	cryptocurrenciesList, err := s.cryptoDataService.GetCryptocurrenciesList()
 	if err != nil {
 		return nil, err
 	}
 
-	query = strings.ToLower(query)
-	var searchResults []domain.Cryptocurrency
-	for _, cryptocurrency := range cryptocurrenciesList {
-		cryptoName := strings.ToLower(cryptocurrency.Name)
-		cryptoSymbol := strings.ToLower(cryptocurrency.Symbol)
-		cryptoId := strings.ToLower(cryptocurrency.Id)
-		if strings.Contains(cryptoName, query) {
-			searchResults = append(searchResults, cryptocurrency)
-			if cryptoName == query {
-				return []domain.Cryptocurrency{cryptocurrency}, nil
-			}
-			continue
-		}
-		if strings.Contains(cryptoSymbol, query) {
-			searchResults = append(searchResults, cryptocurrency)
-			if cryptoSymbol == query {
-				return []domain.Cryptocurrency{cryptocurrency}, nil
-			}
-			continue
-		}
-		if strings.Contains(cryptoId, query) {
-			searchResults = append(searchResults, cryptocurrency)
-			if cryptoId == query {
-				return []domain.Cryptocurrency{cryptocurrency}, nil
-			}
-			continue
-		}
+	// The market caps only improve the ranking, the search still works without them
+	marketCaps, err := s.cryptoDataService.GetCryptocurrenciesMarketCaps()
+	if err != nil {
+		marketCaps = map[string]float64{}
+	}
+
+	for i := range cryptocurrenciesList {
+		cryptocurrenciesList[i].MarketCap = marketCaps[cryptocurrenciesList[i].Id]
 	}
 
-	return searchResults, nil
+	return cryptocurrenciesList, nil
 }
 
 func (s *CryptoService) GetCryptocurrencyNews(symbol string) ([]domain.NewsArticle, error) {
 	return s.cryptoNewsSource.GetCryptocurrencyNews(symbol)
 }
+
+func cryptocurrencyDocument(c domain.Cryptocurrency) search.Document {
+	return search.Document{Symbol: c.Symbol, Name: c.Name, Aliases: []string{c.Id}, Weight: c.MarketCap}
+}
@@ -0,0 +1,405 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/analytics/dcf"
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHighGrowthYears   = 5
+	defaultFadeYears         = 5
+	maxDcfStageYears         = 10
+	defaultTerminalGrowth    = 0.025
+	defaultEquityRiskPremium = 0.05
+	defaultTaxRate           = 0.21
+)
+
+// dcfSensitivitySteps are the steps of the discount rates and the terminal growths of the sensitivity grids around
+// the ones of the valuation, and dcfMultipleSteps the factors of the exit multiples
+var (
+	dcfSensitivitySteps = []float64{-0.01, -0.005, 0, 0.005, 0.01}
+	dcfMultipleSteps    = []float64{0.8, 0.9, 1, 1.1, 1.2}
+)
+
+type DcfDataService interface {
+	GetCashFlows(symbol string) ([]domain.CashFlow, error)
+	GetIncomeStatements(symbol string) ([]domain.IncomeStatement, error)
+	GetBalanceSheets(symbol string) ([]domain.BalanceSheet, error)
+	GetStockForecast(symbol string) (domain.StockForecast, error)
+	GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error)
+	GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error)
+}
+
+type RiskMetricsSource interface {
+	GetRiskMetrics(query RiskMetricsQuery) (RiskMetricsResult, error)
+}
+
+// DcfQuery sets the assumptions of a valuation, the ones not set are derived from the data of the stock. The rates
+// and the growths are fractions.
+type DcfQuery struct {
+	Symbol            string
+	Growth            *float64 // Yearly growth of the free cash flow in the high growth years
+	HighGrowthYears   int
+	FadeYears         *int // Years the growth fades linearly to the terminal growth
+	BaseCashFlow      float64
+	DiscountRate      float64 // Replaces the WACC
+	RiskFreeRate      *float64
+	Beta              *float64
+	EquityRiskPremium float64
+	TerminalMethod    dcf.TerminalMethod
+	TerminalGrowth    *float64
+	ExitMultiple      float64 // Multiple of the free cash flow of the last year
+}
+
+// DcfWacc is the discount rate and how it was derived, the components are zero when it is given
+type DcfWacc struct {
+	Rate              float64
+	Source            string // derived or override
+	RiskFreeRate      float64
+	Beta              float64
+	EquityRiskPremium float64
+	CostOfEquity      float64
+	CostOfDebt        float64 // Before taxes
+	TaxRate           float64
+	EquityWeight      float64
+	DebtWeight        float64
+}
+
+// DcfSensitivity is the value per share for each discount rate (the rows) and each terminal growth or exit
+// multiple (the columns), nil when the model can't be valued
+type DcfSensitivity struct {
+	DiscountRates []float64
+	Terminals     []float64
+	Values        [][]*float64
+}
+
+type DcfResult struct {
+	Symbol          string
+	Price           float64
+	Shares          float64
+	NetCash         float64
+	BaseCashFlow    float64
+	Growth          float64
+	GrowthSource    string // analyst_estimates, historical or override
+	HighGrowthYears int
+	FadeYears       int
+	Wacc            DcfWacc
+	TerminalMethod  dcf.TerminalMethod
+	TerminalGrowth  float64
+	ExitMultiple    float64
+	Valuation       dcf.Valuation
+	Upside          float64 // Of the value per share over the price
+	Sensitivity     DcfSensitivity
+	Warnings        []string
+}
+
+// DcfService values the stocks by discounting their free cash flow: the trailing twelve months one grows at the
+// analyst revenue estimates for the high growth years, then fades to the terminal growth. The discount rate is the
+// WACC, the cost of equity from the 10 year treasury yield and the 5 year beta against SPY.
+type DcfService struct {
+	data   DcfDataService
+	yields TreasuryYieldSource
+	risk   RiskMetricsSource
+	now    func() time.Time
+}
+
+func NewDcfService(data DcfDataService, yields TreasuryYieldSource, risk RiskMetricsSource) (*DcfService, error) {
+	return &DcfService{
+		data:   data,
+		yields: yields,
+		risk:   risk,
+		now:    time.Now,
+	}, nil
+}
+
+func (s *DcfService) CalculateDcf(query DcfQuery) (DcfResult, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(query.Symbol))
+	if symbol == "" {
+		return DcfResult{}, fmt.Errorf("symbol is required")
+	}
+	result := DcfResult{
+		Symbol:          symbol,
+		HighGrowthYears: cmp.Or(query.HighGrowthYears, defaultHighGrowthYears),
+		FadeYears:       defaultFadeYears,
+		TerminalMethod:  cmp.Or(query.TerminalMethod, dcf.PerpetuityGrowth),
+		TerminalGrowth:  defaultTerminalGrowth,
+		ExitMultiple:    query.ExitMultiple,
+	}
+	if query.FadeYears != nil {
+		result.FadeYears = *query.FadeYears
+	}
+	if result.HighGrowthYears < 1 || result.HighGrowthYears > maxDcfStageYears {
+		return DcfResult{}, fmt.Errorf("high_growth_years must be between 1 and %d", maxDcfStageYears)
+	}
+	if result.FadeYears < 0 || result.FadeYears > maxDcfStageYears {
+		return DcfResult{}, fmt.Errorf("fade_years must be between 0 and %d", maxDcfStageYears)
+	}
+	if result.TerminalMethod != dcf.PerpetuityGrowth && result.TerminalMethod != dcf.ExitMultiple {
+		return DcfResult{}, fmt.Errorf("terminal_method valid values are: perpetuity_growth, exit_multiple")
+	}
+	if query.TerminalGrowth != nil {
+		result.TerminalGrowth = *query.TerminalGrowth
+	}
+	if query.ExitMultiple < 0 || query.DiscountRate < 0 || query.EquityRiskPremium < 0 {
+		return DcfResult{}, fmt.Errorf("exit_multiple, discount_rate and equity_risk_premium can't be negative")
+	}
+
+	// Lowercase like the other tools, so that they share the cached data
+	lower := strings.ToLower(symbol)
+	cashFlows, err := s.data.GetCashFlows(lower)
+	if err != nil && query.BaseCashFlow == 0 {
+		return DcfResult{}, fmt.Errorf("failed to get the cash flows of %s: %w", symbol, err)
+	}
+	incomeStatements, err := s.data.GetIncomeStatements(lower)
+	if err != nil {
+		return DcfResult{}, fmt.Errorf("failed to get the income statements of %s: %w", symbol, err)
+	}
+	balanceSheets, err := s.data.GetBalanceSheets(lower)
+	if err != nil {
+		return DcfResult{}, fmt.Errorf("failed to get the balance sheets of %s: %w", symbol, err)
+	}
+	prices, err := s.data.GetHistoricalPrices(lower, domain.Stock, domain.Period1M)
+	if err != nil {
+		return DcfResult{}, fmt.Errorf("failed to get the price of %s: %w", symbol, err)
+	}
+	if len(prices.Prices) == 0 || len(balanceSheets) == 0 {
+		return DcfResult{}, fmt.Errorf("no price or balance sheet found for %s", symbol)
+	}
+	result.Price = prices.Prices[len(prices.Prices)-1].ClosePrice
+
+	// The statements are quarterly, the last first
+	result.Shares = balanceSheets[0].SharesOutTotalCommon
+	if result.Shares <= 0 && len(incomeStatements) > 0 {
+		result.Shares = incomeStatements[0].SharesDiluted
+	}
+	result.NetCash = balanceSheets[0].Netcash
+
+	result.BaseCashFlow = query.BaseCashFlow
+	if result.BaseCashFlow == 0 {
+		if len(cashFlows) < 4 {
+			return DcfResult{}, fmt.Errorf("less than 4 quarters of cash flows found for %s, base_fcf is required", symbol)
+		}
+		for _, cashFlow := range cashFlows[:4] {
+			result.BaseCashFlow += cashFlow.Fcf
+		}
+		if result.BaseCashFlow <= 0 {
+			return DcfResult{}, fmt.Errorf("the trailing twelve months free cash flow of %s isn't positive, base_fcf is required", symbol)
+		}
+	}
+
+	if query.Growth != nil {
+		result.Growth, result.GrowthSource = *query.Growth, "override"
+	} else if result.Growth, result.GrowthSource, err = s.growth(lower, incomeStatements); err != nil {
+		return DcfResult{}, err
+	}
+
+	if result.TerminalMethod == dcf.ExitMultiple && result.ExitMultiple == 0 {
+		ratios, err := s.data.GetFinancialRatios(lower)
+		if err != nil || len(ratios) == 0 || ratios[0].EvFcf <= 0 {
+			return DcfResult{}, fmt.Errorf("the current EV/FCF of %s is unknown, exit_multiple is required", symbol)
+		}
+		// The first ratios are the trailing twelve months ones
+		result.ExitMultiple = ratios[0].EvFcf
+	}
+
+	if query.DiscountRate != 0 {
+		result.Wacc = DcfWacc{Rate: query.DiscountRate, Source: "override"}
+	} else {
+		var warnings []string
+		if result.Wacc, warnings, err = s.wacc(symbol, query, result.Price*result.Shares, balanceSheets[0], incomeStatements); err != nil {
+			return DcfResult{}, err
+		}
+		result.Warnings = append(result.Warnings, warnings...)
+	}
+
+	model := dcf.Model{
+		BaseCashFlow:   result.BaseCashFlow,
+		Stages:         []dcf.Stage{{Years: result.HighGrowthYears, StartGrowth: result.Growth, EndGrowth: result.Growth}},
+		DiscountRate:   result.Wacc.Rate,
+		Terminal:       result.TerminalMethod,
+		TerminalGrowth: result.TerminalGrowth,
+		ExitMultiple:   result.ExitMultiple,
+		NetCash:        result.NetCash,
+		Shares:         result.Shares,
+	}
+	if result.FadeYears > 0 {
+		model.Stages = append(model.Stages, dcf.Stage{Years: result.FadeYears, StartGrowth: result.Growth, EndGrowth: result.TerminalGrowth})
+	}
+	result.Valuation, err = dcf.Value(model)
+	if err != nil {
+		return DcfResult{}, err
+	}
+	if result.Price > 0 {
+		result.Upside = result.Valuation.PerShare/result.Price - 1
+	}
+	if result.Valuation.PerShare < 0 {
+		result.Warnings = append(result.Warnings, "the net debt is greater than the enterprise value")
+	}
+
+	result.Sensitivity = sensitivity(model)
+	return result, nil
+}
+
+// growth returns the growth of the high growth years: the growth of the revenue of the next four quarters
+// estimated by the analysts over the one of the last four quarters, or the growth of the revenue of the last four
+// quarters over the four before them
+func (s *DcfService) growth(symbol string, incomeStatements []domain.IncomeStatement) (float64, string, error) {
+	var ttm, previous float64
+	for i, incomeStatement := range incomeStatements[:min(len(incomeStatements), 8)] {
+		if i < 4 {
+			ttm += incomeStatement.Revenue
+		} else {
+			previous += incomeStatement.Revenue
+		}
+	}
+	if len(incomeStatements) < 4 || ttm <= 0 {
+		return 0, "", fmt.Errorf("less than 4 quarters of revenue found for %s, growth is required", strings.ToUpper(symbol))
+	}
+
+	// The estimations can't be fetched for the stocks without analysts
+	forecast, err := s.data.GetStockForecast(symbol)
+	if err == nil {
+		now := s.now()
+		var estimated float64
+		var quarters int
+		for _, estimation := range forecast.Estimations {
+			date, err := time.Parse(time.DateOnly, estimation.Date)
+			if err != nil || !date.After(now) || quarters == 4 {
+				continue
+			}
+			estimated += estimation.Revenue
+			quarters++
+		}
+		if quarters == 4 && estimated > 0 {
+			return estimated/ttm - 1, "analyst_estimates", nil
+		}
+	}
+
+	if len(incomeStatements) < 8 || previous <= 0 {
+		return 0, "", fmt.Errorf("no revenue estimates nor 8 quarters of revenue found for %s, growth is required", strings.ToUpper(symbol))
+	}
+	return ttm/previous - 1, "historical", nil
+}
+
+// wacc returns the weighted average cost of capital, with the beta and the risk-free rate of the query when given,
+// and the warnings of the assumptions made when the data is missing
+func (s *DcfService) wacc(symbol string, query DcfQuery, equity float64, balanceSheet domain.BalanceSheet, incomeStatements []domain.IncomeStatement) (DcfWacc, []string, error) {
+	var warnings []string
+	wacc := DcfWacc{Source: "derived", EquityRiskPremium: cmp.Or(query.EquityRiskPremium, defaultEquityRiskPremium)}
+
+	if query.RiskFreeRate != nil {
+		wacc.RiskFreeRate = *query.RiskFreeRate
+	} else {
+		rate, err := s.tenYearYield()
+		if err != nil {
+			return DcfWacc{}, nil, fmt.Errorf("failed to get the 10 year treasury yield, discount_rate or risk_free_rate is required: %w", err)
+		}
+		wacc.RiskFreeRate = rate
+	}
+
+	if query.Beta != nil {
+		wacc.Beta = *query.Beta
+	} else {
+		metrics, err := s.risk.GetRiskMetrics(RiskMetricsQuery{Symbol: symbol, AssetClass: domain.Stock, Period: domain.Period5Y})
+		switch {
+		case err != nil:
+			wacc.Beta = 1
+			warnings = append(warnings, fmt.Sprintf("the beta is 1, failed to compute the 5 year beta: %v", err))
+		case !metrics.Metrics.HasBenchmark:
+			wacc.Beta = 1
+			warnings = append(warnings, "the beta is 1, the 5 year beta has no benchmark")
+		default:
+			wacc.Beta = metrics.Metrics.Beta
+		}
+	}
+	wacc.CostOfEquity = dcf.CostOfEquity(wacc.RiskFreeRate, wacc.Beta, wacc.EquityRiskPremium)
+
+	// The cost of the debt is its interest over the last four quarters, and the tax rate their effective one
+	var interest, taxes, pretax float64
+	for _, incomeStatement := range incomeStatements[:min(len(incomeStatements), 4)] {
+		interest += math.Abs(incomeStatement.InterestExpense)
+		taxes += incomeStatement.Taxexp
+		pretax += incomeStatement.Pretax
+	}
+	wacc.TaxRate = defaultTaxRate
+	if pretax > 0 && taxes >= 0 {
+		wacc.TaxRate = min(taxes/pretax, 0.5)
+	}
+	debt := balanceSheet.Debt
+	wacc.CostOfDebt = wacc.RiskFreeRate
+	if debt > 0 && interest > 0 {
+		wacc.CostOfDebt = interest / debt
+	} else if debt > 0 {
+		warnings = append(warnings, "no interest expense found, the cost of debt is the risk-free rate")
+	}
+
+	if equity+debt > 0 {
+		wacc.EquityWeight, wacc.DebtWeight = equity/(equity+debt), max(debt, 0)/(equity+debt)
+	}
+	wacc.Rate = dcf.Wacc(equity, max(debt, 0), wacc.CostOfEquity, wacc.CostOfDebt, wacc.TaxRate)
+	return wacc, warnings, nil
+}
+
+// tenYearYield returns the last 10 year treasury yield
+func (s *DcfService) tenYearYield() (float64, error) {
+	timeSeries, err := s.yields.GetTreasuryYieldTimeSeries(domain.TenYearTreasuryYieldMaturity)
+	if err != nil {
+		return 0, err
+	}
+
+	var last float64
+	var lastDate time.Time
+	for _, entry := range timeSeries.Data {
+		date, err := time.Parse(time.DateOnly, entry.Date)
+		if err != nil {
+			continue
+		}
+		value, err := strconv.ParseFloat(entry.Value, 64)
+		if err != nil {
+			// Missing values are "."
+			continue
+		}
+		if date.After(lastDate) {
+			last, lastDate = value, date
+		}
+	}
+	if lastDate.IsZero() {
+		return 0, fmt.Errorf("no treasury yields found")
+	}
+	return last / 100, nil
+}
+
+// sensitivity returns the values per share of the model around its discount rate and its terminal growth or exit
+// multiple
+func sensitivity(model dcf.Model) DcfSensitivity {
+	var result DcfSensitivity
+	for _, step := range dcfSensitivitySteps {
+		result.DiscountRates = append(result.DiscountRates, model.DiscountRate+step)
+		if model.Terminal == dcf.PerpetuityGrowth {
+			result.Terminals = append(result.Terminals, model.TerminalGrowth+step)
+		}
+	}
+	if model.Terminal == dcf.ExitMultiple {
+		for _, factor := range dcfMultipleSteps {
+			result.Terminals = append(result.Terminals, model.ExitMultiple*factor)
+		}
+	}
+
+	grid := dcf.Sensitivity(model, result.DiscountRates, result.Terminals)
+	result.Values = make([][]*float64, len(grid))
+	for i, row := range grid {
+		result.Values[i] = make([]*float64, len(row))
+		for j, value := range row {
+			if !math.IsNaN(value) {
+				result.Values[i][j] = &value
+			}
+		}
+	}
+	return result
+}
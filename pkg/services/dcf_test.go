@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"market_data_mcp_server/pkg/analytics/dcf"
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+type stubDcfData struct {
+	fcf         float64
+	forecastErr error
+}
+
+func (s stubDcfData) GetCashFlows(symbol string) ([]domain.CashFlow, error) {
+	return []domain.CashFlow{{Fcf: s.fcf}, {Fcf: s.fcf}, {Fcf: s.fcf}, {Fcf: s.fcf}, {Fcf: 1}}, nil
+}
+
+func (s stubDcfData) GetIncomeStatements(symbol string) ([]domain.IncomeStatement, error) {
+	statements := make([]domain.IncomeStatement, 8)
+	for i := range statements {
+		statements[i] = domain.IncomeStatement{Revenue: 250, InterestExpense: -1, Taxexp: 2, Pretax: 10}
+		if i >= 4 {
+			statements[i].Revenue = 200
+		}
+	}
+	return statements, nil
+}
+
+func (s stubDcfData) GetBalanceSheets(symbol string) ([]domain.BalanceSheet, error) {
+	return []domain.BalanceSheet{{SharesOutTotalCommon: 10, Netcash: 50, Debt: 40}}, nil
+}
+
+func (s stubDcfData) GetStockForecast(symbol string) (domain.StockForecast, error) {
+	if s.forecastErr != nil {
+		return domain.StockForecast{}, s.forecastErr
+	}
+	return domain.StockForecast{Estimations: []domain.StockEstimation{
+		{Date: "2024-12-31", Revenue: 250},
+		{Date: "2025-03-31", Revenue: 275},
+		{Date: "2025-06-30", Revenue: 275},
+		{Date: "2025-09-30", Revenue: 275},
+		{Date: "2025-12-31", Revenue: 275},
+		{Date: "2026-03-31", Revenue: 1000},
+	}}, nil
+}
+
+func (s stubDcfData) GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error) {
+	return []domain.FinancialRatios{{EvFcf: 20}}, nil
+}
+
+func (s stubDcfData) GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error) {
+	return domain.HistoricalPrices{Prices: []domain.Price{{ClosePrice: 90}, {ClosePrice: 100}}}, nil
+}
+
+type stubTreasuryYields struct{}
+
+func (stubTreasuryYields) GetTreasuryYieldTimeSeries(maturity domain.TreasuryYieldMaturity) (domain.EconomicIndicatorTimeSeries, error) {
+	if maturity != domain.TenYearTreasuryYieldMaturity {
+		return domain.EconomicIndicatorTimeSeries{}, fmt.Errorf("unexpected maturity %s", maturity)
+	}
+	return domain.EconomicIndicatorTimeSeries{Data: []domain.EconomicIndicatorTimeSeriesEntry{
+		{Date: "2024-12-01", Value: "."},
+		{Date: "2024-11-01", Value: "4.0"},
+		{Date: "2024-10-01", Value: "4.5"},
+	}}, nil
+}
+
+type stubBeta float64
+
+func (s stubBeta) GetRiskMetrics(query RiskMetricsQuery) (RiskMetricsResult, error) {
+	result := RiskMetricsResult{Symbol: query.Symbol}
+	result.Metrics.HasBenchmark, result.Metrics.Beta = true, float64(s)
+	return result, nil
+}
+
+func TestCalculateDcf(t *testing.T) {
+	s, _ := NewDcfService(stubDcfData{fcf: 25}, stubTreasuryYields{}, stubBeta(1.2))
+	s.now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	result, err := s.CalculateDcf(DcfQuery{Symbol: "aapl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The next four quarters are estimated at 1100 against 1000 over the last four
+	if result.BaseCashFlow != 100 || math.Abs(result.Growth-0.1) > 1e-9 || result.GrowthSource != "analyst_estimates" || len(result.Valuation.Years) != 10 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	// The cost of equity is 4% + 1.2 x 5%, the one of the debt 4 / 40 taxed at 20%
+	wacc := result.Wacc
+	if math.Abs(wacc.CostOfEquity-0.1) > 1e-9 || math.Abs(wacc.CostOfDebt-0.1) > 1e-9 || math.Abs(wacc.TaxRate-0.2) > 1e-9 ||
+		math.Abs(wacc.Rate-103.2/1040) > 1e-9 || wacc.Source != "derived" {
+		t.Errorf("unexpected wacc %+v", wacc)
+	}
+	if math.Abs(result.Upside-(result.Valuation.PerShare/100-1)) > 1e-9 || len(result.Warnings) != 0 {
+		t.Errorf("unexpected upside %v, warnings %v", result.Upside, result.Warnings)
+	}
+	values := result.Sensitivity.Values
+	if len(values) != 5 || len(values[0]) != 5 || math.Abs(*values[2][2]-result.Valuation.PerShare) > 1e-9 || *values[0][2] <= *values[4][2] {
+		t.Errorf("unexpected sensitivity %+v", result.Sensitivity)
+	}
+
+	// The example of the dcf package: 100 growing 10% for 2 years at 10%, then 2%
+	growth, fadeYears, terminalGrowth := 0.1, 0, 0.02
+	result, err = s.CalculateDcf(DcfQuery{Symbol: "AAPL", Growth: &growth, HighGrowthYears: 2, FadeYears: &fadeYears, DiscountRate: 0.1, TerminalGrowth: &terminalGrowth})
+	if err != nil || math.Abs(result.Valuation.PerShare-152.5) > 1e-9 || result.GrowthSource != "override" || result.Wacc.Source != "override" {
+		t.Errorf("unexpected result %+v, %v", result, err)
+	}
+
+	result, _ = s.CalculateDcf(DcfQuery{Symbol: "AAPL", TerminalMethod: dcf.ExitMultiple})
+	if result.ExitMultiple != 20 || result.Sensitivity.Terminals[0] != 16 {
+		t.Errorf("expected the current EV/FCF as exit multiple, got %+v", result)
+	}
+
+	s.data = stubDcfData{fcf: 25, forecastErr: fmt.Errorf("no analysts")}
+	if result, err := s.CalculateDcf(DcfQuery{Symbol: "AAPL"}); err != nil || math.Abs(result.Growth-0.25) > 1e-9 || result.GrowthSource != "historical" {
+		t.Errorf("expected the historical growth, got %+v, %v", result, err)
+	}
+
+	fadeYears = -1
+	for _, invalid := range []DcfQuery{{}, {Symbol: "AAPL", TerminalMethod: "gordon"}, {Symbol: "AAPL", FadeYears: &fadeYears}, {Symbol: "AAPL", HighGrowthYears: 11}} {
+		if _, err := s.CalculateDcf(invalid); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+
+	s.data = stubDcfData{fcf: -25}
+	if _, err := s.CalculateDcf(DcfQuery{Symbol: "AAPL"}); err == nil {
+		t.Error("expected an error for a negative free cash flow")
+	}
+	if _, err := s.CalculateDcf(DcfQuery{Symbol: "AAPL", BaseCashFlow: 100}); err != nil {
+		t.Errorf("expected the valuation of the base free cash flow, got %v", err)
+	}
+}
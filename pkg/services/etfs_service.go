@@ -2,7 +2,7 @@ package services
 
 import (
 	"market_data_mcp_server/pkg/domain"
-	"strings"
+	"market_data_mcp_server/pkg/search"
 )
 
 type EtfDataService interface {
@@ -12,51 +12,55 @@ type EtfDataService interface {
 
 type EtfService struct {
 	dataService EtfDataService
+	index       *search.CachedIndex[domain.Etf]
 }
 
 func NewEtfService(dataService EtfDataService) (*EtfService, error) {
-	return &EtfService{dataService: dataService}, nil
+	return &EtfService{
+		dataService: dataService,
+		index:       search.NewCachedIndex(dataService.GetEtfs, etfDocument, searchIndexTtl),
+	}, nil
 }
 
 type EtfFilterOptions struct {
+	Limit        int
+	Cursor       string
 	SearchString string
 }
 
 func (f EtfFilterOptions) IsEmpty() bool {
-	return f.SearchString == ""
+	return f.Limit == 0 && f.Cursor == "" && f.SearchString == ""
 }
 
 func (f EtfFilterOptions) HasSearchString() bool {
 	return f.SearchString != ""
 }
 
-func (s EtfService) GetEtfs(filters EtfFilterOptions) ([]domain.Etf, error) {
-	etfs, err := s.dataService.GetEtfs()
+// GetEtfs returns a page of the ETFs that match the search string ranked by relevance.
+// Without a search string all the ETFs are returned ordered by symbol.
+func (s EtfService) GetEtfs(filters EtfFilterOptions) (Page[search.Result[domain.Etf]], error) {
+	etfs, err := s.SearchEtfs(filters.SearchString)
 	if err != nil {
-		return nil, err
+		return Page[search.Result[domain.Etf]]{}, err
 	}
 
-	if filters.IsEmpty() {
-		return etfs, nil
-	}
+	return paginate(etfs, filters.SearchString, filters.Cursor, filters.Limit)
+}
 
-	if filters.HasSearchString() {
-		filteredEtfs := make([]domain.Etf, 0)
-		for _, e := range etfs {
-			search := strings.ToLower(filters.SearchString)
-			symbol := strings.ToLower(e.Symbol)
-			etfName := strings.ToLower(e.Name)
-			if strings.Contains(symbol, search) || strings.Contains(etfName, search) {
-				filteredEtfs = append(filteredEtfs, e)
-			}
-
-		}
-		return filteredEtfs, nil
+// SearchEtfs returns all the ETFs that match the query ranked by relevance
+func (s EtfService) SearchEtfs(query string) ([]search.Result[domain.Etf], error) {
+	index, err := s.index.Get()
+	if err != nil {
+		return nil, err
 	}
 
-	return etfs, nil
+	return index.Search(query), nil
 }
 
 func (s EtfService) GetEtf(etfSymbol string) (domain.EtfOverview, error) {
 	return s.dataService.GetEtfOverview(etfSymbol)
 }
+
+func etfDocument(e domain.Etf) search.Document {
+	return search.Document{Symbol: e.Symbol, Name: e.Name, Weight: float64(e.Aum)}
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultIndustriesLimit     = 200
+	defaultIndustryStocksLimit = 100
+)
+
+type IndustryDataService interface {
+	GetIndustries() ([]domain.Industry, error)
+	GetIndustryStocks(industry string) ([]domain.IndustryStock, error)
+	GetSectors() ([]domain.Sector, error)
+	GetScreenerStocks(request domain.ScreenerRequest) ([]domain.ScreenedStock, error)
+}
+
+// IndustriesQuery filters the industries, the zero bounds aren't applied
+type IndustriesQuery struct {
+	Sector                             string // Name or url name of the sector
+	MinMarketCap, MaxMarketCap         float64
+	MinPeRatio, MaxPeRatio             float64
+	MinOneYearChange, MaxOneYearChange float64
+	Sort                               string // name, market_cap, pe_ratio, one_year_change, number_of_stocks, dividend_yield or profit_margin
+	Descending                         bool
+	Limit                              int
+}
+
+// SectorIndustry is an industry with its sector, whose name is empty when unknown
+type SectorIndustry struct {
+	domain.Industry
+	Sector        string
+	SectorUrlName string
+}
+
+type IndustriesResult struct {
+	Industries []SectorIndustry
+	Total      int // Number of the industries matching the filters, before the limit
+	Warnings   []string
+}
+
+// IndustryStocksQuery filters the stocks of an industry, the zero bounds aren't applied
+type IndustryStocksQuery struct {
+	Industry                   string // Url name of the industry
+	MinMarketCap, MaxMarketCap float64
+	Sort                       string // market_cap, symbol or name
+	Descending                 bool
+	Limit                      int
+}
+
+type IndustryStocksResult struct {
+	Industry SectorIndustry
+	Stocks   []domain.IndustryStock
+	Total    int // Number of the stocks matching the filters, before the limit
+	Warnings []string
+}
+
+// IndustryService navigates the sectors, their industries and the stocks of the industries. The pages of the
+// industries don't have their sector, an industry belongs to the sector of most of its stocks in the screener.
+type IndustryService struct {
+	data IndustryDataService
+}
+
+func NewIndustryService(data IndustryDataService) (*IndustryService, error) {
+	return &IndustryService{
+		data: data,
+	}, nil
+}
+
+func (s *IndustryService) GetIndustries(query IndustriesQuery) (IndustriesResult, error) {
+	sort := cmp.Or(query.Sort, "market_cap")
+	industryValue, ok := industrySortValues[sort]
+	if !ok {
+		return IndustriesResult{}, fmt.Errorf("sort valid values are: name, market_cap, pe_ratio, one_year_change, number_of_stocks, dividend_yield, profit_margin")
+	}
+	limit := cmp.Or(query.Limit, defaultIndustriesLimit)
+	if limit < 1 {
+		return IndustriesResult{}, fmt.Errorf("limit must be positive")
+	}
+
+	industries, warning, err := s.sectorIndustries()
+	if err != nil {
+		return IndustriesResult{}, err
+	}
+
+	var result IndustriesResult
+	var sector domain.Sector
+	if query.Sector != "" {
+		if sector, err = s.sector(query.Sector); err != nil {
+			return IndustriesResult{}, err
+		}
+		if !slices.ContainsFunc(industries, func(i SectorIndustry) bool { return i.Sector != "" }) {
+			return IndustriesResult{}, fmt.Errorf("the industries can't be filtered by sector: %s", warning)
+		}
+		if warning != "" {
+			warning += ", those industries are left out"
+		}
+	}
+	if warning != "" {
+		result.Warnings = append(result.Warnings, warning)
+	}
+
+	for _, industry := range industries {
+		if sector.Name != "" && industry.Sector != sector.Name {
+			continue
+		}
+		// The P/E is 0 when the industry has none
+		if !inBounds(float64(industry.MarketCap), query.MinMarketCap, query.MaxMarketCap) ||
+			((query.MinPeRatio != 0 || query.MaxPeRatio != 0) && (industry.PeRatio == 0 || !inBounds(float64(industry.PeRatio), query.MinPeRatio, query.MaxPeRatio))) ||
+			!inBounds(float64(industry.OneYearChangePct), query.MinOneYearChange, query.MaxOneYearChange) {
+			continue
+		}
+		result.Industries = append(result.Industries, industry)
+	}
+
+	slices.SortStableFunc(result.Industries, func(a, b SectorIndustry) int {
+		if sort == "name" {
+			return orderBy(strings.Compare(a.Name, b.Name), query.Descending)
+		}
+		// The industries without a P/E are last
+		if sort == "pe_ratio" && (a.PeRatio == 0 || b.PeRatio == 0) {
+			return compareMissing(a.PeRatio != 0, b.PeRatio != 0)
+		}
+		return orderBy(cmp.Compare(industryValue(a.Industry), industryValue(b.Industry)), query.Descending)
+	})
+
+	result.Total = len(result.Industries)
+	result.Industries = result.Industries[:min(len(result.Industries), limit)]
+	return result, nil
+}
+
+func (s *IndustryService) GetIndustryStocks(query IndustryStocksQuery) (IndustryStocksResult, error) {
+	if query.Industry == "" {
+		return IndustryStocksResult{}, fmt.Errorf("url_name is required")
+	}
+	sort := cmp.Or(query.Sort, "market_cap")
+	switch sort {
+	case "market_cap", "symbol", "name":
+	default:
+		return IndustryStocksResult{}, fmt.Errorf("sort valid values are: market_cap, symbol, name")
+	}
+	limit := cmp.Or(query.Limit, defaultIndustryStocksLimit)
+	if limit < 1 {
+		return IndustryStocksResult{}, fmt.Errorf("limit must be positive")
+	}
+
+	// The stocks are still returned without the industry
+	var result IndustryStocksResult
+	industries, _, err := s.sectorIndustries()
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("failed to get the industry: %v", err))
+	}
+	index := slices.IndexFunc(industries, func(i SectorIndustry) bool { return strings.EqualFold(i.UrlName, query.Industry) })
+	if err == nil && index == -1 {
+		return IndustryStocksResult{}, fmt.Errorf("unknown industry %s, the url names of the industries are returned by getIndustries", query.Industry)
+	}
+	if index != -1 {
+		result.Industry = industries[index]
+	}
+	result.Industry.UrlName = cmp.Or(result.Industry.UrlName, query.Industry)
+
+	stocks, err := s.data.GetIndustryStocks(result.Industry.UrlName)
+	if err != nil {
+		return IndustryStocksResult{}, err
+	}
+	for _, stock := range stocks {
+		if inBounds(float64(stock.MarketCap), query.MinMarketCap, query.MaxMarketCap) {
+			result.Stocks = append(result.Stocks, stock)
+		}
+	}
+
+	slices.SortStableFunc(result.Stocks, func(a, b domain.IndustryStock) int {
+		switch sort {
+		case "symbol":
+			return orderBy(strings.Compare(a.Symbol, b.Symbol), query.Descending)
+		case "name":
+			return orderBy(strings.Compare(a.CompanyName, b.CompanyName), query.Descending)
+		default:
+			return orderBy(cmp.Compare(a.MarketCap, b.MarketCap), query.Descending)
+		}
+	})
+
+	result.Total = len(result.Stocks)
+	result.Stocks = result.Stocks[:min(len(result.Stocks), limit)]
+	return result, nil
+}
+
+var industrySortValues = map[string]func(i domain.Industry) float32{
+	"name":             func(i domain.Industry) float32 { return 0 },
+	"market_cap":       func(i domain.Industry) float32 { return i.MarketCap },
+	"pe_ratio":         func(i domain.Industry) float32 { return i.PeRatio },
+	"one_year_change":  func(i domain.Industry) float32 { return i.OneYearChangePct },
+	"number_of_stocks": func(i domain.Industry) float32 { return float32(i.NumberOfStocks) },
+	"dividend_yield":   func(i domain.Industry) float32 { return i.DividendYieldPct },
+	"profit_margin":    func(i domain.Industry) float32 { return i.ProfitMarginPct },
+}
+
+// sectorIndustries returns the industries with their sector, and the warning when their sectors are unknown
+func (s *IndustryService) sectorIndustries() ([]SectorIndustry, string, error) {
+	industries, err := s.data.GetIndustries()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var warning string
+	sectors, err := s.industrySectors()
+	if err != nil {
+		warning = err.Error()
+	}
+	result := make([]SectorIndustry, len(industries))
+	for i, industry := range industries {
+		result[i] = SectorIndustry{Industry: industry}
+		if sector, ok := sectors[industry.Name]; ok {
+			result[i].Sector, result[i].SectorUrlName = sector.Name, sector.UrlName
+		} else if warning == "" {
+			warning = "the sector of some industries is unknown"
+		}
+	}
+	return result, warning, nil
+}
+
+// industrySectors returns the sectors of the industries by name, the sector of most of their stocks
+func (s *IndustryService) industrySectors() (map[string]domain.Sector, error) {
+	stocks, err := s.data.GetScreenerStocks(domain.ScreenerRequest{Columns: []string{"sector", "industry"}, Sort: "symbol"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the sectors of the industries: %w", err)
+	}
+
+	counts := make(map[string]map[string]int)
+	for _, stock := range stocks {
+		industry, _ := stock.Values["industry"].(string)
+		sector, _ := stock.Values["sector"].(string)
+		if industry == "" || sector == "" {
+			continue
+		}
+		if counts[industry] == nil {
+			counts[industry] = make(map[string]int)
+		}
+		counts[industry][sector]++
+	}
+
+	// The url names of the sectors are optional
+	sectors, _ := s.data.GetSectors()
+	result := make(map[string]domain.Sector, len(counts))
+	for industry, sectorCounts := range counts {
+		var best string
+		for sector, count := range sectorCounts {
+			if count > sectorCounts[best] || (count == sectorCounts[best] && sector < best) {
+				best = sector
+			}
+		}
+		result[industry] = domain.Sector{Name: best}
+		if i := slices.IndexFunc(sectors, func(s domain.Sector) bool { return s.Name == best }); i != -1 {
+			result[industry] = sectors[i]
+		}
+	}
+	return result, nil
+}
+
+// sector returns the sector with the name or the url name
+func (s *IndustryService) sector(nameOrUrlName string) (domain.Sector, error) {
+	sectors, err := s.data.GetSectors()
+	if err != nil {
+		return domain.Sector{}, err
+	}
+	nameOrUrlName = strings.TrimSpace(nameOrUrlName)
+	for _, sector := range sectors {
+		if strings.EqualFold(sector.Name, nameOrUrlName) || strings.EqualFold(sector.UrlName, nameOrUrlName) {
+			return sector, nil
+		}
+	}
+	names := make([]string, len(sectors))
+	for i, sector := range sectors {
+		names[i] = sector.Name
+	}
+	return domain.Sector{}, fmt.Errorf("unknown sector %s, valid sectors are: %s", nameOrUrlName, strings.Join(names, ", "))
+}
+
+// inBounds returns whether the value is within the bounds, the zero bounds aren't applied
+func inBounds(value, lower, upper float64) bool {
+	return (lower == 0 || value >= lower) && (upper == 0 || value <= upper)
+}
+
+func orderBy(c int, descending bool) int {
+	if descending {
+		return -c
+	}
+	return c
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"testing"
+)
+
+type stubIndustryData struct {
+	stubScreener
+	screenerErr error
+}
+
+func (s *stubIndustryData) GetIndustries() ([]domain.Industry, error) {
+	return []domain.Industry{
+		{Name: "Semiconductors", UrlName: "semiconductors", MarketCap: 9e12, PeRatio: 40, OneYearChangePct: 30},
+		{Name: "Software - Application", UrlName: "software-application", MarketCap: 2.5e12, PeRatio: 90, OneYearChangePct: 10},
+		{Name: "Biotechnology", UrlName: "biotechnology", MarketCap: 1.4e12, OneYearChangePct: -5},
+		{Name: "Banks - Regional", UrlName: "banks-regional", MarketCap: 1.5e12, PeRatio: 14, OneYearChangePct: 20},
+	}, nil
+}
+
+func (s *stubIndustryData) GetIndustryStocks(industry string) ([]domain.IndustryStock, error) {
+	return []domain.IndustryStock{
+		{Symbol: "INTC", CompanyName: "Intel Corporation", MarketCap: 1.6e11},
+		{Symbol: "NVDA", CompanyName: "NVIDIA Corporation", MarketCap: 4.4e12},
+		{Symbol: "AMD", CompanyName: "Advanced Micro Devices, Inc.", MarketCap: 3.8e11},
+	}, nil
+}
+
+func (s *stubIndustryData) GetSectors() ([]domain.Sector, error) {
+	return []domain.Sector{{Name: "Technology", UrlName: "technology"}, {Name: "Financials", UrlName: "financials"}}, nil
+}
+
+func (s *stubIndustryData) GetScreenerStocks(request domain.ScreenerRequest) ([]domain.ScreenedStock, error) {
+	if s.screenerErr != nil {
+		return nil, s.screenerErr
+	}
+	return s.stubScreener.GetScreenerStocks(request)
+}
+
+func TestGetIndustries(t *testing.T) {
+	data := &stubIndustryData{stubScreener: stubScreener{stocks: []domain.ScreenedStock{
+		{Symbol: "NVDA", Values: map[string]any{"sector": "Technology", "industry": "Semiconductors"}},
+		{Symbol: "ADBE", Values: map[string]any{"sector": "Technology", "industry": "Software - Application"}},
+		{Symbol: "UBER", Values: map[string]any{"sector": "Industrials", "industry": "Software - Application"}},
+		{Symbol: "CRM", Values: map[string]any{"sector": "Technology", "industry": "Software - Application"}},
+		{Symbol: "FITB", Values: map[string]any{"sector": "Financials", "industry": "Banks - Regional"}},
+	}}}
+	s, _ := NewIndustryService(data)
+
+	result, err := s.GetIndustries(IndustriesQuery{Sector: "technology", Descending: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 || result.Industries[0].UrlName != "semiconductors" || result.Industries[1].SectorUrlName != "technology" || len(result.Warnings) != 1 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	// Biotechnology has no P/E
+	result, _ = s.GetIndustries(IndustriesQuery{MinPeRatio: 10, MaxPeRatio: 50, Sort: "one_year_change"})
+	if result.Total != 2 || result.Industries[0].UrlName != "banks-regional" || result.Industries[0].Sector != "Financials" {
+		t.Errorf("unexpected industries %+v", result.Industries)
+	}
+	result, _ = s.GetIndustries(IndustriesQuery{Sort: "pe_ratio", Descending: true, Limit: 4})
+	if result.Industries[0].UrlName != "software-application" || result.Industries[3].UrlName != "biotechnology" {
+		t.Errorf("expected the industries by P/E, the one without last, got %+v", result.Industries)
+	}
+
+	for _, invalid := range []IndustriesQuery{{Sector: "unknown"}, {Sort: "unknown"}, {Limit: -1}} {
+		if _, err := s.GetIndustries(invalid); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+
+	data.screenerErr = fmt.Errorf("rate limited")
+	if result, err := s.GetIndustries(IndustriesQuery{}); err != nil || result.Total != 4 || len(result.Warnings) != 1 {
+		t.Errorf("expected the industries without sectors, got %+v, %v", result, err)
+	}
+	if _, err := s.GetIndustries(IndustriesQuery{Sector: "Technology"}); err == nil {
+		t.Error("expected an error when filtering by sector without the sectors")
+	}
+}
+
+func TestGetIndustryStocks(t *testing.T) {
+	data := &stubIndustryData{stubScreener: stubScreener{stocks: []domain.ScreenedStock{
+		{Symbol: "NVDA", Values: map[string]any{"sector": "Technology", "industry": "Semiconductors"}},
+	}}}
+	s, _ := NewIndustryService(data)
+
+	result, err := s.GetIndustryStocks(IndustryStocksQuery{Industry: "semiconductors", MinMarketCap: 2e11, Descending: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Industry.Sector != "Technology" || result.Total != 2 || result.Stocks[0].Symbol != "NVDA" || result.Stocks[1].Symbol != "AMD" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	result, _ = s.GetIndustryStocks(IndustryStocksQuery{Industry: "semiconductors", Sort: "symbol", Limit: 1})
+	if result.Total != 3 || len(result.Stocks) != 1 || result.Stocks[0].Symbol != "AMD" {
+		t.Errorf("unexpected stocks %+v", result.Stocks)
+	}
+
+	for _, invalid := range []IndustryStocksQuery{{}, {Industry: "unknown"}, {Industry: "semiconductors", Sort: "pe"}} {
+		if _, err := s.GetIndustryStocks(invalid); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"slices"
+	"time"
+)
+
+// quantityEpsilon absorbs the rounding errors of the quantities, e.g. selling 0.3 after buying 0.1 three times
+const quantityEpsilon = 1e-9
+
+// transactionOrder orders the transactions of the same day: the cash comes in first and goes out last
+var transactionOrder = map[domain.TransactionType]int{
+	domain.TransactionDeposit:    0,
+	domain.TransactionSplit:      1,
+	domain.TransactionBuy:        2,
+	domain.TransactionDividend:   3,
+	domain.TransactionSell:       4,
+	domain.TransactionFee:        5,
+	domain.TransactionWithdrawal: 6,
+}
+
+// sortTransactions sorts the transactions in the order they apply: by date, then by type and by id
+func sortTransactions(transactions []domain.Transaction) {
+	slices.SortStableFunc(transactions, func(a, b domain.Transaction) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(transactionOrder[a.Type], transactionOrder[b.Type]), cmp.Compare(a.ID, b.ID))
+	})
+}
+
+// cashFlow is an external cash flow of a portfolio, positive when money comes in
+type cashFlow struct {
+	date   time.Time
+	amount float64
+}
+
+// ledgerState is the state of a portfolio after the replay of its ledger
+type ledgerState struct {
+	positions map[string]*domain.Position // By symbol
+	symbols   []string                    // In the order of the first transaction
+	cash      float64
+	// The deposits and withdrawals, and the implicit deposits funding the transactions that the cash of the
+	// ledger doesn't cover (most ledgers only record the trades, not the deposits)
+	flows     []cashFlow
+	realized  float64
+	dividends float64
+	fees      float64
+}
+
+// replayLedger replays the sorted transactions up to the end of the until day (all of them when zero), the amounts
+// being converted with convert. The lots are consumed according to the cost basis method.
+func replayLedger(transactions []domain.Transaction, method domain.CostBasisMethod, convert func(amount float64, currency domain.Currency) float64, until time.Time) (*ledgerState, error) {
+	state := &ledgerState{positions: make(map[string]*domain.Position)}
+
+	position := func(t domain.Transaction) *domain.Position {
+		p, ok := state.positions[t.Symbol]
+		if !ok {
+			p = &domain.Position{Symbol: t.Symbol, AssetClass: t.AssetClass}
+			state.positions[t.Symbol] = p
+			state.symbols = append(state.symbols, t.Symbol)
+		}
+		if p.AssetClass == "" {
+			p.AssetClass = t.AssetClass
+		}
+		return p
+	}
+
+	for _, t := range transactions {
+		if !until.IsZero() && t.Date.After(until) {
+			break
+		}
+		date := t.Date.Format(time.DateOnly)
+
+		switch t.Type {
+		case domain.TransactionBuy:
+			p := position(t)
+			cost := convert(t.Quantity*t.Price+t.Fees, t.Currency)
+			p.Quantity += t.Quantity
+			p.CostBasis += cost
+			if method == domain.CostBasisAverage && len(p.Lots) > 0 {
+				p.Lots[0].Quantity += t.Quantity
+				p.Lots[0].UnitCost = p.CostBasis / p.Lots[0].Quantity
+			} else {
+				p.Lots = append(p.Lots, domain.Lot{Date: t.Date, Quantity: t.Quantity, UnitCost: cost / t.Quantity})
+			}
+			state.spend(t.Date, cost)
+
+		case domain.TransactionSell:
+			p, ok := state.positions[t.Symbol]
+			if !ok || p.Quantity+quantityEpsilon < t.Quantity {
+				held := 0.0
+				if ok {
+					held = p.Quantity
+				}
+				return nil, fmt.Errorf("the sale of %v %s on %s exceeds the %v held", t.Quantity, t.Symbol, date, held)
+			}
+			proceeds := convert(t.Quantity*t.Price-t.Fees, t.Currency)
+			cost := consumeLots(p, t.Quantity)
+			p.RealizedPnL += proceeds - cost
+			state.realized += proceeds - cost
+			state.cash += proceeds
+
+		case domain.TransactionDividend:
+			p := position(t)
+			amount := convert(t.Amount, t.Currency)
+			p.Dividends += amount
+			state.dividends += amount
+			state.cash += amount
+
+		case domain.TransactionSplit:
+			p, ok := state.positions[t.Symbol]
+			if !ok || p.Quantity == 0 {
+				return nil, fmt.Errorf("the split of %s on %s applies to no position", t.Symbol, date)
+			}
+			p.Quantity *= t.SplitRatio
+			for i := range p.Lots {
+				p.Lots[i].Quantity *= t.SplitRatio
+				p.Lots[i].UnitCost /= t.SplitRatio
+			}
+
+		case domain.TransactionDeposit:
+			amount := convert(t.Amount, t.Currency)
+			state.cash += amount
+			state.flows = append(state.flows, cashFlow{date: t.Date, amount: amount})
+
+		case domain.TransactionWithdrawal:
+			amount := convert(t.Amount, t.Currency)
+			if state.cash+quantityEpsilon < amount {
+				return nil, fmt.Errorf("the withdrawal of %v %s on %s exceeds the cash of the ledger", t.Amount, t.Currency, date)
+			}
+			state.cash -= amount
+			state.flows = append(state.flows, cashFlow{date: t.Date, amount: -amount})
+
+		case domain.TransactionFee:
+			amount := convert(t.Amount, t.Currency)
+			if t.Symbol != "" {
+				position(t).Fees += amount
+			}
+			state.fees += amount
+			state.spend(t.Date, amount)
+		}
+	}
+
+	return state, nil
+}
+
+// spend takes the amount from the cash, the part that the cash doesn't cover is an implicit deposit
+func (s *ledgerState) spend(date time.Time, amount float64) {
+	s.cash -= amount
+	if s.cash < 0 {
+		s.flows = append(s.flows, cashFlow{date: date, amount: -s.cash})
+		s.cash = 0
+	}
+}
+
+// consumeLots removes the quantity from the lots of the position, the oldest first, and returns the cost of the
+// removed quantity. With the average cost method the position has a single lot.
+func consumeLots(p *domain.Position, quantity float64) float64 {
+	var cost float64
+	remaining := quantity
+	for len(p.Lots) > 0 && remaining > quantityEpsilon {
+		lot := &p.Lots[0]
+		sold := math.Min(lot.Quantity, remaining)
+		cost += sold * lot.UnitCost
+		lot.Quantity -= sold
+		remaining -= sold
+		if lot.Quantity <= quantityEpsilon {
+			p.Lots = p.Lots[1:]
+		}
+	}
+
+	p.Quantity -= quantity
+	if p.Quantity <= quantityEpsilon {
+		p.Quantity, p.Lots = 0, nil
+	}
+	p.CostBasis = 0
+	for _, lot := range p.Lots {
+		p.CostBasis += lot.Quantity * lot.UnitCost
+	}
+	return cost
+}
+
+// netDeposits returns the deposits minus the withdrawals
+func (s *ledgerState) netDeposits() float64 {
+	var net float64
+	for _, flow := range s.flows {
+		net += flow.amount
+	}
+	return net
+}
+
+// xirr returns the annualized internal rate of return of the cash flows of an investor (negative when money goes
+// in), false when there is none: the flows must change sign.
+func xirr(flows []cashFlow) (float64, bool) {
+	if len(flows) < 2 {
+		return 0, false
+	}
+	start := flows[0].date
+	for _, flow := range flows {
+		if flow.date.Before(start) {
+			start = flow.date
+		}
+	}
+
+	npv := func(rate float64) float64 {
+		var value float64
+		for _, flow := range flows {
+			years := flow.date.Sub(start).Hours() / 24 / 365
+			value += flow.amount / math.Pow(1+rate, years)
+		}
+		return value
+	}
+
+	// The npv decreases with the rate when the deposits precede the withdrawals, a bisection finds its root
+	low, high := -0.9999, 100.0
+	npvLow, npvHigh := npv(low), npv(high)
+	if math.IsNaN(npvLow) || math.IsNaN(npvHigh) || npvLow*npvHigh > 0 {
+		return 0, false
+	}
+	for range 200 {
+		mid := (low + high) / 2
+		npvMid := npv(mid)
+		if npvMid == 0 || high-low < 1e-12 {
+			return mid, true
+		}
+		if npvMid*npvLow < 0 {
+			high = mid
+		} else {
+			low, npvLow = mid, npvMid
+		}
+	}
+	return (low + high) / 2, true
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+func identity(amount float64, _ domain.Currency) float64 {
+	return amount
+}
+
+func ledgerDay(d int) time.Time {
+	return time.Date(2025, time.January, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestReplayLedgerCostBasis(t *testing.T) {
+	transactions := []domain.Transaction{
+		{ID: 1, Type: domain.TransactionBuy, Date: ledgerDay(2), AssetClass: domain.Stock, Symbol: "AAPL", Quantity: 10, Price: 100},
+		{ID: 2, Type: domain.TransactionBuy, Date: ledgerDay(3), AssetClass: domain.Stock, Symbol: "AAPL", Quantity: 10, Price: 200},
+		{ID: 3, Type: domain.TransactionSell, Date: ledgerDay(4), Symbol: "AAPL", Quantity: 10, Price: 300},
+	}
+
+	tests := []struct {
+		method    domain.CostBasisMethod
+		realized  float64
+		costBasis float64
+		lots      int
+	}{
+		{method: domain.CostBasisFIFO, realized: 2000, costBasis: 2000, lots: 1},
+		{method: domain.CostBasisAverage, realized: 1500, costBasis: 1500, lots: 1},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			state, err := replayLedger(transactions, tt.method, identity, time.Time{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := state.positions["AAPL"]
+			if p.Quantity != 10 || len(p.Lots) != tt.lots {
+				t.Errorf("expected 10 held in %d lots, got %v in %d", tt.lots, p.Quantity, len(p.Lots))
+			}
+			if math.Abs(state.realized-tt.realized) > 1e-9 || math.Abs(p.CostBasis-tt.costBasis) > 1e-9 {
+				t.Errorf("expected realized %v and cost basis %v, got %v and %v", tt.realized, tt.costBasis, state.realized, p.CostBasis)
+			}
+			// Without deposits the buys are funded by implicit deposits
+			if state.netDeposits() != 3000 || state.cash != 3000 {
+				t.Errorf("expected net deposits and cash of 3000, got %v and %v", state.netDeposits(), state.cash)
+			}
+		})
+	}
+}
+
+func TestReplayLedgerSplitAndCash(t *testing.T) {
+	transactions := []domain.Transaction{
+		{ID: 1, Type: domain.TransactionDeposit, Date: ledgerDay(2), Amount: 5000},
+		{ID: 2, Type: domain.TransactionBuy, Date: ledgerDay(2), AssetClass: domain.Stock, Symbol: "NVDA", Quantity: 10, Price: 400, Fees: 10},
+		{ID: 3, Type: domain.TransactionSplit, Date: ledgerDay(5), Symbol: "NVDA", SplitRatio: 4},
+		{ID: 4, Type: domain.TransactionDividend, Date: ledgerDay(6), Symbol: "NVDA", Amount: 20},
+		{ID: 5, Type: domain.TransactionFee, Date: ledgerDay(7), Amount: 5},
+	}
+
+	state, err := replayLedger(transactions, domain.CostBasisFIFO, identity, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := state.positions["NVDA"]
+	if p.Quantity != 40 || p.CostBasis != 4010 || math.Abs(p.Lots[0].UnitCost-100.25) > 1e-9 {
+		t.Errorf("expected 40 held at 100.25, got %v at %v", p.Quantity, p.Lots[0].UnitCost)
+	}
+	if state.cash != 1005 || state.netDeposits() != 5000 || state.dividends != 20 || state.fees != 5 {
+		t.Errorf("unexpected cash %v, net deposits %v, dividends %v or fees %v", state.cash, state.netDeposits(), state.dividends, state.fees)
+	}
+
+	// Until the day of the split, before it applies to the position
+	state, err = replayLedger(transactions, domain.CostBasisFIFO, identity, ledgerDay(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.positions["NVDA"].Quantity != 10 {
+		t.Errorf("expected 10 held before the split, got %v", state.positions["NVDA"].Quantity)
+	}
+}
+
+func TestReplayLedgerInconsistencies(t *testing.T) {
+	tests := map[string][]domain.Transaction{
+		"oversold": {
+			{ID: 1, Type: domain.TransactionBuy, Date: ledgerDay(2), Symbol: "AAPL", Quantity: 1, Price: 100},
+			{ID: 2, Type: domain.TransactionSell, Date: ledgerDay(3), Symbol: "AAPL", Quantity: 2, Price: 100},
+		},
+		"split without position": {
+			{ID: 1, Type: domain.TransactionSplit, Date: ledgerDay(2), Symbol: "AAPL", SplitRatio: 2},
+		},
+		"withdrawal exceeding cash": {
+			{ID: 1, Type: domain.TransactionDeposit, Date: ledgerDay(2), Amount: 100},
+			{ID: 2, Type: domain.TransactionWithdrawal, Date: ledgerDay(3), Amount: 200},
+		},
+	}
+	for name, transactions := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := replayLedger(transactions, domain.CostBasisFIFO, identity, time.Time{}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestTransactionLedgerRejectsInconsistentChanges(t *testing.T) {
+	s, _ := NewTransactionLedgerLocalDataService("")
+
+	buy, err := s.AddTransaction(domain.Transaction{UserID: "u", Type: domain.TransactionBuy, Date: ledgerDay(2), Symbol: "aapl", Quantity: 5, Price: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buy.ID != 1 || buy.Symbol != "AAPL" || buy.Currency != domain.USD || buy.AssetClass != domain.Stock {
+		t.Errorf("unexpected normalized transaction %+v", buy)
+	}
+	if _, err := s.AddTransaction(domain.Transaction{UserID: "u", Type: domain.TransactionSell, Date: ledgerDay(3), Symbol: "AAPL", Quantity: 5, Price: 110}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddTransaction(domain.Transaction{UserID: "u", Type: domain.TransactionSell, Date: ledgerDay(4), Symbol: "AAPL", Quantity: 1, Price: 110}); err == nil {
+		t.Error("expected the sale of a closed position to be rejected")
+	}
+	if _, err := s.DeleteTransaction("u", buy.ID); err == nil {
+		t.Error("expected the deletion of the buy of a sold position to be rejected")
+	}
+
+	transactions, _ := s.GetTransactions("u")
+	if len(transactions) != 2 {
+		t.Errorf("expected 2 transactions, got %d", len(transactions))
+	}
+}
+
+func TestTransactionLedgerChecksCashPerCurrency(t *testing.T) {
+	s, _ := NewTransactionLedgerLocalDataService("")
+	add := func(d int, typ domain.TransactionType, amount float64, currency domain.Currency) error {
+		_, err := s.AddTransaction(domain.Transaction{UserID: "u", Type: typ, Date: ledgerDay(d), Amount: amount, Currency: currency})
+		return err
+	}
+
+	if err := add(2, domain.TransactionDeposit, 1000, domain.USD); err != nil {
+		t.Fatal(err)
+	}
+	// The dollars don't cover a withdrawal in euros, whatever the exchange rate
+	if err := add(3, domain.TransactionWithdrawal, 500, domain.EUR); err == nil {
+		t.Error("expected the withdrawal of euros never deposited to be rejected")
+	}
+
+	if err := add(3, domain.TransactionDeposit, 600, domain.EUR); err != nil {
+		t.Fatal(err)
+	}
+	if err := add(4, domain.TransactionWithdrawal, 500, domain.EUR); err != nil {
+		t.Errorf("expected the withdrawal covered by the euros to be accepted, got %v", err)
+	}
+	// The euros left don't count for a withdrawal in dollars
+	if err := add(5, domain.TransactionWithdrawal, 1050, domain.USD); err == nil {
+		t.Error("expected the withdrawal over the dollars to be rejected")
+	}
+	if err := add(5, domain.TransactionWithdrawal, 1000, domain.USD); err != nil {
+		t.Errorf("expected the withdrawal of the dollars to be accepted, got %v", err)
+	}
+}
+
+func TestXirr(t *testing.T) {
+	rate, ok := xirr([]cashFlow{
+		{date: ledgerDay(1), amount: -100},
+		{date: ledgerDay(1).AddDate(0, 0, 365), amount: 110},
+	})
+	if !ok || math.Abs(rate-0.1) > 1e-6 {
+		t.Errorf("expected a rate of 10%%, got %v (%v)", rate, ok)
+	}
+
+	if _, ok := xirr([]cashFlow{{date: ledgerDay(1), amount: -100}, {date: ledgerDay(2), amount: -100}}); ok {
+		t.Error("expected no rate for flows that don't change sign")
+	}
+}
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomically writes the data to a temporary file renamed over the file, so that the file is never half
+// written (e.g. when the server is stopped in the middle of a write)
+func writeFileAtomically(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
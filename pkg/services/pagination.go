@@ -0,0 +1,87 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"market_data_mcp_server/pkg/errors"
+	"time"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+
+	// searchIndexTtl is how often the search indexes are rebuilt from the (cached) data services
+	searchIndexTtl = 10 * time.Minute
+)
+
+// Page is a page of results along with the total number of results and the cursor of the next page.
+// NextCursor is empty when there are no more results.
+type Page[T any] struct {
+	Items      []T
+	TotalCount int
+	NextCursor string
+}
+
+// cursor is the decoded form of the opaque cursors we hand out. It is bound to the query
+// that produced it so that a cursor can't be used to page through the results of another query.
+type cursor struct {
+	Offset int    `json:"o"`
+	Query  string `json:"q"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor{}, errors.InvalidCursorError{Cursor: encoded}
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset < 0 {
+		return cursor{}, errors.InvalidCursorError{Cursor: encoded}
+	}
+
+	return c, nil
+}
+
+// paginate returns the page of the (already filtered and sorted) items that starts at the given cursor.
+// A limit <= 0 falls back to DefaultPageSize and any limit above MaxPageSize is capped.
+func paginate[T any](items []T, query string, encodedCursor string, limit int) (Page[T], error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	offset := 0
+	if encodedCursor != "" {
+		c, err := decodeCursor(encodedCursor)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		if c.Query != query {
+			return Page[T]{}, errors.InvalidCursorError{Cursor: encodedCursor}
+		}
+		offset = c.Offset
+	}
+
+	page := Page[T]{TotalCount: len(items)}
+	if offset >= len(items) {
+		page.Items = make([]T, 0)
+		return page, nil
+	}
+
+	end := min(offset+limit, len(items))
+	page.Items = items[offset:end]
+	if end < len(items) {
+		page.NextCursor = encodeCursor(cursor{Offset: end, Query: query})
+	}
+
+	return page, nil
+}
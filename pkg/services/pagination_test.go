@@ -0,0 +1,101 @@
+package services
+
+import (
+	"encoding/base64"
+	goerrors "errors"
+	"market_data_mcp_server/pkg/errors"
+	"testing"
+)
+
+func numbers(n int) []int {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+	return items
+}
+
+func TestPaginateLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		expected int
+	}{
+		{name: "default", limit: 0, expected: DefaultPageSize},
+		{name: "negative", limit: -5, expected: DefaultPageSize},
+		{name: "given", limit: 20, expected: 20},
+		{name: "capped", limit: 500, expected: MaxPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := paginate(numbers(250), "", "", tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page.Items) != tt.expected || page.TotalCount != 250 || page.NextCursor == "" {
+				t.Errorf("expected %d items of 250 and a next cursor, got %d of %d (%q)", tt.expected, len(page.Items), page.TotalCount, page.NextCursor)
+			}
+		})
+	}
+}
+
+func TestPaginateCursors(t *testing.T) {
+	items := numbers(25)
+
+	first, err := paginate(items, "app", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := paginate(items, "app", first.NextCursor, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Items[0] != 10 || second.NextCursor == "" {
+		t.Errorf("expected the second page to start at 10, got %+v", second)
+	}
+
+	// The last page has no next cursor
+	last, err := paginate(items, "app", second.NextCursor, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(last.Items) != 5 || last.Items[0] != 20 || last.NextCursor != "" {
+		t.Errorf("expected the last 5 items without a next cursor, got %+v", last)
+	}
+
+	// A cursor past the items, e.g. after the items changed, gives an empty page
+	past := encodeCursor(cursor{Offset: 30, Query: "app"})
+	if page, err := paginate(items, "app", past, 10); err != nil || page.Items == nil || len(page.Items) != 0 || page.NextCursor != "" || page.TotalCount != 25 {
+		t.Errorf("expected an empty final page, got %+v, %v", page, err)
+	}
+
+	// An exact multiple of the limit ends with a page without a next cursor
+	if page, _ := paginate(numbers(20), "", encodeCursor(cursor{Offset: 10}), 10); len(page.Items) != 10 || page.NextCursor != "" {
+		t.Errorf("expected no next cursor on the last page, got %+v", page)
+	}
+}
+
+func TestPaginateInvalidCursors(t *testing.T) {
+	items := numbers(25)
+	first, _ := paginate(items, "app", "", 10)
+
+	tests := []struct {
+		name   string
+		query  string
+		cursor string
+	}{
+		{name: "other query", query: "meta", cursor: first.NextCursor},
+		{name: "malformed base64", query: "app", cursor: "not a cursor!"},
+		{name: "malformed json", query: "app", cursor: base64.RawURLEncoding.EncodeToString([]byte("{\"o\":"))},
+		{name: "negative offset", query: "app", cursor: encodeCursor(cursor{Offset: -10, Query: "app"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := paginate(items, tt.query, tt.cursor, 10); !goerrors.As(err, &errors.InvalidCursorError{}) {
+				t.Errorf("expected an InvalidCursorError, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPeers = 10
+	maxPeers     = 25
+)
+
+type PeerComparisonDataService interface {
+	GetStockProfile(symbol string) (domain.StockProfile, error)
+	GetIndustries() ([]domain.Industry, error)
+	GetIndustryStocks(industry string) ([]domain.IndustryStock, error)
+	GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error)
+}
+
+// peerMetrics are the names of the comparison metrics of the peer comparisons, in their order
+var peerMetrics = []string{
+	"pe", "ps", "pb", "pfcf", "ev_ebitda", "ev_revenue", "fcf_yield", "dividend_yield",
+	"roe", "roa", "roic", "asset_turnover",
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+// PeerComparisonService compares a company with the companies of its industry nearest by market cap
+type PeerComparisonService struct {
+	data PeerComparisonDataService
+}
+
+func NewPeerComparisonService(data PeerComparisonDataService) (*PeerComparisonService, error) {
+	return &PeerComparisonService{
+		data: data,
+	}, nil
+}
+
+// ComparePeers returns the percentiles of the company against its peers, the count stocks of its industry nearest
+// by market cap, on the valuation and profitability ratios of the trailing twelve months. The ratios of the peers
+// are fetched concurrently and the peers whose ratios can't be fetched are reported.
+func (s *PeerComparisonService) ComparePeers(symbol string, count int) (domain.PeerComparison, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return domain.PeerComparison{}, fmt.Errorf("symbol is required")
+	}
+	count = cmp.Or(count, defaultPeers)
+	if count < 1 || count > maxPeers {
+		return domain.PeerComparison{}, fmt.Errorf("peers must be between 1 and %d", maxPeers)
+	}
+
+	// Lowercase like the other tools, so that they share the cached data
+	profile, err := s.data.GetStockProfile(strings.ToLower(symbol))
+	if err != nil {
+		return domain.PeerComparison{}, fmt.Errorf("failed to get the profile of %s: %w", symbol, err)
+	}
+	if profile.Industry == "" {
+		return domain.PeerComparison{}, fmt.Errorf("the industry of %s is unknown", symbol)
+	}
+	ratios, err := s.data.GetFinancialRatios(strings.ToLower(symbol))
+	if err != nil {
+		return domain.PeerComparison{}, fmt.Errorf("failed to get the financial ratios of %s: %w", symbol, err)
+	}
+
+	comparison := domain.PeerComparison{
+		Symbol:      symbol,
+		CompanyName: profile.Name,
+		Industry:    profile.Industry,
+		Sector:      profile.Sector,
+	}
+	comparison.IndustryUrlName, err = s.industryUrlName(profile.Industry)
+	if err != nil {
+		comparison.Warnings = append(comparison.Warnings, err.Error())
+	}
+
+	stocks, err := s.data.GetIndustryStocks(comparison.IndustryUrlName)
+	if err != nil {
+		return domain.PeerComparison{}, fmt.Errorf("failed to get the stocks of the industry %s: %w", profile.Industry, err)
+	}
+
+	// The market cap of the industry page is the current one, the one of the ratios the fallback
+	var target comparisonData
+	if len(ratios) > 0 {
+		// The first ratios are the trailing twelve months ones
+		target.ratios = &ratios[0]
+		comparison.MarketCap = ratios[0].Marketcap
+	}
+	var candidates []domain.Peer
+	for _, stock := range stocks {
+		if strings.EqualFold(stock.Symbol, symbol) {
+			comparison.MarketCap = cmp.Or(float64(stock.MarketCap), comparison.MarketCap)
+		} else if stock.MarketCap > 0 {
+			candidates = append(candidates, domain.Peer{Symbol: strings.ToUpper(stock.Symbol), CompanyName: stock.CompanyName, MarketCap: float64(stock.MarketCap)})
+		}
+	}
+	if comparison.MarketCap <= 0 {
+		return domain.PeerComparison{}, fmt.Errorf("the market cap of %s is unknown", symbol)
+	}
+	if len(candidates) == 0 {
+		return domain.PeerComparison{}, fmt.Errorf("the industry %s has no other stocks", profile.Industry)
+	}
+
+	// The nearest by the ratio of the market caps, so that a company twice as big is as near as one half as big
+	distance := func(peer domain.Peer) float64 { return math.Abs(math.Log(peer.MarketCap / comparison.MarketCap)) }
+	slices.SortStableFunc(candidates, func(a, b domain.Peer) int { return cmp.Compare(distance(a), distance(b)) })
+	comparison.Peers = candidates[:min(len(candidates), count)]
+
+	peers := make([]comparisonData, len(comparison.Peers))
+	errs := make([]error, len(comparison.Peers))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrentPriceRequests)
+	for i, peer := range comparison.Peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			ratios, err := s.data.GetFinancialRatios(strings.ToLower(peer.Symbol))
+			if err != nil {
+				errs[i] = err
+			} else if len(ratios) > 0 {
+				peers[i].ratios = &ratios[0]
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			comparison.Errors = append(comparison.Errors, domain.ComparisonError{Symbol: comparison.Peers[i].Symbol, Error: fmt.Sprintf("failed to get the financial ratios: %v", err)})
+		}
+	}
+
+	for _, name := range peerMetrics {
+		i := slices.IndexFunc(comparisonMetrics, func(m comparisonMetric) bool { return m.name == name })
+		comparison.Metrics = append(comparison.Metrics, comparePeerMetric(comparisonMetrics[i], target, peers))
+	}
+	comparison.Sets = setPercentiles(comparison.Metrics)
+
+	return comparison, nil
+}
+
+// industryUrlName returns the url name of the industry with the name, or the name as a url name when it can't be found
+func (s *PeerComparisonService) industryUrlName(name string) (string, error) {
+	slug := strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	industries, err := s.data.GetIndustries()
+	if err != nil {
+		return slug, fmt.Errorf("failed to get the industries, the url name of %s is guessed: %v", name, err)
+	}
+	for _, industry := range industries {
+		if strings.EqualFold(industry.Name, name) {
+			return industry.UrlName, nil
+		}
+	}
+	return slug, fmt.Errorf("unknown industry %s, its url name is guessed", name)
+}
+
+// comparePeerMetric returns the metric of the company, with its percentile against the peers and their median
+func comparePeerMetric(metric comparisonMetric, target comparisonData, peers []comparisonData) domain.PeerMetric {
+	compared := domain.PeerMetric{
+		Name:           metric.name,
+		Set:            metric.set,
+		Unit:           metric.unit,
+		HigherIsBetter: metric.higherIsBetter,
+	}
+	meaningful := func(value float64) bool { return metric.ranked == nil || metric.ranked(value) }
+
+	var values []float64
+	for _, peer := range peers {
+		if value, ok := metric.value(peer, time.Time{}); ok && meaningful(value) {
+			values = append(values, value)
+		}
+	}
+	compared.PeerCount = len(values)
+	if len(values) > 0 {
+		slices.Sort(values)
+		median := values[len(values)/2]
+		if len(values)%2 == 0 {
+			median = (values[len(values)/2-1] + values[len(values)/2]) / 2
+		}
+		compared.PeerMedian = &median
+	}
+
+	value, ok := metric.value(target, time.Time{})
+	if !ok {
+		return compared
+	}
+	compared.Value = &value
+	if !meaningful(value) || len(values) == 0 {
+		return compared
+	}
+
+	var worse float64
+	for _, other := range values {
+		switch {
+		case other == value:
+			worse += 0.5
+		case (metric.higherIsBetter && other < value) || (!metric.higherIsBetter && other > value):
+			worse++
+		}
+	}
+	percentile := worse / float64(len(values)) * 100
+	compared.Percentile = &percentile
+	if *compared.PeerMedian > 0 {
+		vsMedian := (value / *compared.PeerMedian - 1) * 100
+		compared.VsMedianPct = &vsMedian
+	}
+	return compared
+}
+
+// setPercentiles returns the average percentiles of the sets of the metrics, in the order of the metrics
+func setPercentiles(metrics []domain.PeerMetric) []domain.SetPercentile {
+	var sets []domain.SetPercentile
+	for _, metric := range metrics {
+		if metric.Percentile == nil {
+			continue
+		}
+		i := slices.IndexFunc(sets, func(s domain.SetPercentile) bool { return s.Set == metric.Set })
+		if i == -1 {
+			sets = append(sets, domain.SetPercentile{Set: metric.Set})
+			i = len(sets) - 1
+		}
+		sets[i].Percentile += *metric.Percentile
+		sets[i].Metrics++
+	}
+	for i := range sets {
+		sets[i].Percentile /= float64(sets[i].Metrics)
+	}
+	return sets
+}
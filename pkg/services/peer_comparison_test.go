@@ -0,0 +1,93 @@
+package services
+
+import (
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"testing"
+)
+
+type stubPeerData struct {
+	stubComparisonData
+	industry string
+}
+
+func (s stubPeerData) GetStockProfile(symbol string) (domain.StockProfile, error) {
+	return domain.StockProfile{Name: "NVIDIA Corporation", Industry: s.industry, Sector: "Technology"}, nil
+}
+
+func (s stubPeerData) GetIndustries() ([]domain.Industry, error) {
+	return []domain.Industry{{Name: "Semiconductors", UrlName: "semiconductors"}}, nil
+}
+
+func (s stubPeerData) GetIndustryStocks(industry string) ([]domain.IndustryStock, error) {
+	return []domain.IndustryStock{
+		{Symbol: "QCOM", MarketCap: 1.8e11},
+		{Symbol: "NVDA", MarketCap: 4.4e12},
+		{Symbol: "AMD", MarketCap: 3.8e11},
+		{Symbol: "TSM", MarketCap: 1.2e12},
+		{Symbol: "SHELL", MarketCap: 0},
+		{Symbol: "AVGO", MarketCap: 1.6e12},
+	}, nil
+}
+
+func TestComparePeers(t *testing.T) {
+	data := stubPeerData{
+		stubComparisonData: stubComparisonData{
+			"nvda": {Pe: 50, Roe: 1},
+			"avgo": {Pe: 80, Roe: 0.2},
+			"tsm":  {Pe: 25, Roe: 0.3},
+		},
+		industry: "Semiconductors",
+	}
+	s, _ := NewPeerComparisonService(data)
+
+	comparison, err := s.ComparePeers("nvda", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comparison.IndustryUrlName != "semiconductors" || comparison.MarketCap != float64(float32(4.4e12)) || len(comparison.Warnings) != 0 {
+		t.Errorf("unexpected comparison %+v", comparison)
+	}
+	if len(comparison.Peers) != 3 || comparison.Peers[0].Symbol != "AVGO" || comparison.Peers[1].Symbol != "TSM" || comparison.Peers[2].Symbol != "AMD" {
+		t.Errorf("expected the nearest peers by market cap, got %+v", comparison.Peers)
+	}
+	if len(comparison.Errors) != 1 || comparison.Errors[0].Symbol != "AMD" {
+		t.Errorf("expected an error for AMD, got %+v", comparison.Errors)
+	}
+	if len(comparison.Metrics) != len(peerMetrics) {
+		t.Fatalf("expected %d metrics, got %d", len(peerMetrics), len(comparison.Metrics))
+	}
+
+	pe, roe := comparison.Metrics[0], comparison.Metrics[8]
+	if pe.Name != "pe" || *pe.PeerMedian != 52.5 || pe.PeerCount != 2 || *pe.Percentile != 50 || math.Abs(*pe.VsMedianPct+4.76) > 0.01 {
+		t.Errorf("unexpected pe %+v", pe)
+	}
+	if roe.Name != "roe" || *roe.Value != 100 || *roe.Percentile != 100 {
+		t.Errorf("unexpected roe %+v", roe)
+	}
+	// The zero ratios are equal, so half of the peers are worse
+	if len(comparison.Sets) != 2 || comparison.Sets[1].Set != domain.ProfitabilityMetrics || comparison.Sets[1].Percentile != 62.5 {
+		t.Errorf("unexpected sets %+v", comparison.Sets)
+	}
+
+	data.industry = "Semiconductor Equipment & Materials"
+	s, _ = NewPeerComparisonService(data)
+	if comparison, err := s.ComparePeers("NVDA", 0); err != nil || comparison.IndustryUrlName != "semiconductor-equipment-materials" || len(comparison.Warnings) != 1 || len(comparison.Peers) != 4 {
+		t.Errorf("expected the guessed industry, got %+v, %v", comparison, err)
+	}
+
+	for _, invalid := range []struct {
+		symbol string
+		count  int
+	}{{"", 0}, {"NVDA", -1}, {"NVDA", maxPeers + 1}, {"GOOGL", 0}} {
+		if _, err := s.ComparePeers(invalid.symbol, invalid.count); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+
+	data.industry = ""
+	s, _ = NewPeerComparisonService(data)
+	if _, err := s.ComparePeers("NVDA", 0); err == nil {
+		t.Error("expected an error without the industry")
+	}
+}
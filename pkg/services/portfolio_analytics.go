@@ -0,0 +1,298 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	unknownExposure   = "Unknown"
+	nonEquityExposure = "Non-equity"
+)
+
+type PortfolioValuer interface {
+	ValuePortfolio(holdings []domain.UserPortfolioHolding, baseCurrency domain.Currency) (domain.PortfolioValuation, error)
+}
+
+type EtfOverviewSource interface {
+	GetEtfOverview(symbol string) (domain.EtfOverview, error)
+}
+
+type StockProfileSource interface {
+	GetStockProfile(symbol string) (domain.StockProfile, error)
+}
+
+// PortfolioAnalyticsService analyzes the exposure of the user portfolios. The ETFs are looked through: their top
+// holdings are exposures of the portfolio like the stocks held directly, and the stocks are mapped to their sector,
+// industry and country with their profile.
+type PortfolioAnalyticsService struct {
+	userContexts UserContextSource
+	valuer       PortfolioValuer
+	etfs         EtfOverviewSource
+	profiles     StockProfileSource
+}
+
+func NewPortfolioAnalyticsService(userContexts UserContextSource, valuer PortfolioValuer, etfs EtfOverviewSource, profiles StockProfileSource) (*PortfolioAnalyticsService, error) {
+	return &PortfolioAnalyticsService{
+		userContexts: userContexts,
+		valuer:       valuer,
+		etfs:         etfs,
+		profiles:     profiles,
+	}, nil
+}
+
+// exposure is a part of a portfolio: a holding, a top holding of a fund or the rest of a fund
+type exposure struct {
+	symbol    string
+	name      string
+	weight    float64
+	fund      string // The fund the exposure is held through, empty when held directly
+	remainder bool   // The holdings of the fund beyond its top holdings
+	equity    bool
+	cash      bool
+	profile   *domain.StockProfile
+}
+
+// GetPortfolioExposure returns the exposure of the portfolio of the user by asset class, sector, industry,
+// country and single name
+func (s *PortfolioAnalyticsService) GetPortfolioExposure(userID string, weighting domain.ExposureWeighting) (domain.PortfolioExposure, error) {
+	userContext, err := s.userContexts.GetUserContext(userID)
+	if err != nil {
+		return domain.PortfolioExposure{}, err
+	}
+
+	holdings, weights, excluded, err := s.weightHoldings(userContext.UserPortfolio, weighting)
+	if err != nil {
+		return domain.PortfolioExposure{}, err
+	}
+
+	result := domain.PortfolioExposure{UserID: userID, Weighting: weighting, Excluded: excluded}
+
+	assetClasses := make(map[string]float64)
+	var exposures []exposure
+	var fundsWeight, expandedWeight float64
+	for i, holding := range holdings {
+		assetClasses[string(holding.AssetClass)] += weights[i]
+		if holding.AssetClass != domain.ETF {
+			exposures = append(exposures, exposure{
+				symbol: strings.ToUpper(holding.Symbol),
+				name:   holding.Name,
+				weight: weights[i],
+				equity: holding.AssetClass == domain.Stock,
+				cash:   holding.AssetClass == domain.Cash,
+			})
+			continue
+		}
+
+		fundsWeight += weights[i]
+		fundExposures := s.lookThrough(holding, weights[i])
+		for _, e := range fundExposures {
+			if !e.remainder {
+				expandedWeight += e.weight
+			}
+		}
+		exposures = append(exposures, fundExposures...)
+	}
+	if fundsWeight > 0 {
+		result.LookThroughCoverage = expandedWeight / fundsWeight * 100
+	}
+
+	s.addProfiles(exposures)
+
+	sectors, industries, countries := make(map[string]float64), make(map[string]float64), make(map[string]float64)
+	names := make(map[string]*domain.NameExposure)
+	for _, e := range exposures {
+		sector, industry, country := classify(e)
+		sectors[sector] += e.weight
+		industries[industry] += e.weight
+		countries[country] += e.weight
+
+		if e.remainder || e.cash {
+			continue
+		}
+		key := cmp.Or(e.symbol, strings.ToUpper(e.name))
+		name, ok := names[key]
+		if !ok {
+			name = &domain.NameExposure{Symbol: e.symbol, Name: e.name}
+			names[key] = name
+		}
+		name.Weight += e.weight
+		if e.fund == "" {
+			name.DirectWeight += e.weight
+		} else {
+			name.IndirectWeight += e.weight
+			if !slices.Contains(name.Funds, e.fund) {
+				name.Funds = append(name.Funds, e.fund)
+			}
+		}
+		if name.Name == "" {
+			name.Name = e.name
+		}
+	}
+
+	result.AssetClasses = buckets(assetClasses)
+	result.Sectors = buckets(sectors)
+	result.Industries = buckets(industries)
+	result.Countries = buckets(countries)
+
+	for _, name := range names {
+		result.Names = append(result.Names, *name)
+	}
+	slices.SortFunc(result.Names, func(a, b domain.NameExposure) int {
+		return cmp.Or(cmp.Compare(b.Weight, a.Weight), cmp.Compare(a.Symbol, b.Symbol))
+	})
+
+	for i, name := range result.Names {
+		if i < 10 {
+			result.Top10Weight += name.Weight
+		}
+		result.Herfindahl += (name.Weight / 100) * (name.Weight / 100)
+	}
+	if result.Herfindahl > 0 {
+		result.EffectiveNames = 1 / result.Herfindahl
+	}
+
+	return result, nil
+}
+
+// weightHoldings returns the holdings that have a weight and their weight in percent, and the excluded ones
+func (s *PortfolioAnalyticsService) weightHoldings(portfolio []domain.UserPortfolioHolding, weighting domain.ExposureWeighting) ([]domain.UserPortfolioHolding, []float64, []domain.UnpricedHolding, error) {
+	var holdings []domain.UserPortfolioHolding
+	var weights []float64
+	var excluded []domain.UnpricedHolding
+
+	switch weighting {
+	case domain.WeightingMarketValue:
+		valuation, err := s.valuer.ValuePortfolio(portfolio, domain.USD)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, h := range valuation.Holdings {
+			holdings = append(holdings, h.Holding)
+			weights = append(weights, h.Weight)
+		}
+		excluded = valuation.Unpriced
+
+	case domain.WeightingStated:
+		var total float64
+		for _, h := range portfolio {
+			if h.PortfolioPercentage <= 0 {
+				excluded = append(excluded, domain.UnpricedHolding{Holding: h, Reason: "no stated portfolio percentage"})
+				continue
+			}
+			holdings = append(holdings, h)
+			weights = append(weights, h.PortfolioPercentage)
+			total += h.PortfolioPercentage
+		}
+		// The stated percentages are normalized, they rarely add up to exactly 100
+		for i := range weights {
+			weights[i] = weights[i] / total * 100
+		}
+
+	default:
+		return nil, nil, nil, fmt.Errorf("invalid weighting: %s", weighting)
+	}
+
+	return holdings, weights, excluded, nil
+}
+
+// lookThrough splits the weight of the fund into its top holdings and the remainder
+func (s *PortfolioAnalyticsService) lookThrough(fund domain.UserPortfolioHolding, weight float64) []exposure {
+	fundSymbol := strings.ToUpper(fund.Symbol)
+	remainder := exposure{symbol: fundSymbol, name: fund.Name, weight: weight, fund: fundSymbol, remainder: true}
+
+	overview, err := s.etfs.GetEtfOverview(strings.ToLower(fund.Symbol))
+	if err != nil {
+		return []exposure{remainder}
+	}
+
+	var exposures []exposure
+	for _, holding := range overview.TopHoldings {
+		percentage, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(holding.Weight), "%"), 64)
+		if err != nil || percentage <= 0 {
+			continue
+		}
+		holdingWeight := weight * percentage / 100
+		exposures = append(exposures, exposure{
+			symbol: strings.ToUpper(holding.Symbol),
+			name:   holding.Name,
+			weight: holdingWeight,
+			fund:   fundSymbol,
+			equity: true,
+		})
+		remainder.weight -= holdingWeight
+	}
+
+	if remainder.weight > 1e-9 {
+		exposures = append(exposures, remainder)
+	}
+	return exposures
+}
+
+// addProfiles sets the profile of the equity exposures, the profiles that can't be fetched are left unknown
+func (s *PortfolioAnalyticsService) addProfiles(exposures []exposure) {
+	var symbols []string
+	for _, e := range exposures {
+		// The symbols of the non US listings (e.g. "!jse/TRU") have no stockanalysis profile
+		if e.equity && e.symbol != "" && !strings.ContainsAny(e.symbol, "!/") && !slices.Contains(symbols, e.symbol) {
+			symbols = append(symbols, e.symbol)
+		}
+	}
+
+	profiles := make(map[string]*domain.StockProfile, len(symbols))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrentPriceRequests)
+	for _, symbol := range symbols {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			profile, err := s.profiles.GetStockProfile(strings.ToLower(symbol))
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			profiles[symbol] = &profile
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for i := range exposures {
+		exposures[i].profile = profiles[exposures[i].symbol]
+	}
+}
+
+// classify returns the sector, the industry and the country of the exposure
+func classify(e exposure) (string, string, string) {
+	switch {
+	case e.remainder:
+		return unknownExposure, unknownExposure, unknownExposure
+	case !e.equity:
+		return nonEquityExposure, nonEquityExposure, nonEquityExposure
+	case e.profile == nil:
+		return unknownExposure, unknownExposure, unknownExposure
+	default:
+		return cmp.Or(e.profile.Sector, unknownExposure), cmp.Or(e.profile.Industry, unknownExposure), cmp.Or(e.profile.Country, unknownExposure)
+	}
+}
+
+// buckets returns the weights by name sorted by weight, the largest first
+func buckets(weights map[string]float64) []domain.ExposureBucket {
+	result := make([]domain.ExposureBucket, 0, len(weights))
+	for name, weight := range weights {
+		result = append(result, domain.ExposureBucket{Name: name, Weight: weight})
+	}
+	slices.SortFunc(result, func(a, b domain.ExposureBucket) int {
+		return cmp.Or(cmp.Compare(b.Weight, a.Weight), cmp.Compare(a.Name, b.Name))
+	})
+	return result
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"testing"
+)
+
+type stubUserContexts map[string]domain.UserContext
+
+func (s stubUserContexts) GetUserContext(userID string) (domain.UserContext, error) {
+	return s[userID], nil
+}
+
+type stubEtfs map[string]domain.EtfOverview
+
+func (s stubEtfs) GetEtfOverview(symbol string) (domain.EtfOverview, error) {
+	overview, ok := s[symbol]
+	if !ok {
+		return domain.EtfOverview{}, fmt.Errorf("etf %s not found", symbol)
+	}
+	return overview, nil
+}
+
+type stubProfiles map[string]domain.StockProfile
+
+func (s stubProfiles) GetStockProfile(symbol string) (domain.StockProfile, error) {
+	profile, ok := s[symbol]
+	if !ok {
+		return domain.StockProfile{}, fmt.Errorf("stock %s not found", symbol)
+	}
+	return profile, nil
+}
+
+func TestGetPortfolioExposure(t *testing.T) {
+	userContexts := stubUserContexts{"u1": {UserID: "u1", UserPortfolio: []domain.UserPortfolioHolding{
+		{AssetClass: domain.Stock, Symbol: "AAPL", PortfolioPercentage: 30},
+		{AssetClass: domain.ETF, Symbol: "QQQ", PortfolioPercentage: 50},
+		{AssetClass: domain.Crypto, Symbol: "BTC", PortfolioPercentage: 20},
+		{AssetClass: domain.Stock, Symbol: "NVDA"},
+	}}}
+	etfs := stubEtfs{"qqq": {Symbol: "QQQ", TopHoldings: []domain.EtfHolding{
+		{Symbol: "AAPL", Name: "Apple Inc.", Weight: "10%"},
+		{Symbol: "JPM", Name: "JPMorgan Chase", Weight: "30.00%"},
+	}}}
+	profiles := stubProfiles{
+		"aapl": {Sector: "Technology", Industry: "Consumer Electronics", Country: "United States"},
+		"jpm":  {Sector: "Financials", Industry: "Banks", Country: "United States"},
+	}
+
+	s, _ := NewPortfolioAnalyticsService(userContexts, nil, etfs, profiles)
+	exposure, err := s.GetPortfolioExposure("u1", domain.WeightingStated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weights := func(buckets []domain.ExposureBucket) map[string]float64 {
+		m := make(map[string]float64)
+		for _, b := range buckets {
+			m[b.Name] = math.Round(b.Weight*100) / 100
+		}
+		return m
+	}
+
+	// AAPL: 30 directly + 5 through QQQ, JPM: 15 through QQQ, the rest of QQQ (30) is unknown
+	if got := weights(exposure.Sectors); got["Technology"] != 35 || got["Financials"] != 15 || got["Unknown"] != 30 || got["Non-equity"] != 20 {
+		t.Errorf("unexpected sectors %v", got)
+	}
+	if got := weights(exposure.AssetClasses); got["stock"] != 30 || got["etf"] != 50 || got["crypto"] != 20 {
+		t.Errorf("unexpected asset classes %v", got)
+	}
+	if exposure.LookThroughCoverage != 40 {
+		t.Errorf("expected 40%% of QQQ looked through, got %v", exposure.LookThroughCoverage)
+	}
+
+	apple := exposure.Names[0]
+	if apple.Symbol != "AAPL" || apple.DirectWeight != 30 || apple.IndirectWeight != 5 || len(apple.Funds) != 1 {
+		t.Errorf("expected AAPL held directly and through QQQ first, got %+v", apple)
+	}
+	if len(exposure.Names) != 3 {
+		t.Errorf("expected AAPL, BTC and JPM, got %+v", exposure.Names)
+	}
+	if want := 0.35*0.35 + 0.2*0.2 + 0.15*0.15; math.Abs(exposure.Herfindahl-want) > 1e-9 || exposure.Top10Weight != 70 {
+		t.Errorf("unexpected concentration %v %v", exposure.Herfindahl, exposure.Top10Weight)
+	}
+	if len(exposure.Excluded) != 1 || exposure.Excluded[0].Holding.Symbol != "NVDA" {
+		t.Errorf("expected NVDA excluded, got %+v", exposure.Excluded)
+	}
+}
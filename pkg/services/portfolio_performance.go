@@ -0,0 +1,271 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+type TransactionSource interface {
+	GetTransactions(userID string) ([]domain.Transaction, error)
+}
+
+// PortfolioPerformanceService derives the positions of the users from their transaction ledger and reports their
+// profit and loss and their returns. The amounts are converted to the base currency at the current exchange rates,
+// the historical ones aren't available.
+type PortfolioPerformanceService struct {
+	transactions  TransactionSource
+	valuer        PortfolioValuer
+	prices        PricesDataService
+	exchangeRates ExchangeRatesService
+}
+
+func NewPortfolioPerformanceService(transactions TransactionSource, valuer PortfolioValuer, prices PricesDataService, exchangeRates ExchangeRatesService) (*PortfolioPerformanceService, error) {
+	return &PortfolioPerformanceService{
+		transactions:  transactions,
+		valuer:        valuer,
+		prices:        prices,
+		exchangeRates: exchangeRates,
+	}, nil
+}
+
+// GetPortfolioPerformance returns the positions derived from the ledger of the user with their cost basis and
+// profit and loss, and the time-weighted and money-weighted returns of the portfolio
+func (s *PortfolioPerformanceService) GetPortfolioPerformance(userID string, baseCurrency domain.Currency, method domain.CostBasisMethod) (domain.PortfolioPerformance, error) {
+	if _, ok := domain.CurrencyCodeToNameMap[baseCurrency]; !ok {
+		return domain.PortfolioPerformance{}, fmt.Errorf("invalid base currency: %s", baseCurrency)
+	}
+	if method != domain.CostBasisFIFO && method != domain.CostBasisAverage {
+		return domain.PortfolioPerformance{}, fmt.Errorf("invalid cost basis method: %s", method)
+	}
+
+	transactions, err := s.transactions.GetTransactions(userID)
+	if err != nil {
+		return domain.PortfolioPerformance{}, err
+	}
+	if len(transactions) == 0 {
+		return domain.PortfolioPerformance{}, errors.NoTransactionsError{UserID: userID}
+	}
+
+	rates := &exchangeRates{service: s.exchangeRates, rates: make(map[domain.Currency]float64)}
+	var rateErr error
+	convert := func(amount float64, currency domain.Currency) float64 {
+		rate, err := rates.get(currency, baseCurrency)
+		if err != nil {
+			rateErr = fmt.Errorf("failed to convert %s to %s: %w", currency, baseCurrency, err)
+		}
+		return amount * rate
+	}
+
+	state, err := replayLedger(transactions, method, convert, time.Time{})
+	if err != nil {
+		return domain.PortfolioPerformance{}, err
+	}
+	if rateErr != nil {
+		return domain.PortfolioPerformance{}, rateErr
+	}
+
+	performance := domain.PortfolioPerformance{
+		UserID:               userID,
+		BaseCurrency:         baseCurrency,
+		CostBasisMethod:      method,
+		Cash:                 state.cash,
+		NetDeposits:          state.netDeposits(),
+		RealizedPnL:          state.realized,
+		Dividends:            state.dividends,
+		Fees:                 state.fees,
+		FirstTransactionDate: transactions[0].Date,
+		ValuedAt:             time.Now().UTC(),
+	}
+
+	if err := s.valuePositions(state, baseCurrency); err != nil {
+		return domain.PortfolioPerformance{}, err
+	}
+
+	allPriced := true
+	performance.MarketValue = state.cash
+	for _, symbol := range state.symbols {
+		p := state.positions[symbol]
+		performance.Positions = append(performance.Positions, *p)
+		performance.MarketValue += p.MarketValue
+		performance.UnrealizedPnL += p.UnrealizedPnL
+		if p.Quantity > 0 && !p.Priced {
+			allPriced = false
+		}
+	}
+	slices.SortStableFunc(performance.Positions, func(a, b domain.Position) int {
+		return cmp.Or(cmp.Compare(b.MarketValue, a.MarketValue), cmp.Compare(b.Quantity, a.Quantity))
+	})
+
+	if !allPriced {
+		performance.ReturnsError = "some open positions couldn't be priced"
+		return performance, nil
+	}
+
+	// The investor pays the deposits and gets the withdrawals and the current value
+	investorFlows := make([]cashFlow, 0, len(state.flows)+1)
+	for _, flow := range state.flows {
+		investorFlows = append(investorFlows, cashFlow{date: flow.date, amount: -flow.amount})
+	}
+	investorFlows = append(investorFlows, cashFlow{date: performance.ValuedAt, amount: performance.MarketValue})
+	if rate, ok := xirr(investorFlows); ok {
+		mwr := rate * 100
+		performance.MoneyWeightedReturn = &mwr
+	}
+
+	twr, err := s.timeWeightedReturn(transactions, method, convert, state.flows, baseCurrency)
+	if err != nil {
+		performance.ReturnsError = err.Error()
+		return performance, nil
+	}
+	performance.TimeWeightedReturn = &twr
+	if days := performance.ValuedAt.Sub(performance.FirstTransactionDate).Hours() / 24; days >= 365 {
+		annualized := (math.Pow(1+twr/100, 365/days) - 1) * 100
+		performance.AnnualizedTimeWeightedReturn = &annualized
+	}
+
+	return performance, nil
+}
+
+// valuePositions prices the open positions at their current price
+func (s *PortfolioPerformanceService) valuePositions(state *ledgerState, baseCurrency domain.Currency) error {
+	var holdings []domain.UserPortfolioHolding
+	for _, symbol := range state.symbols {
+		p := state.positions[symbol]
+		if p.Quantity > 0 {
+			holdings = append(holdings, domain.UserPortfolioHolding{AssetClass: p.AssetClass, Symbol: p.Symbol, Quantity: p.Quantity})
+		}
+	}
+	if len(holdings) == 0 {
+		return nil
+	}
+
+	valuation, err := s.valuer.ValuePortfolio(holdings, baseCurrency)
+	if err != nil {
+		return err
+	}
+	for _, h := range valuation.Holdings {
+		p := state.positions[h.Holding.Symbol]
+		p.Price, p.MarketValue, p.Priced = h.MarketValue/p.Quantity, h.MarketValue, true
+		p.UnrealizedPnL = p.MarketValue - p.CostBasis
+	}
+	for _, h := range valuation.Unpriced {
+		state.positions[h.Holding.Symbol].PriceError = h.Reason
+	}
+	return nil
+}
+
+// timeWeightedReturn chains the returns of the periods between the external cash flows, so that the return doesn't
+// depend on when the money came in. The portfolio is valued at the historical closes of the flow days, which are
+// only available for the stocks and the ETFs.
+func (s *PortfolioPerformanceService) timeWeightedReturn(transactions []domain.Transaction, method domain.CostBasisMethod, convert func(float64, domain.Currency) float64, flows []cashFlow, baseCurrency domain.Currency) (float64, error) {
+	if len(flows) == 0 {
+		return 0, fmt.Errorf("the ledger has no cash flows")
+	}
+	start := flows[0].date
+	now := time.Now().UTC()
+
+	period := historyPeriod(now.Sub(start))
+	usdRate, err := (&exchangeRates{service: s.exchangeRates, rates: make(map[domain.Currency]float64)}).get(domain.USD, baseCurrency)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert USD to %s: %w", baseCurrency, err)
+	}
+
+	histories := make(map[string][]domain.Price)
+	for _, t := range transactions {
+		if t.Type != domain.TransactionBuy || histories[t.Symbol] != nil {
+			continue
+		}
+		if t.AssetClass != domain.Stock && t.AssetClass != domain.ETF {
+			return 0, fmt.Errorf("no price history for the asset class %s of %s", t.AssetClass, t.Symbol)
+		}
+		prices, err := s.prices.GetHistoricalPrices(strings.ToLower(t.Symbol), t.AssetClass, period)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get the price history of %s: %w", t.Symbol, err)
+		}
+		if len(prices.Prices) == 0 {
+			return 0, fmt.Errorf("no price history for %s", t.Symbol)
+		}
+		histories[t.Symbol] = prices.Prices
+	}
+
+	// value returns the value of the portfolio at the end of the day, and the external flows of the day
+	value := func(day time.Time) (float64, float64, error) {
+		state, err := replayLedger(transactions, method, convert, day)
+		if err != nil {
+			return 0, 0, err
+		}
+		total := state.cash
+		for _, p := range state.positions {
+			if p.Quantity == 0 {
+				continue
+			}
+			total += p.Quantity * closeOn(histories[p.Symbol], day) * usdRate
+		}
+		var dayFlows float64
+		for _, flow := range state.flows {
+			if flow.date.Equal(day) {
+				dayFlows += flow.amount
+			}
+		}
+		return total, dayFlows, nil
+	}
+
+	var days []time.Time
+	for _, flow := range flows {
+		if len(days) == 0 || !days[len(days)-1].Equal(flow.date) {
+			days = append(days, flow.date)
+		}
+	}
+	days = append(days, now)
+
+	growth := 1.0
+	startValue, _, err := value(days[0])
+	if err != nil {
+		return 0, err
+	}
+	for _, day := range days[1:] {
+		endValue, dayFlows, err := value(day)
+		if err != nil {
+			return 0, err
+		}
+		if startValue > 0 {
+			growth *= (endValue - dayFlows) / startValue
+		}
+		startValue = endValue
+	}
+
+	return (growth - 1) * 100, nil
+}
+
+// historyPeriod returns the shortest period of price history covering the duration
+func historyPeriod(duration time.Duration) domain.Period {
+	days := duration.Hours() / 24
+	switch {
+	case days <= 28:
+		return domain.Period1M
+	case days <= 180:
+		return domain.Period6M
+	case days <= 360:
+		return domain.Period1Y
+	case days <= 5*360:
+		return domain.Period5Y
+	default:
+		return domain.PeriodMax
+	}
+}
+
+// closeOn returns the last close on or before the end of the day, the first close when the day precedes them
+func closeOn(prices []domain.Price, day time.Time) float64 {
+	end := day.AddDate(0, 0, 1)
+	i, _ := slices.BinarySearchFunc(prices, end, func(p domain.Price, t time.Time) int { return p.Date.Compare(t) })
+	if i == 0 {
+		return prices[0].ClosePrice
+	}
+	return prices[i-1].ClosePrice
+}
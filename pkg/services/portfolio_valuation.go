@@ -0,0 +1,240 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/search"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxConcurrentPriceRequests bounds the upstream requests made at the same time to price a portfolio
+const maxConcurrentPriceRequests = 8
+
+type UserContextSource interface {
+	GetUserContext(userID string) (domain.UserContext, error)
+}
+
+type PricesDataService interface {
+	GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error)
+}
+
+type CryptoPricesService interface {
+	SearchCryptocurrencies(query string) ([]search.Result[domain.Cryptocurrency], error)
+	GetCryptocurrencyDataById(id string) (domain.CryptocurrencyData, error)
+}
+
+type ExchangeRatesService interface {
+	GetCurrencyExchangeRate(fromCurrency domain.Currency, toCurrency domain.Currency) (domain.CurrencyExchangeRate, error)
+}
+
+// PortfolioValuationService prices the holdings of the user portfolios: the stocks and the ETFs at their last
+// stockanalysis close, the cryptocurrencies at their CoinGecko price and the cash at face value (the symbol of a
+// cash holding is its currency). The prices are converted to the base currency of the valuation.
+type PortfolioValuationService struct {
+	userContexts  UserContextSource
+	prices        PricesDataService
+	crypto        CryptoPricesService
+	exchangeRates ExchangeRatesService
+}
+
+func NewPortfolioValuationService(userContexts UserContextSource, prices PricesDataService, crypto CryptoPricesService, exchangeRates ExchangeRatesService) (*PortfolioValuationService, error) {
+	return &PortfolioValuationService{
+		userContexts:  userContexts,
+		prices:        prices,
+		crypto:        crypto,
+		exchangeRates: exchangeRates,
+	}, nil
+}
+
+// GetPortfolioValuation prices the portfolio of the user in the base currency
+func (s *PortfolioValuationService) GetPortfolioValuation(userID string, baseCurrency domain.Currency) (domain.PortfolioValuation, error) {
+	userContext, err := s.userContexts.GetUserContext(userID)
+	if err != nil {
+		return domain.PortfolioValuation{}, err
+	}
+
+	valuation, err := s.ValuePortfolio(userContext.UserPortfolio, baseCurrency)
+	if err != nil {
+		return domain.PortfolioValuation{}, err
+	}
+	valuation.UserID = userID
+	return valuation, nil
+}
+
+// ValuePortfolio prices the holdings in the base currency, the holdings that can't be priced are listed apart
+// with the reason. The priced holdings are sorted by market value, the largest first.
+func (s *PortfolioValuationService) ValuePortfolio(holdings []domain.UserPortfolioHolding, baseCurrency domain.Currency) (domain.PortfolioValuation, error) {
+	if _, ok := domain.CurrencyCodeToNameMap[baseCurrency]; !ok {
+		return domain.PortfolioValuation{}, fmt.Errorf("invalid base currency: %s", baseCurrency)
+	}
+
+	valuation := domain.PortfolioValuation{BaseCurrency: baseCurrency, ValuedAt: time.Now().UTC()}
+	rates := &exchangeRates{service: s.exchangeRates, rates: make(map[domain.Currency]float64)}
+
+	type priced struct {
+		valuation domain.HoldingValuation
+		err       error
+	}
+	results := make([]priced, len(holdings))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrentPriceRequests)
+	for i, holding := range holdings {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			results[i].valuation, results[i].err = s.valueHolding(holding, baseCurrency, rates)
+		}()
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		if result.err != nil {
+			valuation.Unpriced = append(valuation.Unpriced, domain.UnpricedHolding{Holding: holdings[i], Reason: result.err.Error()})
+			continue
+		}
+		valuation.Holdings = append(valuation.Holdings, result.valuation)
+		valuation.TotalValue += result.valuation.MarketValue
+	}
+
+	if valuation.TotalValue > 0 {
+		for i := range valuation.Holdings {
+			valuation.Holdings[i].Weight = valuation.Holdings[i].MarketValue / valuation.TotalValue * 100
+		}
+	}
+	slices.SortStableFunc(valuation.Holdings, func(a, b domain.HoldingValuation) int {
+		return cmp.Compare(b.MarketValue, a.MarketValue)
+	})
+
+	return valuation, nil
+}
+
+func (s *PortfolioValuationService) valueHolding(holding domain.UserPortfolioHolding, baseCurrency domain.Currency, rates *exchangeRates) (domain.HoldingValuation, error) {
+	if holding.Quantity <= 0 {
+		return domain.HoldingValuation{}, fmt.Errorf("the quantity isn't known")
+	}
+
+	valuation := domain.HoldingValuation{Holding: holding}
+	switch holding.AssetClass {
+	case domain.Stock, domain.ETF:
+		if holding.Symbol == "" {
+			return domain.HoldingValuation{}, fmt.Errorf("the symbol isn't known")
+		}
+		// Lowercase like the other tools, so that they share the cached prices
+		prices, err := s.prices.GetHistoricalPrices(strings.ToLower(holding.Symbol), holding.AssetClass, domain.Period5D)
+		if err != nil {
+			return domain.HoldingValuation{}, fmt.Errorf("failed to get the price: %w", err)
+		}
+		if len(prices.Prices) == 0 {
+			return domain.HoldingValuation{}, fmt.Errorf("no price found for %s", holding.Symbol)
+		}
+		last := prices.Prices[len(prices.Prices)-1]
+		valuation.Price, valuation.PriceDate = last.ClosePrice, last.Date
+		valuation.PriceCurrency, valuation.PriceSource = domain.USD, "stockanalysis"
+
+	case domain.Crypto:
+		data, err := cryptocurrencyData(s.crypto, cmp.Or(holding.Symbol, holding.Name))
+		if err != nil {
+			return domain.HoldingValuation{}, err
+		}
+		valuation.Price, valuation.PriceDate = data.CurrentUsdPrice, time.Now().UTC()
+		valuation.PriceCurrency, valuation.PriceSource = domain.USD, "coingecko"
+
+	case domain.Cash:
+		currency := domain.Currency(strings.ToUpper(holding.Symbol))
+		if currency == "" {
+			currency = baseCurrency
+		}
+		if _, ok := domain.CurrencyCodeToNameMap[currency]; !ok {
+			return domain.HoldingValuation{}, fmt.Errorf("unsupported cash currency: %s", holding.Symbol)
+		}
+		valuation.Price, valuation.PriceDate = 1, time.Now().UTC()
+		valuation.PriceCurrency, valuation.PriceSource = currency, "face_value"
+
+	default:
+		return domain.HoldingValuation{}, fmt.Errorf("no price source for the asset class %s", holding.AssetClass)
+	}
+
+	rate, err := rates.get(valuation.PriceCurrency, baseCurrency)
+	if err != nil {
+		return domain.HoldingValuation{}, fmt.Errorf("failed to convert %s to %s: %w", valuation.PriceCurrency, baseCurrency, err)
+	}
+	valuation.MarketValue = holding.Quantity * valuation.Price * rate
+
+	return valuation, nil
+}
+
+// cryptocurrencyData returns the data of the cryptocurrency with the symbol (e.g. BTC, the one with the largest
+// market cap is used)
+func cryptocurrencyData(crypto CryptoPricesService, query string) (domain.CryptocurrencyData, error) {
+	id, err := cryptocurrencyID(crypto, query)
+	if err != nil {
+		return domain.CryptocurrencyData{}, err
+	}
+
+	data, err := crypto.GetCryptocurrencyDataById(id)
+	if err != nil {
+		return domain.CryptocurrencyData{}, fmt.Errorf("failed to get the price: %w", err)
+	}
+	return data, nil
+}
+
+// cryptocurrencyID returns the CoinGecko id of the cryptocurrency with the symbol or the id (e.g. bitcoin for BTC),
+// or else with the name (e.g. Bitcoin, for the holdings without a symbol). The one with the largest market cap
+// is used.
+func cryptocurrencyID(crypto CryptoPricesService, query string) (string, error) {
+	results, err := crypto.SearchCryptocurrencies(query)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the cryptocurrency: %w", err)
+	}
+
+	for _, result := range results {
+		if result.Reason == search.MatchReasonExactSymbol || strings.EqualFold(result.Item.Id, query) {
+			return result.Item.Id, nil
+		}
+	}
+	for _, result := range results {
+		if strings.EqualFold(result.Item.Name, strings.TrimSpace(query)) {
+			return result.Item.Id, nil
+		}
+	}
+
+	return "", fmt.Errorf("cryptocurrency not found: %s", query)
+}
+
+// exchangeRates memoizes the exchange rates needed by a valuation, so that each one is requested once
+type exchangeRates struct {
+	service ExchangeRatesService
+
+	mu    sync.Mutex
+	rates map[domain.Currency]float64 // Rates to the base currency by currency
+}
+
+func (r *exchangeRates) get(from domain.Currency, to domain.Currency) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rate, ok := r.rates[from]; ok {
+		return rate, nil
+	}
+	rate, err := r.service.GetCurrencyExchangeRate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	if rate.Rate <= 0 {
+		return 0, fmt.Errorf("invalid exchange rate: %v", rate.Rate)
+	}
+	r.rates[from] = rate.Rate
+	return rate.Rate, nil
+}
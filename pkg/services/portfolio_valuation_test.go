@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/search"
+	"math"
+	"testing"
+	"time"
+)
+
+type stubPrices map[string]float64
+
+func (p stubPrices) GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error) {
+	price, ok := p[ticker]
+	if !ok {
+		return domain.HistoricalPrices{}, fmt.Errorf("no historical prices found for %s", ticker)
+	}
+	return domain.HistoricalPrices{Period: period, Prices: []domain.Price{{Date: time.Now(), ClosePrice: price / 2}, {Date: time.Now(), ClosePrice: price}}}, nil
+}
+
+type stubCrypto struct{}
+
+func (stubCrypto) SearchCryptocurrencies(query string) ([]search.Result[domain.Cryptocurrency], error) {
+	return search.NewIndex([]domain.Cryptocurrency{
+		{Id: "bitcoin", Symbol: "btc", Name: "Bitcoin", MarketCap: 2e12},
+		{Id: "wrapped-bitcoin", Symbol: "wbtc", Name: "Wrapped Bitcoin", MarketCap: 1e10},
+		{Id: "bitcoin-cash", Symbol: "bch", Name: "Bitcoin Cash", MarketCap: 1e10},
+		{Id: "bitcoin-cash-fork", Symbol: "bchf", Name: "Bitcoin Cash", MarketCap: 1e3},
+	}, cryptocurrencyDocument).Search(query), nil
+}
+
+func (stubCrypto) GetCryptocurrencyDataById(id string) (domain.CryptocurrencyData, error) {
+	return domain.CryptocurrencyData{Id: id, CurrentUsdPrice: 100000}, nil
+}
+
+type stubExchangeRates map[domain.Currency]float64 // USD per unit
+
+func (r stubExchangeRates) GetCurrencyExchangeRate(from domain.Currency, to domain.Currency) (domain.CurrencyExchangeRate, error) {
+	return domain.CurrencyExchangeRate{FromCurrency: from, ToCurrency: to, Rate: r[from] / r[to]}, nil
+}
+
+func TestValuePortfolio(t *testing.T) {
+	s, _ := NewPortfolioValuationService(nil, stubPrices{"aapl": 200, "qqq": 500}, stubCrypto{}, stubExchangeRates{domain.USD: 1, domain.EUR: 1.25})
+
+	valuation, err := s.ValuePortfolio([]domain.UserPortfolioHolding{
+		{AssetClass: domain.Stock, Symbol: "AAPL", Quantity: 10},
+		{AssetClass: domain.ETF, Symbol: "QQQ", Quantity: 2},
+		{AssetClass: domain.Crypto, Symbol: "BTC", Quantity: 0.01},
+		{AssetClass: domain.Cash, Symbol: "EUR", Quantity: 1000},
+		{AssetClass: domain.Stock, Symbol: "UNKNOWN", Quantity: 1},
+		{AssetClass: domain.Stock, Symbol: "MSFT", PortfolioPercentage: 10},
+		{AssetClass: domain.RealEstate, Name: "House", Quantity: 1},
+	}, domain.EUR)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2000 + 1000 + 1000 USD at 1.25 USD per EUR, and 1000 EUR
+	if math.Abs(valuation.TotalValue-4200) > 1e-9 {
+		t.Errorf("expected a total value of 4200 EUR, got %v", valuation.TotalValue)
+	}
+	if len(valuation.Holdings) != 4 || valuation.Holdings[0].Holding.Symbol != "AAPL" || math.Abs(valuation.Holdings[0].Weight-1600.0/4200*100) > 1e-9 {
+		t.Errorf("expected the holdings sorted by market value with their weight, got %+v", valuation.Holdings)
+	}
+	if len(valuation.Unpriced) != 3 {
+		t.Errorf("expected the unknown symbol, the unknown quantity and the real estate unpriced, got %+v", valuation.Unpriced)
+	}
+
+	if _, err := s.ValuePortfolio(nil, "XYZ"); err == nil {
+		t.Error("expected an error on an invalid base currency")
+	}
+}
+
+func TestCryptocurrencyID(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "BTC", expected: "bitcoin"},
+		{query: "wrapped-bitcoin", expected: "wrapped-bitcoin"},
+		{query: "Bitcoin", expected: "bitcoin"},
+		{query: "Wrapped Bitcoin", expected: "wrapped-bitcoin"},
+		{query: "bitcoin cash", expected: "bitcoin-cash"},
+		{query: "Bitcoi", expected: ""},
+		{query: "Wrapped", expected: ""},
+	}
+
+	for _, tt := range tests {
+		id, err := cryptocurrencyID(stubCrypto{}, tt.query)
+		if id != tt.expected || (tt.expected == "") != (err != nil) {
+			t.Errorf("expected %q for %q, got %q, %v", tt.expected, tt.query, id, err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPriceHistoryPoints = 500
+	maxPriceHistoryPoints     = 5000
+	// dailyTimeSeriesDays is about the calendar days of the 100 daily bars of Alpha Vantage, the ranges starting
+	// earlier use the weekly bars
+	dailyTimeSeriesDays = 140
+)
+
+type PriceHistoryScraper interface {
+	GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error)
+}
+
+type StockTimeSeriesSource interface {
+	GetStockTimeSeries(symbol string, interval domain.PriceInterval) ([]domain.Price, error)
+}
+
+type CryptoMarketChartSource interface {
+	GetMarketChart(id string, days int) ([]domain.Price, error)
+}
+
+// PriceHistoryService gets the price histories of the stocks and the ETFs from the stockanalysis charts (closes
+// only) or the Alpha Vantage daily and weekly bars (OHLCV), and the ones of the cryptocurrencies from the CoinGecko
+// market charts (closes and 24h volumes)
+type PriceHistoryService struct {
+	scraper      PriceHistoryScraper
+	timeSeries   StockTimeSeriesSource
+	crypto       CryptoPricesService
+	marketCharts CryptoMarketChartSource
+	now          func() time.Time
+}
+
+func NewPriceHistoryService(scraper PriceHistoryScraper, timeSeries StockTimeSeriesSource, crypto CryptoPricesService, marketCharts CryptoMarketChartSource) (*PriceHistoryService, error) {
+	return &PriceHistoryService{
+		scraper:      scraper,
+		timeSeries:   timeSeries,
+		crypto:       crypto,
+		marketCharts: marketCharts,
+		now:          time.Now,
+	}, nil
+}
+
+// GetPriceHistory returns the prices of the query, the oldest first, merged down to its maximum number of points
+func (s *PriceHistoryService) GetPriceHistory(query domain.PriceHistoryQuery) (domain.PriceHistory, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(query.Symbol))
+	if symbol == "" {
+		return domain.PriceHistory{}, fmt.Errorf("symbol is required")
+	}
+	assetClass := cmp.Or(query.AssetClass, domain.Stock)
+	if assetClass != domain.Stock && assetClass != domain.ETF && assetClass != domain.Crypto {
+		return domain.PriceHistory{}, fmt.Errorf("asset_class valid values are: stock, etf, crypto")
+	}
+	maxPoints := cmp.Or(query.MaxPoints, defaultPriceHistoryPoints)
+	if maxPoints < 2 || maxPoints > maxPriceHistoryPoints {
+		return domain.PriceHistory{}, fmt.Errorf("max_points must be between 2 and %d", maxPriceHistoryPoints)
+	}
+
+	now := s.now().UTC()
+	period := cmp.Or(query.Period, domain.Period1Y)
+	// The periods end now, the end is only set for the custom ranges
+	start, end := query.Start, time.Time{}
+	if start.IsZero() {
+		var err error
+		if start, err = periodStart(period, now); err != nil {
+			return domain.PriceHistory{}, err
+		}
+	} else {
+		// The custom ranges are made of days, the end day is included
+		end = now
+		if !query.End.IsZero() {
+			end = query.End.AddDate(0, 0, 1)
+		}
+		if !start.Before(end) {
+			return domain.PriceHistory{}, fmt.Errorf("the start date must be before the end date")
+		}
+		if start.After(now) {
+			return domain.PriceHistory{}, fmt.Errorf("the start date must be in the past")
+		}
+		period = coveringPeriod(start, now)
+	}
+
+	history := domain.PriceHistory{Symbol: symbol, AssetClass: assetClass}
+	var prices []domain.Price
+	var err error
+	switch {
+	case assetClass == domain.Crypto:
+		history.Source = "coingecko"
+		prices, err = s.cryptoPrices(symbol, period, start, now)
+	case query.Ohlcv:
+		if period == domain.Period1D {
+			return domain.PriceHistory{}, fmt.Errorf("the OHLCV bars are daily, they aren't available for the 1d period")
+		}
+		interval := domain.PriceIntervalWeekly
+		if start.After(now.AddDate(0, 0, -dailyTimeSeriesDays)) {
+			interval = domain.PriceIntervalDaily
+		}
+		history.Source = fmt.Sprintf("alphavantage %s", interval)
+		prices, err = s.timeSeries.GetStockTimeSeries(symbol, interval)
+	default:
+		history.Source = "stockanalysis"
+		var historicalPrices domain.HistoricalPrices
+		historicalPrices, err = s.scraper.GetHistoricalPrices(strings.ToLower(symbol), assetClass, period)
+		prices = historicalPrices.Prices
+		// The chart of a period is the range, e.g. the last session for 1d even on a weekend
+		if query.Start.IsZero() {
+			start = time.Time{}
+		}
+	}
+	if err != nil {
+		return domain.PriceHistory{}, err
+	}
+
+	prices = slices.DeleteFunc(slices.Clone(prices), func(p domain.Price) bool {
+		return p.Date.Before(start) || (!end.IsZero() && !p.Date.Before(end))
+	})
+	if len(prices) == 0 {
+		return domain.PriceHistory{}, fmt.Errorf("no prices found for %s in the range", symbol)
+	}
+
+	history.Start = prices[0].Date
+	history.End = prices[len(prices)-1].Date
+	history.SourcePoints = len(prices)
+	if first := prices[0].ClosePrice; first != 0 {
+		history.PercentageChange = (prices[len(prices)-1].ClosePrice - first) / first * 100
+	}
+	// The volumes of the market charts are trailing 24h volumes, they can't be summed
+	history.Prices = downsamplePrices(prices, maxPoints, assetClass != domain.Crypto)
+
+	return history, nil
+}
+
+func (s *PriceHistoryService) cryptoPrices(symbol string, period domain.Period, start time.Time, now time.Time) ([]domain.Price, error) {
+	id, err := cryptocurrencyID(s.crypto, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	days := 0
+	if period == domain.Period1D {
+		days = 1
+	} else if period != domain.PeriodMax {
+		days = int(math.Ceil(now.Sub(start).Hours()/24)) + 1
+	}
+	prices, err := s.marketCharts.GetMarketChart(id, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the market chart: %w", err)
+	}
+	return prices, nil
+}
+
+// periodStart returns the start of the period ending now, the zero time for the whole history
+func periodStart(period domain.Period, now time.Time) (time.Time, error) {
+	switch period {
+	case domain.Period1D:
+		return now.Add(-24 * time.Hour), nil
+	case domain.Period5D:
+		// 5 trading days
+		return now.AddDate(0, 0, -7), nil
+	case domain.Period1M:
+		return now.AddDate(0, -1, 0), nil
+	case domain.PeriodYTD:
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC), nil
+	case domain.Period6M:
+		return now.AddDate(0, -6, 0), nil
+	case domain.Period1Y:
+		return now.AddDate(-1, 0, 0), nil
+	case domain.Period5Y:
+		return now.AddDate(-5, 0, 0), nil
+	case domain.PeriodMax:
+		return time.Time{}, nil
+	default:
+		return time.Time{}, fmt.Errorf("period valid values are: 1d, 5d, 1m, ytd, 6m, 1y, 5y, max")
+	}
+}
+
+// coveringPeriod returns the shortest period ending now that starts before the given start
+func coveringPeriod(start time.Time, now time.Time) domain.Period {
+	for _, period := range []domain.Period{domain.Period5D, domain.Period1M, domain.Period6M, domain.Period1Y, domain.Period5Y} {
+		if periodStart, _ := periodStart(period, now); !start.Before(periodStart) {
+			return period
+		}
+	}
+	return domain.PeriodMax
+}
+
+// downsamplePrices merges consecutive prices into at most maxPoints prices. A merged price has the date and the
+// close of its last price, the open of its first, the highest high, the lowest low, and the sum of the volumes or
+// the last volume.
+func downsamplePrices(prices []domain.Price, maxPoints int, sumVolumes bool) []domain.Price {
+	if len(prices) <= maxPoints {
+		return prices
+	}
+
+	size := (len(prices) + maxPoints - 1) / maxPoints
+	merged := make([]domain.Price, 0, maxPoints)
+	for i := 0; i < len(prices); i += size {
+		group := prices[i:min(i+size, len(prices))]
+		price := group[len(group)-1]
+		price.Open = group[0].Open
+		if sumVolumes {
+			price.Volume = 0
+		}
+		for _, p := range group {
+			price.High = max(price.High, p.High)
+			if p.Low != 0 && (price.Low == 0 || p.Low < price.Low) {
+				price.Low = p.Low
+			}
+			if sumVolumes {
+				price.Volume += p.Volume
+			}
+		}
+		merged = append(merged, price)
+	}
+	return merged
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"market_data_mcp_server/pkg/domain"
+	"testing"
+	"time"
+)
+
+// dailyPrices returns a price per day over the given number of days ending on the given day, the close is the day index
+func dailyPrices(end time.Time, days int) []domain.Price {
+	prices := make([]domain.Price, 0, days)
+	for i := range days {
+		value := float64(i + 1)
+		prices = append(prices, domain.Price{Date: end.AddDate(0, 0, i-days+1), Open: value, High: value + 1, Low: value - 0.5, ClosePrice: value, Volume: 10})
+	}
+	return prices
+}
+
+type stubTimeSeries struct {
+	prices    []domain.Price
+	intervals []domain.PriceInterval
+}
+
+func (s *stubTimeSeries) GetStockTimeSeries(symbol string, interval domain.PriceInterval) ([]domain.Price, error) {
+	s.intervals = append(s.intervals, interval)
+	return s.prices, nil
+}
+
+type stubMarketCharts struct {
+	prices []domain.Price
+	days   []int
+}
+
+func (s *stubMarketCharts) GetMarketChart(id string, days int) ([]domain.Price, error) {
+	s.days = append(s.days, days)
+	return s.prices, nil
+}
+
+func TestGetPriceHistory(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	timeSeries := &stubTimeSeries{prices: dailyPrices(now, 400)}
+	marketCharts := &stubMarketCharts{prices: dailyPrices(now, 400)}
+	s, _ := NewPriceHistoryService(stubPrices{"aapl": 200}, timeSeries, stubCrypto{}, marketCharts)
+	s.now = func() time.Time { return now }
+
+	// A custom range keeps the days from the start to the end included
+	history, err := s.GetPriceHistory(domain.PriceHistoryQuery{
+		Symbol: "aapl", Ohlcv: true, Start: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.Symbol != "AAPL" || history.Source != "alphavantage daily" || history.SourcePoints != 10 || len(history.Prices) != 10 {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	if history.Start.Day() != 1 || history.End.Day() != 10 || history.Prices[0].Open == 0 {
+		t.Errorf("unexpected range %v - %v", history.Start, history.End)
+	}
+
+	// The ranges starting before the daily bars use the weekly ones
+	if _, err := s.GetPriceHistory(domain.PriceHistoryQuery{Symbol: "AAPL", Period: domain.Period1Y, Ohlcv: true}); err != nil {
+		t.Fatal(err)
+	}
+	if timeSeries.intervals[1] != domain.PriceIntervalWeekly {
+		t.Errorf("expected the weekly bars, got %v", timeSeries.intervals)
+	}
+
+	history, err = s.GetPriceHistory(domain.PriceHistoryQuery{Symbol: "btc", AssetClass: domain.Crypto, Period: domain.Period1M, MaxPoints: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marketCharts.days[0] != 32 || history.Source != "coingecko" || history.SourcePoints != 32 || len(history.Prices) != 8 {
+		t.Errorf("unexpected crypto history %+v, days %v", history, marketCharts.days)
+	}
+	if history.Prices[0].Volume != 10 {
+		t.Errorf("expected the 24h volumes not to be summed, got %v", history.Prices[0].Volume)
+	}
+
+	// The charts of the periods are kept whole
+	history, err = s.GetPriceHistory(domain.PriceHistoryQuery{Symbol: "AAPL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.Source != "stockanalysis" || len(history.Prices) != 2 || history.PercentageChange != 100 {
+		t.Errorf("unexpected history %+v", history)
+	}
+
+	for _, invalid := range []domain.PriceHistoryQuery{
+		{Symbol: "AAPL", AssetClass: domain.Cash},
+		{Symbol: "AAPL", Period: "2y"},
+		{Symbol: "AAPL", Period: domain.Period1D, Ohlcv: true},
+		{Symbol: "AAPL", MaxPoints: 1},
+		{Symbol: "AAPL", Start: now.AddDate(0, 0, 2)},
+		{Symbol: "AAPL", Start: now.AddDate(0, 0, -2), End: now.AddDate(0, 0, -5)},
+	} {
+		if _, err := s.GetPriceHistory(invalid); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+}
+
+func TestDownsamplePrices(t *testing.T) {
+	now := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	prices := dailyPrices(now, 10)
+
+	merged := downsamplePrices(prices, 4, true)
+	// 3 prices per point
+	if len(merged) != 4 {
+		t.Fatalf("expected 4 points, got %d", len(merged))
+	}
+	first := merged[0]
+	if first.Open != 1 || first.ClosePrice != 3 || first.High != 4 || first.Low != 0.5 || first.Volume != 30 || !first.Date.Equal(prices[2].Date) {
+		t.Errorf("unexpected merged price %+v", first)
+	}
+	if last := merged[3]; last.ClosePrice != 10 || last.Open != 10 || last.Volume != 10 {
+		t.Errorf("unexpected last merged price %+v", last)
+	}
+
+	if merged := downsamplePrices(prices, 10, true); len(merged) != 10 {
+		t.Errorf("expected the prices to be kept, got %d", len(merged))
+	}
+}
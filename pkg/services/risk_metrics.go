@@ -0,0 +1,249 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/analytics/risk"
+	"market_data_mcp_server/pkg/domain"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRiskBenchmark  = "SPY"
+	defaultRiskConfidence = 0.95
+)
+
+type PriceHistorySource interface {
+	GetPriceHistory(query domain.PriceHistoryQuery) (domain.PriceHistory, error)
+}
+
+type TreasuryYieldSource interface {
+	GetTreasuryYieldTimeSeries(maturity domain.TreasuryYieldMaturity) (domain.EconomicIndicatorTimeSeries, error)
+}
+
+type PortfolioValuationSource interface {
+	GetPortfolioValuation(userID string, baseCurrency domain.Currency) (domain.PortfolioValuation, error)
+}
+
+// RiskMetricsQuery selects the symbol, or the portfolio of the user when UserID is set, whose risk is measured
+type RiskMetricsQuery struct {
+	Symbol     string
+	AssetClass domain.AssetClass
+	UserID     string
+	Benchmark  string // Symbol of an ETF, SPY by default
+	Period     domain.Period
+	Confidence float64 // Confidence of the value at risk, 0.95 by default
+}
+
+// RiskHolding is a holding of a portfolio whose risk is measured, with its weight in percent
+type RiskHolding struct {
+	Symbol     string
+	AssetClass domain.AssetClass
+	Weight     float64
+}
+
+type RiskMetricsResult struct {
+	Symbol     string
+	AssetClass domain.AssetClass
+	UserID     string
+	Benchmark  string
+	Period     domain.Period
+	Holdings   []RiskHolding            // The holdings of the portfolio the metrics are computed over
+	Excluded   []domain.UnpricedHolding // The holdings of the portfolio left out, e.g. without a price history
+	Warnings   []string
+	Metrics    risk.Metrics
+}
+
+// RiskMetricsService measures the risk and the return of the stocks, the ETFs, the cryptocurrencies and the user
+// portfolios over their price histories, against a benchmark ETF and the 3 month treasury yield as the risk-free
+// rate. A portfolio is measured as its current holdings held with their current weights over the period, the
+// returns of each holding in its own currency.
+type RiskMetricsService struct {
+	prices    PriceHistorySource
+	yields    TreasuryYieldSource
+	valuation PortfolioValuationSource
+}
+
+func NewRiskMetricsService(prices PriceHistorySource, yields TreasuryYieldSource, valuation PortfolioValuationSource) (*RiskMetricsService, error) {
+	return &RiskMetricsService{
+		prices:    prices,
+		yields:    yields,
+		valuation: valuation,
+	}, nil
+}
+
+func (s *RiskMetricsService) GetRiskMetrics(query RiskMetricsQuery) (RiskMetricsResult, error) {
+	if query.Symbol == "" && query.UserID == "" {
+		return RiskMetricsResult{}, fmt.Errorf("symbol or user_id is required")
+	}
+	if query.Symbol != "" && query.UserID != "" {
+		return RiskMetricsResult{}, fmt.Errorf("symbol and user_id can't be both set")
+	}
+	period := cmp.Or(query.Period, domain.Period1Y)
+	switch period {
+	case domain.Period1M, domain.PeriodYTD, domain.Period6M, domain.Period1Y, domain.Period5Y, domain.PeriodMax:
+	default:
+		return RiskMetricsResult{}, fmt.Errorf("period valid values are: 1m, ytd, 6m, 1y, 5y, max")
+	}
+	confidence := cmp.Or(query.Confidence, defaultRiskConfidence)
+	if confidence < 0.5 || confidence >= 1 {
+		return RiskMetricsResult{}, fmt.Errorf("confidence must be between 0.5 and 1")
+	}
+
+	result := RiskMetricsResult{
+		UserID:    query.UserID,
+		Benchmark: strings.ToUpper(cmp.Or(strings.TrimSpace(query.Benchmark), defaultRiskBenchmark)),
+		Period:    period,
+	}
+
+	var prices []domain.Price
+	if query.UserID != "" {
+		var err error
+		if prices, err = s.portfolioPrices(query.UserID, period, &result); err != nil {
+			return RiskMetricsResult{}, err
+		}
+	} else {
+		history, err := s.history(query.Symbol, query.AssetClass, period)
+		if err != nil {
+			return RiskMetricsResult{}, err
+		}
+		result.Symbol, result.AssetClass, prices = history.Symbol, history.AssetClass, history.Prices
+	}
+
+	benchmark, err := s.history(result.Benchmark, domain.ETF, period)
+	if err != nil {
+		return RiskMetricsResult{}, fmt.Errorf("failed to get the prices of the benchmark %s: %w", result.Benchmark, err)
+	}
+
+	riskFreeRate, err := s.riskFreeRate(prices[0].Date)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("the risk-free rate is 0, failed to get the 3 month treasury yield: %v", err))
+	}
+
+	result.Metrics, err = risk.Compute(prices, benchmark.Prices, riskFreeRate, confidence)
+	if err != nil {
+		return RiskMetricsResult{}, err
+	}
+	return result, nil
+}
+
+func (s *RiskMetricsService) history(symbol string, assetClass domain.AssetClass, period domain.Period) (domain.PriceHistory, error) {
+	return s.prices.GetPriceHistory(domain.PriceHistoryQuery{
+		Symbol:     symbol,
+		AssetClass: assetClass,
+		Period:     period,
+		MaxPoints:  maxPriceHistoryPoints,
+	})
+}
+
+// portfolioPrices returns the value of the portfolio of the user over the period, from the price histories of its
+// holdings. The cash earns nothing, and the holdings without a price history are left out.
+func (s *RiskMetricsService) portfolioPrices(userID string, period domain.Period, result *RiskMetricsResult) ([]domain.Price, error) {
+	valuation, err := s.valuation.GetPortfolioValuation(userID, domain.USD)
+	if err != nil {
+		return nil, err
+	}
+	result.Excluded = append(result.Excluded, valuation.Unpriced...)
+
+	histories := make([][]domain.Price, len(valuation.Holdings))
+	errs := make([]error, len(valuation.Holdings))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrentPriceRequests)
+	for i, holding := range valuation.Holdings {
+		switch holding.Holding.AssetClass {
+		case domain.Cash:
+			continue
+		case domain.Stock, domain.ETF, domain.Crypto:
+		default:
+			errs[i] = fmt.Errorf("no price history for the asset class %s", holding.Holding.AssetClass)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			history, err := s.history(holding.Holding.Symbol, holding.Holding.AssetClass, period)
+			histories[i], errs[i] = history.Prices, err
+		}()
+	}
+	wg.Wait()
+
+	var series [][]domain.Price
+	var weights []float64
+	var totalWeight float64
+	for i, holding := range valuation.Holdings {
+		if errs[i] != nil {
+			result.Excluded = append(result.Excluded, domain.UnpricedHolding{Holding: holding.Holding, Reason: errs[i].Error()})
+			continue
+		}
+		totalWeight += holding.Weight
+		result.Holdings = append(result.Holdings, RiskHolding{
+			Symbol:     strings.ToUpper(holding.Holding.Symbol),
+			AssetClass: holding.Holding.AssetClass,
+			Weight:     holding.Weight,
+		})
+		if holding.Holding.AssetClass != domain.Cash {
+			series = append(series, histories[i])
+			weights = append(weights, holding.Weight)
+		}
+	}
+	if len(series) == 0 || totalWeight <= 0 {
+		return nil, fmt.Errorf("the portfolio of %s has no holding with a price history", userID)
+	}
+
+	// The weights of the holdings left out are spread over the rest
+	for i := range weights {
+		weights[i] /= totalWeight
+	}
+	for i := range result.Holdings {
+		result.Holdings[i].Weight = result.Holdings[i].Weight / totalWeight * 100
+	}
+
+	return risk.WeightedPortfolio(series, weights)
+}
+
+// riskFreeRate returns the mean of the monthly 3 month treasury yields since the month of the start, the last one
+// when there are none
+func (s *RiskMetricsService) riskFreeRate(start time.Time) (float64, error) {
+	timeSeries, err := s.yields.GetTreasuryYieldTimeSeries(domain.ThreeMonthTreasuryYieldMaturity)
+	if err != nil {
+		return 0, err
+	}
+
+	month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	var sum, last float64
+	var count int
+	var lastDate time.Time
+	for _, entry := range timeSeries.Data {
+		date, err := time.Parse(time.DateOnly, entry.Date)
+		if err != nil {
+			continue
+		}
+		value, err := strconv.ParseFloat(entry.Value, 64)
+		if err != nil {
+			// Missing values are "."
+			continue
+		}
+		if date.After(lastDate) {
+			last, lastDate = value, date
+		}
+		if !date.Before(month) {
+			sum += value
+			count++
+		}
+	}
+
+	if lastDate.IsZero() {
+		return 0, fmt.Errorf("no treasury yields found")
+	}
+	if count == 0 {
+		return last / 100, nil
+	}
+	return sum / float64(count) / 100, nil
+}
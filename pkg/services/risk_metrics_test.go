@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubPriceHistories map[string][]domain.Price
+
+func (s stubPriceHistories) GetPriceHistory(query domain.PriceHistoryQuery) (domain.PriceHistory, error) {
+	prices, ok := s[strings.ToUpper(query.Symbol)]
+	if !ok {
+		return domain.PriceHistory{}, fmt.Errorf("no prices found for %s", query.Symbol)
+	}
+	return domain.PriceHistory{Symbol: strings.ToUpper(query.Symbol), AssetClass: query.AssetClass, Prices: prices}, nil
+}
+
+type stubYields []domain.EconomicIndicatorTimeSeriesEntry
+
+func (s stubYields) GetTreasuryYieldTimeSeries(maturity domain.TreasuryYieldMaturity) (domain.EconomicIndicatorTimeSeries, error) {
+	if s == nil {
+		return domain.EconomicIndicatorTimeSeries{}, fmt.Errorf("rate limited")
+	}
+	return domain.EconomicIndicatorTimeSeries{Data: s}, nil
+}
+
+type stubValuation domain.PortfolioValuation
+
+func (s stubValuation) GetPortfolioValuation(userID string, baseCurrency domain.Currency) (domain.PortfolioValuation, error) {
+	return domain.PortfolioValuation(s), nil
+}
+
+func riskPrices(start time.Time, closes ...float64) []domain.Price {
+	prices := make([]domain.Price, len(closes))
+	for i, c := range closes {
+		prices[i] = domain.Price{Date: start.AddDate(0, 0, i), ClosePrice: c}
+	}
+	return prices
+}
+
+func TestGetRiskMetrics(t *testing.T) {
+	start := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	histories := stubPriceHistories{
+		"SPY":  riskPrices(start, 100, 101, 99, 102, 100, 103),
+		"AAPL": riskPrices(start, 100, 102, 98, 104, 100, 106),
+	}
+	yields := stubYields{
+		{Date: "2025-04-01", Value: "4.2"},
+		{Date: "2025-03-01", Value: "4.4"},
+		{Date: "2025-02-01", Value: "."},
+		{Date: "2025-01-01", Value: "4.6"},
+	}
+	s, _ := NewRiskMetricsService(histories, yields, nil)
+
+	result, err := s.GetRiskMetrics(RiskMetricsQuery{Symbol: "aapl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Symbol != "AAPL" || result.Benchmark != "SPY" || result.Period != domain.Period1Y || len(result.Warnings) != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	// The yields of March and April
+	if math.Abs(result.Metrics.RiskFreeRate-0.043) > 1e-9 || result.Metrics.Beta <= 1 || result.Metrics.Confidence != 0.95 {
+		t.Errorf("unexpected metrics %+v", result.Metrics)
+	}
+
+	s.yields = stubYields(nil)
+	if result, _ := s.GetRiskMetrics(RiskMetricsQuery{Symbol: "AAPL"}); len(result.Warnings) != 1 || result.Metrics.RiskFreeRate != 0 {
+		t.Errorf("expected a warning and no risk-free rate, got %+v", result)
+	}
+
+	for _, invalid := range []RiskMetricsQuery{
+		{},
+		{Symbol: "AAPL", UserID: "u"},
+		{Symbol: "AAPL", Period: domain.Period1D},
+		{Symbol: "AAPL", Confidence: 1},
+		{Symbol: "AAPL", Benchmark: "QQQ"},
+	} {
+		if _, err := s.GetRiskMetrics(invalid); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+}
+
+func TestGetPortfolioRiskMetrics(t *testing.T) {
+	start := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	histories := stubPriceHistories{
+		"SPY":  riskPrices(start, 100, 101, 99, 102, 100, 103),
+		"AAPL": riskPrices(start, 100, 110, 121, 133.1, 146.41, 161.051),
+	}
+	valuation := stubValuation{
+		Holdings: []domain.HoldingValuation{
+			{Holding: domain.UserPortfolioHolding{AssetClass: domain.Stock, Symbol: "aapl"}, Weight: 40},
+			{Holding: domain.UserPortfolioHolding{AssetClass: domain.Cash, Symbol: "USD"}, Weight: 40},
+			{Holding: domain.UserPortfolioHolding{AssetClass: domain.Stock, Symbol: "DELISTED"}, Weight: 10},
+			{Holding: domain.UserPortfolioHolding{AssetClass: domain.RealEstate, Name: "House"}, Weight: 10},
+		},
+		Unpriced: []domain.UnpricedHolding{{Holding: domain.UserPortfolioHolding{Symbol: "MSFT"}, Reason: "no price"}},
+	}
+	s, _ := NewRiskMetricsService(histories, stubYields{{Date: "2025-03-01", Value: "4"}}, valuation)
+
+	result, err := s.GetRiskMetrics(RiskMetricsQuery{UserID: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Holdings) != 2 || result.Holdings[0].Weight != 50 || len(result.Excluded) != 3 {
+		t.Errorf("unexpected holdings %+v, excluded %+v", result.Holdings, result.Excluded)
+	}
+	// Half in a stock rising 10% a day, half in cash
+	if math.Abs(result.Metrics.TotalReturn-(math.Pow(1.05, 5)-1)) > 1e-9 || result.Metrics.MaxDrawdown.Depth != 0 {
+		t.Errorf("unexpected metrics %+v", result.Metrics)
+	}
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	minComparedStocks = 2
+	maxComparedStocks = 10
+)
+
+type StockComparisonDataService interface {
+	GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error)
+	GetStockForecast(symbol string) (domain.StockForecast, error)
+	GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error)
+}
+
+// comparisonData is the data of a compared stock, the parts not fetched are nil
+type comparisonData struct {
+	ratios    *domain.FinancialRatios // Trailing twelve months
+	forecast  *domain.StockForecast
+	changes   map[domain.Period]float64
+	lastClose float64
+}
+
+// comparisonMetric is a metric of a comparison. The values are missing when their data is, and not ranked when
+// not meaningful (e.g. a negative P/E).
+type comparisonMetric struct {
+	name           string
+	set            domain.MetricSet
+	unit           domain.MetricUnit
+	higherIsBetter bool
+	value          func(d comparisonData, now time.Time) (float64, bool)
+	ranked         func(value float64) bool
+}
+
+func positive(value float64) bool    { return value > 0 }
+func nonNegative(value float64) bool { return value >= 0 }
+
+func ratioMetric(name string, set domain.MetricSet, unit domain.MetricUnit, higherIsBetter bool, ranked func(float64) bool, ratio func(r domain.FinancialRatios) float64) comparisonMetric {
+	return comparisonMetric{
+		name:           name,
+		set:            set,
+		unit:           unit,
+		higherIsBetter: higherIsBetter,
+		ranked:         ranked,
+		value: func(d comparisonData, now time.Time) (float64, bool) {
+			if d.ratios == nil {
+				return 0, false
+			}
+			return ratio(*d.ratios), true
+		},
+	}
+}
+
+func changeMetric(name string, period domain.Period) comparisonMetric {
+	return comparisonMetric{
+		name:           name,
+		set:            domain.PerformanceMetrics,
+		unit:           domain.PercentMetricUnit,
+		higherIsBetter: true,
+		value: func(d comparisonData, now time.Time) (float64, bool) {
+			change, ok := d.changes[period]
+			return change, ok
+		},
+	}
+}
+
+// estimateMetric is a growth of the estimations of the next fiscal quarter, year over year
+func estimateMetric(name string, growth func(e domain.StockEstimation) float64) comparisonMetric {
+	return comparisonMetric{
+		name:           name,
+		set:            domain.GrowthMetrics,
+		unit:           domain.PercentMetricUnit,
+		higherIsBetter: true,
+		value: func(d comparisonData, now time.Time) (float64, bool) {
+			if d.forecast == nil {
+				return 0, false
+			}
+			for _, estimation := range d.forecast.Estimations {
+				if date, err := time.Parse(time.DateOnly, estimation.Date); err == nil && date.After(now) {
+					return growth(estimation), true
+				}
+			}
+			return 0, false
+		},
+	}
+}
+
+// comparisonMetrics are the metrics of the comparisons in their order, the yields, the returns and the growths in
+// percent
+var comparisonMetrics = []comparisonMetric{
+	ratioMetric("market_cap", domain.ValuationMetrics, domain.UsdMetricUnit, true, nil, func(r domain.FinancialRatios) float64 { return r.Marketcap }),
+	ratioMetric("pe", domain.ValuationMetrics, domain.RatioMetricUnit, false, positive, func(r domain.FinancialRatios) float64 { return r.Pe }),
+	ratioMetric("ps", domain.ValuationMetrics, domain.RatioMetricUnit, false, positive, func(r domain.FinancialRatios) float64 { return r.Ps }),
+	ratioMetric("pb", domain.ValuationMetrics, domain.RatioMetricUnit, false, positive, func(r domain.FinancialRatios) float64 { return r.Pb }),
+	ratioMetric("pfcf", domain.ValuationMetrics, domain.RatioMetricUnit, false, positive, func(r domain.FinancialRatios) float64 { return r.Pfcf }),
+	ratioMetric("ev_ebitda", domain.ValuationMetrics, domain.RatioMetricUnit, false, positive, func(r domain.FinancialRatios) float64 { return r.EvEbitda }),
+	ratioMetric("ev_revenue", domain.ValuationMetrics, domain.RatioMetricUnit, false, positive, func(r domain.FinancialRatios) float64 { return r.EvRevenue }),
+	ratioMetric("fcf_yield", domain.ValuationMetrics, domain.PercentMetricUnit, true, nil, func(r domain.FinancialRatios) float64 { return r.FcfYield * 100 }),
+	ratioMetric("dividend_yield", domain.ValuationMetrics, domain.PercentMetricUnit, true, nil, func(r domain.FinancialRatios) float64 { return r.DividendYield * 100 }),
+	{
+		name:           "target_price_upside",
+		set:            domain.ValuationMetrics,
+		unit:           domain.PercentMetricUnit,
+		higherIsBetter: true,
+		value: func(d comparisonData, now time.Time) (float64, bool) {
+			if d.forecast == nil || d.forecast.TargetPrice.Average == 0 || d.lastClose == 0 {
+				return 0, false
+			}
+			return (float64(d.forecast.TargetPrice.Average)/d.lastClose - 1) * 100, true
+		},
+	},
+	ratioMetric("roe", domain.ProfitabilityMetrics, domain.PercentMetricUnit, true, nil, func(r domain.FinancialRatios) float64 { return r.Roe * 100 }),
+	ratioMetric("roa", domain.ProfitabilityMetrics, domain.PercentMetricUnit, true, nil, func(r domain.FinancialRatios) float64 { return r.Roa * 100 }),
+	ratioMetric("roic", domain.ProfitabilityMetrics, domain.PercentMetricUnit, true, nil, func(r domain.FinancialRatios) float64 { return r.Roic * 100 }),
+	ratioMetric("asset_turnover", domain.ProfitabilityMetrics, domain.RatioMetricUnit, true, nil, func(r domain.FinancialRatios) float64 { return r.AssetTurnover }),
+	ratioMetric("market_cap_growth", domain.GrowthMetrics, domain.PercentMetricUnit, true, nil, func(r domain.FinancialRatios) float64 { return r.MarketCapGrowth * 100 }),
+	estimateMetric("revenue_growth_next_quarter", func(e domain.StockEstimation) float64 { return e.RevenueGrowth }),
+	estimateMetric("eps_growth_next_quarter", func(e domain.StockEstimation) float64 { return e.EpsGrowth }),
+	ratioMetric("debt_equity", domain.LeverageMetrics, domain.RatioMetricUnit, false, nonNegative, func(r domain.FinancialRatios) float64 { return r.DebtEquity }),
+	ratioMetric("debt_ebitda", domain.LeverageMetrics, domain.RatioMetricUnit, false, nonNegative, func(r domain.FinancialRatios) float64 { return r.DebtEbitda }),
+	ratioMetric("debt_fcf", domain.LeverageMetrics, domain.RatioMetricUnit, false, nonNegative, func(r domain.FinancialRatios) float64 { return r.DebtFcf }),
+	ratioMetric("current_ratio", domain.LeverageMetrics, domain.RatioMetricUnit, true, nil, func(r domain.FinancialRatios) float64 { return r.CurrentRatio }),
+	ratioMetric("quick_ratio", domain.LeverageMetrics, domain.RatioMetricUnit, true, nil, func(r domain.FinancialRatios) float64 { return r.QuickRatio }),
+	changeMetric("change_1m", domain.Period1M),
+	changeMetric("change_ytd", domain.PeriodYTD),
+	changeMetric("change_1y", domain.Period1Y),
+}
+
+// metricSets are the sets of the comparisons in their order
+var metricSets = []domain.MetricSet{
+	domain.ValuationMetrics, domain.ProfitabilityMetrics, domain.GrowthMetrics, domain.LeverageMetrics, domain.PerformanceMetrics,
+}
+
+// StockComparisonService compares the ratios, the estimations and the performance of stocks side by side
+type StockComparisonService struct {
+	data StockComparisonDataService
+	now  func() time.Time
+}
+
+func NewStockComparisonService(data StockComparisonDataService) (*StockComparisonService, error) {
+	return &StockComparisonService{
+		data: data,
+		now:  time.Now,
+	}, nil
+}
+
+// CompareStocks returns the metrics of the sets, all of them when none is given, for each stock with their ranks.
+// The stocks are fetched concurrently, at most maxConcurrentPriceRequests at a time, and the data that can't be
+// fetched is reported per stock.
+func (s *StockComparisonService) CompareStocks(symbols []string, sets []domain.MetricSet) (domain.StockComparison, error) {
+	var unique []string
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol != "" && !slices.Contains(unique, symbol) {
+			unique = append(unique, symbol)
+		}
+	}
+	if len(unique) < minComparedStocks || len(unique) > maxComparedStocks {
+		return domain.StockComparison{}, fmt.Errorf("between %d and %d different symbols are required", minComparedStocks, maxComparedStocks)
+	}
+	if len(sets) == 0 {
+		sets = metricSets
+	}
+	for _, set := range sets {
+		if !slices.Contains(metricSets, set) {
+			return domain.StockComparison{}, fmt.Errorf("metric_sets valid values are: valuation, profitability, growth, leverage, performance")
+		}
+	}
+
+	data := make([]comparisonData, len(unique))
+	errs := make([][]string, len(unique))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrentPriceRequests)
+	for i, symbol := range unique {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			data[i], errs[i] = s.fetch(symbol, sets)
+		}()
+	}
+	wg.Wait()
+
+	comparison := domain.StockComparison{Symbols: unique}
+	for i, symbol := range unique {
+		for _, err := range errs[i] {
+			comparison.Errors = append(comparison.Errors, domain.ComparisonError{Symbol: symbol, Error: err})
+		}
+	}
+
+	now := s.now()
+	for _, set := range metricSets {
+		if !slices.Contains(sets, set) {
+			continue
+		}
+		for _, metric := range comparisonMetrics {
+			if metric.set == set {
+				comparison.Metrics = append(comparison.Metrics, compareMetric(metric, unique, data, now))
+			}
+		}
+	}
+
+	return comparison, nil
+}
+
+// fetch fetches the data of the stock needed by the sets, and the errors of the data that couldn't be fetched
+func (s *StockComparisonService) fetch(symbol string, sets []domain.MetricSet) (comparisonData, []string) {
+	// Lowercase like the other tools, so that they share the cached data
+	symbol = strings.ToLower(symbol)
+	needs := func(needed ...domain.MetricSet) bool {
+		return slices.ContainsFunc(sets, func(set domain.MetricSet) bool { return slices.Contains(needed, set) })
+	}
+
+	var data comparisonData
+	var errs []string
+	if needs(domain.ValuationMetrics, domain.ProfitabilityMetrics, domain.GrowthMetrics, domain.LeverageMetrics) {
+		ratios, err := s.data.GetFinancialRatios(symbol)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to get the financial ratios: %v", err))
+		} else if len(ratios) > 0 {
+			// The first ratios are the trailing twelve months ones
+			data.ratios = &ratios[0]
+		}
+	}
+
+	if needs(domain.ValuationMetrics, domain.GrowthMetrics) {
+		forecast, err := s.data.GetStockForecast(symbol)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to get the forecast: %v", err))
+		} else {
+			data.forecast = &forecast
+		}
+	}
+
+	var periods []domain.Period
+	if needs(domain.ValuationMetrics) {
+		// The last close of the target price upside
+		periods = append(periods, domain.Period1M)
+	}
+	if needs(domain.PerformanceMetrics) {
+		periods = []domain.Period{domain.Period1M, domain.PeriodYTD, domain.Period1Y}
+	}
+	data.changes = make(map[domain.Period]float64)
+	for _, period := range periods {
+		prices, err := s.data.GetHistoricalPrices(symbol, domain.Stock, period)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to get the %s prices: %v", period, err))
+			continue
+		}
+		if len(prices.Prices) == 0 {
+			continue
+		}
+		data.changes[period] = prices.PercentageChange
+		data.lastClose = cmp.Or(data.lastClose, prices.Prices[len(prices.Prices)-1].ClosePrice)
+	}
+
+	return data, errs
+}
+
+// compareMetric ranks the values of the metric, with the same rank for the equal values
+func compareMetric(metric comparisonMetric, symbols []string, data []comparisonData, now time.Time) domain.ComparedMetric {
+	compared := domain.ComparedMetric{
+		Name:           metric.name,
+		Set:            metric.set,
+		Unit:           metric.unit,
+		HigherIsBetter: metric.higherIsBetter,
+		Values:         make([]domain.ComparedValue, len(symbols)),
+	}
+
+	var ranked []float64
+	for i, symbol := range symbols {
+		compared.Values[i].Symbol = symbol
+		value, ok := metric.value(data[i], now)
+		if !ok {
+			continue
+		}
+		compared.Values[i].Value = &value
+		if metric.ranked == nil || metric.ranked(value) {
+			ranked = append(ranked, value)
+		}
+	}
+	if len(ranked) == 0 {
+		return compared
+	}
+
+	slices.Sort(ranked)
+	low, high := ranked[0], ranked[len(ranked)-1]
+	median := ranked[len(ranked)/2]
+	if len(ranked)%2 == 0 {
+		median = (ranked[len(ranked)/2-1] + ranked[len(ranked)/2]) / 2
+	}
+	compared.Min, compared.Max, compared.Median = &low, &high, &median
+
+	for i := range compared.Values {
+		value := compared.Values[i].Value
+		if value == nil || (metric.ranked != nil && !metric.ranked(*value)) {
+			continue
+		}
+		// 1 plus the number of the better values
+		rank := 1
+		for _, other := range ranked {
+			if (metric.higherIsBetter && other > *value) || (!metric.higherIsBetter && other < *value) {
+				rank++
+			}
+		}
+		compared.Values[i].Rank = rank
+	}
+	return compared
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+type stubComparisonData map[string]domain.FinancialRatios
+
+func (s stubComparisonData) GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error) {
+	ratios, ok := s[symbol]
+	if !ok {
+		return nil, fmt.Errorf("no ratios found for %s", symbol)
+	}
+	return []domain.FinancialRatios{ratios}, nil
+}
+
+func (s stubComparisonData) GetStockForecast(symbol string) (domain.StockForecast, error) {
+	return domain.StockForecast{
+		Estimations: []domain.StockEstimation{
+			{Date: "2025-03-31", EpsGrowth: 5},
+			{Date: "2025-06-30", EpsGrowth: 10},
+		},
+		TargetPrice: domain.StockTargetPrc{Average: 120},
+	}, nil
+}
+
+func (s stubComparisonData) GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error) {
+	return domain.HistoricalPrices{Period: period, Prices: []domain.Price{{ClosePrice: 100}}, PercentageChange: float64(len(ticker))}, nil
+}
+
+func TestCompareStocks(t *testing.T) {
+	data := stubComparisonData{
+		"aapl": {Pe: 30, Roe: 1.5, DebtEquity: 2},
+		"msft": {Pe: 30, Roe: 0.3, DebtEquity: 0.5},
+		"intc": {Pe: -10, Roe: -0.05, DebtEquity: 0.5},
+	}
+	s, _ := NewStockComparisonService(data)
+	s.now = func() time.Time { return time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC) }
+
+	comparison, err := s.CompareStocks([]string{"aapl", " MSFT", "intc", "AAPL", "googl"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comparison.Symbols) != 4 || comparison.Symbols[0] != "AAPL" || len(comparison.Metrics) != len(comparisonMetrics) {
+		t.Fatalf("unexpected comparison %+v", comparison)
+	}
+	if len(comparison.Errors) != 1 || comparison.Errors[0].Symbol != "GOOGL" {
+		t.Errorf("expected an error for GOOGL, got %+v", comparison.Errors)
+	}
+
+	metrics := make(map[string]domain.ComparedMetric)
+	for _, metric := range comparison.Metrics {
+		metrics[metric.Name] = metric
+	}
+	ranks := func(name string) []int {
+		var ranks []int
+		for _, value := range metrics[name].Values {
+			ranks = append(ranks, value.Rank)
+		}
+		return ranks
+	}
+
+	// The negative P/E isn't ranked, the equal ones share their rank
+	if got := ranks("pe"); fmt.Sprint(got) != "[1 1 0 0]" || *metrics["pe"].Median != 30 {
+		t.Errorf("unexpected pe ranks %v, metric %+v", got, metrics["pe"])
+	}
+	if got := ranks("roe"); fmt.Sprint(got) != "[1 2 3 0]" || *metrics["roe"].Max != 150 || *metrics["roe"].Median != 30 {
+		t.Errorf("unexpected roe ranks %v, metric %+v", got, metrics["roe"])
+	}
+	if got := ranks("debt_equity"); fmt.Sprint(got) != "[3 1 1 0]" || *metrics["debt_equity"].Median != 0.5 {
+		t.Errorf("unexpected debt_equity ranks %v", got)
+	}
+	if value := metrics["eps_growth_next_quarter"].Values[0].Value; value == nil || *value != 10 {
+		t.Errorf("expected the growth of the next quarter, got %v", value)
+	}
+	if value := metrics["target_price_upside"].Values[3].Value; value == nil || math.Abs(*value-20) > 1e-9 {
+		t.Errorf("expected an upside of 20%%, got %v", value)
+	}
+	// The prices of GOOGL are still fetched without its ratios
+	if got := ranks("change_1y"); fmt.Sprint(got) != "[2 2 2 1]" {
+		t.Errorf("unexpected change_1y ranks %v", got)
+	}
+
+	comparison, _ = s.CompareStocks([]string{"AAPL", "MSFT"}, []domain.MetricSet{domain.LeverageMetrics})
+	if len(comparison.Metrics) != 5 || comparison.Metrics[0].Name != "debt_equity" {
+		t.Errorf("expected the leverage metrics, got %+v", comparison.Metrics)
+	}
+
+	for _, invalid := range [][]string{{"AAPL"}, {"AAPL", "aapl"}, {"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K"}} {
+		if _, err := s.CompareStocks(invalid, nil); err == nil {
+			t.Errorf("expected an error for %v", invalid)
+		}
+	}
+	if _, err := s.CompareStocks([]string{"AAPL", "MSFT"}, []domain.MetricSet{"unknown"}); err == nil {
+		t.Error("expected an error for an unknown metric set")
+	}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultScreenerSort  = "market_cap"
+	defaultScreenerLimit = 25
+	maxScreenerLimit     = 500
+)
+
+// defaultScreenerColumns are the fields returned when none are selected
+var defaultScreenerColumns = []string{"market_cap", "price", "pe", "dividend_yield", "sector", "industry"}
+
+type ScreenerDataService interface {
+	GetScreenerStocks(request domain.ScreenerRequest) ([]domain.ScreenedStock, error)
+}
+
+// StockScreenerService screens the stocks with the stockanalysis screener. The filters the screener supports are
+// pushed to it and the others are applied to the stocks it returns.
+type StockScreenerService struct {
+	data ScreenerDataService
+}
+
+func NewStockScreenerService(data ScreenerDataService) (*StockScreenerService, error) {
+	return &StockScreenerService{
+		data: data,
+	}, nil
+}
+
+func (s *StockScreenerService) ScreenStocks(query domain.ScreenerQuery) (domain.ScreenerResult, error) {
+	if err := validateScreenerQuery(&query); err != nil {
+		return domain.ScreenerResult{}, err
+	}
+
+	var result domain.ScreenerResult
+	request := domain.ScreenerRequest{
+		Columns:    slices.Clone(query.Columns),
+		Sort:       query.Sort,
+		Descending: query.Descending,
+	}
+	for _, filter := range query.Filters {
+		if pushableScreenerFilter(filter) {
+			request.Filters = append(request.Filters, filter)
+			result.Pushed = append(result.Pushed, filter)
+			continue
+		}
+		result.Local = append(result.Local, filter)
+		// The fields of the local filters are needed to apply them
+		if !slices.Contains(request.Columns, filter.Field) {
+			request.Columns = append(request.Columns, filter.Field)
+		}
+	}
+	if !slices.Contains(request.Columns, query.Sort) {
+		request.Columns = append(request.Columns, query.Sort)
+	}
+
+	stocks, err := s.data.GetScreenerStocks(request)
+	if err != nil {
+		return domain.ScreenerResult{}, fmt.Errorf("failed to screen the stocks: %w", err)
+	}
+
+	var matches []domain.ScreenedStock
+	for _, stock := range stocks {
+		if !slices.ContainsFunc(result.Local, func(filter domain.ScreenerFilter) bool { return !matchesScreenerFilter(stock, filter) }) {
+			matches = append(matches, stock)
+		}
+	}
+
+	// The order of the screener is kept for the equal values, the stocks without a value are last
+	slices.SortStableFunc(matches, func(a, b domain.ScreenedStock) int {
+		x, xOk := a.Values[query.Sort]
+		y, yOk := b.Values[query.Sort]
+		if !xOk || !yOk {
+			return compareMissing(xOk, yOk)
+		}
+		c := compareScreenerValues(x, y)
+		if query.Descending {
+			return -c
+		}
+		return c
+	})
+
+	result.Matches = len(matches)
+	result.Stocks = matches[:min(len(matches), query.Limit)]
+	for i, stock := range result.Stocks {
+		// Only the selected fields are returned
+		values := make(map[string]any, len(query.Columns))
+		for _, column := range query.Columns {
+			if value, ok := stock.Values[column]; ok {
+				values[column] = value
+			}
+		}
+		result.Stocks[i].Values = values
+	}
+
+	return result, nil
+}
+
+// validateScreenerQuery checks the fields and the operators of the query against the catalogue, and sets the
+// defaults
+func validateScreenerQuery(query *domain.ScreenerQuery) error {
+	query.Filters = slices.Clone(query.Filters)
+	for i, filter := range query.Filters {
+		field, ok := domain.LookupScreenerField(filter.Field)
+		if !ok {
+			return fmt.Errorf("unknown filter field %s, valid fields are: %s", filter.Field, screenerFieldNames())
+		}
+		switch filter.Operator {
+		case domain.GreaterThanScreenerOperator, domain.GreaterThanOrEqualScreenerOperator, domain.LessThanScreenerOperator,
+			domain.LessThanOrEqualScreenerOperator, domain.BetweenScreenerOperator:
+			if field.Type != domain.NumberScreenerField {
+				return fmt.Errorf("the operator %s needs a number field, %s is a text", filter.Operator, field.Name)
+			}
+			if filter.Operator == domain.BetweenScreenerOperator && filter.Upper < filter.Value {
+				return fmt.Errorf("the upper bound of the %s between filter is below its value", field.Name)
+			}
+		case domain.EqualScreenerOperator, domain.NotEqualScreenerOperator, domain.ContainsScreenerOperator:
+			if field.Type != domain.TextScreenerField {
+				return fmt.Errorf("the operator %s needs a text field, %s is a number", filter.Operator, field.Name)
+			}
+			if len(filter.Texts) != 1 || strings.TrimSpace(filter.Texts[0]) == "" {
+				return fmt.Errorf("the %s %s filter needs a text", field.Name, filter.Operator)
+			}
+		case domain.InScreenerOperator:
+			if field.Type != domain.TextScreenerField {
+				return fmt.Errorf("the operator %s needs a text field, %s is a number", filter.Operator, field.Name)
+			}
+			if len(filter.Texts) == 0 {
+				return fmt.Errorf("the %s in filter needs texts", field.Name)
+			}
+		default:
+			return fmt.Errorf("unknown operator %s, valid operators are: gt, gte, lt, lte, between, eq, neq, in, contains", filter.Operator)
+		}
+		query.Filters[i].Texts = make([]string, len(filter.Texts))
+		for j, text := range filter.Texts {
+			query.Filters[i].Texts[j] = strings.TrimSpace(text)
+		}
+	}
+
+	query.Sort = cmp.Or(query.Sort, defaultScreenerSort)
+	if _, ok := domain.LookupScreenerField(query.Sort); !ok {
+		return fmt.Errorf("unknown sort field %s, valid fields are: %s", query.Sort, screenerFieldNames())
+	}
+
+	query.Limit = cmp.Or(query.Limit, defaultScreenerLimit)
+	if query.Limit < 1 || query.Limit > maxScreenerLimit {
+		return fmt.Errorf("limit must be between 1 and %d", maxScreenerLimit)
+	}
+
+	if len(query.Columns) == 0 {
+		query.Columns = defaultScreenerColumns
+	}
+	var columns []string
+	for _, column := range query.Columns {
+		if _, ok := domain.LookupScreenerField(column); !ok {
+			return fmt.Errorf("unknown column %s, valid fields are: %s", column, screenerFieldNames())
+		}
+		// The symbol and the name are always returned
+		if column != "symbol" && column != "name" && !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	query.Columns = columns
+
+	return nil
+}
+
+func screenerFieldNames() string {
+	names := make([]string, len(domain.ScreenerFields))
+	for i, field := range domain.ScreenerFields {
+		names[i] = field.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// pushableScreenerFilter returns whether the screener applies the filter: the filterable fields with the
+// operators over (gt), under (lt) and is (eq), and texts without the commas separating its filters
+func pushableScreenerFilter(filter domain.ScreenerFilter) bool {
+	field, _ := domain.LookupScreenerField(filter.Field)
+	if !field.Filterable {
+		return false
+	}
+	switch filter.Operator {
+	case domain.GreaterThanScreenerOperator, domain.LessThanScreenerOperator:
+		return true
+	case domain.EqualScreenerOperator:
+		return !strings.Contains(filter.Texts[0], ",")
+	default:
+		return false
+	}
+}
+
+// matchesScreenerFilter returns whether the stock matches the filter, the stocks without a value never match
+func matchesScreenerFilter(stock domain.ScreenedStock, filter domain.ScreenerFilter) bool {
+	switch value := stock.Values[filter.Field].(type) {
+	case float64:
+		switch filter.Operator {
+		case domain.GreaterThanScreenerOperator:
+			return value > filter.Value
+		case domain.GreaterThanOrEqualScreenerOperator:
+			return value >= filter.Value
+		case domain.LessThanScreenerOperator:
+			return value < filter.Value
+		case domain.LessThanOrEqualScreenerOperator:
+			return value <= filter.Value
+		case domain.BetweenScreenerOperator:
+			return value >= filter.Value && value <= filter.Upper
+		}
+	case string:
+		switch filter.Operator {
+		case domain.EqualScreenerOperator:
+			return strings.EqualFold(value, filter.Texts[0])
+		case domain.NotEqualScreenerOperator:
+			return !strings.EqualFold(value, filter.Texts[0])
+		case domain.InScreenerOperator:
+			return slices.ContainsFunc(filter.Texts, func(text string) bool { return strings.EqualFold(value, text) })
+		case domain.ContainsScreenerOperator:
+			return strings.Contains(strings.ToLower(value), strings.ToLower(filter.Texts[0]))
+		}
+	}
+	return false
+}
+
+func compareScreenerValues(x, y any) int {
+	if a, ok := x.(float64); ok {
+		b, _ := y.(float64)
+		return cmp.Compare(a, b)
+	}
+	a, _ := x.(string)
+	b, _ := y.(string)
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// compareMissing orders the missing values last
+func compareMissing(xOk, yOk bool) int {
+	switch {
+	case xOk == yOk:
+		return 0
+	case xOk:
+		return -1
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"market_data_mcp_server/pkg/domain"
+	"testing"
+)
+
+// stubScreener returns its stocks whatever the filters, and records the last request
+type stubScreener struct {
+	stocks  []domain.ScreenedStock
+	request domain.ScreenerRequest
+}
+
+func (s *stubScreener) GetScreenerStocks(request domain.ScreenerRequest) ([]domain.ScreenedStock, error) {
+	s.request = request
+	return s.stocks, nil
+}
+
+func TestScreenStocks(t *testing.T) {
+	screener := &stubScreener{stocks: []domain.ScreenedStock{
+		{Symbol: "AAPL", Name: "Apple Inc.", Values: map[string]any{"market_cap": 3.7e12, "gross_margin": 46.7, "industry": "Consumer Electronics"}},
+		{Symbol: "NVDA", Name: "NVIDIA Corporation", Values: map[string]any{"market_cap": 4.4e12, "gross_margin": 69.8, "industry": "Semiconductors"}},
+		{Symbol: "INTC", Name: "Intel Corporation", Values: map[string]any{"market_cap": 1.6e11, "gross_margin": 33.3, "industry": "Semiconductors"}},
+		{Symbol: "AMD", Name: "Advanced Micro Devices, Inc.", Values: map[string]any{"market_cap": 3.8e11, "industry": "Semiconductors"}},
+	}}
+	s, _ := NewStockScreenerService(screener)
+
+	result, err := s.ScreenStocks(domain.ScreenerQuery{
+		Filters: []domain.ScreenerFilter{
+			{Field: "market_cap", Operator: domain.GreaterThanScreenerOperator, Value: 1e11},
+			{Field: "sector", Operator: domain.EqualScreenerOperator, Texts: []string{" Technology "}},
+			{Field: "gross_margin", Operator: domain.GreaterThanScreenerOperator, Value: 40},
+			{Field: "industry", Operator: domain.InScreenerOperator, Texts: []string{"semiconductors", "Consumer Electronics"}},
+		},
+		Columns:    []string{"market_cap", "symbol"},
+		Descending: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The margins aren't filterable by the screener and it has no in operator
+	if len(result.Pushed) != 2 || len(result.Local) != 2 || screener.request.Filters[1].Texts[0] != "Technology" {
+		t.Errorf("unexpected pushed %+v and local %+v filters", result.Pushed, result.Local)
+	}
+	if got := screener.request.Columns; len(got) != 3 || got[1] != "gross_margin" || got[2] != "industry" {
+		t.Errorf("expected the fields of the local filters to be requested, got %v", got)
+	}
+	// AMD has no margin
+	if result.Matches != 2 || result.Stocks[0].Symbol != "NVDA" || result.Stocks[1].Symbol != "AAPL" {
+		t.Fatalf("unexpected stocks %+v", result.Stocks)
+	}
+	if len(result.Stocks[0].Values) != 1 || result.Stocks[0].Values["market_cap"] != 4.4e12 {
+		t.Errorf("expected the market cap only, got %v", result.Stocks[0].Values)
+	}
+
+	// The sort field is requested but only the default columns returned
+	result, _ = s.ScreenStocks(domain.ScreenerQuery{Sort: "gross_margin", Limit: 3})
+	if _, ok := result.Stocks[0].Values["gross_margin"]; result.Matches != 4 || len(result.Stocks) != 3 || result.Stocks[0].Symbol != "INTC" || ok {
+		t.Errorf("unexpected stocks %+v", result.Stocks)
+	}
+
+	for _, invalid := range []domain.ScreenerQuery{
+		{Filters: []domain.ScreenerFilter{{Field: "unknown", Operator: domain.GreaterThanScreenerOperator}}},
+		{Filters: []domain.ScreenerFilter{{Field: "sector", Operator: domain.GreaterThanScreenerOperator, Value: 1}}},
+		{Filters: []domain.ScreenerFilter{{Field: "pe", Operator: domain.EqualScreenerOperator, Texts: []string{"10"}}}},
+		{Filters: []domain.ScreenerFilter{{Field: "pe", Operator: domain.BetweenScreenerOperator, Value: 20, Upper: 10}}},
+		{Filters: []domain.ScreenerFilter{{Field: "sector", Operator: domain.EqualScreenerOperator}}},
+		{Filters: []domain.ScreenerFilter{{Field: "pe", Operator: "like"}}},
+		{Sort: "unknown"},
+		{Limit: 501},
+		{Columns: []string{"unknown"}},
+	} {
+		if _, err := s.ScreenStocks(invalid); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package services
 
 import (
 	"market_data_mcp_server/pkg/domain"
-	"strings"
+	"market_data_mcp_server/pkg/search"
 )
 
 type TickerDataService interface {
@@ -11,52 +11,51 @@ type TickerDataService interface {
 
 type TickerService struct {
 	dataService TickerDataService
+	index       *search.CachedIndex[domain.Ticker]
 }
 
 func NewTickerService(dataService TickerDataService) (*TickerService, error) {
 	return &TickerService{
 		dataService: dataService,
+		index:       search.NewCachedIndex(dataService.GetTickers, tickerDocument, searchIndexTtl),
 	}, nil
 }
 
 type TickerFilterOptions struct {
 	Limit        int
-	Page         int
+	Cursor       string
 	SearchString string
 }
 
 func (f TickerFilterOptions) IsEmpty() bool {
-	return f.Limit == 0 && f.Page == 0 && f.SearchString == ""
+	return f.Limit == 0 && f.Cursor == "" && f.SearchString == ""
 }
 
 func (f TickerFilterOptions) HasSearchString() bool {
 	return f.SearchString != ""
 }
 
-func (s TickerService) GetTickers(filters TickerFilterOptions) ([]domain.Ticker, error) {
-	// TODO: Implement the page and limit filtering
-	tickers, err := s.dataService.GetTickers()
+// GetTickers returns a page of the tickers that match the search string ranked by relevance.
+// Without a search string all the tickers are returned ordered by symbol.
+func (s TickerService) GetTickers(filters TickerFilterOptions) (Page[search.Result[domain.Ticker]], error) {
+	tickers, err := s.SearchTickers(filters.SearchString)
 	if err != nil {
-		return nil, err
+		return Page[search.Result[domain.Ticker]]{}, err
 	}
 
-	if filters.IsEmpty() {
-		return tickers, nil
-	}
+	return paginate(tickers, filters.SearchString, filters.Cursor, filters.Limit)
+}
 
-	if filters.HasSearchString() {
-		filteredTickers := make([]domain.Ticker, 0)
-		for _, t := range tickers {
-			search := strings.ToLower(filters.SearchString)
-			symbol := strings.ToLower(t.Symbol)
-			companyName := strings.ToLower(t.CompanyName)
-			if strings.Contains(symbol, search) || strings.Contains(companyName, search) {
-				filteredTickers = append(filteredTickers, t)
-			}
-
-		}
-		return filteredTickers, nil
+// SearchTickers returns all the tickers that match the query ranked by relevance
+func (s TickerService) SearchTickers(query string) ([]search.Result[domain.Ticker], error) {
+	index, err := s.index.Get()
+	if err != nil {
+		return nil, err
 	}
 
-	return tickers, nil
+	return index.Search(query), nil
+}
+
+func tickerDocument(t domain.Ticker) search.Document {
+	return search.Document{Symbol: t.Symbol, Name: t.CompanyName, Weight: t.MarketCap}
 }
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/errors"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TransactionLedgerLocalDataService stores the transaction ledgers of the users in a JSON file, or only in memory
+// when no file is given. A transaction is only recorded if the ledger stays consistent, e.g. a sale can't sell more
+// than the quantity held at its date.
+type TransactionLedgerLocalDataService struct {
+	dataPath string
+
+	mu           sync.Mutex
+	transactions map[string][]domain.Transaction
+}
+
+func NewTransactionLedgerLocalDataService(dataPath string) (*TransactionLedgerLocalDataService, error) {
+	s := &TransactionLedgerLocalDataService{
+		dataPath:     dataPath,
+		transactions: make(map[string][]domain.Transaction),
+	}
+	if dataPath == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(dataPath)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transactions file: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.transactions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transactions: %w", err)
+	}
+	if s.transactions == nil {
+		s.transactions = make(map[string][]domain.Transaction)
+	}
+
+	return s, nil
+}
+
+// GetTransactions returns the transactions of the user in the order they apply
+func (s *TransactionLedgerLocalDataService) GetTransactions(userID string) ([]domain.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transactions := slices.Clone(s.transactions[userID])
+	sortTransactions(transactions)
+	return transactions, nil
+}
+
+// AddTransaction records the transaction in the ledger of its user and returns it with its id
+func (s *TransactionLedgerLocalDataService) AddTransaction(transaction domain.Transaction) (domain.Transaction, error) {
+	if err := validateTransaction(&transaction); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.transactions[transaction.UserID]
+	transaction.ID = 1
+	for _, t := range current {
+		transaction.ID = max(transaction.ID, t.ID+1)
+	}
+	transaction.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	updated := append(slices.Clone(current), transaction)
+	if err := checkLedger(updated); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	s.transactions[transaction.UserID] = updated
+	if err := s.save(); err != nil {
+		s.transactions[transaction.UserID] = current
+		return domain.Transaction{}, err
+	}
+	return transaction, nil
+}
+
+// DeleteTransaction removes the transaction from the ledger of the user and returns it
+func (s *TransactionLedgerLocalDataService) DeleteTransaction(userID string, transactionID int) (domain.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.transactions[userID]
+	i := slices.IndexFunc(current, func(t domain.Transaction) bool { return t.ID == transactionID })
+	if i < 0 {
+		return domain.Transaction{}, errors.TransactionNotFoundError{UserID: userID, TransactionID: transactionID}
+	}
+
+	updated := slices.Delete(slices.Clone(current), i, i+1)
+	if err := checkLedger(updated); err != nil {
+		return domain.Transaction{}, fmt.Errorf("the transaction can't be deleted: %w", err)
+	}
+
+	s.transactions[userID] = updated
+	if err := s.save(); err != nil {
+		s.transactions[userID] = current
+		return domain.Transaction{}, err
+	}
+	return current[i], nil
+}
+
+func (s *TransactionLedgerLocalDataService) save() error {
+	if s.dataPath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(s.transactions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transactions: %w", err)
+	}
+	if err := writeFileAtomically(s.dataPath, data); err != nil {
+		return fmt.Errorf("failed to write transactions file: %w", err)
+	}
+	return nil
+}
+
+// validateTransaction checks the fields required by the type of the transaction and normalizes the symbol,
+// the currency (USD by default) and the date (a day)
+func validateTransaction(t *domain.Transaction) error {
+	if t.UserID == "" {
+		return fmt.Errorf("user_id is required")
+	}
+	if t.Date.IsZero() {
+		return fmt.Errorf("date is required")
+	}
+	t.Date = time.Date(t.Date.Year(), t.Date.Month(), t.Date.Day(), 0, 0, 0, 0, time.UTC)
+	if t.Date.After(time.Now().UTC()) {
+		return fmt.Errorf("date must not be in the future")
+	}
+
+	t.Symbol = strings.ToUpper(strings.TrimSpace(t.Symbol))
+	t.Currency = domain.Currency(strings.ToUpper(string(cmp.Or(t.Currency, domain.USD))))
+	if _, ok := domain.CurrencyCodeToNameMap[t.Currency]; !ok {
+		return fmt.Errorf("invalid currency: %s", t.Currency)
+	}
+	if t.Quantity < 0 || t.Price < 0 || t.Amount < 0 || t.Fees < 0 || t.SplitRatio < 0 {
+		return fmt.Errorf("quantity, price, amount, fees and split_ratio must be non-negative numbers")
+	}
+
+	switch t.Type {
+	case domain.TransactionBuy, domain.TransactionSell:
+		if t.Symbol == "" {
+			return fmt.Errorf("symbol is required for a %s", t.Type)
+		}
+		if t.Quantity == 0 {
+			return fmt.Errorf("quantity is required for a %s", t.Type)
+		}
+		if t.Price == 0 {
+			return fmt.Errorf("price is required for a %s", t.Type)
+		}
+		if t.AssetClass == "" {
+			t.AssetClass = domain.Stock
+		}
+		if t.AssetClass != domain.Stock && t.AssetClass != domain.ETF && t.AssetClass != domain.Crypto {
+			return fmt.Errorf("asset_class valid values for a %s are: stock, etf, crypto", t.Type)
+		}
+	case domain.TransactionDividend:
+		if t.Symbol == "" {
+			return fmt.Errorf("symbol is required for a dividend")
+		}
+		if t.Amount == 0 {
+			return fmt.Errorf("amount is required for a dividend")
+		}
+	case domain.TransactionSplit:
+		if t.Symbol == "" {
+			return fmt.Errorf("symbol is required for a split")
+		}
+		if t.SplitRatio == 0 {
+			return fmt.Errorf("split_ratio is required for a split")
+		}
+	case domain.TransactionDeposit, domain.TransactionWithdrawal, domain.TransactionFee:
+		if t.Amount == 0 {
+			return fmt.Errorf("amount is required for a %s", t.Type)
+		}
+	default:
+		return fmt.Errorf("type valid values are: buy, sell, dividend, split, deposit, withdrawal, fee")
+	}
+
+	return nil
+}
+
+// checkLedger replays the transactions to check that they are consistent. The cash is checked per currency, each
+// replay only counting the amounts of one currency, so that the check doesn't depend on the exchange rates.
+func checkLedger(transactions []domain.Transaction) error {
+	transactions = slices.Clone(transactions)
+	sortTransactions(transactions)
+
+	var currencies []domain.Currency
+	for _, t := range transactions {
+		if !slices.Contains(currencies, t.Currency) {
+			currencies = append(currencies, t.Currency)
+		}
+	}
+	for _, currency := range currencies {
+		only := func(amount float64, c domain.Currency) float64 {
+			if c != currency {
+				return 0
+			}
+			return amount
+		}
+		if _, err := replayLedger(transactions, domain.CostBasisFIFO, only, time.Time{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/search"
+	"slices"
+	"sync"
+)
+
+type TickersSearcher interface {
+	SearchTickers(query string) ([]search.Result[domain.Ticker], error)
+}
+
+type EtfsSearcher interface {
+	SearchEtfs(query string) ([]search.Result[domain.Etf], error)
+}
+
+type CryptocurrenciesSearcher interface {
+	SearchCryptocurrencies(query string) ([]search.Result[domain.Cryptocurrency], error)
+}
+
+type SuperInvestorsDataService interface {
+	GetSuperInvestors() ([]domain.SuperInvestor, error)
+}
+
+type SectorsDataService interface {
+	GetSectors() ([]domain.Sector, error)
+}
+
+type IndustriesDataService interface {
+	GetIndustries() ([]domain.Industry, error)
+}
+
+type InvestingIdeasDataService interface {
+	GetInvestingIdeas() ([]domain.InvestingIdea, error)
+}
+
+type UniversalSearchFilterOptions struct {
+	Query string
+	// Types limits the search to the given result types, all the types are searched when empty
+	Types []domain.SearchResultType
+	Limit int
+}
+
+type UniversalSearchResults struct {
+	Results []domain.SearchResult
+	// FailedSources are the result types whose source failed, the results of the rest are still returned
+	FailedSources map[domain.SearchResultType]error
+}
+
+// UniversalSearchService searches all the data sources at once and ranks the results across them
+type UniversalSearchService struct {
+	sources map[domain.SearchResultType]func(query string) ([]domain.SearchResult, error)
+}
+
+func NewUniversalSearchService(
+	tickersSearcher TickersSearcher,
+	etfsSearcher EtfsSearcher,
+	cryptocurrenciesSearcher CryptocurrenciesSearcher,
+	superInvestorsDataService SuperInvestorsDataService,
+	sectorsDataService SectorsDataService,
+	industriesDataService IndustriesDataService,
+	investingIdeasDataService InvestingIdeasDataService,
+) (*UniversalSearchService, error) {
+	superInvestorsIndex := search.NewCachedIndex(superInvestorsDataService.GetSuperInvestors, superInvestorDocument, searchIndexTtl)
+	sectorsIndex := search.NewCachedIndex(sectorsDataService.GetSectors, sectorDocument, searchIndexTtl)
+	industriesIndex := search.NewCachedIndex(industriesDataService.GetIndustries, industryDocument, searchIndexTtl)
+	investingIdeasIndex := search.NewCachedIndex(investingIdeasDataService.GetInvestingIdeas, investingIdeaDocument, searchIndexTtl)
+
+	return &UniversalSearchService{
+		sources: map[domain.SearchResultType]func(query string) ([]domain.SearchResult, error){
+			domain.SearchResultStock: func(query string) ([]domain.SearchResult, error) {
+				tickers, err := tickersSearcher.SearchTickers(query)
+				if err != nil {
+					return nil, err
+				}
+				return toSearchResults(tickers, func(t domain.Ticker) domain.SearchResult {
+					return domain.SearchResult{Type: domain.SearchResultStock, ID: t.Symbol, Symbol: t.Symbol, Name: t.CompanyName, Weight: t.MarketCap}
+				}), nil
+			},
+			domain.SearchResultEtf: func(query string) ([]domain.SearchResult, error) {
+				etfs, err := etfsSearcher.SearchEtfs(query)
+				if err != nil {
+					return nil, err
+				}
+				return toSearchResults(etfs, func(e domain.Etf) domain.SearchResult {
+					return domain.SearchResult{Type: domain.SearchResultEtf, ID: e.Symbol, Symbol: e.Symbol, Name: e.Name, Weight: float64(e.Aum)}
+				}), nil
+			},
+			domain.SearchResultCrypto: func(query string) ([]domain.SearchResult, error) {
+				cryptocurrencies, err := cryptocurrenciesSearcher.SearchCryptocurrencies(query)
+				if err != nil {
+					return nil, err
+				}
+				return toSearchResults(cryptocurrencies, func(c domain.Cryptocurrency) domain.SearchResult {
+					return domain.SearchResult{Type: domain.SearchResultCrypto, ID: c.Id, Symbol: c.Symbol, Name: c.Name, Weight: c.MarketCap}
+				}), nil
+			},
+			domain.SearchResultSuperInvestor: func(query string) ([]domain.SearchResult, error) {
+				return searchCachedIndex(superInvestorsIndex, query, func(s domain.SuperInvestor) domain.SearchResult {
+					return domain.SearchResult{Type: domain.SearchResultSuperInvestor, ID: s.Name, Name: s.Name}
+				})
+			},
+			domain.SearchResultSector: func(query string) ([]domain.SearchResult, error) {
+				return searchCachedIndex(sectorsIndex, query, func(s domain.Sector) domain.SearchResult {
+					return domain.SearchResult{Type: domain.SearchResultSector, ID: s.UrlName, Name: s.Name, Weight: float64(s.MarketCap)}
+				})
+			},
+			domain.SearchResultIndustry: func(query string) ([]domain.SearchResult, error) {
+				return searchCachedIndex(industriesIndex, query, func(i domain.Industry) domain.SearchResult {
+					return domain.SearchResult{Type: domain.SearchResultIndustry, ID: i.UrlName, Name: i.Name, Weight: float64(i.MarketCap)}
+				})
+			},
+			domain.SearchResultInvestingIdea: func(query string) ([]domain.SearchResult, error) {
+				return searchCachedIndex(investingIdeasIndex, query, func(i domain.InvestingIdea) domain.SearchResult {
+					return domain.SearchResult{Type: domain.SearchResultInvestingIdea, ID: i.ID, Name: i.Title}
+				})
+			},
+		},
+	}, nil
+}
+
+// searchResultTypesOrder breaks the ties between results of different types with the same score and weight
+var searchResultTypesOrder = []domain.SearchResultType{
+	domain.SearchResultStock,
+	domain.SearchResultEtf,
+	domain.SearchResultCrypto,
+	domain.SearchResultSuperInvestor,
+	domain.SearchResultSector,
+	domain.SearchResultIndustry,
+	domain.SearchResultInvestingIdea,
+}
+
+// Search queries all the sources (or the ones in filters.Types) concurrently and returns the best
+// filters.Limit results across them. A failing source doesn't fail the search, it is reported in FailedSources.
+func (s *UniversalSearchService) Search(filters UniversalSearchFilterOptions) (UniversalSearchResults, error) {
+	if filters.Query == "" {
+		return UniversalSearchResults{}, fmt.Errorf("query is required")
+	}
+
+	types := filters.Types
+	if len(types) == 0 {
+		types = searchResultTypesOrder
+	}
+	for _, t := range types {
+		if _, ok := s.sources[t]; !ok {
+			return UniversalSearchResults{}, fmt.Errorf("invalid search result type: %s", t)
+		}
+	}
+
+	limit := filters.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = UniversalSearchResults{
+			Results:       make([]domain.SearchResult, 0),
+			FailedSources: make(map[domain.SearchResultType]error),
+		}
+	)
+	for _, t := range slices.Compact(slices.Sorted(slices.Values(types))) {
+		source := s.sources[t]
+		wg.Go(func() {
+			sourceResults, err := source(filters.Query)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				results.FailedSources[t] = err
+				return
+			}
+			results.Results = append(results.Results, sourceResults...)
+		})
+	}
+	wg.Wait()
+
+	slices.SortStableFunc(results.Results, func(a, b domain.SearchResult) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(b.Weight, a.Weight),
+			cmp.Compare(slices.Index(searchResultTypesOrder, a.Type), slices.Index(searchResultTypesOrder, b.Type)),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	if len(results.Results) > limit {
+		results.Results = results.Results[:limit]
+	}
+
+	return results, nil
+}
+
+// toSearchResults adapts the results of a search to domain.SearchResult
+func toSearchResults[T any](items []search.Result[T], convert func(T) domain.SearchResult) []domain.SearchResult {
+	results := make([]domain.SearchResult, 0, len(items))
+	for _, item := range items {
+		result := convert(item.Item)
+		result.Score = item.Score
+		result.MatchReason = string(item.Reason)
+		results = append(results, result)
+	}
+	return results
+}
+
+func searchCachedIndex[T any](index *search.CachedIndex[T], query string, convert func(T) domain.SearchResult) ([]domain.SearchResult, error) {
+	idx, err := index.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return toSearchResults(idx.Search(query), convert), nil
+}
+
+func superInvestorDocument(s domain.SuperInvestor) search.Document {
+	return search.Document{Name: s.Name}
+}
+
+func sectorDocument(s domain.Sector) search.Document {
+	return search.Document{Name: s.Name, Aliases: []string{s.UrlName}, Weight: float64(s.MarketCap)}
+}
+
+func industryDocument(i domain.Industry) search.Document {
+	return search.Document{Name: i.Name, Aliases: []string{i.UrlName}, Weight: float64(i.MarketCap)}
+}
+
+func investingIdeaDocument(i domain.InvestingIdea) search.Document {
+	return search.Document{Name: i.Title, Aliases: []string{i.ID}}
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/search"
+	"reflect"
+	"testing"
+)
+
+type stubSearchSources struct {
+	failing map[domain.SearchResultType]bool
+}
+
+func (s stubSearchSources) fail(t domain.SearchResultType) error {
+	if s.failing[t] {
+		return fmt.Errorf("%s source is down", t)
+	}
+	return nil
+}
+
+func (s stubSearchSources) SearchTickers(query string) ([]search.Result[domain.Ticker], error) {
+	if err := s.fail(domain.SearchResultStock); err != nil {
+		return nil, err
+	}
+	tickers := []domain.Ticker{{Symbol: "TECH", CompanyName: "Bio-Techne Corp", MarketCap: 1e10}, {Symbol: "AAPL", CompanyName: "Apple Inc.", MarketCap: 3e12}}
+	return search.NewIndex(tickers, func(t domain.Ticker) search.Document {
+		return search.Document{Symbol: t.Symbol, Name: t.CompanyName, Weight: t.MarketCap}
+	}).Search(query), nil
+}
+
+func (s stubSearchSources) SearchEtfs(query string) ([]search.Result[domain.Etf], error) {
+	if err := s.fail(domain.SearchResultEtf); err != nil {
+		return nil, err
+	}
+	etfs := []domain.Etf{{Symbol: "XLK", Name: "Technology Select Sector SPDR", Aum: 7e10}}
+	return search.NewIndex(etfs, func(e domain.Etf) search.Document {
+		return search.Document{Symbol: e.Symbol, Name: e.Name, Weight: float64(e.Aum)}
+	}).Search(query), nil
+}
+
+func (s stubSearchSources) SearchCryptocurrencies(query string) ([]search.Result[domain.Cryptocurrency], error) {
+	if err := s.fail(domain.SearchResultCrypto); err != nil {
+		return nil, err
+	}
+	return []search.Result[domain.Cryptocurrency]{}, nil
+}
+
+func (s stubSearchSources) GetSuperInvestors() ([]domain.SuperInvestor, error) {
+	return []domain.SuperInvestor{{Name: "Warren Buffett - Berkshire Hathaway"}}, s.fail(domain.SearchResultSuperInvestor)
+}
+
+func (s stubSearchSources) GetSectors() ([]domain.Sector, error) {
+	return []domain.Sector{{Name: "Technology", UrlName: "technology", MarketCap: 2e13}}, s.fail(domain.SearchResultSector)
+}
+
+func (s stubSearchSources) GetIndustries() ([]domain.Industry, error) {
+	return []domain.Industry{{Name: "Software - Infrastructure", UrlName: "software-infrastructure"}}, s.fail(domain.SearchResultIndustry)
+}
+
+func (s stubSearchSources) GetInvestingIdeas() ([]domain.InvestingIdea, error) {
+	return []domain.InvestingIdea{{ID: "ai", Title: "AI and tech leaders"}}, s.fail(domain.SearchResultInvestingIdea)
+}
+
+func newStubUniversalSearchService(failing ...domain.SearchResultType) *UniversalSearchService {
+	sources := stubSearchSources{failing: make(map[domain.SearchResultType]bool)}
+	for _, t := range failing {
+		sources.failing[t] = true
+	}
+	s, _ := NewUniversalSearchService(sources, sources, sources, sources, sources, sources, sources)
+	return s
+}
+
+func resultIDs(results []domain.SearchResult) []string {
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, string(result.Type)+":"+result.ID)
+	}
+	return ids
+}
+
+func TestUniversalSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		failing  []domain.SearchResultType
+		filters  UniversalSearchFilterOptions
+		expected []string
+	}{
+		{
+			// The exact symbol, the sector url name prefix, then the name token prefixes of the ETF and the idea
+			name:     "ranked across types",
+			filters:  UniversalSearchFilterOptions{Query: "tech"},
+			expected: []string{"stock:TECH", "sector:technology", "etf:XLK", "investing_idea:ai"},
+		},
+		{
+			name:     "types",
+			filters:  UniversalSearchFilterOptions{Query: "tech", Types: []domain.SearchResultType{domain.SearchResultEtf, domain.SearchResultInvestingIdea, domain.SearchResultEtf}},
+			expected: []string{"etf:XLK", "investing_idea:ai"},
+		},
+		{
+			name:     "limit",
+			filters:  UniversalSearchFilterOptions{Query: "tech", Limit: 2},
+			expected: []string{"stock:TECH", "sector:technology"},
+		},
+		{
+			name:     "failing sources",
+			failing:  []domain.SearchResultType{domain.SearchResultStock, domain.SearchResultSector},
+			filters:  UniversalSearchFilterOptions{Query: "tech"},
+			expected: []string{"etf:XLK", "investing_idea:ai"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := newStubUniversalSearchService(tt.failing...).Search(tt.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := resultIDs(results.Results); !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, ids)
+			}
+			if len(results.FailedSources) != len(tt.failing) {
+				t.Errorf("expected the failed sources %v, got %v", tt.failing, results.FailedSources)
+			}
+			for _, failing := range tt.failing {
+				if results.FailedSources[failing] == nil {
+					t.Errorf("expected %s in the failed sources, got %v", failing, results.FailedSources)
+				}
+			}
+			for i := 1; i < len(results.Results); i++ {
+				if results.Results[i].Score > results.Results[i-1].Score {
+					t.Errorf("expected decreasing scores, got %+v", results.Results)
+				}
+			}
+		})
+	}
+}
+
+func TestUniversalSearchInvalidFilters(t *testing.T) {
+	s := newStubUniversalSearchService()
+
+	for _, filters := range []UniversalSearchFilterOptions{{}, {Query: "tech", Types: []domain.SearchResultType{"bond"}}} {
+		if _, err := s.Search(filters); err == nil {
+			t.Errorf("expected an error for %+v", filters)
+		}
+	}
+}
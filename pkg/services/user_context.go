@@ -0,0 +1,427 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/errors"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxUserContextHistory is the number of changes kept in the history of a user, the oldest ones are dropped
+const maxUserContextHistory = 100
+
+// UserContextLocalDataService stores the user contexts and their change history in a JSON file, or only
+// in memory when no file is given. Every change but the full replacement uses optimistic concurrency:
+// it's applied only if the context wasn't updated since the expected UpdatedAt.
+type UserContextLocalDataService struct {
+	dataPath string
+
+	mu       sync.Mutex
+	contexts map[string]domain.UserContext
+	history  map[string][]domain.UserContextChange
+}
+
+type userContextData struct {
+	UserContexts map[string]domain.UserContext         `json:"user_contexts"`
+	History      map[string][]domain.UserContextChange `json:"history"`
+}
+
+func NewUserContextLocalDataService(dataPath string) (*UserContextLocalDataService, error) {
+	s := &UserContextLocalDataService{
+		dataPath: dataPath,
+		contexts: make(map[string]domain.UserContext),
+		history:  make(map[string][]domain.UserContextChange),
+	}
+	if dataPath == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(dataPath)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user contexts file: %w", err)
+	}
+
+	var stored userContextData
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user contexts: %w", err)
+	}
+	if stored.UserContexts != nil {
+		s.contexts = stored.UserContexts
+	}
+	if stored.History != nil {
+		s.history = stored.History
+	}
+
+	return s, nil
+}
+
+func (s *UserContextLocalDataService) GetUserContext(userID string) (domain.UserContext, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userContext, ok := s.contexts[userID]
+	if !ok {
+		return domain.UserContext{}, errors.UserContextNotFoundError{UserID: userID}
+	}
+	return cloneUserContext(userContext), nil
+}
+
+func (s *UserContextLocalDataService) CreateUserContext(userContext domain.UserContext) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.contexts[userContext.UserID]; ok {
+		return errors.UserContextAlreadyExistsError{UserID: userContext.UserID}
+	}
+
+	_, err := s.apply(userContext.UserID, nil, "create", "create the user context", func(uc *domain.UserContext) error {
+		uc.UserProfile = userContext.UserProfile
+		uc.UserPortfolio = userContext.UserPortfolio
+		return nil
+	})
+	return err
+}
+
+// UpdateUserContext replaces the profile and the portfolio of the user, the context is created if it doesn't exist
+func (s *UserContextLocalDataService) UpdateUserContext(userContext domain.UserContext) error {
+	_, err := s.ReplaceUserContext(userContext, "")
+	return err
+}
+
+// ReplaceUserContext replaces the profile and the portfolio of the user. The replacement is unconditional
+// when expectedUpdatedAt is empty, otherwise the context must not have been updated since.
+func (s *UserContextLocalDataService) ReplaceUserContext(userContext domain.UserContext, expectedUpdatedAt string) (domain.UserContext, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expected *string
+	if expectedUpdatedAt != "" {
+		expected = &expectedUpdatedAt
+	}
+
+	return s.apply(userContext.UserID, expected, "replace", "replace the user context", func(uc *domain.UserContext) error {
+		uc.UserProfile = userContext.UserProfile
+		uc.UserPortfolio = userContext.UserPortfolio
+		return nil
+	})
+}
+
+// UpsertPortfolioHolding adds the holding to the portfolio or replaces the holding with the same symbol
+// (the same name for the holdings without symbol), the context is created if it doesn't exist
+func (s *UserContextLocalDataService) UpsertPortfolioHolding(userID string, expectedUpdatedAt string, holding domain.UserPortfolioHolding) (domain.UserContext, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := holdingKey(holding)
+	return s.apply(userID, &expectedUpdatedAt, "upsert_holding", "add or update the holding "+key, func(uc *domain.UserContext) error {
+		if i := findHolding(uc.UserPortfolio, key); i >= 0 {
+			uc.UserPortfolio[i] = holding
+		} else {
+			uc.UserPortfolio = append(uc.UserPortfolio, holding)
+		}
+		return nil
+	})
+}
+
+// RemovePortfolioHolding removes the holding with the symbol (or the name) from the portfolio
+func (s *UserContextLocalDataService) RemovePortfolioHolding(userID string, expectedUpdatedAt string, symbol string) (domain.UserContext, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkExists(userID); err != nil {
+		return domain.UserContext{}, err
+	}
+
+	return s.apply(userID, &expectedUpdatedAt, "remove_holding", "remove the holding "+symbol, func(uc *domain.UserContext) error {
+		i := findHolding(uc.UserPortfolio, symbol)
+		if i < 0 {
+			return errors.PortfolioHoldingNotFoundError{UserID: userID, Symbol: symbol}
+		}
+		uc.UserPortfolio = slices.Delete(uc.UserPortfolio, i, i+1)
+		return nil
+	})
+}
+
+// AdjustPortfolioHoldingQuantity adds delta (negative to sell) to the quantity of the holding, the holding is
+// removed when its quantity drops to zero (a zero quantity means not known)
+func (s *UserContextLocalDataService) AdjustPortfolioHoldingQuantity(userID string, expectedUpdatedAt string, symbol string, delta float64) (domain.UserContext, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkExists(userID); err != nil {
+		return domain.UserContext{}, err
+	}
+
+	summary := fmt.Sprintf("adjust the quantity of %s by %g", symbol, delta)
+	return s.apply(userID, &expectedUpdatedAt, "adjust_quantity", summary, func(uc *domain.UserContext) error {
+		i := findHolding(uc.UserPortfolio, symbol)
+		if i < 0 {
+			return errors.PortfolioHoldingNotFoundError{UserID: userID, Symbol: symbol}
+		}
+
+		quantity := uc.UserPortfolio[i].Quantity + delta
+		switch {
+		case quantity < 0:
+			return fmt.Errorf("the quantity of %s would be negative: %g + %g", symbol, uc.UserPortfolio[i].Quantity, delta)
+		case quantity == 0:
+			uc.UserPortfolio = slices.Delete(uc.UserPortfolio, i, i+1)
+		default:
+			uc.UserPortfolio[i].Quantity = quantity
+		}
+		return nil
+	})
+}
+
+// PatchUserProfile applies a JSON merge patch (RFC 7386) to the profile: the keys set to null are removed, the
+// objects are merged recursively and the other values replace the existing ones. The context is created if it
+// doesn't exist.
+func (s *UserContextLocalDataService) PatchUserProfile(userID string, expectedUpdatedAt string, patch map[string]any) (domain.UserContext, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var set, unset []string
+	for key, value := range patch {
+		if value == nil {
+			unset = append(unset, key)
+		} else {
+			set = append(set, key)
+		}
+	}
+	sort.Strings(set)
+	sort.Strings(unset)
+
+	var summary []string
+	if len(set) > 0 {
+		summary = append(summary, "set "+strings.Join(set, ", "))
+	}
+	if len(unset) > 0 {
+		summary = append(summary, "unset "+strings.Join(unset, ", "))
+	}
+
+	return s.apply(userID, &expectedUpdatedAt, "patch_profile", "patch the profile: "+strings.Join(summary, "; "), func(uc *domain.UserContext) error {
+		uc.UserProfile = mergePatch(uc.UserProfile, patch)
+		return nil
+	})
+}
+
+// ClearUserPortfolio removes all the holdings of the portfolio
+func (s *UserContextLocalDataService) ClearUserPortfolio(userID string, expectedUpdatedAt string) (domain.UserContext, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkExists(userID); err != nil {
+		return domain.UserContext{}, err
+	}
+
+	return s.apply(userID, &expectedUpdatedAt, "clear_portfolio", "clear the portfolio", func(uc *domain.UserContext) error {
+		uc.UserPortfolio = nil
+		return nil
+	})
+}
+
+// GetUserContextHistory returns the last changes of the user context, the most recent first, all when limit is 0
+func (s *UserContextLocalDataService) GetUserContextHistory(userID string, limit int) ([]domain.UserContextChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkExists(userID); err != nil {
+		return nil, err
+	}
+
+	history := s.history[userID]
+	changes := make([]domain.UserContextChange, 0, len(history))
+	for i := len(history) - 1; i >= 0 && (limit <= 0 || len(changes) < limit); i-- {
+		changes = append(changes, history[i])
+	}
+	return changes, nil
+}
+
+// RevertUserContextChange restores the user context as it was before the change, which also reverts the changes
+// made after it. The revert is itself a change of the history, that can be reverted.
+func (s *UserContextLocalDataService) RevertUserContextChange(userID string, expectedUpdatedAt string, changeID int) (domain.UserContext, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkExists(userID); err != nil {
+		return domain.UserContext{}, err
+	}
+
+	var change *domain.UserContextChange
+	for i := range s.history[userID] {
+		if s.history[userID][i].ID == changeID {
+			change = &s.history[userID][i]
+		}
+	}
+	if change == nil {
+		return domain.UserContext{}, errors.UserContextChangeNotFoundError{UserID: userID, ChangeID: changeID}
+	}
+
+	before := domain.UserContext{}
+	if change.Before != nil {
+		before = cloneUserContext(*change.Before)
+	}
+
+	summary := fmt.Sprintf("revert change %d (%s)", change.ID, change.Summary)
+	return s.apply(userID, &expectedUpdatedAt, "revert", summary, func(uc *domain.UserContext) error {
+		uc.UserProfile = before.UserProfile
+		uc.UserPortfolio = before.UserPortfolio
+		return nil
+	})
+}
+
+func (s *UserContextLocalDataService) checkExists(userID string) error {
+	if _, ok := s.contexts[userID]; !ok {
+		return errors.UserContextNotFoundError{UserID: userID}
+	}
+	return nil
+}
+
+// apply applies the change to the user context (created if it doesn't exist), records it in the history and
+// stores the contexts. When expectedUpdatedAt isn't nil the context must not have been updated since it, an
+// empty expectedUpdatedAt meaning that the context must not exist. The lock must be held.
+func (s *UserContextLocalDataService) apply(userID string, expectedUpdatedAt *string, operation string, summary string, change func(uc *domain.UserContext) error) (domain.UserContext, error) {
+	if userID == "" {
+		return domain.UserContext{}, fmt.Errorf("user_id is required")
+	}
+
+	current, exists := s.contexts[userID]
+	if expectedUpdatedAt != nil && current.UpdatedAt != *expectedUpdatedAt {
+		return domain.UserContext{}, errors.UserContextConflictError{UserID: userID, ExpectedUpdatedAt: *expectedUpdatedAt, UpdatedAt: current.UpdatedAt}
+	}
+
+	now := time.Now().UTC()
+	// The versions must differ even for two changes within the resolution of the clock
+	if updatedAt, err := time.Parse(time.RFC3339Nano, current.UpdatedAt); err == nil && !now.After(updatedAt) {
+		now = updatedAt.Add(time.Nanosecond)
+	}
+
+	updated := domain.UserContext{UserID: userID, CreatedAt: now.Format(time.RFC3339Nano)}
+	var before *domain.UserContext
+	if exists {
+		updated = cloneUserContext(current)
+		before = &current
+	}
+	if err := change(&updated); err != nil {
+		return domain.UserContext{}, err
+	}
+	if updated.UserProfile == nil {
+		updated.UserProfile = map[string]any{}
+	}
+	updated.UpdatedAt = now.Format(time.RFC3339Nano)
+
+	history := s.history[userID]
+	id := 1
+	if len(history) > 0 {
+		id = history[len(history)-1].ID + 1
+	}
+	history = append(history, domain.UserContextChange{
+		ID:        id,
+		UserID:    userID,
+		Operation: operation,
+		Summary:   summary,
+		CreatedAt: updated.UpdatedAt,
+		Before:    before,
+		After:     cloneUserContext(updated),
+	})
+	if len(history) > maxUserContextHistory {
+		history = slices.Clone(history[len(history)-maxUserContextHistory:])
+	}
+
+	previousHistory := s.history[userID]
+	s.contexts[userID] = updated
+	s.history[userID] = history
+
+	if err := s.save(); err != nil {
+		// Keep the memory consistent with the file
+		if exists {
+			s.contexts[userID] = current
+		} else {
+			delete(s.contexts, userID)
+		}
+		s.history[userID] = previousHistory
+		return domain.UserContext{}, err
+	}
+
+	return cloneUserContext(updated), nil
+}
+
+// save writes the contexts and their history to the data file
+func (s *UserContextLocalDataService) save() error {
+	if s.dataPath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(userContextData{UserContexts: s.contexts, History: s.history})
+	if err != nil {
+		return fmt.Errorf("failed to marshal user contexts: %w", err)
+	}
+
+	if err := writeFileAtomically(s.dataPath, data); err != nil {
+		return fmt.Errorf("failed to write user contexts file: %w", err)
+	}
+	return nil
+}
+
+// holdingKey identifies a holding in a portfolio: its symbol, or its name when it has no symbol
+func holdingKey(holding domain.UserPortfolioHolding) string {
+	if holding.Symbol != "" {
+		return holding.Symbol
+	}
+	return holding.Name
+}
+
+// findHolding returns the index of the holding with the symbol (or the name), -1 if there is none
+func findHolding(portfolio []domain.UserPortfolioHolding, key string) int {
+	return slices.IndexFunc(portfolio, func(h domain.UserPortfolioHolding) bool {
+		return strings.EqualFold(holdingKey(h), key)
+	})
+}
+
+// mergePatch returns the target with the JSON merge patch (RFC 7386) applied, the target isn't modified
+func mergePatch(target map[string]any, patch map[string]any) map[string]any {
+	result := maps.Clone(target)
+	if result == nil {
+		result = make(map[string]any)
+	}
+
+	for key, value := range patch {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		if patchObject, ok := value.(map[string]any); ok {
+			targetObject, _ := result[key].(map[string]any)
+			result[key] = mergePatch(targetObject, patchObject)
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// cloneUserContext returns a deep copy of the user context, the profile may contain nested maps and lists
+func cloneUserContext(userContext domain.UserContext) domain.UserContext {
+	clone := userContext
+	clone.UserPortfolio = slices.Clone(userContext.UserPortfolio)
+
+	if userContext.UserProfile != nil {
+		data, err := json.Marshal(userContext.UserProfile)
+		if err == nil {
+			var profile map[string]any
+			if json.Unmarshal(data, &profile) == nil {
+				clone.UserProfile = profile
+			}
+		}
+	}
+	return clone
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	goerrors "errors"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUserContextIncrementalChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user_contexts.json")
+	s, err := NewUserContextLocalDataService(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uc, err := s.UpsertPortfolioHolding("u1", "", domain.UserPortfolioHolding{AssetClass: domain.Stock, Symbol: "AAPL", Quantity: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpsertPortfolioHolding("u1", "", domain.UserPortfolioHolding{AssetClass: domain.Stock, Symbol: "MSFT"}); !goerrors.As(err, &errors.UserContextConflictError{}) {
+		t.Fatalf("expected a conflict when the context exists, got %v", err)
+	}
+
+	uc, err = s.UpsertPortfolioHolding("u1", uc.UpdatedAt, domain.UserPortfolioHolding{AssetClass: domain.Stock, Symbol: "MSFT", Quantity: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := uc.UpdatedAt
+	uc, err = s.AdjustPortfolioHoldingQuantity("u1", uc.UpdatedAt, "aapl", -4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.UserPortfolio[0].Quantity != 6 {
+		t.Errorf("expected 6 AAPL, got %v", uc.UserPortfolio)
+	}
+	if _, err := s.RemovePortfolioHolding("u1", stale, "MSFT"); !goerrors.As(err, &errors.UserContextConflictError{}) {
+		t.Fatalf("expected a conflict on a stale updated_at, got %v", err)
+	}
+	if _, err := s.AdjustPortfolioHoldingQuantity("u1", uc.UpdatedAt, "MSFT", -6); err == nil {
+		t.Fatal("expected an error on a negative quantity")
+	}
+	uc, err = s.AdjustPortfolioHoldingQuantity("u1", uc.UpdatedAt, "MSFT", -5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uc.UserPortfolio) != 1 {
+		t.Errorf("expected the holding to be removed at zero, got %v", uc.UserPortfolio)
+	}
+
+	uc, err = s.PatchUserProfile("u1", uc.UpdatedAt, map[string]any{"age": 40.0, "goals": map[string]any{"retirement": 2050.0, "house": 2030.0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc, err = s.PatchUserProfile("u1", uc.UpdatedAt, map[string]any{"age": nil, "goals": map[string]any{"house": nil}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"goals": map[string]any{"retirement": 2050.0}}; !reflect.DeepEqual(uc.UserProfile, want) {
+		t.Errorf("expected the merge patch to remove the null keys, got %v", uc.UserProfile)
+	}
+
+	uc, err = s.ClearUserPortfolio("u1", uc.UpdatedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := s.GetUserContextHistory("u1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 7 || history[0].Operation != "clear_portfolio" || history[6].Before != nil {
+		t.Fatalf("expected the 7 changes, the most recent first, got %+v", history)
+	}
+
+	// Reverting the third change restores the context before it: AAPL only, no profile
+	uc, err = s.RevertUserContextChange("u1", uc.UpdatedAt, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uc.UserPortfolio) != 2 || len(uc.UserProfile) != 0 || uc.UserPortfolio[0].Quantity != 10 {
+		t.Errorf("expected the context before the third change, got %+v", uc)
+	}
+
+	// The contexts and the history are persisted
+	reloaded, err := NewUserContextLocalDataService(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reloaded.GetUserContext("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.UpdatedAt != uc.UpdatedAt || len(got.UserPortfolio) != 2 {
+		t.Errorf("expected the stored context, got %+v", got)
+	}
+	if history, _ := reloaded.GetUserContextHistory("u1", 1); len(history) != 1 || history[0].Operation != "revert" {
+		t.Errorf("expected the revert as last change, got %+v", history)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	target := map[string]any{"a": "b", "c": map[string]any{"d": "e", "f": "g"}, "list": []any{1.0}}
+	patch := map[string]any{"a": "z", "c": map[string]any{"f": nil}, "list": []any{2.0}, "new": map[string]any{"x": nil}}
+
+	got := mergePatch(target, patch)
+	want := map[string]any{"a": "z", "c": map[string]any{"d": "e"}, "list": []any{2.0}, "new": map[string]any{}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if target["a"] != "b" {
+		t.Error("expected the target not to be modified")
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type WatchlistSource interface {
+	GetWatchlist(userID string, name string) (domain.Watchlist, error)
+}
+
+type WatchlistDataService interface {
+	GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error)
+	GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error)
+	GetEtfOverview(symbol string) (domain.EtfOverview, error)
+}
+
+// WatchlistSnapshotService fetches the price, the performance and the key ratios of the entries of the watchlists:
+// the stocks and the ETFs from stockanalysis through the cached scraper, the cryptocurrencies from CoinGecko
+type WatchlistSnapshotService struct {
+	watchlists WatchlistSource
+	data       WatchlistDataService
+	crypto     CryptoPricesService
+}
+
+func NewWatchlistSnapshotService(watchlists WatchlistSource, data WatchlistDataService, crypto CryptoPricesService) (*WatchlistSnapshotService, error) {
+	return &WatchlistSnapshotService{
+		watchlists: watchlists,
+		data:       data,
+		crypto:     crypto,
+	}, nil
+}
+
+// GetWatchlistSnapshot returns the snapshot of every entry of the watchlist. The entries are fetched concurrently,
+// at most maxConcurrentPriceRequests at a time, and the data that can't be fetched is reported per entry.
+func (s *WatchlistSnapshotService) GetWatchlistSnapshot(userID string, name string) (domain.WatchlistSnapshot, error) {
+	watchlist, err := s.watchlists.GetWatchlist(userID, name)
+	if err != nil {
+		return domain.WatchlistSnapshot{}, err
+	}
+
+	snapshot := domain.WatchlistSnapshot{
+		Watchlist: watchlist,
+		Entries:   make([]domain.WatchlistEntrySnapshot, len(watchlist.Entries)),
+		FetchedAt: time.Now().UTC(),
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrentPriceRequests)
+	for i, entry := range watchlist.Entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			snapshot.Entries[i] = s.entrySnapshot(entry)
+		}()
+	}
+	wg.Wait()
+
+	return snapshot, nil
+}
+
+func (s *WatchlistSnapshotService) entrySnapshot(entry domain.WatchlistEntry) domain.WatchlistEntrySnapshot {
+	snapshot := domain.WatchlistEntrySnapshot{Entry: entry}
+	if entry.AssetClass == domain.Crypto {
+		s.addCryptocurrencyData(&snapshot)
+		return snapshot
+	}
+
+	// Lowercase like the other tools, so that they share the cached data
+	symbol := strings.ToLower(entry.Symbol)
+	changes := []struct {
+		period domain.Period
+		change **float64
+	}{
+		{domain.Period1D, &snapshot.Change1D},
+		{domain.Period1M, &snapshot.Change1M},
+		{domain.Period1Y, &snapshot.Change1Y},
+	}
+	for _, c := range changes {
+		prices, err := s.data.GetHistoricalPrices(symbol, entry.AssetClass, c.period)
+		if err != nil {
+			snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("failed to get the %s prices: %v", c.period, err))
+			continue
+		}
+		if len(prices.Prices) == 0 {
+			continue
+		}
+		change := prices.PercentageChange
+		*c.change = &change
+		if snapshot.PriceDate.IsZero() {
+			last := prices.Prices[len(prices.Prices)-1]
+			snapshot.Price, snapshot.PriceDate = last.ClosePrice, last.Date
+		}
+	}
+
+	if entry.AssetClass == domain.ETF {
+		overview, err := s.data.GetEtfOverview(symbol)
+		if err != nil {
+			snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("failed to get the ETF overview: %v", err))
+			return snapshot
+		}
+		snapshot.Ratios = domain.KeyRatios{
+			Pe:            parseNumber(overview.PeRatio),
+			DividendYield: parseNumber(overview.DividendYield),
+			ExpenseRatio:  parseNumber(overview.ExpenseRatio),
+		}
+		return snapshot
+	}
+
+	ratios, err := s.data.GetFinancialRatios(symbol)
+	if err != nil {
+		snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("failed to get the financial ratios: %v", err))
+		return snapshot
+	}
+	if len(ratios) > 0 {
+		// The first ratios are the trailing twelve months ones, the yields and returns are fractions
+		r := ratios[0]
+		snapshot.Ratios = domain.KeyRatios{
+			MarketCap:     r.Marketcap,
+			Pe:            r.Pe,
+			Ps:            r.Ps,
+			Pb:            r.Pb,
+			EvEbitda:      r.EvEbitda,
+			DebtEquity:    r.DebtEquity,
+			Roe:           r.Roe * 100,
+			DividendYield: r.DividendYield * 100,
+		}
+	}
+	return snapshot
+}
+
+func (s *WatchlistSnapshotService) addCryptocurrencyData(snapshot *domain.WatchlistEntrySnapshot) {
+	data, err := cryptocurrencyData(s.crypto, snapshot.Entry.Symbol)
+	if err != nil {
+		snapshot.Errors = append(snapshot.Errors, err.Error())
+		return
+	}
+	snapshot.Price, snapshot.PriceDate = data.CurrentUsdPrice, time.Now().UTC()
+	snapshot.Change1D = &data.PriceChangePercentage24h
+	snapshot.Change1M = &data.PriceChangePercentage30d
+	snapshot.Change1Y = &data.PriceChangePercentage1y
+	snapshot.Ratios.MarketCap = data.MarketCapUsd
+}
+
+// parseNumber parses the numbers of the ETF overviews (e.g. "0.20%" or "24.5"), 0 when it isn't a number
+func parseNumber(value string) float64 {
+	value = strings.TrimSuffix(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), "%")
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return number
+}
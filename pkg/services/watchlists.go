@@ -0,0 +1,243 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/errors"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WatchlistLocalDataService stores the named watchlists of the users in a JSON file, or only in memory when no
+// file is given. The names of the watchlists of a user are unique regardless of the case.
+type WatchlistLocalDataService struct {
+	dataPath string
+
+	mu         sync.Mutex
+	watchlists map[string][]domain.Watchlist // By user id
+}
+
+func NewWatchlistLocalDataService(dataPath string) (*WatchlistLocalDataService, error) {
+	s := &WatchlistLocalDataService{
+		dataPath:   dataPath,
+		watchlists: make(map[string][]domain.Watchlist),
+	}
+	if dataPath == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(dataPath)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watchlists file: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.watchlists); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal watchlists: %w", err)
+	}
+	if s.watchlists == nil {
+		s.watchlists = make(map[string][]domain.Watchlist)
+	}
+
+	return s, nil
+}
+
+// GetWatchlists returns the watchlists of the user sorted by name
+func (s *WatchlistLocalDataService) GetWatchlists(userID string) ([]domain.Watchlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watchlists := make([]domain.Watchlist, 0, len(s.watchlists[userID]))
+	for _, w := range s.watchlists[userID] {
+		watchlists = append(watchlists, cloneWatchlist(w))
+	}
+	slices.SortFunc(watchlists, func(a, b domain.Watchlist) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return watchlists, nil
+}
+
+func (s *WatchlistLocalDataService) GetWatchlist(userID string, name string) (domain.Watchlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.find(userID, name)
+	if i < 0 {
+		return domain.Watchlist{}, errors.WatchlistNotFoundError{UserID: userID, Name: name}
+	}
+	return cloneWatchlist(s.watchlists[userID][i]), nil
+}
+
+// CreateWatchlist creates an empty watchlist
+func (s *WatchlistLocalDataService) CreateWatchlist(userID string, name string) (domain.Watchlist, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.Watchlist{}, fmt.Errorf("name is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.find(userID, name) >= 0 {
+		return domain.Watchlist{}, errors.WatchlistAlreadyExistsError{UserID: userID, Name: name}
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	watchlist := domain.Watchlist{UserID: userID, Name: name, Entries: []domain.WatchlistEntry{}, CreatedAt: now, UpdatedAt: now}
+
+	current := s.watchlists[userID]
+	s.watchlists[userID] = append(slices.Clone(current), watchlist)
+	if err := s.save(); err != nil {
+		s.watchlists[userID] = current
+		return domain.Watchlist{}, err
+	}
+	return cloneWatchlist(watchlist), nil
+}
+
+func (s *WatchlistLocalDataService) RenameWatchlist(userID string, name string, newName string) (domain.Watchlist, error) {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return domain.Watchlist{}, fmt.Errorf("new_name is required")
+	}
+
+	return s.update(userID, name, func(w *domain.Watchlist) error {
+		if s.find(userID, newName) >= 0 && !strings.EqualFold(newName, name) {
+			return errors.WatchlistAlreadyExistsError{UserID: userID, Name: newName}
+		}
+		w.Name = newName
+		return nil
+	})
+}
+
+// DeleteWatchlist deletes the watchlist and returns it
+func (s *WatchlistLocalDataService) DeleteWatchlist(userID string, name string) (domain.Watchlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.find(userID, name)
+	if i < 0 {
+		return domain.Watchlist{}, errors.WatchlistNotFoundError{UserID: userID, Name: name}
+	}
+
+	current := s.watchlists[userID]
+	s.watchlists[userID] = slices.Delete(slices.Clone(current), i, i+1)
+	if err := s.save(); err != nil {
+		s.watchlists[userID] = current
+		return domain.Watchlist{}, err
+	}
+	return current[i], nil
+}
+
+// AddWatchlistEntries adds the entries to the watchlist. An entry whose symbol and asset class are already in the
+// watchlist replaces its notes and keeps its date added.
+func (s *WatchlistLocalDataService) AddWatchlistEntries(userID string, name string, entries []domain.WatchlistEntry) (domain.Watchlist, error) {
+	if len(entries) == 0 {
+		return domain.Watchlist{}, fmt.Errorf("entries must not be empty")
+	}
+	for i := range entries {
+		entries[i].Symbol = strings.ToUpper(strings.TrimSpace(entries[i].Symbol))
+		if entries[i].Symbol == "" {
+			return domain.Watchlist{}, fmt.Errorf("symbol is required")
+		}
+		if entries[i].AssetClass == "" {
+			entries[i].AssetClass = domain.Stock
+		}
+		if a := entries[i].AssetClass; a != domain.Stock && a != domain.ETF && a != domain.Crypto {
+			return domain.Watchlist{}, fmt.Errorf("asset_class valid values are: stock, etf, crypto")
+		}
+	}
+
+	return s.update(userID, name, func(w *domain.Watchlist) error {
+		now := time.Now().UTC().Format(time.RFC3339)
+		for _, entry := range entries {
+			i := slices.IndexFunc(w.Entries, func(e domain.WatchlistEntry) bool {
+				return e.Symbol == entry.Symbol && e.AssetClass == entry.AssetClass
+			})
+			if i >= 0 {
+				w.Entries[i].Notes = entry.Notes
+				continue
+			}
+			entry.AddedAt = now
+			w.Entries = append(w.Entries, entry)
+		}
+		return nil
+	})
+}
+
+// RemoveWatchlistEntries removes the entries of the symbols from the watchlist, whatever their asset class
+func (s *WatchlistLocalDataService) RemoveWatchlistEntries(userID string, name string, symbols []string) (domain.Watchlist, error) {
+	if len(symbols) == 0 {
+		return domain.Watchlist{}, fmt.Errorf("symbols must not be empty")
+	}
+
+	return s.update(userID, name, func(w *domain.Watchlist) error {
+		for _, symbol := range symbols {
+			entries := slices.DeleteFunc(w.Entries, func(e domain.WatchlistEntry) bool {
+				return strings.EqualFold(e.Symbol, strings.TrimSpace(symbol))
+			})
+			if len(entries) == len(w.Entries) {
+				return errors.WatchlistEntryNotFoundError{UserID: userID, Name: w.Name, Symbol: symbol}
+			}
+			w.Entries = entries
+		}
+		return nil
+	})
+}
+
+// update applies the change to a copy of the watchlist and saves it, the watchlist is unchanged if the change fails
+func (s *WatchlistLocalDataService) update(userID string, name string, change func(w *domain.Watchlist) error) (domain.Watchlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.find(userID, name)
+	if i < 0 {
+		return domain.Watchlist{}, errors.WatchlistNotFoundError{UserID: userID, Name: name}
+	}
+
+	current := s.watchlists[userID]
+	updated := cloneWatchlist(current[i])
+	if err := change(&updated); err != nil {
+		return domain.Watchlist{}, err
+	}
+	updated.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	s.watchlists[userID] = slices.Clone(current)
+	s.watchlists[userID][i] = updated
+	if err := s.save(); err != nil {
+		s.watchlists[userID] = current
+		return domain.Watchlist{}, err
+	}
+	return cloneWatchlist(updated), nil
+}
+
+// find returns the index of the watchlist of the user with the name, -1 when there is none
+func (s *WatchlistLocalDataService) find(userID string, name string) int {
+	name = strings.TrimSpace(name)
+	return slices.IndexFunc(s.watchlists[userID], func(w domain.Watchlist) bool {
+		return strings.EqualFold(w.Name, name)
+	})
+}
+
+func (s *WatchlistLocalDataService) save() error {
+	if s.dataPath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(s.watchlists)
+	if err != nil {
+		return fmt.Errorf("failed to marshal watchlists: %w", err)
+	}
+	if err := writeFileAtomically(s.dataPath, data); err != nil {
+		return fmt.Errorf("failed to write watchlists file: %w", err)
+	}
+	return nil
+}
+
+func cloneWatchlist(w domain.Watchlist) domain.Watchlist {
+	w.Entries = append([]domain.WatchlistEntry{}, w.Entries...)
+	return w
+}
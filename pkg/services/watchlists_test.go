@@ -0,0 +1,110 @@
+package services
+
+import (
+	goerrors "errors"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/errors"
+	"testing"
+)
+
+func TestWatchlistEntries(t *testing.T) {
+	s, _ := NewWatchlistLocalDataService("")
+
+	if _, err := s.CreateWatchlist("u", "Tech"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateWatchlist("u", "tech"); !goerrors.As(err, &errors.WatchlistAlreadyExistsError{}) {
+		t.Errorf("expected the names to be unique regardless of the case, got %v", err)
+	}
+
+	watchlist, err := s.AddWatchlistEntries("u", "TECH", []domain.WatchlistEntry{
+		{Symbol: "aapl", Notes: "Below 180"},
+		{Symbol: "BTC", AssetClass: domain.Crypto},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(watchlist.Entries) != 2 || watchlist.Entries[0].Symbol != "AAPL" || watchlist.Entries[0].AssetClass != domain.Stock {
+		t.Errorf("unexpected entries %+v", watchlist.Entries)
+	}
+
+	// Adding an entry again only replaces its notes
+	addedAt := watchlist.Entries[0].AddedAt
+	watchlist, _ = s.AddWatchlistEntries("u", "Tech", []domain.WatchlistEntry{{Symbol: "AAPL", Notes: "Below 170"}})
+	if len(watchlist.Entries) != 2 || watchlist.Entries[0].Notes != "Below 170" || watchlist.Entries[0].AddedAt != addedAt {
+		t.Errorf("unexpected entries %+v", watchlist.Entries)
+	}
+
+	// A missing symbol fails the whole removal
+	if _, err := s.RemoveWatchlistEntries("u", "Tech", []string{"AAPL", "MSFT"}); !goerrors.As(err, &errors.WatchlistEntryNotFoundError{}) {
+		t.Errorf("expected an entry not found error, got %v", err)
+	}
+	watchlist, _ = s.GetWatchlist("u", "Tech")
+	if len(watchlist.Entries) != 2 {
+		t.Errorf("expected the entries to be kept, got %+v", watchlist.Entries)
+	}
+
+	if _, err := s.RenameWatchlist("u", "Tech", "Ideas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DeleteWatchlist("u", "Tech"); !goerrors.As(err, &errors.WatchlistNotFoundError{}) {
+		t.Errorf("expected a watchlist not found error, got %v", err)
+	}
+	if _, err := s.DeleteWatchlist("u", "ideas"); err != nil {
+		t.Fatal(err)
+	}
+	if watchlists, _ := s.GetWatchlists("u"); len(watchlists) != 0 {
+		t.Errorf("expected no watchlists, got %+v", watchlists)
+	}
+}
+
+type stubWatchlistData struct {
+	stubPrices
+	stubEtfs
+}
+
+func (s stubWatchlistData) GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error) {
+	if symbol != "aapl" {
+		return nil, fmt.Errorf("no financial ratios found for %s", symbol)
+	}
+	return []domain.FinancialRatios{{Datekey: "TTM", Marketcap: 3e12, Pe: 30, DividendYield: 0.005}, {Pe: 25}}, nil
+}
+
+func TestGetWatchlistSnapshot(t *testing.T) {
+	watchlists, _ := NewWatchlistLocalDataService("")
+	watchlists.CreateWatchlist("u", "Tech")
+	watchlists.AddWatchlistEntries("u", "Tech", []domain.WatchlistEntry{
+		{Symbol: "AAPL"},
+		{Symbol: "QQQ", AssetClass: domain.ETF},
+		{Symbol: "BTC", AssetClass: domain.Crypto},
+		{Symbol: "UNKNOWN"},
+	})
+	data := stubWatchlistData{
+		stubPrices: stubPrices{"aapl": 200, "qqq": 500},
+		stubEtfs:   stubEtfs{"qqq": {PeRatio: "32.5", DividendYield: "0.55%", ExpenseRatio: "0.20%"}},
+	}
+	s, _ := NewWatchlistSnapshotService(watchlists, data, stubCrypto{})
+
+	snapshot, err := s.GetWatchlistSnapshot("u", "Tech")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(snapshot.Entries))
+	}
+
+	aapl, qqq, btc, unknown := snapshot.Entries[0], snapshot.Entries[1], snapshot.Entries[2], snapshot.Entries[3]
+	if aapl.Price != 200 || aapl.Change1Y == nil || aapl.Ratios.Pe != 30 || aapl.Ratios.DividendYield != 0.5 || len(aapl.Errors) != 0 {
+		t.Errorf("unexpected AAPL snapshot %+v", aapl)
+	}
+	if qqq.Price != 500 || qqq.Ratios.Pe != 32.5 || qqq.Ratios.ExpenseRatio != 0.2 || qqq.Ratios.DividendYield != 0.55 {
+		t.Errorf("unexpected QQQ snapshot %+v", qqq)
+	}
+	if btc.Price != 100000 || btc.Change1D == nil {
+		t.Errorf("unexpected BTC snapshot %+v", btc)
+	}
+	if unknown.Price != 0 || unknown.Change1D != nil || len(unknown.Errors) != 4 {
+		t.Errorf("expected the errors of the unknown symbol, got %+v", unknown)
+	}
+}
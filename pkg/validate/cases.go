@@ -0,0 +1,480 @@
+package validate
+
+// DefaultCases covers every tool of the server, the subjects are the ones the fake upstreams serve
+var DefaultCases = []Case{
+	{
+		Tool:      "stockSearch",
+		Arguments: map[string]any{"search_string": "Microsoft", "limit": 5},
+		Rules:     []Rule{NonEmpty("search_results"), NonEmpty("search_results[].symbol"), InRange("total_count", 1, 1e6)},
+	},
+	{
+		Tool:      "etfSearch",
+		Arguments: map[string]any{"search_string": "Vanguard", "limit": 5},
+		Rules:     []Rule{NonEmpty("search_results"), NonEmpty("search_results[].symbol"), InRange("total_count", 1, 1e6)},
+	},
+	{
+		Tool:      "getETF",
+		Arguments: map[string]any{"etf_symbol": "SPY"},
+		Rules:     []Rule{NonEmpty("symbol"), NonEmpty("top_holdings")},
+	},
+	{
+		Tool:  "getSuperInvestors",
+		Rules: []Rule{NonEmpty("super_investors"), NonEmpty("super_investors[].super_investor_name")},
+	},
+	{
+		Tool:      "getSuperInvestorPortfolio",
+		Arguments: map[string]any{"super_investor_name": "Warren Buffett - Berkshire Hathaway"},
+		Rules:     []Rule{NonEmpty("holdings"), NonEmpty("holdings[].stock"), InRange("holdings[].portfolio_pct", 0, 100)},
+	},
+	{
+		Tool:  "getMarketNews",
+		Rules: []Rule{NonEmpty("stock_symbol"), NonEmpty("stock_symbol[].title"), NonEmpty("stock_symbol[].url")},
+	},
+	{
+		Tool:      "getMarketNews",
+		Arguments: map[string]any{"stock_symbol": "AAPL"},
+		Rules:     []Rule{NonEmpty("stock_symbol"), NonEmpty("stock_symbol[].title")},
+	},
+	{
+		Tool:  "getSectors",
+		Rules: []Rule{NonEmpty("sectors"), NonEmpty("sectors[].name"), NonEmpty("sectors[].url_name"), InRange("sectors[].number_of_stocks", 1, 1e5)},
+	},
+	{
+		Tool:      "getSectorStocks",
+		Arguments: map[string]any{"url_name": "technology", "limit": 10},
+		Rules:     []Rule{NonEmpty("sector_stocks"), NonEmpty("sector_stocks[].symbol")},
+	},
+	{
+		Tool:      "getIndustries",
+		Arguments: map[string]any{"sector": "Technology", "min_pe_ratio": 1},
+		Rules:     []Rule{NonEmpty("industries"), NonEmpty("industries[].url_name"), NonEmpty("industries[].sector"), InRange("industries[].pe_ratio", 1, 10000)},
+	},
+	{
+		Tool:      "getIndustryStocks",
+		Arguments: map[string]any{"url_name": "semiconductors", "sort": "symbol", "order": "asc", "limit": 5},
+		Rules:     []Rule{Length("industry_stocks", 5), NonEmpty("industry.name"), NonEmpty("industry.sector")},
+	},
+	{
+		Tool:      "getStockOverview",
+		Arguments: map[string]any{"stock_symbol": "AAPL"},
+		Rules:     []Rule{NonEmpty("symbol"), NonEmpty("stock_profile.name"), NonEmpty("stock_historical_performance")},
+	},
+	{
+		Tool: "getStockFinancials",
+		Arguments: map[string]any{
+			"stock_symbol":              "MSFT",
+			"include_balance_sheets":    true,
+			"include_income_statements": true,
+			"include_cash_flows":        true,
+			"limit":                     4,
+		},
+		Rules: []Rule{NonEmpty("balance_sheets"), NonEmpty("income_statements"), NonEmpty("cash_flows")},
+	},
+	{
+		Tool:      "getEconomicIndicatorTimeSeries",
+		Arguments: map[string]any{"indicator_name": "Inflation", "limit": 5},
+		Rules:     []Rule{NonEmpty("data"), NonEmpty("data[].date"), InRange("data[].value", -50, 1000)},
+	},
+	{
+		Tool:      "getEconomicIndicatorTimeSeries",
+		Arguments: map[string]any{"indicator_name": "TreasuryYield", "treasury_yield_maturity": "10Y", "limit": 5},
+		Rules:     []Rule{NonEmpty("data"), InRange("data[].value", -5, 30)},
+	},
+	{
+		Tool:      "getCommodityTimeSeries",
+		Arguments: map[string]any{"commodity_name": "CrudeOil", "limit": 5},
+		Rules:     []Rule{NonEmpty("data"), NonEmpty("data[].date")},
+	},
+	{
+		Tool:      "searchCryptocurrencies",
+		Arguments: map[string]any{"search_query": "Bitcoin", "limit": 5},
+		Rules:     []Rule{NonEmpty("results"), NonEmpty("results[].id")},
+	},
+	{
+		Tool:      "getCryptocurrencyDataById",
+		Arguments: map[string]any{"id": "bitcoin"},
+		Rules:     []Rule{InRange("current_usd_price", 0.000001, 1e8)},
+	},
+	{
+		Tool:      "getCryptocurrencyNews",
+		Arguments: map[string]any{"symbol": "BTC"},
+		Rules:     []Rule{NonEmpty("news")},
+	},
+	{
+		Tool:      "calculateInvestmentFutureValue",
+		Arguments: map[string]any{"initial_investment": 10000.0, "annual_return": 8.0, "years": 10},
+		Rules:     []Rule{InRange("future_value", 21589, 21590)},
+	},
+	{
+		Tool:      "getEarningsCallTranscript",
+		Arguments: map[string]any{"stock_symbol": "MSFT", "year": 2024, "quarter": "Q1"},
+		Rules:     []Rule{NonEmpty("earnings_call_transcripts")},
+	},
+	{
+		Tool:      "getInsiderTransactions",
+		Arguments: map[string]any{"stock_symbol": "TSLA", "year": 2025},
+		Rules:     []Rule{NonEmpty("insider_transactions")},
+	},
+	{
+		Tool:      "getCompanyKpiMetrics",
+		Arguments: map[string]any{"stock_symbol": "TSLA"},
+		Rules:     []Rule{NonEmpty("kpi_metrics_categories")},
+	},
+	{
+		Tool:  "getInvestingIdeas",
+		Rules: []Rule{NonEmpty("investing_ideas"), NonEmpty("investing_ideas[].idea_id"), NonEmpty("investing_ideas[].title")},
+	},
+	{
+		Tool:      "getInvestingIdeaStocks",
+		Arguments: map[string]any{"idea_id": "5919d698-0a14-4a64-8c62-3bd8763e37f1"},
+		Rules:     []Rule{NonEmpty("stocks")},
+	},
+	{
+		Tool:      "getCurrencyExchangeRate",
+		Arguments: map[string]any{"from_currency": "EUR", "to_currency": "USD"},
+		Rules:     []Rule{InRange("rate", 0.01, 100)},
+	},
+	{
+		Tool:      "search",
+		Arguments: map[string]any{"query": "apple", "limit": 5},
+		Rules:     []Rule{NonEmpty("results")},
+	},
+	// The user context cases are chained: each change is based on the updated_at of the previous output. The
+	// full replacement resets the context of the validation user, whatever the previous runs left.
+	{
+		Tool: "updateUserContext",
+		Arguments: map[string]any{
+			"user_id":        "validate",
+			"user_profile":   map[string]any{"age": 40, "risk_tolerance": "medium"},
+			"user_portfolio": []any{map[string]any{"asset_class": "stock", "symbol": "AAPL", "name": "Apple", "quantity": 10, "portfolio_percentage": 0}},
+		},
+		Rules: []Rule{Length("user_portfolio", 1), NonEmpty("updated_at")},
+	},
+	{
+		Tool:      "getUserContext",
+		Arguments: map[string]any{"user_id": "validate"},
+		Rules:     []Rule{Length("user_portfolio", 1), NonEmpty("user_profile")},
+	},
+	{
+		Tool: "upsertPortfolioHolding",
+		Arguments: map[string]any{
+			"user_id": "validate",
+			"holding": map[string]any{"asset_class": "stock", "symbol": "MSFT", "name": "Microsoft", "quantity": 5, "portfolio_percentage": 0},
+		},
+		From:  map[string]string{"expected_updated_at": "updated_at"},
+		Rules: []Rule{Length("user_portfolio", 2)},
+	},
+	{
+		Tool:      "adjustHoldingQuantity",
+		Arguments: map[string]any{"user_id": "validate", "symbol": "AAPL", "quantity_delta": 5},
+		From:      map[string]string{"expected_updated_at": "updated_at"},
+		Rules:     []Rule{Length("user_portfolio", 2), InRange("user_portfolio[].quantity", 5, 15)},
+	},
+	{
+		Tool:      "patchUserProfile",
+		Arguments: map[string]any{"user_id": "validate", "patch": map[string]any{"risk_tolerance": "high", "age": nil}},
+		From:      map[string]string{"expected_updated_at": "updated_at"},
+		Rules:     []Rule{NonEmpty("user_profile.risk_tolerance")},
+	},
+	{
+		Tool:      "removePortfolioHolding",
+		Arguments: map[string]any{"user_id": "validate", "symbol": "MSFT"},
+		From:      map[string]string{"expected_updated_at": "updated_at"},
+		Rules:     []Rule{Length("user_portfolio", 1)},
+	},
+	{
+		Tool:      "getUserContextHistory",
+		Arguments: map[string]any{"user_id": "validate", "limit": 5},
+		Rules:     []Rule{Length("changes", 5), NonEmpty("changes[].summary")},
+	},
+	{
+		// Reverts the removal, the last change: its created_at is the current updated_at
+		Tool:      "revertUserContextChange",
+		Arguments: map[string]any{"user_id": "validate"},
+		From:      map[string]string{"change_id": "changes[].id", "expected_updated_at": "changes[].created_at"},
+		Rules:     []Rule{Length("user_portfolio", 2)},
+	},
+	{
+		Tool:      "clearUserPortfolio",
+		Arguments: map[string]any{"user_id": "validate"},
+		From:      map[string]string{"expected_updated_at": "updated_at"},
+		Rules:     []Rule{Length("user_portfolio", 0), NonEmpty("user_profile")},
+	},
+	// The portfolio cases value the portfolio of a second validation user, with a holding of each asset class
+	{
+		Tool: "updateUserContext",
+		Arguments: map[string]any{
+			"user_id":      "validate_portfolio",
+			"user_profile": map[string]any{"risk_tolerance": "medium"},
+			"user_portfolio": []any{
+				map[string]any{"asset_class": "stock", "symbol": "AAPL", "name": "Apple", "quantity": 20, "portfolio_percentage": 40},
+				map[string]any{"asset_class": "etf", "symbol": "QQQ", "name": "Invesco QQQ", "quantity": 10, "portfolio_percentage": 30},
+				map[string]any{"asset_class": "crypto", "symbol": "BTC", "name": "Bitcoin", "quantity": 0.05, "portfolio_percentage": 0},
+				map[string]any{"asset_class": "cash", "symbol": "EUR", "name": "Savings", "quantity": 5000, "portfolio_percentage": 0},
+				map[string]any{"asset_class": "real_estate", "symbol": "", "name": "Apartment", "quantity": 1, "portfolio_percentage": 0},
+			},
+		},
+		Rules: []Rule{Length("user_portfolio", 5)},
+	},
+	{
+		Tool:      "getPortfolioValuation",
+		Arguments: map[string]any{"user_id": "validate_portfolio", "base_currency": "EUR"},
+		Rules:     []Rule{Length("holdings", 4), Length("unpriced_holdings", 1), InRange("holdings[].market_value", 0.01, 1e7), InRange("holdings[].weight", 0, 100)},
+	},
+	{
+		Tool:      "getPortfolioExposure",
+		Arguments: map[string]any{"user_id": "validate_portfolio", "names_limit": 10},
+		Rules:     []Rule{NonEmpty("asset_classes"), NonEmpty("sectors"), NonEmpty("names"), InRange("concentration.herfindahl_index", 0.0001, 1), InRange("look_through_coverage", 1, 100)},
+	},
+	{
+		Tool:      "getPortfolioExposure",
+		Arguments: map[string]any{"user_id": "validate_portfolio", "weighting": "stated"},
+		Rules:     []Rule{Length("asset_classes", 2), Length("excluded_holdings", 3)},
+	},
+	{
+		Tool:      "getRiskMetrics",
+		Arguments: map[string]any{"user_id": "validate_portfolio", "period": "6m"},
+		Rules:     []Rule{Length("holdings", 4), Length("excluded", 1), InRange("annualized_volatility", 0.1, 200), InRange("correlation", -1, 1)},
+	},
+	// The ledger cases record the transactions of a third validation user, whose ledger is only kept in memory
+	{
+		Tool:      "recordTransaction",
+		Arguments: map[string]any{"user_id": "validate_ledger", "type": "deposit", "date": "2025-01-02", "amount": 10000},
+		Rules:     []Rule{NonEmpty("transaction_id")},
+	},
+	{
+		Tool:      "recordTransaction",
+		Arguments: map[string]any{"user_id": "validate_ledger", "type": "buy", "date": "2025-01-03", "symbol": "AAPL", "quantity": 20, "price": 180, "fees": 1},
+		Rules:     []Rule{NonEmpty("transaction_id")},
+	},
+	{
+		Tool:      "recordTransaction",
+		Arguments: map[string]any{"user_id": "validate_ledger", "type": "buy", "date": "2025-02-03", "asset_class": "etf", "symbol": "QQQ", "quantity": 5, "price": 450},
+		Rules:     []Rule{NonEmpty("transaction_id")},
+	},
+	{
+		Tool:      "recordTransaction",
+		Arguments: map[string]any{"user_id": "validate_ledger", "type": "buy", "date": "2025-03-03", "symbol": "AAPL", "quantity": 10, "price": 200, "fees": 1},
+		Rules:     []Rule{NonEmpty("transaction_id")},
+	},
+	{
+		Tool:      "recordTransaction",
+		Arguments: map[string]any{"user_id": "validate_ledger", "type": "dividend", "date": "2025-05-15", "symbol": "AAPL", "amount": 7.5},
+		Rules:     []Rule{NonEmpty("transaction_id")},
+	},
+	{
+		Tool:      "recordTransaction",
+		Arguments: map[string]any{"user_id": "validate_ledger", "type": "sell", "date": "2025-06-02", "symbol": "AAPL", "quantity": 15, "price": 220, "fees": 1},
+		Rules:     []Rule{NonEmpty("transaction_id")},
+	},
+	{
+		Tool:      "recordTransaction",
+		Arguments: map[string]any{"user_id": "validate_ledger", "type": "fee", "date": "2025-06-30", "amount": 10, "notes": "Custody fee"},
+		Rules:     []Rule{NonEmpty("transaction_id")},
+	},
+	{
+		// Recorded by mistake, then deleted
+		Tool:      "recordTransaction",
+		Arguments: map[string]any{"user_id": "validate_ledger", "type": "withdrawal", "date": "2025-07-01", "amount": 100},
+		Rules:     []Rule{NonEmpty("transaction_id")},
+	},
+	{
+		Tool:      "deleteTransaction",
+		Arguments: map[string]any{"user_id": "validate_ledger"},
+		From:      map[string]string{"transaction_id": "transaction_id"},
+		Rules:     []Rule{NonEmpty("type")},
+	},
+	{
+		Tool:      "listTransactions",
+		Arguments: map[string]any{"user_id": "validate_ledger"},
+		Rules:     []Rule{Length("transactions", 7)},
+	},
+	{
+		Tool:      "getPortfolioPerformance",
+		Arguments: map[string]any{"user_id": "validate_ledger", "include_lots": true},
+		Rules:     []Rule{Length("positions", 2), NonEmpty("positions[].lots"), NonEmpty("realized_pnl"), InRange("net_deposits", 10000, 10000)},
+	},
+	{
+		Tool:      "getPortfolioPerformance",
+		Arguments: map[string]any{"user_id": "validate_ledger", "base_currency": "EUR", "cost_basis_method": "average"},
+		Rules:     []Rule{Length("positions", 2), InRange("market_value", 0.01, 1e7)},
+	},
+	// The watchlist cases delete the watchlist they create, so that they can run again against a running server
+	{
+		Tool:      "createWatchlist",
+		Arguments: map[string]any{"user_id": "validate_watchlist", "name": "Ideas"},
+		Rules:     []Rule{Length("entries", 0), NonEmpty("created_at")},
+	},
+	{
+		Tool: "addWatchlistEntries",
+		Arguments: map[string]any{
+			"user_id": "validate_watchlist",
+			"name":    "ideas",
+			"entries": []any{
+				map[string]any{"symbol": "AAPL", "notes": "Buy below 180"},
+				map[string]any{"symbol": "MSFT", "asset_class": "stock"},
+				map[string]any{"symbol": "QQQ", "asset_class": "etf"},
+				map[string]any{"symbol": "BTC", "asset_class": "crypto"},
+			},
+		},
+		Rules: []Rule{Length("entries", 4), NonEmpty("entries[].added_at")},
+	},
+	{
+		Tool:      "removeWatchlistEntries",
+		Arguments: map[string]any{"user_id": "validate_watchlist", "name": "Ideas", "symbols": []any{"MSFT"}},
+		Rules:     []Rule{Length("entries", 3)},
+	},
+	{
+		Tool:      "renameWatchlist",
+		Arguments: map[string]any{"user_id": "validate_watchlist", "name": "Ideas", "new_name": "Tech ideas"},
+		Rules:     []Rule{Length("entries", 3)},
+	},
+	{
+		Tool:      "listWatchlists",
+		Arguments: map[string]any{"user_id": "validate_watchlist"},
+		Rules:     []Rule{Length("watchlists", 1), Length("watchlists[].entries", 3)},
+	},
+	{
+		Tool:      "getWatchlistSnapshot",
+		Arguments: map[string]any{"user_id": "validate_watchlist", "name": "Tech ideas"},
+		Rules:     []Rule{Length("entries", 3), InRange("entries[].price", 0.0001, 1e7), NonEmpty("entries[].change_1m"), InRange("entries[].ratios.pe", 0, 1000)},
+	},
+	{
+		Tool:      "deleteWatchlist",
+		Arguments: map[string]any{"user_id": "validate_watchlist", "name": "Tech ideas"},
+		Rules:     []Rule{Length("entries", 3)},
+	},
+	// The alert case deletes the alert it creates, its event is the last one of the user
+	{
+		Tool:      "createAlert",
+		Arguments: map[string]any{"user_id": "validate_alerts", "type": "price_above", "symbol": "AAPL", "threshold": 1, "cooldown_minutes": 5},
+		Rules:     []Rule{InRange("id", 1, 1e6), NonEmpty("created_at")},
+	},
+	{
+		Tool:      "checkAlerts",
+		Arguments: map[string]any{"user_id": "validate_alerts"},
+		Rules:     []Rule{Length("events", 1), InRange("events[].value", 1, 1e7), Length("alerts", 1), NonEmpty("alerts[].last_evaluated_at")},
+	},
+	{
+		Tool:      "listAlerts",
+		Arguments: map[string]any{"user_id": "validate_alerts"},
+		Rules:     []Rule{Length("alerts", 1), NonEmpty("alerts[].last_triggered_at")},
+	},
+	{
+		Tool:      "getAlertEvents",
+		Arguments: map[string]any{"user_id": "validate_alerts", "limit": 1},
+		Rules:     []Rule{Length("events", 1), NonEmpty("events[].message")},
+	},
+	{
+		Tool:      "deleteAlert",
+		Arguments: map[string]any{"user_id": "validate_alerts"},
+		From:      map[string]string{"alert_id": "events[].alert_id"},
+		Rules:     []Rule{NonEmpty("last_triggered_at")},
+	},
+	{
+		Tool:      "getHistoricalPrices",
+		Arguments: map[string]any{"symbol": "AAPL", "period": "ytd"},
+		Rules:     []Rule{NonEmpty("prices"), NonEmpty("prices[].date"), InRange("prices[].close", 1, 1e5), NonEmpty("source")},
+	},
+	{
+		Tool:      "getHistoricalPrices",
+		Arguments: map[string]any{"symbol": "AAPL", "start_date": "2024-01-01", "end_date": "2024-12-31", "max_points": 2},
+		Rules:     []Rule{Length("prices", 2), InRange("source_points", 3, 1e4), InRange("prices[].close", 1, 1e5)},
+	},
+	{
+		Tool:      "getHistoricalPrices",
+		Arguments: map[string]any{"symbol": "SPY", "asset_class": "etf", "period": "1m", "ohlcv": true},
+		Rules:     []Rule{NonEmpty("prices"), InRange("prices[].open", 1, 1e5), InRange("prices[].high", 1, 1e5), InRange("prices[].volume", 1, 1e12)},
+	},
+	{
+		Tool:      "getHistoricalPrices",
+		Arguments: map[string]any{"symbol": "BTC", "asset_class": "crypto", "period": "1m"},
+		Rules:     []Rule{NonEmpty("prices"), InRange("prices[].close", 1, 1e7), InRange("prices[].volume", 1, 1e13)},
+	},
+	{
+		Tool:      "getTechnicalIndicators",
+		Arguments: map[string]any{"symbol": "AAPL"},
+		Rules: []Rule{
+			Length("sma", 3), InRange("sma[].value", 1, 1e5), InRange("rsi.value", 0, 100), NonEmpty("macd"),
+			InRange("bollinger_bands.upper", 1, 1e5), InRange("52_week_range.high", 1, 1e5), NonEmpty("moving_average_crosses"),
+			Length("unavailable", 2),
+		},
+	},
+	{
+		Tool:      "getTechnicalIndicators",
+		Arguments: map[string]any{"symbol": "SPY", "asset_class": "etf", "period": "1m", "ohlcv": true, "indicators": []string{"atr", "vwap", "rsi"}, "series_points": 5},
+		Rules:     []Rule{InRange("atr.value", 0.01, 1e4), InRange("vwap.value", 1, 1e5), Length("rsi.series", 5)},
+	},
+	{
+		Tool:      "getTechnicalIndicators",
+		Arguments: map[string]any{"symbol": "BTC", "asset_class": "crypto", "period": "6m", "indicators": []string{"ema", "vwap"}, "parameters": map[string]any{"ema_periods": []int{10}}},
+		Rules:     []Rule{Length("ema", 1), InRange("ema[].value", 1, 1e7), InRange("vwap.value", 1, 1e7)},
+	},
+	{
+		Tool:      "getRiskMetrics",
+		Arguments: map[string]any{"symbol": "AAPL"},
+		Rules: []Rule{
+			InRange("observations", 200, 300), InRange("annualized_volatility", 1, 200), InRange("max_drawdown.percentage", 0.01, 100),
+			NonEmpty("max_drawdown.peak_date"), InRange("risk_free_rate", 0.01, 20), InRange("beta", -5, 5), InRange("value_at_risk", 0.01, 50),
+		},
+	},
+	{
+		Tool:      "getRiskMetrics",
+		Arguments: map[string]any{"symbol": "BTC", "asset_class": "crypto", "benchmark": "QQQ", "period": "6m", "confidence": 0.99},
+		Rules:     []Rule{InRange("correlation", -1, 1), InRange("expected_shortfall", 0.01, 100), InRange("confidence", 0.99, 0.99)},
+	},
+	{
+		Tool:      "compareStocks",
+		Arguments: map[string]any{"symbols": []string{"AAPL", "MSFT", "GOOGL"}, "metric_sets": []string{"valuation", "performance"}},
+		Rules:     []Rule{Length("symbols", 3), Length("metrics", 13), Length("metrics[].values", 3), NonEmpty("metrics[].name")},
+	},
+	{
+		Tool: "screenStocks",
+		Arguments: map[string]any{
+			"filters": []map[string]any{
+				{"field": "sector", "operator": "eq", "text": "Technology"},
+				{"field": "gross_margin", "operator": "gt", "value": 50},
+			},
+			"columns": []string{"market_cap", "gross_margin"},
+			"limit":   5,
+		},
+		Rules: []Rule{
+			Length("stocks", 5), InRange("matches", 5, 500), Length("pushed_filters", 1), Length("local_filters", 1),
+			InRange("stocks[].values.gross_margin", 50, 100),
+		},
+	},
+	{
+		Tool: "screenStocks",
+		Arguments: map[string]any{
+			"filters": []map[string]any{
+				{"field": "market_cap", "operator": "between", "value": 1e11, "upper": 1e12},
+				{"field": "dividend_yield", "operator": "gt", "value": 2},
+			},
+			"sort":    "pe",
+			"order":   "asc",
+			"columns": []string{"pe", "dividend_yield"},
+		},
+		Rules: []Rule{NonEmpty("stocks"), InRange("stocks[].values.dividend_yield", 2, 20)},
+	},
+	{
+		Tool:      "getPeerComparison",
+		Arguments: map[string]any{"symbol": "NVDA", "peers": 5},
+		Rules: []Rule{
+			NonEmpty("industry"), NonEmpty("industry_url_name"), Length("peers", 5), Length("metrics", 12),
+			NonEmpty("sets"), InRange("sets[].percentile", 0, 100),
+		},
+	},
+	{
+		Tool:      "calculateDcf",
+		Arguments: map[string]any{"symbol": "AAPL"},
+		Rules: []Rule{
+			NonEmpty("intrinsic_value_per_share"), NonEmpty("wacc.rate"), Length("projections", 10),
+			Length("sensitivity", 5), Length("sensitivity[].values", 5),
+		},
+	},
+	{
+		Tool:      "calculateDcf",
+		Arguments: map[string]any{"symbol": "MSFT", "terminal_method": "exit_multiple", "exit_multiple": 20, "discount_rate_pct": 9, "fade_years": 0},
+		Rules:     []Rule{Length("projections", 5), InRange("wacc.rate", 9, 9), InRange("exit_multiple", 20, 20)},
+	},
+}
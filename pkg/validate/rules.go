@@ -0,0 +1,141 @@
+package validate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule is a semantic check of a tool output, on top of its output schema
+type Rule struct {
+	Description string
+	Check       func(output map[string]any) error
+}
+
+// NonEmpty checks that the values at the path are not empty (strings, lists and objects) nor null.
+// The path is dotted and "[]" goes through every item of a list, e.g. "sectors[].name".
+func NonEmpty(path string) Rule {
+	return Rule{
+		Description: path + " is not empty",
+		Check: func(output map[string]any) error {
+			values, err := lookup(output, path)
+			if err != nil {
+				return err
+			}
+			for i, value := range values {
+				if isEmpty(value) {
+					return fmt.Errorf("%s is empty (value %d of %d)", path, i+1, len(values))
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// InRange checks that the values at the path are numbers (or numeric strings) between min and max
+func InRange(path string, min float64, max float64) Rule {
+	return Rule{
+		Description: fmt.Sprintf("%s is between %v and %v", path, min, max),
+		Check: func(output map[string]any) error {
+			values, err := lookup(output, path)
+			if err != nil {
+				return err
+			}
+			for _, value := range values {
+				number, ok := toNumber(value)
+				if !ok {
+					return fmt.Errorf("%s is not a number: %v", path, value)
+				}
+				if number < min || number > max {
+					return fmt.Errorf("%s is out of range: %v", path, number)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// Length checks that the lists at the path have the given number of items
+func Length(path string, length int) Rule {
+	return Rule{
+		Description: fmt.Sprintf("%s has %d items", path, length),
+		Check: func(output map[string]any) error {
+			values, err := lookup(output, path)
+			if err != nil {
+				return err
+			}
+			for _, value := range values {
+				items, ok := value.([]any)
+				if !ok {
+					return fmt.Errorf("%s is not a list", path)
+				}
+				if len(items) != length {
+					return fmt.Errorf("%s has %d items, expected %d", path, len(items), length)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// lookup returns the values at the path, an error if a field of the path is missing
+func lookup(value any, path string) ([]any, error) {
+	values := []any{value}
+	traversed := ""
+
+	for _, segment := range strings.Split(path, ".") {
+		name, each := strings.CutSuffix(segment, "[]")
+		traversed = strings.TrimPrefix(traversed+"."+name, ".")
+
+		next := make([]any, 0, len(values))
+		for _, v := range values {
+			object, ok := v.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s is not an object", strings.TrimSuffix(traversed, "."+name))
+			}
+			field, ok := object[name]
+			if !ok {
+				return nil, fmt.Errorf("%s is missing", traversed)
+			}
+			if !each {
+				next = append(next, field)
+				continue
+			}
+			items, ok := field.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a list", traversed)
+			}
+			next = append(next, items...)
+		}
+		values = next
+	}
+
+	return values, nil
+}
+
+func isEmpty(value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(value) == ""
+	case []any:
+		return len(value) == 0
+	case map[string]any:
+		return len(value) == 0
+	default:
+		return false
+	}
+}
+
+func toNumber(value any) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
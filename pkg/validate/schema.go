@@ -0,0 +1,115 @@
+package validate
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// validateSchema returns the violations of a JSON value against a JSON schema. It supports the subset of JSON schema
+// mcp-go generates for the tool outputs: type, properties, required, items, enum, minimum, maximum and additionalProperties.
+func validateSchema(schema map[string]any, value any, path string) []string {
+	if !matchesType(schema["type"], value) {
+		return []string{fmt.Sprintf("%s: expected %v, got %s", path, schema["type"], jsonType(value))}
+	}
+
+	var violations []string
+
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		violations = append(violations, fmt.Sprintf("%s: %v is not one of %v", path, value, enum))
+	}
+
+	if number, ok := value.(float64); ok {
+		if minimum, ok := schema["minimum"].(float64); ok && number < minimum {
+			violations = append(violations, fmt.Sprintf("%s: %v is less than the minimum %v", path, number, minimum))
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && number > maximum {
+			violations = append(violations, fmt.Sprintf("%s: %v is greater than the maximum %v", path, number, maximum))
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := value[fmt.Sprint(name)]; !ok {
+					violations = append(violations, fmt.Sprintf("%s.%v: required property is missing", path, name))
+				}
+			}
+		}
+
+		// Sorted so that the report is stable
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if propertySchema, ok := properties[name].(map[string]any); ok {
+				violations = append(violations, validateSchema(propertySchema, value[name], path+"."+name)...)
+			} else if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+				violations = append(violations, fmt.Sprintf("%s.%s: property is not allowed", path, name))
+			}
+		}
+	case []any:
+		if itemSchema, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				violations = append(violations, validateSchema(itemSchema, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+
+	return violations
+}
+
+// matchesType returns true if the value has the schema type, which is a type name, a list of type names or nothing
+func matchesType(schemaType any, value any) bool {
+	switch schemaType := schemaType.(type) {
+	case nil:
+		return true
+	case string:
+		return hasType(schemaType, value)
+	case []any:
+		return slices.ContainsFunc(schemaType, func(t any) bool {
+			name, ok := t.(string)
+			return ok && hasType(name, value)
+		})
+	default:
+		return false
+	}
+}
+
+func hasType(name string, value any) bool {
+	switch name {
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	default:
+		return jsonType(value) == name
+	}
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,241 @@
+// Package validate calls every tool of a running MCP server with a set of arguments and checks that the
+// structured outputs conform to the output schemas the tools declare and to semantic rules (non-empty
+// lists, sane ranges). It's the end-to-end check of the server, run in-process or against a deployment.
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Case is a call of a tool whose output is checked
+type Case struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// From sets arguments from the output of the previous case, to chain the calls of stateful tools.
+	// It maps an argument name to a path in that output (see NonEmpty), the first value at the path is used.
+	From  map[string]string `json:"from,omitempty"`
+	Rules []Rule            `json:"-"`
+}
+
+// Result is the result of a case
+type Result struct {
+	Tool       string         `json:"tool"`
+	Arguments  map[string]any `json:"arguments,omitempty"`
+	Passed     bool           `json:"passed"`
+	DurationMs int64          `json:"durationMs"`
+	Errors     []string       `json:"errors,omitempty"`
+}
+
+// Report is the result of a validation run
+type Report struct {
+	Tools   int      `json:"tools"`
+	Cases   int      `json:"cases"`
+	Failed  int      `json:"failed"`
+	Results []Result `json:"results"`
+}
+
+func (r Report) Passed() bool {
+	return r.Failed == 0
+}
+
+// LoadCases reads cases from a JSON file, the rules of the default cases of the same tool apply to them
+func LoadCases(path string) ([]Case, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []Case
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("failed to parse the cases of %s: %w", path, err)
+	}
+
+	rules := make(map[string][]Rule)
+	for _, c := range DefaultCases {
+		rules[c.Tool] = c.Rules
+	}
+	for i := range cases {
+		cases[i].Rules = rules[cases[i].Tool]
+	}
+
+	return cases, nil
+}
+
+// Run lists the tools of the server and runs the cases against them. Every tool must have at least one case
+// so that a new tool can't go unvalidated, and every case must target a registered tool.
+func Run(ctx context.Context, mcpClient *client.Client, cases []Case) (Report, error) {
+	listResult, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to list the tools: %w", err)
+	}
+
+	tools := make(map[string]mcp.Tool, len(listResult.Tools))
+	for _, tool := range listResult.Tools {
+		tools[tool.Name] = tool
+	}
+
+	report := Report{Tools: len(tools)}
+	covered := make(map[string]bool)
+
+	var previous map[string]any
+	for _, c := range cases {
+		var result Result
+		result, previous = runCase(ctx, mcpClient, tools, c, previous)
+		covered[c.Tool] = true
+		report.Cases++
+		if !result.Passed {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	var uncovered []string
+	for name := range tools {
+		if !covered[name] {
+			uncovered = append(uncovered, name)
+		}
+	}
+	sort.Strings(uncovered)
+
+	for _, name := range uncovered {
+		report.Failed++
+		report.Results = append(report.Results, Result{Tool: name, Errors: []string{"no case validates the tool"}})
+	}
+
+	return report, nil
+}
+
+// runCase runs the case and returns its result and the output of the tool, nil if the call failed.
+// previous is the output of the previous case, the arguments of From are read from it.
+func runCase(ctx context.Context, mcpClient *client.Client, tools map[string]mcp.Tool, c Case, previous map[string]any) (result Result, output map[string]any) {
+	result = Result{Tool: c.Tool, Arguments: c.Arguments}
+	start := time.Now()
+	defer func() { result.DurationMs = time.Since(start).Milliseconds() }()
+
+	tool, ok := tools[c.Tool]
+	if !ok {
+		result.Errors = []string{"the tool isn't registered"}
+		return result, nil
+	}
+
+	arguments, err := chainArguments(c, previous)
+	if err != nil {
+		result.Errors = []string{err.Error()}
+		return result, nil
+	}
+	result.Arguments = arguments
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = c.Tool
+	request.Params.Arguments = arguments
+
+	callResult, err := mcpClient.CallTool(ctx, request)
+	if err != nil {
+		result.Errors = []string{err.Error()}
+		return result, nil
+	}
+	if callResult.IsError {
+		result.Errors = []string{"the tool returned an error: " + textContent(callResult)}
+		return result, nil
+	}
+
+	// Round trip so that the output is checked as a client sees it, whatever the transport
+	if err := roundTrip(callResult.StructuredContent, &output); err != nil || output == nil {
+		result.Errors = []string{"the tool returned no structured output"}
+		return result, nil
+	}
+
+	var schema map[string]any
+	if err := roundTrip(tool.OutputSchema, &schema); err != nil {
+		result.Errors = []string{fmt.Sprintf("failed to read the output schema: %v", err)}
+		return result, output
+	}
+	if len(schema) > 0 {
+		result.Errors = append(result.Errors, validateSchema(schema, output, "$")...)
+	} else {
+		result.Errors = append(result.Errors, "the tool declares no output schema")
+	}
+
+	for _, rule := range c.Rules {
+		if err := rule.Check(output); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+
+	result.Passed = len(result.Errors) == 0
+	return result, output
+}
+
+// chainArguments returns the arguments of the case with the ones of From read from the previous output
+func chainArguments(c Case, previous map[string]any) (map[string]any, error) {
+	if len(c.From) == 0 {
+		return c.Arguments, nil
+	}
+	if previous == nil {
+		return nil, fmt.Errorf("the arguments %v are read from the previous case, which has no output", slices.Sorted(maps.Keys(c.From)))
+	}
+
+	arguments := maps.Clone(c.Arguments)
+	if arguments == nil {
+		arguments = make(map[string]any)
+	}
+	for name, path := range c.From {
+		values, err := lookup(previous, path)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w in the previous output", name, err)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("argument %s: %s has no value in the previous output", name, path)
+		}
+		arguments[name] = values[0]
+	}
+	return arguments, nil
+}
+
+// PrintReport writes one line per case and a summary
+func PrintReport(w io.Writer, report Report) {
+	for _, result := range report.Results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+		}
+
+		arguments, _ := json.Marshal(result.Arguments)
+		if result.Arguments == nil {
+			arguments = []byte("{}")
+		}
+		fmt.Fprintf(w, "[%s] %s(%s) %dms\n", status, result.Tool, arguments, result.DurationMs)
+		for _, err := range result.Errors {
+			fmt.Fprintf(w, "       %s\n", err)
+		}
+	}
+	fmt.Fprintf(w, "%d tools, %d cases, %d failed\n", report.Tools, report.Cases, report.Failed)
+}
+
+func roundTrip(from any, to any) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+func textContent(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
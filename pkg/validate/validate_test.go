@@ -0,0 +1,138 @@
+package validate
+
+import (
+	"context"
+	"market_data_mcp_server/pkg/app"
+	"market_data_mcp_server/pkg/config"
+	"market_data_mcp_server/pkg/fakeupstreams"
+	"market_data_mcp_server/pkg/services"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestValidateSchema(t *testing.T) {
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"symbol", "prices"},
+		"properties": map[string]any{
+			"symbol": map[string]any{"type": "string", "enum": []any{"AAPL", "MSFT"}},
+			"prices": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":                 "object",
+					"additionalProperties": false,
+					"properties": map[string]any{
+						"close":  map[string]any{"type": "number", "minimum": 0.0},
+						"volume": map[string]any{"type": "integer"},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		value map[string]any
+		want  int
+	}{
+		{
+			name:  "valid",
+			value: map[string]any{"symbol": "AAPL", "prices": []any{map[string]any{"close": 10.5, "volume": 100.0}}},
+		},
+		{
+			name:  "missing required property",
+			value: map[string]any{"symbol": "AAPL"},
+			want:  1,
+		},
+		{
+			name:  "null list",
+			value: map[string]any{"symbol": "AAPL", "prices": nil},
+			want:  1,
+		},
+		{
+			name:  "invalid items",
+			value: map[string]any{"symbol": "TSLA", "prices": []any{map[string]any{"close": -1.0, "volume": 1.5, "open": 1.0}}},
+			want:  4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := validateSchema(schema, tt.value, "$")
+			if len(violations) != tt.want {
+				t.Errorf("expected %d violations, got %v", tt.want, violations)
+			}
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	output := map[string]any{
+		"rate":    "1.0850",
+		"sectors": []any{map[string]any{"name": "Technology", "stocks": 12.0}, map[string]any{"name": " ", "stocks": 0.0}},
+	}
+
+	tests := []struct {
+		rule    Rule
+		wantErr bool
+	}{
+		{rule: InRange("rate", 0.01, 100)},
+		{rule: InRange("rate", 2, 100), wantErr: true},
+		{rule: NonEmpty("sectors")},
+		{rule: NonEmpty("sectors[].name"), wantErr: true},
+		{rule: InRange("sectors[].stocks", 1, 100), wantErr: true},
+		{rule: NonEmpty("industries"), wantErr: true},
+		{rule: NonEmpty("rate[].name"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule.Description, func(t *testing.T) {
+			if err := tt.rule.Check(output); (err != nil) != tt.wantErr {
+				t.Errorf("expected an error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRunAgainstFakeUpstreams(t *testing.T) {
+	upstreams := httptest.NewServer(fakeupstreams.NewHandler())
+	defer upstreams.Close()
+
+	conf := config.Config{
+		StockAnalysisBaseURL:    upstreams.URL + fakeupstreams.StockAnalysisPrefix,
+		StockAnalysisApiBaseURL: upstreams.URL + fakeupstreams.StockAnalysisApiPrefix,
+		DataromaBaseURL:         upstreams.URL + fakeupstreams.DataromaPrefix,
+		AlphaVantageBaseURL:     upstreams.URL + fakeupstreams.AlphaVantagePrefix + "/query",
+		CoinGeckoBaseURL:        upstreams.URL + fakeupstreams.CoinGeckoPrefix + "/api/v3",
+		InvestingIdeasDataPath:  "../../static_data/investing_ideas.json",
+	}
+
+	ctx := context.Background()
+	mcpClient, err := client.NewInProcessClient(app.New(conf, services.NewMemoryCacheService()).MCPServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mcpClient.Close()
+
+	if err := mcpClient.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := mcpClient.Initialize(ctx, initRequest); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(ctx, mcpClient, DefaultCases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range report.Results {
+		if !result.Passed {
+			t.Errorf("%s(%v) failed: %v", result.Tool, result.Arguments, result.Errors)
+		}
+	}
+}
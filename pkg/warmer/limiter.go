@@ -0,0 +1,50 @@
+package warmer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter spaces out the upstream calls of the warmer so that it stays within the rate limits of the upstreams
+// and leaves room for the requests of the tools
+type Limiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewLimiter returns a limiter allowing the given number of calls per minute, unlimited when 0
+func NewLimiter(perMinute int) *Limiter {
+	if perMinute <= 0 {
+		return &Limiter{}
+	}
+	return &Limiter{interval: time.Minute / time.Duration(perMinute)}
+}
+
+// Wait blocks until a call is allowed, an error if the context is done before
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l.interval == 0 || ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
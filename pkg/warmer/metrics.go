@@ -0,0 +1,23 @@
+package warmer
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// published is the warmer whose stats are published with expvar (/debug/vars) under "warmer"
+var published atomic.Pointer[Warmer]
+
+func init() {
+	expvar.Publish("warmer", expvar.Func(func() any {
+		if w := published.Load(); w != nil {
+			return w.Stats()
+		}
+		return nil
+	}))
+}
+
+// PublishMetrics publishes the stats of the warmer: its counters and the warm coverage of its targets
+func PublishMetrics(w *Warmer) {
+	published.Store(w)
+}
@@ -0,0 +1,22 @@
+package warmer
+
+import (
+	"fmt"
+	"market_data_mcp_server/pkg/services"
+)
+
+// refreshCache writes to a cache but never reads from it, a cached scraper on top of it always calls
+// the upstream and replaces the cached entry with the fresh response
+type refreshCache struct {
+	services.CacheService
+}
+
+// RefreshCache returns a cache that writes to the given cache and misses on every read, so that
+// the warmer refreshes the entries that are already cached
+func RefreshCache(cache services.CacheService) services.CacheService {
+	return refreshCache{CacheService: cache}
+}
+
+func (c refreshCache) Get(key string, target interface{}) error {
+	return fmt.Errorf("key %s is refreshed", key)
+}
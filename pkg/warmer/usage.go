@@ -0,0 +1,75 @@
+package warmer
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxTrackedSymbols bounds the memory of the usage tracker, the symbols requested once it's full aren't tracked
+const maxTrackedSymbols = 10000
+
+// UsageTracker counts the stock symbols requested through the tools, to warm the most requested ones
+type UsageTracker struct {
+	arguments []string
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewUsageTracker returns a tracker of the stock symbols passed in the given tool arguments (e.g. stock_symbol)
+func NewUsageTracker(arguments ...string) *UsageTracker {
+	return &UsageTracker{arguments: arguments, counts: make(map[string]int)}
+}
+
+// ToolMiddleware counts the symbols of the tool calls that succeed, invalid symbols aren't warmed
+func (u *UsageTracker) ToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, req)
+		if err == nil && result != nil && !result.IsError {
+			for _, argument := range u.arguments {
+				if symbol := req.GetString(argument, ""); symbol != "" {
+					u.Record(symbol)
+				}
+			}
+		}
+		return result, err
+	}
+}
+
+// Record counts a request of the symbol
+func (u *UsageTracker) Record(symbol string) {
+	symbol = strings.ToLower(strings.TrimSpace(symbol))
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, ok := u.counts[symbol]; ok || len(u.counts) < maxTrackedSymbols {
+		u.counts[symbol]++
+	}
+}
+
+// Top returns the n most requested symbols, the most requested first
+func (u *UsageTracker) Top(n int) []string {
+	u.mu.Lock()
+	symbols := make([]string, 0, len(u.counts))
+	for symbol := range u.counts {
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if u.counts[symbols[i]] != u.counts[symbols[j]] {
+			return u.counts[symbols[i]] > u.counts[symbols[j]]
+		}
+		return symbols[i] < symbols[j]
+	})
+	u.mu.Unlock()
+
+	if len(symbols) > n {
+		symbols = symbols[:n]
+	}
+	return symbols
+}
@@ -0,0 +1,224 @@
+// Package warmer prefetches the data the tools need into the cache, so that the first request of the day
+// for a popular symbol doesn't pay the full scraping latency. It warms the market wide lists (sectors,
+// industries, tickers, market news) and the overview and financials of a watchlist and of the most
+// requested symbols, one upstream call at a time within a rate limit.
+package warmer
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scraper is the scraper the data is warmed with, its responses must be written to the cache the tools read
+// without being read from it (see RefreshCache), otherwise the warmer only reads the cache
+type Scraper interface {
+	GetSectors() ([]domain.Sector, error)
+	GetIndustries() ([]domain.Industry, error)
+	GetTickers() ([]domain.Ticker, error)
+	GetMarketNews() ([]domain.NewsArticle, error)
+	GetStockProfile(symbol string) (domain.StockProfile, error)
+	GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error)
+	GetStockForecast(symbol string) (domain.StockForecast, error)
+	GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error)
+	GetBalanceSheets(symbol string) ([]domain.BalanceSheet, error)
+	GetIncomeStatements(symbol string) ([]domain.IncomeStatement, error)
+	GetCashFlows(symbol string) ([]domain.CashFlow, error)
+}
+
+// SymbolSource returns the most requested stock symbols
+type SymbolSource interface {
+	Top(n int) []string
+}
+
+type Config struct {
+	Watchlist          []string        // Stock symbols always warmed
+	TopSymbols         int             // Number of most requested symbols warmed on top of the watchlist
+	PerformancePeriods []domain.Period // Periods of the historical performance of the stock overview
+	RatePerMinute      int             // Maximum upstream calls per minute, unlimited when 0
+	CacheTtl           time.Duration   // Time a warmed entry stays in the cache, to compute the coverage
+}
+
+// step is a single upstream call that warms a target
+type step struct {
+	target string
+	fetch  func() error
+}
+
+// targetState is the last time a target was warmed and the error of the last attempt
+type targetState struct {
+	warmedAt  time.Time
+	lastError string
+}
+
+type Warmer struct {
+	scraper Scraper
+	symbols SymbolSource
+	conf    Config
+	limiter *Limiter
+
+	mu      sync.Mutex
+	targets map[string]targetState
+	stats   Stats
+}
+
+func NewWarmer(scraper Scraper, symbols SymbolSource, conf Config) (*Warmer, error) {
+	if conf.TopSymbols < 0 {
+		return nil, fmt.Errorf("top symbols must not be negative")
+	}
+	if conf.TopSymbols > 0 && symbols == nil {
+		return nil, fmt.Errorf("a symbol source is required to warm the top symbols")
+	}
+	if conf.CacheTtl <= 0 {
+		return nil, fmt.Errorf("cache ttl must be positive")
+	}
+
+	return &Warmer{
+		scraper: scraper,
+		symbols: symbols,
+		conf:    conf,
+		limiter: NewLimiter(conf.RatePerMinute),
+		targets: make(map[string]targetState),
+	}, nil
+}
+
+// WarmMarket warms the sectors, the industries, the tickers and the market news
+func (w *Warmer) WarmMarket(ctx context.Context) {
+	w.warm(ctx, w.marketSteps())
+}
+
+// WarmSymbols warms the overview and the financials of the watchlist and of the most requested symbols
+func (w *Warmer) WarmSymbols(ctx context.Context) {
+	var steps []step
+	for _, symbol := range w.Symbols() {
+		steps = append(steps, w.symbolSteps(symbol)...)
+	}
+	w.warm(ctx, steps)
+}
+
+// Symbols returns the symbols warmed by WarmSymbols: the watchlist followed by the most requested symbols
+func (w *Warmer) Symbols() []string {
+	var symbols []string
+	add := func(symbol string) {
+		if symbol = strings.ToLower(strings.TrimSpace(symbol)); symbol != "" && !slices.Contains(symbols, symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	for _, symbol := range w.conf.Watchlist {
+		add(symbol)
+	}
+	if w.conf.TopSymbols > 0 {
+		for _, symbol := range w.symbols.Top(w.conf.TopSymbols) {
+			add(symbol)
+		}
+	}
+	return symbols
+}
+
+func (w *Warmer) marketSteps() []step {
+	return []step{
+		{"sectors", func() error { _, err := w.scraper.GetSectors(); return err }},
+		{"industries", func() error { _, err := w.scraper.GetIndustries(); return err }},
+		{"tickers", func() error { _, err := w.scraper.GetTickers(); return err }},
+		{"market_news", func() error { _, err := w.scraper.GetMarketNews(); return err }},
+	}
+}
+
+// symbolSteps are the upstream calls of the getStockOverview and getStockFinancials tools for the symbol
+func (w *Warmer) symbolSteps(symbol string) []step {
+	steps := []step{
+		{"stock_profile_" + symbol, func() error { _, err := w.scraper.GetStockProfile(symbol); return err }},
+		{"financial_ratios_" + symbol, func() error { _, err := w.scraper.GetFinancialRatios(symbol); return err }},
+		{"stock_forecast_" + symbol, func() error { _, err := w.scraper.GetStockForecast(symbol); return err }},
+	}
+	for _, period := range w.conf.PerformancePeriods {
+		steps = append(steps, step{
+			target: fmt.Sprintf("historical_prices_%s_%s", symbol, period),
+			fetch: func() error {
+				_, err := w.scraper.GetHistoricalPrices(symbol, domain.Stock, period)
+				return err
+			},
+		})
+	}
+	return append(steps,
+		step{"balance_sheets_" + symbol, func() error { _, err := w.scraper.GetBalanceSheets(symbol); return err }},
+		step{"income_statements_" + symbol, func() error { _, err := w.scraper.GetIncomeStatements(symbol); return err }},
+		step{"cash_flows_" + symbol, func() error { _, err := w.scraper.GetCashFlows(symbol); return err }},
+	)
+}
+
+func (w *Warmer) warm(ctx context.Context, steps []step) {
+	w.mu.Lock()
+	w.stats.Runs++
+	w.stats.LastRunAt = time.Now()
+	w.mu.Unlock()
+
+	for _, s := range steps {
+		if err := w.limiter.Wait(ctx); err != nil {
+			return
+		}
+
+		err := s.fetch()
+
+		w.mu.Lock()
+		state := w.targets[s.target]
+		w.stats.Calls++
+		if err != nil {
+			w.stats.Failures++
+			state.lastError = err.Error()
+		} else {
+			state.warmedAt = time.Now()
+			state.lastError = ""
+		}
+		w.targets[s.target] = state
+		w.mu.Unlock()
+	}
+}
+
+// Stats are the counters of the warmer and the warm coverage of its current targets
+type Stats struct {
+	Runs      int64     `json:"runs"`
+	Calls     int64     `json:"calls"`
+	Failures  int64     `json:"failures"`
+	LastRunAt time.Time `json:"lastRunAt"`
+
+	Symbols  []string `json:"symbols"`
+	Targets  int      `json:"targets"`
+	Warm     int      `json:"warm"`     // Targets warmed less than the cache ttl ago
+	Coverage float64  `json:"coverage"` // Warm targets over targets
+	Failing  []string `json:"failing,omitempty"`
+}
+
+// Stats returns the counters and the warm coverage of the market lists and of the current symbols
+func (w *Warmer) Stats() Stats {
+	symbols := w.Symbols()
+	steps := w.marketSteps()
+	for _, symbol := range symbols {
+		steps = append(steps, w.symbolSteps(symbol)...)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := w.stats
+	stats.Symbols = symbols
+	stats.Targets = len(steps)
+	for _, s := range steps {
+		state := w.targets[s.target]
+		if !state.warmedAt.IsZero() && time.Since(state.warmedAt) < w.conf.CacheTtl {
+			stats.Warm++
+		}
+		if state.lastError != "" {
+			stats.Failing = append(stats.Failing, s.target)
+		}
+	}
+	if stats.Targets > 0 {
+		stats.Coverage = float64(stats.Warm) / float64(stats.Targets)
+	}
+	return stats
+}
@@ -0,0 +1,159 @@
+package warmer
+
+import (
+	"context"
+	"fmt"
+	"market_data_mcp_server/pkg/domain"
+	"market_data_mcp_server/pkg/services"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// countingScraper counts its calls and fails for the symbol "fail"
+type countingScraper struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (s *countingScraper) call(name string, symbol string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.calls == nil {
+		s.calls = make(map[string]int)
+	}
+	s.calls[strings.TrimSuffix(name+"_"+symbol, "_")]++
+	if symbol == "fail" {
+		return fmt.Errorf("symbol %s not found", symbol)
+	}
+	return nil
+}
+
+func (s *countingScraper) GetSectors() ([]domain.Sector, error) { return nil, s.call("sectors", "") }
+func (s *countingScraper) GetIndustries() ([]domain.Industry, error) {
+	return nil, s.call("industries", "")
+}
+func (s *countingScraper) GetTickers() ([]domain.Ticker, error) { return nil, s.call("tickers", "") }
+func (s *countingScraper) GetMarketNews() ([]domain.NewsArticle, error) {
+	return nil, s.call("market_news", "")
+}
+func (s *countingScraper) GetStockProfile(symbol string) (domain.StockProfile, error) {
+	return domain.StockProfile{}, s.call("stock_profile", symbol)
+}
+func (s *countingScraper) GetFinancialRatios(symbol string) ([]domain.FinancialRatios, error) {
+	return nil, s.call("financial_ratios", symbol)
+}
+func (s *countingScraper) GetStockForecast(symbol string) (domain.StockForecast, error) {
+	return domain.StockForecast{}, s.call("stock_forecast", symbol)
+}
+func (s *countingScraper) GetHistoricalPrices(ticker string, assetClass domain.AssetClass, period domain.Period) (domain.HistoricalPrices, error) {
+	return domain.HistoricalPrices{}, s.call("historical_prices_"+string(period), ticker)
+}
+func (s *countingScraper) GetBalanceSheets(symbol string) ([]domain.BalanceSheet, error) {
+	return nil, s.call("balance_sheets", symbol)
+}
+func (s *countingScraper) GetIncomeStatements(symbol string) ([]domain.IncomeStatement, error) {
+	return nil, s.call("income_statements", symbol)
+}
+func (s *countingScraper) GetCashFlows(symbol string) ([]domain.CashFlow, error) {
+	return nil, s.call("cash_flows", symbol)
+}
+
+func TestWarmer(t *testing.T) {
+	scraper := &countingScraper{}
+	tracker := NewUsageTracker("stock_symbol")
+	for _, symbol := range []string{"MSFT", "msft", "nvda", "fail", "fail", "fail"} {
+		tracker.Record(symbol)
+	}
+
+	w, err := NewWarmer(scraper, tracker, Config{
+		Watchlist:          []string{"AAPL", "msft"},
+		TopSymbols:         2,
+		PerformancePeriods: []domain.Period{domain.Period1M, domain.Period1Y},
+		CacheTtl:           time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(w.Symbols(), ","); got != "aapl,msft,fail" {
+		t.Fatalf("expected the watchlist followed by the top symbols, got %s", got)
+	}
+
+	stats := w.Stats()
+	if stats.Targets != 4+3*8 || stats.Warm != 0 || stats.Coverage != 0 {
+		t.Fatalf("expected 28 cold targets, got %+v", stats)
+	}
+
+	w.WarmMarket(context.Background())
+	w.WarmSymbols(context.Background())
+
+	if scraper.calls["sectors"] != 1 || scraper.calls["historical_prices_1y_aapl"] != 1 || scraper.calls["cash_flows_msft"] != 1 {
+		t.Errorf("expected every target to be fetched once, got %v", scraper.calls)
+	}
+
+	stats = w.Stats()
+	if stats.Runs != 2 || stats.Calls != 28 || stats.Failures != 8 || stats.Warm != 20 || len(stats.Failing) != 8 {
+		t.Errorf("expected the targets of the failing symbol not to be warm, got %+v", stats)
+	}
+}
+
+func TestRefreshCache(t *testing.T) {
+	cache := services.NewMemoryCacheService()
+	cache.Set("sectors", []string{"old"}, time.Hour)
+
+	refresh := RefreshCache(cache)
+	var sectors []string
+	if err := refresh.Get("sectors", &sectors); err == nil {
+		t.Fatal("expected the refresh cache to miss")
+	}
+
+	refresh.Set("sectors", []string{"new"}, time.Hour)
+	if err := cache.Get("sectors", &sectors); err != nil || sectors[0] != "new" {
+		t.Errorf("expected the refreshed entry in the cache, got %v (%v)", sectors, err)
+	}
+}
+
+func TestUsageTrackerMiddleware(t *testing.T) {
+	tracker := NewUsageTracker("stock_symbol")
+	handler := tracker.ToolMiddleware(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if req.GetString("stock_symbol", "") == "invalid" {
+			return mcp.NewToolResultError("not found"), nil
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	for _, symbol := range []string{"AAPL", "invalid", "tsla", "aapl"} {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]any{"stock_symbol": symbol}
+		handler(context.Background(), req)
+	}
+
+	if got := strings.Join(tracker.Top(5), ","); got != "aapl,tsla" {
+		t.Errorf("expected the successful symbols by number of requests, got %s", got)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(60 * 50) // 20ms between calls
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 4 {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("expected the calls to be spaced out, took %v", elapsed)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := NewLimiter(1).Wait(canceled); err == nil {
+		t.Error("expected an error once the context is done")
+	}
+}